@@ -154,6 +154,10 @@ func migrateActionsLog(ctx context.Context, dstStorage storage.ObjectStorage) er
 			// running tasks store logs in DBFS
 			return nil
 		}
+		if task.LogPruned {
+			// the local copy has been removed, the log is in the archive storage
+			return nil
+		}
 		p := task.LogFilename
 		_, err := storage.Copy(dstStorage, p, storage.Actions, p)
 		return err
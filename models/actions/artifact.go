@@ -9,9 +9,12 @@ package actions
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
@@ -147,8 +150,134 @@ func ListNeedExpiredArtifacts(ctx context.Context) ([]*ActionArtifact, error) {
 		Where("expired_unix < ? AND status = ?", timeutil.TimeStamp(time.Now().Unix()), ArtifactStatusUploadConfirmed).Find(&arts)
 }
 
+// ExtendArtifactsForRerun restarts the retention of the uploaded artifacts of the run from now,
+// so the artifacts of the jobs which are not rerun are still available to the rerun jobs.
+// Each artifact keeps its own retention days, which is the duration between its creation and expiration.
+func ExtendArtifactsForRerun(ctx context.Context, runID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("run_id=? AND status=?", runID, ArtifactStatusUploadConfirmed).
+		SetExpr("expired_unix", fmt.Sprintf("%d + expired_unix - created_unix", timeutil.TimeStampNow())).
+		NoAutoTime().
+		Update(new(ActionArtifact))
+}
+
+// ListExpiredArtifactNames returns the names of the artifacts of the run which have expired,
+// including the ones which haven't been marked as expired by the cleanup task yet.
+func ListExpiredArtifactNames(ctx context.Context, runID int64) ([]string, error) {
+	names := make([]string, 0, 5)
+	return names, db.GetEngine(ctx).Table("action_artifact").
+		Where(builder.Eq{"run_id": runID}.And(builder.Or(
+			builder.Eq{"status": ArtifactStatusExpired},
+			builder.Eq{"status": ArtifactStatusUploadConfirmed}.And(builder.Lt{"expired_unix": timeutil.TimeStampNow()}),
+		))).
+		Distinct("artifact_name").
+		OrderBy("artifact_name").
+		Find(&names)
+}
+
 // SetArtifactExpired sets an artifact to expired
 func SetArtifactExpired(ctx context.Context, artifactID int64) error {
 	_, err := db.GetEngine(ctx).Where("id=? AND status = ?", artifactID, ArtifactStatusUploadConfirmed).Cols("status").Update(&ActionArtifact{Status: int64(ArtifactStatusExpired)})
 	return err
 }
+
+// ErrArtifactQuotaExceeded means a new artifact can't be stored without exceeding the artifact quota of the repository,
+// even if all artifacts which could be evicted were evicted.
+type ErrArtifactQuotaExceeded struct {
+	Quota int64
+	Used  int64
+	Size  int64
+}
+
+func (err ErrArtifactQuotaExceeded) Error() string {
+	return fmt.Sprintf("the artifacts of the repository would exceed the quota of %d bytes, %d bytes are used by the artifacts which can't be evicted and the artifact is %d bytes", err.Quota, err.Used, err.Size)
+}
+
+func (err ErrArtifactQuotaExceeded) Unwrap() error {
+	return util.ErrInvalidArgument
+}
+
+// FindArtifactsToEvict finds the uploaded artifacts of the repository which should be evicted to store a new artifact of incoming bytes
+// without exceeding the quota in bytes, the artifact being uploaded is excluded. The oldest artifacts are evicted first,
+// and the artifacts of the runs which haven't finished are never evicted since their jobs may still download them.
+// It returns ErrArtifactQuotaExceeded if the quota would be exceeded anyway.
+func FindArtifactsToEvict(ctx context.Context, repoID, quota, incoming, excludeID int64) ([]*ActionArtifact, error) {
+	if quota <= 0 {
+		return nil, nil
+	}
+	var artifacts []*ActionArtifact
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID}.And(builder.In("status", ArtifactStatusUploadPending, ArtifactStatusUploadConfirmed))).
+		And(builder.Neq{"id": excludeID}).
+		Find(&artifacts); err != nil {
+		return nil, err
+	}
+
+	runIDs := make(container.Set[int64])
+	for _, artifact := range artifacts {
+		runIDs.Add(artifact.RunID)
+	}
+	var runs []*ActionRun
+	if len(runIDs) > 0 {
+		if err := db.GetEngine(ctx).In("id", runIDs.Values()).Cols("id", "status").Find(&runs); err != nil {
+			return nil, err
+		}
+	}
+	doneRuns := make(container.Set[int64], len(runs))
+	for _, run := range runs {
+		if run.Status.IsDone() {
+			doneRuns.Add(run.ID)
+		}
+	}
+
+	return pickArtifactsToEvict(artifacts, doneRuns, quota, incoming)
+}
+
+func pickArtifactsToEvict(artifacts []*ActionArtifact, doneRuns container.Set[int64], quota, incoming int64) ([]*ActionArtifact, error) {
+	total := incoming
+	var evictable []*ActionArtifact
+	for _, artifact := range artifacts {
+		total += artifact.FileCompressedSize
+		if artifact.Status == int64(ArtifactStatusUploadConfirmed) && doneRuns.Contains(artifact.RunID) {
+			evictable = append(evictable, artifact)
+		}
+	}
+	if total <= quota {
+		return nil, nil
+	}
+
+	sort.SliceStable(evictable, func(i, j int) bool {
+		if evictable[i].CreatedUnix != evictable[j].CreatedUnix {
+			return evictable[i].CreatedUnix < evictable[j].CreatedUnix
+		}
+		return evictable[i].ID < evictable[j].ID
+	})
+
+	var ret []*ActionArtifact
+	for _, artifact := range evictable {
+		if total <= quota {
+			break
+		}
+		ret = append(ret, artifact)
+		total -= artifact.FileCompressedSize
+	}
+	if total > quota {
+		return nil, ErrArtifactQuotaExceeded{Quota: quota, Used: total - incoming, Size: incoming}
+	}
+	return ret, nil
+}
+
+// ActionArtifactsUsage is the total size of the stored artifacts of a repository
+type ActionArtifactsUsage struct {
+	RepoID int64
+	Size   int64
+}
+
+// ListArtifactsUsages returns the total sizes of the stored artifacts of all repositories which have any
+func ListArtifactsUsages(ctx context.Context) ([]*ActionArtifactsUsage, error) {
+	usages := make([]*ActionArtifactsUsage, 0, 10)
+	return usages, db.GetEngine(ctx).Table("action_artifact").
+		Where(builder.In("status", ArtifactStatusUploadPending, ArtifactStatusUploadConfirmed)).
+		GroupBy("repo_id").
+		Select("repo_id, sum(file_compressed_size) as size").
+		Find(&usages)
+}
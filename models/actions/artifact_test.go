@@ -0,0 +1,49 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pickArtifactsToEvict(t *testing.T) {
+	confirmed := int64(ArtifactStatusUploadConfirmed)
+	artifacts := []*ActionArtifact{
+		{ID: 1, RunID: 1, FileCompressedSize: 40, Status: confirmed, CreatedUnix: 30},
+		{ID: 2, RunID: 1, FileCompressedSize: 30, Status: confirmed, CreatedUnix: 10},
+		{ID: 3, RunID: 2, FileCompressedSize: 20, Status: confirmed, CreatedUnix: 5},                         // the run is still in progress
+		{ID: 4, RunID: 1, FileCompressedSize: 5, Status: int64(ArtifactStatusUploadPending), CreatedUnix: 1}, // still uploading
+	}
+	doneRuns := container.SetOf[int64](1)
+
+	evicted, err := pickArtifactsToEvict(artifacts, doneRuns, 100, 5)
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+
+	// the oldest artifacts of the finished runs are evicted first
+	evicted, err = pickArtifactsToEvict(artifacts, doneRuns, 100, 20)
+	require.NoError(t, err)
+	if assert.Len(t, evicted, 1) {
+		assert.EqualValues(t, 2, evicted[0].ID)
+	}
+
+	evicted, err = pickArtifactsToEvict(artifacts, doneRuns, 100, 60)
+	require.NoError(t, err)
+	if assert.Len(t, evicted, 2) {
+		assert.EqualValues(t, 2, evicted[0].ID)
+		assert.EqualValues(t, 1, evicted[1].ID)
+	}
+
+	// the artifacts in use are never evicted
+	evicted, err = pickArtifactsToEvict(artifacts, doneRuns, 100, 80)
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	assert.Equal(t, ErrArtifactQuotaExceeded{Quota: 100, Used: 25, Size: 80}, err)
+	assert.Empty(t, evicted)
+}
@@ -0,0 +1,210 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(ActionCache))
+}
+
+// ActionCache is an entry of the actions cache, which is scoped by the repository and the ref that created it.
+type ActionCache struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"index"`
+	Ref         string `xorm:"VARCHAR(255) index"` // the full ref name which created the cache, like "refs/heads/main"
+	Key         string `xorm:"VARCHAR(512)"`
+	Version     string `xorm:"VARCHAR(64)"` // the hash of the paths and the compression method, caches can only be restored with the same version
+	Size        int64
+	StoragePath string
+	Complete    bool               `xorm:"index"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UsedUnix    timeutil.TimeStamp `xorm:"index"` // the last time the cache was created or restored, the least recently used caches are evicted first
+}
+
+// CacheScopes returns the refs whose caches can be restored by a run of ref, in the order they should be searched.
+// Like GitHub, a run can restore the caches created by its own ref, then the base branch of the pull request if any,
+// then the default branch of the repository.
+func CacheScopes(ref, baseRef, defaultBranch string) []string {
+	scopes := make([]string, 0, 3)
+	for _, scope := range []string{ref, baseRef, "refs/heads/" + defaultBranch} {
+		if scope == "" || scope == "refs/heads/" {
+			continue
+		}
+		duplicated := false
+		for _, v := range scopes {
+			if v == scope {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// FindCacheToRestore finds the cache to restore for the primary key and the restore keys.
+// It returns nil if there is no matched cache.
+func FindCacheToRestore(ctx context.Context, repoID int64, scopes, keys []string, version string) (*ActionCache, error) {
+	if len(scopes) == 0 || len(keys) == 0 {
+		return nil, nil
+	}
+	cond := builder.NewCond()
+	for i, key := range keys {
+		if i == 0 {
+			cond = cond.Or(builder.Eq{"`key`": key})
+		} else {
+			cond = cond.Or(builder.Like{"`key`", key + "%"})
+		}
+	}
+
+	var caches []*ActionCache
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID, "version": version, "complete": true}).
+		And(builder.In("ref", scopes)).
+		And(cond).
+		Find(&caches); err != nil {
+		return nil, err
+	}
+	return pickCacheToRestore(caches, scopes, keys), nil
+}
+
+// pickCacheToRestore picks the cache to restore like GitHub does:
+// the scopes are searched in order, and in each scope, the primary key must match exactly,
+// then the restore keys are matched as prefixes in order, and the newest cache wins if several caches match a restore key.
+func pickCacheToRestore(caches []*ActionCache, scopes, keys []string) *ActionCache {
+	for _, scope := range scopes {
+		for i, key := range keys {
+			var picked *ActionCache
+			for _, cache := range caches {
+				if cache.Ref != scope {
+					continue
+				}
+				if i == 0 && cache.Key != key || i > 0 && !strings.HasPrefix(cache.Key, key) {
+					continue
+				}
+				if picked == nil || cache.CreatedUnix > picked.CreatedUnix {
+					picked = cache
+				}
+			}
+			if picked != nil {
+				return picked
+			}
+		}
+	}
+	return nil
+}
+
+// CreateCache reserves the cache to upload, it can't be restored until it's completed by CompleteCache.
+func CreateCache(ctx context.Context, cache *ActionCache) error {
+	cache.UsedUnix = timeutil.TimeStampNow()
+	return db.Insert(ctx, cache)
+}
+
+// GetCacheByID returns the cache of the repository
+func GetCacheByID(ctx context.Context, repoID, id int64) (*ActionCache, error) {
+	var cache ActionCache
+	has, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Get(&cache)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("cache with id %d: %w", id, util.ErrNotExist)
+	}
+	return &cache, nil
+}
+
+// ExistsCache reports whether the cache of the key and the version has been created by the ref, caches are immutable like GitHub.
+// The incomplete caches are counted too, so a cache being uploaded by a job can't be reserved by another job.
+func ExistsCache(ctx context.Context, repoID int64, ref, key, version string) (bool, error) {
+	return db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "ref": ref, "`key`": key, "version": version}).Exist(new(ActionCache))
+}
+
+// CompleteCache marks the cache as completed after its content has been stored, so it could be restored.
+func CompleteCache(ctx context.Context, cache *ActionCache) error {
+	cache.Complete = true
+	cache.UsedUnix = timeutil.TimeStampNow()
+	n, err := db.GetEngine(ctx).ID(cache.ID).Cols("size", "storage_path", "complete", "used_unix").Update(cache)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("cache with id %d: %w", cache.ID, util.ErrNotExist)
+	}
+	return nil
+}
+
+// TouchCache marks the cache as used now, so it will be evicted later than the others.
+func TouchCache(ctx context.Context, cache *ActionCache) error {
+	cache.UsedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(cache.ID).Cols("used_unix").Update(cache)
+	return err
+}
+
+// FindCachesToEvict finds the caches of the repository which should be evicted to store a new cache of incoming bytes
+// without exceeding the quota in bytes. The least recently used caches are evicted first, rather than the oldest created ones,
+// so the caches restored by every run are kept. The caches being uploaded are never evicted, they take no space yet.
+func FindCachesToEvict(ctx context.Context, repoID, quota, incoming int64) ([]*ActionCache, error) {
+	if quota <= 0 {
+		return nil, nil
+	}
+	var caches []*ActionCache
+	if err := db.GetEngine(ctx).Where("repo_id = ? AND complete = ?", repoID, true).Find(&caches); err != nil {
+		return nil, err
+	}
+	return pickCachesToEvict(caches, quota, incoming), nil
+}
+
+func pickCachesToEvict(caches []*ActionCache, quota, incoming int64) []*ActionCache {
+	total := incoming
+	for _, cache := range caches {
+		total += cache.Size
+	}
+	if total <= quota {
+		return nil
+	}
+
+	sorted := make([]*ActionCache, len(caches))
+	copy(sorted, caches)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].UsedUnix != sorted[j].UsedUnix {
+			return sorted[i].UsedUnix < sorted[j].UsedUnix
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var ret []*ActionCache
+	for _, cache := range sorted {
+		if total <= quota {
+			break
+		}
+		ret = append(ret, cache)
+		total -= cache.Size
+	}
+	return ret
+}
+
+// DeleteCaches deletes the records of the caches, the files in the storage should be deleted by the caller.
+func DeleteCaches(ctx context.Context, caches []*ActionCache) error {
+	if len(caches) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(caches))
+	for _, cache := range caches {
+		ids = append(ids, cache.ID)
+	}
+	_, err := db.GetEngine(ctx).In("id", ids).Delete(new(ActionCache))
+	return err
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheScopes(t *testing.T) {
+	assert.Equal(t, []string{"refs/heads/feature", "refs/heads/main"}, CacheScopes("refs/heads/feature", "", "main"))
+	assert.Equal(t, []string{"refs/pull/1/merge", "refs/heads/develop", "refs/heads/main"}, CacheScopes("refs/pull/1/merge", "refs/heads/develop", "main"))
+	assert.Equal(t, []string{"refs/heads/main"}, CacheScopes("refs/heads/main", "", "main"))
+	assert.Equal(t, []string{"refs/tags/v1"}, CacheScopes("refs/tags/v1", "", ""))
+}
+
+func Test_pickCacheToRestore(t *testing.T) {
+	caches := []*ActionCache{
+		{ID: 1, Ref: "refs/heads/main", Key: "deps-linux-aaa", CreatedUnix: 1},
+		{ID: 2, Ref: "refs/heads/main", Key: "deps-linux-bbb", CreatedUnix: 2},
+		{ID: 3, Ref: "refs/heads/feature", Key: "deps-linux-ccc", CreatedUnix: 3},
+		{ID: 4, Ref: "refs/heads/other", Key: "deps-linux-ddd", CreatedUnix: 4},
+	}
+	scopes := CacheScopes("refs/heads/feature", "", "main")
+
+	tests := []struct {
+		name string
+		keys []string
+		want int64
+	}{
+		{
+			name: "exact key in default branch",
+			keys: []string{"deps-linux-aaa"},
+			want: 1,
+		},
+		{
+			name: "restore key prefers the current branch",
+			keys: []string{"deps-linux-xxx", "deps-linux-"},
+			want: 3,
+		},
+		{
+			name: "primary key is not a prefix",
+			keys: []string{"deps-linux-"},
+			want: 0,
+		},
+		{
+			name: "restore keys are matched in order",
+			keys: []string{"deps-linux-xxx", "deps-linux-b", "deps-"},
+			want: 3,
+		},
+		{
+			name: "caches of unrelated branches are invisible",
+			keys: []string{"deps-linux-ddd"},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pickCacheToRestore(caches, scopes, tt.keys)
+			if tt.want == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.want, got.ID)
+			}
+		})
+	}
+
+	// the newest cache wins within the default branch
+	got := pickCacheToRestore(caches, CacheScopes("refs/heads/main", "", "main"), []string{"x", "deps-linux-"})
+	if assert.NotNil(t, got) {
+		assert.EqualValues(t, 2, got.ID)
+	}
+}
+
+func Test_pickCachesToEvict(t *testing.T) {
+	caches := []*ActionCache{
+		{ID: 1, Size: 40, UsedUnix: 30},
+		{ID: 2, Size: 30, UsedUnix: 10},
+		{ID: 3, Size: 20, UsedUnix: 20},
+	}
+
+	assert.Empty(t, pickCachesToEvict(caches, 100, 10))
+
+	evicted := pickCachesToEvict(caches, 100, 20)
+	if assert.Len(t, evicted, 1) {
+		assert.EqualValues(t, 2, evicted[0].ID)
+	}
+
+	evicted = pickCachesToEvict(caches, 100, 60)
+	if assert.Len(t, evicted, 2) {
+		assert.EqualValues(t, 2, evicted[0].ID)
+		assert.EqualValues(t, 3, evicted[1].ID)
+	}
+}
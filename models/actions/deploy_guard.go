@@ -0,0 +1,68 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// The decisions of the deploy guard, see ActionRun.DeployGuard
+const (
+	DeployGuardPassed  = "passed"  // the previous deploy run didn't stop the run
+	DeployGuardQueued  = "queued"  // the jobs wait until the previous deploy run is done
+	DeployGuardBlocked = "blocked" // the run failed when it was created because of the previous deploy run
+	DeployGuardStale   = "stale"   // the previous deploy run was ignored since it seemed to be stuck
+)
+
+// GetPreviousDeployRun returns the latest production run of the workflow in the repository, or nil if there isn't any.
+// The runs blocked by the deploy guard are skipped, they have never deployed anything.
+func GetPreviousDeployRun(ctx context.Context, repoID int64, workflowID string) (*ActionRun, error) {
+	var run ActionRun
+	has, err := db.GetEngine(ctx).Where(builder.Eq{
+		"repo_id":       repoID,
+		"workflow_id":   workflowID,
+		"is_production": true,
+	}.And(builder.Neq{"deploy_guard": DeployGuardBlocked})).Desc("id").Get(&run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return &run, nil
+}
+
+// IsDeployRunStuck reports whether the unfinished deploy run hasn't been updated for the timeout,
+// so the deploy guard ignores it rather than waiting for it forever
+func IsDeployRunStuck(run *ActionRun, timeout time.Duration, now timeutil.TimeStamp) bool {
+	return !run.Status.IsDone() && run.Updated.AddDuration(timeout) < now
+}
+
+// isDeployGuardQueued reports whether the jobs of the run are queued by the deploy guard, which is true
+// until the previous deploy run is done, either by itself or by the cleanup of the zombie, endless and abandoned tasks and jobs,
+// or until it has been stuck for setting.Actions.DeployGuardTimeout.
+func isDeployGuardQueued(ctx context.Context, runID int64) (bool, error) {
+	run, err := GetRunByID(ctx, runID)
+	if err != nil {
+		return false, err
+	}
+	if run.DeployGuard != DeployGuardQueued {
+		return false, nil
+	}
+	var previous ActionRun
+	has, err := db.GetEngine(ctx).ID(run.DeployGuardRunID).Get(&previous)
+	if err != nil {
+		return false, err
+	} else if !has {
+		// the previous run has been deleted
+		return false, nil
+	}
+	return !previous.Status.IsDone() && !IsDeployRunStuck(&previous, setting.Actions.DeployGuardTimeout, timeutil.TimeStampNow()), nil
+}
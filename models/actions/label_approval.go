@@ -0,0 +1,54 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ActionLabelApproval records that a pull request from a fork has been approved by the approval label, see setting.Actions.ApprovalLabel.
+// It only approves the head commit of the pull request when the label was added, so the commits pushed later need to be approved again.
+type ActionLabelApproval struct {
+	ID         int64
+	RepoID     int64              `xorm:"index"`
+	IssueID    int64              `xorm:"UNIQUE"`      // the issue of the pull request
+	CommitSHA  string             `xorm:"VARCHAR(64)"` // the head commit of the pull request when the label was added
+	ApproverID int64              // who added the label
+	Created    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionLabelApproval))
+}
+
+// SetLabelApproval records the approval of the pull request by the approval label, it replaces the previous approval of the pull request
+func SetLabelApproval(ctx context.Context, approval *ActionLabelApproval) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := DeleteLabelApproval(ctx, approval.IssueID); err != nil {
+			return err
+		}
+		approval.ID = 0
+		return db.Insert(ctx, approval)
+	})
+}
+
+// GetLabelApproval returns the approval of the pull request by the approval label, or nil if it hasn't been approved by the label
+func GetLabelApproval(ctx context.Context, issueID int64) (*ActionLabelApproval, error) {
+	var approval ActionLabelApproval
+	if has, err := db.GetEngine(ctx).Where("issue_id=?", issueID).Get(&approval); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return &approval, nil
+}
+
+// DeleteLabelApproval deletes the approval of the pull request by the approval label
+func DeleteLabelApproval(ctx context.Context, issueID int64) error {
+	_, err := db.GetEngine(ctx).Where("issue_id=?", issueID).Delete(new(ActionLabelApproval))
+	return err
+}
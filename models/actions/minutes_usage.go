@@ -0,0 +1,120 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(ActionMinutesUsage))
+}
+
+// ActionMinutesUsage records the runner time consumed by the jobs of an owner in a quota period, see setting.Actions.MinutesQuota
+type ActionMinutesUsage struct {
+	ID      int64              `xorm:"pk autoincr"`
+	OwnerID int64              `xorm:"UNIQUE(owner_period)"`
+	Period  string             `xorm:"VARCHAR(16) UNIQUE(owner_period)"` // the key of the period, like "2024-01", "2024-W01" or "2024-01-02"
+	Seconds int64              `xorm:"NOT NULL DEFAULT 0"`
+	Updated timeutil.TimeStamp `xorm:"updated"`
+}
+
+// MinutesQuotaPeriodKey returns the key of the quota period which t belongs to,
+// the periods are in UTC, so they are reset at the same time for all owners.
+func MinutesQuotaPeriodKey(t time.Time, period string) string {
+	t = t.UTC()
+	switch period {
+	case setting.MinutesQuotaPeriodDay:
+		return t.Format("2006-01-02")
+	case setting.MinutesQuotaPeriodWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// isMinutesQuotaCounted reports whether the time the runner spends on jobs counts against the minutes quota
+func isMinutesQuotaCounted(runner *ActionRunner) bool {
+	return setting.Actions.MinutesQuota > 0 && (runner.IsHosted() || setting.Actions.MinutesQuotaSelfHosted)
+}
+
+// GetMinutesUsage returns the seconds consumed by the jobs of the owner in the current period
+func GetMinutesUsage(ctx context.Context, ownerID int64) (int64, error) {
+	usage := &ActionMinutesUsage{}
+	has, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND period = ?", ownerID, MinutesQuotaPeriodKey(time.Now(), setting.Actions.MinutesQuotaPeriod)).
+		Get(usage)
+	if err != nil || !has {
+		return 0, err
+	}
+	return usage.Seconds, nil
+}
+
+// IsMinutesQuotaExhausted reports whether the owner has used up the runner minutes of the current period
+func IsMinutesQuotaExhausted(ctx context.Context, ownerID int64) (bool, error) {
+	if setting.Actions.MinutesQuota <= 0 {
+		return false, nil
+	}
+	seconds, err := GetMinutesUsage(ctx, ownerID)
+	if err != nil {
+		return false, err
+	}
+	return seconds >= setting.Actions.MinutesQuota*60, nil
+}
+
+// MarkRunsQuotaQueued records whether the waiting jobs of the runs are held since the owner has used up the runner minutes,
+// it's decided when the runs are created and when the runners pick their jobs, so the state is known without checking the usage.
+func MarkRunsQuotaQueued(ctx context.Context, queued bool, runIDs ...int64) error {
+	if len(runIDs) == 0 {
+		return nil
+	}
+	_, err := db.GetEngine(ctx).Table("action_run").
+		Where(builder.In("id", runIDs).And(builder.Eq{"quota_queued": !queued})).
+		Update(map[string]any{"quota_queued": queued})
+	return err
+}
+
+// addMinutesUsage adds the time the stopped task consumed to the usage of its owner in the current period
+func addMinutesUsage(ctx context.Context, task *ActionTask) error {
+	if setting.Actions.MinutesQuota <= 0 || task.Started.IsZero() || task.Stopped <= task.Started {
+		return nil
+	}
+	runner, err := GetRunnerByID(ctx, task.RunnerID)
+	if err != nil {
+		// the runner may have been deleted, it's unknown whether it was a hosted one
+		log.Warn("unable to count minutes of task %d: %v", task.ID, err)
+		return nil
+	}
+	if !isMinutesQuotaCounted(runner) {
+		return nil
+	}
+
+	seconds := int64(task.Stopped - task.Started)
+	period := MinutesQuotaPeriodKey(task.Stopped.AsTime(), setting.Actions.MinutesQuotaPeriod)
+	e := db.GetEngine(ctx)
+	affected, err := e.Where("owner_id = ? AND period = ?", task.OwnerID, period).
+		Incr("seconds", seconds).
+		Update(new(ActionMinutesUsage))
+	if err != nil {
+		return err
+	} else if affected > 0 {
+		return nil
+	}
+	_, err = e.Insert(&ActionMinutesUsage{
+		OwnerID: task.OwnerID,
+		Period:  period,
+		Seconds: seconds,
+	})
+	return err
+}
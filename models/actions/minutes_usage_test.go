@@ -0,0 +1,46 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinutesQuotaPeriodKey(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 2, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	assert.Equal(t, "2023-12-31", MinutesQuotaPeriodKey(tm, setting.MinutesQuotaPeriodDay))
+	assert.Equal(t, "2023-W52", MinutesQuotaPeriodKey(tm, setting.MinutesQuotaPeriodWeek))
+	assert.Equal(t, "2023-12", MinutesQuotaPeriodKey(tm, setting.MinutesQuotaPeriodMonth))
+
+	tm = time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2024-12-30", MinutesQuotaPeriodKey(tm, setting.MinutesQuotaPeriodDay))
+	assert.Equal(t, "2025-W01", MinutesQuotaPeriodKey(tm, setting.MinutesQuotaPeriodWeek))
+	assert.Equal(t, "2024-12", MinutesQuotaPeriodKey(tm, setting.MinutesQuotaPeriodMonth))
+}
+
+func Test_isMinutesQuotaCounted(t *testing.T) {
+	defer func(quota int64, selfHosted bool) {
+		setting.Actions.MinutesQuota = quota
+		setting.Actions.MinutesQuotaSelfHosted = selfHosted
+	}(setting.Actions.MinutesQuota, setting.Actions.MinutesQuotaSelfHosted)
+
+	hosted := &ActionRunner{}
+	selfHosted := &ActionRunner{OwnerID: 2}
+
+	setting.Actions.MinutesQuota = 0
+	assert.False(t, isMinutesQuotaCounted(hosted))
+
+	setting.Actions.MinutesQuota = 100
+	setting.Actions.MinutesQuotaSelfHosted = false
+	assert.True(t, isMinutesQuotaCounted(hosted))
+	assert.False(t, isMinutesQuotaCounted(selfHosted))
+
+	setting.Actions.MinutesQuotaSelfHosted = true
+	assert.True(t, isMinutesQuotaCounted(selfHosted))
+}
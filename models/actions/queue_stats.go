@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// QueueWaitStats is the aggregate queue wait time of the jobs of a runner pool, which is the set of the labels requested by the jobs
+type QueueWaitStats struct {
+	RunsOn  []string
+	Jobs    int64
+	Average time.Duration
+	Max     time.Duration
+}
+
+// GetQueueWaitStats returns the queue wait stats of the jobs which have been picked by runners since the time, grouped by their runner pools.
+// The pools which wait longer on average come first.
+func GetQueueWaitStats(ctx context.Context, since timeutil.TimeStamp) ([]*QueueWaitStats, error) {
+	var jobs []*ActionRunJob
+	if err := db.GetEngine(ctx).
+		Where(builder.Gt{"queued": 0}.And(builder.Gte{"started": since})).
+		And("started >= queued").
+		Cols("id", "runs_on", "fallback_runs_on", "queued", "started").
+		Find(&jobs); err != nil {
+		return nil, err
+	}
+	return aggregateQueueWaitStats(jobs), nil
+}
+
+func aggregateQueueWaitStats(jobs []*ActionRunJob) []*QueueWaitStats {
+	pools := make(map[string]*QueueWaitStats)
+	totals := make(map[string]time.Duration)
+	for _, job := range jobs {
+		runsOn := slices.Clone(job.EffectiveRunsOn())
+		sort.Strings(runsOn)
+		key := strings.Join(runsOn, "\n")
+		stats, ok := pools[key]
+		if !ok {
+			stats = &QueueWaitStats{RunsOn: runsOn}
+			pools[key] = stats
+		}
+		wait := job.QueueDuration()
+		stats.Jobs++
+		stats.Max = max(stats.Max, wait)
+		totals[key] += wait
+	}
+
+	ret := make([]*QueueWaitStats, 0, len(pools))
+	for key, stats := range pools {
+		stats.Average = totals[key] / time.Duration(stats.Jobs)
+		ret = append(ret, stats)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Average != ret[j].Average {
+			return ret[i].Average > ret[j].Average
+		}
+		return strings.Join(ret[i].RunsOn, "\n") < strings.Join(ret[j].RunsOn, "\n")
+	})
+	return ret
+}
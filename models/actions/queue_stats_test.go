@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_aggregateQueueWaitStats(t *testing.T) {
+	jobs := []*ActionRunJob{
+		{RunsOn: []string{"ubuntu-latest"}, Queued: 100, Started: 110},
+		{RunsOn: []string{"ubuntu-latest"}, Queued: 100, Started: 130},
+		{RunsOn: []string{"linux", "arm64"}, Queued: 100, Started: 400},
+		{RunsOn: []string{"arm64", "linux"}, Queued: 100, Started: 200},
+		{RunsOn: []string{"windows-latest"}, FallbackRunsOn: "self-hosted", Queued: 100, Started: 105},
+	}
+
+	assert.Equal(t, []*QueueWaitStats{
+		{RunsOn: []string{"arm64", "linux"}, Jobs: 2, Average: 200 * time.Second, Max: 300 * time.Second},
+		{RunsOn: []string{"ubuntu-latest"}, Jobs: 2, Average: 20 * time.Second, Max: 30 * time.Second},
+		{RunsOn: []string{"self-hosted"}, Jobs: 1, Average: 5 * time.Second, Max: 5 * time.Second},
+	}, aggregateQueueWaitStats(jobs))
+}
+
+func TestActionRunJob_QueueDuration(t *testing.T) {
+	assert.Zero(t, (&ActionRunJob{Status: StatusBlocked}).QueueDuration())
+	assert.Equal(t, 30*time.Second, (&ActionRunJob{Status: StatusSuccess, Queued: 100, Started: 130}).QueueDuration())
+	// the job was cancelled before any runner picked it
+	assert.Zero(t, (&ActionRunJob{Status: StatusCancelled, Queued: 100}).QueueDuration())
+	assert.Greater(t, (&ActionRunJob{Status: StatusWaiting, Queued: 100}).QueueDuration(), time.Duration(0))
+}
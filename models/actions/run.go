@@ -5,6 +5,7 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
@@ -32,18 +33,42 @@ type ActionRun struct {
 	Repo              *repo_model.Repository `xorm:"-"`
 	OwnerID           int64                  `xorm:"index"`
 	WorkflowID        string                 `xorm:"index"`                    // the name of workflow file
+	WorkflowDir       string                 `xorm:"NOT NULL DEFAULT ''"`      // the directory of the workflow file, like ".gitea/workflows", empty for the runs created before it's recorded
 	Index             int64                  `xorm:"index unique(repo_index)"` // a unique number for each run of a repository
 	TriggerUserID     int64                  `xorm:"index"`
 	TriggerUser       *user_model.User       `xorm:"-"`
 	ScheduleID        int64
-	Ref               string `xorm:"index"` // the commit/tag/… that caused the run
+	ManualSchedule    bool   `xorm:"NOT NULL DEFAULT false"` // the scheduled run was triggered manually by TriggerUser rather than by the cron
+	Ref               string `xorm:"index"`                  // the commit/tag/… that caused the run
 	CommitSHA         string
+	WorkflowSHA       string                       `xorm:"VARCHAR(64)"` // the git blob SHA of the workflow file which the run executed, it identifies the version of the file even across renames
+	HeadSHA           string                       // the head commit of the pull request or the merge group when the run was triggered, empty for other events
+	BaseSHA           string                       // the base commit of the pull request or the merge group when the run was triggered, empty for other events
+	HeadRef           string                       // the head branch of the pull request, like `github.head_ref`, empty for the events other than pull_request and pull_request_target
+	BaseRef           string                       // the base branch of the pull request, like `github.base_ref`, empty for the events other than pull_request and pull_request_target
 	IsForkPullRequest bool                         // If this is triggered by a PR from a forked repository or an untrusted user, we need to check if it is approved and limit permissions when running the workflow.
 	NeedApproval      bool                         // may need approval if it's a fork pull request
-	ApprovedBy        int64                        `xorm:"index"` // who approved
+	ApprovedBy        int64                        `xorm:"index"`                  // who approved
+	ApprovedByLabel   bool                         `xorm:"NOT NULL DEFAULT false"` // the run was approved by the approval label rather than explicitly, see ActionLabelApproval
 	Event             webhook_module.HookEventType // the webhook event that causes the workflow to run
 	EventPayload      string                       `xorm:"LONGTEXT"`
 	TriggerEvent      string                       // the trigger event defined in the `on` configuration of the triggered workflow
+	ContentHash       string                       `xorm:"VARCHAR(64)"`           // the hash of the snapshot of the workflow content, see ActionWorkflowContent
+	HostedFallback    bool                         `xorm:"NOT NULL DEFAULT true"` // whether the jobs may fall back to the hosted runners if no self-hosted runners match, see setting.Actions.HostedFallbackPolicy
+	Warnings          []string                     `xorm:"JSON TEXT"`             // the deprecated syntax detected in the workflow, they don't block the run
+	Errors            []string                     `xorm:"JSON TEXT"`             // the problems which failed the run when it was created, like the disallowed container images
+	SameNamed         []string                     `xorm:"JSON TEXT"`             // the paths of the other workflows with the same `name`, the contexts of the commit statuses of the run contain its file name if it's not empty
+	TriggerMatch      *RunTriggerMatch             `xorm:"JSON TEXT"`             // how the trigger event matched, nil for the runs created before it's recorded
+	DetectionTrace    *RunDetectionTrace           `xorm:"JSON TEXT"`             // why the run was created, nil if setting.Actions.DetectionTrace is disabled
+	IssueID           int64                        `xorm:"index"`                 // the issue or the pull request which the event is about, zero for other events
+	QuotaExceeded     bool                         // the jobs failed since the owner had used up the runner minutes, see setting.Actions.MinutesQuota
+	QuotaQueued       bool                         `xorm:"NOT NULL DEFAULT false"`                 // the jobs wait since the owner had used up the runner minutes, see MarkRunsQuotaQueued
+	IsProduction      bool                         `xorm:"index"`                                  // the run deploys to production, see setting.Actions.ProductionEnvironments
+	DeployGuard       string                       `xorm:"VARCHAR(20) NOT NULL DEFAULT ''"`        // the decision of the deploy guard when the run was created, empty if it wasn't checked, see setting.Actions.DeployGuard
+	DeployGuardRunID  int64                        `xorm:"NOT NULL DEFAULT 0"`                     // the previous deploy run which the decision of the deploy guard is based on
+	IsProtectedTag    bool                         `xorm:"NOT NULL DEFAULT false"`                 // the run was triggered by creating or pushing a protected tag, see setting.Actions.ProtectedTagApproval
+	DeliveryID        string                       `xorm:"VARCHAR(255) index NOT NULL DEFAULT ''"` // the ID of the upstream webhook delivery which the trigger event originates from, empty for internally-originated events
+	Fingerprint       string                       `xorm:"VARCHAR(64) index"`                      // identifies the notification and the workflow which created the run, so retrying to insert the run won't create duplicate runs
 	Status            Status                       `xorm:"index"`
 	Version           int                          `xorm:"version default 0"` // Status could be updated concomitantly, so an optimistic lock is needed
 	// Started and Stopped is used for recording last run time, if rerun happened, they will be reset to 0
@@ -51,8 +76,17 @@ type ActionRun struct {
 	Stopped timeutil.TimeStamp
 	// PreviousDuration is used for recording previous duration
 	PreviousDuration time.Duration
-	Created          timeutil.TimeStamp `xorm:"created"`
-	Updated          timeutil.TimeStamp `xorm:"updated"`
+	// EstimatedDuration is the median duration of the latest successful runs of the workflow, zero if there is no history
+	EstimatedDuration time.Duration `xorm:"NOT NULL DEFAULT 0"`
+	// Timeout is the limit of the whole run counting from Started, zero means unlimited, see actions_module.ParseRunTimeout
+	Timeout time.Duration `xorm:"NOT NULL DEFAULT 0"`
+	// InfraRetries is the number of times the run has been rerun automatically since its jobs failed by the infrastructure,
+	// see repo_model.ActionsConfig.InfraFailureRetries
+	InfraRetries int64 `xorm:"NOT NULL DEFAULT 0"`
+	// CompletedNotified is whether the completion of the latest attempt has been notified to the workflow_run workflows, if rerun happened, it will be reset
+	CompletedNotified bool               `xorm:"NOT NULL DEFAULT false"`
+	Created           timeutil.TimeStamp `xorm:"created"`
+	Updated           timeutil.TimeStamp `xorm:"updated"`
 }
 
 func init() {
@@ -146,6 +180,75 @@ func (run *ActionRun) GetPullRequestEventPayload() (*api.PullRequestPayload, err
 	return nil, fmt.Errorf("event %s is not a pull request event", run.Event)
 }
 
+func (run *ActionRun) GetMergeGroupEventPayload() (*api.MergeGroupPayload, error) {
+	if run.Event == webhook_module.HookEventMergeGroup {
+		var payload api.MergeGroupPayload
+		if err := json.Unmarshal([]byte(run.EventPayload), &payload); err != nil {
+			return nil, err
+		}
+		return &payload, nil
+	}
+	return nil, fmt.Errorf("event %s is not a merge group event", run.Event)
+}
+
+func (run *ActionRun) GetWorkflowRunEventPayload() (*api.WorkflowRunPayload, error) {
+	if run.Event == webhook_module.HookEventWorkflowRun {
+		var payload api.WorkflowRunPayload
+		if err := json.Unmarshal([]byte(run.EventPayload), &payload); err != nil {
+			return nil, err
+		}
+		return &payload, nil
+	}
+	return nil, fmt.Errorf("event %s is not a workflow run event", run.Event)
+}
+
+// MaxWorkflowRunDepth is the max levels of workflows which can be chained by workflow_run, the same as GitHub
+const MaxWorkflowRunDepth = 3
+
+// GetUpstreamRuns returns the runs which have triggered the run by workflow_run directly or through other runs, the nearest one first.
+// No more than MaxWorkflowRunDepth runs are returned, and the chain ends at the upstream run which has been deleted.
+// The upstream runs found before an error are returned with it.
+func (run *ActionRun) GetUpstreamRuns(ctx context.Context) ([]*ActionRun, error) {
+	var upstreams []*ActionRun
+	for run.Event == webhook_module.HookEventWorkflowRun && len(upstreams) < MaxWorkflowRunDepth {
+		payload, err := run.GetWorkflowRunEventPayload()
+		if err != nil {
+			return upstreams, fmt.Errorf("GetWorkflowRunEventPayload of run %d: %w", run.ID, err)
+		} else if payload.WorkflowRun == nil {
+			return upstreams, fmt.Errorf("run %d has no upstream run in its payload", run.ID)
+		}
+		upstream, err := GetRunByID(ctx, payload.WorkflowRun.ID)
+		if errors.Is(err, util.ErrNotExist) {
+			break
+		} else if err != nil {
+			return upstreams, err
+		}
+		upstreams = append(upstreams, upstream)
+		run = upstream
+	}
+	return upstreams, nil
+}
+
+// IsFromForkPullRequest reports whether the run is a run of a pull request from a fork, or it has been triggered by workflow_run
+// from such a run. The runs triggered by workflow_run run the workflows of the default branch, but the upstream runs could still
+// be controlled by the forks, so the origin of the chain should be checked rather than IsForkPullRequest to guard the sensitive data.
+// It reports true if the chain can't be checked.
+func (run *ActionRun) IsFromForkPullRequest(ctx context.Context) (bool, error) {
+	if run.IsForkPullRequest {
+		return true, nil
+	}
+	upstreams, err := run.GetUpstreamRuns(ctx)
+	if err != nil {
+		return true, err
+	}
+	for _, upstream := range upstreams {
+		if upstream.IsForkPullRequest {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func updateRepoRunsNumbers(ctx context.Context, repo *repo_model.Repository) error {
 	_, err := db.GetEngine(ctx).ID(repo.ID).
 		SetExpr("num_action_runs",
@@ -172,11 +275,18 @@ func updateRepoRunsNumbers(ctx context.Context, repo *repo_model.Repository) err
 
 // CancelRunningJobs cancels all running and waiting jobs associated with a specific workflow.
 func CancelRunningJobs(ctx context.Context, repoID int64, ref, workflowID string, event webhook_module.HookEventType) error {
+	return CancelRunningJobsOfWorkflowDir(ctx, repoID, ref, "", workflowID, event)
+}
+
+// CancelRunningJobsOfWorkflowDir is like CancelRunningJobs, but only the runs of the workflow in workflowDir are cancelled if it's not empty,
+// since the same-named workflows in both workflow directories are different workflows if both of them run.
+func CancelRunningJobsOfWorkflowDir(ctx context.Context, repoID int64, ref, workflowDir, workflowID string, event webhook_module.HookEventType) error {
 	// Find all runs in the specified repository, reference, and workflow with statuses 'Running' or 'Waiting'.
 	runs, total, err := db.FindAndCount[ActionRun](ctx, FindRunOptions{
 		RepoID:       repoID,
 		Ref:          ref,
 		WorkflowID:   workflowID,
+		WorkflowDir:  workflowDir,
 		TriggerEvent: event,
 		Status:       []Status{StatusRunning, StatusWaiting},
 	})
@@ -199,53 +309,110 @@ func CancelRunningJobs(ctx context.Context, repoID int64, ref, workflowID string
 			return err
 		}
 
-		// Iterate over each job and attempt to cancel it.
-		for _, job := range jobs {
-			// Skip jobs that are already in a terminal state (completed, cancelled, etc.).
-			status := job.Status
-			if status.IsDone() {
-				continue
-			}
+		if err := CancelJobs(ctx, jobs); err != nil {
+			return err
+		}
+	}
 
-			// If the job has no associated task (probably an error), set its status to 'Cancelled' and stop it.
-			if job.TaskID == 0 {
-				job.Status = StatusCancelled
-				job.Stopped = timeutil.TimeStampNow()
+	// Return nil to indicate successful cancellation of all running and waiting jobs.
+	return nil
+}
 
-				// Update the job's status and stopped time in the database.
-				n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
-				if err != nil {
-					return err
-				}
+// ErrJobChanged is returned by CancelJobs if a job has been changed since it was loaded, like picked by a runner,
+// the callers could load the jobs again and retry
+var ErrJobChanged = errors.New("job has changed, try again")
+
+// CancelJobs cancels the jobs which are not done yet.
+func CancelJobs(ctx context.Context, jobs []*ActionRunJob) error {
+	// Iterate over each job and attempt to cancel it.
+	for _, job := range jobs {
+		// Skip jobs that are already in a terminal state (completed, cancelled, etc.).
+		status := job.Status
+		if status.IsDone() {
+			continue
+		}
 
-				// If the update affected 0 rows, it means the job has changed in the meantime, so we need to try again.
-				if n == 0 {
-					return fmt.Errorf("job has changed, try again")
-				}
+		// If the job has no associated task (probably an error), set its status to 'Cancelled' and stop it.
+		if job.TaskID == 0 {
+			job.Status = StatusCancelled
+			job.Stopped = timeutil.TimeStampNow()
 
-				// Continue with the next job.
-				continue
+			// Update the job's status and stopped time in the database.
+			n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
+			if err != nil {
+				return err
 			}
 
-			// If the job has an associated task, try to stop the task, effectively cancelling the job.
-			if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
-				return err
+			// If the update affected 0 rows, it means the job has changed in the meantime, so we need to try again.
+			if n == 0 {
+				return ErrJobChanged
 			}
+
+			// Continue with the next job.
+			continue
 		}
-	}
 
-	// Return nil to indicate successful cancellation of all running and waiting jobs.
+		// If the job has an associated task, try to stop the task, effectively cancelling the job.
+		if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// ErrRunFingerprintExists is returned by InsertRun if the run of the same fingerprint has been inserted, the run is replaced with the existing one
+var ErrRunFingerprintExists = util.NewAlreadyExistErrorf("the run of the fingerprint has been inserted")
+
 // InsertRun inserts a run
-func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWorkflow) error {
+// InsertRun inserts a run and its jobs, and saves the snapshot of the workflow content that the run is created from.
+// stepRetries are the retry configurations of steps, gates are the manual gates, envs are the resolved `env` of jobs
+// and services are the service containers of jobs, they are keyed by job id and could be nil.
+// The jobs whose `if` is always false are skipped at once, and so is the run if all of its jobs are skipped.
+func InsertRun(ctx context.Context, run *ActionRun, content []byte, jobs []*jobparser.SingleWorkflow, stepRetries map[string]map[int64]*StepRetry, gates map[string]*JobGate, envs map[string]map[string]string, services map[string][]*JobService) error {
+	if len(jobs) == 0 {
+		// a run without jobs would wait forever
+		return util.NewInvalidArgumentErrorf("the workflow has no jobs")
+	}
+	allSkipped := true
+	for _, v := range jobs {
+		if _, job := v.Job(); !isStaticallySkipped(job) {
+			allSkipped = false
+			break
+		}
+	}
+	if allSkipped {
+		// no job will run, so the run is concluded at once
+		run.Status = StatusSkipped
+		run.Stopped = timeutil.TimeStampNow()
+	}
+
 	ctx, commiter, err := db.TxContext(ctx)
 	if err != nil {
 		return err
 	}
 	defer commiter.Close()
 
+	if run.Fingerprint != "" {
+		// the run may have been inserted by a previous attempt whose result was lost, like the connection was broken when committing
+		existing := &ActionRun{}
+		has, err := db.GetEngine(ctx).Where("repo_id = ? AND fingerprint = ?", run.RepoID, run.Fingerprint).Get(existing)
+		if err != nil {
+			return err
+		} else if has {
+			existing.Repo = run.Repo
+			*run = *existing
+			return ErrRunFingerprintExists
+		}
+	}
+
+	if run.ContentHash, err = InsertWorkflowContentIfNotExist(ctx, content); err != nil {
+		return err
+	}
+
+	if run.EstimatedDuration, err = EstimateRunDuration(ctx, run.RepoID, run.WorkflowID); err != nil {
+		return err
+	}
+
 	index, err := db.GetNextResourceIndex(ctx, "action_run_index", run.RepoID)
 	if err != nil {
 		return err
@@ -268,21 +435,48 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 		return err
 	}
 
+	tokenPermissions := resolveTokenPermissions(content)
+	continueOnErrors := resolveContinueOnErrors(content)
+	environments := resolveEnvironments(content)
+	environmentURLs := resolveEnvironmentURLs(content)
 	runJobs := make([]*ActionRunJob, 0, len(jobs))
 	var hasWaiting bool
 	for _, v := range jobs {
 		id, job := v.Job()
 		needs := job.Needs()
+		for _, service := range services[id] {
+			// the options carry the health check declared by `health`, so the runner honors it
+			if spec := job.Services[service.Name]; spec != nil {
+				spec.Options = service.Options
+			}
+		}
 		if err := v.SetJob(id, job.EraseNeeds()); err != nil {
 			return err
 		}
 		payload, _ := v.Marshal()
+		gate := gates[id]
 		status := StatusWaiting
-		if len(needs) > 0 || run.NeedApproval {
+		var gateDeadline timeutil.TimeStamp
+		if isStaticallySkipped(job) {
+			// the jobs which need it are resolved by the job emitter
+			status = StatusSkipped
+		} else if len(needs) > 0 || run.NeedApproval {
+			status = StatusBlocked
+		} else if gate != nil {
+			// the gate needs no other jobs, so it's reached at once
 			status = StatusBlocked
+			gateDeadline = timeutil.TimeStamp(time.Now().Add(gate.Timeout).Unix())
 		} else {
 			hasWaiting = true
 		}
+		var queued timeutil.TimeStamp
+		if status == StatusWaiting {
+			queued = timeutil.TimeStampNow()
+		}
+		tokenPermission, ok := tokenPermissions[id]
+		if !ok {
+			tokenPermission = defaultTokenPermission()
+		}
 		job.Name, _ = util.SplitStringAtByteN(job.Name, 255)
 		runJobs = append(runJobs, &ActionRunJob{
 			RunID:             run.ID,
@@ -295,7 +489,19 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 			JobID:             id,
 			Needs:             needs,
 			RunsOn:            job.RunsOn(),
+			StepRetries:       stepRetries[id],
+			MaxParallel:       parseMaxParallel(job),
+			FailFast:          parseFailFast(job),
+			ContinueOnError:   evaluateContinueOnError(continueOnErrors[id], job),
+			Gate:              gate,
+			GateDeadline:      gateDeadline,
+			TokenPermission:   tokenPermission,
+			Env:               envs[id],
+			Environment:       environments[id],
+			RawEnvironmentURL: environmentURLs[id],
+			Services:          services[id],
 			Status:            status,
+			Queued:            queued,
 		})
 	}
 	if err := db.Insert(ctx, runJobs); err != nil {
@@ -312,6 +518,32 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 	return commiter.Commit()
 }
 
+// IsTimedOut reports whether the run has been running longer than its timeout at now
+func (run *ActionRun) IsTimedOut(now timeutil.TimeStamp) bool {
+	if run.Timeout <= 0 || run.Started.IsZero() || run.Status.IsDone() {
+		return false
+	}
+	return run.Started.AddDuration(run.Timeout) < now
+}
+
+// FindTimedOutRuns finds the unfinished runs which have been running longer than their timeouts at now
+func FindTimedOutRuns(ctx context.Context, now timeutil.TimeStamp) ([]*ActionRun, error) {
+	var runs []*ActionRun
+	if err := db.GetEngine(ctx).
+		Where(builder.Gt{"timeout": 0}.And(builder.Gt{"started": 0})).
+		And(builder.In("status", StatusWaiting, StatusRunning, StatusBlocked)).
+		Find(&runs); err != nil {
+		return nil, err
+	}
+	ret := make([]*ActionRun, 0, len(runs))
+	for _, run := range runs {
+		if run.IsTimedOut(now) {
+			ret = append(ret, run)
+		}
+	}
+	return ret, nil
+}
+
 func GetRunByID(ctx context.Context, id int64) (*ActionRun, error) {
 	var run ActionRun
 	has, err := db.GetEngine(ctx).Where("id=?", id).Get(&run)
@@ -378,4 +610,16 @@ func UpdateRun(ctx context.Context, run *ActionRun, cols ...string) error {
 	return nil
 }
 
+// MarkRunCompletedNotified marks the completion of the run as notified,
+// it returns false if it has been marked by others, so that the completion will be notified only once.
+func MarkRunCompletedNotified(ctx context.Context, runID int64) (bool, error) {
+	affected, err := db.GetEngine(ctx).Table("action_run").
+		Where(builder.Eq{"id": runID, "completed_notified": false}).
+		Update(map[string]any{"completed_notified": true})
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
 type ActionRunIndex db.ResourceIndex
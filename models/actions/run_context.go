@@ -0,0 +1,46 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ActionRunContext is the snapshot of the contexts which the workflow of a run saw when the run was created,
+// like the `github`, `inputs`, `vars` and `env` contexts, so the problems of the run can be inspected later.
+// The values of secrets are never stored.
+type ActionRunContext struct {
+	ID        int64
+	RunID     int64              `xorm:"UNIQUE"`
+	RepoID    int64              `xorm:"index"`
+	Content   string             `xorm:"LONGTEXT"` // the contexts as a JSON object
+	Truncated bool               // some contexts have been dropped since they were too large, see setting.Actions.RunContextMaxSize
+	Created   timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionRunContext))
+}
+
+// InsertRunContext saves the snapshot of the contexts of the run
+func InsertRunContext(ctx context.Context, runContext *ActionRunContext) error {
+	return db.Insert(ctx, runContext)
+}
+
+// GetRunContextByRunID returns the snapshot of the contexts of the run
+func GetRunContextByRunID(ctx context.Context, runID int64) (*ActionRunContext, error) {
+	var runContext ActionRunContext
+	has, err := db.GetEngine(ctx).Where("run_id=?", runID).Get(&runContext)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("context of run %d: %w", runID, util.ErrNotExist)
+	}
+	return &runContext, nil
+}
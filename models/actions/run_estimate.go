@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+
+	"xorm.io/builder"
+)
+
+// estimateSamples is the number of the latest successful runs of a workflow whose durations estimate the duration of the new runs
+const estimateSamples = 10
+
+// EstimateRunDuration returns the median duration of the latest successful runs of the workflow in the repository,
+// or zero if there is no history. It only reads a few rows by the index of workflow_id, so it's cheap enough for creating runs.
+func EstimateRunDuration(ctx context.Context, repoID int64, workflowID string) (time.Duration, error) {
+	var runs []*ActionRun
+	if err := db.GetEngine(ctx).Cols("started", "stopped", "status").
+		Where(builder.Eq{"workflow_id": workflowID, "repo_id": repoID, "status": StatusSuccess}).
+		And(builder.Gt{"started": 0}).
+		OrderBy("id DESC").
+		Limit(estimateSamples).
+		Find(&runs); err != nil {
+		return 0, err
+	}
+
+	durations := make([]time.Duration, 0, len(runs))
+	for _, run := range runs {
+		// only the duration of the latest attempt, the previous attempts of a rerun didn't succeed
+		if d := calculateDuration(run.Started, run.Stopped, run.Status); d > 0 {
+			durations = append(durations, d)
+		}
+	}
+	return medianDuration(durations), nil
+}
+
+// refreshEstimatedDurations updates the estimated durations of the unfinished runs of the workflow,
+// it's called when a run of the workflow succeeds since the history has changed.
+func refreshEstimatedDurations(ctx context.Context, repoID int64, workflowID string) error {
+	estimated, err := EstimateRunDuration(ctx, repoID, workflowID)
+	if err != nil {
+		return err
+	}
+	// update by a map to bypass the optimistic lock, the version of the runs shouldn't be changed by the estimate
+	_, err = db.GetEngine(ctx).Table("action_run").
+		Where(builder.Eq{"workflow_id": workflowID, "repo_id": repoID}).
+		And(builder.In("status", []Status{StatusWaiting, StatusRunning, StatusBlocked})).
+		Update(map[string]any{"estimated_duration": estimated})
+	return err
+}
+
+// medianDuration returns the median of the durations, or zero if there are no durations
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
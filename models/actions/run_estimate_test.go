@@ -0,0 +1,23 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_medianDuration(t *testing.T) {
+	assert.Zero(t, medianDuration(nil))
+	assert.Equal(t, 3*time.Minute, medianDuration([]time.Duration{3 * time.Minute}))
+	assert.Equal(t, 2*time.Minute, medianDuration([]time.Duration{5 * time.Minute, time.Minute, 2 * time.Minute}))
+	assert.Equal(t, 150*time.Second, medianDuration([]time.Duration{time.Minute, 3 * time.Minute, 2 * time.Minute, 10 * time.Minute}))
+
+	// the durations are not sorted in place
+	durations := []time.Duration{3 * time.Second, time.Second, 2 * time.Second}
+	medianDuration(durations)
+	assert.Equal(t, []time.Duration{3 * time.Second, time.Second, 2 * time.Second}, durations)
+}
@@ -7,12 +7,20 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
 	"xorm.io/builder"
 )
 
@@ -28,21 +36,313 @@ type ActionRunJob struct {
 	Name              string `xorm:"VARCHAR(255)"`
 	Attempt           int64
 	WorkflowPayload   []byte
-	JobID             string   `xorm:"VARCHAR(255)"` // job id in workflow, not job's id
-	Needs             []string `xorm:"JSON TEXT"`
-	RunsOn            []string `xorm:"JSON TEXT"`
-	TaskID            int64    // the latest task of the job
-	Status            Status   `xorm:"index"`
+	JobID             string               `xorm:"VARCHAR(255)"` // job id in workflow, not job's id
+	Needs             []string             `xorm:"JSON TEXT"`
+	RunsOn            []string             `xorm:"JSON TEXT"`
+	FallbackRunsOn    string               `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"` // the default label applied since no runners matched RunsOn, see setting.Actions.DefaultRunsOn
+	StepRetries       map[int64]*StepRetry `xorm:"JSON TEXT"`                        // the retry configurations of steps, keyed by the index of step
+	MaxParallel       int                  `xorm:"NOT NULL DEFAULT 0"`               // the max number of the legs of the matrix job running at the same time, 0 means unlimited
+	FailFast          bool                 `xorm:"NOT NULL DEFAULT false"`           // the other legs of the matrix job are cancelled if the leg fails, it's false for the jobs without a matrix
+	ContinueOnError   bool                 `xorm:"NOT NULL DEFAULT false"`           // the job's `continue-on-error` evaluated with the matrix of the leg, a failed leg with it doesn't cancel others
+	Gate              *JobGate             `xorm:"JSON TEXT"`                        // the job is a manual gate if it's not nil, it never runs on runners
+	GateDeadline      timeutil.TimeStamp   `xorm:"index"`                            // when the gate will be rejected automatically, it's zero until the gate is reached
+	GateDecidedBy     int64                // the user who approved or rejected the gate, zero if it's rejected since timeout
+	TokenPermission   perm.AccessMode      `xorm:"NOT NULL DEFAULT 2"`               // the access mode of the token resolved from the `permissions` of the workflow
+	Env               map[string]string    `xorm:"JSON TEXT"`                        // the `env` of the workflow and the job, the expressions referencing `secrets` are kept for the runner
+	Environment       string               `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"` // the lower-cased name of the job's `environment`, the secrets of the environment are only available to the job
+	RawEnvironmentURL string               `xorm:"TEXT"`                             // the `url` of the job's `environment` as it's written, see EnvironmentURL
+	EnvironmentURL    string               `xorm:"TEXT"`                             // the resolved RawEnvironmentURL, it's resolved after the job is done if it needs the contexts only known then, like `steps`
+	Services          []*JobService        `xorm:"JSON TEXT"`                        // the service containers of the job, sorted by name
+	FailureCause      string               `xorm:"VARCHAR(16) NOT NULL DEFAULT ''"`  // why the latest attempt of the job failed, see FailureCauseInfra, empty if it didn't fail or the cause is unknown
+	TaskID            int64                // the latest task of the job
+	Status            Status               `xorm:"index"`
+	Queued            timeutil.TimeStamp   `xorm:"index"` // when the job became ready to run, after its needs are done and the run is approved, zero while it's blocked
 	Started           timeutil.TimeStamp
 	Stopped           timeutil.TimeStamp
 	Created           timeutil.TimeStamp `xorm:"created"`
 	Updated           timeutil.TimeStamp `xorm:"updated index"`
 }
 
+// QueueDuration returns how long the job waited for a runner after it became ready to run, it's still growing while the job is waiting
+func (job *ActionRunJob) QueueDuration() time.Duration {
+	if job.Queued.IsZero() {
+		return 0
+	}
+	if job.Started.IsZero() {
+		if !job.Status.IsWaiting() {
+			return 0
+		}
+		return timeSince(job.Queued.AsTime()).Truncate(time.Second)
+	}
+	if job.Started < job.Queued {
+		return 0
+	}
+	return time.Duration(job.Started-job.Queued) * time.Second
+}
+
+// EffectiveRunsOn returns the labels which the runners should match to run the job,
+// it's the default label if it has been applied since no runners matched the requested labels.
+func (job *ActionRunJob) EffectiveRunsOn() []string {
+	if job.FallbackRunsOn != "" {
+		return []string{job.FallbackRunsOn}
+	}
+	return job.RunsOn
+}
+
+const (
+	FailureCauseInfra = "infra" // the job failed by the infrastructure, like the runner died or the image of the job container failed to be pulled
+	FailureCauseLogic = "logic" // the job failed by the workflow itself, like a step failed or timed out
+)
+
+// StepRetry is the retry configuration of a step, the runner retries the step if it fails transiently.
+type StepRetry struct {
+	Count   int           `json:"count"`   // the max times to retry, not including the first attempt
+	Backoff time.Duration `json:"backoff"` // the duration to wait before each retry
+}
+
+// JobGate is the configuration of a manual gate, which pauses the run until it's approved or rejected by a reviewer.
+type JobGate struct {
+	Message   string        `json:"message"`   // the message shown to the reviewers, like "Proceed to prod?"
+	Reviewers []string      `json:"reviewers"` // the names of the users who can decide, anyone with write permission for actions can if it's empty
+	Timeout   time.Duration `json:"timeout"`   // the gate is rejected automatically if it isn't decided in time
+}
+
+// JobService is a service container of a job, which is parsed from `jobs.<job_id>.services`.
+// The expressions referencing `secrets` in its fields are kept for the runner, so the secrets are never stored in plaintext.
+type JobService struct {
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	Ports   []string          `json:"ports,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Options string            `json:"options,omitempty"` // the options of `docker create`, including the flags of the health check
+	Health  *ServiceHealth    `json:"health,omitempty"`  // nil if the service has no health check
+}
+
+// ServiceHealth is the health check of a service container, the runner waits until the service is healthy before it runs the steps
+type ServiceHealth struct {
+	Cmd         string        `json:"cmd"`
+	Interval    time.Duration `json:"interval,omitempty"`     // zero means the default of Docker
+	Timeout     time.Duration `json:"timeout,omitempty"`      // zero means the default of Docker
+	StartPeriod time.Duration `json:"start_period,omitempty"` // zero means the default of Docker
+	Retries     int           `json:"retries,omitempty"`      // zero means the default of Docker
+}
+
 func init() {
 	db.RegisterModel(new(ActionRunJob))
 }
 
+// IsAwaitingGate reports whether the job is a manual gate which has been reached and is waiting for a decision
+func (job *ActionRunJob) IsAwaitingGate() bool {
+	return job.Gate != nil && job.Status.IsBlocked() && !job.GateDeadline.IsZero()
+}
+
+// ReachGate starts waiting for the decision of the gate job whose needs are all done,
+// it does nothing if the gate has been reached already.
+func ReachGate(ctx context.Context, job *ActionRunJob) error {
+	if job.Gate == nil || !job.GateDeadline.IsZero() {
+		return nil
+	}
+	job.GateDeadline = timeutil.TimeStamp(time.Now().Add(job.Gate.Timeout).Unix())
+	_, err := UpdateRunJob(ctx, job, builder.Eq{"status": StatusBlocked, "gate_deadline": 0}, "gate_deadline")
+	return err
+}
+
+// parseMaxParallel returns `strategy.max-parallel` of the job, 0 means unlimited.
+// Expressions are not supported yet, so they are treated as unlimited.
+func parseMaxParallel(job *jobparser.Job) int {
+	if job.Strategy.MaxParallelString == "" {
+		return 0
+	}
+	maxParallel, err := strconv.Atoi(job.Strategy.MaxParallelString)
+	if err != nil || maxParallel < 0 {
+		log.Warn("ignore invalid max-parallel %q of job %q", job.Strategy.MaxParallelString, job.Name)
+		return 0
+	}
+	return maxParallel
+}
+
+// parseFailFast returns `strategy.fail-fast` of the leg of a matrix job, which is true by default like GitHub,
+// it's false for the jobs without a matrix. Expressions are not supported yet, so they are treated as true.
+func parseFailFast(job *jobparser.Job) bool {
+	if job.Strategy.RawMatrix.Kind == 0 {
+		return false
+	}
+	if job.Strategy.FailFastString == "" {
+		return true
+	}
+	failFast, err := strconv.ParseBool(job.Strategy.FailFastString)
+	if err != nil {
+		log.Warn("ignore invalid fail-fast %q of job %q", job.Strategy.FailFastString, job.Name)
+		return true
+	}
+	return failFast
+}
+
+// resolveContinueOnErrors returns the raw `continue-on-error` of the jobs in the workflow content keyed by job id,
+// the jobs without it are not included.
+func resolveContinueOnErrors(content []byte) map[string]string {
+	var workflow struct {
+		Jobs map[string]struct {
+			ContinueOnError string `yaml:"continue-on-error"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		log.Warn("unable to parse continue-on-error of workflow: %v", err)
+		return nil
+	}
+	ret := make(map[string]string, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		if job.ContinueOnError != "" {
+			ret[id] = job.ContinueOnError
+		}
+	}
+	return ret
+}
+
+// resolveEnvironments returns the lower-cased names of the `environment` of the jobs in the workflow content keyed by job id,
+// the `environment` could be a name or a mapping with `name`. The jobs without it are not included, and so are the jobs whose name
+// is an expression, since the environment secrets must be resolved when the run is created, before any context is available.
+func resolveEnvironments(content []byte) map[string]string {
+	var workflow struct {
+		Jobs map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		log.Warn("unable to parse environment of workflow: %v", err)
+		return nil
+	}
+	ret := make(map[string]string, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		var name string
+		switch job.Environment.Kind {
+		case yaml.ScalarNode:
+			name = job.Environment.Value
+		case yaml.MappingNode:
+			var v struct {
+				Name string `yaml:"name"`
+			}
+			if err := job.Environment.Decode(&v); err != nil {
+				log.Warn("unable to parse environment of job %q: %v", id, err)
+				continue
+			}
+			name = v.Name
+		}
+		if strings.Contains(name, "${{") {
+			log.Warn("the environment %q of job %q is an expression, its secrets are not available", name, id)
+			continue
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			ret[id] = name
+		}
+	}
+	return ret
+}
+
+// resolveEnvironmentURLs returns the `url` of the `environment` of the jobs in the workflow content keyed by job id,
+// the jobs without it are not included. The expressions in them are evaluated by the services once the contexts are known.
+func resolveEnvironmentURLs(content []byte) map[string]string {
+	var workflow struct {
+		Jobs map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		log.Warn("unable to parse environment of workflow: %v", err)
+		return nil
+	}
+	ret := make(map[string]string, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		if job.Environment.Kind != yaml.MappingNode {
+			continue
+		}
+		var v struct {
+			URL string `yaml:"url"`
+		}
+		if err := job.Environment.Decode(&v); err != nil {
+			log.Warn("unable to parse environment of job %q: %v", id, err)
+			continue
+		}
+		if url := strings.TrimSpace(v.URL); url != "" {
+			ret[id] = url
+		}
+	}
+	return ret
+}
+
+// evaluateContinueOnError evaluates the raw `continue-on-error` of the leg of a job, like `true` or `${{ matrix.experimental }}`,
+// only the `matrix` context is available. It's false if it can't be evaluated, so the failure of the leg still cancels others.
+func evaluateContinueOnError(raw string, job *jobparser.Job) (ret bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false
+	}
+	if v, err := strconv.ParseBool(raw); err == nil {
+		return v
+	}
+	expr, ok := strings.CutPrefix(raw, "${{")
+	if expr, ok = strings.CutSuffix(expr, "}}"); !ok {
+		log.Warn("ignore invalid continue-on-error %q of job %q", raw, job.Name)
+		return false
+	}
+
+	matrix := map[string]any{}
+	if job.Strategy.RawMatrix.Kind != 0 {
+		// the matrix of a leg has only one value of each key
+		var values map[string][]any
+		if err := job.Strategy.RawMatrix.Decode(&values); err != nil {
+			log.Warn("ignore continue-on-error %q of job %q since its matrix can't be decoded: %v", raw, job.Name, err)
+			return false
+		}
+		for k, v := range values {
+			if len(v) > 0 {
+				matrix[k] = v[0]
+			}
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warn("ignore continue-on-error %q of job %q: %v", raw, job.Name, r)
+			ret = false
+		}
+	}()
+	interpreter := exprparser.NewInterpeter(&exprparser.EvaluationEnvironment{Matrix: matrix}, exprparser.Config{
+		Run:     &model.Run{Workflow: &model.Workflow{}},
+		Context: "job",
+	})
+	v, err := interpreter.Evaluate(strings.TrimSpace(expr), exprparser.DefaultStatusCheckNone)
+	if err != nil {
+		log.Warn("ignore continue-on-error %q of job %q: %v", raw, job.Name, err)
+		return false
+	}
+	return exprparser.IsTruthy(v)
+}
+
+// isStaticallySkipped reports whether the `if` of the job is always false, like `if: false` or `if: ${{ false }}`,
+// so the job is skipped once the run is created instead of waiting for a runner. Other expressions are evaluated by runners.
+func isStaticallySkipped(job *jobparser.Job) bool {
+	cond := strings.TrimSpace(job.If.Value)
+	if expr, ok := strings.CutPrefix(cond, "${{"); ok {
+		if expr, ok = strings.CutSuffix(expr, "}}"); ok {
+			cond = strings.TrimSpace(expr)
+		}
+	}
+	return cond == "false"
+}
+
+// isMatrixLegThrottled reports whether the job has to wait since as many legs of the matrix job as max-parallel are running,
+// the legs which have been cancelled, like by fail-fast, don't count.
+func isMatrixLegThrottled(ctx context.Context, job *ActionRunJob) (bool, error) {
+	if job.MaxParallel <= 0 {
+		return false, nil
+	}
+	running, err := db.GetEngine(ctx).Where("run_id=? AND job_id=? AND status=?", job.RunID, job.JobID, StatusRunning).Count(new(ActionRunJob))
+	if err != nil {
+		return false, err
+	}
+	return running >= int64(job.MaxParallel), nil
+}
+
 func (job *ActionRunJob) Duration() time.Duration {
 	return calculateDuration(job.Started, job.Stopped, job.Status)
 }
@@ -94,6 +394,18 @@ func GetRunJobsByRunID(ctx context.Context, runID int64) ([]*ActionRunJob, error
 func UpdateRunJob(ctx context.Context, job *ActionRunJob, cond builder.Cond, cols ...string) (int64, error) {
 	e := db.GetEngine(ctx)
 
+	if slices.Contains(cols, "status") && !slices.Contains(cols, "queued") {
+		// the job is ready to run once it's waiting, like after its needs are done or it's rerun,
+		// and it's not ready any longer once it's blocked again
+		if job.Status.IsWaiting() {
+			job.Queued = timeutil.TimeStampNow()
+			cols = append(slices.Clone(cols), "queued")
+		} else if job.Status.IsBlocked() {
+			job.Queued = 0
+			cols = append(slices.Clone(cols), "queued")
+		}
+	}
+
 	sess := e.ID(job.ID)
 	if len(cols) > 0 {
 		sess.Cols(cols...)
@@ -126,6 +438,13 @@ func UpdateRunJob(ctx context.Context, job *ActionRunJob, cond builder.Cond, col
 		}
 	}
 
+	if job.MaxParallel > 0 && job.Status.IsDone() {
+		// a leg of the matrix job is done, so the legs throttled by max-parallel may run now
+		if err := IncreaseTaskVersion(ctx, job.OwnerID, job.RepoID); err != nil {
+			return 0, err
+		}
+	}
+
 	{
 		// Other goroutines may aggregate the status of the run and update it too.
 		// So we need load the run and its jobs before updating the run.
@@ -141,12 +460,19 @@ func UpdateRunJob(ctx context.Context, job *ActionRunJob, cond builder.Cond, col
 		if run.Started.IsZero() && run.Status.IsRunning() {
 			run.Started = timeutil.TimeStampNow()
 		}
+		succeeded := false
 		if run.Stopped.IsZero() && run.Status.IsDone() {
 			run.Stopped = timeutil.TimeStampNow()
+			succeeded = run.Status.IsSuccess()
 		}
 		if err := UpdateRun(ctx, run, "status", "started", "stopped"); err != nil {
 			return 0, fmt.Errorf("update run %d: %w", run.ID, err)
 		}
+		if succeeded {
+			if err := refreshEstimatedDurations(ctx, run.RepoID, run.WorkflowID); err != nil {
+				return 0, fmt.Errorf("refresh estimated durations of workflow %q: %w", run.WorkflowID, err)
+			}
+		}
 	}
 
 	return affected, nil
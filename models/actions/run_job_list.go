@@ -59,6 +59,8 @@ type FindRunJobOptions struct {
 	CommitSHA     string
 	Statuses      []Status
 	UpdatedBefore timeutil.TimeStamp
+	// GateDeadlineBefore finds the gates which have been reached but not decided before the deadline
+	GateDeadlineBefore timeutil.TimeStamp
 }
 
 func (opts FindRunJobOptions) ToConds() builder.Cond {
@@ -81,5 +83,8 @@ func (opts FindRunJobOptions) ToConds() builder.Cond {
 	if opts.UpdatedBefore > 0 {
 		cond = cond.And(builder.Lt{"updated": opts.UpdatedBefore})
 	}
+	if opts.GateDeadlineBefore > 0 {
+		cond = cond.And(builder.Gt{"gate_deadline": 0}, builder.Lt{"gate_deadline": opts.GateDeadlineBefore})
+	}
 	return cond
 }
@@ -0,0 +1,274 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMaxParallel(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		want     int
+	}{
+		{
+			name:     "unset",
+			strategy: "",
+			want:     0,
+		},
+		{
+			name:     "number",
+			strategy: "      max-parallel: 2\n",
+			want:     2,
+		},
+		{
+			name:     "with fail-fast",
+			strategy: "      fail-fast: false\n      max-parallel: 1\n",
+			want:     1,
+		},
+		{
+			name:     "expression",
+			strategy: "      max-parallel: ${{ vars.MAX_PARALLEL }}\n",
+			want:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n" + tt.strategy +
+				"      matrix:\n        version: [1, 2, 3]\n    steps:\n      - run: echo ${{ matrix.version }}\n"
+			workflows, err := jobparser.Parse([]byte(content))
+			require.NoError(t, err)
+			// every leg of the matrix job keeps the value
+			require.Len(t, workflows, 3)
+			for _, wf := range workflows {
+				_, job := wf.Job()
+				assert.Equal(t, tt.want, parseMaxParallel(job))
+			}
+		})
+	}
+}
+
+func Test_parseFailFast(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{
+			name:    "default",
+			content: "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n      matrix:\n        version: [1, 2]\n    steps:\n      - run: make test\n",
+			want:    true,
+		},
+		{
+			name:    "disabled",
+			content: "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n      fail-fast: false\n      matrix:\n        version: [1, 2]\n    steps:\n      - run: make test\n",
+			want:    false,
+		},
+		{
+			name:    "without matrix",
+			content: "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make test\n",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflows, err := jobparser.Parse([]byte(tt.content))
+			require.NoError(t, err)
+			for _, wf := range workflows {
+				_, job := wf.Job()
+				assert.Equal(t, tt.want, parseFailFast(job))
+			}
+		})
+	}
+}
+
+func Test_evaluateContinueOnError(t *testing.T) {
+	content := []byte(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        version: [1, 2]
+        experimental: [false]
+        include:
+          - version: 3
+            experimental: true
+    steps:
+      - run: make test
+  lint:
+    runs-on: ubuntu-latest
+    continue-on-error: true
+    steps:
+      - run: make lint
+  build:
+    runs-on: ubuntu-latest
+    continue-on-error: ${{ unknown }}
+    steps:
+      - run: make build
+`)
+	continueOnErrors := resolveContinueOnErrors(content)
+	workflows, err := jobparser.Parse(content)
+	require.NoError(t, err)
+	got := map[string]bool{}
+	for _, wf := range workflows {
+		id, job := wf.Job()
+		got[job.Name] = evaluateContinueOnError(continueOnErrors[id], job)
+	}
+	assert.Equal(t, map[string]bool{
+		"test (false, 1)": false,
+		"test (false, 2)": false,
+		"test (true, 3)":  true,
+		"lint":            true,
+		"build":           false,
+	}, got)
+}
+
+func Test_resolveEnvironments(t *testing.T) {
+	content := []byte(`on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    environment: Production
+    steps:
+      - run: make deploy
+  release:
+    runs-on: ubuntu-latest
+    environment:
+      name: staging
+      url: https://staging.example.com
+    steps:
+      - run: make release
+  preview:
+    runs-on: ubuntu-latest
+    environment: ${{ github.ref_name }}
+    steps:
+      - run: make preview
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	assert.Equal(t, map[string]string{
+		"deploy":  "production",
+		"release": "staging",
+	}, resolveEnvironments(content))
+}
+
+func Test_resolveEnvironmentURLs(t *testing.T) {
+	content := []byte(`on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - run: make deploy
+  release:
+    runs-on: ubuntu-latest
+    environment:
+      name: staging
+      url: https://staging.example.com
+    steps:
+      - run: make release
+  preview:
+    runs-on: ubuntu-latest
+    environment:
+      name: preview
+      url: ${{ steps.deploy.outputs.url }}
+    steps:
+      - id: deploy
+        run: make preview
+`)
+	assert.Equal(t, map[string]string{
+		"release": "https://staging.example.com",
+		"preview": "${{ steps.deploy.outputs.url }}",
+	}, resolveEnvironmentURLs(content))
+}
+
+func Test_isMatrixLegThrottled(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	legs := make([]*ActionRunJob, 0, 3)
+	for _, status := range []Status{StatusRunning, StatusRunning, StatusWaiting} {
+		leg := &ActionRunJob{RunID: 1000, JobID: "test", MaxParallel: 2, Status: status}
+		// insert the legs one by one, so their IDs are set
+		require.NoError(t, db.Insert(db.DefaultContext, leg))
+		legs = append(legs, leg)
+	}
+
+	throttled, err := isMatrixLegThrottled(db.DefaultContext, legs[2])
+	require.NoError(t, err)
+	assert.True(t, throttled)
+
+	// a leg cancelled like by fail-fast doesn't take a slot anymore
+	legs[0].Status = StatusCancelled
+	_, err = db.GetEngine(db.DefaultContext).ID(legs[0].ID).Cols("status").Update(legs[0])
+	require.NoError(t, err)
+	throttled, err = isMatrixLegThrottled(db.DefaultContext, legs[2])
+	require.NoError(t, err)
+	assert.False(t, throttled)
+
+	// unlimited
+	legs[2].MaxParallel = 0
+	throttled, err = isMatrixLegThrottled(db.DefaultContext, legs[2])
+	require.NoError(t, err)
+	assert.False(t, throttled)
+}
+
+func Test_isStaticallySkipped(t *testing.T) {
+	content := []byte(`on: push
+jobs:
+  disabled:
+    if: false
+    runs-on: ubuntu-latest
+    steps:
+      - run: make disabled
+  disabled-expression:
+    if: ${{ false }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: make disabled
+  conditional:
+    if: ${{ github.ref == 'refs/heads/main' }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: make conditional
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	workflows, err := jobparser.Parse(content)
+	require.NoError(t, err)
+	got := map[string]bool{}
+	for _, wf := range workflows {
+		id, job := wf.Job()
+		got[id] = isStaticallySkipped(job)
+	}
+	assert.Equal(t, map[string]bool{
+		"disabled":            true,
+		"disabled-expression": true,
+		"conditional":         false,
+		"test":                false,
+	}, got)
+}
+
+func TestActionRunJob_EffectiveRunsOn(t *testing.T) {
+	job := &ActionRunJob{RunsOn: []string{"ubuntu-latest"}}
+	assert.Equal(t, []string{"ubuntu-latest"}, job.EffectiveRunsOn())
+
+	// the requested labels are kept after falling back to the default label
+	job.FallbackRunsOn = "self-hosted"
+	assert.Equal(t, []string{"self-hosted"}, job.EffectiveRunsOn())
+	assert.Equal(t, []string{"ubuntu-latest"}, job.RunsOn)
+}
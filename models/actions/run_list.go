@@ -10,6 +10,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/util"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 
 	"xorm.io/builder"
@@ -70,11 +71,15 @@ type FindRunOptions struct {
 	RepoID        int64
 	OwnerID       int64
 	WorkflowID    string
+	WorkflowDir   string // the directory of the workflow, the runs whose directory is unknown are included
 	Ref           string // the commit/tag/… that caused this workflow
 	TriggerUserID int64
 	TriggerEvent  webhook_module.HookEventType
-	Approved      bool // not util.OptionalBool, it works only when it's true
+	Approved      bool // approved explicitly rather than by the approval label, not util.OptionalBool, it works only when it's true
 	Status        []Status
+	IssueID       int64 // the issue or the pull request which the event is about
+	IsProduction  util.OptionalBool
+	DeliveryID    string // the ID of the upstream webhook delivery which the trigger event originates from
 }
 
 func (opts FindRunOptions) ToConds() builder.Cond {
@@ -88,11 +93,14 @@ func (opts FindRunOptions) ToConds() builder.Cond {
 	if opts.WorkflowID != "" {
 		cond = cond.And(builder.Eq{"workflow_id": opts.WorkflowID})
 	}
+	if opts.WorkflowDir != "" {
+		cond = cond.And(builder.In("workflow_dir", opts.WorkflowDir, ""))
+	}
 	if opts.TriggerUserID > 0 {
 		cond = cond.And(builder.Eq{"trigger_user_id": opts.TriggerUserID})
 	}
 	if opts.Approved {
-		cond = cond.And(builder.Gt{"approved_by": 0})
+		cond = cond.And(builder.Gt{"approved_by": 0}, builder.Eq{"approved_by_label": false})
 	}
 	if len(opts.Status) > 0 {
 		cond = cond.And(builder.In("status", opts.Status))
@@ -100,9 +108,18 @@ func (opts FindRunOptions) ToConds() builder.Cond {
 	if opts.Ref != "" {
 		cond = cond.And(builder.Eq{"ref": opts.Ref})
 	}
+	if opts.DeliveryID != "" {
+		cond = cond.And(builder.Eq{"delivery_id": opts.DeliveryID})
+	}
 	if opts.TriggerEvent != "" {
 		cond = cond.And(builder.Eq{"trigger_event": opts.TriggerEvent})
 	}
+	if opts.IssueID > 0 {
+		cond = cond.And(builder.Eq{"issue_id": opts.IssueID})
+	}
+	if !opts.IsProduction.IsNone() {
+		cond = cond.And(builder.Eq{"is_production": opts.IsProduction.IsTrue()})
+	}
 	return cond
 }
 
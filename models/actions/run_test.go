@@ -0,0 +1,137 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertRun_NoJobs(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	run := &ActionRun{RepoID: 4, OwnerID: 1, WorkflowID: "empty.yaml", Status: StatusWaiting}
+	err := InsertRun(db.DefaultContext, run, []byte("on: push\njobs: {}\n"), nil, nil, nil, nil, nil)
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	unittest.AssertNotExistsBean(t, &ActionRun{RepoID: 4, WorkflowID: "empty.yaml"})
+}
+
+func TestInsertRun_AllJobsSkipped(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	content := []byte(`on: push
+jobs:
+  build:
+    if: false
+    runs-on: ubuntu-latest
+    steps:
+      - run: make build
+  test:
+    if: ${{ false }}
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	jobs, err := jobparser.Parse(content)
+	require.NoError(t, err)
+	run := &ActionRun{RepoID: 4, OwnerID: 1, WorkflowID: "skipped.yaml", Status: StatusWaiting, Repo: &repo_model.Repository{ID: 4}}
+	require.NoError(t, InsertRun(db.DefaultContext, run, content, jobs, nil, nil, nil, nil))
+
+	// the run is concluded instead of waiting forever
+	run = unittest.AssertExistsAndLoadBean(t, &ActionRun{ID: run.ID})
+	assert.Equal(t, StatusSkipped, run.Status)
+	assert.False(t, run.Stopped.IsZero())
+	runJobs, err := GetRunJobsByRunID(db.DefaultContext, run.ID)
+	require.NoError(t, err)
+	require.Len(t, runJobs, 2)
+	for _, job := range runJobs {
+		assert.Equal(t, StatusSkipped, job.Status)
+	}
+}
+
+func TestInsertRun_Services(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	content := []byte(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    services:
+      redis:
+        image: redis:7
+        health:
+          cmd: redis-cli ping
+    steps:
+      - run: make test
+`)
+	jobs, err := jobparser.Parse(content)
+	require.NoError(t, err)
+	services := map[string][]*JobService{
+		"test": {{Name: "redis", Image: "redis:7", Options: "--health-cmd 'redis-cli ping'", Health: &ServiceHealth{Cmd: "redis-cli ping"}}},
+	}
+	run := &ActionRun{RepoID: 4, OwnerID: 1, WorkflowID: "services.yaml", Status: StatusWaiting, Repo: &repo_model.Repository{ID: 4}}
+	require.NoError(t, InsertRun(db.DefaultContext, run, content, jobs, nil, nil, nil, services))
+
+	runJobs, err := GetRunJobsByRunID(db.DefaultContext, run.ID)
+	require.NoError(t, err)
+	require.Len(t, runJobs, 1)
+	assert.Equal(t, services["test"], runJobs[0].Services)
+	// the health check declared by `health` is passed to the runner by the options
+	swf, err := jobparser.Parse(runJobs[0].WorkflowPayload)
+	require.NoError(t, err)
+	_, job := swf[0].Job()
+	assert.Equal(t, "--health-cmd 'redis-cli ping'", job.Services["redis"].Options)
+}
+
+func TestFindTimedOutRuns(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	started := now.AddDuration(-time.Hour)
+	timedOut := &ActionRun{RepoID: 4, Index: 1001, Timeout: 30 * time.Minute, Started: started, Status: StatusRunning}
+	inTime := &ActionRun{RepoID: 4, Index: 1002, Timeout: 2 * time.Hour, Started: started, Status: StatusRunning}
+	unlimited := &ActionRun{RepoID: 4, Index: 1003, Started: started, Status: StatusRunning}
+	// the timeout counts from the time the first job started
+	notStarted := &ActionRun{RepoID: 4, Index: 1004, Timeout: time.Minute, Status: StatusWaiting}
+	done := &ActionRun{RepoID: 4, Index: 1005, Timeout: 30 * time.Minute, Started: started, Stopped: now, Status: StatusSuccess}
+	for _, run := range []*ActionRun{timedOut, inTime, unlimited, notStarted, done} {
+		require.NoError(t, db.Insert(db.DefaultContext, run))
+	}
+
+	runs, err := FindTimedOutRuns(db.DefaultContext, now)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, timedOut.ID, runs[0].ID)
+}
+
+func TestInsertRun_Fingerprint(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	content := []byte("on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make build\n")
+	insert := func() (*ActionRun, error) {
+		jobs, err := jobparser.Parse(content)
+		require.NoError(t, err)
+		run := &ActionRun{RepoID: 4, OwnerID: 1, WorkflowID: "build.yaml", Status: StatusWaiting, Fingerprint: "fingerprint", Repo: &repo_model.Repository{ID: 4}}
+		return run, InsertRun(db.DefaultContext, run, content, jobs, nil, nil, nil, nil)
+	}
+
+	run, err := insert()
+	require.NoError(t, err)
+	// the run inserted by a previous attempt is returned instead of a duplicate run
+	existing, err := insert()
+	assert.ErrorIs(t, err, ErrRunFingerprintExists)
+	assert.Equal(t, run.ID, existing.ID)
+	unittest.AssertCount(t, &ActionRun{RepoID: 4, Fingerprint: "fingerprint"}, 1)
+}
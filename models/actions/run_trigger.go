@@ -0,0 +1,26 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+// RunTriggerMatch is how the trigger event of a run matched the event, it tells why the run was created.
+// It's compact since it's stored with every run, the empty fields are omitted.
+type RunTriggerMatch struct {
+	Event        string `json:"event"`                   // the event in `on` of the workflow, the same as ActionRun.TriggerEvent
+	ActivityType string `json:"activity_type,omitempty"` // the activity type of the event, like "opened" of pull_request or "published" of release
+	Ref          string `json:"ref,omitempty"`           // the branch or the tag which is matched by the `branches` or the `tags` filters
+	Path         string `json:"path,omitempty"`          // a changed file which is matched by the `paths` or the `paths-ignore` filters
+	Schedule     string `json:"schedule,omitempty"`      // the cron spec which fired, empty if the schedule was run manually
+}
+
+// RunDetectionTrace explains why the run was created, it's stored with the run if setting.Actions.DetectionTrace is enabled.
+// It's compact and never contains the event payload, since it's visible to the admins of the repository.
+type RunDetectionTrace struct {
+	Workflow     string           `json:"workflow"`                // the path of the workflow, like ".gitea/workflows/test.yml"
+	Event        string           `json:"event"`                   // the webhook event which triggered the run
+	Match        *RunTriggerMatch `json:"match,omitempty"`         // how the trigger event matched the event
+	Trusted      bool             `json:"trusted"`                 // whether the run has the access of the repository, false for the runs of the pull requests from forks
+	NeedApproval bool             `json:"need_approval"`           // whether the run needed approval when it was created
+	Approval     []string         `json:"approval,omitempty"`      // the checks which decided the approval, like "fork_pull_request" or "production"
+	Policy       string           `json:"policy_reason,omitempty"` // the reason of the policy webhook which allowed the run
+}
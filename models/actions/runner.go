@@ -56,6 +56,16 @@ const (
 	RunnerIdleTime    = 10 * time.Second
 )
 
+// CanMatchLabels reports whether the runner has all the labels which the job runs on
+func (r *ActionRunner) CanMatchLabels(jobRunsOn []string) bool {
+	return isSubset(r.AgentLabels, jobRunsOn)
+}
+
+// IsHosted reports whether the runner belongs to the instance, which is regarded as the shared hosted pool
+func (r *ActionRunner) IsHosted() bool {
+	return r.OwnerID == 0 && r.RepoID == 0
+}
+
 // BelongsToOwnerName before calling, should guarantee that all attributes are loaded
 func (r *ActionRunner) BelongsToOwnerName() string {
 	if r.RepoID != 0 {
@@ -6,15 +6,18 @@ package actions
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 
 	"github.com/robfig/cron/v3"
+	"xorm.io/builder"
 )
 
 // ActionSchedule represents a schedule of a workflow file
@@ -26,6 +29,8 @@ type ActionSchedule struct {
 	Repo          *repo_model.Repository `xorm:"-"`
 	OwnerID       int64                  `xorm:"index"`
 	WorkflowID    string
+	WorkflowDir   string `xorm:"NOT NULL DEFAULT ''"` // the directory of the workflow file, like ".gitea/workflows"
+	WorkflowSHA   string `xorm:"VARCHAR(64)"`         // the git blob SHA of the workflow file
 	TriggerUserID int64
 	TriggerUser   *user_model.User `xorm:"-"`
 	Ref           string
@@ -33,6 +38,13 @@ type ActionSchedule struct {
 	Event         webhook_module.HookEventType
 	EventPayload  string `xorm:"LONGTEXT"`
 	Content       []byte
+	Disabled      bool               `xorm:"NOT NULL DEFAULT false"` // the scheduler skips the disabled schedule, it's kept after re-registering if the specs are unchanged
+	Paused        bool               `xorm:"NOT NULL DEFAULT false"` // the schedules of the workflow are paused by the operators, they are disabled until resumed even if they are re-registered with other specs
+	PausedUnix    timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`     // when the schedules of the workflow were paused, zero if they aren't paused
+	SkippedUnix   timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`     // when a spec fired last without a run since the `schedule-if` of the workflow was false
+	SkipReason    string             `xorm:"TEXT"`                   // the condition of `schedule-if` which was false when the schedule was skipped last
+	Deferred      bool               `xorm:"NOT NULL DEFAULT false"` // the scheduler skips the schedule since the repository doesn't meet [actions] SCHEDULE_REQUIREMENTS, until it does
+	SameNamed     []string           `xorm:"JSON TEXT"`              // the paths of the other workflows with the same `name`, see ActionRun.SameNamed
 	Created       timeutil.TimeStamp `xorm:"created"`
 	Updated       timeutil.TimeStamp `xorm:"updated"`
 }
@@ -53,8 +65,86 @@ func GetReposMapByIDs(ctx context.Context, ids []int64) (map[int64]*repo_model.R
 	return repos, db.GetEngine(ctx).In("id", ids).Find(&repos)
 }
 
+// SpecsKey identifies the workflow and the cron specs of the schedule,
+// it's used to preserve the disabled state when the schedules are re-registered with the same specs.
+func (s *ActionSchedule) SpecsKey() string {
+	return s.WorkflowID + "\n" + strings.Join(s.Specs, "\n")
+}
+
+// GetScheduleByRepoAndID returns the schedule of the repository
+func GetScheduleByRepoAndID(ctx context.Context, repoID, scheduleID int64) (*ActionSchedule, error) {
+	var schedule ActionSchedule
+	has, err := db.GetEngine(ctx).Where("id=? AND repo_id=?", scheduleID, repoID).Get(&schedule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("schedule with id %d: %w", scheduleID, util.ErrNotExist)
+	}
+	return &schedule, nil
+}
+
+// SetScheduleDisabled enables or disables the schedule of the repository
+func SetScheduleDisabled(ctx context.Context, repoID, scheduleID int64, disabled bool) error {
+	has, err := db.GetEngine(ctx).Where("id=? AND repo_id=?", scheduleID, repoID).Exist(&ActionSchedule{})
+	if err != nil {
+		return err
+	} else if !has {
+		return fmt.Errorf("schedule with id %d: %w", scheduleID, util.ErrNotExist)
+	}
+	_, err = db.GetEngine(ctx).ID(scheduleID).Cols("disabled").Update(&ActionSchedule{Disabled: disabled})
+	return err
+}
+
+// SetScheduleSkipped records that a spec of the schedule fired without a run since the condition was false
+func SetScheduleSkipped(ctx context.Context, scheduleID int64, reason string) error {
+	_, err := db.GetEngine(ctx).ID(scheduleID).NoAutoTime().Cols("skipped_unix", "skip_reason").
+		Update(&ActionSchedule{SkippedUnix: timeutil.TimeStampNow(), SkipReason: reason})
+	return err
+}
+
+// SetWorkflowSchedulesPaused pauses or resumes all schedules of the workflow of the repository, the paused schedules are disabled.
+// It returns the schedules as they were before, so the caller knows since when they were paused.
+func SetWorkflowSchedulesPaused(ctx context.Context, repoID int64, workflowID string, paused bool) ([]*ActionSchedule, error) {
+	var schedules []*ActionSchedule
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		schedules, err = db.Find[ActionSchedule](ctx, FindScheduleOptions{RepoID: repoID, WorkflowID: workflowID})
+		if err != nil {
+			return err
+		} else if len(schedules) == 0 {
+			return fmt.Errorf("schedules of workflow %q: %w", workflowID, util.ErrNotExist)
+		}
+
+		update := &ActionSchedule{Disabled: paused, Paused: paused}
+		if paused {
+			update.PausedUnix = timeutil.TimeStampNow()
+		}
+		for _, schedule := range schedules {
+			if paused && schedule.Paused {
+				// keep the time it was paused first
+				continue
+			}
+			if _, err := db.GetEngine(ctx).ID(schedule.ID).Cols("disabled", "paused", "paused_unix").Update(update); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return schedules, err
+}
+
+// SetRepoSchedulesDeferred defers or resumes all schedules of the repository, it returns the number of the schedules which have changed
+func SetRepoSchedulesDeferred(ctx context.Context, repoID int64, deferred bool) (int64, error) {
+	return db.GetEngine(ctx).Where("repo_id=? AND deferred=?", repoID, !deferred).Cols("deferred").Update(&ActionSchedule{Deferred: deferred})
+}
+
 var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 
+// ParseScheduleSpec parses the cron spec of `on.schedule` like the schedules are created
+func ParseScheduleSpec(spec string) (cron.Schedule, error) {
+	return cronParser.Parse(spec)
+}
+
 // CreateScheduleTask creates new schedule task.
 func CreateScheduleTask(ctx context.Context, rows []*ActionSchedule) error {
 	// Return early if there are no rows to insert
@@ -91,7 +181,7 @@ func CreateScheduleTask(ctx context.Context, rows []*ActionSchedule) error {
 				RepoID:     row.RepoID,
 				ScheduleID: row.ID,
 				Spec:       spec,
-				Next:       timeutil.TimeStamp(schedule.Next(now).Unix()),
+				Next:       timeutil.TimeStamp(NextScheduleTime(schedule, now, ScheduleJitter(row.RepoID, row.WorkflowID)).Unix()),
 			}); err != nil {
 				return err
 			}
@@ -102,6 +192,26 @@ func CreateScheduleTask(ctx context.Context, rows []*ActionSchedule) error {
 	return committer.Commit()
 }
 
+// UpdateScheduleOwnerByRepo updates the owner of the schedules of the repository, since it could be transferred to another owner
+func UpdateScheduleOwnerByRepo(ctx context.Context, repoID, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("repo_id=? AND owner_id<>?", repoID, ownerID).Cols("owner_id").Update(&ActionSchedule{OwnerID: ownerID})
+}
+
+// DeleteScheduleTaskByOwner deletes the schedules of all repositories of the owner with their specs, and returns how many schedules are deleted
+func DeleteScheduleTaskByOwner(ctx context.Context, ownerID int64) (int64, error) {
+	var deleted int64
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where(builder.In("schedule_id", builder.Select("id").From("action_schedule").Where(builder.Eq{"owner_id": ownerID}))).
+			Delete(&ActionScheduleSpec{}); err != nil {
+			return err
+		}
+		var err error
+		deleted, err = db.GetEngine(ctx).Where(builder.Eq{"owner_id": ownerID}).Delete(&ActionSchedule{})
+		return err
+	})
+	return deleted, err
+}
+
 func DeleteScheduleTaskByRepo(ctx context.Context, id int64) error {
 	ctx, committer, err := db.TxContext(ctx)
 	if err != nil {
@@ -120,11 +230,16 @@ func DeleteScheduleTaskByRepo(ctx context.Context, id int64) error {
 	return committer.Commit()
 }
 
-func CleanRepoScheduleTasks(ctx context.Context, repo *repo_model.Repository) error {
+// CleanRepoScheduleTasks deletes the schedules of the repository and cancels the running scheduled jobs, it returns the deleted schedules
+func CleanRepoScheduleTasks(ctx context.Context, repo *repo_model.Repository) ([]*ActionSchedule, error) {
+	schedules, err := db.Find[ActionSchedule](ctx, FindScheduleOptions{RepoID: repo.ID})
+	if err != nil {
+		return nil, fmt.Errorf("FindSchedules: %v", err)
+	}
 	// If actions disabled when there is schedule task, this will remove the outdated schedule tasks
 	// There is no other place we can do this because the app.ini will be changed manually
 	if err := DeleteScheduleTaskByRepo(ctx, repo.ID); err != nil {
-		return fmt.Errorf("DeleteCronTaskByRepo: %v", err)
+		return nil, fmt.Errorf("DeleteCronTaskByRepo: %v", err)
 	}
 	// cancel running cron jobs of this repository and delete old schedules
 	if err := CancelRunningJobs(
@@ -134,7 +249,7 @@ func CleanRepoScheduleTasks(ctx context.Context, repo *repo_model.Repository) er
 		"",
 		webhook_module.HookEventSchedule,
 	); err != nil {
-		return fmt.Errorf("CancelRunningJobs: %v", err)
+		return nil, fmt.Errorf("CancelRunningJobs: %v", err)
 	}
-	return nil
+	return schedules, nil
 }
@@ -63,8 +63,9 @@ func (schedules ScheduleList) LoadRepos(ctx context.Context) error {
 
 type FindScheduleOptions struct {
 	db.ListOptions
-	RepoID  int64
-	OwnerID int64
+	RepoID     int64
+	OwnerID    int64
+	WorkflowID string
 }
 
 func (opts FindScheduleOptions) ToConds() builder.Cond {
@@ -75,6 +76,9 @@ func (opts FindScheduleOptions) ToConds() builder.Cond {
 	if opts.OwnerID > 0 {
 		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
 	}
+	if opts.WorkflowID != "" {
+		cond = cond.And(builder.Eq{"workflow_id": opts.WorkflowID})
+	}
 
 	return cond
 }
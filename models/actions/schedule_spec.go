@@ -5,9 +5,13 @@ package actions
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/robfig/cron/v3"
@@ -36,6 +40,29 @@ func (s *ActionScheduleSpec) Parse() (cron.Schedule, error) {
 	return cronParser.Parse(s.Spec)
 }
 
+// ScheduleJitter returns the delay of the fires of the schedules of the workflow of the repository, within setting.Actions.ScheduleJitter.
+// It's derived from the hash of the repository and the workflow, so the schedules of different workflows firing at the same time are spread,
+// while the fire times of every workflow are stable.
+func ScheduleJitter(repoID int64, workflowID string) time.Duration {
+	seconds := uint64(setting.Actions.ScheduleJitter / time.Second)
+	if seconds == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s", repoID, workflowID)
+	return time.Duration(h.Sum64()%seconds) * time.Second
+}
+
+// NextScheduleTime returns the next fire time of the schedule after the time, which is the next cron time delayed by the jitter,
+// so it's never earlier than the cron time. It returns the zero time if the schedule is unsatisfiable.
+func NextScheduleTime(schedule cron.Schedule, after time.Time, jitter time.Duration) time.Time {
+	next := schedule.Next(after.Add(-jitter))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(jitter)
+}
+
 func init() {
 	db.RegisterModel(new(ActionScheduleSpec))
 }
@@ -0,0 +1,147 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/builder"
+)
+
+func TestDeleteScheduleTaskByOwner(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, CreateScheduleTask(db.DefaultContext, []*ActionSchedule{
+		{RepoID: 1, OwnerID: 2, WorkflowID: "nightly.yml", Specs: []string{"0 1 * * *", "0 2 * * *"}},
+		{RepoID: 2, OwnerID: 2, WorkflowID: "nightly.yml", Specs: []string{"0 1 * * *"}},
+		{RepoID: 3, OwnerID: 3, WorkflowID: "nightly.yml", Specs: []string{"0 1 * * *"}},
+	}))
+
+	deleted, err := DeleteScheduleTaskByOwner(db.DefaultContext, 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+	assert.Zero(t, unittest.GetCount(t, &ActionSchedule{OwnerID: 2}))
+	assert.Zero(t, unittest.GetCount(t, &ActionScheduleSpec{RepoID: 1}))
+	assert.Zero(t, unittest.GetCount(t, &ActionScheduleSpec{RepoID: 2}))
+
+	// the schedules of other owners are kept
+	assert.Equal(t, 1, unittest.GetCount(t, &ActionSchedule{OwnerID: 3}))
+	assert.Equal(t, 1, unittest.GetCount(t, &ActionScheduleSpec{RepoID: 3}))
+}
+
+func TestSetWorkflowSchedulesPaused(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, CreateScheduleTask(db.DefaultContext, []*ActionSchedule{
+		{RepoID: 1, OwnerID: 2, WorkflowID: "nightly.yml", Specs: []string{"0 1 * * *"}},
+		{RepoID: 1, OwnerID: 2, WorkflowID: "weekly.yml", Specs: []string{"0 1 * * 0"}},
+	}))
+
+	_, err := SetWorkflowSchedulesPaused(db.DefaultContext, 1, "unknown.yml", true)
+	assert.ErrorIs(t, err, util.ErrNotExist)
+
+	_, err = SetWorkflowSchedulesPaused(db.DefaultContext, 1, "nightly.yml", true)
+	require.NoError(t, err)
+	nightly := unittest.AssertExistsAndLoadBean(t, &ActionSchedule{RepoID: 1, WorkflowID: "nightly.yml"})
+	assert.True(t, nightly.Paused)
+	assert.True(t, nightly.Disabled)
+	assert.NotZero(t, nightly.PausedUnix)
+
+	// other workflows are untouched
+	weekly := unittest.AssertExistsAndLoadBean(t, &ActionSchedule{RepoID: 1, WorkflowID: "weekly.yml"})
+	assert.False(t, weekly.Paused)
+	assert.False(t, weekly.Disabled)
+
+	// resuming returns the schedules as they were paused
+	schedules, err := SetWorkflowSchedulesPaused(db.DefaultContext, 1, "nightly.yml", false)
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.True(t, schedules[0].Paused)
+	assert.Equal(t, nightly.PausedUnix, schedules[0].PausedUnix)
+	nightly = unittest.AssertExistsAndLoadBean(t, &ActionSchedule{RepoID: 1, WorkflowID: "nightly.yml"})
+	assert.False(t, nightly.Paused)
+	assert.False(t, nightly.Disabled)
+	assert.Zero(t, nightly.PausedUnix)
+}
+
+func TestSetRepoSchedulesDeferred(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, CreateScheduleTask(db.DefaultContext, []*ActionSchedule{
+		{RepoID: 5, OwnerID: 2, WorkflowID: "nightly.yml", Specs: []string{"0 1 * * *"}},
+		{RepoID: 5, OwnerID: 2, WorkflowID: "weekly.yml", Specs: []string{"0 1 * * 0"}},
+		{RepoID: 6, OwnerID: 2, WorkflowID: "nightly.yml", Specs: []string{"0 1 * * *"}},
+	}))
+
+	n, err := SetRepoSchedulesDeferred(db.DefaultContext, 5, true)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	unittest.AssertCountByCond(t, "action_schedule", builder.Eq{"repo_id": 5, "deferred": true}, 2)
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &ActionSchedule{RepoID: 6}).Deferred)
+
+	// only the changed schedules are counted
+	n, err = SetRepoSchedulesDeferred(db.DefaultContext, 5, true)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = SetRepoSchedulesDeferred(db.DefaultContext, 5, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	unittest.AssertCountByCond(t, "action_schedule", builder.Eq{"repo_id": 5, "deferred": true}, 0)
+}
+
+func TestScheduleJitter(t *testing.T) {
+	defer test.MockVariableValue(&setting.Actions.ScheduleJitter, 0)()
+	assert.Zero(t, ScheduleJitter(1, "nightly.yml"))
+
+	setting.Actions.ScheduleJitter = 10 * time.Minute
+	jitters := make(map[time.Duration]bool)
+	for _, workflow := range []string{"nightly.yml", "daily.yml", "cleanup.yml", "report.yml"} {
+		jitter := ScheduleJitter(1, workflow)
+		assert.GreaterOrEqual(t, jitter, time.Duration(0))
+		assert.Less(t, jitter, 10*time.Minute)
+		// it's stable for the same workflow
+		assert.Equal(t, jitter, ScheduleJitter(1, workflow))
+		jitters[jitter] = true
+	}
+	assert.Greater(t, len(jitters), 1, "the workflows should be spread")
+	assert.NotEqual(t, ScheduleJitter(1, "nightly.yml"), ScheduleJitter(2, "nightly.yml"))
+}
+
+func TestNextScheduleTime(t *testing.T) {
+	daily, err := ParseScheduleSpec("0 0 * * *")
+	require.NoError(t, err)
+	every5Minutes, err := ParseScheduleSpec("*/5 * * * *")
+	require.NoError(t, err)
+	midnight := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule cron.Schedule
+		after    time.Time
+		jitter   time.Duration
+		expected time.Time
+	}{
+		{name: "no jitter", schedule: daily, after: midnight.Add(-time.Hour), expected: midnight},
+		{name: "delayed", schedule: daily, after: midnight.Add(-time.Hour), jitter: 3 * time.Minute, expected: midnight.Add(3 * time.Minute)},
+		{name: "cron time passed but not fired", schedule: daily, after: midnight.Add(time.Minute), jitter: 3 * time.Minute, expected: midnight.Add(3 * time.Minute)},
+		{name: "fired", schedule: daily, after: midnight.Add(4 * time.Minute), jitter: 3 * time.Minute, expected: midnight.Add(24*time.Hour + 3*time.Minute)},
+		{name: "jitter longer than interval", schedule: every5Minutes, after: midnight.Add(8 * time.Minute), jitter: 7 * time.Minute, expected: midnight.Add(12 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NextScheduleTime(tt.schedule, tt.after, tt.jitter).UTC())
+		})
+	}
+}
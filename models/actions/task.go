@@ -11,6 +11,7 @@ import (
 
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -40,6 +41,7 @@ type ActionTask struct {
 	OwnerID           int64  `xorm:"index"`
 	CommitSHA         string `xorm:"index"`
 	IsForkPullRequest bool
+	TokenPermission   perm.AccessMode `xorm:"NOT NULL DEFAULT 2"` // the access mode of the token requested by the workflow, see TokenAccessMode
 
 	Token          string `xorm:"-"`
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
@@ -53,6 +55,10 @@ type ActionTask struct {
 	LogIndexes   LogIndexes `xorm:"LONGBLOB"` // line number to offset
 	LogExpired   bool       // files that are too old will be deleted
 
+	LogArchivePath  string             // the path of the log in the archive storage, empty if it hasn't been archived
+	LogArchivedUnix timeutil.TimeStamp `xorm:"index"` // when the log was archived
+	LogPruned       bool               // the local copy of the archived log has been removed, so the log is read from the archive storage
+
 	Created timeutil.TimeStamp `xorm:"created"`
 	Updated timeutil.TimeStamp `xorm:"updated index"`
 }
@@ -214,6 +220,23 @@ func GetRunningTaskByToken(ctx context.Context, token string) (*ActionTask, erro
 	return nil, errNotExist
 }
 
+// hasOnlineSelfHostedRunnerMatched reports whether any online runner of the repository or the owner of the job can match its labels
+func hasOnlineSelfHostedRunnerMatched(ctx context.Context, job *ActionRunJob) (bool, error) {
+	var runners []*ActionRunner
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": job.RepoID}.Or(builder.Eq{"owner_id": job.OwnerID, "repo_id": 0}.And(builder.Neq{"owner_id": 0}))).
+		And(builder.Gt{"last_online": time.Now().Add(-RunnerOfflineTime).Unix()}).
+		Find(&runners); err != nil {
+		return false, err
+	}
+	for _, runner := range runners {
+		if runner.CanMatchLabels(job.EffectiveRunsOn()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask, bool, error) {
 	ctx, commiter, err := db.TxContext(ctx)
 	if err != nil {
@@ -229,6 +252,9 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 	} else if runner.OwnerID != 0 {
 		jobCond = builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": runner.OwnerID}))
 	}
+	if runner.IsHosted() && setting.Actions.HostedFallbackPolicy {
+		jobCond = builder.Eq{"hosted_fallback": true}
+	}
 	if jobCond.IsValid() {
 		jobCond = builder.In("run_id", builder.Select("id").From("action_run").Where(jobCond))
 	}
@@ -241,10 +267,70 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 	// TODO: a more efficient way to filter labels
 	var job *ActionRunJob
 	log.Trace("runner labels: %v", runner.AgentLabels)
+	quotaExhausted := make(map[int64]bool)
+	var quotaHeld, quotaHeldRuns []int64
+	deployQueued := make(map[int64]bool)
 	for _, v := range jobs {
-		if isSubset(runner.AgentLabels, v.RunsOn) {
-			job = v
-			break
+		if !runner.CanMatchLabels(v.EffectiveRunsOn()) {
+			continue
+		}
+		queued, ok := deployQueued[v.RunID]
+		if !ok {
+			if queued, err = isDeployGuardQueued(ctx, v.RunID); err != nil {
+				return nil, false, err
+			}
+			deployQueued[v.RunID] = queued
+		}
+		if queued {
+			// the job keeps waiting until the previous deploy run is done
+			continue
+		}
+		if throttled, err := isMatrixLegThrottled(ctx, v); err != nil {
+			return nil, false, err
+		} else if throttled {
+			continue
+		}
+		if isMinutesQuotaCounted(runner) {
+			exhausted, ok := quotaExhausted[v.OwnerID]
+			if !ok {
+				if exhausted, err = IsMinutesQuotaExhausted(ctx, v.OwnerID); err != nil {
+					return nil, false, err
+				}
+				quotaExhausted[v.OwnerID] = exhausted
+			}
+			if exhausted {
+				// the job keeps waiting until the quota is reset in the next period
+				quotaHeld = append(quotaHeld, v.ID)
+				quotaHeldRuns = append(quotaHeldRuns, v.RunID)
+				continue
+			}
+		}
+		if runner.IsHosted() && setting.Actions.HostedFallbackPolicy {
+			// leave the job to the self-hosted runners if any of them can run it
+			matched, err := hasOnlineSelfHostedRunnerMatched(ctx, v)
+			if err != nil {
+				return nil, false, err
+			} else if matched {
+				continue
+			}
+		}
+		job = v
+		break
+	}
+	if len(quotaHeld) > 0 {
+		// the jobs held by the quota aren't ready to run, so the time waiting for the quota isn't counted as the queue time,
+		// the updated time is kept since the jobs are picked in its order
+		if _, err := e.In("id", quotaHeld).NoAutoTime().Cols("queued").Update(&ActionRunJob{Queued: timeutil.TimeStampNow()}); err != nil {
+			return nil, false, err
+		}
+		if err := MarkRunsQuotaQueued(ctx, true, quotaHeldRuns...); err != nil {
+			return nil, false, err
+		}
+		if job == nil {
+			// keep the updated queued times and the held runs
+			if err := commiter.Commit(); err != nil {
+				return nil, false, err
+			}
 		}
 	}
 	if job == nil {
@@ -269,6 +355,7 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 		OwnerID:           job.OwnerID,
 		CommitSHA:         job.CommitSHA,
 		IsForkPullRequest: job.IsForkPullRequest,
+		TokenPermission:   job.TokenPermission,
 	}
 	if err := task.GenerateToken(); err != nil {
 		return nil, false, err
@@ -316,6 +403,10 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 	} else if n != 1 {
 		return nil, false, nil
 	}
+	// the quota has been reset if the job was held before
+	if err := MarkRunsQuotaQueued(ctx, false, job.RunID); err != nil {
+		return nil, false, err
+	}
 
 	task.Job = job
 
@@ -371,11 +462,15 @@ func UpdateTaskByState(ctx context.Context, state *runnerv1.TaskState) (*ActionT
 		if err := UpdateTask(ctx, task, "status", "stopped"); err != nil {
 			return nil, err
 		}
+		if err := addMinutesUsage(ctx, task); err != nil {
+			return nil, fmt.Errorf("addMinutesUsage: %w", err)
+		}
 		if _, err := UpdateRunJob(ctx, &ActionRunJob{
-			ID:      task.JobID,
-			Status:  task.Status,
-			Stopped: task.Stopped,
-		}, nil); err != nil {
+			ID:           task.JobID,
+			Status:       task.Status,
+			Stopped:      task.Stopped,
+			FailureCause: classifyTaskFailure(task.Status, state.Steps),
+		}, nil, "status", "stopped", "failure_cause"); err != nil {
 			return nil, err
 		}
 	} else {
@@ -416,6 +511,22 @@ func UpdateTaskByState(ctx context.Context, state *runnerv1.TaskState) (*ActionT
 	return task, nil
 }
 
+// classifyTaskFailure returns the cause of the failure of the task by the states of its steps reported by the runner,
+// or empty if the task didn't fail. The task failed by the infrastructure if none of its steps has started,
+// since it failed to set up the job, like failing to pull the image of the job container.
+func classifyTaskFailure(status Status, steps []*runnerv1.StepState) string {
+	if status != StatusFailure {
+		return ""
+	}
+	for _, step := range steps {
+		if step.Result != runnerv1.Result_RESULT_UNSPECIFIED && step.Result != runnerv1.Result_RESULT_SKIPPED ||
+			step.StartedAt != nil && step.StartedAt.AsTime().Unix() > 0 {
+			return FailureCauseLogic
+		}
+	}
+	return FailureCauseInfra
+}
+
 func StopTask(ctx context.Context, taskID int64, status Status) error {
 	if !status.IsDone() {
 		return fmt.Errorf("cannot stop task with status %v", status)
@@ -435,17 +546,21 @@ func StopTask(ctx context.Context, taskID int64, status Status) error {
 	now := timeutil.TimeStampNow()
 	task.Status = status
 	task.Stopped = now
+	// the cause of the failure is unknown, the callers which know it could set it later
 	if _, err := UpdateRunJob(ctx, &ActionRunJob{
 		ID:      task.JobID,
 		Status:  task.Status,
 		Stopped: task.Stopped,
-	}, nil); err != nil {
+	}, nil, "status", "stopped", "failure_cause"); err != nil {
 		return err
 	}
 
 	if err := UpdateTask(ctx, task, "status", "stopped"); err != nil {
 		return err
 	}
+	if err := addMinutesUsage(ctx, task); err != nil {
+		return fmt.Errorf("addMinutesUsage: %w", err)
+	}
 
 	if err := task.LoadAttributes(ctx); err != nil {
 		return err
@@ -0,0 +1,51 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// FindTasksToArchiveLogs returns the finished tasks whose logs have been transferred to the storage but haven't been archived,
+// they are the tasks of the run if runID is not zero, or the oldest ones of all runs up to limit
+func FindTasksToArchiveLogs(ctx context.Context, runID int64, limit int) ([]*ActionTask, error) {
+	cond := builder.Eq{
+		"log_in_storage":   true,
+		"log_expired":      false,
+		"log_archive_path": "",
+	}
+	sess := db.GetEngine(ctx).Where(cond).OrderBy("id")
+	if runID > 0 {
+		sess.In("job_id", builder.Select("id").From("action_run_job").Where(builder.Eq{"run_id": runID}))
+	}
+	if limit > 0 {
+		sess.Limit(limit)
+	}
+	var tasks []*ActionTask
+	if err := sess.Find(&tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindTasksToPruneLogs returns the tasks whose logs were archived before the time and whose local copies haven't been removed
+func FindTasksToPruneLogs(ctx context.Context, archivedBefore timeutil.TimeStamp, limit int) ([]*ActionTask, error) {
+	sess := db.GetEngine(ctx).
+		Where(builder.Neq{"log_archive_path": ""}.And(builder.Eq{"log_pruned": false, "log_expired": false})).
+		And(builder.Lt{"log_archived_unix": archivedBefore}).
+		OrderBy("id")
+	if limit > 0 {
+		sess.Limit(limit)
+	}
+	var tasks []*ActionTask
+	if err := sess.Find(&tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTasksToArchiveAndPruneLogs(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, db.Insert(db.DefaultContext, &ActionRunJob{ID: 1001, RunID: 2001, RepoID: 1}))
+	require.NoError(t, db.Insert(db.DefaultContext, &ActionRunJob{ID: 1002, RunID: 2002, RepoID: 1}))
+	tasks := []*ActionTask{
+		{ID: 3001, JobID: 1001, TokenHash: "3001", LogFilename: "1/3001.log", LogInStorage: true},
+		{ID: 3002, JobID: 1001, TokenHash: "3002", LogFilename: "1/3002.log"}, // still running
+		{ID: 3003, JobID: 1002, TokenHash: "3003", LogFilename: "1/3003.log", LogInStorage: true},
+		{ID: 3004, JobID: 1002, TokenHash: "3004", LogFilename: "1/3004.log", LogInStorage: true, LogExpired: true},
+		{ID: 3005, JobID: 1002, TokenHash: "3005", LogFilename: "1/3005.log", LogInStorage: true, LogArchivePath: "1/3005.log", LogArchivedUnix: 100},
+		{ID: 3006, JobID: 1002, TokenHash: "3006", LogFilename: "1/3006.log", LogInStorage: true, LogArchivePath: "1/3006.log", LogArchivedUnix: 300},
+		{ID: 3007, JobID: 1002, TokenHash: "3007", LogFilename: "1/3007.log", LogInStorage: true, LogArchivePath: "1/3007.log", LogArchivedUnix: 100, LogPruned: true},
+	}
+	for _, task := range tasks {
+		require.NoError(t, db.Insert(db.DefaultContext, task))
+	}
+
+	taskIDs := func(tasks []*ActionTask) []int64 {
+		ids := make([]int64, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	got, err := FindTasksToArchiveLogs(db.DefaultContext, 2001, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3001}, taskIDs(got))
+
+	got, err = FindTasksToArchiveLogs(db.DefaultContext, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3001, 3003}, taskIDs(got))
+
+	got, err = FindTasksToArchiveLogs(db.DefaultContext, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3001}, taskIDs(got))
+
+	got, err = FindTasksToPruneLogs(db.DefaultContext, timeutil.TimeStamp(200), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3005}, taskIDs(got))
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TokenAccessMode returns the access mode of the token of the task to its repository, see ResolveTokenAccessMode
+func (task *ActionTask) TokenAccessMode() perm.AccessMode {
+	return ResolveTokenAccessMode(task.IsForkPullRequest, task.TokenPermission)
+}
+
+// ResolveTokenAccessMode returns the access mode of the token of a job requesting the permission to its repository.
+// The tokens of the runs from forks are always read-only, whatever the workflow requests.
+func ResolveTokenAccessMode(isForkPullRequest bool, requested perm.AccessMode) perm.AccessMode {
+	if isForkPullRequest {
+		return perm.AccessModeRead
+	}
+	if requested <= perm.AccessModeNone || requested > perm.AccessModeWrite {
+		return perm.AccessModeWrite
+	}
+	return requested
+}
+
+// defaultTokenPermission returns the access mode of the tokens if the workflows don't specify `permissions`, see setting.Actions.DefaultTokenPermissions
+func defaultTokenPermission() perm.AccessMode {
+	if setting.Actions.DefaultTokenPermissions == setting.TokenPermissionsRead {
+		return perm.AccessModeRead
+	}
+	return perm.AccessModeWrite
+}
+
+// resolveTokenPermissions resolves the access modes of the tokens for the jobs of the workflow content, keyed by job id.
+// The `permissions` of a job overrides the `permissions` of the workflow,
+// and the default token permission is used if neither is specified.
+// Only the coarse access mode is supported, so the token has write access if any scope requests `write`.
+func resolveTokenPermissions(content []byte) map[string]perm.AccessMode {
+	var workflow struct {
+		Permissions yaml.Node `yaml:"permissions"`
+		Jobs        map[string]struct {
+			Permissions yaml.Node `yaml:"permissions"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		log.Warn("unable to parse permissions of workflow: %v", err)
+		return nil
+	}
+
+	mode := defaultTokenPermission()
+	if m, ok := permissionsAccessMode(&workflow.Permissions); ok {
+		mode = m
+	}
+	ret := make(map[string]perm.AccessMode, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		ret[id] = mode
+		if m, ok := permissionsAccessMode(&job.Permissions); ok {
+			ret[id] = m
+		}
+	}
+	return ret
+}
+
+// permissionsAccessMode converts `permissions` like `read-all`, `write-all` or `{contents: read, issues: write}` to the access mode,
+// it returns false if `permissions` is not specified.
+func permissionsAccessMode(node *yaml.Node) (perm.AccessMode, bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value == "write-all" {
+			return perm.AccessModeWrite, true
+		}
+		return perm.AccessModeRead, true
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if node.Content[i].Value == "write" {
+				return perm.AccessModeWrite, true
+			}
+		}
+		// `none` is treated as read-only since the token is always able to read the repository
+		return perm.AccessModeRead, true
+	default:
+		return perm.AccessModeNone, false
+	}
+}
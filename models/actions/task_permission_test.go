@@ -0,0 +1,68 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_resolveTokenPermissions(t *testing.T) {
+	content := []byte(`
+on: push
+permissions:
+  contents: read
+jobs:
+  inherit:
+    runs-on: ubuntu-latest
+  escalate:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      packages: write
+  none:
+    runs-on: ubuntu-latest
+    permissions: {}
+`)
+	for _, defaults := range []string{setting.TokenPermissionsRead, setting.TokenPermissionsWrite} {
+		t.Run(defaults, func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Actions.DefaultTokenPermissions, defaults)()
+			assert.Equal(t, map[string]perm.AccessMode{
+				"inherit":  perm.AccessModeRead,
+				"escalate": perm.AccessModeWrite,
+				"none":     perm.AccessModeRead,
+			}, resolveTokenPermissions(content))
+		})
+	}
+
+	content = []byte(`
+on: push
+jobs:
+  unspecified:
+    runs-on: ubuntu-latest
+  write-all:
+    runs-on: ubuntu-latest
+    permissions: write-all
+`)
+	defer test.MockVariableValue(&setting.Actions.DefaultTokenPermissions, setting.TokenPermissionsRead)()
+	assert.Equal(t, map[string]perm.AccessMode{
+		"unspecified": perm.AccessModeRead,
+		"write-all":   perm.AccessModeWrite,
+	}, resolveTokenPermissions(content))
+
+	setting.Actions.DefaultTokenPermissions = setting.TokenPermissionsWrite
+	assert.Equal(t, perm.AccessModeWrite, resolveTokenPermissions(content)["unspecified"])
+}
+
+func TestActionTask_TokenAccessMode(t *testing.T) {
+	assert.Equal(t, perm.AccessModeRead, (&ActionTask{TokenPermission: perm.AccessModeRead}).TokenAccessMode())
+	assert.Equal(t, perm.AccessModeWrite, (&ActionTask{TokenPermission: perm.AccessModeWrite}).TokenAccessMode())
+	// the runs from forks are always read-only
+	assert.Equal(t, perm.AccessModeRead, (&ActionTask{TokenPermission: perm.AccessModeWrite, IsForkPullRequest: true}).TokenAccessMode())
+}
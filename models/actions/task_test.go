@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	runnerv1 "code.gitea.io/actions-proto-go/runner/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_classifyTaskFailure(t *testing.T) {
+	started := timestamppb.New(time.Unix(1700000000, 0))
+	tests := []struct {
+		name   string
+		status Status
+		steps  []*runnerv1.StepState
+		want   string
+	}{
+		{name: "success", status: StatusSuccess, steps: []*runnerv1.StepState{{Result: runnerv1.Result_RESULT_SUCCESS, StartedAt: started}}, want: ""},
+		{name: "cancelled", status: StatusCancelled, want: ""},
+		{name: "no steps", status: StatusFailure, want: FailureCauseInfra},
+		{name: "no step started", status: StatusFailure, steps: []*runnerv1.StepState{{}, {Result: runnerv1.Result_RESULT_SKIPPED}}, want: FailureCauseInfra},
+		{name: "step failed", status: StatusFailure, steps: []*runnerv1.StepState{{Result: runnerv1.Result_RESULT_SUCCESS, StartedAt: started}, {Result: runnerv1.Result_RESULT_FAILURE, StartedAt: started}}, want: FailureCauseLogic},
+		{name: "step started", status: StatusFailure, steps: []*runnerv1.StepState{{StartedAt: started}}, want: FailureCauseLogic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyTaskFailure(tt.status, tt.steps))
+		})
+	}
+}
@@ -82,3 +82,26 @@ func UpdateVariable(ctx context.Context, variable *ActionVariable) (bool, error)
 		})
 	return count != 0, err
 }
+
+// GetVariablesOfRepo returns the variables available to the workflows of the repository keyed by name,
+// the variables of the repository take precedence over the ones of the owner, which take precedence over the global ones.
+func GetVariablesOfRepo(ctx context.Context, ownerID, repoID int64) (map[string]string, error) {
+	globalVariables, err := db.Find[ActionVariable](ctx, FindVariablesOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("find global variables: %w", err)
+	}
+	ownerVariables, err := db.Find[ActionVariable](ctx, FindVariablesOpts{OwnerID: ownerID})
+	if err != nil {
+		return nil, fmt.Errorf("find variables of owner %d: %w", ownerID, err)
+	}
+	repoVariables, err := db.Find[ActionVariable](ctx, FindVariablesOpts{RepoID: repoID})
+	if err != nil {
+		return nil, fmt.Errorf("find variables of repo %d: %w", repoID, err)
+	}
+
+	variables := make(map[string]string, len(globalVariables)+len(ownerVariables)+len(repoVariables))
+	for _, v := range append(globalVariables, append(ownerVariables, repoVariables...)...) {
+		variables[v.Name] = v.Data
+	}
+	return variables, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ActionWorkflowContent is the snapshot of the content of a workflow file which runs are created from, for auditing what the runs executed.
+// Reruns never read it, since the payloads of the jobs are rendered from the same content when the run is inserted and are reused by reruns,
+// so changing the file later won't alter what a rerun executes either.
+// It's deduplicated by the hash of the content, since many runs share identical content.
+type ActionWorkflowContent struct {
+	ID      int64
+	Hash    string             `xorm:"VARCHAR(64) UNIQUE"` // sha256 of the content
+	Content []byte             `xorm:"LONGBLOB"`
+	Created timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionWorkflowContent))
+}
+
+// WorkflowContentHash returns the hash of the workflow content which the snapshot is keyed by
+func WorkflowContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// InsertWorkflowContentIfNotExist saves the snapshot of the workflow content if there isn't an identical one, and returns its hash
+func InsertWorkflowContentIfNotExist(ctx context.Context, content []byte) (string, error) {
+	hash := WorkflowContentHash(content)
+	exist, err := db.GetEngine(ctx).Exist(&ActionWorkflowContent{Hash: hash})
+	if err != nil {
+		return "", err
+	} else if exist {
+		return hash, nil
+	}
+
+	if err := db.Insert(ctx, &ActionWorkflowContent{Hash: hash, Content: content}); err != nil {
+		// it may have been inserted by others concurrently
+		if exist, _ := db.GetEngine(ctx).Exist(&ActionWorkflowContent{Hash: hash}); exist {
+			return hash, nil
+		}
+		return "", err
+	}
+	return hash, nil
+}
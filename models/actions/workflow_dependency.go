@@ -0,0 +1,56 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ActionWorkflowDependency records that a workflow on the default branch of a repository calls a reusable workflow
+// of another repository by a moving ref, so the repository can be notified when the reusable workflow changes.
+type ActionWorkflowDependency struct {
+	ID         int64
+	RepoID     int64              `xorm:"index"`                             // the repository of the caller
+	WorkflowID string             `xorm:"VARCHAR(255)"`                      // the entry name of the caller workflow
+	CalleeRepo string             `xorm:"VARCHAR(255) INDEX(callee)"`        // the lower-cased full name of the repository of the reusable workflow
+	CalleeRef  string             `xorm:"VARCHAR(255) INDEX(callee)"`        // the branch or tag name of the call
+	CalleePath string             `xorm:"VARCHAR(1024) NOT NULL DEFAULT ''"` // the path of the reusable workflow in its repository
+	Created    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionWorkflowDependency))
+}
+
+// ReplaceWorkflowDependencies replaces the recorded dependencies of the workflows of the repository,
+// they are recorded from scratch every time the default branch is pushed.
+func ReplaceWorkflowDependencies(ctx context.Context, repoID int64, dependencies []*ActionWorkflowDependency) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("repo_id=?", repoID).Delete(new(ActionWorkflowDependency)); err != nil {
+			return err
+		}
+		if len(dependencies) == 0 {
+			return nil
+		}
+		for _, dependency := range dependencies {
+			dependency.RepoID = repoID
+		}
+		return db.Insert(ctx, dependencies)
+	})
+}
+
+// FindWorkflowDependents returns the dependencies on the reusable workflows of the repository called by the ref,
+// they are ordered by the callers so the fan-out is capped consistently.
+func FindWorkflowDependents(ctx context.Context, calleeRepo, calleeRef string) ([]*ActionWorkflowDependency, error) {
+	var dependencies []*ActionWorkflowDependency
+	return dependencies, db.GetEngine(ctx).Where(builder.Eq{
+		"callee_repo": calleeRepo,
+		"callee_ref":  calleeRef,
+	}).OrderBy("repo_id, id").Find(&dependencies)
+}
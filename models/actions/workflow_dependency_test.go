@@ -0,0 +1,41 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceWorkflowDependencies(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	require.NoError(t, ReplaceWorkflowDependencies(db.DefaultContext, 2, []*ActionWorkflowDependency{
+		{WorkflowID: "ci.yml", CalleeRepo: "org/shared-ci", CalleeRef: "main", CalleePath: ".gitea/workflows/build.yml"},
+	}))
+	require.NoError(t, ReplaceWorkflowDependencies(db.DefaultContext, 1, []*ActionWorkflowDependency{
+		{WorkflowID: "ci.yml", CalleeRepo: "org/shared-ci", CalleeRef: "main", CalleePath: ".gitea/workflows/build.yml"},
+		{WorkflowID: "release.yml", CalleeRepo: "org/shared-ci", CalleeRef: "v1", CalleePath: ".gitea/workflows/release.yml"},
+	}))
+
+	dependencies, err := FindWorkflowDependents(db.DefaultContext, "org/shared-ci", "main")
+	require.NoError(t, err)
+	if assert.Len(t, dependencies, 2) {
+		assert.EqualValues(t, 1, dependencies[0].RepoID)
+		assert.EqualValues(t, 2, dependencies[1].RepoID)
+	}
+
+	// the dependencies are replaced, so the workflow which no longer calls the reusable workflow isn't a dependent
+	require.NoError(t, ReplaceWorkflowDependencies(db.DefaultContext, 1, nil))
+	dependencies, err = FindWorkflowDependents(db.DefaultContext, "org/shared-ci", "main")
+	require.NoError(t, err)
+	if assert.Len(t, dependencies, 1) {
+		assert.EqualValues(t, 2, dependencies[0].RepoID)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ActionWorkflowsSource records who has configured the workflows source of a repository, see repo_model.ActionsConfig.WorkflowsSource.
+// The workflows of the source are only read while the user can read it, and a push to the source finds the repositories reading it by the index.
+type ActionWorkflowsSource struct {
+	ID           int64
+	RepoID       int64              `xorm:"UNIQUE"` // the repository reading the workflows of the source
+	SourceRepoID int64              `xorm:"index"`
+	ConfiguredBy int64              // the user who has configured the workflows source
+	Created      timeutil.TimeStamp `xorm:"created"`
+	Updated      timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionWorkflowsSource))
+}
+
+// SetWorkflowsSource records the workflows source of the repository, it replaces the previous one of the repository
+func SetWorkflowsSource(ctx context.Context, source *ActionWorkflowsSource) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := DeleteWorkflowsSource(ctx, source.RepoID); err != nil {
+			return err
+		}
+		source.ID = 0
+		return db.Insert(ctx, source)
+	})
+}
+
+// GetWorkflowsSource returns the workflows source of the repository, or nil if it hasn't been configured
+func GetWorkflowsSource(ctx context.Context, repoID int64) (*ActionWorkflowsSource, error) {
+	var source ActionWorkflowsSource
+	if has, err := db.GetEngine(ctx).Where("repo_id=?", repoID).Get(&source); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return &source, nil
+}
+
+// FindWorkflowsSourceDependents returns the workflows sources of the repositories reading the workflows of the source repository
+func FindWorkflowsSourceDependents(ctx context.Context, sourceRepoID int64) ([]*ActionWorkflowsSource, error) {
+	var sources []*ActionWorkflowsSource
+	return sources, db.GetEngine(ctx).Where("source_repo_id=?", sourceRepoID).Find(&sources)
+}
+
+// DeleteWorkflowsSource deletes the workflows source of the repository
+func DeleteWorkflowsSource(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("repo_id=?", repoID).Delete(new(ActionWorkflowsSource))
+	return err
+}
@@ -49,6 +49,8 @@ const (
 	NotificationSourceCommit
 	// NotificationSourceRepository is a notification for a repository
 	NotificationSourceRepository
+	// NotificationSourceActionRun is a notification for an actions run which is waiting for approval or runner minutes
+	NotificationSourceActionRun
 )
 
 // Notification represents a notification
@@ -63,6 +65,7 @@ type Notification struct {
 	IssueID   int64  `xorm:"INDEX NOT NULL"`
 	CommitID  string `xorm:"INDEX"`
 	CommentID int64
+	RunIndex  int64 // the index of the actions run in the repository
 
 	UpdatedBy int64 `xorm:"INDEX NOT NULL"`
 
@@ -159,6 +162,18 @@ func CreateRepoTransferNotification(ctx context.Context, doer, newOwner *user_mo
 	})
 }
 
+// CreateActionRunNotification creates a notification for the user who triggered the actions run
+func CreateActionRunNotification(ctx context.Context, userID, repoID, runIndex int64) error {
+	return db.Insert(ctx, &Notification{
+		UserID:    userID,
+		RepoID:    repoID,
+		Status:    NotificationStatusUnread,
+		Source:    NotificationSourceActionRun,
+		RunIndex:  runIndex,
+		UpdatedBy: userID,
+	})
+}
+
 // CreateOrUpdateIssueNotifications creates an issue notification
 // for each watcher, or updates it if already exists
 // receiverID > 0 just send to receiver, else send to all watcher
@@ -399,6 +414,8 @@ func (n *Notification) HTMLURL(ctx context.Context) string {
 		return n.Repository.HTMLURL() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.HTMLURL()
+	case NotificationSourceActionRun:
+		return n.Repository.HTMLURL() + "/actions/runs/" + strconv.FormatInt(n.RunIndex, 10)
 	}
 	return ""
 }
@@ -415,6 +432,8 @@ func (n *Notification) Link(ctx context.Context) string {
 		return n.Repository.Link() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.Link()
+	case NotificationSourceActionRun:
+		return n.Repository.Link() + "/actions/runs/" + strconv.FormatInt(n.RunIndex, 10)
 	}
 	return ""
 }
@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package db_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsErrTransient(t *testing.T) {
+	for _, err := range []error{
+		driver.ErrBadConn,
+		fmt.Errorf("InsertRun: %w", driver.ErrBadConn),
+		&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+		&pq.Error{Code: "40001"},
+		&pq.Error{Code: "08006"},
+		mssql.Error{Number: 1205},
+		errors.New("database is locked"),
+	} {
+		assert.True(t, db.IsErrTransient(err), err)
+	}
+
+	for _, err := range []error{
+		nil,
+		errors.New("json: unsupported type"),
+		&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+		&pq.Error{Code: "23505"},
+		mssql.Error{Number: 2627},
+		db.ErrNotExist{Resource: "action_run", ID: 1},
+	} {
+		assert.False(t, db.IsErrTransient(err), err)
+	}
+}
@@ -110,6 +110,19 @@ func GetProtectedTagByID(ctx context.Context, id int64) (*ProtectedTag, error) {
 	return tag, nil
 }
 
+// IsTagProtected reports whether the tag name matches any of the protected tags
+func IsTagProtected(tags []*ProtectedTag, tagName string) (bool, error) {
+	for _, tag := range tags {
+		if err := tag.EnsureCompiledPattern(); err != nil {
+			return false, err
+		}
+		if tag.matchString(tagName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // IsUserAllowedToControlTag checks if a user can control the specific tag.
 // It returns true if the tag name is not protected or the user is allowed to control it.
 func IsUserAllowedToControlTag(ctx context.Context, tags []*ProtectedTag, tagName string, userID int64) (bool, error) {
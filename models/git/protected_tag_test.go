@@ -163,3 +163,24 @@ func TestIsUserAllowedToControlTag(t *testing.T) {
 		}
 	})
 }
+
+func TestIsTagProtected(t *testing.T) {
+	tags := []*git_model.ProtectedTag{
+		{NamePattern: "v*"},
+		{NamePattern: `/^release-\d+$/`},
+	}
+	for tagName, want := range map[string]bool{
+		"v1.0.0":      true,
+		"release-12":  true,
+		"release-foo": false,
+		"nightly":     false,
+	} {
+		protected, err := git_model.IsTagProtected(tags, tagName)
+		assert.NoError(t, err)
+		assert.Equal(t, want, protected, tagName)
+	}
+
+	protected, err := git_model.IsTagProtected(nil, "v1.0.0")
+	assert.NoError(t, err)
+	assert.False(t, protected)
+}
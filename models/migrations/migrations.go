@@ -558,6 +558,98 @@ var migrations = []Migration{
 	NewMigration("Add PreviousDuration to ActionRun", v1_22.AddPreviousDurationToActionRun),
 	// v286 -> v287
 	NewMigration("Add support for SHA256 git repositories", v1_22.AdjustDBForSha256),
+	// v287 -> v288
+	NewMigration("Add CompletedNotified to ActionRun", v1_22.AddCompletedNotifiedToActionRun),
+	// v288 -> v289
+	NewMigration("Add StepRetries to ActionRunJob", v1_22.AddStepRetriesToActionRunJob),
+	// v289 -> v290
+	NewMigration("Add HeadSHA and BaseSHA to ActionRun", v1_22.AddHeadAndBaseSHAToActionRun),
+	// v290 -> v291
+	NewMigration("Add ActionWorkflowContent table and ContentHash to ActionRun", v1_22.AddActionWorkflowContentTable),
+	// v291 -> v292
+	NewMigration("Add HostedFallback to ActionRun", v1_22.AddHostedFallbackToActionRun),
+	// v292 -> v293
+	NewMigration("Add MaxParallel to ActionRunJob", v1_22.AddMaxParallelToActionRunJob),
+	// v293 -> v294
+	NewMigration("Add Gate, GateDeadline and GateDecidedBy to ActionRunJob", v1_22.AddGateToActionRunJob),
+	// v294 -> v295
+	NewMigration("Add ActionCache table", v1_22.AddActionCacheTable),
+	// v295 -> v296
+	NewMigration("Add TokenPermission to ActionRunJob and ActionTask", v1_22.AddTokenPermissionToActionRunJobAndTask),
+	// v296 -> v297
+	NewMigration("Add Warnings to ActionRun", v1_22.AddWarningsToActionRun),
+	// v297 -> v298
+	NewMigration("Add IssueID to ActionRun", v1_22.AddIssueIDToActionRun),
+	// v298 -> v299
+	NewMigration("Add ActionMinutesUsage table and QuotaExceeded to ActionRun", v1_22.AddActionMinutesUsageTable),
+	// v299 -> v300
+	NewMigration("Add Fingerprint to ActionRun", v1_22.AddFingerprintToActionRun),
+	// v300 -> v301
+	NewMigration("Add WorkflowSHA to ActionRun and ActionSchedule", v1_22.AddWorkflowSHAToActionRunAndSchedule),
+	// v301 -> v302
+	NewMigration("Add Env to ActionRunJob", v1_22.AddEnvToActionRunJob),
+	// v302 -> v303
+	NewMigration("Add Disabled to ActionSchedule", v1_22.AddDisabledToActionSchedule),
+	// v303 -> v304
+	NewMigration("Add RunIndex to Notification", v1_22.AddRunIndexToNotification),
+	// v304 -> v305
+	NewMigration("Add EstimatedDuration to ActionRun", v1_22.AddEstimatedDurationToActionRun),
+	// v305 -> v306
+	NewMigration("Add HeadRef and BaseRef to ActionRun", v1_22.AddHeadRefAndBaseRefToActionRun),
+	// v306 -> v307
+	NewMigration("Add IsProduction to ActionRun", v1_22.AddIsProductionToActionRun),
+	// v307 -> v308
+	NewMigration("Add Errors to ActionRun", v1_22.AddErrorsToActionRun),
+	// v308 -> v309
+	NewMigration("Add ManualSchedule to ActionRun", v1_22.AddManualScheduleToActionRun),
+	// v309 -> v310
+	NewMigration("Add ActionRunContext table", v1_22.AddActionRunContextTable),
+	// v310 -> v311
+	NewMigration("Add FailFast and ContinueOnError to ActionRunJob", v1_22.AddFailFastToActionRunJob),
+	// v311 -> v312
+	NewMigration("Add WorkflowDir to ActionRun and ActionSchedule", v1_22.AddWorkflowDirToActionRunAndSchedule),
+	// v312 -> v313
+	NewMigration("Add TriggerMatch to ActionRun", v1_22.AddTriggerMatchToActionRun),
+	// v313 -> v314
+	NewMigration("Add DeployGuard to ActionRun", v1_22.AddDeployGuardToActionRun),
+	// v314 -> v315
+	NewMigration("Add Environment to Secret and ActionRunJob", v1_22.AddEnvironmentToSecretAndActionRunJob),
+	// v315 -> v316
+	NewMigration("Add ActionWorkflowDependency table", v1_22.AddActionWorkflowDependencyTable),
+	// v316 -> v317
+	NewMigration("Add DeliveryID to ActionRun", v1_22.AddDeliveryIDToActionRun),
+	// v317 -> v318
+	NewMigration("Add Timeout to ActionRun", v1_22.AddTimeoutToActionRun),
+	// v318 -> v319
+	NewMigration("Add IsProtectedTag to ActionRun", v1_22.AddIsProtectedTagToActionRun),
+	// v319 -> v320
+	NewMigration("Add FallbackRunsOn to ActionRunJob", v1_22.AddFallbackRunsOnToActionRunJob),
+	// v320 -> v321
+	NewMigration("Add Paused to ActionSchedule", v1_22.AddPausedToActionSchedule),
+	// v321 -> v322
+	NewMigration("Add Queued to ActionRunJob", v1_22.AddQueuedToActionRunJob),
+	// v322 -> v323
+	NewMigration("Add SkippedUnix and SkipReason to ActionSchedule", v1_22.AddSkippedToActionSchedule),
+	// v323 -> v324
+	NewMigration("Add LogArchivePath, LogArchivedUnix and LogPruned to ActionTask", v1_22.AddLogArchiveToActionTask),
+	// v324 -> v325
+	NewMigration("Add FailureCause to ActionRunJob and InfraRetries to ActionRun", v1_22.AddInfraFailureRetries),
+	// v325 -> v326
+	NewMigration("Add Deferred to ActionSchedule", v1_22.AddDeferredToActionSchedule),
+	// v326 -> v327
+	NewMigration("Add Services to ActionRunJob", v1_22.AddServicesToActionRunJob),
+	// v327 -> v328
+	NewMigration("Add SameNamed to ActionRun and ActionSchedule", v1_22.AddSameNamedToActionRunAndSchedule),
+	// v328 -> v329
+	NewMigration("Add EnvironmentURL to ActionRunJob", v1_22.AddEnvironmentURLToActionRunJob),
+	// v329 -> v330
+	NewMigration("Add DetectionTrace to ActionRun", v1_22.AddDetectionTraceToActionRun),
+	// v330 -> v331
+	NewMigration("Add ActionLabelApproval table and ApprovedByLabel to ActionRun", v1_22.AddActionLabelApprovalTable),
+	// v331 -> v332
+	NewMigration("Add ActionWorkflowsSource table", v1_22.AddActionWorkflowsSourceTable),
+	// v332 -> v333
+	NewMigration("Add QuotaQueued to ActionRun", v1_22.AddQuotaQueuedToActionRun),
 }
 
 // GetCurrentDBVersion returns the current db version
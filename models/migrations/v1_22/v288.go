@@ -0,0 +1,21 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+)
+
+func AddStepRetriesToActionRunJob(x *xorm.Engine) error {
+	type StepRetry struct {
+		Count   int           `json:"count"`
+		Backoff time.Duration `json:"backoff"`
+	}
+	type ActionRunJob struct {
+		StepRetries map[int64]*StepRetry `xorm:"JSON TEXT"`
+	}
+	return x.Sync(new(ActionRunJob))
+}
@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddHeadAndBaseSHAToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		HeadSHA string
+		BaseSHA string
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,23 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionWorkflowContentTable(x *xorm.Engine) error {
+	type ActionWorkflowContent struct {
+		ID      int64
+		Hash    string             `xorm:"VARCHAR(64) UNIQUE"`
+		Content []byte             `xorm:"LONGBLOB"`
+		Created timeutil.TimeStamp `xorm:"created"`
+	}
+	type ActionRun struct {
+		ContentHash string `xorm:"VARCHAR(64)"`
+	}
+	return x.Sync(new(ActionWorkflowContent), new(ActionRun))
+}
@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddHostedFallbackToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		HostedFallback bool `xorm:"NOT NULL DEFAULT true"`
+	}
+	return x.Sync(new(ActionRun))
+}
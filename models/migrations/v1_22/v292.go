@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddMaxParallelToActionRunJob(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		MaxParallel int `xorm:"NOT NULL DEFAULT 0"`
+	}
+	return x.Sync(new(ActionRunJob))
+}
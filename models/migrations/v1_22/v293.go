@@ -0,0 +1,26 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddGateToActionRunJob(x *xorm.Engine) error {
+	type JobGate struct {
+		Message   string        `json:"message"`
+		Reviewers []string      `json:"reviewers"`
+		Timeout   time.Duration `json:"timeout"`
+	}
+	type ActionRunJob struct {
+		Gate          *JobGate           `xorm:"JSON TEXT"`
+		GateDeadline  timeutil.TimeStamp `xorm:"index"`
+		GateDecidedBy int64
+	}
+	return x.Sync(new(ActionRunJob))
+}
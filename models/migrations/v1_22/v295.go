@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddTokenPermissionToActionRunJobAndTask(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		TokenPermission int `xorm:"NOT NULL DEFAULT 2"`
+	}
+	type ActionTask struct {
+		TokenPermission int `xorm:"NOT NULL DEFAULT 2"`
+	}
+	return x.Sync(new(ActionRunJob), new(ActionTask))
+}
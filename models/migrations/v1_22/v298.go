@@ -0,0 +1,24 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionMinutesUsageTable(x *xorm.Engine) error {
+	type ActionMinutesUsage struct {
+		ID      int64              `xorm:"pk autoincr"`
+		OwnerID int64              `xorm:"UNIQUE(owner_period)"`
+		Period  string             `xorm:"VARCHAR(16) UNIQUE(owner_period)"`
+		Seconds int64              `xorm:"NOT NULL DEFAULT 0"`
+		Updated timeutil.TimeStamp `xorm:"updated"`
+	}
+	type ActionRun struct {
+		QuotaExceeded bool
+	}
+	return x.Sync(new(ActionMinutesUsage), new(ActionRun))
+}
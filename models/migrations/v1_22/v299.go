@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddFingerprintToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		Fingerprint string `xorm:"VARCHAR(64) index"`
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddWorkflowSHAToActionRunAndSchedule(x *xorm.Engine) error {
+	type ActionRun struct {
+		WorkflowSHA string `xorm:"VARCHAR(64)"`
+	}
+	type ActionSchedule struct {
+		WorkflowSHA string `xorm:"VARCHAR(64)"`
+	}
+	return x.Sync(new(ActionRun), new(ActionSchedule))
+}
@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddRunIndexToNotification(x *xorm.Engine) error {
+	type Notification struct {
+		RunIndex int64
+	}
+	return x.Sync(new(Notification))
+}
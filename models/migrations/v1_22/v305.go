@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddHeadRefAndBaseRefToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		HeadRef string
+		BaseRef string
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddIsProductionToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		IsProduction bool `xorm:"index"`
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddErrorsToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		Errors []string `xorm:"JSON TEXT"`
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionRunContextTable(x *xorm.Engine) error {
+	type ActionRunContext struct {
+		ID        int64
+		RunID     int64  `xorm:"UNIQUE"`
+		RepoID    int64  `xorm:"index"`
+		Content   string `xorm:"LONGTEXT"`
+		Truncated bool
+		Created   timeutil.TimeStamp `xorm:"created"`
+	}
+	return x.Sync(new(ActionRunContext))
+}
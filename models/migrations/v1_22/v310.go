@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddFailFastToActionRunJob(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		FailFast        bool `xorm:"NOT NULL DEFAULT false"`
+		ContinueOnError bool `xorm:"NOT NULL DEFAULT false"`
+	}
+	return x.Sync(new(ActionRunJob))
+}
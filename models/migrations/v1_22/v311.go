@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddWorkflowDirToActionRunAndSchedule(x *xorm.Engine) error {
+	type ActionRun struct {
+		WorkflowDir string `xorm:"NOT NULL DEFAULT ''"`
+	}
+	type ActionSchedule struct {
+		WorkflowDir string `xorm:"NOT NULL DEFAULT ''"`
+	}
+	return x.Sync(new(ActionRun), new(ActionSchedule))
+}
@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddTriggerMatchToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		TriggerMatch map[string]string `xorm:"JSON TEXT"`
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddDeployGuardToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		DeployGuard      string `xorm:"VARCHAR(20) NOT NULL DEFAULT ''"`
+		DeployGuardRunID int64  `xorm:"NOT NULL DEFAULT 0"`
+	}
+	return x.Sync(new(ActionRun))
+}
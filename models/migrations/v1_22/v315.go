@@ -0,0 +1,23 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionWorkflowDependencyTable(x *xorm.Engine) error {
+	type ActionWorkflowDependency struct {
+		ID         int64
+		RepoID     int64              `xorm:"index"`
+		WorkflowID string             `xorm:"VARCHAR(255)"`
+		CalleeRepo string             `xorm:"VARCHAR(255) INDEX(callee)"`
+		CalleeRef  string             `xorm:"VARCHAR(255) INDEX(callee)"`
+		CalleePath string             `xorm:"VARCHAR(1024) NOT NULL DEFAULT ''"`
+		Created    timeutil.TimeStamp `xorm:"created"`
+	}
+	return x.Sync(new(ActionWorkflowDependency))
+}
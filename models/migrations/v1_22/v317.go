@@ -0,0 +1,17 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+)
+
+func AddTimeoutToActionRun(x *xorm.Engine) error {
+	type ActionRun struct {
+		Timeout time.Duration `xorm:"NOT NULL DEFAULT 0"`
+	}
+	return x.Sync(new(ActionRun))
+}
@@ -0,0 +1,15 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddFallbackRunsOnToActionRunJob(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		FallbackRunsOn string `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+	}
+	return x.Sync(new(ActionRunJob))
+}
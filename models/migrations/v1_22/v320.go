@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddPausedToActionSchedule(x *xorm.Engine) error {
+	type ActionSchedule struct {
+		Paused     bool               `xorm:"NOT NULL DEFAULT false"`
+		PausedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+	return x.Sync(new(ActionSchedule))
+}
@@ -0,0 +1,19 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddLogArchiveToActionTask(x *xorm.Engine) error {
+	type ActionTask struct {
+		LogArchivePath  string
+		LogArchivedUnix timeutil.TimeStamp `xorm:"index"`
+		LogPruned       bool
+	}
+	return x.Sync(new(ActionTask))
+}
@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddInfraFailureRetries(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		FailureCause string `xorm:"VARCHAR(16) NOT NULL DEFAULT ''"`
+	}
+	type ActionRun struct {
+		InfraRetries int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+	return x.Sync(new(ActionRunJob), new(ActionRun))
+}
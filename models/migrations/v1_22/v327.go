@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddSameNamedToActionRunAndSchedule(x *xorm.Engine) error {
+	type ActionRun struct {
+		SameNamed []string `xorm:"JSON TEXT"`
+	}
+	type ActionSchedule struct {
+		SameNamed []string `xorm:"JSON TEXT"`
+	}
+	return x.Sync(new(ActionRun), new(ActionSchedule))
+}
@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddEnvironmentURLToActionRunJob(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		RawEnvironmentURL string `xorm:"TEXT"`
+		EnvironmentURL    string `xorm:"TEXT"`
+	}
+	return x.Sync(new(ActionRunJob))
+}
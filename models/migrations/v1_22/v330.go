@@ -0,0 +1,25 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionLabelApprovalTable(x *xorm.Engine) error {
+	type ActionLabelApproval struct {
+		ID         int64
+		RepoID     int64  `xorm:"index"`
+		IssueID    int64  `xorm:"UNIQUE"`
+		CommitSHA  string `xorm:"VARCHAR(64)"`
+		ApproverID int64
+		Created    timeutil.TimeStamp `xorm:"created"`
+	}
+	type ActionRun struct {
+		ApprovedByLabel bool `xorm:"NOT NULL DEFAULT false"`
+	}
+	return x.Sync(new(ActionLabelApproval), new(ActionRun))
+}
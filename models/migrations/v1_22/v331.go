@@ -0,0 +1,22 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionWorkflowsSourceTable(x *xorm.Engine) error {
+	type ActionWorkflowsSource struct {
+		ID           int64
+		RepoID       int64 `xorm:"UNIQUE"`
+		SourceRepoID int64 `xorm:"index"`
+		ConfiguredBy int64
+		Created      timeutil.TimeStamp `xorm:"created"`
+		Updated      timeutil.TimeStamp `xorm:"updated"`
+	}
+	return x.Sync(new(ActionWorkflowsSource))
+}
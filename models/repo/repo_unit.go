@@ -164,8 +164,150 @@ func (cfg *PullRequestsConfig) GetDefaultMergeStyle() MergeStyle {
 	return MergeStyleMerge
 }
 
+const (
+	SkipWorkflowStringsModeDefault = ""        // the global setting.Actions.SkipWorkflowStrings are used
+	SkipWorkflowStringsModeExtend  = "extend"  // the skip strings of the repository are used besides the global ones
+	SkipWorkflowStringsModeReplace = "replace" // the skip strings of the repository are used instead of the global ones
+	SkipWorkflowStringsModeDisable = "disable" // no commit messages can skip the workflows of the repository
+)
+
+const (
+	CommitStatusModeJobs     = ""         // a commit status is created for every job
+	CommitStatusModeWorkflow = "workflow" // a single aggregate commit status is created for every workflow instead
+	CommitStatusModeBoth     = "both"     // the aggregate commit status of every workflow is created besides the ones of the jobs
+)
+
+const (
+	WorkflowsSourcePrecedenceLocal  = ""       // the workflows of the repository win over the same-named ones of the workflows source
+	WorkflowsSourcePrecedenceSource = "source" // the workflows of the workflows source win over the same-named ones of the repository
+)
+
 type ActionsConfig struct {
 	DisabledWorkflows []string
+	// DisabledEvents are the events which won't trigger any workflows of the repository, like `watch`
+	DisabledEvents []string
+	// SkipWorkflowStrings are the strings in commit messages which skip the workflows of the repository, see SkipWorkflowStringsMode
+	SkipWorkflowStrings []string
+	// SkipWorkflowStringsMode is how SkipWorkflowStrings work with the global setting.Actions.SkipWorkflowStrings
+	SkipWorkflowStringsMode string
+	// ApprovalReviewers are the users and the teams like "org/team" who can approve the runs of the repository,
+	// only the users with write permission for actions among them can. Empty means the reviewers of the owner,
+	// or any user with write permission for actions if the owner has no reviewers either.
+	ApprovalReviewers []string
+	// CancelSupersededRuns makes a push to a branch cancel the queued runs of the older commits of the branch across all workflows,
+	// the runs which have been started are left to finish. It's opt-in since some workflows must run on every commit.
+	CancelSupersededRuns bool
+	// RunOnMirrorSync makes the syncs of a pull mirror trigger the workflows like the pushes, the creations and the deletions of refs,
+	// their actor is the actions user. It's opt-in since the upstream may change a lot of refs in a sync.
+	RunOnMirrorSync bool
+	// WorkflowDirs are the directories which the workflows of the repository are read from, like ".gitea/workflows/ci",
+	// they must be in .gitea/workflows or .github/workflows. Only the files directly in them are read by looking them up,
+	// so it saves scanning the workflow directories of huge repositories. Empty means all files in the workflow directories.
+	WorkflowDirs []string
+	// ArtifactQuota is the total size in MiB of the artifacts of the repository, it overrides setting.Actions.ArtifactRepoQuota.
+	// Zero means the global quota, and negative means unlimited.
+	ArtifactQuota int64
+	// CommitStatusMode is whether the runs of the repository create the commit statuses of the jobs, the aggregate ones of the workflows or both,
+	// see CommitStatusModeJobs. The aggregate ones simplify the required status checks of the protected branches.
+	CommitStatusMode string
+	// WorkflowsSource is the repository whose workflows are detected as if they were in the repository, like "org/ci-config@main",
+	// the default branch of it is read if the ref is omitted. It must be public or have the same owner as the repository.
+	// It's only read if it has been configured by a user who can still read it, see actions_model.ActionWorkflowsSource.
+	WorkflowsSource string
+	// WorkflowsSourcePrecedence is which one wins when the repository and the workflows source have same-named workflows,
+	// see WorkflowsSourcePrecedenceLocal
+	WorkflowsSourcePrecedence string
+	// WorkflowsSourceNotify makes a push changing the workflows of the workflows source send a `repository_dispatch` event
+	// of the type `workflows-source-changed` to the repository, so its workflows could run again with the changes.
+	WorkflowsSourceNotify bool
+	// AllowedForbiddenCommands are the names of the rules of [actions.forbidden_commands] which don't apply to the repository,
+	// so the false positives of them don't block its runs
+	AllowedForbiddenCommands []string
+	// ChatOpsCommands map the commands commented on the issues and the pull requests of the repository, like "/deploy staging",
+	// to the workflows which are dispatched with the arguments of the commands
+	ChatOpsCommands []*ChatOpsCommand
+	// BotCommits skips the workflows, or runs only some of them, for the pushes and the pull requests whose commits are authored
+	// and committed by bots, like the version bumps of automations. It's opt-in, nil means no commits are the ones of bots.
+	BotCommits *BotCommitsRule
+	// DispatchSchemas are the JSON schemas of the client payloads of the `repository_dispatch` events by their types,
+	// the dispatches whose client payloads don't match the schemas are rejected before any runs are created.
+	// The schemas can't reference other schemas, except the meta-schemas.
+	DispatchSchemas map[string]any
+	// InfraFailureRetries is the max number of times a failed run is rerun automatically if all of its failed jobs failed
+	// by the infrastructure, like the runners died, rather than by the workflow. 0 disables the automatic reruns.
+	InfraFailureRetries int64
+	// DeployRefRules restrict the refs which the deploy workflows of the repository can run from, like only the protected branches,
+	// the runs from the other refs fail when they are created. A run restricted by several rules must be allowed by all of them.
+	DeployRefRules []*DeployRefRule
+}
+
+// DeployRefRule restricts the refs which the workflows or the jobs deploying to the environments can run from
+type DeployRefRule struct {
+	// Workflows are the file names of the workflows restricted by the rule
+	Workflows []string
+	// Environments are the glob patterns of the environments, a workflow is restricted by the rule if any job deploys to a matched environment.
+	// They are matched case-insensitively like the names of environments.
+	Environments []string
+	// Branches are the glob patterns of the branches which the restricted workflows can run from
+	Branches []string
+	// ProtectedBranches allows the restricted workflows to run from the protected branches
+	ProtectedBranches bool
+}
+
+// BotCommitsRule identifies the bots by the names or the emails of the authors and the committers of commits
+type BotCommitsRule struct {
+	// Names are the names of the bots, they are matched exactly and case-insensitively
+	Names []string
+	// Emails are the emails of the bots, they are matched exactly and case-insensitively, or like "*[bot]@noreply.example.com"
+	// to match the emails ending with the part after "*". The part must have a local part before "@", so "*@example.com" never matches.
+	Emails []string
+	// Workflows are the file names of the workflows which still run for the commits of the bots, empty means none runs
+	Workflows []string
+}
+
+// IsBot reports whether the author or the committer of the name and the email is one of the bots
+func (rule *BotCommitsRule) IsBot(name, email string) bool {
+	for _, n := range rule.Names {
+		if n != "" && strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	email = strings.ToLower(email)
+	for _, e := range rule.Emails {
+		e = strings.ToLower(e)
+		if suffix, ok := strings.CutPrefix(e, "*"); ok {
+			// the suffix must not match any email of a domain
+			if !strings.HasPrefix(suffix, "@") && strings.Contains(suffix, "@") && strings.HasSuffix(email, suffix) {
+				return true
+			}
+		} else if e != "" && e == email {
+			return true
+		}
+	}
+	return false
+}
+
+// ChatOpsCommand maps a command commented on its own line to a workflow of the repository, the workflow is dispatched
+// by a `repository_dispatch` event with the arguments following the command when a user who can run the command comments it
+type ChatOpsCommand struct {
+	// Command is the command starting the line, like "/deploy"
+	Command string
+	// Workflow is the file name of the workflow to dispatch, like "deploy.yml"
+	Workflow string
+	// Arguments are the positional arguments following the command, the extra ones are rejected
+	Arguments []*ChatOpsArgument
+	// Users are the users and the teams like "org/team" who can run the command, only the users with write permission
+	// for actions among them can. Empty means any user with write permission for actions.
+	Users []string
+}
+
+// ChatOpsArgument is a positional argument of a ChatOpsCommand
+type ChatOpsArgument struct {
+	Name string
+	// Pattern is the regular expression which the whole value must match, empty means any value
+	Pattern string
+	// Default is the value if the argument is omitted, the argument is required if it's empty
+	Default string
 }
 
 func (cfg *ActionsConfig) EnableWorkflow(file string) {
@@ -190,6 +332,78 @@ func (cfg *ActionsConfig) DisableWorkflow(file string) {
 	cfg.DisabledWorkflows = append(cfg.DisabledWorkflows, file)
 }
 
+// IsForbiddenCommandAllowed returns whether the rule of [actions.forbidden_commands] doesn't apply to the repository
+func (cfg *ActionsConfig) IsForbiddenCommandAllowed(name string) bool {
+	return slices.Contains(cfg.AllowedForbiddenCommands, name)
+}
+
+func (cfg *ActionsConfig) IsEventDisabled(event string) bool {
+	return slices.Contains(cfg.DisabledEvents, event)
+}
+
+func (cfg *ActionsConfig) DisableEvent(event string) {
+	if !cfg.IsEventDisabled(event) {
+		cfg.DisabledEvents = append(cfg.DisabledEvents, event)
+	}
+}
+
+func (cfg *ActionsConfig) EnableEvent(event string) {
+	cfg.DisabledEvents = util.SliceRemoveAll(cfg.DisabledEvents, event)
+}
+
+// GetSkipWorkflowStrings returns the strings in commit messages which skip the workflows of the repository
+func (cfg *ActionsConfig) GetSkipWorkflowStrings() []string {
+	switch cfg.SkipWorkflowStringsMode {
+	case SkipWorkflowStringsModeExtend:
+		ret := slices.Clone(setting.Actions.SkipWorkflowStrings)
+		for _, s := range cfg.SkipWorkflowStrings {
+			if !slices.Contains(ret, s) {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	case SkipWorkflowStringsModeReplace:
+		return cfg.SkipWorkflowStrings
+	case SkipWorkflowStringsModeDisable:
+		return nil
+	default:
+		return setting.Actions.SkipWorkflowStrings
+	}
+}
+
+// CreatesJobCommitStatuses returns whether a commit status is created for every job of the runs of the repository
+func (cfg *ActionsConfig) CreatesJobCommitStatuses() bool {
+	return cfg.CommitStatusMode != CommitStatusModeWorkflow
+}
+
+// CreatesWorkflowCommitStatuses returns whether an aggregate commit status is created for every run of the repository
+func (cfg *ActionsConfig) CreatesWorkflowCommitStatuses() bool {
+	return cfg.CommitStatusMode == CommitStatusModeWorkflow || cfg.CommitStatusMode == CommitStatusModeBoth
+}
+
+// GetWorkflowsSource returns the owner, the name and the ref of the workflows source, the ref is empty for the default branch.
+// The name is empty if the repository has no workflows source or it's invalid.
+func (cfg *ActionsConfig) GetWorkflowsSource() (ownerName, repoName, ref string) {
+	target, ref, _ := strings.Cut(strings.TrimSpace(cfg.WorkflowsSource), "@")
+	ownerName, repoName, ok := strings.Cut(target, "/")
+	if !ok || ownerName == "" || repoName == "" || strings.Contains(repoName, "/") {
+		return "", "", ""
+	}
+	return ownerName, repoName, ref
+}
+
+// GetArtifactQuota returns the total size in bytes of the artifacts of the repository, zero means unlimited
+func (cfg *ActionsConfig) GetArtifactQuota() int64 {
+	quota := cfg.ArtifactQuota
+	if quota == 0 {
+		quota = setting.Actions.ArtifactRepoQuota
+	}
+	if quota < 0 {
+		return 0
+	}
+	return quota * 1024 * 1024
+}
+
 // FromDB fills up a ActionsConfig from serialized format.
 func (cfg *ActionsConfig) FromDB(bs []byte) error {
 	return json.UnmarshalHandleDoubleEncode(bs, &cfg)
@@ -6,6 +6,9 @@ package repo
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +31,72 @@ func TestActionsConfig(t *testing.T) {
 	cfg.DisableWorkflow("test3.yaml")
 	assert.EqualValues(t, "test1.yaml,test2.yaml,test3.yaml", cfg.ToString())
 }
+
+func TestActionsConfig_GetSkipWorkflowStrings(t *testing.T) {
+	defer test.MockVariableValue(&setting.Actions.SkipWorkflowStrings, []string{"[skip ci]", "[ci skip]"})()
+
+	cfg := &ActionsConfig{SkipWorkflowStrings: []string{"[wip]", "[ci skip]"}}
+	assert.EqualValues(t, []string{"[skip ci]", "[ci skip]"}, cfg.GetSkipWorkflowStrings())
+
+	cfg.SkipWorkflowStringsMode = SkipWorkflowStringsModeExtend
+	assert.EqualValues(t, []string{"[skip ci]", "[ci skip]", "[wip]"}, cfg.GetSkipWorkflowStrings())
+
+	cfg.SkipWorkflowStringsMode = SkipWorkflowStringsModeReplace
+	assert.EqualValues(t, []string{"[wip]", "[ci skip]"}, cfg.GetSkipWorkflowStrings())
+
+	cfg.SkipWorkflowStringsMode = SkipWorkflowStringsModeDisable
+	assert.Empty(t, cfg.GetSkipWorkflowStrings())
+}
+
+func TestActionsConfig_GetArtifactQuota(t *testing.T) {
+	defer test.MockVariableValue(&setting.Actions.ArtifactRepoQuota, 100)()
+
+	assert.EqualValues(t, 100*1024*1024, (&ActionsConfig{}).GetArtifactQuota())
+	assert.EqualValues(t, 10*1024*1024, (&ActionsConfig{ArtifactQuota: 10}).GetArtifactQuota())
+	assert.Zero(t, (&ActionsConfig{ArtifactQuota: -1}).GetArtifactQuota())
+}
+
+func TestActionsConfig_CommitStatusMode(t *testing.T) {
+	cfg := &ActionsConfig{}
+	assert.True(t, cfg.CreatesJobCommitStatuses())
+	assert.False(t, cfg.CreatesWorkflowCommitStatuses())
+
+	cfg.CommitStatusMode = CommitStatusModeWorkflow
+	assert.False(t, cfg.CreatesJobCommitStatuses())
+	assert.True(t, cfg.CreatesWorkflowCommitStatuses())
+
+	cfg.CommitStatusMode = CommitStatusModeBoth
+	assert.True(t, cfg.CreatesJobCommitStatuses())
+	assert.True(t, cfg.CreatesWorkflowCommitStatuses())
+}
+
+func TestActionsConfig_GetWorkflowsSource(t *testing.T) {
+	tests := []struct {
+		source, owner, name, ref string
+	}{
+		{source: ""},
+		{source: "org"},
+		{source: "org/ci/sub"},
+		{source: "org/ci", owner: "org", name: "ci"},
+		{source: " org/ci@v1 ", owner: "org", name: "ci", ref: "v1"},
+	}
+	for _, tt := range tests {
+		owner, name, ref := (&ActionsConfig{WorkflowsSource: tt.source}).GetWorkflowsSource()
+		assert.Equal(t, []string{tt.owner, tt.name, tt.ref}, []string{owner, name, ref}, tt.source)
+	}
+}
+
+func TestBotCommitsRule_IsBot(t *testing.T) {
+	rule := &BotCommitsRule{
+		Names:  []string{"Release Bot"},
+		Emails: []string{"ci@example.com", "*[bot]@noreply.example.com", "*@example.com"},
+	}
+	assert.True(t, rule.IsBot("release bot", "someone@example.org"))
+	assert.True(t, rule.IsBot("", "CI@example.com"))
+	assert.True(t, rule.IsBot("", "renovate[bot]@noreply.example.com"))
+	assert.False(t, rule.IsBot("Release Bot Jr", ""))
+	assert.False(t, rule.IsBot("", "alice@noreply.example.com"))
+	// too loose patterns never match
+	assert.False(t, rule.IsBot("", "alice@example.com"))
+	assert.False(t, (&BotCommitsRule{Emails: []string{"*"}}).IsBot("", "alice@example.com"))
+}
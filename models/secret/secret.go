@@ -23,6 +23,7 @@ type Secret struct {
 	ID          int64
 	OwnerID     int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL"`
 	RepoID      int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+	Environment string             `xorm:"VARCHAR(255) UNIQUE(owner_repo_name) NOT NULL DEFAULT ''"` // lower-cased, empty if the secret is not scoped to an environment
 	Name        string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
 	Data        string             `xorm:"LONGTEXT"` // encrypted data
 	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
@@ -41,17 +42,19 @@ func (err ErrSecretNotFound) Unwrap() error {
 	return util.ErrNotExist
 }
 
-// InsertEncryptedSecret Creates, encrypts, and validates a new secret with yet unencrypted data and insert into database
-func InsertEncryptedSecret(ctx context.Context, ownerID, repoID int64, name, data string) (*Secret, error) {
+// InsertEncryptedSecret Creates, encrypts, and validates a new secret with yet unencrypted data and insert into database,
+// the secret is scoped to the environment of the repository if environment isn't empty
+func InsertEncryptedSecret(ctx context.Context, ownerID, repoID int64, environment, name, data string) (*Secret, error) {
 	encrypted, err := secret_module.EncryptSecret(setting.SecretKey, data)
 	if err != nil {
 		return nil, err
 	}
 	secret := &Secret{
-		OwnerID: ownerID,
-		RepoID:  repoID,
-		Name:    strings.ToUpper(name),
-		Data:    encrypted,
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Environment: NormalizeEnvironment(environment),
+		Name:        strings.ToUpper(name),
+		Data:        encrypted,
 	}
 	if err := secret.Validate(); err != nil {
 		return secret, err
@@ -67,15 +70,26 @@ func (s *Secret) Validate() error {
 	if s.OwnerID == 0 && s.RepoID == 0 {
 		return errors.New("the secret is not bound to any scope")
 	}
+	if s.Environment != "" && s.RepoID == 0 {
+		return errors.New("only the secrets of a repository can be scoped to an environment")
+	}
 	return nil
 }
 
+// NormalizeEnvironment returns the name of the environment stored in Secret.Environment,
+// the names of environments are case-insensitive like GitHub
+func NormalizeEnvironment(environment string) string {
+	return strings.ToLower(strings.TrimSpace(environment))
+}
+
 type FindSecretsOptions struct {
 	db.ListOptions
 	OwnerID  int64
 	RepoID   int64
 	SecretID int64
 	Name     string
+	// Environment is the environment which the secrets are scoped to, the secrets of any environment are never found if it's empty
+	Environment string
 }
 
 func (opts FindSecretsOptions) ToConds() builder.Cond {
@@ -92,6 +106,7 @@ func (opts FindSecretsOptions) ToConds() builder.Cond {
 	if opts.Name != "" {
 		cond = cond.And(builder.Eq{"name": strings.ToUpper(opts.Name)})
 	}
+	cond = cond.And(builder.Eq{"environment": NormalizeEnvironment(opts.Environment)})
 
 	return cond
 }
@@ -10,6 +10,12 @@ const (
 	SettingsKeyDiffWhitespaceBehavior = "diff.whitespace_behaviour"
 	// SettingsKeyShowOutdatedComments is the setting key wether or not to show outdated comments in PRs
 	SettingsKeyShowOutdatedComments = "comment_code.show_outdated"
+	// SettingsKeyActionsRunNotification is the setting key whether or not to notify the user when the runs triggered by the user are blocked
+	SettingsKeyActionsRunNotification = "actions.run_notification"
+	// SettingsKeyActionsApprovalReviewers is the setting key for the comma-separated users and teams who can approve the runs of the repositories of an owner
+	SettingsKeyActionsApprovalReviewers = "actions.approval_reviewers"
+	// SettingsKeyActionsDefaultRunsOn is the setting key for the label applied to the jobs of the repositories of an owner which no runners match
+	SettingsKeyActionsDefaultRunsOn = "actions.default_runs_on"
 	// UserActivityPubPrivPem is user's private key
 	UserActivityPubPrivPem = "activitypub.priv_pem"
 	// UserActivityPubPubPem is user's public key
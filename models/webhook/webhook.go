@@ -298,6 +298,12 @@ func (w *Webhook) HasPackageEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Package)
 }
 
+// HasActionsScheduleEvent returns if hook enabled actions schedule event.
+func (w *Webhook) HasActionsScheduleEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.ActionsSchedule)
+}
+
 // HasPullRequestReviewRequestEvent returns true if hook enabled pull request review request event.
 func (w *Webhook) HasPullRequestReviewRequestEvent() bool {
 	return w.SendEverything ||
@@ -336,6 +342,7 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasReleaseEvent, webhook_module.HookEventRelease},
 		{w.HasPackageEvent, webhook_module.HookEventPackage},
 		{w.HasPullRequestReviewRequestEvent, webhook_module.HookEventPullRequestReviewRequest},
+		{w.HasActionsScheduleEvent, webhook_module.HookEventActionsSchedule},
 	}
 }
 
@@ -0,0 +1,59 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseActorTeams parses the `actor-teams` of the workflow content, it's a Gitea extension like:
+//
+//	actor-teams: [deployers, my-org/release-managers]
+//
+// The workflow runs only if the actor who triggered the event is a member of any of the teams,
+// a team without an organization, like `deployers`, is a team of the organization which owns the repository.
+// It could be a single team, like `actor-teams: deployers`. It returns nil if the workflow has no `actor-teams`.
+func ParseActorTeams(content []byte) ([]string, error) {
+	var workflow struct {
+		ActorTeams yaml.Node `yaml:"actor-teams"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	var teams []string
+	switch workflow.ActorTeams.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		teams = []string{workflow.ActorTeams.Value}
+	case yaml.SequenceNode:
+		if err := workflow.ActorTeams.Decode(&teams); err != nil {
+			return nil, fmt.Errorf("invalid actor-teams: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid actor-teams: it should be a team or a list of teams")
+	}
+
+	ret := make([]string, 0, len(teams))
+	for _, team := range teams {
+		team = strings.TrimSpace(team)
+		org, name, hasOrg := strings.Cut(team, "/")
+		if !hasOrg {
+			org, name = "", org
+		}
+		if name == "" || (hasOrg && org == "") || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid team %q of actor-teams, it should be like `team` or `org/team`", team)
+		}
+		ret = append(ret, team)
+	}
+	if len(ret) == 0 {
+		// an empty list allows nobody rather than anybody
+		return nil, fmt.Errorf("invalid actor-teams: no teams")
+	}
+	return ret, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseActorTeams(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "none",
+			content: "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+		},
+		{
+			name:    "single",
+			content: "on: push\nactor-teams: deployers\n",
+			want:    []string{"deployers"},
+		},
+		{
+			name:    "list",
+			content: "on: push\nactor-teams: [deployers, my-org/release-managers]\n",
+			want:    []string{"deployers", "my-org/release-managers"},
+		},
+		{
+			name:    "empty list",
+			content: "on: push\nactor-teams: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty team",
+			content: "on: push\nactor-teams: ['']\n",
+			wantErr: true,
+		},
+		{
+			name:    "without org",
+			content: "on: push\nactor-teams: /deployers\n",
+			wantErr: true,
+		},
+		{
+			name:    "nested",
+			content: "on: push\nactor-teams: my-org/a/b\n",
+			wantErr: true,
+		},
+		{
+			name:    "mapping",
+			content: "on: push\nactor-teams:\n  team: deployers\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseActorTeams([]byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
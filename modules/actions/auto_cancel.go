@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsAutoCancelExempt reports whether the running runs of the workflow must not be cancelled by a new push to the same ref.
+// Gitea cancels them by default, and a workflow is exempted by `cancel-in-progress: false` of its `concurrency`,
+// or by `auto-cancel: false`, a Gitea extension, like:
+//
+//	concurrency:
+//	  group: deploy
+//	  cancel-in-progress: false
+//
+// A `concurrency` of only a group name doesn't exempt the workflow.
+func IsAutoCancelExempt(content []byte) (bool, error) {
+	var workflow struct {
+		Concurrency yaml.Node `yaml:"concurrency"`
+		AutoCancel  *bool     `yaml:"auto-cancel"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return false, err
+	}
+	if workflow.AutoCancel != nil && !*workflow.AutoCancel {
+		return true, nil
+	}
+
+	switch workflow.Concurrency.Kind {
+	case 0, yaml.ScalarNode:
+		// no concurrency, or only a group
+		return false, nil
+	case yaml.MappingNode:
+		var concurrency struct {
+			CancelInProgress any `yaml:"cancel-in-progress"`
+		}
+		if err := workflow.Concurrency.Decode(&concurrency); err != nil {
+			return false, fmt.Errorf("invalid concurrency: %w", err)
+		}
+		// an expression can't be resolved before the run is created, so it keeps the default behavior
+		cancelInProgress, ok := concurrency.CancelInProgress.(bool)
+		return ok && !cancelInProgress, nil
+	default:
+		return false, fmt.Errorf("invalid concurrency: it should be a string or a mapping")
+	}
+}
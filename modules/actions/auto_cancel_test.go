@@ -0,0 +1,66 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAutoCancelExempt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "no concurrency",
+			content: "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    false,
+		},
+		{
+			name:    "group only",
+			content: "on: push\nconcurrency: deploy\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    false,
+		},
+		{
+			name:    "cancel in progress",
+			content: "on: push\nconcurrency:\n  group: deploy\n  cancel-in-progress: true\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    false,
+		},
+		{
+			name:    "not cancel in progress",
+			content: "on: push\nconcurrency:\n  group: deploy\n  cancel-in-progress: false\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    true,
+		},
+		{
+			name:    "expression",
+			content: "on: push\nconcurrency:\n  group: deploy\n  cancel-in-progress: ${{ github.ref != 'refs/heads/main' }}\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    false,
+		},
+		{
+			name:    "auto-cancel annotation",
+			content: "on: push\nauto-cancel: false\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    true,
+		},
+		{
+			name:    "invalid concurrency",
+			content: "on: push\nconcurrency: [deploy]\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsAutoCancelExempt([]byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
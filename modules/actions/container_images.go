@@ -0,0 +1,136 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+// CheckContainerImages checks the images of the job containers and the service containers in the workflow content,
+// and returns the problems of the images which are not allowed, it returns nil if all images are allowed.
+// The patterns are globs of the fully-qualified names of the allowed images without tags, like "docker.io/library/*" or "*.example.com/*",
+// an empty patterns allows any images. If requireDigest is true, the images must be pinned by digests, like "node@sha256:...".
+// The expressions in the images are resolved with the `github`, `vars` and `matrix` contexts,
+// an image which can't be resolved is not allowed since it can't be checked.
+func CheckContainerImages(content []byte, gitCtx *model.GithubContext, vars map[string]string, patterns []string, requireDigest bool) ([]string, error) {
+	if len(patterns) == 0 && !requireDigest {
+		return nil, nil
+	}
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(strings.ToLower(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed image pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(workflow.Jobs))
+	for id := range workflow.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var problems []string
+	addProblem := func(format string, args ...any) {
+		if problem := fmt.Sprintf(format, args...); !slices.Contains(problems, problem) {
+			problems = append(problems, problem)
+		}
+	}
+	for _, id := range ids {
+		job := workflow.Jobs[id]
+		if job == nil {
+			continue
+		}
+		images := map[string]string{} // the images keyed by the fields
+		if container := job.Container(); container != nil && container.Image != "" {
+			images[fmt.Sprintf("jobs.%s.container", id)] = container.Image
+		}
+		for name, service := range job.Services {
+			if service != nil && service.Image != "" {
+				images[fmt.Sprintf("jobs.%s.services.%s", id, name)] = service.Image
+			}
+		}
+		if len(images) == 0 {
+			continue
+		}
+		fields := make([]string, 0, len(images))
+		for field := range images {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		matrixes, err := job.GetMatrixes()
+		if err != nil {
+			addProblem("jobs.%s.strategy: invalid matrix, the images can't be checked: %v", id, err)
+			continue
+		}
+		for _, matrix := range matrixes {
+			interpreter := exprparser.NewInterpeter(&exprparser.EvaluationEnvironment{
+				Github: gitCtx,
+				Vars:   vars,
+				Matrix: matrix,
+			}, exprparser.Config{
+				Run:     &model.Run{Workflow: &model.Workflow{}},
+				Context: "job",
+			})
+			for _, field := range fields {
+				image, err := interpolateExpressions(interpreter, images[field])
+				if err != nil {
+					addProblem("%s: image %q can't be resolved to check whether it's allowed: %v", field, images[field], err)
+					continue
+				}
+				if image == "" {
+					// the expression for a leg of the matrix is empty, so there is no container
+					continue
+				}
+				name, pinned := parseImageReference(image)
+				if len(globs) > 0 && !slices.ContainsFunc(globs, func(g glob.Glob) bool { return g.Match(name) }) {
+					addProblem("%s: image %q is not allowed, the allowed images are %v", field, image, patterns)
+				} else if requireDigest && !pinned {
+					addProblem("%s: image %q must be pinned by a digest, like %s@sha256:...", field, image, name)
+				}
+			}
+		}
+	}
+	return problems, nil
+}
+
+// parseImageReference returns the fully-qualified name of the image without the tag and the digest like Docker does,
+// e.g. "node:20" is "docker.io/library/node", and whether the image is pinned by a digest.
+func parseImageReference(image string) (name string, pinned bool) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(image), "docker://"))
+	name, digest, _ := strings.Cut(name, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i] // the tag
+	}
+
+	registry, path, ok := strings.Cut(name, "/")
+	if !ok {
+		return "docker.io/library/" + name, digest != ""
+	}
+	if !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		// the first component is not a registry, like "user/image" on Docker Hub
+		return "docker.io/" + name, digest != ""
+	}
+	if registry == "docker.io" || registry == "index.docker.io" || registry == "registry-1.docker.io" {
+		name = "docker.io/" + path
+		if !strings.Contains(path, "/") {
+			name = "docker.io/library/" + path
+		}
+	}
+	return name, digest != ""
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckContainerImages(t *testing.T) {
+	const content = `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    container: node:20
+    services:
+      db:
+        image: ghcr.io/example/postgres@sha256:0123456789abcdef
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        image: [golang:1.22, registry.example.com/team/go:1.22]
+    container:
+      image: ${{ matrix.image }}
+  deploy:
+    runs-on: ubuntu-latest
+    container: ${{ vars.DEPLOY_IMAGE }}
+  release:
+    runs-on: ubuntu-latest
+    container: ${{ secrets.IMAGE }}-${{ unknown.tag }}
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`
+	vars := map[string]string{"DEPLOY_IMAGE": "docker.io/example/deploy:v1"}
+
+	tests := []struct {
+		name          string
+		patterns      []string
+		requireDigest bool
+		want          []string
+	}{
+		{
+			name: "no restrictions",
+			want: nil,
+		},
+		{
+			name:     "registry wildcards",
+			patterns: []string{"docker.io/library/*", "*.example.com/*", "ghcr.io/example/*", "docker.io/example/*"},
+			want: []string{
+				"jobs.release.container: image \"${{ secrets.IMAGE }}-${{ unknown.tag }}\" can't be resolved to check whether it's allowed: Unavailable context: unknown",
+			},
+		},
+		{
+			name:     "disallowed images",
+			patterns: []string{"ghcr.io/*"},
+			want: []string{
+				"jobs.build.container: image \"node:20\" is not allowed, the allowed images are [ghcr.io/*]",
+				"jobs.deploy.container: image \"docker.io/example/deploy:v1\" is not allowed, the allowed images are [ghcr.io/*]",
+				"jobs.release.container: image \"${{ secrets.IMAGE }}-${{ unknown.tag }}\" can't be resolved to check whether it's allowed: Unavailable context: unknown",
+				"jobs.test.container: image \"golang:1.22\" is not allowed, the allowed images are [ghcr.io/*]",
+				"jobs.test.container: image \"registry.example.com/team/go:1.22\" is not allowed, the allowed images are [ghcr.io/*]",
+			},
+		},
+		{
+			name:          "pinned digests",
+			patterns:      []string{"*"},
+			requireDigest: true,
+			want: []string{
+				"jobs.build.container: image \"node:20\" must be pinned by a digest, like docker.io/library/node@sha256:...",
+				"jobs.deploy.container: image \"docker.io/example/deploy:v1\" must be pinned by a digest, like docker.io/example/deploy@sha256:...",
+				"jobs.release.container: image \"${{ secrets.IMAGE }}-${{ unknown.tag }}\" can't be resolved to check whether it's allowed: Unavailable context: unknown",
+				"jobs.test.container: image \"golang:1.22\" must be pinned by a digest, like docker.io/library/golang@sha256:...",
+				"jobs.test.container: image \"registry.example.com/team/go:1.22\" must be pinned by a digest, like registry.example.com/team/go@sha256:...",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckContainerImages([]byte(content), &model.GithubContext{}, vars, tt.patterns, tt.requireDigest)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := CheckContainerImages([]byte(content), &model.GithubContext{}, vars, []string{"[invalid"}, false)
+	assert.Error(t, err)
+}
+
+func Test_parseImageReference(t *testing.T) {
+	tests := []struct {
+		image  string
+		name   string
+		pinned bool
+	}{
+		{image: "node", name: "docker.io/library/node"},
+		{image: "node:20-alpine", name: "docker.io/library/node"},
+		{image: "docker.io/node:20", name: "docker.io/library/node"},
+		{image: "index.docker.io/library/node", name: "docker.io/library/node"},
+		{image: "example/app:v1", name: "docker.io/example/app"},
+		{image: "docker://ghcr.io/Example/App:v1", name: "ghcr.io/example/app"},
+		{image: "localhost:5000/app:v1", name: "localhost:5000/app"},
+		{image: "localhost/app", name: "localhost/app"},
+		{image: "registry.example.com:5000/team/app@sha256:0123", name: "registry.example.com:5000/team/app", pinned: true},
+		{image: "node:20@sha256:0123", name: "docker.io/library/node", pinned: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			name, pinned := parseImageReference(tt.image)
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.pinned, pinned)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// varsExpressionPattern matches the expressions which only reference a variable, like `${{ vars.NAME }}`
+var varsExpressionPattern = regexp.MustCompile(`\$\{\{\s*vars\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ResolveJobEnvs parses the `env` of the workflow and the `env` of the jobs in the workflow content,
+// the `env` of a job overrides the `env` of the workflow with the same name.
+// The expressions which only reference a variable are resolved with vars since they are known when the run is created,
+// the other expressions, including the ones referencing `secrets`, are kept as they are to be evaluated by the runner,
+// so the secrets are never stored in plaintext and are still masked in the logs.
+// It returns the envs keyed by job id, the jobs without envs are not included.
+func ResolveJobEnvs(content []byte, vars map[string]string) (map[string]map[string]string, error) {
+	var workflow struct {
+		Env  yaml.Node `yaml:"env"`
+		Jobs map[string]struct {
+			Env yaml.Node `yaml:"env"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	workflowEnv, err := decodeEnv(&workflow.Env)
+	if err != nil {
+		return nil, fmt.Errorf("invalid env of workflow: %w", err)
+	}
+
+	ret := make(map[string]map[string]string)
+	for id, job := range workflow.Jobs {
+		jobEnv, err := decodeEnv(&job.Env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid env of job %q: %w", id, err)
+		}
+		if len(workflowEnv) == 0 && len(jobEnv) == 0 {
+			continue
+		}
+		env := make(map[string]string, len(workflowEnv)+len(jobEnv))
+		for k, v := range workflowEnv {
+			env[k] = resolveVarsExpressions(v, vars)
+		}
+		for k, v := range jobEnv {
+			env[k] = resolveVarsExpressions(v, vars)
+		}
+		ret[id] = env
+	}
+	return ret, nil
+}
+
+// decodeEnv decodes the `env` mapping, an `env` which is a single expression like `${{ fromJSON(vars.ENV) }}`
+// can't be decoded before it's evaluated, so it's ignored and left to the runner.
+func decodeEnv(node *yaml.Node) (map[string]string, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	env := make(map[string]string, len(node.Content)/2)
+	if err := node.Decode(&env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// resolveVarsExpressions replaces the expressions which only reference a variable with the value of the variable,
+// the variables are case-insensitive and a missing variable is an empty string, like GitHub does.
+func resolveVarsExpressions(value string, vars map[string]string) string {
+	return varsExpressionPattern.ReplaceAllStringFunc(value, func(expr string) string {
+		name := varsExpressionPattern.FindStringSubmatch(expr)[1]
+		return vars[strings.ToUpper(name)]
+	})
+}
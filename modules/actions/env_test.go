@@ -0,0 +1,127 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveJobEnvs(t *testing.T) {
+	vars := map[string]string{
+		"REGION":  "eu-west-1",
+		"VERSION": "1.2.3",
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]map[string]string
+		wantErr bool
+	}{
+		{
+			name: "no env",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+`,
+			want: map[string]map[string]string{},
+		},
+		{
+			name: "job env overrides workflow env",
+			content: `
+env:
+  STAGE: dev
+  DEBUG: false
+  RETRIES: 3
+jobs:
+  test:
+    runs-on: ubuntu-latest
+  deploy:
+    runs-on: ubuntu-latest
+    env:
+      STAGE: prod
+      TARGET: cluster
+`,
+			want: map[string]map[string]string{
+				"test":   {"STAGE": "dev", "DEBUG": "false", "RETRIES": "3"},
+				"deploy": {"STAGE": "prod", "DEBUG": "false", "RETRIES": "3", "TARGET": "cluster"},
+			},
+		},
+		{
+			name: "vars are resolved",
+			content: `
+env:
+  REGION: ${{ vars.REGION }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    env:
+      IMAGE: app:${{vars.version}}-${{ vars.MISSING }}
+`,
+			want: map[string]map[string]string{
+				"build": {"REGION": "eu-west-1", "IMAGE": "app:1.2.3-"},
+			},
+		},
+		{
+			name: "secrets are not resolved",
+			content: `
+env:
+  TOKEN: ${{ secrets.TOKEN }}
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    env:
+      PASSWORD: ${{ secrets.PASSWORD }}
+      FALLBACK: ${{ vars.REGION || secrets.REGION }}
+      SHA: ${{ github.sha }}
+`,
+			want: map[string]map[string]string{
+				"deploy": {
+					"TOKEN":    "${{ secrets.TOKEN }}",
+					"PASSWORD": "${{ secrets.PASSWORD }}",
+					"FALLBACK": "${{ vars.REGION || secrets.REGION }}",
+					"SHA":      "${{ github.sha }}",
+				},
+			},
+		},
+		{
+			name: "env of expression is left to the runner",
+			content: `
+env: ${{ fromJSON(vars.ENV) }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      STAGE: dev
+`,
+			want: map[string]map[string]string{
+				"test": {"STAGE": "dev"},
+			},
+		},
+		{
+			name: "invalid env",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      STAGE: [dev, prod]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveJobEnvs([]byte(tt.content), vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
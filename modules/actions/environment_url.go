@@ -0,0 +1,79 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+var (
+	// stringLiteralPattern matches the string literals of expressions, like `'a''b'`
+	stringLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// runtimeContextPattern matches the references to the contexts which are only known when the job runs
+	runtimeContextPattern = regexp.MustCompile(`(?:^|[^\w.-])(?:steps|needs|job|jobs|runner|env|secrets|strategy)\s*[.\[]`)
+	// stepOutputPattern matches the outputs of jobs which are exactly an output of a step, like `${{ steps.deploy.outputs.url }}`
+	stepOutputPattern = regexp.MustCompile(`^\$\{\{\s*steps\.([\w-]+)\.outputs\.([\w-]+)\s*\}\}$`)
+)
+
+// IsRuntimeEnvironmentURL reports whether the `url` of the `environment` of a job references the contexts which are only known
+// when the job runs, like `steps` and `needs`, so it can't be resolved until the job is done.
+func IsRuntimeEnvironmentURL(rawURL string) bool {
+	for _, match := range expressionPattern.FindAllStringSubmatch(rawURL, -1) {
+		if runtimeContextPattern.MatchString(stringLiteralPattern.ReplaceAllString(match[1], "''")) {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluateEnvironmentURL evaluates the expressions in the `url` of the `environment` of a job with the contexts.
+// The result must be an absolute http or https URL, since it's a link in the UI. It's empty if the url is evaluated to empty.
+func EvaluateEnvironmentURL(rawURL string, env *exprparser.EvaluationEnvironment) (string, error) {
+	interpreter := exprparser.NewInterpeter(env, exprparser.Config{
+		Run:     &model.Run{Workflow: &model.Workflow{}},
+		Context: "job",
+	})
+	ret, err := interpolateExpressions(interpreter, rawURL)
+	if err != nil {
+		return "", fmt.Errorf("evaluate environment url %q: %w", rawURL, err)
+	}
+	if ret = strings.TrimSpace(ret); ret == "" {
+		return "", nil
+	}
+	u, err := url.Parse(ret)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("environment url %q is evaluated to %q, which isn't an http or https URL", rawURL, ret)
+	}
+	return ret, nil
+}
+
+// JobStepsContext returns the `steps` context of a job which has been done, it's derived from the outputs of the job,
+// since only the outputs of the jobs are sent to Gitea. An output of the job whose declaration is exactly an output of a step,
+// like `url: ${{ steps.deploy.outputs.url }}`, reveals the output of the step.
+func JobStepsContext(declaredOutputs, outputs map[string]string) map[string]*model.StepResult {
+	steps := make(map[string]*model.StepResult)
+	for name, declared := range declaredOutputs {
+		match := stepOutputPattern.FindStringSubmatch(strings.TrimSpace(declared))
+		if match == nil {
+			continue
+		}
+		value, ok := outputs[name]
+		if !ok {
+			continue
+		}
+		step, ok := steps[match[1]]
+		if !ok {
+			step = &model.StepResult{Outputs: map[string]string{}}
+			steps[match[1]] = step
+		}
+		step.Outputs[match[2]] = value
+	}
+	return steps
+}
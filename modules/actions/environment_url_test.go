@@ -0,0 +1,97 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRuntimeEnvironmentURL(t *testing.T) {
+	for rawURL, want := range map[string]bool{
+		"https://example.com":                                  false,
+		"https://${{ github.ref_name }}.example.com":           false,
+		"https://example.com/${{ matrix.region }}":             false,
+		"${{ vars.BASE_URL }}/${{ inputs.environment }}":       false,
+		"${{ format('https://steps.{0}', github.sha) }}":       false,
+		"${{ steps.deploy.outputs.url }}":                      true,
+		"https://example.com/${{ needs.build.outputs.tag }}":   true,
+		"${{ format('{0}/pr', steps['deploy'].outputs.url) }}": true,
+		"https://example.com/${{ env.PATH_PREFIX }}":           true,
+	} {
+		assert.Equal(t, want, IsRuntimeEnvironmentURL(rawURL), rawURL)
+	}
+}
+
+func TestEvaluateEnvironmentURL(t *testing.T) {
+	t.Run("static", func(t *testing.T) {
+		env := &exprparser.EvaluationEnvironment{
+			Github: &model.GithubContext{RefName: "feature"},
+			Vars:   map[string]string{"DOMAIN": "example.com"},
+			Inputs: map[string]any{"environment": "staging"},
+			Matrix: map[string]any{"region": "eu"},
+		}
+		got, err := EvaluateEnvironmentURL("https://${{ github.ref_name }}.${{ vars.DOMAIN }}/${{ inputs.environment }}/${{ matrix.region }}", env)
+		require.NoError(t, err)
+		assert.Equal(t, "https://feature.example.com/staging/eu", got)
+
+		got, err = EvaluateEnvironmentURL("https://example.com", env)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", got)
+
+		got, err = EvaluateEnvironmentURL("${{ vars.MISSING }}", env)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("outputs", func(t *testing.T) {
+		env := &exprparser.EvaluationEnvironment{
+			Needs: map[string]exprparser.Needs{
+				"build": {Outputs: map[string]string{"tag": "v1.2.3"}, Result: "success"},
+			},
+			Steps: JobStepsContext(
+				map[string]string{"url": "${{ steps.deploy.outputs.url }}", "id": "${{ steps.deploy.outputs.id }}-x"},
+				map[string]string{"url": "https://pr-1.example.com", "id": "42-x"},
+			),
+		}
+		got, err := EvaluateEnvironmentURL("${{ steps.deploy.outputs.url }}/${{ needs.build.outputs.tag }}", env)
+		require.NoError(t, err)
+		assert.Equal(t, "https://pr-1.example.com/v1.2.3", got)
+
+		// the outputs of the steps which aren't outputs of the job are unknown
+		got, err = EvaluateEnvironmentURL("${{ steps.deploy.outputs.id }}", env)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, rawURL := range []string{"javascript:alert(1)", "example.com/path", "ftp://example.com", "https://${{ fromJSON('{') }}"} {
+			_, err := EvaluateEnvironmentURL(rawURL, &exprparser.EvaluationEnvironment{})
+			assert.Error(t, err, rawURL)
+		}
+	})
+}
+
+func TestJobStepsContext(t *testing.T) {
+	steps := JobStepsContext(map[string]string{
+		"url":     "${{ steps.deploy.outputs.url }}",
+		"preview": " ${{steps.deploy.outputs.preview}} ",
+		"tag":     "${{ steps.meta.outputs.tag }}",
+		"mixed":   "${{ steps.meta.outputs.a }}-${{ steps.meta.outputs.b }}",
+		"missing": "${{ steps.other.outputs.value }}",
+	}, map[string]string{
+		"url":     "https://example.com",
+		"preview": "https://preview.example.com",
+		"tag":     "v1",
+		"mixed":   "a-b",
+	})
+	assert.Equal(t, map[string]*model.StepResult{
+		"deploy": {Outputs: map[string]string{"url": "https://example.com", "preview": "https://preview.example.com"}},
+		"meta":   {Outputs: map[string]string{"tag": "v1"}},
+	}, steps)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// CheckForbiddenCommands checks the scripts of the `run` steps in the workflow content against the rules of the forbidden commands,
+// and returns the problems of the steps which match any of the rules, it returns nil if no steps match.
+// It's a coarse guardrail rather than a sandbox: the scripts are matched as they are written, the expressions in them aren't resolved.
+// The scanning is bounded by limit, the max total size in bytes of the scripts, the workflow whose scripts exceed it is a problem
+// since it can't be checked.
+func CheckForbiddenCommands(content []byte, rules []*setting.ForbiddenCommand, limit int64) ([]string, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(workflow.Jobs))
+	for id := range workflow.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var size int64
+	for _, id := range ids {
+		if job := workflow.Jobs[id]; job != nil {
+			for _, step := range job.Steps {
+				if step != nil {
+					size += int64(len(step.Run))
+				}
+			}
+		}
+	}
+	if size > limit {
+		return []string{fmt.Sprintf("the `run` steps have %d bytes in total, more than %d bytes which can be checked for the forbidden commands", size, limit)}, nil
+	}
+
+	var problems []string
+	for _, id := range ids {
+		job := workflow.Jobs[id]
+		if job == nil {
+			continue
+		}
+		for i, step := range job.Steps {
+			if step == nil || step.Run == "" {
+				continue
+			}
+			for _, rule := range rules {
+				if loc := rule.Pattern.FindStringIndex(step.Run); loc != nil {
+					match := base.EllipsisString(step.Run[loc[0]:loc[1]], 100)
+					problems = append(problems, fmt.Sprintf("jobs.%s.steps[%d]: the command %q is forbidden by the rule %q", id, i, match, rule.Name))
+				}
+			}
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"regexp"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckForbiddenCommands(t *testing.T) {
+	rules := []*setting.ForbiddenCommand{
+		{Name: "curl-pipe-shell", Pattern: regexp.MustCompile(`curl[^|\n]*\|\s*(sudo\s+)?(ba)?sh\b`)},
+		{Name: "rm-root", Pattern: regexp.MustCompile(`rm\s+-rf\s+/(\s|$)`)},
+	}
+	content := `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: make build
+      - run: |
+          echo install
+          curl -fsSL https://example.com/install.sh | sudo bash
+  clean:
+    runs-on: ubuntu-latest
+    steps:
+      - run: rm -rf /
+`
+	tests := []struct {
+		name  string
+		rules []*setting.ForbiddenCommand
+		limit int64
+		want  []string
+	}{
+		{
+			name:  "no rules",
+			limit: 1024,
+			want:  nil,
+		},
+		{
+			name:  "forbidden",
+			rules: rules,
+			limit: 1024,
+			want: []string{
+				`jobs.build.steps[2]: the command "curl -fsSL https://example.com/install.sh | sudo bash" is forbidden by the rule "curl-pipe-shell"`,
+				`jobs.clean.steps[0]: the command "rm -rf /" is forbidden by the rule "rm-root"`,
+			},
+		},
+		{
+			name:  "too large",
+			rules: rules,
+			limit: 32,
+			want:  []string{"the `run` steps have 85 bytes in total, more than 32 bytes which can be checked for the forbidden commands"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckForbiddenCommands([]byte(content), tt.rules, tt.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseGates parses the `gate` of jobs in the workflow content, it's a Gitea extension like:
+//
+//	jobs:
+//	  confirm:
+//	    needs: build
+//	    gate:
+//	      message: Proceed to prod?
+//	      reviewers: [alice, bob]
+//	      timeout: 2h
+//
+// A gate job has no steps to run, it pauses the run until it's approved or rejected.
+// It returns the gates keyed by job id, the jobs without `gate` are not included.
+// The timeout is defaultTimeout if it's not specified.
+func ParseGates(content []byte, defaultTimeout time.Duration) (map[string]*actions_model.JobGate, error) {
+	var workflow struct {
+		Jobs map[string]struct {
+			Gate *struct {
+				Message   string   `yaml:"message"`
+				Reviewers []string `yaml:"reviewers"`
+				Timeout   string   `yaml:"timeout"`
+			} `yaml:"gate"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]*actions_model.JobGate)
+	for id, job := range workflow.Jobs {
+		if job.Gate == nil {
+			continue
+		}
+		timeout := defaultTimeout
+		if job.Gate.Timeout != "" {
+			var err error
+			if timeout, err = time.ParseDuration(job.Gate.Timeout); err != nil {
+				return nil, fmt.Errorf("invalid gate timeout of job %q: %w", id, err)
+			} else if timeout <= 0 {
+				return nil, fmt.Errorf("the gate timeout of job %q should be positive", id)
+			}
+		}
+		ret[id] = &actions_model.JobGate{
+			Message:   job.Gate.Message,
+			Reviewers: job.Gate.Reviewers,
+			Timeout:   timeout,
+		}
+	}
+	return ret, nil
+}
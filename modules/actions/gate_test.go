@@ -0,0 +1,86 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGates(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]*actions_model.JobGate
+		wantErr bool
+	}{
+		{
+			name: "no gate",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`,
+			want: map[string]*actions_model.JobGate{},
+		},
+		{
+			name: "gate with default timeout",
+			content: `
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make build
+  confirm:
+    needs: build
+    gate:
+      message: Proceed to prod?
+`,
+			want: map[string]*actions_model.JobGate{
+				"confirm": {Message: "Proceed to prod?", Timeout: 24 * time.Hour},
+			},
+		},
+		{
+			name: "gate with reviewers and timeout",
+			content: `
+jobs:
+  confirm:
+    gate:
+      message: Proceed to prod?
+      reviewers: [alice, bob]
+      timeout: 2h
+`,
+			want: map[string]*actions_model.JobGate{
+				"confirm": {Message: "Proceed to prod?", Reviewers: []string{"alice", "bob"}, Timeout: 2 * time.Hour},
+			},
+		},
+		{
+			name: "invalid timeout",
+			content: `
+jobs:
+  confirm:
+    gate:
+      timeout: -1h
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGates([]byte(tt.content), 24*time.Hour)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
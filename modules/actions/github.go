@@ -23,6 +23,15 @@ const (
 	GithubEventPullRequestComment       = "pull_request_comment"
 	GithubEventGollum                   = "gollum"
 	GithubEventSchedule                 = "schedule"
+	GithubEventMergeGroup               = "merge_group"
+	GithubEventWorkflowRun              = "workflow_run"
+	GithubEventWatch                    = "watch"
+	GithubEventPageBuild                = "page_build"
+	GithubEventLabel                    = "label"
+	GithubEventMilestone                = "milestone"
+	GithubEventRepositoryDispatch       = "repository_dispatch"
+	GithubEventOrganization             = "organization"
+	GithubEventMembership               = "membership"
 )
 
 // canGithubEventMatch check if the input Github event can match any Gitea event.
@@ -73,6 +82,42 @@ func canGithubEventMatch(eventName string, triggedEvent webhook_module.HookEvent
 	case GithubEventSchedule:
 		return triggedEvent == webhook_module.HookEventSchedule
 
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#merge_group
+	case GithubEventMergeGroup:
+		return triggedEvent == webhook_module.HookEventMergeGroup
+
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#workflow_run
+	case GithubEventWorkflowRun:
+		return triggedEvent == webhook_module.HookEventWorkflowRun
+
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#watch
+	case GithubEventWatch:
+		return triggedEvent == webhook_module.HookEventWatch
+
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#page_build
+	case GithubEventPageBuild:
+		return triggedEvent == webhook_module.HookEventPageBuild
+
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#label
+	case GithubEventLabel:
+		return triggedEvent == webhook_module.HookEventLabel
+
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#milestone
+	case GithubEventMilestone:
+		return triggedEvent == webhook_module.HookEventMilestone
+
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#repository_dispatch
+	case GithubEventRepositoryDispatch:
+		return triggedEvent == webhook_module.HookEventRepositoryDispatch
+
+	// See https://docs.github.com/en/webhooks/webhook-events-and-payloads#organization
+	case GithubEventOrganization:
+		return triggedEvent == webhook_module.HookEventOrganization
+
+	// See https://docs.github.com/en/webhooks/webhook-events-and-payloads#membership
+	case GithubEventMembership:
+		return triggedEvent == webhook_module.HookEventMembership
+
 	default:
 		return eventName == string(triggedEvent)
 	}
@@ -96,6 +96,19 @@ func TestCanGithubEventMatch(t *testing.T) {
 			webhook_module.HookEventPullRequestComment,
 			false,
 		},
+		// merge_group event
+		{
+			"merge_group matches",
+			GithubEventMergeGroup,
+			webhook_module.HookEventMergeGroup,
+			true,
+		},
+		{
+			"merge_group cannot match",
+			GithubEventMergeGroup,
+			webhook_module.HookEventPush,
+			false,
+		},
 		// other events
 		{
 			"create event",
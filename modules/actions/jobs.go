@@ -0,0 +1,34 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HasJobs reports whether the workflow content declares any job. A workflow could declare its triggers
+// but no jobs, like an empty or a null `jobs`, or even no `jobs` at all, then no run should be created for it.
+func HasJobs(content []byte) (bool, error) {
+	var workflow struct {
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return false, err
+	}
+
+	switch workflow.Jobs.Kind {
+	case 0:
+		return false, nil
+	case yaml.ScalarNode:
+		// `jobs:` without a value
+		if workflow.Jobs.Tag == "!!null" {
+			return false, nil
+		}
+	case yaml.MappingNode:
+		return len(workflow.Jobs.Content) > 0, nil
+	}
+	return false, fmt.Errorf("invalid jobs: it should be a mapping of jobs")
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "jobs",
+			content: "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    true,
+		},
+		{
+			name:    "no jobs",
+			content: "on: push\n",
+			want:    false,
+		},
+		{
+			name:    "null jobs",
+			content: "on: push\njobs:\n",
+			want:    false,
+		},
+		{
+			name:    "empty jobs",
+			content: "on: push\njobs: {}\n",
+			want:    false,
+		},
+		{
+			name:    "invalid jobs",
+			content: "on: push\njobs: [build]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HasJobs([]byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
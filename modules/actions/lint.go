@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// deprecationRule is a rule to detect a deprecated syntax in the steps of workflows
+type deprecationRule struct {
+	// Match reports whether the step uses the deprecated syntax
+	Match func(step *model.Step) bool
+	// Message tells the authors what is deprecated and how to migrate
+	Message string
+}
+
+// runCommandRule matches the steps whose scripts use the workflow command
+func runCommandRule(command, message string) deprecationRule {
+	return deprecationRule{
+		Match: func(step *model.Step) bool {
+			return strings.Contains(step.Run, "::"+command+" ") || strings.Contains(step.Run, "::"+command+"::")
+		},
+		Message: message,
+	}
+}
+
+// usesVersionsRule matches the steps which use the versions of the action
+func usesVersionsRule(action string, versions ...string) deprecationRule {
+	return deprecationRule{
+		Match: func(step *model.Step) bool {
+			name, version, ok := strings.Cut(step.Uses, "@")
+			if !ok || !strings.EqualFold(strings.TrimPrefix(name, "https://github.com/"), action) {
+				return false
+			}
+			for _, v := range versions {
+				if version == v || strings.HasPrefix(version, v+".") {
+					return true
+				}
+			}
+			return false
+		},
+		Message: fmt.Sprintf("%s@%s is deprecated, please use a newer version", action, strings.Join(versions, ", @")),
+	}
+}
+
+// deprecationRules are the rules of deprecated syntax, new rules could be appended when more syntax is deprecated
+var deprecationRules = []deprecationRule{
+	runCommandRule("set-output", "the `set-output` command is deprecated, please write to $GITHUB_OUTPUT instead"),
+	runCommandRule("save-state", "the `save-state` command is deprecated, please write to $GITHUB_STATE instead"),
+	runCommandRule("set-env", "the `set-env` command has been removed, please write to $GITHUB_ENV instead"),
+	runCommandRule("add-path", "the `add-path` command has been removed, please write to $GITHUB_PATH instead"),
+	usesVersionsRule("actions/checkout", "v1", "v2", "v3"),
+	usesVersionsRule("actions/setup-node", "v1", "v2", "v3"),
+	usesVersionsRule("actions/setup-go", "v1", "v2", "v3", "v4"),
+	usesVersionsRule("actions/setup-python", "v1", "v2", "v3", "v4"),
+	usesVersionsRule("actions/setup-java", "v1", "v2", "v3"),
+	usesVersionsRule("actions/cache", "v1", "v2", "v3"),
+	usesVersionsRule("actions/upload-artifact", "v1", "v2"),
+	usesVersionsRule("actions/download-artifact", "v1", "v2"),
+}
+
+// LintWorkflow detects the deprecated syntax in the workflow content.
+// The warnings don't block the runs, and the invalid workflows are not reported since they are parse errors.
+func LintWorkflow(content []byte) []string {
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		return nil
+	}
+
+	jobIDs := make([]string, 0, len(workflow.Jobs))
+	for id := range workflow.Jobs {
+		jobIDs = append(jobIDs, id)
+	}
+	sort.Strings(jobIDs)
+
+	var warnings []string
+	for _, id := range jobIDs {
+		job := workflow.Jobs[id]
+		if job == nil {
+			continue
+		}
+		for i, step := range job.Steps {
+			if step == nil {
+				continue
+			}
+			for _, rule := range deprecationRules {
+				if rule.Match(step) {
+					warnings = append(warnings, fmt.Sprintf("jobs.%s.steps[%d]: %s", id, i, rule.Message))
+				}
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "no deprecated syntax",
+			content: `
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: echo "result=ok" >> $GITHUB_OUTPUT
+`,
+			want: nil,
+		},
+		{
+			name: "deprecated commands and actions",
+			content: `
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v2
+      - run: echo "::set-output name=result::ok"
+      - uses: https://github.com/actions/setup-go@v3.5.0
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "::add-path::/opt/bin"
+`,
+			want: []string{
+				"jobs.build.steps[0]: the `add-path` command has been removed, please write to $GITHUB_PATH instead",
+				"jobs.test.steps[0]: actions/checkout@v1, @v2, @v3 is deprecated, please use a newer version",
+				"jobs.test.steps[1]: the `set-output` command is deprecated, please write to $GITHUB_OUTPUT instead",
+				"jobs.test.steps[2]: actions/setup-go@v1, @v2, @v3, @v4 is deprecated, please use a newer version",
+			},
+		},
+		{
+			name: "similar names are not matched",
+			content: `
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout-v2@v1
+      - uses: actions/cache@v20
+`,
+			want: nil,
+		},
+		{
+			name:    "invalid workflow",
+			content: "on: [",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LintWorkflow([]byte(tt.content)))
+		})
+	}
+}
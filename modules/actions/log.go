@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/dbfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
@@ -78,7 +79,20 @@ func ReadLogs(ctx context.Context, inStorage bool, filename string, offset, limi
 		return nil, err
 	}
 	defer f.Close()
+	return readLogs(f, offset, limit)
+}
+
+// ReadTaskLogs reads the logs of the task like ReadLogs, they are read from the archive storage if the local copy has been pruned
+func ReadTaskLogs(ctx context.Context, task *actions_model.ActionTask, offset, limit int64) ([]*runnerv1.LogRow, error) {
+	f, err := OpenTaskLogs(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readLogs(f, offset, limit)
+}
 
+func readLogs(f io.ReadSeeker, offset, limit int64) ([]*runnerv1.LogRow, error) {
 	if _, err := f.Seek(offset, io.SeekStart); err != nil {
 		return nil, fmt.Errorf("file seek: %w", err)
 	}
@@ -157,6 +171,45 @@ func OpenLogs(ctx context.Context, inStorage bool, filename string) (io.ReadSeek
 	return f, nil
 }
 
+// OpenTaskLogs opens the logs of the task, they are opened from the archive storage if the local copy has been pruned
+func OpenTaskLogs(ctx context.Context, task *actions_model.ActionTask) (io.ReadSeekCloser, error) {
+	if !task.LogPruned {
+		return OpenLogs(ctx, task.LogInStorage, task.LogFilename)
+	}
+	f, err := storage.ActionsLogArchive.Open(task.LogArchivePath)
+	if err != nil {
+		return nil, fmt.Errorf("archive storage open %q: %w", task.LogArchivePath, err)
+	}
+	return f, nil
+}
+
+// ArchiveLogs copies the logs which have been transferred to the storage to the archive storage,
+// and returns the path of them in the archive storage. The local copy is kept.
+func ArchiveLogs(filename string) (string, error) {
+	f, err := storage.Actions.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("storage open %q: %w", filename, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("storage stat %q: %w", filename, err)
+	}
+	if _, err := storage.ActionsLogArchive.Save(filename, f, stat.Size()); err != nil {
+		return "", fmt.Errorf("archive storage save %q: %w", filename, err)
+	}
+	return filename, nil
+}
+
+// RemoveArchivedLogs removes the logs from the archive storage
+func RemoveArchivedLogs(path string) error {
+	if err := storage.ActionsLogArchive.Delete(path); err != nil {
+		return fmt.Errorf("archive storage delete %q: %w", path, err)
+	}
+	return nil
+}
+
 func FormatLog(timestamp time.Time, content string) string {
 	// Content shouldn't contain new line, it will break log indexes, other control chars are safe.
 	content = strings.ReplaceAll(content, "\n", `\n`)
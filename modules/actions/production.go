@@ -0,0 +1,111 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v3"
+)
+
+// IsProductionDeploy reports whether the workflow deploys to production, which is true if any job
+// has an `environment` whose name matches one of the patterns, or is annotated by `production: true`, a Gitea extension like:
+//
+//	jobs:
+//	  deploy:
+//	    environment: production
+//	  release:
+//	    environment:
+//	      name: prod-eu
+//	  publish:
+//	    production: true
+//
+// The patterns are globs, and the names of environments are matched case-insensitively like GitHub.
+func IsProductionDeploy(content []byte, patterns []string) (bool, error) {
+	var workflow struct {
+		Jobs map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+			Production  bool      `yaml:"production"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return false, err
+	}
+
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(strings.ToLower(pattern))
+		if err != nil {
+			return false, fmt.Errorf("invalid production environment pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+
+	for id, job := range workflow.Jobs {
+		if job.Production {
+			return true, nil
+		}
+		name, err := environmentName(&job.Environment)
+		if err != nil {
+			return false, fmt.Errorf("invalid environment of job %q: %w", id, err)
+		}
+		if name == "" {
+			continue
+		}
+		for _, g := range globs {
+			if g.Match(strings.ToLower(name)) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// environmentName returns the name of the `environment` of a job, which could be a name or a mapping with `name`
+func environmentName(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case 0:
+		return "", nil
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.MappingNode:
+		var v struct {
+			Name string `yaml:"name"`
+		}
+		if err := node.Decode(&v); err != nil {
+			return "", err
+		}
+		return v.Name, nil
+	default:
+		return "", fmt.Errorf("unsupported kind %d", node.Kind)
+	}
+}
+
+// JobEnvironments returns the lower-cased names of the `environment` of the jobs in the workflow content, they are sorted and unique
+func JobEnvironments(content []byte) ([]string, error) {
+	var workflow struct {
+		Jobs map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for id, job := range workflow.Jobs {
+		name, err := environmentName(&job.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid environment of job %q: %w", id, err)
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProductionDeploy(t *testing.T) {
+	patterns := []string{"production", "prod-*"}
+
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "no environment",
+			content: `
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+`,
+			want: false,
+		},
+		{
+			name: "environment name",
+			content: `
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+  deploy:
+    needs: test
+    runs-on: ubuntu-latest
+    environment: Production
+`,
+			want: true,
+		},
+		{
+			name: "environment mapping matches pattern",
+			content: `
+on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    environment:
+      name: prod-eu
+      url: https://example.com
+`,
+			want: true,
+		},
+		{
+			name: "other environment",
+			content: `
+on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    environment: staging
+`,
+			want: false,
+		},
+		{
+			name: "annotated",
+			content: `
+on: push
+jobs:
+  publish:
+    runs-on: ubuntu-latest
+    production: true
+`,
+			want: true,
+		},
+		{
+			name: "invalid environment",
+			content: `
+on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    environment: [production]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsProductionDeploy([]byte(tt.content), patterns)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := IsProductionDeploy([]byte("jobs:\n  deploy:\n    environment: production\n"), nil)
+	assert.NoError(t, err)
+	assert.False(t, got)
+}
+
+func TestJobEnvironments(t *testing.T) {
+	environments, err := JobEnvironments([]byte(`
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+  staging:
+    runs-on: ubuntu-latest
+    environment: Staging
+  deploy-eu:
+    runs-on: ubuntu-latest
+    environment:
+      name: production
+  deploy-us:
+    runs-on: ubuntu-latest
+    environment: production
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"production", "staging"}, environments)
+}
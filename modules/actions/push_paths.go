@@ -0,0 +1,73 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// pushChangedFilesCache caches the changed files of the recent pushes keyed by the commits before and after the push,
+// so the diff is computed once for all workflows of a push which have the paths filters.
+var pushChangedFilesCache, _ = lru.New[string, *pushChangedFilesResult](64)
+
+type pushChangedFilesResult struct {
+	files []string
+	ok    bool
+}
+
+// pushChangedFiles returns the changed files of the push to match the `paths` and `paths-ignore` filters,
+// which are the difference between the commit before the push and the pushed commit.
+// If the push creates a branch, or the commit before the push no longer exists after a force push,
+// they are compared with the parent of the pushed commit, or all files of the pushed commit if it has no parent.
+// It returns false if the push changes more files than setting.Actions.PathsFilterMaxFiles or the changed files can't be determined,
+// then the filters should be ignored, so the workflows are triggered rather than skipped silently.
+func pushChangedFiles(commit *git.Commit, before string) ([]string, bool) {
+	emptyID := commit.ID.Type().EmptyObjectID().String()
+	if before == "" {
+		before = emptyID
+	}
+	key := before + ".." + commit.ID.String()
+	if result, ok := pushChangedFilesCache.Get(key); ok {
+		return result.files, result.ok
+	}
+
+	files, err := commit.GetFilesChangedSinceCommit(before)
+	if err != nil && before != emptyID {
+		log.Debug("GetFilesChangedSinceCommit [commit_sha1: %s, before: %s]: %v, compare with the parent instead", commit.ID.String(), before, err)
+		files, err = commit.GetFilesChangedSinceCommit(emptyID)
+	}
+	if err != nil {
+		log.Error("GetFilesChangedSinceCommit [commit_sha1: %s]: %v, the paths filters are ignored", commit.ID.String(), err)
+		return nil, false
+	}
+
+	result := &pushChangedFilesResult{files: files, ok: true}
+	if len(files) > setting.Actions.PathsFilterMaxFiles {
+		log.Warn("the push of commit %s changes %d files which are more than %d, the paths filters are ignored", commit.ID.String(), len(files), setting.Actions.PathsFilterMaxFiles)
+		result = &pushChangedFilesResult{}
+	}
+	pushChangedFilesCache.Add(key, result)
+	return result.files, result.ok
+}
+
+// pullRequestChangedFiles returns the changed files of the pull request to match the `paths` and `paths-ignore` filters,
+// which are compared with the base branch according to setting.Actions.PullRequestPathsDiff.
+// The three-dot diff compares the head with the merge base, so the commits pushed to the base branch after the head branched off don't count,
+// it falls back to the two-dot diff which compares the head with the tip of the base branch if the merge base can't be found.
+func pullRequestChangedFiles(gitRepo *git.Repository, headCommit *git.Commit, baseRef string) ([]string, error) {
+	base := baseRef
+	if setting.Actions.PullRequestPathsDiff == setting.PullRequestPathsDiffThreeDot {
+		mergeBase, _, err := gitRepo.GetMergeBase("", baseRef, headCommit.ID.String())
+		if err != nil {
+			log.Debug("GetMergeBase [base: %s, head: %s]: %v, compare with the tip of the base branch instead", baseRef, headCommit.ID.String(), err)
+		} else {
+			base = mergeBase
+		}
+	}
+	return headCommit.GetFilesChangedSinceCommit(base)
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushChangedFiles(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.HomePath, t.TempDir())()
+	require.NoError(t, git.InitSimple(context.Background()))
+	repo, err := git.OpenRepository(context.Background(), filepath.Join("..", "git", "tests", "repos", "repo1_bare"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	commit, err := repo.GetCommit("5c80b0245c1c6f8343fa418ec374b13b5d4ee658")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		before   string
+		maxFiles int
+		files    []string
+		ok       bool
+	}{
+		{
+			name:     "push",
+			before:   "95bb4d39648ee7e325106df01a621c530863a653",
+			maxFiles: 10,
+			files:    []string{"file2.txt", "branch2/branch2.txt"},
+			ok:       true,
+		},
+		{
+			name:     "new branch",
+			before:   git.Sha1ObjectFormat.EmptyObjectID().String(),
+			maxFiles: 10,
+			files:    []string{"branch2/branch2.txt"},
+			ok:       true,
+		},
+		{
+			name:     "force push",
+			before:   "1234567890123456789012345678901234567890",
+			maxFiles: 10,
+			files:    []string{"branch2/branch2.txt"},
+			ok:       true,
+		},
+		{
+			name:     "too many files",
+			before:   "95bb4d39648ee7e325106df01a621c530863a653",
+			maxFiles: 1,
+			ok:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Actions.PathsFilterMaxFiles, tt.maxFiles)()
+			pushChangedFilesCache.Purge()
+			files, ok := pushChangedFiles(commit, tt.before)
+			assert.Equal(t, tt.ok, ok)
+			assert.ElementsMatch(t, tt.files, files)
+		})
+	}
+}
+
+func TestPullRequestChangedFiles(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.HomePath, t.TempDir())()
+	require.NoError(t, git.InitSimple(context.Background()))
+	repo, err := git.OpenRepository(context.Background(), filepath.Join("..", "git", "tests", "repos", "repo1_bare"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	// branch2 branched off master, and more commits have been pushed to master since then
+	head, err := repo.GetBranchCommit("branch2")
+	require.NoError(t, err)
+
+	t.Run("three-dot", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.Actions.PullRequestPathsDiff, setting.PullRequestPathsDiffThreeDot)()
+		files, err := pullRequestChangedFiles(repo, head, "master")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"branch2/branch2.txt"}, files)
+	})
+
+	t.Run("two-dot", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.Actions.PullRequestPathsDiff, setting.PullRequestPathsDiffTwoDot)()
+		files, err := pullRequestChangedFiles(repo, head, "master")
+		require.NoError(t, err)
+		assert.Contains(t, files, "branch2/branch2.txt")
+		assert.Greater(t, len(files), 1, "the changes of master after branch2 branched off count")
+	})
+}
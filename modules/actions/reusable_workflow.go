@@ -0,0 +1,73 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReusableWorkflowCall is a job calling a reusable workflow of another repository by a moving ref, like:
+//
+//	jobs:
+//	  build:
+//	    uses: org/shared-ci/.gitea/workflows/build.yml@main
+type ReusableWorkflowCall struct {
+	Repo string // the lower-cased full name of the repository of the reusable workflow, like "org/shared-ci"
+	Path string // the path of the reusable workflow in the repository, like ".gitea/workflows/build.yml"
+	Ref  string // the branch or tag name, like "main" or "v1"
+}
+
+var commitSHAPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// ParseReusableWorkflowCalls returns the distinct calls of the jobs in the workflow to the reusable workflows of other repositories.
+// The local calls like `./.gitea/workflows/build.yml` and the calls pinned by commit SHAs are not included, they never change.
+func ParseReusableWorkflowCalls(content []byte) ([]*ReusableWorkflowCall, error) {
+	var workflow struct {
+		Jobs map[string]struct {
+			Uses string `yaml:"uses"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	var calls []*ReusableWorkflowCall
+	seen := make(map[ReusableWorkflowCall]bool, len(workflow.Jobs))
+	for _, job := range workflow.Jobs {
+		call := parseReusableWorkflowCall(job.Uses)
+		if call == nil || seen[*call] {
+			continue
+		}
+		seen[*call] = true
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// parseReusableWorkflowCall parses `{owner}/{repo}/{path}@{ref}`, it returns nil if the job doesn't call a reusable workflow by a moving ref
+func parseReusableWorkflowCall(uses string) *ReusableWorkflowCall {
+	uses = strings.TrimSpace(uses)
+	if uses == "" || strings.HasPrefix(uses, "./") || strings.Contains(uses, "${{") {
+		return nil
+	}
+	target, ref, ok := strings.Cut(uses, "@")
+	if !ok || ref == "" || commitSHAPattern.MatchString(ref) {
+		return nil
+	}
+	parts := strings.SplitN(target, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	if !strings.HasPrefix(parts[2], ".gitea/workflows/") && !strings.HasPrefix(parts[2], ".github/workflows/") {
+		return nil
+	}
+	return &ReusableWorkflowCall{
+		Repo: strings.ToLower(parts[0] + "/" + parts[1]),
+		Path: parts[2],
+		Ref:  ref,
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReusableWorkflowCalls(t *testing.T) {
+	content := []byte(`
+on: push
+jobs:
+  build:
+    uses: Org/Shared-CI/.gitea/workflows/build.yml@main
+  build-again:
+    uses: org/shared-ci/.gitea/workflows/build.yml@main
+  release:
+    uses: org/shared-ci/.github/workflows/release.yml@v1
+  pinned:
+    uses: org/shared-ci/.gitea/workflows/build.yml@8cbd7e6ba3b5ec7c9e57b18c298d9b8c8bbb1a4d
+  local:
+    uses: ./.gitea/workflows/local.yml
+  expression:
+    uses: org/shared-ci/.gitea/workflows/build.yml@${{ inputs.ref }}
+  no-ref:
+    uses: org/shared-ci/.gitea/workflows/build.yml
+  action:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`)
+	calls, err := ParseReusableWorkflowCalls(content)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*ReusableWorkflowCall{
+		{Repo: "org/shared-ci", Path: ".gitea/workflows/build.yml", Ref: "main"},
+		{Repo: "org/shared-ci", Path: ".github/workflows/release.yml", Ref: "v1"},
+	}, calls)
+}
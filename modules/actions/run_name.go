@@ -0,0 +1,106 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// maxRunNameSize is the max size in bytes of the evaluated `run-name`, it's the size of the title column of runs
+const maxRunNameSize = 255
+
+// EvaluateRunName evaluates the `run-name` of the workflow content to be the title of a run.
+// Like GitHub, only the `github`, `inputs` and `vars` contexts are available to the expressions in it.
+// The result is sanitized to be a single line and capped to maxRunNameSize, it's empty if the workflow has no `run-name`.
+func EvaluateRunName(content []byte, gitCtx *model.GithubContext, inputs map[string]any, vars map[string]string) (string, error) {
+	var workflow struct {
+		RunName string `yaml:"run-name"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return "", err
+	}
+	if workflow.RunName == "" {
+		return "", nil
+	}
+
+	interpreter := exprparser.NewInterpeter(&exprparser.EvaluationEnvironment{
+		Github: gitCtx,
+		Inputs: inputs,
+		Vars:   vars,
+	}, exprparser.Config{
+		Run:     &model.Run{Workflow: &model.Workflow{}},
+		Context: "workflow",
+	})
+
+	runName, err := interpolateExpressions(interpreter, workflow.RunName)
+	if err != nil {
+		return "", fmt.Errorf("evaluate run-name %q: %w", workflow.RunName, err)
+	}
+	return sanitizeRunName(runName), nil
+}
+
+// interpolateExpressions replaces the expressions in the value with their results
+func interpolateExpressions(interpreter exprparser.Interpreter, value string) (string, error) {
+	var evalErr error
+	ret := expressionPattern.ReplaceAllStringFunc(value, func(expr string) string {
+		if evalErr != nil {
+			return ""
+		}
+		var result string
+		result, evalErr = evaluateExpressionString(interpreter, expressionPattern.FindStringSubmatch(expr)[1])
+		return result
+	})
+	return ret, evalErr
+}
+
+// evaluateExpressionString evaluates the expression and formats the result like GitHub does when it's in a string
+func evaluateExpressionString(interpreter exprparser.Interpreter, expr string) (ret string, err error) {
+	defer func() {
+		// the functions which need the jobs, like success(), are not available to the expressions evaluated by Gitea
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to evaluate %q: %v", expr, r)
+		}
+	}()
+	value, err := interpreter.Evaluate(expr, exprparser.DefaultStatusCheckNone)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+}
+
+// sanitizeRunName replaces the line breaks and other control characters with spaces, and caps the size of the run name
+func sanitizeRunName(name string) string {
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+	name, _ = util.SplitStringAtByteN(name, maxRunNameSize)
+	return name
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateRunName(t *testing.T) {
+	gitCtx := &model.GithubContext{
+		Actor:     "user2",
+		EventName: "push",
+		Ref:       "refs/heads/main",
+		Event: map[string]any{
+			"head_commit": map[string]any{"message": "fix the bug"},
+		},
+	}
+	inputs := map[string]any{"environment": "staging", "dry-run": true, "count": float64(3), "notes": "line1\n\tline2"}
+	vars := map[string]string{"TEAM": "infra"}
+
+	tests := []struct {
+		name    string
+		runName string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no run-name",
+			want: "",
+		},
+		{
+			name:    "plain",
+			runName: "Deploy",
+			want:    "Deploy",
+		},
+		{
+			name:    "inputs and actor",
+			runName: "Deploy to ${{ inputs.environment }} by @${{ github.actor }}",
+			want:    "Deploy to staging by @user2",
+		},
+		{
+			name:    "not strings",
+			runName: "dry-run: ${{ inputs.dry-run }}, count: ${{ inputs.count }}, missing: '${{ inputs.missing }}'",
+			want:    "dry-run: true, count: 3, missing: ''",
+		},
+		{
+			name:    "event and vars",
+			runName: "${{ vars.team }}: ${{ github.event.head_commit.message }} (${{ github.event_name }})",
+			want:    "infra: fix the bug (push)",
+		},
+		{
+			name:    "functions",
+			runName: "${{ format('{0} on {1}', github.actor, github.ref) }}",
+			want:    "user2 on refs/heads/main",
+		},
+		{
+			name:    "sanitized",
+			runName: "Notes: ${{ inputs.notes }}",
+			want:    "Notes: line1 line2",
+		},
+		{
+			name:    "secrets are unavailable",
+			runName: "token: '${{ secrets.TOKEN }}'",
+			want:    "token: ''",
+		},
+		{
+			name:    "unknown context",
+			runName: "${{ unknown.value }}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid expression",
+			runName: "${{ github.actor == }}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "on: push\n"
+			if tt.runName != "" {
+				content += "run-name: \"" + tt.runName + "\"\n"
+			}
+			content += "jobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			got, err := EvaluateRunName([]byte(content), gitCtx, inputs, vars)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("capped", func(t *testing.T) {
+		got, err := EvaluateRunName([]byte("run-name: "+strings.Repeat("a", 300)+"\n"), gitCtx, nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, got, maxRunNameSize)
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseRunTimeout parses the `run-timeout-minutes` of the workflow content, it's a Gitea extension like:
+//
+//	run-timeout-minutes: 120
+//
+// Unlike the `timeout-minutes` of a job, it limits the whole run, counting from the time the first job started,
+// and the unfinished jobs are cancelled once it's exceeded. It returns zero if the workflow has no `run-timeout-minutes`,
+// which means the run is unlimited.
+func ParseRunTimeout(content []byte) (time.Duration, error) {
+	var workflow struct {
+		RunTimeoutMinutes yaml.Node `yaml:"run-timeout-minutes"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return 0, err
+	}
+	if workflow.RunTimeoutMinutes.Kind == 0 {
+		return 0, nil
+	}
+
+	var minutes int64
+	if err := workflow.RunTimeoutMinutes.Decode(&minutes); err != nil {
+		// an expression can't be resolved before the run is created
+		return 0, fmt.Errorf("invalid run-timeout-minutes %q: it should be a number of minutes", workflow.RunTimeoutMinutes.Value)
+	}
+	if minutes <= 0 {
+		return 0, fmt.Errorf("invalid run-timeout-minutes %d: it should be positive", minutes)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
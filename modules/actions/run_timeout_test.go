@@ -0,0 +1,52 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRunTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "unlimited",
+			content: "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    0,
+		},
+		{
+			name:    "minutes",
+			content: "on: push\nrun-timeout-minutes: 90\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			want:    90 * time.Minute,
+		},
+		{
+			name:    "zero",
+			content: "on: push\nrun-timeout-minutes: 0\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			wantErr: true,
+		},
+		{
+			name:    "expression",
+			content: "on: push\nrun-timeout-minutes: ${{ vars.RUN_TIMEOUT }}\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRunTimeout([]byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
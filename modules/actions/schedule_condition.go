@@ -0,0 +1,98 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ScheduleCommitsSinceLastSuccess is true if the ref has new commits since the last successful scheduled run
+	ScheduleCommitsSinceLastSuccess = "last-success"
+	// ScheduleCommitsSinceLastRun is true if the ref has new commits since the last scheduled run, whatever its result
+	ScheduleCommitsSinceLastRun = "last-run"
+)
+
+// ScheduleCondition is a predicate of the `schedule-if` of a workflow, only one of the fields is set
+type ScheduleCondition struct {
+	// CommitsSince is ScheduleCommitsSinceLastSuccess or ScheduleCommitsSinceLastRun
+	CommitsSince string
+	// FileExists is the path of a file which should exist in the commit of the schedule
+	FileExists string
+	// EnvFlag is the name of a variable which should be true
+	EnvFlag string
+}
+
+func (c *ScheduleCondition) String() string {
+	switch {
+	case c.CommitsSince != "":
+		return "commits-since: " + c.CommitsSince
+	case c.FileExists != "":
+		return "file-exists: " + c.FileExists
+	default:
+		return "env-flag: " + c.EnvFlag
+	}
+}
+
+// ParseScheduleConditions parses the `schedule-if` of the workflow content, it's a Gitea extension like:
+//
+//	schedule-if:
+//	  - commits-since: last-success
+//	  - file-exists: nightly/config.yml
+//	  - env-flag: NIGHTLY_ENABLED
+//
+// They are evaluated when a cron spec of the schedule event fires, and the run is created only if all of them are true.
+// They don't affect the other events of the workflow. It returns nil if the workflow has no `schedule-if`,
+// which means the schedules are unconditional.
+func ParseScheduleConditions(content []byte) ([]*ScheduleCondition, error) {
+	var workflow struct {
+		ScheduleIf yaml.Node `yaml:"schedule-if"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+	switch workflow.ScheduleIf.Kind {
+	case 0:
+		return nil, nil
+	case yaml.SequenceNode:
+	default:
+		return nil, fmt.Errorf("invalid schedule-if: it should be a list of conditions")
+	}
+
+	var items []map[string]string
+	if err := workflow.ScheduleIf.Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid schedule-if: %w", err)
+	}
+	ret := make([]*ScheduleCondition, 0, len(items))
+	for _, item := range items {
+		if len(item) != 1 {
+			return nil, fmt.Errorf("invalid condition %v of schedule-if: it should have a single predicate", item)
+		}
+		for key, value := range item {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil, fmt.Errorf("invalid condition %q of schedule-if: it has no value", key)
+			}
+			cond := &ScheduleCondition{}
+			switch key {
+			case "commits-since":
+				if value != ScheduleCommitsSinceLastSuccess && value != ScheduleCommitsSinceLastRun {
+					return nil, fmt.Errorf("invalid commits-since %q of schedule-if: it should be %q or %q", value, ScheduleCommitsSinceLastSuccess, ScheduleCommitsSinceLastRun)
+				}
+				cond.CommitsSince = value
+			case "file-exists":
+				cond.FileExists = strings.TrimPrefix(value, "./")
+			case "env-flag":
+				cond.EnvFlag = value
+			default:
+				return nil, fmt.Errorf("unknown condition %q of schedule-if: it should be commits-since, file-exists or env-flag", key)
+			}
+			ret = append(ret, cond)
+		}
+	}
+	return ret, nil
+}
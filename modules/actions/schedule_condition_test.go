@@ -0,0 +1,72 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScheduleConditions(t *testing.T) {
+	const head = "on:\n  schedule:\n    - cron: '0 2 * * *'\n"
+	const jobs = "jobs:\n  build:\n    runs-on: ubuntu-latest\n"
+	tests := []struct {
+		name    string
+		content string
+		want    []*ScheduleCondition
+		wantErr bool
+	}{
+		{
+			name:    "unconditional",
+			content: head + jobs,
+			want:    nil,
+		},
+		{
+			name:    "conditions",
+			content: head + "schedule-if:\n  - commits-since: last-success\n  - file-exists: ./nightly/config.yml\n  - env-flag: NIGHTLY_ENABLED\n" + jobs,
+			want: []*ScheduleCondition{
+				{CommitsSince: ScheduleCommitsSinceLastSuccess},
+				{FileExists: "nightly/config.yml"},
+				{EnvFlag: "NIGHTLY_ENABLED"},
+			},
+		},
+		{
+			name:    "not a list",
+			content: head + "schedule-if: commits-since\n" + jobs,
+			wantErr: true,
+		},
+		{
+			name:    "unknown predicate",
+			content: head + "schedule-if:\n  - branch-exists: main\n" + jobs,
+			wantErr: true,
+		},
+		{
+			name:    "unknown commits-since",
+			content: head + "schedule-if:\n  - commits-since: yesterday\n" + jobs,
+			wantErr: true,
+		},
+		{
+			name:    "multiple predicates in a condition",
+			content: head + "schedule-if:\n  - commits-since: last-run\n    env-flag: NIGHTLY_ENABLED\n" + jobs,
+			wantErr: true,
+		},
+		{
+			name:    "no value",
+			content: head + "schedule-if:\n  - file-exists: ''\n" + jobs,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScheduleConditions([]byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
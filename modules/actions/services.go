@@ -0,0 +1,222 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/kballard/go-shellquote"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseJobServices parses the service containers of jobs in the workflow content, like:
+//
+//	jobs:
+//	  test:
+//	    services:
+//	      postgres:
+//	        image: postgres:16
+//	        env:
+//	          POSTGRES_PASSWORD: ${{ secrets.DB_PASSWORD }}
+//	        ports: ["5432:5432"]
+//	        options: --health-cmd pg_isready --health-interval 10s --health-retries 5
+//	      redis:
+//	        image: redis:7
+//	        health:
+//	          cmd: redis-cli ping
+//	          interval: 5s
+//
+// The health check is parsed from the `--health-*` flags of `options` like GitHub, or from `health`, a Gitea extension
+// which overrides the flags. The flags of `health` are appended to `options`, so the runner creates the container with the health check
+// and waits until it's healthy before running the steps.
+// The expressions which only reference a variable are resolved with vars since they are known when the run is created,
+// the other expressions, including the ones referencing `secrets`, are kept as they are to be evaluated by the runner.
+// It returns the services keyed by job id and sorted by name, the jobs without services are not included.
+func ParseJobServices(content []byte, vars map[string]string) (map[string][]*actions_model.JobService, error) {
+	var workflow struct {
+		Jobs map[string]struct {
+			Services yaml.Node `yaml:"services"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string][]*actions_model.JobService)
+	for id, job := range workflow.Jobs {
+		if job.Services.Kind != yaml.MappingNode {
+			// like `services: ${{ fromJSON(vars.SERVICES) }}`, it can't be parsed before it's evaluated, so it's left to the runner
+			continue
+		}
+		var specs map[string]*serviceSpec
+		if err := job.Services.Decode(&specs); err != nil {
+			return nil, fmt.Errorf("invalid services of job %q: %w", id, err)
+		}
+		services := make([]*actions_model.JobService, 0, len(specs))
+		for name, spec := range specs {
+			service, err := parseJobService(name, spec, vars)
+			if err != nil {
+				return nil, fmt.Errorf("invalid service %q of job %q: %w", name, id, err)
+			}
+			services = append(services, service)
+		}
+		if len(services) == 0 {
+			continue
+		}
+		sort.Slice(services, func(i, j int) bool {
+			return services[i].Name < services[j].Name
+		})
+		ret[id] = services
+	}
+	return ret, nil
+}
+
+type serviceSpec struct {
+	Image   string    `yaml:"image"`
+	Env     yaml.Node `yaml:"env"`
+	Ports   []string  `yaml:"ports"`
+	Options string    `yaml:"options"`
+	Health  *struct {
+		Cmd         string `yaml:"cmd"`
+		Interval    string `yaml:"interval"`
+		Timeout     string `yaml:"timeout"`
+		StartPeriod string `yaml:"start-period"`
+		Retries     int    `yaml:"retries"`
+	} `yaml:"health"`
+}
+
+func parseJobService(name string, spec *serviceSpec, vars map[string]string) (*actions_model.JobService, error) {
+	if spec == nil || strings.TrimSpace(spec.Image) == "" {
+		return nil, fmt.Errorf("image is required")
+	}
+	env, err := decodeEnv(&spec.Env)
+	if err != nil {
+		return nil, fmt.Errorf("invalid env: %w", err)
+	}
+	for k, v := range env {
+		env[k] = resolveVarsExpressions(v, vars)
+	}
+	ports := make([]string, 0, len(spec.Ports))
+	for _, port := range spec.Ports {
+		ports = append(ports, resolveVarsExpressions(port, vars))
+	}
+
+	service := &actions_model.JobService{
+		Name:    name,
+		Image:   resolveVarsExpressions(spec.Image, vars),
+		Ports:   ports,
+		Env:     env,
+		Options: resolveVarsExpressions(spec.Options, vars),
+	}
+	if service.Health, err = parseHealthFlags(service.Options); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	if h := spec.Health; h != nil {
+		if strings.TrimSpace(h.Cmd) == "" {
+			return nil, fmt.Errorf("health.cmd is required")
+		}
+		health := &actions_model.ServiceHealth{Cmd: resolveVarsExpressions(h.Cmd, vars), Retries: h.Retries}
+		for _, v := range []struct {
+			field string
+			value string
+			ret   *time.Duration
+		}{
+			{"interval", h.Interval, &health.Interval},
+			{"timeout", h.Timeout, &health.Timeout},
+			{"start-period", h.StartPeriod, &health.StartPeriod},
+		} {
+			if *v.ret, err = parseHealthDuration(v.value); err != nil {
+				return nil, fmt.Errorf("invalid health.%s: %w", v.field, err)
+			}
+		}
+		if health.Retries < 0 {
+			return nil, fmt.Errorf("health.retries should not be negative")
+		}
+		service.Health = health
+		service.Options = strings.TrimSpace(service.Options + " " + healthFlags(health))
+	}
+	return service, nil
+}
+
+// parseHealthFlags parses the health check from the `--health-*` flags of the options of `docker create`, it returns nil if there is no `--health-cmd`
+func parseHealthFlags(options string) (*actions_model.ServiceHealth, error) {
+	args, err := shellquote.Split(options)
+	if err != nil {
+		return nil, err
+	}
+	health := &actions_model.ServiceHealth{}
+	for i := 0; i < len(args); i++ {
+		flag, value, ok := strings.Cut(args[i], "=")
+		if !strings.HasPrefix(flag, "--health-") {
+			continue
+		}
+		if !ok {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag %s needs a value", flag)
+			}
+			i++
+			value = args[i]
+		}
+		switch flag {
+		case "--health-cmd":
+			health.Cmd = value
+		case "--health-interval":
+			health.Interval, err = parseHealthDuration(value)
+		case "--health-timeout":
+			health.Timeout, err = parseHealthDuration(value)
+		case "--health-start-period":
+			health.StartPeriod, err = parseHealthDuration(value)
+		case "--health-retries":
+			if health.Retries, err = strconv.Atoi(value); err == nil && health.Retries < 0 {
+				err = fmt.Errorf("should not be negative")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", flag, value, err)
+		}
+	}
+	if health.Cmd == "" {
+		return nil, nil
+	}
+	return health, nil
+}
+
+// parseHealthDuration parses a duration of the health check like "10s", an empty value is zero
+func parseHealthDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	} else if d < 0 {
+		return 0, fmt.Errorf("should not be negative")
+	}
+	return d, nil
+}
+
+// healthFlags returns the `--health-*` flags of `docker create` for the health check, the later flags override the earlier ones
+func healthFlags(health *actions_model.ServiceHealth) string {
+	args := []string{"--health-cmd", health.Cmd}
+	if health.Interval > 0 {
+		args = append(args, "--health-interval", health.Interval.String())
+	}
+	if health.Timeout > 0 {
+		args = append(args, "--health-timeout", health.Timeout.String())
+	}
+	if health.StartPeriod > 0 {
+		args = append(args, "--health-start-period", health.StartPeriod.String())
+	}
+	if health.Retries > 0 {
+		args = append(args, "--health-retries", strconv.Itoa(health.Retries))
+	}
+	return shellquote.Join(args...)
+}
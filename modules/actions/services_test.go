@@ -0,0 +1,134 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJobServices(t *testing.T) {
+	vars := map[string]string{"PG_VERSION": "16"}
+
+	t.Run("multiple services", func(t *testing.T) {
+		services, err := ParseJobServices([]byte(`
+jobs:
+  build:
+    runs-on: ubuntu-latest
+  test:
+    runs-on: ubuntu-latest
+    services:
+      redis:
+        image: redis:7
+        ports:
+          - 6379
+        health:
+          cmd: redis-cli ping
+          interval: 5s
+          retries: 10
+      postgres:
+        image: postgres:${{ vars.PG_VERSION }}
+        env:
+          POSTGRES_USER: gitea
+          POSTGRES_PASSWORD: ${{ secrets.DB_PASSWORD }}
+        ports: ["5432:5432"]
+        options: --health-cmd "pg_isready -U gitea" --health-interval=10s --health-timeout 5s --health-retries 5
+`), vars)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]*actions_model.JobService{
+			"test": {
+				{
+					Name:  "postgres",
+					Image: "postgres:16",
+					Ports: []string{"5432:5432"},
+					// the secrets are kept for the runner
+					Env:     map[string]string{"POSTGRES_USER": "gitea", "POSTGRES_PASSWORD": "${{ secrets.DB_PASSWORD }}"},
+					Options: `--health-cmd "pg_isready -U gitea" --health-interval=10s --health-timeout 5s --health-retries 5`,
+					Health:  &actions_model.ServiceHealth{Cmd: "pg_isready -U gitea", Interval: 10 * time.Second, Timeout: 5 * time.Second, Retries: 5},
+				},
+				{
+					Name:    "redis",
+					Image:   "redis:7",
+					Ports:   []string{"6379"},
+					Options: `--health-cmd 'redis-cli ping' --health-interval 5s --health-retries 10`,
+					Health:  &actions_model.ServiceHealth{Cmd: "redis-cli ping", Interval: 5 * time.Second, Retries: 10},
+				},
+			},
+		}, services)
+	})
+
+	t.Run("health overrides options", func(t *testing.T) {
+		services, err := ParseJobServices([]byte(`
+jobs:
+  test:
+    services:
+      db:
+        image: mysql:8
+        options: --tmpfs /var/lib/mysql --health-cmd "mysqladmin ping" --health-retries 3
+        health:
+          cmd: mysqladmin ping -h 127.0.0.1
+          start-period: 30s
+`), vars)
+		require.NoError(t, err)
+		require.Len(t, services["test"], 1)
+		db := services["test"][0]
+		assert.Equal(t, &actions_model.ServiceHealth{Cmd: "mysqladmin ping -h 127.0.0.1", StartPeriod: 30 * time.Second}, db.Health)
+		assert.Equal(t, `--tmpfs /var/lib/mysql --health-cmd "mysqladmin ping" --health-retries 3 --health-cmd 'mysqladmin ping -h 127.0.0.1' --health-start-period 30s`, db.Options)
+	})
+
+	t.Run("expression", func(t *testing.T) {
+		services, err := ParseJobServices([]byte(`
+jobs:
+  test:
+    services: ${{ fromJSON(vars.SERVICES) }}
+`), vars)
+		require.NoError(t, err)
+		assert.Empty(t, services)
+	})
+
+	for name, content := range map[string]string{
+		"no image": `
+jobs:
+  test:
+    services:
+      redis:
+        ports: [6379]
+`,
+		"invalid health interval": `
+jobs:
+  test:
+    services:
+      redis:
+        image: redis:7
+        options: --health-cmd "redis-cli ping" --health-interval soon
+`,
+		"health without cmd": `
+jobs:
+  test:
+    services:
+      redis:
+        image: redis:7
+        health:
+          interval: 5s
+`,
+		"unbalanced quotes": `
+jobs:
+  test:
+    services:
+      redis:
+        image: redis:7
+        options: --health-cmd "redis-cli ping
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseJobServices([]byte(content), vars)
+			assert.Error(t, err)
+		})
+	}
+}
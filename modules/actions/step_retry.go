@@ -0,0 +1,68 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseStepRetries parses the `retry` of steps in the workflow content, it's a Gitea extension like:
+//
+//	steps:
+//	  - run: make test
+//	    retry:
+//	      count: 3
+//	      backoff: 10s
+//
+// It returns the retry configurations keyed by job id and the index of step, the steps without `retry` are not included.
+// The count must be positive and no more than maxCount.
+func ParseStepRetries(content []byte, maxCount int) (map[string]map[int64]*actions_model.StepRetry, error) {
+	var workflow struct {
+		Jobs map[string]struct {
+			Steps []struct {
+				Retry *struct {
+					Count   int    `yaml:"count"`
+					Backoff string `yaml:"backoff"`
+				} `yaml:"retry"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]map[int64]*actions_model.StepRetry)
+	for id, job := range workflow.Jobs {
+		for i, step := range job.Steps {
+			if step.Retry == nil {
+				continue
+			}
+			if step.Retry.Count <= 0 || step.Retry.Count > maxCount {
+				return nil, fmt.Errorf("the retry count %d of step %d of job %q should be between 1 and %d", step.Retry.Count, i, id, maxCount)
+			}
+			var backoff time.Duration
+			if step.Retry.Backoff != "" {
+				var err error
+				if backoff, err = time.ParseDuration(step.Retry.Backoff); err != nil {
+					return nil, fmt.Errorf("invalid retry backoff of step %d of job %q: %w", i, id, err)
+				} else if backoff < 0 {
+					return nil, fmt.Errorf("the retry backoff of step %d of job %q should not be negative", i, id)
+				}
+			}
+			if ret[id] == nil {
+				ret[id] = make(map[int64]*actions_model.StepRetry)
+			}
+			ret[id][int64(i)] = &actions_model.StepRetry{
+				Count:   step.Retry.Count,
+				Backoff: backoff,
+			}
+		}
+	}
+	return ret, nil
+}
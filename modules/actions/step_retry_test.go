@@ -0,0 +1,104 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStepRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]map[int64]*actions_model.StepRetry
+		wantErr bool
+	}{
+		{
+			name: "no retry",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`,
+			want: map[string]map[int64]*actions_model.StepRetry{},
+		},
+		{
+			name: "retry with backoff",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: make test
+        retry:
+          count: 3
+          backoff: 10s
+`,
+			want: map[string]map[int64]*actions_model.StepRetry{
+				"test": {1: {Count: 3, Backoff: 10 * time.Second}},
+			},
+		},
+		{
+			name: "retry without backoff",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        retry:
+          count: 1
+`,
+			want: map[string]map[int64]*actions_model.StepRetry{
+				"test": {0: {Count: 1}},
+			},
+		},
+		{
+			name: "count exceeds the cap",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        retry:
+          count: 6
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid backoff",
+			content: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        retry:
+          count: 2
+          backoff: soon
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStepRetries([]byte(tt.content), 5)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/workflowpattern"
+)
+
+// describeTriggerMatch returns how the trigger event matched the triggered event, it should be called after detectMatched returns true.
+// The activity types are named like the `types` filters, and the matched branch, tag and path are reported only if the filters are used.
+func describeTriggerMatch(gitRepo *git.Repository, commit *git.Commit, triggedEvent webhook_module.HookEventType, payload api.Payloader, evt *jobparser.Event) *actions_model.RunTriggerMatch {
+	match := &actions_model.RunTriggerMatch{Event: evt.Name}
+	acts := evt.Acts()
+
+	switch p := payload.(type) {
+	case *api.PushPayload:
+		refName := git.RefName(p.Ref)
+		if hasAnyFilter(acts, "branches", "branches-ignore", "tags", "tags-ignore") {
+			match.Ref = refName.ShortName()
+		}
+		if hasAnyFilter(acts, "paths", "paths-ignore") {
+			if files, ok := pushChangedFiles(commit, p.Before); ok {
+				match.Path = matchedPath(acts, files)
+			}
+		}
+	case *api.PullRequestPayload:
+		match.ActivityType = pullRequestActivityType(triggedEvent, p.Action)
+		if hasAnyFilter(acts, "branches", "branches-ignore") {
+			match.Ref = git.RefName(p.PullRequest.Base.Ref).ShortName()
+		}
+		if hasAnyFilter(acts, "paths", "paths-ignore") {
+			headCommit := commit
+			if evt.Name == GithubEventPullRequestTarget {
+				var err error
+				if headCommit, err = gitRepo.GetCommit(p.PullRequest.Head.Sha); err != nil {
+					log.Error("GetCommit [ref: %s]: %v", p.PullRequest.Head.Sha, err)
+					break
+				}
+			}
+			if files, err := pullRequestChangedFiles(gitRepo, headCommit, p.PullRequest.Base.Ref); err != nil {
+				log.Error("pullRequestChangedFiles [commit_sha1: %s]: %v", headCommit.ID.String(), err)
+			} else {
+				match.Path = matchedPath(acts, files)
+			}
+		}
+	case *api.ReleasePayload:
+		match.ActivityType = string(p.Action)
+		if p.Action == api.HookReleaseUpdated {
+			match.ActivityType = "edited"
+		}
+	case *api.IssuePayload:
+		match.ActivityType = issuesActivityType(p.Action)
+	case *api.IssueCommentPayload:
+		match.ActivityType = string(p.Action)
+	case *api.RepositoryDispatchPayload:
+		// the custom event type of the dispatch, which is matched by the `types` filters
+		match.ActivityType = p.Action
+	case *api.WorkflowRunPayload:
+		match.ActivityType = string(p.Action)
+	}
+	return match
+}
+
+// pullRequestActivityType returns the activity type of the pull request event like it's matched by the `types` filters
+func pullRequestActivityType(triggedEvent webhook_module.HookEventType, action api.HookIssueAction) string {
+	switch triggedEvent {
+	case webhook_module.HookEventPullRequestReviewApproved, webhook_module.HookEventPullRequestReviewRejected:
+		return "submitted"
+	case webhook_module.HookEventPullRequestReviewComment:
+		return "created"
+	}
+	switch action {
+	case api.HookIssueSynchronized:
+		return "synchronize"
+	case api.HookIssueLabelUpdated:
+		return "labeled"
+	case api.HookIssueLabelCleared:
+		return "unlabeled"
+	}
+	return string(action)
+}
+
+// issuesActivityType returns the activity type of the issues event like it's matched by the `types` filters
+func issuesActivityType(action api.HookIssueAction) string {
+	switch action {
+	case api.HookIssueLabelUpdated:
+		return "labeled"
+	case api.HookIssueLabelCleared:
+		return "unlabeled"
+	}
+	return string(action)
+}
+
+func hasAnyFilter(acts map[string][]string, filters ...string) bool {
+	for _, filter := range filters {
+		if _, ok := acts[filter]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchedPath returns the first changed file which is matched by the `paths` filters and not ignored by the `paths-ignore` filters
+func matchedPath(acts map[string][]string, files []string) string {
+	var includes, ignores []*workflowpattern.WorkflowPattern
+	var err error
+	if vals, ok := acts["paths"]; ok {
+		if includes, err = workflowpattern.CompilePatterns(vals...); err != nil {
+			return ""
+		}
+	}
+	if vals, ok := acts["paths-ignore"]; ok {
+		if ignores, err = workflowpattern.CompilePatterns(vals...); err != nil {
+			return ""
+		}
+	}
+	for _, file := range files {
+		if includes != nil && workflowpattern.Skip(includes, []string{file}, &workflowpattern.EmptyTraceWriter{}) {
+			continue
+		}
+		if ignores != nil && workflowpattern.Filter(ignores, []string{file}, &workflowpattern.EmptyTraceWriter{}) {
+			continue
+		}
+		return file
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeTriggerMatch(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		triggedEvent webhook_module.HookEventType
+		payload      api.Payloader
+		yamlOn       string
+		expected     *actions_model.RunTriggerMatch
+	}{
+		{
+			desc:         "push without filters",
+			triggedEvent: webhook_module.HookEventPush,
+			payload:      &api.PushPayload{Ref: "refs/heads/main"},
+			yamlOn:       "on: push",
+			expected:     &actions_model.RunTriggerMatch{Event: "push"},
+		},
+		{
+			desc:         "push with tags filter",
+			triggedEvent: webhook_module.HookEventPush,
+			payload:      &api.PushPayload{Ref: "refs/tags/v1.0.0"},
+			yamlOn:       "on:\n  push:\n    tags: ['v*']",
+			expected:     &actions_model.RunTriggerMatch{Event: "push", Ref: "v1.0.0"},
+		},
+		{
+			desc:         "pull_request synchronized",
+			triggedEvent: webhook_module.HookEventPullRequestSync,
+			payload: &api.PullRequestPayload{
+				Action:      api.HookIssueSynchronized,
+				PullRequest: &api.PullRequest{Base: &api.PRBranchInfo{Ref: "main"}},
+			},
+			yamlOn:   "on:\n  pull_request:\n    types: [synchronize]\n    branches: [main]",
+			expected: &actions_model.RunTriggerMatch{Event: "pull_request", ActivityType: "synchronize", Ref: "main"},
+		},
+		{
+			desc:         "release updated",
+			triggedEvent: webhook_module.HookEventRelease,
+			payload:      &api.ReleasePayload{Action: api.HookReleaseUpdated},
+			yamlOn:       "on: release",
+			expected:     &actions_model.RunTriggerMatch{Event: "release", ActivityType: "edited"},
+		},
+		{
+			desc:         "repository_dispatch",
+			triggedEvent: webhook_module.HookEventRepositoryDispatch,
+			payload:      &api.RepositoryDispatchPayload{Action: "deploy"},
+			yamlOn:       "on:\n  repository_dispatch:\n    types: [deploy]",
+			expected:     &actions_model.RunTriggerMatch{Event: "repository_dispatch", ActivityType: "deploy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			evts, err := GetEventsFromContent([]byte(tc.yamlOn))
+			require.NoError(t, err)
+			require.Len(t, evts, 1)
+			require.True(t, detectMatched(nil, nil, tc.triggedEvent, tc.payload, evts[0]))
+			assert.Equal(t, tc.expected, describeTriggerMatch(nil, nil, tc.triggedEvent, tc.payload, evts[0]))
+		})
+	}
+}
+
+func TestMatchedPath(t *testing.T) {
+	files := []string{"docs/README.md", "src/main.go", "src/main_test.go"}
+	assert.Equal(t, "src/main.go", matchedPath(map[string][]string{"paths": {"src/**"}}, files))
+	assert.Equal(t, "src/main_test.go", matchedPath(map[string][]string{"paths": {"src/**"}, "paths-ignore": {"src/main.go"}}, files))
+	assert.Equal(t, "src/main.go", matchedPath(map[string][]string{"paths-ignore": {"docs/**"}}, files))
+	assert.Empty(t, matchedPath(map[string][]string{"paths": {"web/**"}}, files))
+}
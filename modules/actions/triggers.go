@@ -0,0 +1,134 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// TriggerMap is what can trigger the workflows of a commit, see ReadTriggerMap
+type TriggerMap struct {
+	// Events are the events in `on` of the workflows with the workflows which respond to them, sorted by the events
+	Events []*EventTriggers
+	// Schedules are the cron specs of the schedule events of the workflows
+	Schedules []*ScheduleTrigger
+	// DisabledWorkflows are the workflows excluded from the map since they have been disabled
+	DisabledWorkflows []string
+	// Errors are the problems of the workflows which fail to be parsed, the other workflows are still in the map
+	Errors []*WorkflowError
+}
+
+// EventTriggers are the workflows which respond to an event
+type EventTriggers struct {
+	Event string
+	// Supported is false if Gitea never triggers workflows by the event, or the event has been disabled by the administrator
+	Supported bool
+	Workflows []*EventWorkflow
+}
+
+// EventWorkflow is a workflow which responds to an event, with the activity types and the filters of the event
+type EventWorkflow struct {
+	Workflow string
+	Dir      string // the workflow directory of the file, like ".gitea/workflows"
+	Filters  map[string][]string
+}
+
+// ScheduleTrigger is a cron spec of the schedule event of a workflow
+type ScheduleTrigger struct {
+	Workflow string
+	Dir      string
+	Spec     string
+	Next     time.Time // the next time the spec fires after now, zero if the spec is invalid
+}
+
+// WorkflowError is the problem of a workflow which fails to be parsed
+type WorkflowError struct {
+	Workflow string
+	Dir      string
+	Error    string
+}
+
+// ReadTriggerMap reads the workflows of the commit in the dirs like they are detected, and returns what can trigger them.
+// The workflows for which isDisabled returns true are excluded, and the invalid workflows are reported in Errors rather than ignored.
+// The next times of the schedules are delayed by the jitter of their workflows, see actions_model.ScheduleJitter.
+func ReadTriggerMap(commit *git.Commit, dirs []string, isDisabled func(workflow string) bool, jitter func(workflow string) time.Duration, now time.Time) (*TriggerMap, error) {
+	entries, err := ListWorkflows(commit, dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &TriggerMap{}
+	events := map[string]*EventTriggers{}
+	for _, entry := range entries {
+		if isDisabled(entry.Name()) {
+			ret.DisabledWorkflows = append(ret.DisabledWorkflows, entry.Name())
+			continue
+		}
+		content, err := GetContentFromEntry(entry.TreeEntry)
+		if err != nil {
+			return nil, err
+		}
+		if isActionMetadata(content) {
+			continue
+		}
+		addError := func(format string, args ...any) {
+			ret.Errors = append(ret.Errors, &WorkflowError{Workflow: entry.Name(), Dir: entry.Dir, Error: fmt.Sprintf(format, args...)})
+		}
+
+		workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+		if err != nil {
+			addError("invalid workflow: %v", err)
+			continue
+		}
+		evts, err := GetEventsFromContent(content)
+		if err != nil {
+			addError("invalid `on`: %v", err)
+			continue
+		}
+		for _, evt := range evts {
+			if evt.IsSchedule() {
+				// the schedules are created from the specs of the workflow, see handleSchedules
+				for _, spec := range workflow.OnSchedule() {
+					schedule := &ScheduleTrigger{Workflow: entry.Name(), Dir: entry.Dir, Spec: spec}
+					if cron, err := actions_model.ParseScheduleSpec(spec); err != nil {
+						addError("invalid cron spec %q of schedule: %v", spec, err)
+					} else {
+						schedule.Next = actions_model.NextScheduleTime(cron, now, jitter(entry.Name()))
+					}
+					ret.Schedules = append(ret.Schedules, schedule)
+				}
+				continue
+			}
+
+			triggers, ok := events[evt.Name]
+			if !ok {
+				triggers = &EventTriggers{
+					Event: evt.Name,
+					Supported: slices.ContainsFunc(hookEvents, func(e webhook_module.HookEventType) bool { return canGithubEventMatch(evt.Name, e) }) &&
+						!slices.Contains(setting.Actions.DisabledEvents, evt.Name),
+				}
+				events[evt.Name] = triggers
+			}
+			triggers.Workflows = append(triggers.Workflows, &EventWorkflow{Workflow: entry.Name(), Dir: entry.Dir, Filters: evt.Acts()})
+		}
+	}
+
+	ret.Events = make([]*EventTriggers, 0, len(events))
+	for _, triggers := range events {
+		ret.Events = append(ret.Events, triggers)
+	}
+	sort.Slice(ret.Events, func(i, j int) bool { return ret.Events[i].Event < ret.Events[j].Event })
+	return ret, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTriggerMap(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.HomePath, t.TempDir())()
+	commit := createWorkflowsCommit(t, map[string]string{
+		".gitea/workflows/build.yml":    "on:\n  push:\n    branches: [main]\n  pull_request:\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n",
+		".gitea/workflows/nightly.yml":  "on:\n  schedule:\n    - cron: '0 1 * * *'\n    - cron: 'invalid'\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n",
+		".gitea/workflows/disabled.yml": testWorkflowContent,
+		".gitea/workflows/broken.yml":   "on: [push\njobs: {}\n",
+		".gitea/workflows/check.yml":    "on: check_run\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n",
+	})
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	jitter := func(string) time.Duration { return 90 * time.Second }
+	triggerMap, err := ReadTriggerMap(commit, nil, func(workflow string) bool { return workflow == "disabled.yml" }, jitter, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"disabled.yml"}, triggerMap.DisabledWorkflows)
+
+	events := map[string]*EventTriggers{}
+	for _, evt := range triggerMap.Events {
+		events[evt.Event] = evt
+	}
+	if assert.Contains(t, events, "push") {
+		assert.True(t, events["push"].Supported)
+		require.Len(t, events["push"].Workflows, 1)
+		assert.Equal(t, "build.yml", events["push"].Workflows[0].Workflow)
+		assert.Equal(t, ".gitea/workflows", events["push"].Workflows[0].Dir)
+		assert.Equal(t, []string{"main"}, events["push"].Workflows[0].Filters["branches"])
+	}
+	assert.Contains(t, events, "pull_request")
+	if assert.Contains(t, events, "check_run") {
+		assert.False(t, events["check_run"].Supported)
+	}
+
+	require.Len(t, triggerMap.Schedules, 2)
+	assert.Equal(t, "0 1 * * *", triggerMap.Schedules[0].Spec)
+	assert.Equal(t, time.Date(2024, 1, 2, 1, 1, 30, 0, time.UTC), triggerMap.Schedules[0].Next.UTC())
+	assert.True(t, triggerMap.Schedules[1].Next.IsZero())
+
+	workflows := make([]string, 0, len(triggerMap.Errors))
+	for _, workflowErr := range triggerMap.Errors {
+		workflows = append(workflows, workflowErr.Workflow)
+	}
+	assert.ElementsMatch(t, []string{"broken.yml", "nightly.yml"}, workflows)
+}
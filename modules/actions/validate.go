@@ -0,0 +1,258 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/setting"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+// WorkflowDiagnostics is the result of validating a workflow without running it
+type WorkflowDiagnostics struct {
+	// Errors are the problems which make the workflow fail to be parsed or run
+	Errors []string
+	// Warnings are the problems which don't block the workflow, but it may not work as expected
+	Warnings []string
+	// Triggers are the events in `on` which the workflow will be detected for
+	Triggers []*WorkflowTrigger
+	// Jobs are the jobs which will be created for a run, every leg of a matrix job is a job
+	Jobs []*WorkflowJob
+}
+
+// WorkflowTrigger is an event in `on` of a workflow
+type WorkflowTrigger struct {
+	Event     string
+	Acts      map[string][]string
+	Schedules []string // the cron specs of the schedule event
+}
+
+// WorkflowJob is a job which will be created for a run of a workflow
+type WorkflowJob struct {
+	ID     string
+	Name   string
+	Needs  []string
+	RunsOn []string
+}
+
+// hookEvents are the events which may trigger workflows
+var hookEvents = []webhook_module.HookEventType{
+	webhook_module.HookEventCreate,
+	webhook_module.HookEventDelete,
+	webhook_module.HookEventFork,
+	webhook_module.HookEventPush,
+	webhook_module.HookEventIssues,
+	webhook_module.HookEventIssueAssign,
+	webhook_module.HookEventIssueLabel,
+	webhook_module.HookEventIssueMilestone,
+	webhook_module.HookEventIssueComment,
+	webhook_module.HookEventPullRequest,
+	webhook_module.HookEventPullRequestAssign,
+	webhook_module.HookEventPullRequestLabel,
+	webhook_module.HookEventPullRequestMilestone,
+	webhook_module.HookEventPullRequestComment,
+	webhook_module.HookEventPullRequestReviewApproved,
+	webhook_module.HookEventPullRequestReviewRejected,
+	webhook_module.HookEventPullRequestReviewComment,
+	webhook_module.HookEventPullRequestSync,
+	webhook_module.HookEventPullRequestReviewRequest,
+	webhook_module.HookEventWiki,
+	webhook_module.HookEventRepository,
+	webhook_module.HookEventRelease,
+	webhook_module.HookEventPackage,
+	webhook_module.HookEventSchedule,
+	webhook_module.HookEventMergeGroup,
+	webhook_module.HookEventWorkflowRun,
+	webhook_module.HookEventWatch,
+	webhook_module.HookEventPageBuild,
+	webhook_module.HookEventLabel,
+	webhook_module.HookEventMilestone,
+	webhook_module.HookEventRepositoryDispatch,
+	webhook_module.HookEventOrganization,
+	webhook_module.HookEventMembership,
+}
+
+var (
+	expressionPattern = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	needsRefPattern   = regexp.MustCompile(`\bneeds\.([A-Za-z_][A-Za-z0-9_-]*)(?:\.outputs\.([A-Za-z_][A-Za-z0-9_-]*))?`)
+	stepsRefPattern   = regexp.MustCompile(`\bsteps\.([A-Za-z_][A-Za-z0-9_-]*)`)
+)
+
+// ValidateWorkflow validates the workflow content like it's going to be detected and run,
+// and checks the references in `needs`, `if` and `outputs` which can be checked statically.
+// It doesn't access the database or the git repositories, so it's safe to validate any content.
+func ValidateWorkflow(content []byte) *WorkflowDiagnostics {
+	ret := &WorkflowDiagnostics{}
+
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("invalid workflow: %v", err))
+		return ret
+	}
+
+	events, err := GetEventsFromContent(content)
+	if err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("invalid `on`: %v", err))
+	}
+	for _, evt := range events {
+		trigger := &WorkflowTrigger{Event: evt.Name, Acts: evt.Acts()}
+		if evt.IsSchedule() {
+			// the schedules are created from the specs of the workflow, see handleSchedules
+			trigger.Schedules = workflow.OnSchedule()
+			for _, spec := range trigger.Schedules {
+				if _, err := actions_model.ParseScheduleSpec(spec); err != nil {
+					ret.Errors = append(ret.Errors, fmt.Sprintf("invalid cron spec %q of schedule: %v", spec, err))
+				}
+			}
+			if _, err := ParseScheduleConditions(content); err != nil {
+				ret.Errors = append(ret.Errors, err.Error())
+			}
+		}
+		ret.Triggers = append(ret.Triggers, trigger)
+
+		if !slices.ContainsFunc(hookEvents, func(e webhook_module.HookEventType) bool { return canGithubEventMatch(evt.Name, e) }) {
+			ret.Warnings = append(ret.Warnings, fmt.Sprintf("event %q is not supported, the workflow won't be triggered by it", evt.Name))
+		} else if slices.Contains(setting.Actions.DisabledEvents, evt.Name) {
+			ret.Warnings = append(ret.Warnings, fmt.Sprintf("event %q has been disabled by the administrator", evt.Name))
+		}
+	}
+	if len(events) == 0 && err == nil {
+		ret.Warnings = append(ret.Warnings, "the workflow has no events in `on`, it will never be triggered")
+	}
+
+	ret.Errors = append(ret.Errors, checkWorkflowRefs(workflow)...)
+
+	singleWorkflows, err := jobparser.Parse(content)
+	if err != nil {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("invalid jobs: %v", err))
+	}
+	for _, v := range singleWorkflows {
+		id, job := v.Job()
+		ret.Jobs = append(ret.Jobs, &WorkflowJob{
+			ID:     id,
+			Name:   job.Name,
+			Needs:  job.Needs(),
+			RunsOn: job.RunsOn(),
+		})
+	}
+
+	ret.Warnings = append(ret.Warnings, LintWorkflow(content)...)
+	return ret
+}
+
+// checkWorkflowRefs checks that the jobs in `needs` exist without cycles,
+// and that the jobs and the steps referenced by `if` and `outputs` are available to the jobs.
+func checkWorkflowRefs(workflow *model.Workflow) []string {
+	ids := make([]string, 0, len(workflow.Jobs))
+	for id := range workflow.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []string
+	for _, id := range ids {
+		job := workflow.Jobs[id]
+		if job == nil {
+			continue
+		}
+		needs := job.Needs()
+		for _, need := range needs {
+			if _, ok := workflow.Jobs[need]; !ok {
+				errs = append(errs, fmt.Sprintf("jobs.%s.needs: job %q does not exist", id, need))
+			}
+		}
+
+		checkNeedsRefs := func(field, expr string) {
+			for _, m := range needsRefPattern.FindAllStringSubmatch(expr, -1) {
+				if !slices.Contains(needs, m[1]) {
+					errs = append(errs, fmt.Sprintf("%s: job %q is not in `needs` of job %q", field, m[1], id))
+					continue
+				}
+				if needed := workflow.Jobs[m[1]]; m[2] != "" && needed != nil {
+					if _, ok := needed.Outputs[m[2]]; !ok {
+						errs = append(errs, fmt.Sprintf("%s: output %q is not declared by job %q", field, m[2], m[1]))
+					}
+				}
+			}
+		}
+
+		checkNeedsRefs(fmt.Sprintf("jobs.%s.if", id), job.If.Value)
+
+		names := make([]string, 0, len(job.Outputs))
+		for name := range job.Outputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			field := fmt.Sprintf("jobs.%s.outputs.%s", id, name)
+			for _, m := range expressionPattern.FindAllStringSubmatch(job.Outputs[name], -1) {
+				checkNeedsRefs(field, m[1])
+				for _, ref := range stepsRefPattern.FindAllStringSubmatch(m[1], -1) {
+					if !slices.ContainsFunc(job.Steps, func(step *model.Step) bool { return step != nil && step.ID == ref[1] }) {
+						errs = append(errs, fmt.Sprintf("%s: step %q does not exist in job %q", field, ref[1], id))
+					}
+				}
+			}
+		}
+
+		for i, step := range job.Steps {
+			if step != nil {
+				checkNeedsRefs(fmt.Sprintf("jobs.%s.steps[%d].if", id, i), step.If.Value)
+			}
+		}
+	}
+
+	if cycle := findNeedsCycle(workflow, ids); len(cycle) > 0 {
+		errs = append(errs, fmt.Sprintf("jobs: the jobs need each other in a cycle: %v", cycle))
+	}
+	return errs
+}
+
+// findNeedsCycle returns the ids of the jobs in a cycle of `needs`, or nil if there is no cycle
+func findNeedsCycle(workflow *model.Workflow, ids []string) []string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	states := make(map[string]int, len(ids))
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch states[id] {
+		case visiting:
+			return append(slices.Clone(path[slices.Index(path, id):]), id)
+		case visited:
+			return nil
+		}
+		job := workflow.Jobs[id]
+		if job == nil {
+			return nil
+		}
+		states[id] = visiting
+		path = append(path, id)
+		for _, need := range job.Needs() {
+			if cycle := visit(need); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		states[id] = visited
+		return nil
+	}
+	for _, id := range ids {
+		if cycle := visit(id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
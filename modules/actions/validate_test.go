@@ -0,0 +1,117 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWorkflow(t *testing.T) {
+	t.Run("valid workflow", func(t *testing.T) {
+		ret := ValidateWorkflow([]byte(`
+on:
+  push:
+    branches: [main]
+  schedule:
+    - cron: "0 0 * * *"
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        go: [1.21, 1.22]
+    outputs:
+      version: ${{ steps.version.outputs.value }}
+    steps:
+      - id: version
+        run: echo "value=1" >> $GITHUB_OUTPUT
+  deploy:
+    needs: build
+    if: needs.build.outputs.version != ''
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.build.outputs.version }}
+`))
+		assert.Empty(t, ret.Errors)
+		assert.Empty(t, ret.Warnings)
+		if assert.Len(t, ret.Triggers, 2) {
+			assert.Equal(t, "push", ret.Triggers[0].Event)
+			assert.Equal(t, map[string][]string{"branches": {"main"}}, ret.Triggers[0].Acts)
+			assert.Equal(t, "schedule", ret.Triggers[1].Event)
+			assert.Equal(t, []string{"0 0 * * *"}, ret.Triggers[1].Schedules)
+		}
+		if assert.Len(t, ret.Jobs, 3) {
+			assert.Equal(t, "build", ret.Jobs[0].ID)
+			assert.Equal(t, "build (1.21)", ret.Jobs[0].Name)
+			assert.Equal(t, "build (1.22)", ret.Jobs[1].Name)
+			assert.Equal(t, "deploy", ret.Jobs[2].ID)
+			assert.Equal(t, []string{"build"}, ret.Jobs[2].Needs)
+			assert.Equal(t, []string{"ubuntu-latest"}, ret.Jobs[2].RunsOn)
+		}
+	})
+
+	t.Run("invalid references", func(t *testing.T) {
+		ret := ValidateWorkflow([]byte(`
+on: [push, unknown_event]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.missing.outputs.value }}
+    steps:
+      - uses: actions/checkout@v2
+  test:
+    needs: [build, lint]
+    if: needs.build.outputs.missing == 'x' && needs.deploy.result == 'success'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo test
+  a:
+    needs: b
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo a
+  b:
+    needs: a
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo b
+`))
+		assert.Equal(t, []string{
+			"jobs.build.outputs.version: step \"missing\" does not exist in job \"build\"",
+			"jobs.test.needs: job \"lint\" does not exist",
+			"jobs.test.if: output \"missing\" is not declared by job \"build\"",
+			"jobs.test.if: job \"deploy\" is not in `needs` of job \"test\"",
+			"jobs: the jobs need each other in a cycle: [a b a]",
+		}, ret.Errors)
+		assert.Equal(t, []string{
+			"event \"unknown_event\" is not supported, the workflow won't be triggered by it",
+			"jobs.build.steps[0]: actions/checkout@v1, @v2, @v3 is deprecated, please use a newer version",
+		}, ret.Warnings)
+	})
+
+	t.Run("invalid cron spec", func(t *testing.T) {
+		ret := ValidateWorkflow([]byte(`
+on:
+  schedule:
+    - cron: "every day"
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+`))
+		if assert.Len(t, ret.Errors, 1) {
+			assert.Contains(t, ret.Errors[0], `invalid cron spec "every day" of schedule`)
+		}
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		ret := ValidateWorkflow([]byte("on: ["))
+		assert.Len(t, ret.Errors, 1)
+		assert.Empty(t, ret.Jobs)
+	})
+}
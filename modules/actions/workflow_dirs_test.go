@@ -0,0 +1,215 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWorkflowContent = "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n"
+
+// createWorkflowsCommit commits the files to a new repository, and returns the commit
+func createWorkflowsCommit(tb testing.TB, files map[string]string) *git.Commit {
+	tb.Helper()
+	require.NoError(tb, git.InitSimple(context.Background()))
+
+	repoPath := tb.TempDir()
+	require.NoError(tb, git.InitRepository(context.Background(), repoPath, false, git.Sha1ObjectFormat.Name()))
+	for name, content := range files {
+		require.NoError(tb, os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), os.ModePerm))
+		require.NoError(tb, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644))
+	}
+	require.NoError(tb, git.AddChanges(repoPath, true))
+	signature := &git.Signature{Name: "gitea", Email: "gitea@example.com", When: time.Now()}
+	require.NoError(tb, git.CommitChanges(repoPath, git.CommitChangesOptions{Committer: signature, Message: "add workflows"}))
+
+	repo, err := git.OpenRepository(context.Background(), repoPath)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { repo.Close() })
+	commit, err := repo.GetCommit("HEAD")
+	require.NoError(tb, err)
+	return commit
+}
+
+func listWorkflowPaths(t *testing.T, commit *git.Commit, dirs ...string) []string {
+	entries, err := ListWorkflows(commit, dirs...)
+	require.NoError(t, err)
+	workflows := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		workflows = append(workflows, entry.Dir+"/"+entry.Name())
+	}
+	return workflows
+}
+
+func TestListWorkflowsOfBothDirs(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.HomePath, t.TempDir())()
+	commit := createWorkflowsCommit(t, map[string]string{
+		".gitea/workflows/build.yml":      testWorkflowContent,
+		".gitea/workflows/gitea-only.yml": testWorkflowContent,
+		".github/workflows/build.yml":     testWorkflowContent,
+		".github/workflows/release.yaml":  testWorkflowContent,
+	})
+
+	tests := []struct {
+		precedence string
+		workflows  []string
+	}{
+		{
+			precedence: setting.WorkflowDirsPrecedenceGitea,
+			workflows:  []string{".gitea/workflows/build.yml", ".gitea/workflows/gitea-only.yml", ".github/workflows/release.yaml"},
+		},
+		{
+			precedence: setting.WorkflowDirsPrecedenceGithub,
+			workflows:  []string{".github/workflows/build.yml", ".github/workflows/release.yaml", ".gitea/workflows/gitea-only.yml"},
+		},
+		{
+			precedence: setting.WorkflowDirsPrecedenceBoth,
+			workflows:  []string{".gitea/workflows/build.yml", ".gitea/workflows/gitea-only.yml", ".github/workflows/build.yml", ".github/workflows/release.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.precedence, func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Actions.WorkflowDirsPrecedence, tt.precedence)()
+
+			assert.Equal(t, tt.workflows, listWorkflowPaths(t, commit))
+
+			parsed, err := ReadWorkflows(commit)
+			require.NoError(t, err)
+			require.Len(t, parsed, len(tt.workflows))
+			for i, pwf := range parsed {
+				assert.Equal(t, tt.workflows[i], pwf.Dir+"/"+pwf.EntryName)
+			}
+		})
+	}
+}
+
+func TestListWorkflowsOfConfiguredDirs(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.HomePath, t.TempDir())()
+	commit := createWorkflowsCommit(t, map[string]string{
+		".gitea/workflows/build.yml":          testWorkflowContent,
+		".gitea/workflows/ci/test.yml":        testWorkflowContent,
+		".gitea/workflows/ci/nested/lint.yml": testWorkflowContent,
+		".gitea/workflows/ci/action.yml":      "runs:\n  using: composite\n",
+		".github/workflows/build.yml":         testWorkflowContent,
+		".github/workflows/release.yaml":      testWorkflowContent,
+	})
+	all := listWorkflowPaths(t, commit)
+	assert.Equal(t, []string{
+		".gitea/workflows/build.yml",
+		".gitea/workflows/ci/nested/lint.yml",
+		".gitea/workflows/ci/test.yml",
+		".github/workflows/release.yaml",
+	}, all)
+
+	tests := []struct {
+		name      string
+		dirs      []string
+		workflows []string
+	}{
+		{
+			name:      "root only",
+			dirs:      []string{".gitea/workflows"},
+			workflows: []string{".gitea/workflows/build.yml"},
+		},
+		{
+			name:      "subdirectory",
+			dirs:      []string{".gitea/workflows/ci/"},
+			workflows: []string{".gitea/workflows/ci/test.yml"},
+		},
+		{
+			name:      "the other directory",
+			dirs:      []string{".github/workflows"},
+			workflows: []string{".github/workflows/build.yml", ".github/workflows/release.yaml"},
+		},
+		{
+			name:      "all directories with workflows",
+			dirs:      []string{".gitea/workflows", ".gitea/workflows/ci", ".gitea/workflows/ci/nested", ".github/workflows", ".gitea/workflows"},
+			workflows: all,
+		},
+		{
+			name:      "missing directory",
+			dirs:      []string{".gitea/workflows/missing"},
+			workflows: []string{},
+		},
+		{
+			name:      "invalid directories",
+			dirs:      []string{"workflows", ".gitea/workflows/../../ci"},
+			workflows: all,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.workflows, listWorkflowPaths(t, commit, tt.dirs...))
+		})
+	}
+}
+
+// BenchmarkListWorkflows compares scanning the workflow directories with reading the configured directories only,
+// in a repository whose workflow directories have a lot of other files, like scripts and vendored actions.
+func BenchmarkListWorkflows(b *testing.B) {
+	defer test.MockVariableValue(&setting.Git.HomePath, b.TempDir())()
+	files := map[string]string{}
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf(".gitea/workflows/workflow-%d.yml", i)] = testWorkflowContent
+	}
+	for i := 0; i < 2000; i++ {
+		files[fmt.Sprintf(".gitea/workflows/scripts/%d/script-%d.sh", i%100, i)] = "echo hello\n"
+		files[fmt.Sprintf(".github/workflows/actions/%d/action-%d.js", i%100, i)] = "console.log('hello')\n"
+	}
+	commit := createWorkflowsCommit(b, files)
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			entries, err := ListWorkflows(commit)
+			require.NoError(b, err)
+			require.Len(b, entries, 10)
+		}
+	})
+	b.Run("configured", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			entries, err := ListWorkflows(commit, ".gitea/workflows")
+			require.NoError(b, err)
+			require.Len(b, entries, 10)
+		}
+	})
+}
+
+func TestIsWorkflowDirsChangedInDirs(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.HomePath, t.TempDir())()
+	oldCommit := createWorkflowsCommit(t, map[string]string{
+		".gitea/workflows/ci/build.yml":     testWorkflowContent,
+		".gitea/workflows/ci/old/build.yml": testWorkflowContent,
+		".gitea/workflows/release.yml":      testWorkflowContent,
+	})
+	newCommit := createWorkflowsCommit(t, map[string]string{
+		".gitea/workflows/ci/build.yml":     testWorkflowContent,
+		".gitea/workflows/ci/old/build.yml": testWorkflowContent + "# changed\n",
+		".gitea/workflows/release.yml":      testWorkflowContent + "# changed\n",
+	})
+
+	changed, err := IsWorkflowDirsChanged(oldCommit, newCommit)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// the changes out of the dirs and in their subdirectories are ignored
+	changed, err = IsWorkflowDirsChanged(oldCommit, newCommit, ".gitea/workflows/ci")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = IsWorkflowDirsChanged(nil, newCommit, ".gitea/workflows/ci")
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
@@ -5,11 +5,18 @@ package actions
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"maps"
+	"path"
+	"sort"
 	"strings"
 
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 
@@ -22,8 +29,12 @@ import (
 
 type DetectedWorkflow struct {
 	EntryName    string
+	Dir          string // the workflow directory of the file, like ".gitea/workflows"
+	BlobSHA      string // the git blob SHA of the workflow file
 	TriggerEvent *jobparser.Event
+	TriggerMatch *actions_model.RunTriggerMatch // how the trigger event matched, nil for the schedules
 	Content      []byte
+	SameNamed    []string // the paths of the other workflows with the same `name`, see FindSameNamedWorkflows
 }
 
 func init() {
@@ -36,18 +47,105 @@ func init() {
 }
 
 func IsWorkflow(path string) bool {
-	if (!strings.HasSuffix(path, ".yaml")) && (!strings.HasSuffix(path, ".yml")) {
+	if !isWorkflowFile(path) {
 		return false
 	}
 
 	return strings.HasPrefix(path, ".gitea/workflows") || strings.HasPrefix(path, ".github/workflows")
 }
 
-func ListWorkflows(commit *git.Commit) (git.Entries, error) {
-	tree, err := commit.SubTree(".gitea/workflows")
-	if _, ok := err.(git.ErrNotExist); ok {
-		tree, err = commit.SubTree(".github/workflows")
+// WorkflowEntry is a workflow file in one of the workflow directories
+type WorkflowEntry struct {
+	*git.TreeEntry
+	Dir  string // the workflow directory of the file, like ".gitea/workflows"
+	name string
+}
+
+// Name returns the path of the file relative to Dir, it's the name of the workflow
+func (e *WorkflowEntry) Name() string {
+	return e.name
+}
+
+// ListWorkflows lists the workflow files in the workflow directories of the commit.
+// If both directories have a workflow with the same name, which is the path relative to the directory,
+// only the one in the directory preferred by setting.Actions.WorkflowDirsPrecedence is listed, unless it's "both".
+// If dirs are given, only the files directly in them are listed by looking them up in the tree,
+// so the other directories and the subdirectories are never read, see repo_model.ActionsConfig.WorkflowDirs.
+func ListWorkflows(commit *git.Commit, dirs ...string) ([]*WorkflowEntry, error) {
+	roots := workflowDirs
+	if setting.Actions.WorkflowDirsPrecedence == setting.WorkflowDirsPrecedenceGithub {
+		roots = []string{workflowDirs[1], workflowDirs[0]}
+	}
+	subDirs := groupWorkflowSubDirs(dirs)
+
+	var ret []*WorkflowEntry
+	names := make(container.Set[string])
+	for _, root := range roots {
+		var entries []*WorkflowEntry
+		if subDirs == nil {
+			var err error
+			if entries, err = listWorkflowsInDir(commit, root); err != nil {
+				return nil, err
+			}
+		}
+		for _, subDir := range subDirs[root] {
+			subEntries, err := listWorkflowsInSubDir(commit, root, subDir)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, subEntries...)
+		}
+		for _, entry := range entries {
+			if !names.Add(entry.Name()) && setting.Actions.WorkflowDirsPrecedence != setting.WorkflowDirsPrecedenceBoth {
+				log.Trace("ignore workflow %q in %s of commit %s since it's overridden by the same-named one in the other directory", entry.Name(), root, commit.ID)
+				continue
+			}
+			ret = append(ret, entry)
+		}
+	}
+	return ret, nil
+}
+
+// groupWorkflowSubDirs groups the dirs by the workflow directories which they are in, the values are the paths relative to them.
+// The dirs out of the workflow directories are ignored, and it returns nil if there are no valid dirs, then all workflows are listed,
+// so a misconfiguration never makes the workflows missed silently.
+func groupWorkflowSubDirs(dirs []string) map[string][]string {
+	var ret map[string][]string
+	added := make(container.Set[string])
+	for _, dir := range dirs {
+		root, subDir, ok := splitWorkflowDir(dir)
+		if !ok {
+			log.Warn("ignore workflow dir %q since it's not in %v", dir, workflowDirs)
+			continue
+		}
+		if !added.Add(path.Join(root, subDir)) {
+			continue
+		}
+		if ret == nil {
+			ret = make(map[string][]string, len(workflowDirs))
+		}
+		ret[root] = append(ret[root], subDir)
+	}
+	return ret
+}
+
+// splitWorkflowDir returns the workflow directory which dir is in, and the path of dir relative to it,
+// like ".gitea/workflows" and "ci" for ".gitea/workflows/ci"
+func splitWorkflowDir(dir string) (root, subDir string, ok bool) {
+	dir = path.Clean(strings.Trim(dir, "/"))
+	for _, root := range workflowDirs {
+		if dir == root {
+			return root, "", true
+		}
+		if subDir, ok := strings.CutPrefix(dir, root+"/"); ok {
+			return root, subDir, true
+		}
 	}
+	return "", "", false
+}
+
+func listWorkflowsInDir(commit *git.Commit, dir string) ([]*WorkflowEntry, error) {
+	tree, err := commit.SubTree(dir)
 	if _, ok := err.(git.ErrNotExist); ok {
 		return nil, nil
 	}
@@ -60,15 +158,135 @@ func ListWorkflows(commit *git.Commit) (git.Entries, error) {
 		return nil, err
 	}
 
-	ret := make(git.Entries, 0, len(entries))
+	ret := make([]*WorkflowEntry, 0, len(entries))
 	for _, entry := range entries {
-		if strings.HasSuffix(entry.Name(), ".yml") || strings.HasSuffix(entry.Name(), ".yaml") {
-			ret = append(ret, entry)
+		if isWorkflowFile(entry.Name()) {
+			ret = append(ret, &WorkflowEntry{TreeEntry: entry, Dir: dir, name: entry.Name()})
+		}
+	}
+	return ret, nil
+}
+
+// listWorkflowsInSubDir lists the workflow files directly in subDir of the workflow directory root, the subdirectories of it are not read
+func listWorkflowsInSubDir(commit *git.Commit, root, subDir string) ([]*WorkflowEntry, error) {
+	tree, err := commit.SubTree(path.Join(root, subDir))
+	if _, ok := err.(git.ErrNotExist); ok {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*WorkflowEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && isWorkflowFile(entry.Name()) {
+			ret = append(ret, &WorkflowEntry{TreeEntry: entry, Dir: root, name: path.Join(subDir, entry.Name())})
 		}
 	}
 	return ret, nil
 }
 
+// isWorkflowFile reports whether the file in the workflow directories is a workflow,
+// the metadata files of composite actions, like "action.yml", are not workflows.
+func isWorkflowFile(name string) bool {
+	if !strings.HasSuffix(name, ".yml") && !strings.HasSuffix(name, ".yaml") {
+		return false
+	}
+	base := path.Base(name)
+	return base != "action.yml" && base != "action.yaml"
+}
+
+// isActionMetadata reports whether the content is the metadata of an action rather than a workflow,
+// which has `runs` but no `jobs`.
+func isActionMetadata(content []byte) bool {
+	var v struct {
+		Runs yaml.Node `yaml:"runs"`
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &v); err != nil {
+		return false
+	}
+	return !v.Runs.IsZero() && v.Jobs.IsZero()
+}
+
+// workflowDirs are the directories which may contain workflows, see ListWorkflows
+var workflowDirs = []string{".gitea/workflows", ".github/workflows"}
+
+// IsWorkflowDirsChanged reports whether the workflow directories differ between the two commits.
+// If dirs are given, only the workflow files directly in them are compared like ListWorkflows lists them,
+// see repo_model.ActionsConfig.WorkflowDirs. A nil commit is treated as a commit without any workflows.
+func IsWorkflowDirsChanged(oldCommit, newCommit *git.Commit, dirs ...string) (bool, error) {
+	subDirs := groupWorkflowSubDirs(dirs)
+	if subDirs == nil {
+		for _, dir := range workflowDirs {
+			oldID, err := getSubTreeID(oldCommit, dir)
+			if err != nil {
+				return false, err
+			}
+			newID, err := getSubTreeID(newCommit, dir)
+			if err != nil {
+				return false, err
+			}
+			if oldID != newID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	for root, subDirs := range subDirs {
+		for _, subDir := range subDirs {
+			oldIDs, err := getSubDirWorkflowIDs(oldCommit, root, subDir)
+			if err != nil {
+				return false, err
+			}
+			newIDs, err := getSubDirWorkflowIDs(newCommit, root, subDir)
+			if err != nil {
+				return false, err
+			}
+			if !maps.Equal(oldIDs, newIDs) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// getSubDirWorkflowIDs returns the blob IDs of the workflow files directly in subDir of the workflow directory root, keyed by their names
+func getSubDirWorkflowIDs(commit *git.Commit, root, subDir string) (map[string]string, error) {
+	if commit == nil {
+		return nil, nil
+	}
+	entries, err := listWorkflowsInSubDir(commit, root, subDir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		ids[entry.Name()] = entry.ID.String()
+	}
+	return ids, nil
+}
+
+func getSubTreeID(commit *git.Commit, dir string) (string, error) {
+	if commit == nil {
+		return "", nil
+	}
+	tree, err := commit.SubTree(dir)
+	if _, ok := err.(git.ErrNotExist); ok {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return tree.ID.String(), nil
+}
+
 func GetContentFromEntry(entry *git.TreeEntry) ([]byte, error) {
 	f, err := entry.Blob().DataAsync()
 	if err != nil {
@@ -95,6 +313,100 @@ func GetEventsFromContent(content []byte) ([]*jobparser.Event, error) {
 	return events, nil
 }
 
+// ParsedWorkflow represents a workflow file whose trigger events have been parsed
+type ParsedWorkflow struct {
+	EntryName string
+	Dir       string // the workflow directory of the file, like ".gitea/workflows"
+	BlobSHA   string // the git blob SHA of the workflow file
+	Name      string // the `name` of the workflow, empty if it has no name
+	Content   []byte
+	Events    []*jobparser.Event
+}
+
+// Path returns the path of the workflow file, like ".gitea/workflows/ci.yml"
+func (pwf *ParsedWorkflow) Path() string {
+	return path.Join(pwf.Dir, pwf.EntryName)
+}
+
+// FindSameNamedWorkflows returns the paths of the other workflows with the same `name` keyed by the paths of the workflows,
+// the workflows with unique names are not included. The names are compared exactly like the contexts of the commit statuses,
+// and the workflows without names are named by their file names in the contexts, so they are never the same named.
+func FindSameNamedWorkflows(workflows []*ParsedWorkflow) map[string][]string {
+	byName := make(map[string][]string)
+	for _, pwf := range workflows {
+		if pwf.Name != "" {
+			byName[pwf.Name] = append(byName[pwf.Name], pwf.Path())
+		}
+	}
+	ret := make(map[string][]string)
+	for _, paths := range byName {
+		if len(paths) < 2 {
+			continue
+		}
+		for _, p := range paths {
+			others := make([]string, 0, len(paths)-1)
+			for _, other := range paths {
+				if other != p {
+					others = append(others, other)
+				}
+			}
+			sort.Strings(others)
+			ret[p] = others
+		}
+	}
+	return ret
+}
+
+// ReadWorkflows lists and parses the workflows of the commit in the dirs, invalid workflows are ignored, see ListWorkflows
+func ReadWorkflows(commit *git.Commit, dirs ...string) ([]*ParsedWorkflow, error) {
+	entries, err := ListWorkflows(commit, dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make([]*ParsedWorkflow, 0, len(entries))
+	for _, entry := range entries {
+		content, err := GetContentFromEntry(entry.TreeEntry)
+		if err != nil {
+			return nil, err
+		}
+		if pwf := parseWorkflow(entry.Name(), entry.ID.String(), content); pwf != nil {
+			pwf.Dir = entry.Dir
+			workflows = append(workflows, pwf)
+		}
+	}
+	return workflows, nil
+}
+
+// parseWorkflow parses the trigger events of the workflow file, it returns nil if the file is not a valid workflow
+func parseWorkflow(entryName, blobSHA string, content []byte) *ParsedWorkflow {
+	if isActionMetadata(content) {
+		// the composite actions could be kept with the workflows which test them, they are not invalid workflows
+		log.Trace("ignore action metadata %q in the workflow directories", entryName)
+		return nil
+	}
+
+	// one workflow may have multiple events
+	events, err := GetEventsFromContent(content)
+	if err != nil {
+		log.Warn("ignore invalid workflow %q: %v", entryName, err)
+		return nil
+	}
+	var workflow struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		log.Warn("ignore the name of workflow %q: %v", entryName, err)
+	}
+	return &ParsedWorkflow{
+		EntryName: entryName,
+		BlobSHA:   blobSHA,
+		Name:      workflow.Name,
+		Content:   content,
+		Events:    events,
+	}
+}
+
 func DetectWorkflows(
 	gitRepo *git.Repository,
 	commit *git.Commit,
@@ -102,114 +414,167 @@ func DetectWorkflows(
 	payload api.Payloader,
 	detectSchedule bool,
 ) ([]*DetectedWorkflow, []*DetectedWorkflow, error) {
-	entries, err := ListWorkflows(commit)
+	parsed, err := ReadWorkflows(commit)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	workflows := make([]*DetectedWorkflow, 0, len(entries))
-	schedules := make([]*DetectedWorkflow, 0, len(entries))
-	for _, entry := range entries {
-		content, err := GetContentFromEntry(entry)
-		if err != nil {
-			return nil, nil, err
-		}
+	workflows, schedules := MatchWorkflows(gitRepo, commit, parsed, triggedEvent, payload, detectSchedule)
+	return workflows, schedules, nil
+}
 
-		// one workflow may have multiple events
-		events, err := GetEventsFromContent(content)
-		if err != nil {
-			log.Warn("ignore invalid workflow %q: %v", entry.Name(), err)
-			continue
-		}
-		for _, evt := range events {
-			log.Trace("detect workflow %q for event %#v matching %q", entry.Name(), evt, triggedEvent)
+// MatchWorkflows returns the workflows and the schedules matching the triggered event from the parsed workflows
+func MatchWorkflows(
+	gitRepo *git.Repository,
+	commit *git.Commit,
+	parsed []*ParsedWorkflow,
+	triggedEvent webhook_module.HookEventType,
+	payload api.Payloader,
+	detectSchedule bool,
+) ([]*DetectedWorkflow, []*DetectedWorkflow) {
+	workflows := make([]*DetectedWorkflow, 0, len(parsed))
+	schedules := make([]*DetectedWorkflow, 0, len(parsed))
+	sameNamed := FindSameNamedWorkflows(parsed)
+	for _, pwf := range parsed {
+		for _, evt := range pwf.Events {
+			log.Trace("detect workflow %q for event %#v matching %q", pwf.EntryName, evt, triggedEvent)
 			if evt.IsSchedule() {
 				if detectSchedule {
 					dwf := &DetectedWorkflow{
-						EntryName:    entry.Name(),
+						EntryName:    pwf.EntryName,
+						Dir:          pwf.Dir,
+						BlobSHA:      pwf.BlobSHA,
 						TriggerEvent: evt,
-						Content:      content,
+						Content:      pwf.Content,
+						SameNamed:    sameNamed[pwf.Path()],
 					}
 					schedules = append(schedules, dwf)
 				}
 			} else if detectMatched(gitRepo, commit, triggedEvent, payload, evt) {
 				dwf := &DetectedWorkflow{
-					EntryName:    entry.Name(),
+					EntryName:    pwf.EntryName,
+					Dir:          pwf.Dir,
+					BlobSHA:      pwf.BlobSHA,
 					TriggerEvent: evt,
-					Content:      content,
+					TriggerMatch: describeTriggerMatch(gitRepo, commit, triggedEvent, payload, evt),
+					Content:      pwf.Content,
+					SameNamed:    sameNamed[pwf.Path()],
 				}
 				workflows = append(workflows, dwf)
 			}
 		}
 	}
 
-	return workflows, schedules, nil
+	return workflows, schedules
+}
+
+// CheckTriggerEvent checks that the trigger event of the detected workflow is declared in its `on` configuration
+// and is able to match the triggered event. A failure indicates a bug in detecting workflows.
+func CheckTriggerEvent(dwf *DetectedWorkflow, triggedEvent webhook_module.HookEventType) error {
+	if dwf.TriggerEvent == nil {
+		return fmt.Errorf("workflow %q has no trigger event", dwf.EntryName)
+	}
+	if !canGithubEventMatch(dwf.TriggerEvent.Name, triggedEvent) {
+		return fmt.Errorf("trigger event %q of workflow %q can't match event %q", dwf.TriggerEvent.Name, dwf.EntryName, triggedEvent)
+	}
+	events, err := GetEventsFromContent(dwf.Content)
+	if err != nil {
+		return fmt.Errorf("GetEventsFromContent: %w", err)
+	}
+	for _, evt := range events {
+		if evt.Name == dwf.TriggerEvent.Name {
+			return nil
+		}
+	}
+	return fmt.Errorf("trigger event %q is not declared in the `on` configuration of workflow %q", dwf.TriggerEvent.Name, dwf.EntryName)
 }
 
+// noFilterEvents are the events in `on` which have no filters, they match any triggered event which they can match
+var noFilterEvents = container.SetOf(
+	GithubEventCreate,
+	GithubEventDelete,
+	GithubEventFork,
+	GithubEventGollum,
+	GithubEventSchedule,
+	GithubEventPageBuild,
+)
+
+// eventMatcher reports whether the triggered event with the payload satisfies the filters of the event in `on`
+type eventMatcher func(gitRepo *git.Repository, commit *git.Commit, payload api.Payloader, evt *jobparser.Event) bool
+
+// withPayload returns the eventMatcher of the matching function which needs the payload of type T,
+// it never matches a payload of another type, which means the triggered event isn't the one the filters are for.
+func withPayload[T api.Payloader](match func(commit *git.Commit, payload T, evt *jobparser.Event) bool) eventMatcher {
+	return func(_ *git.Repository, commit *git.Commit, payload api.Payloader, evt *jobparser.Event) bool {
+		p, ok := payload.(T)
+		if !ok {
+			log.Warn("the payload %T can't be matched with the filters of event %q", payload, evt.Name)
+			return false
+		}
+		return match(commit, p, evt)
+	}
+}
+
+// eventMatchers are the matchers of the events in `on` with filters, keyed by the names of the events.
+// The filters are dispatched by the event in `on` rather than the triggered event, so the filters of an event never apply to another one.
+// For example, a review comment triggers both `pull_request_review` and `pull_request_review_comment`,
+// and the `types` of each event are matched with its own activity types.
+var eventMatchers = map[string]eventMatcher{
+	GithubEventPush:   withPayload(matchPushEvent),
+	GithubEventIssues: withPayload(matchIssuesEvent),
+	// `pull_request_comment` is same as `issue_comment`
+	// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#pull_request_comment-use-issue_comment
+	GithubEventIssueComment:       withPayload(matchIssueCommentEvent),
+	GithubEventPullRequestComment: withPayload(matchIssueCommentEvent),
+	GithubEventPullRequest:        matchPullRequestEventWithRepo,
+	GithubEventPullRequestTarget:  matchPullRequestEventWithRepo,
+	GithubEventPullRequestReview:  withPayload(matchPullRequestReviewEvent),
+	// the review comments are sent as the payloads of pull requests
+	GithubEventPullRequestReviewComment: withPayload(matchPullRequestReviewCommentEvent),
+	GithubEventRelease:                  withPayload(matchReleaseEvent),
+	GithubEventRegistryPackage:          withPayload(matchPackageEvent),
+	GithubEventMergeGroup:               withPayload(matchMergeGroupEvent),
+	GithubEventWorkflowRun:              withPayload(matchWorkflowRunEvent),
+	GithubEventWatch:                    withPayload(matchWatchEvent),
+	GithubEventLabel:                    withPayload(matchLabelEvent),
+	GithubEventMilestone:                withPayload(matchMilestoneEvent),
+	GithubEventRepositoryDispatch:       withPayload(matchRepositoryDispatchEvent),
+	GithubEventOrganization:             withPayload(matchOrganizationEvent),
+	GithubEventMembership:               withPayload(matchMembershipEvent),
+}
+
+func matchPullRequestEventWithRepo(gitRepo *git.Repository, commit *git.Commit, payload api.Payloader, evt *jobparser.Event) bool {
+	p, ok := payload.(*api.PullRequestPayload)
+	if !ok {
+		log.Warn("the payload %T can't be matched with the filters of event %q", payload, evt.Name)
+		return false
+	}
+	return matchPullRequestEvent(gitRepo, commit, p, evt)
+}
+
+// detectMatched reports whether the event in `on` of a workflow matches the triggered event.
+// An event in the list form of `on`, like `on: [push, pull_request]`, has no filters, so it matches any triggered event which it can match,
+// except the defaults of the event, like the activity types of `pull_request`. An event in the map form has its own filters,
+// which are evaluated by the matcher of the event, see eventMatchers.
 func detectMatched(gitRepo *git.Repository, commit *git.Commit, triggedEvent webhook_module.HookEventType, payload api.Payloader, evt *jobparser.Event) bool {
 	if !canGithubEventMatch(evt.Name, triggedEvent) {
 		return false
 	}
 
-	switch triggedEvent {
-	case // events with no activity types
-		webhook_module.HookEventCreate,
-		webhook_module.HookEventDelete,
-		webhook_module.HookEventFork,
-		webhook_module.HookEventWiki,
-		webhook_module.HookEventSchedule:
+	if noFilterEvents.Contains(evt.Name) {
 		if len(evt.Acts()) != 0 {
-			log.Warn("Ignore unsupported %s event arguments %v", triggedEvent, evt.Acts())
+			log.Warn("Ignore unsupported %s event arguments %v", evt.Name, evt.Acts())
 		}
 		// no special filter parameters for these events, just return true if name matched
 		return true
+	}
 
-	case // push
-		webhook_module.HookEventPush:
-		return matchPushEvent(commit, payload.(*api.PushPayload), evt)
-
-	case // issues
-		webhook_module.HookEventIssues,
-		webhook_module.HookEventIssueAssign,
-		webhook_module.HookEventIssueLabel,
-		webhook_module.HookEventIssueMilestone:
-		return matchIssuesEvent(commit, payload.(*api.IssuePayload), evt)
-
-	case // issue_comment
-		webhook_module.HookEventIssueComment,
-		// `pull_request_comment` is same as `issue_comment`
-		// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#pull_request_comment-use-issue_comment
-		webhook_module.HookEventPullRequestComment:
-		return matchIssueCommentEvent(commit, payload.(*api.IssueCommentPayload), evt)
-
-	case // pull_request
-		webhook_module.HookEventPullRequest,
-		webhook_module.HookEventPullRequestSync,
-		webhook_module.HookEventPullRequestAssign,
-		webhook_module.HookEventPullRequestLabel:
-		return matchPullRequestEvent(gitRepo, commit, payload.(*api.PullRequestPayload), evt)
-
-	case // pull_request_review
-		webhook_module.HookEventPullRequestReviewApproved,
-		webhook_module.HookEventPullRequestReviewRejected:
-		return matchPullRequestReviewEvent(commit, payload.(*api.PullRequestPayload), evt)
-
-	case // pull_request_review_comment
-		webhook_module.HookEventPullRequestReviewComment:
-		return matchPullRequestReviewCommentEvent(commit, payload.(*api.PullRequestPayload), evt)
-
-	case // release
-		webhook_module.HookEventRelease:
-		return matchReleaseEvent(commit, payload.(*api.ReleasePayload), evt)
-
-	case // registry_package
-		webhook_module.HookEventPackage:
-		return matchPackageEvent(commit, payload.(*api.PackagePayload), evt)
-
-	default:
-		log.Warn("unsupported event %q", triggedEvent)
+	match, ok := eventMatchers[evt.Name]
+	if !ok {
+		log.Warn("unsupported event %q", evt.Name)
 		return false
 	}
+	return match(gitRepo, commit, payload, evt)
 }
 
 func matchPushEvent(commit *git.Commit, pushPayload *api.PushPayload, evt *jobparser.Event) bool {
@@ -274,30 +639,32 @@ func matchPushEvent(commit *git.Commit, pushPayload *api.PushPayload, evt *jobpa
 				matchTimes++
 			}
 		case "paths":
-			filesChanged, err := commit.GetFilesChangedSinceCommit(pushPayload.Before)
+			filesChanged, ok := pushChangedFiles(commit, pushPayload.Before)
+			if !ok {
+				// fall open if the changed files are too many or unknown
+				matchTimes++
+				break
+			}
+			patterns, err := workflowpattern.CompilePatterns(vals...)
 			if err != nil {
-				log.Error("GetFilesChangedSinceCommit [commit_sha1: %s]: %v", commit.ID.String(), err)
-			} else {
-				patterns, err := workflowpattern.CompilePatterns(vals...)
-				if err != nil {
-					break
-				}
-				if !workflowpattern.Skip(patterns, filesChanged, &workflowpattern.EmptyTraceWriter{}) {
-					matchTimes++
-				}
+				break
+			}
+			if !workflowpattern.Skip(patterns, filesChanged, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
 			}
 		case "paths-ignore":
-			filesChanged, err := commit.GetFilesChangedSinceCommit(pushPayload.Before)
+			filesChanged, ok := pushChangedFiles(commit, pushPayload.Before)
+			if !ok {
+				// fall open if the changed files are too many or unknown
+				matchTimes++
+				break
+			}
+			patterns, err := workflowpattern.CompilePatterns(vals...)
 			if err != nil {
-				log.Error("GetFilesChangedSinceCommit [commit_sha1: %s]: %v", commit.ID.String(), err)
-			} else {
-				patterns, err := workflowpattern.CompilePatterns(vals...)
-				if err != nil {
-					break
-				}
-				if !workflowpattern.Filter(patterns, filesChanged, &workflowpattern.EmptyTraceWriter{}) {
-					matchTimes++
-				}
+				break
+			}
+			if !workflowpattern.Filter(patterns, filesChanged, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
 			}
 		default:
 			log.Warn("push event unsupported condition %q", cond)
@@ -423,9 +790,9 @@ func matchPullRequestEvent(gitRepo *git.Repository, commit *git.Commit, prPayloa
 				matchTimes++
 			}
 		case "paths":
-			filesChanged, err := headCommit.GetFilesChangedSinceCommit(prPayload.PullRequest.Base.Ref)
+			filesChanged, err := pullRequestChangedFiles(gitRepo, headCommit, prPayload.PullRequest.Base.Ref)
 			if err != nil {
-				log.Error("GetFilesChangedSinceCommit [commit_sha1: %s]: %v", headCommit.ID.String(), err)
+				log.Error("pullRequestChangedFiles [commit_sha1: %s]: %v", headCommit.ID.String(), err)
 			} else {
 				patterns, err := workflowpattern.CompilePatterns(vals...)
 				if err != nil {
@@ -436,9 +803,9 @@ func matchPullRequestEvent(gitRepo *git.Repository, commit *git.Commit, prPayloa
 				}
 			}
 		case "paths-ignore":
-			filesChanged, err := headCommit.GetFilesChangedSinceCommit(prPayload.PullRequest.Base.Ref)
+			filesChanged, err := pullRequestChangedFiles(gitRepo, headCommit, prPayload.PullRequest.Base.Ref)
 			if err != nil {
-				log.Error("GetFilesChangedSinceCommit [commit_sha1: %s]: %v", headCommit.ID.String(), err)
+				log.Error("pullRequestChangedFiles [commit_sha1: %s]: %v", headCommit.ID.String(), err)
 			} else {
 				patterns, err := workflowpattern.CompilePatterns(vals...)
 				if err != nil {
@@ -658,3 +1025,304 @@ func matchPackageEvent(commit *git.Commit, payload *api.PackagePayload, evt *job
 	}
 	return matchTimes == len(evt.Acts())
 }
+
+func matchMergeGroupEvent(commit *git.Commit, payload *api.MergeGroupPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#merge_group
+			// Activity types with the same name:
+			// checks_requested
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// NONE
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		case "branches":
+			// the branch filter is applied to the target branch of the merge group, not the temporary ref
+			refName := git.RefName(payload.MergeGroup.BaseRef)
+			patterns, err := workflowpattern.CompilePatterns(vals...)
+			if err != nil {
+				break
+			}
+			if !workflowpattern.Skip(patterns, []string{refName.ShortName()}, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
+			}
+		case "branches-ignore":
+			refName := git.RefName(payload.MergeGroup.BaseRef)
+			patterns, err := workflowpattern.CompilePatterns(vals...)
+			if err != nil {
+				break
+			}
+			if !workflowpattern.Filter(patterns, []string{refName.ShortName()}, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
+			}
+		default:
+			log.Warn("merge group event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchWorkflowRunEvent(commit *git.Commit, payload *api.WorkflowRunPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#workflow_run
+			// Activity types with the same name:
+			// completed
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// requested, in_progress
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		case "workflows":
+			// the upstream workflow can be specified by its name or by the name of its file
+			for _, val := range vals {
+				if val == payload.WorkflowRun.Name || val == payload.WorkflowRun.Path {
+					matchTimes++
+					break
+				}
+			}
+		case "conclusions":
+			// It's a Gitea extension, the workflow will be triggered only if the upstream run concluded with one of them
+			for _, val := range vals {
+				if val == payload.WorkflowRun.Conclusion {
+					matchTimes++
+					break
+				}
+			}
+		case "branches":
+			patterns, err := workflowpattern.CompilePatterns(vals...)
+			if err != nil {
+				break
+			}
+			if !workflowpattern.Skip(patterns, []string{payload.WorkflowRun.HeadBranch}, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
+			}
+		case "branches-ignore":
+			patterns, err := workflowpattern.CompilePatterns(vals...)
+			if err != nil {
+				break
+			}
+			if !workflowpattern.Filter(patterns, []string{payload.WorkflowRun.HeadBranch}, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
+			}
+		default:
+			log.Warn("workflow run event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchWatchEvent(commit *git.Commit, payload *api.WatchPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#watch
+			// Activity types with the same name:
+			// started
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// NONE
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		default:
+			log.Warn("watch event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchLabelEvent(commit *git.Commit, payload *api.LabelPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#label
+			// Activity types with the same name:
+			// created, edited, deleted
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// NONE
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		default:
+			log.Warn("label event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchMilestoneEvent(commit *git.Commit, payload *api.MilestonePayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#milestone
+			// Activity types with the same name:
+			// created, opened, closed
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// edited, deleted
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		default:
+			log.Warn("milestone event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchRepositoryDispatchEvent(commit *git.Commit, payload *api.RepositoryDispatchPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#repository_dispatch
+			// The types are the custom `event_type` of the dispatches, and they are matched exactly like GitHub.
+			for _, val := range vals {
+				if val == payload.Action {
+					matchTimes++
+					break
+				}
+			}
+		default:
+			log.Warn("repository dispatch event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchOrganizationEvent(commit *git.Commit, payload *api.OrganizationPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/webhooks/webhook-events-and-payloads#organization
+			// Activity types with the same name:
+			// member_added, member_removed
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// deleted, renamed, member_invited
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		default:
+			log.Warn("organization event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
+
+func matchMembershipEvent(commit *git.Commit, payload *api.MembershipPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/webhooks/webhook-events-and-payloads#membership
+			// Activity types with the same name:
+			// added, removed
+			// Activity types need to be converted:
+			// NONE
+			// Unsupported activity types:
+			// NONE
+
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		default:
+			log.Warn("membership event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
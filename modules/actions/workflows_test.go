@@ -10,7 +10,9 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 
+	"github.com/nektos/act/pkg/jobparser"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDetectMatched(t *testing.T) {
@@ -111,6 +113,160 @@ func TestDetectMatched(t *testing.T) {
 			yamlOn:       "on:\n  registry_package:\n    types: [updated]",
 			expected:     false,
 		},
+		{
+			desc:         "HookEventMergeGroup(merge_group) `checks_requested` action matches GithubEventMergeGroup(merge_group) with `checks_requested` activity type",
+			triggedEvent: webhook_module.HookEventMergeGroup,
+			payload:      &api.MergeGroupPayload{Action: api.HookMergeGroupChecksRequested, MergeGroup: &api.MergeGroup{BaseRef: "refs/heads/main"}},
+			yamlOn:       "on:\n  merge_group:\n    types: [checks_requested]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventMergeGroup(merge_group) matches GithubEventMergeGroup(merge_group) with target branch filter",
+			triggedEvent: webhook_module.HookEventMergeGroup,
+			payload:      &api.MergeGroupPayload{Action: api.HookMergeGroupChecksRequested, MergeGroup: &api.MergeGroup{BaseRef: "refs/heads/main"}},
+			yamlOn:       "on:\n  merge_group:\n    branches: [main]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventMergeGroup(merge_group) doesn't match GithubEventMergeGroup(merge_group) with another target branch",
+			triggedEvent: webhook_module.HookEventMergeGroup,
+			payload:      &api.MergeGroupPayload{Action: api.HookMergeGroupChecksRequested, MergeGroup: &api.MergeGroup{BaseRef: "refs/heads/main"}},
+			yamlOn:       "on:\n  merge_group:\n    branches: [release/*]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventWorkflowRun(workflow_run) `completed` action matches GithubEventWorkflowRun(workflow_run) with the upstream workflow name",
+			triggedEvent: webhook_module.HookEventWorkflowRun,
+			payload:      &api.WorkflowRunPayload{Action: api.HookWorkflowRunCompleted, WorkflowRun: &api.ActionWorkflowRun{Name: "CI", Path: "ci.yaml", Conclusion: "success", HeadBranch: "main"}},
+			yamlOn:       "on:\n  workflow_run:\n    workflows: [CI]\n    types: [completed]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventWorkflowRun(workflow_run) matches GithubEventWorkflowRun(workflow_run) with the upstream workflow file name",
+			triggedEvent: webhook_module.HookEventWorkflowRun,
+			payload:      &api.WorkflowRunPayload{Action: api.HookWorkflowRunCompleted, WorkflowRun: &api.ActionWorkflowRun{Name: "CI", Path: "ci.yaml", Conclusion: "success", HeadBranch: "main"}},
+			yamlOn:       "on:\n  workflow_run:\n    workflows: [ci.yaml]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventWorkflowRun(workflow_run) doesn't match GithubEventWorkflowRun(workflow_run) with another upstream workflow",
+			triggedEvent: webhook_module.HookEventWorkflowRun,
+			payload:      &api.WorkflowRunPayload{Action: api.HookWorkflowRunCompleted, WorkflowRun: &api.ActionWorkflowRun{Name: "CI", Path: "ci.yaml", Conclusion: "success", HeadBranch: "main"}},
+			yamlOn:       "on:\n  workflow_run:\n    workflows: [Lint]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventWorkflowRun(workflow_run) doesn't match GithubEventWorkflowRun(workflow_run) with another conclusion",
+			triggedEvent: webhook_module.HookEventWorkflowRun,
+			payload:      &api.WorkflowRunPayload{Action: api.HookWorkflowRunCompleted, WorkflowRun: &api.ActionWorkflowRun{Name: "CI", Path: "ci.yaml", Conclusion: "failure", HeadBranch: "main"}},
+			yamlOn:       "on:\n  workflow_run:\n    workflows: [CI]\n    conclusions: [success]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventWorkflowRun(workflow_run) doesn't match GithubEventWorkflowRun(workflow_run) with an ignored head branch",
+			triggedEvent: webhook_module.HookEventWorkflowRun,
+			payload:      &api.WorkflowRunPayload{Action: api.HookWorkflowRunCompleted, WorkflowRun: &api.ActionWorkflowRun{Name: "CI", Path: "ci.yaml", Conclusion: "success", HeadBranch: "main"}},
+			yamlOn:       "on:\n  workflow_run:\n    workflows: [CI]\n    branches-ignore: [main]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventWatch(watch) `started` action matches GithubEventWatch(watch) with `started` activity type",
+			triggedEvent: webhook_module.HookEventWatch,
+			payload:      &api.WatchPayload{Action: api.HookWatchStarted},
+			yamlOn:       "on:\n  watch:\n    types: [started]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventPageBuild(page_build) matches GithubEventPageBuild(page_build)",
+			triggedEvent: webhook_module.HookEventPageBuild,
+			payload:      &api.PageBuildPayload{Build: &api.PageBuild{Status: api.PageBuildStatusBuilt}},
+			yamlOn:       "on: page_build",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventLabel(label) `created` action matches GithubEventLabel(label) with `created` activity type",
+			triggedEvent: webhook_module.HookEventLabel,
+			payload:      &api.LabelPayload{Action: api.HookLabelCreated},
+			yamlOn:       "on:\n  label:\n    types: [created]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventLabel(label) `deleted` action doesn't match GithubEventLabel(label) with `created` and `edited` activity types",
+			triggedEvent: webhook_module.HookEventLabel,
+			payload:      &api.LabelPayload{Action: api.HookLabelDeleted},
+			yamlOn:       "on:\n  label:\n    types: [created, edited]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventIssueLabel(issue_label) doesn't match GithubEventLabel(label)",
+			triggedEvent: webhook_module.HookEventIssueLabel,
+			payload:      &api.IssuePayload{Action: api.HookIssueLabelUpdated},
+			yamlOn:       "on: label",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventMilestone(milestone) `closed` action matches GithubEventMilestone(milestone) with `closed` activity type",
+			triggedEvent: webhook_module.HookEventMilestone,
+			payload:      &api.MilestonePayload{Action: api.HookMilestoneClosed},
+			yamlOn:       "on:\n  milestone:\n    types: [closed]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventMilestone(milestone) `created` action doesn't match GithubEventMilestone(milestone) with `closed` activity type",
+			triggedEvent: webhook_module.HookEventMilestone,
+			payload:      &api.MilestonePayload{Action: api.HookMilestoneCreated},
+			yamlOn:       "on:\n  milestone:\n    types: [closed]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventRepositoryDispatch(repository_dispatch) matches GithubEventRepositoryDispatch(repository_dispatch) without types",
+			triggedEvent: webhook_module.HookEventRepositoryDispatch,
+			payload:      &api.RepositoryDispatchPayload{Action: "deploy"},
+			yamlOn:       "on: repository_dispatch",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventRepositoryDispatch(repository_dispatch) `deploy` event type matches GithubEventRepositoryDispatch(repository_dispatch) with `deploy` type",
+			triggedEvent: webhook_module.HookEventRepositoryDispatch,
+			payload:      &api.RepositoryDispatchPayload{Action: "deploy"},
+			yamlOn:       "on:\n  repository_dispatch:\n    types: [build, deploy]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventRepositoryDispatch(repository_dispatch) `deploy-prod` event type doesn't match GithubEventRepositoryDispatch(repository_dispatch) with `deploy` type",
+			triggedEvent: webhook_module.HookEventRepositoryDispatch,
+			payload:      &api.RepositoryDispatchPayload{Action: "deploy-prod"},
+			yamlOn:       "on:\n  repository_dispatch:\n    types: [deploy]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventOrganization(organization) `member_added` action matches GithubEventOrganization(organization) with `member_added` activity type",
+			triggedEvent: webhook_module.HookEventOrganization,
+			payload:      &api.OrganizationPayload{Action: api.HookOrganizationMemberAdded},
+			yamlOn:       "on:\n  organization:\n    types: [member_added]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventOrganization(organization) `member_removed` action doesn't match GithubEventOrganization(organization) with `member_added` activity type",
+			triggedEvent: webhook_module.HookEventOrganization,
+			payload:      &api.OrganizationPayload{Action: api.HookOrganizationMemberRemoved},
+			yamlOn:       "on:\n  organization:\n    types: [member_added]",
+			expected:     false,
+		},
+		{
+			desc:         "HookEventMembership(membership) `removed` action matches GithubEventMembership(membership) without types",
+			triggedEvent: webhook_module.HookEventMembership,
+			payload:      &api.MembershipPayload{Action: api.HookMembershipRemoved},
+			yamlOn:       "on: membership",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventMembership(membership) `added` action doesn't match GithubEventMembership(membership) with `removed` activity type",
+			triggedEvent: webhook_module.HookEventMembership,
+			payload:      &api.MembershipPayload{Action: api.HookMembershipAdded},
+			yamlOn:       "on:\n  membership:\n    types: [removed]",
+			expected:     false,
+		},
 		{
 			desc:         "HookEventWiki(wiki) matches GithubEventGollum(gollum)",
 			triggedEvent: webhook_module.HookEventWiki,
@@ -136,3 +292,242 @@ func TestDetectMatched(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckTriggerEvent(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		content      string
+		triggerEvent string
+		triggedEvent webhook_module.HookEventType
+		expectErr    bool
+	}{
+		{
+			desc:         "pull_request is declared and matches HookEventPullRequest",
+			content:      "on: pull_request",
+			triggerEvent: GithubEventPullRequest,
+			triggedEvent: webhook_module.HookEventPullRequest,
+			expectErr:    false,
+		},
+		{
+			desc:         "pull_request_target is declared and matches HookEventPullRequestSync",
+			content:      "on: pull_request_target",
+			triggerEvent: GithubEventPullRequestTarget,
+			triggedEvent: webhook_module.HookEventPullRequestSync,
+			expectErr:    false,
+		},
+		{
+			desc:         "pull_request_target is not declared by a pull_request workflow",
+			content:      "on: pull_request",
+			triggerEvent: GithubEventPullRequestTarget,
+			triggedEvent: webhook_module.HookEventPullRequest,
+			expectErr:    true,
+		},
+		{
+			desc:         "pull_request is not declared by a pull_request_target workflow",
+			content:      "on: pull_request_target",
+			triggerEvent: GithubEventPullRequest,
+			triggedEvent: webhook_module.HookEventPullRequest,
+			expectErr:    true,
+		},
+		{
+			desc:         "push is declared but can't match HookEventPullRequest",
+			content:      "on: [push, pull_request]",
+			triggerEvent: GithubEventPush,
+			triggedEvent: webhook_module.HookEventPullRequest,
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckTriggerEvent(&DetectedWorkflow{
+				EntryName:    "test.yml",
+				TriggerEvent: &jobparser.Event{Name: tc.triggerEvent},
+				Content:      []byte(tc.content),
+			}, tc.triggedEvent)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseWorkflowsWithActionMetadata(t *testing.T) {
+	files := map[string]string{
+		"test.yml": `
+on:
+  push:
+    paths:
+      - action.yml
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./
+`,
+		"action.yml": `
+name: greet
+inputs:
+  who:
+    default: world
+runs:
+  using: composite
+  steps:
+    - run: echo "hello ${{ inputs.who }}"
+      shell: bash
+`,
+		"tools/setup.yaml": `
+name: setup
+runs:
+  using: node20
+  main: index.js
+`,
+		"readme.md": "# workflows",
+	}
+
+	var parsed []string
+	for name, content := range files {
+		if !isWorkflowFile(name) {
+			continue
+		}
+		if pwf := parseWorkflow(name, "", []byte(content)); pwf != nil {
+			parsed = append(parsed, pwf.EntryName)
+			assert.Len(t, pwf.Events, 1)
+			assert.Equal(t, []string{"action.yml"}, pwf.Events[0].Acts()["paths"])
+		}
+	}
+	assert.Equal(t, []string{"test.yml"}, parsed)
+
+	assert.True(t, IsWorkflow(".gitea/workflows/test.yml"))
+	assert.False(t, IsWorkflow(".gitea/workflows/action.yml"))
+	assert.False(t, IsWorkflow(".github/workflows/greet/action.yaml"))
+}
+
+func TestFindSameNamedWorkflows(t *testing.T) {
+	content := func(name string) []byte {
+		return []byte(name + "\non: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make test\n")
+	}
+	var workflows []*ParsedWorkflow
+	for _, f := range []struct{ dir, name, content string }{
+		{".gitea/workflows", "test.yml", "name: CI"},
+		{".gitea/workflows", "lint.yml", "name: CI"},
+		{".github/workflows", "ci.yml", "name: CI"},
+		{".gitea/workflows", "release.yml", "name: Release"},
+		{".gitea/workflows", "a.yml", ""},
+		{".gitea/workflows", "b.yml", ""},
+	} {
+		pwf := parseWorkflow(f.name, "", content(f.content))
+		require.NotNil(t, pwf)
+		pwf.Dir = f.dir
+		workflows = append(workflows, pwf)
+	}
+
+	assert.Equal(t, map[string][]string{
+		".gitea/workflows/test.yml": {".gitea/workflows/lint.yml", ".github/workflows/ci.yml"},
+		".gitea/workflows/lint.yml": {".gitea/workflows/test.yml", ".github/workflows/ci.yml"},
+		".github/workflows/ci.yml":  {".gitea/workflows/lint.yml", ".gitea/workflows/test.yml"},
+	}, FindSameNamedWorkflows(workflows))
+}
+
+func TestMatchWorkflowsEventForms(t *testing.T) {
+	const (
+		listForm = "on: [push, pull_request, schedule]"
+		mapForm  = `on:
+  push:
+    branches: [main]
+    tags: [v*]
+  pull_request:
+    branches: [release/*]
+  schedule:
+    - cron: '0 0 * * *'`
+		mapTagsOnly = `on:
+  push:
+    tags: [v*]
+  pull_request:
+    types: [closed]`
+		mapNullFilters = `on:
+  push:
+  pull_request:`
+	)
+	pushTo := func(ref string) *api.PushPayload {
+		return &api.PushPayload{Ref: ref}
+	}
+	pullRequest := func(action api.HookIssueAction, base string) *api.PullRequestPayload {
+		return &api.PullRequestPayload{Action: action, PullRequest: &api.PullRequest{Base: &api.PRBranchInfo{Ref: base}}}
+	}
+
+	testCases := []struct {
+		desc           string
+		on             string
+		triggedEvent   webhook_module.HookEventType
+		payload        api.Payloader
+		matched        []string // the names of the matched events in `on`
+		schedules      int
+		detectSchedule bool
+	}{
+		{desc: "list form push to branch", on: listForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/feature"), matched: []string{"push"}},
+		{desc: "list form push of tag", on: listForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/tags/v1.0"), matched: []string{"push"}},
+		{desc: "list form pull request opened", on: listForm, triggedEvent: webhook_module.HookEventPullRequest, payload: pullRequest(api.HookIssueOpened, "feature"), matched: []string{"pull_request"}},
+		{desc: "list form pull request closed isn't a default type", on: listForm, triggedEvent: webhook_module.HookEventPullRequest, payload: pullRequest(api.HookIssueClosed, "main")},
+		// a schedule in the list form has no crons, so it never fires
+		{desc: "list form schedule without crons", on: listForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/main"), matched: []string{"push"}, detectSchedule: true},
+
+		{desc: "map form push to filtered branch", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/main"), matched: []string{"push"}},
+		{desc: "map form push to other branch", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/feature")},
+		{desc: "map form push of filtered tag", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/tags/v1.0"), matched: []string{"push"}},
+		{desc: "map form push of other tag", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/tags/nightly")},
+		// the branches of push don't gate pull_request, and vice versa
+		{desc: "map form pull request to its branch", on: mapForm, triggedEvent: webhook_module.HookEventPullRequest, payload: pullRequest(api.HookIssueOpened, "release/v1"), matched: []string{"pull_request"}},
+		{desc: "map form pull request to branch of push", on: mapForm, triggedEvent: webhook_module.HookEventPullRequestSync, payload: pullRequest(api.HookIssueSynchronized, "main")},
+		{desc: "map form push to branch of pull request", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/release/v1")},
+		{desc: "map form schedule", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/main"), matched: []string{"push"}, detectSchedule: true, schedules: 1},
+		{desc: "map form schedule isn't detected for events", on: mapForm, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/main"), matched: []string{"push"}},
+
+		{desc: "map form tags only push to branch", on: mapTagsOnly, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/main")},
+		{desc: "map form tags only push of tag", on: mapTagsOnly, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/tags/v2"), matched: []string{"push"}},
+		// the types of pull_request don't gate push
+		{desc: "map form types of pull request", on: mapTagsOnly, triggedEvent: webhook_module.HookEventPullRequest, payload: pullRequest(api.HookIssueClosed, "main"), matched: []string{"pull_request"}},
+		{desc: "map form other types of pull request", on: mapTagsOnly, triggedEvent: webhook_module.HookEventPullRequest, payload: pullRequest(api.HookIssueOpened, "main")},
+
+		{desc: "map form without filters push to branch", on: mapNullFilters, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/heads/feature"), matched: []string{"push"}},
+		{desc: "map form without filters push of tag", on: mapNullFilters, triggedEvent: webhook_module.HookEventPush, payload: pushTo("refs/tags/v1.0"), matched: []string{"push"}},
+		{desc: "map form without filters pull request", on: mapNullFilters, triggedEvent: webhook_module.HookEventPullRequest, payload: pullRequest(api.HookIssueReOpened, "feature"), matched: []string{"pull_request"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pwf := parseWorkflow("test.yml", "", []byte(tc.on+"\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make test\n"))
+			require.NotNil(t, pwf)
+			workflows, schedules := MatchWorkflows(nil, nil, []*ParsedWorkflow{pwf}, tc.triggedEvent, tc.payload, tc.detectSchedule)
+			var matched []string
+			for _, dwf := range workflows {
+				matched = append(matched, dwf.TriggerEvent.Name)
+			}
+			assert.Equal(t, tc.matched, matched)
+			assert.Len(t, schedules, tc.schedules)
+		})
+	}
+}
+
+func TestDetectMatchedDispatchesByEvent(t *testing.T) {
+	// a review comment triggers both pull_request_review and pull_request_review_comment, each with its own activity types
+	payload := &api.PullRequestPayload{Action: api.HookIssueReviewed}
+	for yamlOn, expected := range map[string]bool{
+		"on:\n  pull_request_review:\n    types: [submitted]":         true,
+		"on:\n  pull_request_review:\n    types: [dismissed]":         false,
+		"on:\n  pull_request_review_comment:\n    types: [created]":   true,
+		"on:\n  pull_request_review_comment:\n    types: [deleted]":   false,
+		"on:\n  pull_request_review_comment:\n    types: [submitted]": false,
+	} {
+		evts, err := GetEventsFromContent([]byte(yamlOn))
+		require.NoError(t, err)
+		require.Len(t, evts, 1)
+		assert.Equal(t, expected, detectMatched(nil, nil, webhook_module.HookEventPullRequestReviewComment, payload, evts[0]), yamlOn)
+	}
+
+	// the filters never match the payload of another event
+	evts, err := GetEventsFromContent([]byte("on:\n  push:\n    branches: [main]"))
+	require.NoError(t, err)
+	assert.False(t, detectMatched(nil, nil, webhook_module.HookEventPush, &api.PullRequestPayload{}, evts[0]))
+}
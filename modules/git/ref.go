@@ -4,6 +4,7 @@
 package git
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -69,6 +70,15 @@ const ForPrefix = "refs/for/"
 
 // TODO: /refs/for-review for suggest change interface
 
+// MergeGroupPrefix is the base directory of the temporary branches created by a merge queue:
+// refs/heads/gitea-merge-queue/<target-branch>/pr-<index>-<head-sha>
+const MergeGroupPrefix = BranchPrefix + "gitea-merge-queue/"
+
+// RefNameFromMergeGroup returns the temporary ref used to check a pull request queued for merging into the target branch
+func RefNameFromMergeGroup(targetBranch string, index int64, headSHA string) RefName {
+	return RefName(fmt.Sprintf("%s%s/pr-%d-%s", MergeGroupPrefix, targetBranch, index, headSHA))
+}
+
 // RefName represents a full git reference name
 type RefName string
 
@@ -100,6 +110,10 @@ func (ref RefName) IsPull() bool {
 	return strings.HasPrefix(string(ref), PullPrefix) && strings.IndexByte(string(ref)[len(PullPrefix):], '/') > -1
 }
 
+func (ref RefName) IsMergeGroup() bool {
+	return strings.HasPrefix(string(ref), MergeGroupPrefix)
+}
+
 func (ref RefName) IsFor() bool {
 	return strings.HasPrefix(string(ref), ForPrefix)
 }
@@ -5,6 +5,9 @@ package setting
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,22 +17,176 @@ import (
 // Actions settings
 var (
 	Actions = struct {
-		LogStorage            *Storage // how the created logs should be stored
-		ArtifactStorage       *Storage // how the created artifacts should be stored
-		ArtifactRetentionDays int64    `ini:"ARTIFACT_RETENTION_DAYS"`
-		Enabled               bool
-		DefaultActionsURL     defaultActionsURL `ini:"DEFAULT_ACTIONS_URL"`
-		ZombieTaskTimeout     time.Duration     `ini:"ZOMBIE_TASK_TIMEOUT"`
-		EndlessTaskTimeout    time.Duration     `ini:"ENDLESS_TASK_TIMEOUT"`
-		AbandonedJobTimeout   time.Duration     `ini:"ABANDONED_JOB_TIMEOUT"`
-		SkipWorkflowStrings   []string          `ìni:"SKIP_WORKFLOW_STRINGS"`
+		LogStorage              *Storage // how the created logs should be stored
+		ArtifactStorage         *Storage // how the created artifacts should be stored
+		LogArchiveStorage       *Storage // where the logs of the completed runs are archived, only if LogArchive is true
+		ArtifactRetentionDays   int64    `ini:"ARTIFACT_RETENTION_DAYS"`
+		ArtifactRepoQuota       int64    `ini:"ARTIFACT_REPO_QUOTA"` // the total size in MiB of the artifacts of a repository, zero means unlimited
+		Enabled                 bool
+		DefaultActionsURL       defaultActionsURL `ini:"DEFAULT_ACTIONS_URL"`
+		ZombieTaskTimeout       time.Duration     `ini:"ZOMBIE_TASK_TIMEOUT"`
+		EndlessTaskTimeout      time.Duration     `ini:"ENDLESS_TASK_TIMEOUT"`
+		AbandonedJobTimeout     time.Duration     `ini:"ABANDONED_JOB_TIMEOUT"`
+		SkipWorkflowStrings     []string          `ìni:"SKIP_WORKFLOW_STRINGS"`
+		WorkflowsCacheSize      int               `ini:"WORKFLOWS_CACHE_SIZE"`
+		ApprovalLabel           string            `ini:"APPROVAL_LABEL"`
+		ApprovalCommand         string            `ini:"APPROVAL_COMMAND"`
+		StrictEventCheck        bool              `ini:"STRICT_EVENT_CHECK"`
+		MaxStepRetries          int               `ini:"MAX_STEP_RETRIES"`
+		PolicyWebhookURL        string            `ini:"POLICY_WEBHOOK_URL"`
+		PolicyWebhookTimeout    time.Duration     `ini:"POLICY_WEBHOOK_TIMEOUT"`
+		PolicyWebhookFailOpen   bool              `ini:"POLICY_WEBHOOK_FAIL_OPEN"`
+		PolicyWebhookCacheTTL   time.Duration     `ini:"POLICY_WEBHOOK_CACHE_TTL"`
+		DisabledEvents          []string          `ini:"DISABLED_EVENTS"`
+		StrictRunsOnCheck       bool              `ini:"STRICT_RUNS_ON_CHECK"`
+		HostedFallbackPolicy    bool              `ini:"HOSTED_FALLBACK_POLICY"`
+		HostedFallbackPublic    bool              `ini:"HOSTED_FALLBACK_PUBLIC"`
+		HostedFallbackOwners    []string          `ini:"HOSTED_FALLBACK_OWNERS"`
+		GateTimeout             time.Duration     `ini:"GATE_TIMEOUT"`
+		ApprovalScope           string            `ini:"APPROVAL_SCOPE"`
+		CacheRepoQuota          int64             `ini:"CACHE_REPO_QUOTA"`
+		DefaultTokenPermissions string            `ini:"DEFAULT_TOKEN_PERMISSIONS"`
+		MinutesQuota            int64             `ini:"MINUTES_QUOTA"`
+		MinutesQuotaPeriod      string            `ini:"MINUTES_QUOTA_PERIOD"`
+		MinutesQuotaPolicy      string            `ini:"MINUTES_QUOTA_POLICY"`
+		MinutesQuotaSelfHosted  bool              `ini:"MINUTES_QUOTA_SELF_HOSTED"` // whether the minutes of self-hosted runners count against the quota
+		MissingCommitPolicy     string            `ini:"MISSING_COMMIT_POLICY"`
+		WorkflowParallelism     int               `ini:"WORKFLOW_PARALLELISM"`
+		ProductionEnvironments  []string          `ini:"PRODUCTION_ENVIRONMENTS"`
+		ProductionApproval      bool              `ini:"PRODUCTION_APPROVAL"`    // whether the production deploys need approval before they start
+		ProtectedTagApproval    bool              `ini:"PROTECTED_TAG_APPROVAL"` // whether the runs triggered by creating or pushing protected tags need approval before they start
+		AllowedImages           []string          `ini:"ALLOWED_IMAGES"`
+		RequireImageDigest      bool              `ini:"REQUIRE_IMAGE_DIGEST"` // whether the container images must be pinned by digests
+		AuditExporter           string            `ini:"AUDIT_EXPORTER"`
+		AuditExportTarget       string            `ini:"AUDIT_EXPORT_TARGET"`
+		AuditExportBuffer       int               `ini:"AUDIT_EXPORT_BUFFER"`    // the max number of the audit events waiting to be exported
+		PathsFilterMaxFiles     int               `ini:"PATHS_FILTER_MAX_FILES"` // the max number of the changed files of a push to match the paths filters
+		RunContextMaxSize       int64             `ini:"RUN_CONTEXT_MAX_SIZE"`   // the max size in bytes of the stored contexts of a run, zero means they are not stored
+		RunContextSecrets       string            `ini:"RUN_CONTEXT_SECRETS"`
+		WorkflowDirsPrecedence  string            `ini:"WORKFLOW_DIRS_PRECEDENCE"`
+		PullRequestPathsDiff    string            `ini:"PULL_REQUEST_PATHS_DIFF"` // how the changed files of a pull request are computed to match the paths filters
+		RunEventPublisher       string            `ini:"RUN_EVENT_PUBLISHER"`
+		RunEventTarget          string            `ini:"RUN_EVENT_TARGET"`
+		RunEventBuffer          int               `ini:"RUN_EVENT_BUFFER"` // the max number of the run events waiting to be published
+		DetectionTrace          bool              `ini:"DETECTION_TRACE"`  // whether every run stores the trace of why it was created
+		TracingEndpoint         string            `ini:"TRACING_ENDPOINT"` // the OTLP/HTTP endpoint which the spans of triggering the workflows are exported to, empty disables tracing
+		DeployGuard             []string          `ini:"DEPLOY_GUARD"`
+		DeployGuardTimeout      time.Duration     `ini:"DEPLOY_GUARD_TIMEOUT"`      // how long an unfinished previous deploy run may stay unchanged before the deploy guard ignores it
+		ReusableWorkflowCallers int               `ini:"REUSABLE_WORKFLOW_CALLERS"` // the max number of the repositories notified when a reusable workflow called by them changes, zero disables it
+		DefaultRunsOn           string            `ini:"DEFAULT_RUNS_ON"`           // the label applied to the jobs which no runners match, empty means no fallback
+		LogArchive              bool              `ini:"LOG_ARCHIVE"`               // whether the logs of the completed runs are archived to the storage of [storage.actions_log_archive]
+		LogArchivePruneAfter    time.Duration     `ini:"LOG_ARCHIVE_PRUNE_AFTER"`   // how long the local copies of the archived logs are kept, zero means they are kept forever
+		ScheduleRequirements    []string          `ini:"SCHEDULE_REQUIREMENTS"`     // the repositories must meet any of them to fire schedules, empty means no requirements
+		ScheduleActivityWindow  time.Duration     `ini:"SCHEDULE_ACTIVITY_WINDOW"`  // how recently a repository must have been updated to meet the "recent-activity" requirement
+		ScheduleJitter          time.Duration     `ini:"SCHEDULE_JITTER"`           // the max delay of the fires of the schedules to spread them, zero disables it
+		OrgEventsRepo           string            `ini:"ORG_EVENTS_REPO"`           // the name of the repository of every organization which hosts the workflows of the organization events, empty disables them
+
+		ForbiddenCommands      []*ForbiddenCommand `ini:"-"`                        // the rules of the commands forbidden in the `run` steps, loaded from [actions.forbidden_commands]
+		ForbiddenCommandsLimit int64               `ini:"FORBIDDEN_COMMANDS_LIMIT"` // the max total size in bytes of the `run` steps of a workflow which are scanned for the forbidden commands
 	}{
-		Enabled:             true,
-		DefaultActionsURL:   defaultActionsURLGitHub,
-		SkipWorkflowStrings: []string{"[skip ci]", "[ci skip]", "[no ci]", "[skip actions]", "[actions skip]"},
+		Enabled:                 true,
+		DefaultActionsURL:       defaultActionsURLGitHub,
+		SkipWorkflowStrings:     []string{"[skip ci]", "[ci skip]", "[no ci]", "[skip actions]", "[actions skip]"},
+		MaxStepRetries:          5,
+		PolicyWebhookTimeout:    5 * time.Second,
+		PolicyWebhookCacheTTL:   time.Minute,
+		HostedFallbackPublic:    true,
+		GateTimeout:             24 * time.Hour,
+		ApprovalScope:           ApprovalScopeRepo,
+		CacheRepoQuota:          10240,
+		DefaultTokenPermissions: TokenPermissionsWrite,
+		MinutesQuotaPeriod:      MinutesQuotaPeriodMonth,
+		MinutesQuotaPolicy:      MinutesQuotaPolicyQueue,
+		MissingCommitPolicy:     MissingCommitPolicySkip,
+		WorkflowParallelism:     1,
+		ProductionEnvironments:  []string{"production"},
+		AuditExportBuffer:       1000,
+		PathsFilterMaxFiles:     3000,
+		RunContextMaxSize:       256 * 1024,
+		RunContextSecrets:       RunContextSecretsRedacted,
+		WorkflowDirsPrecedence:  WorkflowDirsPrecedenceGitea,
+		PullRequestPathsDiff:    PullRequestPathsDiffThreeDot,
+		RunEventBuffer:          1000,
+		DeployGuardTimeout:      3 * time.Hour,
+		ForbiddenCommandsLimit:  1024 * 1024,
+		ScheduleActivityWindow:  90 * 24 * time.Hour,
 	}
 )
 
+// ForbiddenCommand is a rule of the commands forbidden in the `run` steps of workflows, it's a key of [actions.forbidden_commands]
+// whose name identifies the rule and whose value is the regular expression matching the commands
+type ForbiddenCommand struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+const (
+	ApprovalScopeRepo     = "repo"     // the users approved in a repository are trusted only in the repository
+	ApprovalScopeOwner    = "owner"    // the users approved in a repository are trusted in all repositories of the same owner
+	ApprovalScopeInstance = "instance" // the users approved in a repository are trusted in all repositories of the instance
+)
+
+const (
+	TokenPermissionsRead  = "read"  // the tokens are read-only unless the workflows request write permissions
+	TokenPermissionsWrite = "write" // the tokens have write permissions unless the workflows request read-only permissions
+)
+
+const (
+	MinutesQuotaPeriodDay   = "day"   // the usage of runner minutes is reset every day
+	MinutesQuotaPeriodWeek  = "week"  // the usage of runner minutes is reset every ISO week
+	MinutesQuotaPeriodMonth = "month" // the usage of runner minutes is reset every month
+)
+
+const (
+	MinutesQuotaPolicyQueue = "queue" // the jobs wait until the next period if the quota has been used up
+	MinutesQuotaPolicyFail  = "fail"  // the new runs fail at once if the quota has been used up
+)
+
+const (
+	MissingCommitPolicySkip   = "skip"   // the events whose commits don't exist any longer are skipped with a warning
+	MissingCommitPolicyNotice = "notice" // the events are skipped, and a system notice is recorded
+	MissingCommitPolicyError  = "error"  // the events fail with an error
+)
+
+const (
+	AuditExporterNone    = ""        // the audit events of runs are not exported
+	AuditExporterWebhook = "webhook" // the audit events are posted to the URL of AUDIT_EXPORT_TARGET
+	AuditExporterFile    = "file"    // the audit events are appended to the file of AUDIT_EXPORT_TARGET as JSON lines
+	AuditExporterSyslog  = "syslog"  // the audit events are sent to the syslog server of AUDIT_EXPORT_TARGET
+)
+
+const (
+	RunContextSecretsRedacted = "redacted" // the names of the secrets are stored in the contexts of runs with redacted values
+	RunContextSecretsNone     = "none"     // nothing about the secrets is stored in the contexts of runs
+)
+
+const (
+	WorkflowDirsPrecedenceGitea  = "gitea"  // the workflows in .gitea/workflows win over the same-named ones in .github/workflows
+	WorkflowDirsPrecedenceGithub = "github" // the workflows in .github/workflows win over the same-named ones in .gitea/workflows
+	WorkflowDirsPrecedenceBoth   = "both"   // the same-named workflows in both directories run
+)
+
+const (
+	PullRequestPathsDiffThreeDot = "three-dot" // the head of a pull request is compared with the merge base of the base and the head, also known as "merge-base"
+	PullRequestPathsDiffTwoDot   = "two-dot"   // the head of a pull request is compared with the tip of the base branch, also known as "base-tip"
+)
+
+const (
+	RunEventPublisherNone    = ""        // the events of runs are not published
+	RunEventPublisherWebhook = "webhook" // the events of runs are posted to the URL of RUN_EVENT_TARGET
+)
+
+const (
+	DeployGuardBlockOnInProgress = "block-on-in-progress" // the new deploy runs fail if the previous deploy run of the workflow is still in progress
+	DeployGuardQueueOnInProgress = "queue-on-in-progress" // the new deploy runs wait until the previous deploy run of the workflow is done
+	DeployGuardBlockOnFailure    = "block-on-failure"     // the new deploy runs fail if the previous deploy run of the workflow failed
+)
+
+const (
+	ScheduleRequirementProtectedBranch = "protected-branch" // the default branch of the repository is protected
+	ScheduleRequirementRecentActivity  = "recent-activity"  // the repository has been updated within SCHEDULE_ACTIVITY_WINDOW
+)
+
 type defaultActionsURL string
 
 func (url defaultActionsURL) URL() string {
@@ -73,16 +230,155 @@ func loadActionsFrom(rootCfg ConfigProvider) error {
 		}
 	}
 
+	switch Actions.ApprovalScope {
+	case ApprovalScopeRepo, ApprovalScopeOwner, ApprovalScopeInstance:
+	default:
+		return fmt.Errorf("unsupported [actions] APPROVAL_SCOPE: %q", Actions.ApprovalScope)
+	}
+	switch Actions.DefaultTokenPermissions {
+	case TokenPermissionsRead, TokenPermissionsWrite:
+	default:
+		return fmt.Errorf("unsupported [actions] DEFAULT_TOKEN_PERMISSIONS: %q", Actions.DefaultTokenPermissions)
+	}
+	switch Actions.MinutesQuotaPeriod {
+	case MinutesQuotaPeriodDay, MinutesQuotaPeriodWeek, MinutesQuotaPeriodMonth:
+	default:
+		return fmt.Errorf("unsupported [actions] MINUTES_QUOTA_PERIOD: %q", Actions.MinutesQuotaPeriod)
+	}
+	switch Actions.MinutesQuotaPolicy {
+	case MinutesQuotaPolicyQueue, MinutesQuotaPolicyFail:
+	default:
+		return fmt.Errorf("unsupported [actions] MINUTES_QUOTA_POLICY: %q", Actions.MinutesQuotaPolicy)
+	}
+	switch Actions.MissingCommitPolicy {
+	case MissingCommitPolicySkip, MissingCommitPolicyNotice, MissingCommitPolicyError:
+	default:
+		return fmt.Errorf("unsupported [actions] MISSING_COMMIT_POLICY: %q", Actions.MissingCommitPolicy)
+	}
+	switch Actions.AuditExporter {
+	case AuditExporterNone:
+	case AuditExporterWebhook, AuditExporterFile, AuditExporterSyslog:
+		if Actions.AuditExportTarget == "" {
+			return fmt.Errorf("[actions] AUDIT_EXPORT_TARGET is required by AUDIT_EXPORTER %q", Actions.AuditExporter)
+		}
+	default:
+		return fmt.Errorf("unsupported [actions] AUDIT_EXPORTER: %q", Actions.AuditExporter)
+	}
+	switch Actions.RunContextSecrets {
+	case RunContextSecretsRedacted, RunContextSecretsNone:
+	default:
+		return fmt.Errorf("unsupported [actions] RUN_CONTEXT_SECRETS: %q", Actions.RunContextSecrets)
+	}
+	switch Actions.WorkflowDirsPrecedence {
+	case WorkflowDirsPrecedenceGitea, WorkflowDirsPrecedenceGithub, WorkflowDirsPrecedenceBoth:
+	default:
+		return fmt.Errorf("unsupported [actions] WORKFLOW_DIRS_PRECEDENCE: %q", Actions.WorkflowDirsPrecedence)
+	}
+	switch Actions.PullRequestPathsDiff {
+	case PullRequestPathsDiffThreeDot, PullRequestPathsDiffTwoDot:
+	case "merge-base":
+		Actions.PullRequestPathsDiff = PullRequestPathsDiffThreeDot
+	case "base-tip":
+		Actions.PullRequestPathsDiff = PullRequestPathsDiffTwoDot
+	default:
+		return fmt.Errorf("unsupported [actions] PULL_REQUEST_PATHS_DIFF: %q", Actions.PullRequestPathsDiff)
+	}
+	switch Actions.RunEventPublisher {
+	case RunEventPublisherNone:
+	case RunEventPublisherWebhook:
+		if Actions.RunEventTarget == "" {
+			return fmt.Errorf("[actions] RUN_EVENT_TARGET is required by RUN_EVENT_PUBLISHER %q", Actions.RunEventPublisher)
+		}
+	default:
+		return fmt.Errorf("unsupported [actions] RUN_EVENT_PUBLISHER: %q", Actions.RunEventPublisher)
+	}
+	if Actions.TracingEndpoint != "" {
+		if u, err := url.Parse(Actions.TracingEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid [actions] TRACING_ENDPOINT: %q", Actions.TracingEndpoint)
+		}
+	}
+
+	for _, guard := range Actions.DeployGuard {
+		switch guard {
+		case DeployGuardBlockOnInProgress, DeployGuardQueueOnInProgress, DeployGuardBlockOnFailure:
+		default:
+			return fmt.Errorf("unsupported [actions] DEPLOY_GUARD: %q", guard)
+		}
+	}
+	if slices.Contains(Actions.DeployGuard, DeployGuardBlockOnInProgress) && slices.Contains(Actions.DeployGuard, DeployGuardQueueOnInProgress) {
+		return fmt.Errorf("[actions] DEPLOY_GUARD can't contain both %q and %q", DeployGuardBlockOnInProgress, DeployGuardQueueOnInProgress)
+	}
+
+	for _, requirement := range Actions.ScheduleRequirements {
+		switch requirement {
+		case ScheduleRequirementProtectedBranch, ScheduleRequirementRecentActivity:
+		default:
+			return fmt.Errorf("unsupported [actions] SCHEDULE_REQUIREMENTS: %q", requirement)
+		}
+	}
+	if Actions.ScheduleActivityWindow <= 0 {
+		Actions.ScheduleActivityWindow = 90 * 24 * time.Hour
+	}
+	// read it explicitly since zero disables it, while MapTo ignores the durations which aren't positive
+	Actions.ScheduleJitter = sec.Key("SCHEDULE_JITTER").MustDuration(5 * time.Minute)
+	if Actions.ScheduleJitter < 0 {
+		return fmt.Errorf("[actions] SCHEDULE_JITTER should not be negative: %v", Actions.ScheduleJitter)
+	}
+
+	Actions.ForbiddenCommands = nil
+	for _, key := range rootCfg.Section("actions.forbidden_commands").Keys() {
+		pattern, err := regexp.Compile(key.String())
+		if err != nil {
+			return fmt.Errorf("invalid [actions.forbidden_commands] %s: %w", key.Name(), err)
+		}
+		Actions.ForbiddenCommands = append(Actions.ForbiddenCommands, &ForbiddenCommand{Name: key.Name(), Pattern: pattern})
+	}
+
 	// don't support to read configuration from [actions]
 	Actions.LogStorage, err = getStorage(rootCfg, "actions_log", "", nil)
 	if err != nil {
 		return err
 	}
 
+	if Actions.LogArchive {
+		Actions.LogArchiveStorage, err = getStorage(rootCfg, "actions_log_archive", "", nil)
+		if err != nil {
+			return err
+		}
+	}
+	if Actions.LogArchivePruneAfter < 0 {
+		Actions.LogArchivePruneAfter = 0
+	}
+
 	actionsSec, _ := rootCfg.GetSection("actions.artifacts")
 
 	Actions.ArtifactStorage, err = getStorage(rootCfg, "actions_artifacts", "", actionsSec)
 
+	if Actions.WorkflowParallelism < 1 {
+		Actions.WorkflowParallelism = 1
+	}
+	if Actions.AuditExportBuffer < 1 {
+		Actions.AuditExportBuffer = 1000
+	}
+	if Actions.RunEventBuffer < 1 {
+		Actions.RunEventBuffer = 1000
+	}
+	if Actions.PathsFilterMaxFiles < 1 {
+		Actions.PathsFilterMaxFiles = 3000
+	}
+	if Actions.DeployGuardTimeout <= 0 {
+		Actions.DeployGuardTimeout = 3 * time.Hour
+	}
+	if Actions.ReusableWorkflowCallers < 0 {
+		Actions.ReusableWorkflowCallers = 0
+	}
+	if Actions.ForbiddenCommandsLimit <= 0 {
+		Actions.ForbiddenCommandsLimit = 1024 * 1024
+	}
+	if Actions.RunContextMaxSize < 0 {
+		Actions.RunContextMaxSize = 256 * 1024
+	}
+
 	// default to 90 days in Github Actions
 	if Actions.ArtifactRetentionDays <= 0 {
 		Actions.ArtifactRetentionDays = 90
@@ -6,6 +6,7 @@ package setting
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -179,3 +180,106 @@ DEFAULT_ACTIONS_URL = gitea
 		})
 	}
 }
+
+func Test_loadActionsForbiddenCommands(t *testing.T) {
+	oldActions := Actions
+	defer func() {
+		Actions = oldActions
+	}()
+
+	cfg, err := NewConfigProviderFromData(`
+[actions.forbidden_commands]
+curl-pipe-shell = curl[^|]*\|\s*(ba)?sh\b
+rm-root = rm\s+-rf\s+/(\s|$)
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	require.Len(t, Actions.ForbiddenCommands, 2)
+	assert.Equal(t, "curl-pipe-shell", Actions.ForbiddenCommands[0].Name)
+	assert.True(t, Actions.ForbiddenCommands[0].Pattern.MatchString("curl -sL https://example.com/install.sh | bash"))
+	assert.False(t, Actions.ForbiddenCommands[0].Pattern.MatchString("curl -sLO https://example.com/install.sh"))
+	assert.Equal(t, "rm-root", Actions.ForbiddenCommands[1].Name)
+	assert.EqualValues(t, 1024*1024, Actions.ForbiddenCommandsLimit)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions.forbidden_commands]
+invalid = curl(
+`)
+	require.NoError(t, err)
+	assert.Error(t, loadActionsFrom(cfg))
+}
+
+func Test_loadActionsScheduleRequirements(t *testing.T) {
+	oldActions := Actions
+	defer func() {
+		Actions = oldActions
+	}()
+
+	cfg, err := NewConfigProviderFromData(`
+[actions]
+SCHEDULE_REQUIREMENTS = protected-branch, recent-activity
+SCHEDULE_ACTIVITY_WINDOW = 720h
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.Equal(t, []string{ScheduleRequirementProtectedBranch, ScheduleRequirementRecentActivity}, Actions.ScheduleRequirements)
+	assert.Equal(t, 720*time.Hour, Actions.ScheduleActivityWindow)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions]
+SCHEDULE_REQUIREMENTS = stars
+`)
+	require.NoError(t, err)
+	assert.ErrorContains(t, loadActionsFrom(cfg), `unsupported [actions] SCHEDULE_REQUIREMENTS: "stars"`)
+}
+
+func Test_loadActionsScheduleJitter(t *testing.T) {
+	oldActions := Actions
+	defer func() {
+		Actions = oldActions
+	}()
+
+	cfg, err := NewConfigProviderFromData(`
+[actions]
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.Equal(t, 5*time.Minute, Actions.ScheduleJitter)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions]
+SCHEDULE_JITTER = 0
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.Zero(t, Actions.ScheduleJitter)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions]
+SCHEDULE_JITTER = -1m
+`)
+	require.NoError(t, err)
+	assert.ErrorContains(t, loadActionsFrom(cfg), "SCHEDULE_JITTER should not be negative")
+}
+
+func Test_loadActionsTracingEndpoint(t *testing.T) {
+	oldActions := Actions
+	defer func() {
+		Actions = oldActions
+	}()
+
+	cfg, err := NewConfigProviderFromData(`
+[actions]
+TRACING_ENDPOINT = http://localhost:4318
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.Equal(t, "http://localhost:4318", Actions.TracingEndpoint)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions]
+TRACING_ENDPOINT = localhost:4318
+`)
+	require.NoError(t, err)
+	assert.ErrorContains(t, loadActionsFrom(cfg), `invalid [actions] TRACING_ENDPOINT: "localhost:4318"`)
+}
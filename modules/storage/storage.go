@@ -129,6 +129,8 @@ var (
 	Actions ObjectStorage = uninitializedStorage
 	// Actions Artifacts represents actions artifacts storage
 	ActionsArtifacts ObjectStorage = uninitializedStorage
+	// ActionsLogArchive represents the storage which the logs of the completed runs are archived to
+	ActionsLogArchive ObjectStorage = uninitializedStorage
 )
 
 // Init init the stoarge
@@ -214,8 +216,17 @@ func initActions() (err error) {
 	if !setting.Actions.Enabled {
 		Actions = discardStorage("Actions isn't enabled")
 		ActionsArtifacts = discardStorage("ActionsArtifacts isn't enabled")
+		ActionsLogArchive = discardStorage("ActionsLogArchive isn't enabled")
 		return nil
 	}
+	if setting.Actions.LogArchive {
+		log.Info("Initialising ActionsLogArchive storage with type: %s", setting.Actions.LogArchiveStorage.Type)
+		if ActionsLogArchive, err = NewStorage(setting.Actions.LogArchiveStorage.Type, setting.Actions.LogArchiveStorage); err != nil {
+			return err
+		}
+	} else {
+		ActionsLogArchive = discardStorage("ActionsLogArchive isn't enabled")
+	}
 	log.Info("Initialising Actions storage with type: %s", setting.Actions.LogStorage.Type)
 	if Actions, err = NewStorage(setting.Actions.LogStorage.Type, setting.Actions.LogStorage); err != nil {
 		return err
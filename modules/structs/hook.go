@@ -130,6 +130,10 @@ type CreatePayload struct {
 	RefType string      `json:"ref_type"`
 	Repo    *Repository `json:"repository"`
 	Sender  *User       `json:"sender"`
+	// Message is the message of the annotated tag, it's omitted for branches and lightweight tags
+	Message string `json:"message,omitempty"`
+	// Tagger is the tagger of the annotated tag, it's omitted for branches and lightweight tags
+	Tagger *PayloadUser `json:"tagger,omitempty"`
 }
 
 // JSONPayload return payload information
@@ -277,9 +281,13 @@ type PushPayload struct {
 	Commits      []*PayloadCommit `json:"commits"`
 	TotalCommits int              `json:"total_commits"`
 	HeadCommit   *PayloadCommit   `json:"head_commit"`
-	Repo         *Repository      `json:"repository"`
-	Pusher       *User            `json:"pusher"`
-	Sender       *User            `json:"sender"`
+	// TagMessage is the message of the pushed annotated tag, it's only provided for Actions
+	TagMessage string `json:"tag_message,omitempty"`
+	// MirrorSync is true if the commits are synced from the upstream of a pull mirror rather than pushed, it's only provided for Actions
+	MirrorSync bool        `json:"mirror_sync,omitempty"`
+	Repo       *Repository `json:"repository"`
+	Pusher     *User       `json:"pusher"`
+	Sender     *User       `json:"sender"`
 }
 
 // JSONPayload FIXME
@@ -494,3 +502,303 @@ type PackagePayload struct {
 func (p *PackagePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
+
+// HookMergeGroupAction an action that happens to a merge group
+type HookMergeGroupAction string
+
+const (
+	// HookMergeGroupChecksRequested checks requested
+	HookMergeGroupChecksRequested HookMergeGroupAction = "checks_requested"
+	// HookMergeGroupDestroyed destroyed
+	HookMergeGroupDestroyed HookMergeGroupAction = "destroyed"
+)
+
+// MergeGroup represents the temporary combination of pull requests waiting in a merge queue
+type MergeGroup struct {
+	// HeadSHA is the commit at the tip of the temporary merge group ref
+	HeadSHA string `json:"head_sha"`
+	// HeadRef is the full name of the temporary merge group ref
+	HeadRef string `json:"head_ref"`
+	// BaseSHA is the commit of the target branch the group was built on
+	BaseSHA string `json:"base_sha"`
+	// BaseRef is the full name of the target branch
+	BaseRef     string       `json:"base_ref"`
+	PullRequest *PullRequest `json:"pull_request"`
+}
+
+// MergeGroupPayload represents a payload information of merge group event.
+type MergeGroupPayload struct {
+	Action     HookMergeGroupAction `json:"action"`
+	MergeGroup *MergeGroup          `json:"merge_group"`
+	Repository *Repository          `json:"repository"`
+	Sender     *User                `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *MergeGroupPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookWatchAction an action that happens to a watch
+type HookWatchAction string
+
+const (
+	// HookWatchStarted started, the repository is starred
+	HookWatchStarted HookWatchAction = "started"
+)
+
+// WatchPayload represents a payload information of watch event, which is about starring a repository like GitHub.
+type WatchPayload struct {
+	Action     HookWatchAction `json:"action"`
+	Repository *Repository     `json:"repository"`
+	Sender     *User           `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *WatchPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookLabelAction an action that happens to a label
+type HookLabelAction string
+
+const (
+	// HookLabelCreated created
+	HookLabelCreated HookLabelAction = "created"
+	// HookLabelEdited edited
+	HookLabelEdited HookLabelAction = "edited"
+	// HookLabelDeleted deleted
+	HookLabelDeleted HookLabelAction = "deleted"
+)
+
+// LabelPayload represents a payload information of label event, which is about the labels of a repository rather than the labels of issues
+type LabelPayload struct {
+	Action     HookLabelAction `json:"action"`
+	Label      *Label          `json:"label"`
+	Repository *Repository     `json:"repository"`
+	Sender     *User           `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *LabelPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookMilestoneAction an action that happens to a milestone
+type HookMilestoneAction string
+
+const (
+	// HookMilestoneCreated created
+	HookMilestoneCreated HookMilestoneAction = "created"
+	// HookMilestoneOpened opened, the milestone has been reopened
+	HookMilestoneOpened HookMilestoneAction = "opened"
+	// HookMilestoneClosed closed
+	HookMilestoneClosed HookMilestoneAction = "closed"
+)
+
+// MilestonePayload represents a payload information of milestone event
+type MilestonePayload struct {
+	Action     HookMilestoneAction `json:"action"`
+	Milestone  *Milestone          `json:"milestone"`
+	Repository *Repository         `json:"repository"`
+	Sender     *User               `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *MilestonePayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// PageBuildStatus the status of a build of the pages
+type PageBuildStatus string
+
+const (
+	// PageBuildStatusBuilding the pages are being built
+	PageBuildStatusBuilding PageBuildStatus = "building"
+	// PageBuildStatusBuilt the pages have been built and published
+	PageBuildStatusBuilt PageBuildStatus = "built"
+	// PageBuildStatusErrored the pages failed to build
+	PageBuildStatusErrored PageBuildStatus = "errored"
+)
+
+// PageBuildError represents the error of a failed build of the pages
+type PageBuildError struct {
+	Message string `json:"message"`
+}
+
+// PageBuild represents a build of the pages of a repository
+type PageBuild struct {
+	URL    string          `json:"url"`
+	Status PageBuildStatus `json:"status"`
+	Error  PageBuildError  `json:"error"`
+	Pusher *User           `json:"pusher"`
+	Commit string          `json:"commit"`
+	// Duration of the build in milliseconds
+	Duration int64 `json:"duration"`
+	// Ref is the branch which the pages are built from, it's specific to Gitea
+	Ref string `json:"ref,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// PageBuildPayload represents a payload information of page_build event, which is close to GitHub's
+type PageBuildPayload struct {
+	ID         int64       `json:"id"`
+	Build      *PageBuild  `json:"build"`
+	Repository *Repository `json:"repository"`
+	Sender     *User       `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *PageBuildPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookWorkflowRunAction an action that happens to a workflow run
+type HookWorkflowRunAction string
+
+const (
+	// HookWorkflowRunCompleted completed
+	HookWorkflowRunCompleted HookWorkflowRunAction = "completed"
+)
+
+// ActionWorkflowRun represents a run of an Actions workflow
+type ActionWorkflowRun struct {
+	ID        int64 `json:"id"`
+	RunNumber int64 `json:"run_number"`
+	// Name is the name of the workflow
+	Name string `json:"name"`
+	// Path is the file name of the workflow, like `ci.yaml`
+	Path       string `json:"path"`
+	Event      string `json:"event"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HeadBranch string `json:"head_branch"`
+	HeadSha    string `json:"head_sha"`
+	HTMLURL    string `json:"html_url"`
+	// Production is whether the run deploys to production
+	Production bool `json:"production"`
+}
+
+// WorkflowRunPayload represents a payload information of workflow run event.
+type WorkflowRunPayload struct {
+	Action      HookWorkflowRunAction `json:"action"`
+	WorkflowRun *ActionWorkflowRun    `json:"workflow_run"`
+	Repository  *Repository           `json:"repository"`
+	Sender      *User                 `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *WorkflowRunPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// RepositoryDispatchPayload represents a payload information of repository dispatch event.
+type RepositoryDispatchPayload struct {
+	// Action is the custom `event_type` of the dispatch
+	Action        string         `json:"action"`
+	Branch        string         `json:"branch"`
+	ClientPayload map[string]any `json:"client_payload"`
+	Repository    *Repository    `json:"repository"`
+	Sender        *User          `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *RepositoryDispatchPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookOrganizationAction an action that happens to the members of an organization
+type HookOrganizationAction string
+
+const (
+	// HookOrganizationMemberAdded the user has become a member of the organization
+	HookOrganizationMemberAdded HookOrganizationAction = "member_added"
+	// HookOrganizationMemberRemoved the user is no longer a member of the organization
+	HookOrganizationMemberRemoved HookOrganizationAction = "member_removed"
+)
+
+// OrganizationMembership represents the membership of a user in an organization
+type OrganizationMembership struct {
+	User *User `json:"user"`
+}
+
+// OrganizationPayload represents a payload information of organization event.
+// The organization has no repository, the repository is the one hosting the workflows of the organization events.
+type OrganizationPayload struct {
+	Action       HookOrganizationAction  `json:"action"`
+	Membership   *OrganizationMembership `json:"membership"`
+	Organization *Organization           `json:"organization"`
+	Repository   *Repository             `json:"repository"`
+	Sender       *User                   `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *OrganizationPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookMembershipAction an action that happens to the members of a team
+type HookMembershipAction string
+
+const (
+	// HookMembershipAdded the user is added to the team
+	HookMembershipAdded HookMembershipAction = "added"
+	// HookMembershipRemoved the user is removed from the team
+	HookMembershipRemoved HookMembershipAction = "removed"
+)
+
+// MembershipPayload represents a payload information of membership event, which is about the members of the teams of an organization.
+// The repository is the one hosting the workflows of the organization events, see OrganizationPayload.
+type MembershipPayload struct {
+	Action HookMembershipAction `json:"action"`
+	// Scope is always `team` like GitHub
+	Scope        string        `json:"scope"`
+	Member       *User         `json:"member"`
+	Team         *Team         `json:"team"`
+	Organization *Organization `json:"organization"`
+	Repository   *Repository   `json:"repository"`
+	Sender       *User         `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *MembershipPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookActionsScheduleAction an action that happens to the schedules of the Actions workflows of a repository
+type HookActionsScheduleAction string
+
+const (
+	// HookActionsScheduleRegistered the schedules are registered, they replace all previous schedules of the repository
+	HookActionsScheduleRegistered HookActionsScheduleAction = "registered"
+	// HookActionsScheduleCleaned the schedules are cleaned, the repository has no schedules any longer
+	HookActionsScheduleCleaned HookActionsScheduleAction = "cleaned"
+)
+
+// ActionsSchedule represents the schedule of an Actions workflow
+type ActionsSchedule struct {
+	// Workflow is the file name of the workflow, like `nightly.yaml`
+	Workflow string `json:"workflow"`
+	// Dir is the directory of the workflow file, like `.gitea/workflows`
+	Dir string `json:"dir"`
+	// Specs are the cron specs of the `schedule` event of the workflow
+	Specs    []string `json:"specs"`
+	Disabled bool     `json:"disabled"`
+}
+
+// ActionsSchedulePayload represents a payload information of actions schedule event.
+// All schedules of the repository which are registered or cleaned at once are carried by a single payload.
+type ActionsSchedulePayload struct {
+	Action     HookActionsScheduleAction `json:"action"`
+	Schedules  []*ActionsSchedule        `json:"schedules"`
+	Repository *Repository               `json:"repository"`
+	Sender     *User                     `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *ActionsSchedulePayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import "time"
+
+// ActionRun represents a run of a workflow
+// swagger:model
+type ActionRun struct {
+	ID int64 `json:"id"`
+	// The number of the run in the repository
+	RunNumber int64  `json:"run_number"`
+	Title     string `json:"title"`
+	// The name of the workflow file
+	WorkflowID string `json:"workflow_id"`
+	// The directory of the workflow file, like `.gitea/workflows`, empty for the runs created before it's recorded
+	WorkflowDir string `json:"workflow_dir"`
+	// The git blob SHA of the workflow file which the run executed.
+	// It identifies the version of the file even across renames,
+	// for `pull_request_target` it's the file of the base branch.
+	WorkflowBlobSHA string `json:"workflow_blob_sha"`
+	// The webhook event which triggered the run
+	Event string `json:"event"`
+	// The event in `on` of the workflow which matched
+	TriggerEvent string `json:"trigger_event"`
+	// How the trigger event matched, absent for the runs created before it's recorded
+	TriggerMatch *ActionRunTriggerMatch `json:"trigger_match,omitempty"`
+	Status       string                 `json:"status"`
+	Ref          string                 `json:"ref"`
+	CommitSHA    string                 `json:"commit_sha"`
+	// The head commit of the pull request or the merge group, empty for other events
+	HeadSHA string `json:"head_sha"`
+	// The base commit of the pull request or the merge group, empty for other events
+	BaseSHA string `json:"base_sha"`
+	HTMLURL string `json:"html_url"`
+	// Whether the run deploys to production, by a job environment or a `production: true` annotation
+	Production bool `json:"production"`
+	// Whether the run was triggered by creating or pushing a protected tag, such a run may need approval
+	ProtectedTag bool `json:"protected_tag"`
+	// The ID of the upstream webhook delivery which the trigger event originates from,
+	// empty for the events originated internally
+	DeliveryID string `json:"delivery_id"`
+	// The estimated duration of the run in seconds, it's the median duration of the latest successful runs of the workflow,
+	// zero if there is no history
+	EstimatedDuration int64 `json:"estimated_duration"`
+	// How many times the run has been rerun automatically since its jobs failed by the infrastructure
+	InfraRetries int64 `json:"infra_retries"`
+	// swagger:strfmt date-time
+	Started time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Stopped time.Time `json:"stopped_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// ActionRunJob represents a job of a run, the legs of a matrix job are separate jobs
+type ActionRunJob struct {
+	ID int64 `json:"id"`
+	// The id of the job in the workflow
+	JobID  string `json:"job_id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// The labels which the runners should match to run the job
+	RunsOn []string `json:"runs_on"`
+	// When the job became ready to run, after its needs were done and the run was approved, it's zero while the job is blocked
+	// swagger:strfmt date-time
+	Queued time.Time `json:"queued_at"`
+	// When a runner picked the job
+	// swagger:strfmt date-time
+	Started time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Stopped time.Time `json:"stopped_at"`
+	// How long in seconds the job waited for a runner after it became ready to run
+	QueueDuration int64 `json:"queue_duration"`
+	// How long in seconds the job has run on the runner
+	Duration int64 `json:"duration"`
+	// Why the job failed, `infra` if it failed by the infrastructure, like a dead runner,
+	// `logic` if it failed by the workflow, empty if the job didn't fail or the cause is unknown
+	FailureCause string `json:"failure_cause"`
+	// The resolved `url` of the `environment` of the job, the link to what it deploys.
+	// It's empty until the url is resolved, the url referencing `steps` or `needs` is resolved after the job is done
+	EnvironmentURL string `json:"environment_url"`
+}
+
+// ActionQueueStats is the aggregate queue wait time of the jobs which requested the same runner labels
+type ActionQueueStats struct {
+	// The labels which the jobs requested, which are sorted
+	RunsOn []string `json:"runs_on"`
+	// The number of the jobs which were picked by runners
+	Jobs int64 `json:"jobs"`
+	// The average time in seconds the jobs waited for runners
+	AverageWait int64 `json:"average_wait"`
+	// The max time in seconds a job waited for a runner
+	MaxWait int64 `json:"max_wait"`
+}
+
+// ActionRunTriggerMatch is how the trigger event of a run matched, it tells why the run was created
+type ActionRunTriggerMatch struct {
+	// The event in `on` of the workflow
+	Event string `json:"event"`
+	// The activity type of the event, like `opened` of `pull_request`
+	ActivityType string `json:"activity_type,omitempty"`
+	// The branch or the tag which is matched by the `branches` or the `tags` filters
+	Ref string `json:"ref,omitempty"`
+	// A changed file which is matched by the `paths` or the `paths-ignore` filters
+	Path string `json:"path,omitempty"`
+	// The cron spec which fired, absent if the schedule was run manually
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// ActionRunDetectionTrace explains why a run was created, it never contains the event payload
+// swagger:model
+type ActionRunDetectionTrace struct {
+	// The path of the workflow, like `.gitea/workflows/test.yml`
+	Workflow string `json:"workflow"`
+	// The webhook event which triggered the run
+	Event string `json:"event"`
+	// How the trigger event matched the event
+	Match *ActionRunTriggerMatch `json:"match,omitempty"`
+	// Whether the run has the access of the repository, false for the runs of the pull requests from forks
+	Trusted bool `json:"trusted"`
+	// Whether the run needed approval when it was created
+	NeedApproval bool `json:"need_approval"`
+	// The checks which decided the approval, `fork_pull_request`, `approval_label`, `production` or `protected_tag`
+	Approval []string `json:"approval,omitempty"`
+	// The reason of the policy webhook which allowed the run
+	PolicyReason string `json:"policy_reason,omitempty"`
+}
+
+// ActionRunContext is the snapshot of the contexts which the workflow of a run saw when the run was created
+// swagger:model
+type ActionRunContext struct {
+	// The `github`, `inputs`, `vars`, `env` and `secrets` contexts, the values of secrets are never included
+	Context map[string]any `json:"context"`
+	// Whether some contexts have been dropped since they were too large
+	Truncated bool `json:"truncated"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// ActionSchedule represents a schedule of a workflow
+// swagger:model
+type ActionSchedule struct {
+	ID int64 `json:"id"`
+	// The name of the workflow file
+	WorkflowID string `json:"workflow_id"`
+	// The cron specs of the schedule event
+	Specs     []string `json:"specs"`
+	Ref       string   `json:"ref"`
+	CommitSHA string   `json:"commit_sha"`
+	// Whether the scheduler creates runs for the schedule
+	Enabled bool `json:"enabled"`
+	// Whether all schedules of the workflow have been paused, a paused schedule is never enabled
+	Paused bool `json:"paused"`
+	// swagger:strfmt date-time
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+	// When a spec fired last without a run since the `schedule-if` of the workflow was false
+	// swagger:strfmt date-time
+	SkippedAt *time.Time `json:"skipped_at,omitempty"`
+	// The condition of `schedule-if` which was false when the schedule was skipped last
+	SkipReason string `json:"skip_reason,omitempty"`
+	// Whether the schedule is deferred since the repository doesn't meet the schedule requirements of the site,
+	// a deferred schedule doesn't fire until the repository meets them
+	Deferred bool `json:"deferred"`
+	// The next time any of the specs fires, it's still updated when the schedule is disabled
+	// swagger:strfmt date-time
+	NextRun *time.Time `json:"next_run_at"`
+}
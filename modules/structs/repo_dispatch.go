@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+// CreateRepositoryDispatchOption options when creating a repository dispatch event
+// swagger:model
+type CreateRepositoryDispatchOption struct {
+	// A custom event type, the workflows can filter it with `on.repository_dispatch.types`
+	//
+	// required: true
+	EventType string `json:"event_type" binding:"Required;MaxSize(100)"`
+	// Extra information about the event, which is available as `github.event.client_payload` in workflows.
+	// It can have up to 10 top-level properties.
+	ClientPayload map[string]any `json:"client_payload"`
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import "time"
+
+// LintWorkflowOption options when validating a workflow
+// swagger:model
+type LintWorkflowOption struct {
+	// The YAML content of the workflow
+	//
+	// required: true
+	Content string `json:"content" binding:"Required"`
+}
+
+// WorkflowLintResult represents the diagnostics of a workflow which has been validated without running it
+// swagger:model
+type WorkflowLintResult struct {
+	// Whether the workflow has no errors
+	Valid bool `json:"valid"`
+	// The problems which make the workflow fail to be parsed or run
+	Errors []string `json:"errors"`
+	// The problems which don't block the workflow, like deprecated syntax
+	Warnings []string `json:"warnings"`
+	// The events in `on` which the workflow will be triggered by
+	Triggers []*WorkflowLintTrigger `json:"triggers"`
+	// The jobs which will be created for a run, every leg of a matrix job is a job
+	Jobs []*WorkflowLintJob `json:"jobs"`
+}
+
+// WorkflowLintTrigger represents an event in `on` of a workflow
+type WorkflowLintTrigger struct {
+	Event string `json:"event"`
+	// The filters of the event, like `branches` or `types`
+	Filters map[string][]string `json:"filters"`
+	// The cron specs of the schedule event
+	Schedules []string `json:"schedules"`
+}
+
+// WorkflowLintJob represents a job of a workflow
+type WorkflowLintJob struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Needs  []string `json:"needs"`
+	RunsOn []string `json:"runs_on"`
+}
+
+// WorkflowTriggerMap represents what can trigger the workflows of the default branch of a repository
+// swagger:model
+type WorkflowTriggerMap struct {
+	// The commit of the default branch which the workflows are read from, empty if the repository is empty
+	CommitSHA string `json:"commit_sha"`
+	// The events in `on` of the workflows with the workflows which respond to them
+	Events []*WorkflowEventTriggers `json:"events"`
+	// The cron specs of the schedule events of the workflows
+	Schedules []*WorkflowScheduleTrigger `json:"schedules"`
+	// The workflows which have been disabled, they are excluded from the events and the schedules
+	DisabledWorkflows []string `json:"disabled_workflows"`
+	// The problems of the workflows which fail to be parsed, the other workflows are still in the map
+	Errors []*WorkflowTriggerError `json:"errors"`
+}
+
+// WorkflowEventTriggers represents the workflows which respond to an event
+type WorkflowEventTriggers struct {
+	Event string `json:"event"`
+	// Whether Gitea triggers workflows by the event, it's false for the unsupported events and the events disabled by the administrator
+	Supported bool                     `json:"supported"`
+	Workflows []*WorkflowEventWorkflow `json:"workflows"`
+}
+
+// WorkflowEventWorkflow represents a workflow which responds to an event
+type WorkflowEventWorkflow struct {
+	// The name of the workflow file
+	WorkflowID string `json:"workflow_id"`
+	// The directory of the workflow file, like `.gitea/workflows`
+	WorkflowDir string `json:"workflow_dir"`
+	// The activity types and the filters of the event, like `types`, `branches` or `paths`
+	Filters map[string][]string `json:"filters"`
+}
+
+// WorkflowScheduleTrigger represents a cron spec of the schedule event of a workflow
+type WorkflowScheduleTrigger struct {
+	WorkflowID  string `json:"workflow_id"`
+	WorkflowDir string `json:"workflow_dir"`
+	Spec        string `json:"spec"`
+	// The next time the spec fires, it's absent if the spec is invalid
+	// swagger:strfmt date-time
+	NextRun *time.Time `json:"next_run_at,omitempty"`
+}
+
+// WorkflowTriggerError represents the problem of a workflow which fails to be parsed
+type WorkflowTriggerError struct {
+	WorkflowID  string `json:"workflow_id"`
+	WorkflowDir string `json:"workflow_dir"`
+	Error       string `json:"error"`
+}
+
+// SetWorkflowsSourceOption options when setting the workflows source of a repository
+// swagger:model
+type SetWorkflowsSourceOption struct {
+	// The repository whose workflows are detected as if they were in the repository, like `org/ci-config@main`,
+	// the default branch of it is read if the ref is omitted
+	//
+	// required: true
+	Source string `json:"source" binding:"Required"`
+	// Which one wins when the repository and the workflows source have same-named workflows, empty for the repository or `source`
+	Precedence string `json:"precedence"`
+	// Whether a push changing the workflows of the source sends a `workflows-source-changed` event to the repository
+	Notify bool `json:"notify"`
+}
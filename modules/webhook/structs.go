@@ -26,6 +26,7 @@ type HookEvents struct {
 	Repository               bool `json:"repository"`
 	Release                  bool `json:"release"`
 	Package                  bool `json:"package"`
+	ActionsSchedule          bool `json:"actions_schedule"`
 }
 
 // HookEvent represents events that will delivery hook.
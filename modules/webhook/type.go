@@ -32,6 +32,16 @@ const (
 	HookEventRelease                   HookEventType = "release"
 	HookEventPackage                   HookEventType = "package"
 	HookEventSchedule                  HookEventType = "schedule"
+	HookEventMergeGroup                HookEventType = "merge_group"
+	HookEventWorkflowRun               HookEventType = "workflow_run"
+	HookEventWatch                     HookEventType = "watch"
+	HookEventPageBuild                 HookEventType = "page_build"
+	HookEventLabel                     HookEventType = "label"
+	HookEventMilestone                 HookEventType = "milestone"
+	HookEventRepositoryDispatch        HookEventType = "repository_dispatch"
+	HookEventOrganization              HookEventType = "organization"     // the members of an organization are added or removed
+	HookEventMembership                HookEventType = "membership"       // the members of a team of an organization are added or removed
+	HookEventActionsSchedule           HookEventType = "actions_schedule" // the schedules of the Actions workflows of a repository are registered or cleaned
 )
 
 // Event returns the HookEventType as an event string
@@ -63,6 +63,7 @@ package actions
 
 import (
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -70,6 +71,7 @@ import (
 
 	"code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -78,6 +80,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	web_types "code.gitea.io/gitea/modules/web/types"
+	actions_service "code.gitea.io/gitea/services/actions"
 )
 
 const artifactRouteBase = "/_apis/pipelines/workflows/{run_id}/artifacts"
@@ -247,6 +250,25 @@ func (ar artifactRoutes) uploadArtifact(ctx *ArtifactContext) {
 		return
 	}
 
+	// evict the oldest artifacts of the repository if the artifact would exceed the artifact quota
+	repo, err := repo_model.GetRepositoryByID(ctx, task.RepoID)
+	if err != nil {
+		log.Error("Error get repository: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error get repository")
+		return
+	}
+	if err := actions_service.EvictArtifacts(ctx, repo, fileRealTotalSize, artifact.ID); err != nil {
+		var errQuota actions.ErrArtifactQuotaExceeded
+		if errors.As(err, &errQuota) {
+			log.Warn("Error artifact quota exceeded: %v", err)
+			ctx.Error(http.StatusRequestEntityTooLarge, "Error artifact quota exceeded: "+err.Error())
+			return
+		}
+		log.Error("Error evict artifacts: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error evict artifacts")
+		return
+	}
+
 	// save chunk to storage, if success, return chunk stotal size
 	// if artifact is not gzip when uploading, chunksTotalSize ==  fileRealTotalSize
 	// if artifact is gzip when uploading, chunksTotalSize <  fileRealTotalSize
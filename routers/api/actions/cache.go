@@ -0,0 +1,380 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+// GitHub Actions Cache API Simple Description
+//
+// The runners use it by setting ACTIONS_CACHE_URL to {ROOT_URL}api/actions_cache/, like the `cache.external_server` of act_runner.
+// The caches are scoped by the ref of the run, see actions_service.RestoreCache.
+//
+// 1. Restore cache
+// GET: /api/actions_cache/_apis/artifactcache/cache?keys={primary_key},{restore_key}&version={version}
+// Response:
+// 204 if there is no matched cache, or
+// {
+//   "result": "hit",
+//   "cacheKey": "{key}",
+//   "scope": "{ref}",
+//   "archiveLocation": "/api/actions_cache/_apis/artifactcache/artifacts/{cache_id}?expires={unix}&sig={signature}"
+// }
+// the archive location is signed, since actions/cache downloads it without the token
+//
+// 2. Save cache
+// 2.1 Reserve cache
+// POST: /api/actions_cache/_apis/artifactcache/caches
+// Request:
+// {
+//   "key": "{key}",
+//   "version": "{version}",
+//   "cacheSize": 1024
+// }
+// Response:
+// {
+//   "cacheId": 1
+// }
+// 409 if the ref has created the cache
+// 2.2 Upload cache chunk
+// PATCH: /api/actions_cache/_apis/artifactcache/caches/{cache_id}
+// it uploads the chunk with the header:
+//    content-range: bytes 0-1023/*
+// 2.3 Commit cache
+// POST: /api/actions_cache/_apis/artifactcache/caches/{cache_id}
+// Request:
+// {
+//   "size": 1024
+// }
+// it merges the chunks to one file, the least recently used caches of the repository are evicted if it exceeds the quota
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	cacheRouteBase = "/_apis/artifactcache"
+	// cacheArchiveExpiration is how long the signed archive location of a restored cache is valid
+	cacheArchiveExpiration = time.Hour
+)
+
+func CacheRoutes(prefix string) *web.Route {
+	m := web.NewRoute()
+
+	r := cacheRoutes{
+		prefix: prefix,
+		fs:     storage.ActionsArtifacts,
+	}
+
+	m.Group(cacheRouteBase, func() {
+		m.Get("/cache", r.restoreCache)
+		m.Post("/caches", r.reserveCache)
+		m.Combo("/caches/{cache_id}").Patch(r.uploadCache).Post(r.commitCache)
+	}, ArtifactContexter())
+	// actions/cache downloads the archive without the token, so the location is signed instead
+	m.Get(cacheRouteBase+"/artifacts/{cache_id}", r.downloadCache)
+
+	return m
+}
+
+type cacheRoutes struct {
+	prefix string
+	fs     storage.ObjectStorage
+}
+
+type restoreCacheResponse struct {
+	Result          string `json:"result"`
+	CacheKey        string `json:"cacheKey"`
+	Scope           string `json:"scope"`
+	ArchiveLocation string `json:"archiveLocation"`
+}
+
+// restoreCache finds the cache to restore for the keys, the first one is the primary key and the others are the restore keys
+func (cr cacheRoutes) restoreCache(ctx *ArtifactContext) {
+	run, ok := loadCacheRun(ctx)
+	if !ok {
+		return
+	}
+	keys := strings.Split(ctx.Req.URL.Query().Get("keys"), ",")
+	version := ctx.Req.URL.Query().Get("version")
+
+	cache, err := actions_service.RestoreCache(ctx, run, keys, version)
+	if err != nil {
+		log.Error("Error restore cache: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error restore cache")
+		return
+	}
+	if cache == nil {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.JSON(http.StatusOK, restoreCacheResponse{
+		Result:          "hit",
+		CacheKey:        cache.Key,
+		Scope:           cache.Ref,
+		ArchiveLocation: cr.buildArchiveURL(cache.ID, time.Now().Add(cacheArchiveExpiration).Unix()),
+	})
+}
+
+type reserveCacheRequest struct {
+	Key       string `json:"key"`
+	Version   string `json:"version"`
+	CacheSize int64  `json:"cacheSize"`
+}
+
+type reserveCacheResponse struct {
+	CacheID int64 `json:"cacheId"`
+}
+
+// reserveCache reserves the cache to upload for the ref of the run
+func (cr cacheRoutes) reserveCache(ctx *ArtifactContext) {
+	run, ok := loadCacheRun(ctx)
+	if !ok {
+		return
+	}
+	var req reserveCacheRequest
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&req); err != nil {
+		log.Error("Error decode request body: %v", err)
+		ctx.Error(http.StatusBadRequest, "Error decode request body")
+		return
+	}
+
+	cache, err := actions_service.ReserveCache(ctx, run, req.Key, req.Version, req.CacheSize)
+	if errors.Is(err, util.ErrAlreadyExist) {
+		ctx.Error(http.StatusConflict, err.Error())
+		return
+	} else if errors.Is(err, util.ErrInvalidArgument) {
+		ctx.Error(http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		log.Error("Error reserve cache: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error reserve cache")
+		return
+	}
+	ctx.JSON(http.StatusOK, reserveCacheResponse{CacheID: cache.ID})
+}
+
+// uploadCache saves a chunk of the cache, the chunks could be uploaded concurrently in any order
+func (cr cacheRoutes) uploadCache(ctx *ArtifactContext) {
+	cache, ok := cr.loadUploadingCache(ctx)
+	if !ok {
+		return
+	}
+	// parse content-range header, format: bytes 0-1023/*
+	start, end := int64(0), int64(0)
+	if _, err := fmt.Sscanf(ctx.Req.Header.Get("Content-Range"), "bytes %d-%d/", &start, &end); err != nil || start > end {
+		ctx.Error(http.StatusBadRequest, "Invalid content range")
+		return
+	}
+	chunkPath := fmt.Sprintf("%s/%d-%d.chunk", cacheChunksDir(cache), start, end)
+	written, err := cr.fs.Save(chunkPath, ctx.Req.Body, -1)
+	if err != nil {
+		log.Error("Error save cache chunk: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error save cache chunk")
+		return
+	}
+	if written != end-start+1 {
+		if err := cr.fs.Delete(chunkPath); err != nil {
+			log.Error("Error delete cache chunk %s: %v", chunkPath, err)
+		}
+		ctx.Error(http.StatusBadRequest, "Chunk size doesn't match the content range")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+type commitCacheRequest struct {
+	Size int64 `json:"size"`
+}
+
+// commitCache merges the uploaded chunks of the cache to one file, then the cache could be restored
+func (cr cacheRoutes) commitCache(ctx *ArtifactContext) {
+	cache, ok := cr.loadUploadingCache(ctx)
+	if !ok {
+		return
+	}
+	var req commitCacheRequest
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&req); err != nil {
+		log.Error("Error decode request body: %v", err)
+		ctx.Error(http.StatusBadRequest, "Error decode request body")
+		return
+	}
+
+	storagePath := fmt.Sprintf("cache/%d/%d.cache", cache.RepoID, cache.ID)
+	if err := cr.mergeCacheChunks(cache, storagePath, req.Size); err != nil {
+		log.Error("Error merge cache chunks: %v", err)
+		ctx.Error(http.StatusBadRequest, "Error merge cache chunks")
+		return
+	}
+	if err := actions_service.CommitCache(ctx, cache, storagePath, req.Size); err != nil {
+		if err := cr.fs.Delete(storagePath); err != nil {
+			log.Error("Error delete cache %s: %v", storagePath, err)
+		}
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("Error commit cache: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error commit cache")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// downloadCache downloads the archive of the cache by the signed location returned by restoreCache
+func (cr cacheRoutes) downloadCache(resp http.ResponseWriter, req *http.Request) {
+	cacheID, err := strconv.ParseInt(chi.URLParam(req, "cache_id"), 10, 64)
+	if err != nil {
+		http.Error(resp, "Invalid cache id", http.StatusBadRequest)
+		return
+	}
+	expires, err := strconv.ParseInt(req.URL.Query().Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires || !hmac.Equal([]byte(req.URL.Query().Get("sig")), []byte(signCacheArchive(cacheID, expires))) {
+		http.Error(resp, "Invalid or expired signature", http.StatusUnauthorized)
+		return
+	}
+
+	cache, exist, err := db.GetByID[actions.ActionCache](req.Context(), cacheID)
+	if err != nil {
+		log.Error("Error get cache: %v", err)
+		http.Error(resp, "Error get cache", http.StatusInternalServerError)
+		return
+	} else if !exist || !cache.Complete {
+		http.Error(resp, "Cache not found", http.StatusNotFound)
+		return
+	}
+	f, err := cr.fs.Open(cache.StoragePath)
+	if err != nil {
+		log.Error("Error open cache %s: %v", cache.StoragePath, err)
+		http.Error(resp, "Error open cache", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	resp.Header().Set("Content-Type", "application/octet-stream")
+	resp.Header().Set("Content-Length", strconv.FormatInt(cache.Size, 10))
+	_, _ = io.Copy(resp, f)
+}
+
+// loadCacheRun loads the run of the task, the caches are scoped by it
+func loadCacheRun(ctx *ArtifactContext) (*actions.ActionRun, bool) {
+	run, err := actions.GetRunByID(ctx, ctx.ActionTask.Job.RunID)
+	if err != nil {
+		log.Error("Error get run: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error get run")
+		return nil, false
+	}
+	return run, true
+}
+
+// loadUploadingCache loads the cache in the url, it must be reserved by the ref of the task and not be completed yet
+func (cr cacheRoutes) loadUploadingCache(ctx *ArtifactContext) (*actions.ActionCache, bool) {
+	run, ok := loadCacheRun(ctx)
+	if !ok {
+		return nil, false
+	}
+	cache, err := actions.GetCacheByID(ctx, run.RepoID, ctx.ParamsInt64("cache_id"))
+	if errors.Is(err, util.ErrNotExist) {
+		ctx.Error(http.StatusNotFound, "Cache not found")
+		return nil, false
+	} else if err != nil {
+		log.Error("Error get cache: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error get cache")
+		return nil, false
+	}
+	if cache.Ref != run.Ref || cache.Complete {
+		ctx.Error(http.StatusBadRequest, "Cache is not being uploaded by the ref")
+		return nil, false
+	}
+	return cache, true
+}
+
+func cacheChunksDir(cache *actions.ActionCache) string {
+	return fmt.Sprintf("tmp-cache%d", cache.ID)
+}
+
+type cacheChunk struct {
+	Start, End int64
+	Path       string
+}
+
+// mergeCacheChunks merges the chunks of the cache to one file of storagePath, the chunks must cover the size exactly
+func (cr cacheRoutes) mergeCacheChunks(cache *actions.ActionCache, storagePath string, size int64) error {
+	dir := cacheChunksDir(cache)
+	var chunks []*cacheChunk
+	if err := cr.fs.IterateObjects(dir, func(fpath string, obj storage.Object) error {
+		// like the chunks of artifacts, the path only contains the storage dir and the basename
+		chunk := &cacheChunk{Path: dir + "/" + filepath.Base(fpath)}
+		if _, err := fmt.Sscanf(filepath.Base(fpath), "%d-%d.chunk", &chunk.Start, &chunk.End); err != nil {
+			return fmt.Errorf("parse chunk %s: %w", fpath, err)
+		}
+		chunks = append(chunks, chunk)
+		return nil
+	}); err != nil {
+		return err
+	}
+	defer func() {
+		for _, chunk := range chunks {
+			if err := cr.fs.Delete(chunk.Path); err != nil {
+				log.Error("Error delete cache chunk %s: %v", chunk.Path, err)
+			}
+		}
+	}()
+
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Start < chunks[j].Start
+	})
+	readers := make([]io.Reader, 0, len(chunks))
+	next := int64(0)
+	for _, chunk := range chunks {
+		if chunk.Start != next {
+			return fmt.Errorf("the chunks are not contiguous at %d", next)
+		}
+		next = chunk.End + 1
+		f, err := cr.fs.Open(chunk.Path)
+		if err != nil {
+			return fmt.Errorf("open chunk %s: %w", chunk.Path, err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	if next != size {
+		return fmt.Errorf("the chunks of %d bytes don't match the size %d", next, size)
+	}
+	if _, err := cr.fs.Save(storagePath, io.MultiReader(readers...), size); err != nil {
+		return fmt.Errorf("save cache: %w", err)
+	}
+	return nil
+}
+
+func (cr cacheRoutes) buildArchiveURL(cacheID, expires int64) string {
+	return fmt.Sprintf("%s%s%s/artifacts/%d?expires=%d&sig=%s", strings.TrimSuffix(setting.AppURL, "/"), strings.TrimSuffix(cr.prefix, "/"),
+		cacheRouteBase, cacheID, expires, signCacheArchive(cacheID, expires))
+}
+
+func signCacheArchive(cacheID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey))
+	_, _ = fmt.Fprintf(mac, "actions-cache:%d:%d", cacheID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
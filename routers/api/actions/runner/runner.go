@@ -212,6 +212,10 @@ func (s *Service) UpdateTask(
 	}
 
 	if req.Msg.State.Result != runnerv1.Result_RESULT_UNSPECIFIED {
+		// the outputs have been saved, so the environment url referencing them can be resolved now
+		if err := actions_service.ResolveJobEnvironmentURL(ctx, task.Job); err != nil {
+			log.Error("ResolveJobEnvironmentURL of job %d: %v", task.Job.ID, err)
+		}
 		if err := actions_service.EmitJobsIfReady(task.Job.RunID); err != nil {
 			log.Error("Emit ready jobs of run %d: %v", task.Job.RunID, err)
 		}
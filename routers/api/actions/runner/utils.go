@@ -33,6 +33,7 @@ func pickTask(ctx context.Context, runner *actions_model.ActionRunner) (*runnerv
 	}
 
 	actions.CreateCommitStatus(ctx, t.Job)
+	actions.AuditHostedFallback(ctx, runner, t)
 
 	task := &runnerv1.Task{
 		Id:              t.ID,
@@ -78,8 +79,19 @@ func getSecretsOfTask(ctx context.Context, task *actions_model.ActionTask) map[s
 		log.Error("find secrets of repo %v: %v", task.Job.Run.RepoID, err)
 		// go on
 	}
+	var environmentSecrets []*secret_model.Secret
+	if task.Job.Environment != "" && !isFromForkPullRequest(ctx, task.Job.Run) {
+		// the secrets of the environment are only available to the jobs deploying to it, and override the secrets with the same names.
+		// unlike the other secrets, they are never available to fork pull requests, even for the tasks triggered by pull_request_target event,
+		// or the runs triggered by workflow_run from the runs of fork pull requests
+		environmentSecrets, err = db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{RepoID: task.Job.Run.RepoID, Environment: task.Job.Environment})
+		if err != nil {
+			log.Error("find secrets of environment %q of repo %v: %v", task.Job.Environment, task.Job.Run.RepoID, err)
+			// go on
+		}
+	}
 
-	for _, secret := range append(ownerSecrets, repoSecrets...) {
+	for _, secret := range append(append(ownerSecrets, repoSecrets...), environmentSecrets...) {
 		if v, err := secret_module.DecryptSecret(setting.SecretKey, secret.Data); err != nil {
 			log.Error("decrypt secret %v %q: %v", secret.ID, secret.Name, err)
 			// go on
@@ -91,32 +103,21 @@ func getSecretsOfTask(ctx context.Context, task *actions_model.ActionTask) map[s
 	return secrets
 }
 
-func getVariablesOfTask(ctx context.Context, task *actions_model.ActionTask) map[string]string {
-	variables := map[string]string{}
-
-	// Global
-	globalVariables, err := db.Find[actions_model.ActionVariable](ctx, actions_model.FindVariablesOpts{})
-	if err != nil {
-		log.Error("find global variables: %v", err)
-	}
-
-	// Org / User level
-	ownerVariables, err := db.Find[actions_model.ActionVariable](ctx, actions_model.FindVariablesOpts{OwnerID: task.Job.Run.Repo.OwnerID})
+// isFromForkPullRequest reports whether the run or its upstream runs are runs of fork pull requests, it reports true if it's unknown
+func isFromForkPullRequest(ctx context.Context, run *actions_model.ActionRun) bool {
+	fromFork, err := run.IsFromForkPullRequest(ctx)
 	if err != nil {
-		log.Error("find variables of org: %d, error: %v", task.Job.Run.Repo.OwnerID, err)
+		log.Error("IsFromForkPullRequest [run: %d]: %v", run.ID, err)
 	}
+	return fromFork
+}
 
-	// Repo level
-	repoVariables, err := db.Find[actions_model.ActionVariable](ctx, actions_model.FindVariablesOpts{RepoID: task.Job.Run.RepoID})
+func getVariablesOfTask(ctx context.Context, task *actions_model.ActionTask) map[string]string {
+	variables, err := actions_model.GetVariablesOfRepo(ctx, task.Job.Run.Repo.OwnerID, task.Job.Run.RepoID)
 	if err != nil {
-		log.Error("find variables of repo: %d, error: %v", task.Job.Run.RepoID, err)
+		log.Error("GetVariablesOfRepo: %v", err)
+		return map[string]string{}
 	}
-
-	// Level precedence: Repo > Org / User > Global
-	for _, v := range append(globalVariables, append(ownerVariables, repoVariables...)...) {
-		variables[v.Name] = v.Data
-	}
-
 	return variables
 }
 
@@ -132,13 +133,16 @@ func generateTaskContext(t *actions_model.ActionTask) *structpb.Struct {
 		eventName = t.Job.Run.Event.Event()
 	}
 
-	baseRef := ""
-	headRef := ""
+	baseRef := t.Job.Run.BaseRef
+	headRef := t.Job.Run.HeadRef
 	ref := t.Job.Run.Ref
 	sha := t.Job.Run.CommitSHA
 	if pullPayload, err := t.Job.Run.GetPullRequestEventPayload(); err == nil && pullPayload.PullRequest != nil && pullPayload.PullRequest.Base != nil && pullPayload.PullRequest.Head != nil {
-		baseRef = pullPayload.PullRequest.Base.Ref
-		headRef = pullPayload.PullRequest.Head.Ref
+		// HeadRef and BaseRef are recorded when the run is created, this fallback is for the old runs without them
+		if baseRef == "" && headRef == "" {
+			baseRef = pullPayload.PullRequest.Base.Ref
+			headRef = pullPayload.PullRequest.Head.Ref
+		}
 
 		// if the TriggerEvent is pull_request_target, ref and sha need to be set according to the base of pull request
 		// In GitHub's documentation, ref should be the branch or tag that triggered workflow. But when the TriggerEvent is pull_request_target,
@@ -151,6 +155,15 @@ func generateTaskContext(t *actions_model.ActionTask) *structpb.Struct {
 
 	refName := git.RefName(ref)
 
+	// the retry configurations of steps keyed by the index of step, the runner retries the failed steps according to them
+	stepRetries := make(map[string]any, len(t.Job.StepRetries))
+	for index, retry := range t.Job.StepRetries {
+		stepRetries[fmt.Sprint(index)] = map[string]any{
+			"count":   retry.Count,
+			"backoff": retry.Backoff.String(),
+		}
+	}
+
 	taskContext, err := structpb.NewStruct(map[string]any{
 		// standard contexts, see https://docs.github.com/en/actions/learn-github-actions/contexts#github-context
 		"action":            "",                                                   // string, The name of the action currently running, or the id of a step. GitHub removes special characters, and uses the name __run when the current step runs a script without an id. If you use the same action more than once in the same job, the name will include a suffix with the sequence number with underscore before it. For example, the first script you run will have the name __run, and the second script will be named __run_2. Similarly, the second invocation of actions/checkout will be actionscheckout2.
@@ -190,6 +203,7 @@ func generateTaskContext(t *actions_model.ActionTask) *structpb.Struct {
 
 		// additional contexts
 		"gitea_default_actions_url": setting.Actions.DefaultActionsURL.URL(),
+		"gitea_step_retries":        stepRetries,
 	})
 	if err != nil {
 		log.Error("structpb.NewStruct failed: %v", err)
@@ -0,0 +1,108 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package runner
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getSecretsOfTask(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+	for _, s := range []struct{ environment, name, data string }{
+		{"", "DEPLOY_KEY", "repo"},
+		{"", "SHARED", "shared"},
+		{"Production", "DEPLOY_KEY", "production"},
+		{"staging", "DEPLOY_KEY", "staging"},
+		{"staging", "STAGING_ONLY", "staging only"},
+	} {
+		_, err := secret_model.InsertEncryptedSecret(db.DefaultContext, repo.OwnerID, repo.ID, s.environment, s.name, s.data)
+		require.NoError(t, err)
+	}
+
+	secretsOfRun := func(environment string, run *actions_model.ActionRun) map[string]string {
+		secrets := getSecretsOfTask(db.DefaultContext, &actions_model.ActionTask{
+			Token: "token",
+			Job: &actions_model.ActionRunJob{
+				Environment: environment,
+				Run:         run,
+			},
+		})
+		delete(secrets, "GITHUB_TOKEN")
+		delete(secrets, "GITEA_TOKEN")
+		return secrets
+	}
+	secretsOf := func(environment string, isForkPullRequest bool, event string) map[string]string {
+		return secretsOfRun(environment, &actions_model.ActionRun{
+			RepoID:            repo.ID,
+			Repo:              repo,
+			IsForkPullRequest: isForkPullRequest,
+			TriggerEvent:      event,
+		})
+	}
+
+	t.Run("no environment", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "repo", "SHARED": "shared"}, secretsOf("", false, actions_module.GithubEventPush))
+	})
+	t.Run("environment overrides", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "production", "SHARED": "shared"}, secretsOf("production", false, actions_module.GithubEventPush))
+	})
+	t.Run("other environment", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "staging", "SHARED": "shared", "STAGING_ONLY": "staging only"}, secretsOf("staging", false, actions_module.GithubEventPush))
+	})
+	t.Run("unknown environment", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "repo", "SHARED": "shared"}, secretsOf("preview", false, actions_module.GithubEventPush))
+	})
+	t.Run("fork pull request", func(t *testing.T) {
+		assert.Empty(t, secretsOf("production", true, actions_module.GithubEventPullRequest))
+	})
+	t.Run("fork pull request target", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "repo", "SHARED": "shared"}, secretsOf("production", true, actions_module.GithubEventPullRequestTarget))
+	})
+	t.Run("workflow_run of fork pull request", func(t *testing.T) {
+		index := int64(1000)
+		downstreamOf := func(isForkPullRequest bool) *actions_model.ActionRun {
+			index++
+			upstream := &actions_model.ActionRun{
+				RepoID:            repo.ID,
+				OwnerID:           repo.OwnerID,
+				Index:             index,
+				WorkflowID:        "test.yml",
+				Ref:               "refs/pull/3/head",
+				Event:             webhook_module.HookEventPullRequest,
+				TriggerEvent:      actions_module.GithubEventPullRequest,
+				IsForkPullRequest: isForkPullRequest,
+				Status:            actions_model.StatusSuccess,
+			}
+			require.NoError(t, db.Insert(db.DefaultContext, upstream))
+			payload, err := json.Marshal(&api.WorkflowRunPayload{WorkflowRun: &api.ActionWorkflowRun{ID: upstream.ID}})
+			require.NoError(t, err)
+			return &actions_model.ActionRun{
+				RepoID:       repo.ID,
+				Repo:         repo,
+				Event:        webhook_module.HookEventWorkflowRun,
+				EventPayload: string(payload),
+				TriggerEvent: actions_module.GithubEventWorkflowRun,
+			}
+		}
+		// the downstream runs of fork pull requests have the secrets of the repository, since they run the workflows of the default branch,
+		// but the environments they deploy to could be controlled by the forks
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "repo", "SHARED": "shared"}, secretsOfRun("production", downstreamOf(true)))
+		assert.Equal(t, map[string]string{"DEPLOY_KEY": "production", "SHARED": "shared"}, secretsOfRun("production", downstreamOf(false)))
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// GetActionsQueueStats returns the aggregate time the jobs waited for runners, grouped by the runner labels they requested
+func GetActionsQueueStats(ctx *context.APIContext) {
+	// swagger:operation GET /admin/actions/queue-stats admin adminGetActionsQueueStats
+	// ---
+	// summary: Get the aggregate time the jobs waited for runners, grouped by the runner labels they requested
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: since
+	//   in: query
+	//   description: Only the jobs picked by runners since the specified time are counted, the last 24 hours by default. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionQueueStatsList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	_, since, err := context.GetQueryBeforeSince(ctx.Base)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+	if since == 0 {
+		since = time.Now().Add(-24 * time.Hour).Unix()
+	}
+
+	stats, err := actions_model.GetQueueWaitStats(ctx, timeutil.TimeStamp(since))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetQueueWaitStats", err)
+		return
+	}
+	apiStats := make([]*api.ActionQueueStats, 0, len(stats))
+	for _, s := range stats {
+		apiStats = append(apiStats, convert.ToActionQueueStats(s))
+	}
+	ctx.JSON(http.StatusOK, apiStats)
+}
@@ -25,6 +25,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
 	"code.gitea.io/gitea/services/convert"
 	"code.gitea.io/gitea/services/mailer"
@@ -229,6 +230,7 @@ func EditUser(ctx *context.APIContext) {
 		ctx.ContextUser.MustChangePassword = *form.MustChangePassword
 	}
 
+	wasSuspended := actions_service.IsOwnerSuspended(ctx.ContextUser)
 	ctx.ContextUser.LoginName = form.LoginName
 
 	if form.FullName != nil {
@@ -301,6 +303,9 @@ func EditUser(ctx *context.APIContext) {
 		}
 		return
 	}
+	if err := actions_service.UpdateOwnerSchedules(ctx, ctx.ContextUser, wasSuspended); err != nil {
+		log.Error("UpdateOwnerSchedules of user %s: %v", ctx.ContextUser.Name, err)
+	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
 
 	ctx.JSON(http.StatusOK, convert.ToUser(ctx, ctx.ContextUser, ctx.Doer))
@@ -200,11 +200,7 @@ func repoAssignment() func(ctx *context.APIContext) {
 				return
 			}
 
-			if task.IsForkPullRequest {
-				ctx.Repo.Permission.AccessMode = perm.AccessModeRead
-			} else {
-				ctx.Repo.Permission.AccessMode = perm.AccessModeWrite
-			}
+			ctx.Repo.Permission.AccessMode = task.TokenAccessMode()
 
 			if err := ctx.Repo.Repository.LoadUnits(ctx); err != nil {
 				ctx.Error(http.StatusInternalServerError, "LoadUnits", err)
@@ -1064,10 +1060,33 @@ func Routes() *web.Route {
 							Put(reqToken(), reqOwner(), bind(api.CreateOrUpdateSecretOption{}), repo.CreateOrUpdateSecret).
 							Delete(reqToken(), reqOwner(), repo.DeleteSecret)
 					})
+					m.Combo("/environments/{environment}/secrets/{secretname}").
+						Put(reqToken(), reqOwner(), bind(api.CreateOrUpdateSecretOption{}), repo.CreateOrUpdateEnvironmentSecret).
+						Delete(reqToken(), reqOwner(), repo.DeleteEnvironmentSecret)
 
 					m.Group("/runners", func() {
 						m.Get("/registration-token", reqToken(), reqOwner(), repo.GetRegistrationToken)
 					})
+
+					m.Post("/workflows/lint", reqToken(), reqRepoReader(unit.TypeActions), bind(api.LintWorkflowOption{}), repo.LintWorkflow)
+					m.Combo("/workflows-source").
+						Put(reqToken(), reqAdmin(), bind(api.SetWorkflowsSourceOption{}), repo.SetWorkflowsSource).
+						Delete(reqToken(), reqAdmin(), repo.DeleteWorkflowsSource)
+					m.Put("/workflows/{workflow_id}/schedules/pause", reqToken(), reqRepoWriter(unit.TypeActions), repo.PauseWorkflowSchedules)
+					m.Put("/workflows/{workflow_id}/schedules/resume", reqToken(), reqRepoWriter(unit.TypeActions), repo.ResumeWorkflowSchedules)
+					m.Get("/triggers", reqRepoReader(unit.TypeActions), repo.GetActionTriggers)
+					m.Get("/runs", reqRepoReader(unit.TypeActions), repo.ListActionRuns)
+					m.Get("/runs/{run}", reqRepoReader(unit.TypeActions), repo.GetActionRun)
+					m.Get("/runs/{run}/jobs", reqRepoReader(unit.TypeActions), repo.ListActionRunJobs)
+					m.Post("/runs/{run}/jobs/{job}/cancel", reqToken(), reqRepoWriter(unit.TypeActions), repo.CancelActionRunJob)
+					m.Get("/runs/{run}/context", reqToken(), reqAdmin(), repo.GetActionRunContext)
+					m.Get("/runs/{run}/trace", reqToken(), reqAdmin(), repo.GetActionRunDetectionTrace)
+					m.Group("/schedules", func() {
+						m.Get("", reqRepoReader(unit.TypeActions), repo.ListActionSchedules)
+						m.Put("/{id}/enable", reqToken(), reqRepoWriter(unit.TypeActions), repo.EnableActionSchedule)
+						m.Put("/{id}/disable", reqToken(), reqRepoWriter(unit.TypeActions), repo.DisableActionSchedule)
+						m.Post("/{id}/run", reqToken(), reqRepoWriter(unit.TypeActions), repo.RunActionSchedule)
+					})
 				})
 				m.Group("/hooks/git", func() {
 					m.Combo("").Get(repo.ListGitHooks)
@@ -1182,6 +1201,7 @@ func Routes() *web.Route {
 					})
 				}, reqRepoReader(unit.TypeReleases))
 				m.Post("/mirror-sync", reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, repo.MirrorSync)
+				m.Post("/dispatches", reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, bind(api.CreateRepositoryDispatchOption{}), repo.CreateRepositoryDispatch)
 				m.Post("/push_mirrors-sync", reqAdmin(), reqToken(), mustNotBeArchived, repo.PushMirrorSync)
 				m.Group("/push_mirrors", func() {
 					m.Combo("").Get(repo.ListPushMirrors).
@@ -1539,6 +1559,7 @@ func Routes() *web.Route {
 			m.Group("/runners", func() {
 				m.Get("/registration-token", admin.GetRegistrationToken)
 			})
+			m.Get("/actions/queue-stats", admin.GetActionsQueueStats)
 		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryAdmin), reqToken(), reqSiteAdmin())
 
 		m.Group("/topics", func() {
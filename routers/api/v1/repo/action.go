@@ -7,10 +7,18 @@ import (
 	"errors"
 	"net/http"
 
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	actions_module "code.gitea.io/gitea/modules/actions"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/convert"
 	secret_service "code.gitea.io/gitea/services/secrets"
 )
 
@@ -127,3 +135,951 @@ func DeleteSecret(ctx *context.APIContext) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// CreateOrUpdateEnvironmentSecret create or update one secret of the repository scoped to the environment
+func CreateOrUpdateEnvironmentSecret(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/environments/{environment}/secrets/{secretname} repository updateRepoEnvironmentSecret
+	// ---
+	// summary: Create or Update a secret value of an environment in a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: environment
+	//   in: path
+	//   description: name of the environment, case-insensitive
+	//   type: string
+	//   required: true
+	// - name: secretname
+	//   in: path
+	//   description: name of the secret
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateOrUpdateSecretOption"
+	// responses:
+	//   "201":
+	//     description: response when creating a secret
+	//   "204":
+	//     description: response when updating a secret
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	owner := ctx.Repo.Owner
+	repo := ctx.Repo.Repository
+
+	opt := web.GetForm(ctx).(*api.CreateOrUpdateSecretOption)
+
+	_, created, err := secret_service.CreateOrUpdateEnvironmentSecret(ctx, owner.ID, repo.ID, ctx.Params("environment"), ctx.Params("secretname"), opt.Data)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusBadRequest, "CreateOrUpdateEnvironmentSecret", err)
+		} else if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, "CreateOrUpdateEnvironmentSecret", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateOrUpdateEnvironmentSecret", err)
+		}
+		return
+	}
+
+	if created {
+		ctx.Status(http.StatusCreated)
+	} else {
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// DeleteEnvironmentSecret delete one secret of the repository scoped to the environment
+func DeleteEnvironmentSecret(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/actions/environments/{environment}/secrets/{secretname} repository deleteRepoEnvironmentSecret
+	// ---
+	// summary: Delete a secret of an environment in a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: environment
+	//   in: path
+	//   description: name of the environment, case-insensitive
+	//   type: string
+	//   required: true
+	// - name: secretname
+	//   in: path
+	//   description: name of the secret
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     description: delete one secret of the environment
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	owner := ctx.Repo.Owner
+	repo := ctx.Repo.Repository
+
+	err := secret_service.DeleteEnvironmentSecretByName(ctx, owner.ID, repo.ID, ctx.Params("environment"), ctx.Params("secretname"))
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusBadRequest, "DeleteEnvironmentSecret", err)
+		} else if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, "DeleteEnvironmentSecret", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteEnvironmentSecret", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreateRepositoryDispatch triggers the repository_dispatch workflows of the repository
+func CreateRepositoryDispatch(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/dispatches repository repoCreateDispatch
+	// ---
+	// summary: Trigger the repository_dispatch workflows of a repository with a custom event
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: X-Gitea-Delivery
+	//   in: header
+	//   description: the ID of the upstream webhook delivery which the event originates from, it's recorded on the triggered runs. `X-GitHub-Delivery` is accepted too
+	//   type: string
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateRepositoryDispatchOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opt := web.GetForm(ctx).(*api.CreateRepositoryDispatchOption)
+
+	deliveryID := ctx.Req.Header.Get("X-Gitea-Delivery")
+	if deliveryID == "" {
+		deliveryID = ctx.Req.Header.Get("X-GitHub-Delivery")
+	}
+
+	if err := actions_service.DispatchRepositoryEvent(actions_service.WithDeliveryID(ctx, deliveryID), ctx.Doer, ctx.Repo.Repository, opt.EventType, opt.ClientPayload); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "DispatchRepositoryEvent", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DispatchRepositoryEvent", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// LintWorkflow validates a workflow without pushing or running it
+func LintWorkflow(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/workflows/lint repository repoLintWorkflow
+	// ---
+	// summary: Validate the YAML content of a workflow without running it
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/LintWorkflowOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowLintResult"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opt := web.GetForm(ctx).(*api.LintWorkflowOption)
+
+	ctx.JSON(http.StatusOK, convert.ToWorkflowLintResult(actions_module.ValidateWorkflow([]byte(opt.Content))))
+}
+
+// SetWorkflowsSource sets the workflows source of the repository
+func SetWorkflowsSource(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/workflows-source repository repoSetWorkflowsSource
+	// ---
+	// summary: Set the repository whose workflows are detected as if they were in a repository, the doer must be able to read it
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetWorkflowsSourceOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opt := web.GetForm(ctx).(*api.SetWorkflowsSourceOption)
+
+	if err := actions_service.SetWorkflowsSource(ctx, ctx.Repo.Repository, ctx.Doer, opt.Source, opt.Precedence, opt.Notify); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "SetWorkflowsSource", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetWorkflowsSource", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// DeleteWorkflowsSource removes the workflows source of the repository
+func DeleteWorkflowsSource(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/actions/workflows-source repository repoDeleteWorkflowsSource
+	// ---
+	// summary: Remove the workflows source of a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := actions_service.RemoveWorkflowsSource(ctx, ctx.Repo.Repository); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RemoveWorkflowsSource", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetActionTriggers returns what can trigger the workflows of the default branch of the repository
+func GetActionTriggers(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/triggers repository repoGetActionTriggers
+	// ---
+	// summary: Get the events and the schedules which trigger the workflows of the default branch of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowTriggerMap"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	triggerMap, commitSHA, err := actions_service.GetTriggerMap(ctx, ctx.Repo.Repository)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetTriggerMap", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToWorkflowTriggerMap(triggerMap, commitSHA))
+}
+
+// ListActionRuns lists the runs of the workflows of the repository
+func ListActionRuns(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs repository repoListActionRuns
+	// ---
+	// summary: List the runs of the workflows of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: workflow
+	//   in: query
+	//   description: filter by the name of the workflow file
+	//   type: string
+	// - name: production
+	//   in: query
+	//   description: filter (exclude / include) the production deploys
+	//   type: boolean
+	// - name: delivery_id
+	//   in: query
+	//   description: filter by the ID of the upstream webhook delivery which the trigger event originates from
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listOptions := utils.GetListOptions(ctx)
+	runs, total, err := db.FindAndCount[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+		ListOptions:  listOptions,
+		RepoID:       ctx.Repo.Repository.ID,
+		WorkflowID:   ctx.FormString("workflow"),
+		IsProduction: ctx.FormOptionalBool("production"),
+		DeliveryID:   ctx.FormString("delivery_id"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRuns", err)
+		return
+	}
+
+	apiRuns := make([]*api.ActionRun, 0, len(runs))
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		apiRuns = append(apiRuns, convert.ToActionRun(run))
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiRuns)
+}
+
+// GetActionRun gets a run of the workflows of the repository
+func GetActionRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run} repository repoGetActionRun
+	// ---
+	// summary: Get a run of the workflows of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: number of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":run"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByIndex", err)
+		}
+		return
+	}
+	run.Repo = ctx.Repo.Repository
+
+	ctx.JSON(http.StatusOK, convert.ToActionRun(run))
+}
+
+// ListActionRunJobs lists the jobs of a run of the workflows of the repository
+func ListActionRunJobs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run}/jobs repository repoListActionRunJobs
+	// ---
+	// summary: List the jobs of a run of the workflows of a repository, with the time they waited for runners
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: number of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunJobList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":run"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByIndex", err)
+		}
+		return
+	}
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobsByRunID", err)
+		return
+	}
+
+	apiJobs := make([]*api.ActionRunJob, 0, len(jobs))
+	for _, job := range jobs {
+		apiJobs = append(apiJobs, convert.ToActionRunJob(job))
+	}
+	ctx.JSON(http.StatusOK, apiJobs)
+}
+
+// CancelActionRunJob cancels a job of a run of the workflows of the repository without cancelling the other jobs
+func CancelActionRunJob(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run}/jobs/{job}/cancel repository repoCancelActionRunJob
+	// ---
+	// summary: Cancel a job of a run of the workflows of a repository, the jobs which need it are skipped while the others go on
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: number of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: job
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunJob"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":run"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByIndex", err)
+		}
+		return
+	}
+	job, err := actions_model.GetRunJobByID(ctx, ctx.ParamsInt64(":job"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunJobByID", err)
+		}
+		return
+	}
+	if job.RunID != run.ID {
+		ctx.NotFound()
+		return
+	}
+
+	if err := actions_service.CancelJob(ctx, job); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CancelJob", err)
+		return
+	}
+	if job, err = actions_model.GetRunJobByID(ctx, job.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobByID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToActionRunJob(job))
+}
+
+// GetActionRunContext gets the contexts which the workflow of a run saw when the run was created
+func GetActionRunContext(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run}/context repository repoGetActionRunContext
+	// ---
+	// summary: Get the contexts which the workflow of a run saw when the run was created, for debugging
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: number of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunContext"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":run"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByIndex", err)
+		}
+		return
+	}
+	runContext, err := actions_model.GetRunContextByRunID(ctx, run.ID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunContextByRunID", err)
+		}
+		return
+	}
+
+	contexts := map[string]any{}
+	if err := json.Unmarshal([]byte(runContext.Content), &contexts); err != nil {
+		ctx.Error(http.StatusInternalServerError, "json.Unmarshal", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ActionRunContext{
+		Context:   contexts,
+		Truncated: runContext.Truncated,
+		Created:   runContext.Created.AsLocalTime(),
+	})
+}
+
+// GetActionRunDetectionTrace gets the trace of why a run was created
+func GetActionRunDetectionTrace(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run}/trace repository repoGetActionRunDetectionTrace
+	// ---
+	// summary: Get the trace of why a run was created, which is only stored if the detection trace is enabled
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: number of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunDetectionTrace"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":run"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByIndex", err)
+		}
+		return
+	}
+	if run.DetectionTrace == nil {
+		ctx.NotFound()
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionRunDetectionTrace(run.DetectionTrace))
+}
+
+// ListActionSchedules lists the schedules of the workflows of the repository
+func ListActionSchedules(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/schedules repository repoListActionSchedules
+	// ---
+	// summary: List the schedules of the workflows of a repository with their enabled state
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionScheduleList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	schedules, err := db.Find[actions_model.ActionSchedule](ctx, actions_model.FindScheduleOptions{RepoID: ctx.Repo.Repository.ID})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindSchedules", err)
+		return
+	}
+	specs, err := db.Find[actions_model.ActionScheduleSpec](ctx, actions_model.FindSpecOptions{RepoID: ctx.Repo.Repository.ID})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindSpecs", err)
+		return
+	}
+	nexts := make(map[int64]timeutil.TimeStamp, len(schedules))
+	for _, spec := range specs {
+		if next, ok := nexts[spec.ScheduleID]; !ok || spec.Next < next {
+			nexts[spec.ScheduleID] = spec.Next
+		}
+	}
+
+	apiSchedules := make([]*api.ActionSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		apiSchedules = append(apiSchedules, convert.ToActionSchedule(schedule, nexts[schedule.ID]))
+	}
+	ctx.JSON(http.StatusOK, apiSchedules)
+}
+
+// EnableActionSchedule enables a schedule of the workflows of the repository
+func EnableActionSchedule(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/schedules/{id}/enable repository repoEnableActionSchedule
+	// ---
+	// summary: Enable a schedule of the workflows of a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the schedule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	enableOrDisableActionSchedule(ctx, true)
+}
+
+// DisableActionSchedule disables a schedule of the workflows of the repository without changing the workflow
+func DisableActionSchedule(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/schedules/{id}/disable repository repoDisableActionSchedule
+	// ---
+	// summary: Disable a schedule of the workflows of a repository without changing the workflow
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the schedule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	enableOrDisableActionSchedule(ctx, false)
+}
+
+// RunActionSchedule runs a schedule of the workflows of the repository at once
+func RunActionSchedule(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/schedules/{id}/run repository repoRunActionSchedule
+	// ---
+	// summary: Run a schedule of the workflows of a repository at once as if it had fired, to test the schedule
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the schedule
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ActionRun"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	schedule, err := actions_model.GetScheduleByRepoAndID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetScheduleByRepoAndID", err)
+		}
+		return
+	}
+	schedule.Repo = ctx.Repo.Repository
+
+	run, err := actions_service.RunScheduleNow(ctx, ctx.Doer, schedule)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "RunScheduleNow", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RunScheduleNow", err)
+		}
+		return
+	}
+	run.Repo = ctx.Repo.Repository
+
+	ctx.JSON(http.StatusCreated, convert.ToActionRun(run))
+}
+
+// PauseWorkflowSchedules pauses all schedules of a workflow of the repository
+func PauseWorkflowSchedules(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/workflows/{workflow_id}/schedules/pause repository repoPauseWorkflowSchedules
+	// ---
+	// summary: Pause all schedules of a workflow of a repository, they stay paused even if the workflow is changed until they are resumed
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: name of the workflow file
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := actions_service.PauseWorkflowSchedules(ctx, ctx.Repo.Repository, ctx.Params(":workflow_id")); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "PauseWorkflowSchedules", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ResumeWorkflowSchedules resumes all schedules of a workflow of the repository
+func ResumeWorkflowSchedules(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/workflows/{workflow_id}/schedules/resume repository repoResumeWorkflowSchedules
+	// ---
+	// summary: Resume all schedules of a workflow of a repository, the occurrences missed while paused are skipped unless catch_up is set
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: name of the workflow file
+	//   type: string
+	//   required: true
+	// - name: catch_up
+	//   in: query
+	//   description: run each schedule which has missed any occurrence while paused once at once
+	//   type: boolean
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := actions_service.ResumeWorkflowSchedules(ctx, ctx.Repo.Repository, ctx.Params(":workflow_id"), ctx.FormBool("catch_up")); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ResumeWorkflowSchedules", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func enableOrDisableActionSchedule(ctx *context.APIContext, isEnable bool) {
+	if err := actions_service.EnableOrDisableSchedule(ctx, ctx.Repo.Repository, ctx.ParamsInt64(":id"), isEnable); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "EnableOrDisableSchedule", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
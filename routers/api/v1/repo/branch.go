@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/convert"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -640,6 +641,10 @@ func CreateBranchProtection(ctx *context.APIContext) {
 			ctx.Error(http.StatusInternalServerError, "CheckPRsForBaseBranch", err)
 			return
 		}
+		if err = automerge.MergeScheduledPullRequestsByBaseBranch(ctx, ctx.Repo.Repository, ruleName); err != nil {
+			ctx.Error(http.StatusInternalServerError, "MergeScheduledPullRequestsByBaseBranch", err)
+			return
+		}
 	} else {
 		if !isPlainRule {
 			if ctx.Repo.GitRepo == nil {
@@ -665,6 +670,10 @@ func CreateBranchProtection(ctx *context.APIContext) {
 					ctx.Error(http.StatusInternalServerError, "CheckPRsForBaseBranch", err)
 					return
 				}
+				if err = automerge.MergeScheduledPullRequestsByBaseBranch(ctx, ctx.Repo.Repository, branchName); err != nil {
+					ctx.Error(http.StatusInternalServerError, "MergeScheduledPullRequestsByBaseBranch", err)
+					return
+				}
 			}
 		}
 	}
@@ -917,6 +926,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 			ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
 			return
 		}
+		if err = automerge.MergeScheduledPullRequestsByBaseBranch(ctx, ctx.Repo.Repository, bpName); err != nil {
+			ctx.Error(http.StatusInternalServerError, "MergeScheduledPullRequestsByBaseBranch", err)
+			return
+		}
 	} else {
 		if !isPlainRule {
 			if ctx.Repo.GitRepo == nil {
@@ -943,6 +956,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 					ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
 					return
 				}
+				if err = automerge.MergeScheduledPullRequestsByBaseBranch(ctx, ctx.Repo.Repository, branchName); err != nil {
+					ctx.Error(http.StatusInternalServerError, "MergeScheduledPullRequestsByBaseBranch", err)
+					return
+				}
 			}
 		}
 	}
@@ -18,3 +18,73 @@ type swaggerResponseSecret struct {
 	// in:body
 	Body api.Secret `json:"body"`
 }
+
+// WorkflowLintResult
+// swagger:response WorkflowLintResult
+type swaggerResponseWorkflowLintResult struct {
+	// in:body
+	Body api.WorkflowLintResult `json:"body"`
+}
+
+// WorkflowTriggerMap
+// swagger:response WorkflowTriggerMap
+type swaggerResponseWorkflowTriggerMap struct {
+	// in:body
+	Body api.WorkflowTriggerMap `json:"body"`
+}
+
+// ActionRun
+// swagger:response ActionRun
+type swaggerResponseActionRun struct {
+	// in:body
+	Body api.ActionRun `json:"body"`
+}
+
+// ActionRunList
+// swagger:response ActionRunList
+type swaggerResponseActionRunList struct {
+	// in:body
+	Body []api.ActionRun `json:"body"`
+}
+
+// ActionRunJob
+// swagger:response ActionRunJob
+type swaggerResponseActionRunJob struct {
+	// in:body
+	Body api.ActionRunJob `json:"body"`
+}
+
+// ActionRunJobList
+// swagger:response ActionRunJobList
+type swaggerResponseActionRunJobList struct {
+	// in:body
+	Body []api.ActionRunJob `json:"body"`
+}
+
+// ActionQueueStatsList
+// swagger:response ActionQueueStatsList
+type swaggerResponseActionQueueStatsList struct {
+	// in:body
+	Body []api.ActionQueueStats `json:"body"`
+}
+
+// ActionRunDetectionTrace
+// swagger:response ActionRunDetectionTrace
+type swaggerResponseActionRunDetectionTrace struct {
+	// in:body
+	Body api.ActionRunDetectionTrace `json:"body"`
+}
+
+// ActionRunContext
+// swagger:response ActionRunContext
+type swaggerResponseActionRunContext struct {
+	// in:body
+	Body api.ActionRunContext `json:"body"`
+}
+
+// ActionScheduleList
+// swagger:response ActionScheduleList
+type swaggerResponseActionScheduleList struct {
+	// in:body
+	Body []api.ActionSchedule `json:"body"`
+}
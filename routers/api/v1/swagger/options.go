@@ -190,4 +190,13 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreateOrUpdateSecretOption api.CreateOrUpdateSecretOption
+
+	// in:body
+	CreateRepositoryDispatchOption api.CreateRepositoryDispatchOption
+
+	// in:body
+	LintWorkflowOption api.LintWorkflowOption
+
+	// in:body
+	SetWorkflowsSourceOption api.SetWorkflowsSourceOption
 }
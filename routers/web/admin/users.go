@@ -25,6 +25,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/explore"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
@@ -442,6 +443,7 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 
+	wasSuspended := actions_service.IsOwnerSuspended(u)
 	u.LoginName = form.LoginName
 	u.FullName = form.FullName
 	emailChanged := !strings.EqualFold(u.Email, form.Email)
@@ -478,6 +480,9 @@ func EditUserPost(ctx *context.Context) {
 		}
 		return
 	}
+	if err := actions_service.UpdateOwnerSchedules(ctx, u, wasSuspended); err != nil {
+		log.Error("UpdateOwnerSchedules of user %s: %v", u.Name, err)
+	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, u.Name)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
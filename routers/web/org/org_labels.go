@@ -13,6 +13,7 @@ import (
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
+	issue_service "code.gitea.io/gitea/services/issue"
 )
 
 // RetrieveLabels find all the labels of an organization
@@ -50,7 +51,7 @@ func NewLabel(ctx *context.Context) {
 		Description: form.Description,
 		Color:       form.Color,
 	}
-	if err := issues_model.NewLabel(ctx, l); err != nil {
+	if err := issue_service.NewLabel(ctx, ctx.Doer, l); err != nil {
 		ctx.ServerError("NewLabel", err)
 		return
 	}
@@ -76,7 +77,7 @@ func UpdateLabel(ctx *context.Context) {
 	l.Description = form.Description
 	l.Color = form.Color
 	l.SetArchived(form.IsArchived)
-	if err := issues_model.UpdateLabel(ctx, l); err != nil {
+	if err := issue_service.UpdateLabel(ctx, ctx.Doer, l); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
 	}
@@ -85,7 +86,7 @@ func UpdateLabel(ctx *context.Context) {
 
 // DeleteLabel delete a label
 func DeleteLabel(ctx *context.Context) {
-	if err := issues_model.DeleteLabel(ctx, ctx.Org.Organization.ID, ctx.FormInt64("id")); err != nil {
+	if err := issue_service.DeleteLabel(ctx, ctx.Doer, ctx.Org.Organization.ID, ctx.FormInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteLabel: " + err.Error())
 	} else {
 		ctx.Flash.Success(ctx.Tr("repo.issues.label_deletion_success"))
@@ -11,12 +11,12 @@ import (
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/actions"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/context"
-	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/web/repo"
@@ -31,7 +31,7 @@ const (
 )
 
 type Workflow struct {
-	Entry  git.TreeEntry
+	Entry  *actions.WorkflowEntry
 	ErrMsg string
 }
 
@@ -69,7 +69,7 @@ func List(ctx *context.Context) {
 			ctx.Error(http.StatusInternalServerError, err.Error())
 			return
 		}
-		entries, err := actions.ListWorkflows(commit)
+		entries, err := actions.ListWorkflows(commit, ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions).ActionsConfig().WorkflowDirs...)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, err.Error())
 			return
@@ -92,8 +92,8 @@ func List(ctx *context.Context) {
 
 		workflows = make([]Workflow, 0, len(entries))
 		for _, entry := range entries {
-			workflow := Workflow{Entry: *entry}
-			content, err := actions.GetContentFromEntry(entry)
+			workflow := Workflow{Entry: entry}
+			content, err := actions.GetContentFromEntry(entry.TreeEntry)
 			if err != nil {
 				ctx.Error(http.StatusInternalServerError, err.Error())
 				return
@@ -137,6 +137,7 @@ func List(ctx *context.Context) {
 	workflow := ctx.FormString("workflow")
 	actorID := ctx.FormInt64("actor")
 	status := ctx.FormInt("status")
+	issueIndex := ctx.FormInt64("issue")
 	ctx.Data["CurWorkflow"] = workflow
 
 	actionsConfig := ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions).ActionsConfig()
@@ -165,6 +166,22 @@ func List(ctx *context.Context) {
 		TriggerUserID: actorID,
 	}
 
+	// list the runs triggered by the events of an issue or a pull request, like the checks of it
+	if issueIndex > 0 {
+		issue, err := issues_model.GetIssueByIndex(ctx, ctx.Repo.Repository.ID, issueIndex)
+		if err != nil {
+			if issues_model.IsErrIssueNotExist(err) {
+				ctx.NotFound("GetIssueByIndex", err)
+			} else {
+				ctx.ServerError("GetIssueByIndex", err)
+			}
+			return
+		}
+		opts.IssueID = issue.ID
+		ctx.Data["CurIssue"] = issueIndex
+		ctx.Data["IsFiltered"] = true
+	}
+
 	// if status is not StatusUnknown, it means user has selected a status filter
 	if actions_model.Status(status) != actions_model.StatusUnknown {
 		opts.Status = []actions_model.Status{actions_model.Status(status)}
@@ -201,6 +218,9 @@ func List(ctx *context.Context) {
 	pager.AddParamString("workflow", workflow)
 	pager.AddParamString("actor", fmt.Sprint(actorID))
 	pager.AddParamString("status", fmt.Sprint(status))
+	if issueIndex > 0 {
+		pager.AddParamString("issue", fmt.Sprint(issueIndex))
+	}
 	ctx.Data["Page"] = pager
 	ctx.Data["HasWorkflowsOrRuns"] = len(workflows) > 0 || len(runs) > 0
 
@@ -17,13 +17,15 @@ import (
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/actions"
 	"code.gitea.io/gitea/modules/base"
 	context_module "code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
-	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	actions_service "code.gitea.io/gitea/services/actions"
@@ -64,13 +66,18 @@ type ViewResponse struct {
 			CanApprove bool       `json:"canApprove"` // the run needs an approval and the doer has permission to approve
 			CanRerun   bool       `json:"canRerun"`
 			Done       bool       `json:"done"`
+			Warnings   []string   `json:"warnings"` // the deprecated syntax detected in the workflow
+			Errors     []string   `json:"errors"`   // the problems which failed the run when it was created
+			Trigger    string     `json:"trigger"`  // why the run was created, empty for the runs created before it's recorded
+			Issue      *ViewIssue `json:"issue"`    // the issue or the pull request which the event is about, nil for other events
 			Jobs       []*ViewJob `json:"jobs"`
 			Commit     ViewCommit `json:"commit"`
 		} `json:"run"`
 		CurrentJob struct {
-			Title  string         `json:"title"`
-			Detail string         `json:"detail"`
-			Steps  []*ViewJobStep `json:"steps"`
+			Title          string         `json:"title"`
+			Detail         string         `json:"detail"`
+			EnvironmentURL string         `json:"environmentURL"` // the link to what the job deploys, see actions_model.ActionRunJob.EnvironmentURL
+			Steps          []*ViewJobStep `json:"steps"`
 		} `json:"currentJob"`
 	} `json:"state"`
 	Logs struct {
@@ -79,11 +86,19 @@ type ViewResponse struct {
 }
 
 type ViewJob struct {
-	ID       int64  `json:"id"`
-	Name     string `json:"name"`
-	Status   string `json:"status"`
-	CanRerun bool   `json:"canRerun"`
-	Duration string `json:"duration"`
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	CanRerun      bool   `json:"canRerun"`
+	Duration      string `json:"duration"`
+	QueueDuration string `json:"queueDuration"`
+}
+
+type ViewIssue struct {
+	Index  int64  `json:"index"`
+	Title  string `json:"title"`
+	Link   string `json:"link"`
+	IsPull bool   `json:"isPull"`
 }
 
 type ViewCommit struct {
@@ -144,19 +159,39 @@ func ViewPost(ctx *context_module.Context) {
 	resp.State.Run.Title = run.Title
 	resp.State.Run.Link = run.Link()
 	resp.State.Run.CanCancel = !run.Status.IsDone() && ctx.Repo.CanWrite(unit.TypeActions)
-	resp.State.Run.CanApprove = run.NeedApproval && ctx.Repo.CanWrite(unit.TypeActions)
+	if run.NeedApproval {
+		canApprove, err := actions_service.CanApproveRuns(ctx, ctx.Repo.Repository, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.State.Run.CanApprove = canApprove
+	}
 	resp.State.Run.CanRerun = run.Status.IsDone() && ctx.Repo.CanWrite(unit.TypeActions)
 	resp.State.Run.Done = run.Status.IsDone()
+	resp.State.Run.Warnings = run.Warnings
+	if resp.State.Run.Warnings == nil {
+		resp.State.Run.Warnings = []string{} // marshal to '[]' instead of 'null' in json
+	}
+	resp.State.Run.Errors = run.Errors
+	if resp.State.Run.Errors == nil {
+		resp.State.Run.Errors = []string{} // marshal to '[]' instead of 'null' in json
+	}
+	resp.State.Run.Trigger = triggerMatchDescription(ctx, run.TriggerMatch)
 	resp.State.Run.Jobs = make([]*ViewJob, 0, len(jobs)) // marshal to '[]' instead fo 'null' in json
 	resp.State.Run.Status = run.Status.String()
 	for _, v := range jobs {
-		resp.State.Run.Jobs = append(resp.State.Run.Jobs, &ViewJob{
+		job := &ViewJob{
 			ID:       v.ID,
 			Name:     v.Name,
 			Status:   v.Status.String(),
 			CanRerun: v.Status.IsDone() && ctx.Repo.CanWrite(unit.TypeActions),
 			Duration: v.Duration().String(),
-		})
+		}
+		if d := v.QueueDuration(); d > 0 {
+			job.QueueDuration = d.String()
+		}
+		resp.State.Run.Jobs = append(resp.State.Run.Jobs, job)
 	}
 
 	pusher := ViewUser{
@@ -176,6 +211,24 @@ func ViewPost(ctx *context_module.Context) {
 		Branch:         branch,
 	}
 
+	if run.IssueID > 0 {
+		// load the issue every time, so the title and the link are always up to date
+		issue, err := issues_model.GetIssueByID(ctx, run.IssueID)
+		if err != nil && !issues_model.IsErrIssueNotExist(err) {
+			ctx.Error(http.StatusInternalServerError, err.Error())
+			return
+		}
+		if issue != nil && issue.RepoID == run.RepoID {
+			issue.Repo = run.Repo
+			resp.State.Run.Issue = &ViewIssue{
+				Index:  issue.Index,
+				Title:  issue.Title,
+				Link:   issue.Link(),
+				IsPull: issue.IsPull,
+			}
+		}
+	}
+
 	var task *actions_model.ActionTask
 	if current.TaskID > 0 {
 		var err error
@@ -193,8 +246,21 @@ func ViewPost(ctx *context_module.Context) {
 
 	resp.State.CurrentJob.Title = current.Name
 	resp.State.CurrentJob.Detail = current.Status.LocaleString(ctx.Locale)
+	resp.State.CurrentJob.EnvironmentURL = current.EnvironmentURL
 	if run.NeedApproval {
 		resp.State.CurrentJob.Detail = ctx.Locale.Tr("actions.need_approval_desc")
+		if run.IsProtectedTag && setting.Actions.ProtectedTagApproval {
+			resp.State.CurrentJob.Detail = ctx.Locale.Tr("actions.runs.protected_tag_approval")
+		}
+	}
+	if len(run.Errors) > 0 && current.Status == actions_model.StatusFailure && current.TaskID == 0 {
+		resp.State.CurrentJob.Detail = ctx.Locale.Tr("actions.runs.run_errors_failed")
+	} else if run.QuotaExceeded && current.Status == actions_model.StatusFailure && current.TaskID == 0 {
+		resp.State.CurrentJob.Detail = ctx.Locale.Tr("actions.runs.minutes_quota_failed")
+	} else if run.DeployGuard == actions_model.DeployGuardQueued && current.Status.IsWaiting() {
+		resp.State.CurrentJob.Detail = ctx.Locale.Tr("actions.runs.deploy_guard_queued")
+	} else if run.QuotaQueued && current.Status.IsWaiting() {
+		resp.State.CurrentJob.Detail = ctx.Locale.Tr("actions.runs.minutes_quota_queued")
 	}
 	resp.State.CurrentJob.Steps = make([]*ViewJobStep, 0) // marshal to '[]' instead fo 'null' in json
 	resp.Logs.StepsLog = make([]*ViewStepLog, 0)          // marshal to '[]' instead fo 'null' in json
@@ -232,7 +298,7 @@ func ViewPost(ctx *context_module.Context) {
 				length := step.LogLength - cursor.Cursor
 				offset := task.LogIndexes[index]
 				var err error
-				logRows, err := actions.ReadLogs(ctx, task.LogInStorage, task.LogFilename, offset, length)
+				logRows, err := actions.ReadTaskLogs(ctx, task, offset, length)
 				if err != nil {
 					ctx.Error(http.StatusInternalServerError, err.Error())
 					return
@@ -259,6 +325,28 @@ func ViewPost(ctx *context_module.Context) {
 	ctx.JSON(http.StatusOK, resp)
 }
 
+// triggerMatchDescription describes how the trigger event of the run matched, like "Triggered by pull_request (opened), on branch or tag main"
+func triggerMatchDescription(ctx *context_module.Context, match *actions_model.RunTriggerMatch) string {
+	if match == nil {
+		return ""
+	}
+	event := match.Event
+	if match.ActivityType != "" {
+		event = fmt.Sprintf("%s (%s)", match.Event, match.ActivityType)
+	}
+	parts := []string{ctx.Locale.Tr("actions.runs.trigger_match", event)}
+	if match.Ref != "" {
+		parts = append(parts, ctx.Locale.Tr("actions.runs.trigger_match_ref", match.Ref))
+	}
+	if match.Path != "" {
+		parts = append(parts, ctx.Locale.Tr("actions.runs.trigger_match_path", match.Path))
+	}
+	if match.Schedule != "" {
+		parts = append(parts, ctx.Locale.Tr("actions.runs.trigger_match_schedule", match.Schedule))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // Rerun will rerun jobs in the given run
 // jobIndex = 0 means rerun all jobs
 func Rerun(ctx *context_module.Context) {
@@ -284,7 +372,8 @@ func Rerun(ctx *context_module.Context) {
 		run.PreviousDuration = run.Duration()
 		run.Started = 0
 		run.Stopped = 0
-		if err := actions_model.UpdateRun(ctx, run, "started", "stopped", "previous_duration"); err != nil {
+		run.CompletedNotified = false
+		if err := actions_model.UpdateRun(ctx, run, "started", "stopped", "previous_duration", "completed_notified"); err != nil {
 			ctx.Error(http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -306,6 +395,42 @@ func Rerun(ctx *context_module.Context) {
 		}
 	}
 
+	// the artifacts uploaded before will be downloaded by the rerun jobs rather than being regenerated
+	if _, err := actions_model.ExtendArtifactsForRerun(ctx, run.ID); err != nil {
+		log.Error("ExtendArtifactsForRerun [run: %d]: %v", run.ID, err)
+	}
+
+	ctx.JSON(http.StatusOK, struct{}{})
+}
+
+// RerunFromJob reruns the given job and the jobs which need it, the other jobs are kept
+func RerunFromJob(ctx *context_module.Context) {
+	runIndex := ctx.ParamsInt64("run")
+	jobIndex := ctx.ParamsInt64("job")
+
+	job, _ := getRunJobs(ctx, runIndex, jobIndex)
+	if ctx.Written() {
+		return
+	}
+	run := job.Run
+
+	// can not rerun job when workflow is disabled
+	cfgUnit := ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions)
+	cfg := cfgUnit.ActionsConfig()
+	if cfg.IsWorkflowDisabled(run.WorkflowID) {
+		ctx.JSONError(ctx.Locale.Tr("actions.workflow.disabled"))
+		return
+	}
+
+	if err := actions_service.RerunFromJob(ctx, run, job.JobID); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) || errors.Is(err, util.ErrNotExist) {
+			ctx.JSONError(err.Error())
+		} else {
+			ctx.Error(http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
 	ctx.JSON(http.StatusOK, struct{}{})
 }
 
@@ -319,15 +444,28 @@ func rerunJob(ctx *context_module.Context, job *actions_model.ActionRunJob) erro
 	job.Status = actions_model.StatusWaiting
 	job.Started = 0
 	job.Stopped = 0
+	cols := []string{"task_id", "status", "started", "stopped"}
+	if job.Gate != nil {
+		// the gate will wait for a decision again once its needs are done
+		job.Status = actions_model.StatusBlocked
+		job.GateDeadline = 0
+		job.GateDecidedBy = 0
+		cols = append(cols, "gate_deadline", "gate_decided_by")
+	}
 
 	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		_, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "task_id", "status", "started", "stopped")
+		_, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, cols...)
 		return err
 	}); err != nil {
 		return err
 	}
 
 	actions_service.CreateCommitStatus(ctx, job)
+	if job.Gate != nil {
+		if err := actions_service.EmitJobsIfReady(job.RunID); err != nil {
+			log.Error("Emit ready jobs of run %d: %v", job.RunID, err)
+		}
+	}
 	return nil
 }
 
@@ -360,7 +498,7 @@ func Logs(ctx *context_module.Context) {
 		return
 	}
 
-	reader, err := actions.OpenLogs(ctx, task.LogInStorage, task.LogFilename)
+	reader, err := actions.OpenTaskLogs(ctx, task)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
@@ -383,41 +521,16 @@ func Logs(ctx *context_module.Context) {
 func Cancel(ctx *context_module.Context) {
 	runIndex := ctx.ParamsInt64("run")
 
-	_, jobs := getRunJobs(ctx, runIndex, -1)
+	current, _ := getRunJobs(ctx, runIndex, -1)
 	if ctx.Written() {
 		return
 	}
 
-	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		for _, job := range jobs {
-			status := job.Status
-			if status.IsDone() {
-				continue
-			}
-			if job.TaskID == 0 {
-				job.Status = actions_model.StatusCancelled
-				job.Stopped = timeutil.TimeStampNow()
-				n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
-				if err != nil {
-					return err
-				}
-				if n == 0 {
-					return fmt.Errorf("job has changed, try again")
-				}
-				continue
-			}
-			if err := actions_model.StopTask(ctx, job.TaskID, actions_model.StatusCancelled); err != nil {
-				return err
-			}
-		}
-		return nil
-	}); err != nil {
+	if err := actions_service.CancelRun(ctx, current.Run); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	actions_service.CreateCommitStatus(ctx, jobs...)
-
 	ctx.JSON(http.StatusOK, struct{}{})
 }
 
@@ -431,28 +544,61 @@ func Approve(ctx *context_module.Context) {
 	run := current.Run
 	doer := ctx.Doer
 
-	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		run.NeedApproval = false
-		run.ApprovedBy = doer.ID
-		if err := actions_model.UpdateRun(ctx, run, "need_approval", "approved_by"); err != nil {
-			return err
-		}
-		for _, job := range jobs {
-			if len(job.Needs) == 0 && job.Status.IsBlocked() {
-				job.Status = actions_model.StatusWaiting
-				_, err := actions_model.UpdateRunJob(ctx, job, nil, "status")
-				if err != nil {
-					return err
-				}
-			}
-		}
-		return nil
-	}); err != nil {
+	if can, err := actions_service.CanApproveRuns(ctx, ctx.Repo.Repository, doer); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
+	} else if !can {
+		ctx.Error(http.StatusForbidden, "no permission to approve the run")
+		return
 	}
 
-	actions_service.CreateCommitStatus(ctx, jobs...)
+	if err := actions_service.ApproveRun(ctx, run, jobs, doer); err != nil {
+		ctx.Error(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, struct{}{})
+}
+
+// ApproveGate approves the manual gate of the job, the run goes on with the jobs which need it
+func ApproveGate(ctx *context_module.Context) {
+	decideGate(ctx, true)
+}
+
+// RejectGate rejects the manual gate of the job, the job fails and the jobs which need it are skipped
+func RejectGate(ctx *context_module.Context) {
+	decideGate(ctx, false)
+}
+
+func decideGate(ctx *context_module.Context, approve bool) {
+	runIndex := ctx.ParamsInt64("run")
+	jobIndex := ctx.ParamsInt64("job")
+
+	job, _ := getRunJobs(ctx, runIndex, jobIndex)
+	if ctx.Written() {
+		return
+	}
+
+	if !job.IsAwaitingGate() {
+		ctx.JSONError(ctx.Locale.Tr("actions.runs.gate.not_awaiting"))
+		return
+	}
+	if can, err := actions_service.CanDecideGate(ctx, job, ctx.Doer); err != nil {
+		ctx.Error(http.StatusInternalServerError, err.Error())
+		return
+	} else if !can {
+		ctx.Error(http.StatusForbidden, "no permission to decide the gate")
+		return
+	}
+
+	if err := actions_service.DecideGate(ctx, job, ctx.Doer, approve); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.JSONError(ctx.Locale.Tr("actions.runs.gate.not_awaiting"))
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	ctx.JSON(http.StatusOK, struct{}{})
 }
@@ -119,7 +119,7 @@ func NewLabel(ctx *context.Context) {
 		Color:        form.Color,
 		ArchivedUnix: timeutil.TimeStamp(0),
 	}
-	if err := issues_model.NewLabel(ctx, l); err != nil {
+	if err := issue_service.NewLabel(ctx, ctx.Doer, l); err != nil {
 		ctx.ServerError("NewLabel", err)
 		return
 	}
@@ -145,7 +145,7 @@ func UpdateLabel(ctx *context.Context) {
 	l.Color = form.Color
 
 	l.SetArchived(form.IsArchived)
-	if err := issues_model.UpdateLabel(ctx, l); err != nil {
+	if err := issue_service.UpdateLabel(ctx, ctx.Doer, l); err != nil {
 		ctx.ServerError("UpdateLabel", err)
 		return
 	}
@@ -154,7 +154,7 @@ func UpdateLabel(ctx *context.Context) {
 
 // DeleteLabel delete a label
 func DeleteLabel(ctx *context.Context) {
-	if err := issues_model.DeleteLabel(ctx, ctx.Repo.Repository.ID, ctx.FormInt64("id")); err != nil {
+	if err := issue_service.DeleteLabel(ctx, ctx.Doer, ctx.Repo.Repository.ID, ctx.FormInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteLabel: " + err.Error())
 	} else {
 		ctx.Flash.Success(ctx.Tr("repo.issues.label_deletion_success"))
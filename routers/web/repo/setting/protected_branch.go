@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/repo"
+	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
@@ -258,6 +259,10 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.ServerError("CheckPRsForBaseBranch", err)
 			return
 		}
+		if err = automerge.MergeScheduledPullRequestsByBaseBranch(ctx, ctx.Repo.Repository, branchName); err != nil {
+			ctx.ServerError("MergeScheduledPullRequestsByBaseBranch", err)
+			return
+		}
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_protect_branch_success", protectBranch.RuleName))
@@ -183,6 +183,7 @@ func ParseHookEvent(form forms.WebhookForm) *webhook_module.HookEvent {
 			Wiki:                     form.Wiki,
 			Repository:               form.Repository,
 			Package:                  form.Package,
+			ActionsSchedule:          form.ActionsSchedule,
 		},
 		BranchFilter: form.BranchFilter,
 	}
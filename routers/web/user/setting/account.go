@@ -7,6 +7,7 @@ package setting
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -170,6 +171,16 @@ func EmailPost(ctx *context.Context) {
 		ctx.Redirect(setting.AppSubURL + "/user/settings/account")
 		return
 	}
+	// Set Actions Run Notification Preference
+	if ctx.FormString("_method") == "ACTIONS_NOTIFICATION" {
+		if err := user_model.SetUserSetting(ctx, ctx.Doer.ID, user_model.SettingsKeyActionsRunNotification, strconv.FormatBool(ctx.FormBool("actions_run_notification"))); err != nil {
+			ctx.ServerError("SetUserSetting", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("settings.actions_run_notification_success"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/account")
+		return
+	}
 
 	if ctx.HasError() {
 		loadAccountData(ctx)
@@ -294,6 +305,12 @@ func loadAccountData(ctx *context.Context) {
 	}
 	ctx.Data["Emails"] = emails
 	ctx.Data["EmailNotificationsPreference"] = ctx.Doer.EmailNotifications()
+	actionsRunNotification, err := user_model.GetUserSetting(ctx, ctx.Doer.ID, user_model.SettingsKeyActionsRunNotification)
+	if err != nil {
+		ctx.ServerError("GetUserSetting", err)
+		return
+	}
+	ctx.Data["ActionsRunNotification"], _ = strconv.ParseBool(actionsRunNotification)
 	ctx.Data["ActivationsPending"] = pendingActivation
 	ctx.Data["CanAddEmails"] = !pendingActivation || !setting.Service.RegisterEmailConfirm
 
@@ -1360,7 +1360,11 @@ func registerRoutes(m *web.Route) {
 						Get(actions.View).
 						Post(web.Bind(actions.ViewRequest{}), actions.ViewPost)
 					m.Post("/rerun", reqRepoActionsWriter, actions.Rerun)
+					m.Post("/rerun_from", reqRepoActionsWriter, actions.RerunFromJob)
 					m.Get("/logs", actions.Logs)
+					// the permission is checked by the handlers, since the reviewers of the gate could be anyone who can read the run
+					m.Post("/gate/approve", actions.ApproveGate)
+					m.Post("/gate/reject", actions.RejectGate)
 				})
 				m.Post("/cancel", reqRepoActionsWriter, actions.Cancel)
 				m.Post("/approve", reqRepoActionsWriter, actions.Approve)
@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+)
+
+// checkActorTeams checks whether the doer is a member of any of the teams required by the `actor-teams` of the workflow,
+// see actions_module.ParseActorTeams. It fails closed: if the doer isn't a member, or the membership can't be determined,
+// like the team doesn't exist, it returns false with the reason, and the run should be skipped.
+func checkActorTeams(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, teams []string) (bool, string) {
+	if len(teams) == 0 {
+		return true, ""
+	}
+	var problems []string
+	for _, ref := range teams {
+		isMember, err := isTeamMember(ctx, repo, doer, ref)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if isMember {
+			return true, ""
+		}
+	}
+	reason := fmt.Sprintf("%s is not a member of any of the teams %v", doer.Name, teams)
+	if len(problems) > 0 {
+		reason += ", the membership can't be determined: " + strings.Join(problems, "; ")
+	}
+	return false, reason
+}
+
+// isTeamMember checks whether the doer is a member of the team referenced like `team` or `org/team`,
+// a team without an organization is a team of the owner of the repository
+func isTeamMember(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, ref string) (bool, error) {
+	orgName, teamName, hasOrg := strings.Cut(ref, "/")
+	if !hasOrg {
+		if err := repo.LoadOwner(ctx); err != nil {
+			return false, fmt.Errorf("LoadOwner: %w", err)
+		}
+		if !repo.Owner.IsOrganization() {
+			return false, fmt.Errorf("team %q: the owner of the repository is not an organization", ref)
+		}
+		orgName, teamName = repo.Owner.Name, ref
+	}
+	org, err := organization.GetOrgByName(ctx, orgName)
+	if err != nil {
+		return false, fmt.Errorf("team %q: GetOrgByName: %w", ref, err)
+	}
+	team, err := organization.GetTeam(ctx, org.ID, teamName)
+	if err != nil {
+		return false, fmt.Errorf("team %q: GetTeam: %w", ref, err)
+	}
+	return organization.IsTeamMember(ctx, org.ID, team.ID, doer.ID)
+}
@@ -0,0 +1,363 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ApproveRun approves a run which needs approval explicitly, its blocked jobs without needs will be waiting to run.
+func ApproveRun(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob, doer *user_model.User) error {
+	return approveRun(ctx, run, jobs, doer, false)
+}
+
+// approveRun approves a run which needs approval, byLabel is whether it's approved by the approval label rather than explicitly
+func approveRun(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob, doer *user_model.User, byLabel bool) error {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		run.NeedApproval = false
+		run.ApprovedBy = doer.ID
+		run.ApprovedByLabel = byLabel
+		if err := actions_model.UpdateRun(ctx, run, "need_approval", "approved_by", "approved_by_label"); err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if len(job.Needs) == 0 && job.Gate != nil {
+				// gates never run on runners, they wait for decisions instead
+				if err := actions_model.ReachGate(ctx, job); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(job.Needs) == 0 && job.Status.IsBlocked() {
+				job.Status = actions_model.StatusWaiting
+				_, err := actions_model.UpdateRunJob(ctx, job, nil, "status")
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	exportRunAuditEvent(ctx, runAuditActionApproved, run, jobs)
+	CreateCommitStatus(ctx, jobs...)
+	return nil
+}
+
+// CanApproveRuns reports whether the doer can approve the runs of the repository which need approval, like the runs of fork pull requests.
+// The doer needs write permission for actions, and must be one of the approval reviewers too if they are configured, see approvalReviewers.
+func CanApproveRuns(ctx context.Context, repo *repo_model.Repository, doer *user_model.User) (bool, error) {
+	if doer == nil {
+		return false, nil
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %w", err)
+	} else if !perm.CanWrite(unit_model.TypeActions) {
+		return false, nil
+	}
+
+	reviewers, err := approvalReviewers(ctx, repo)
+	if err != nil {
+		return false, err
+	} else if len(reviewers) == 0 {
+		return true, nil
+	}
+	return isApprovalReviewer(ctx, reviewers, doer)
+}
+
+// approvalReviewers returns the users and the teams like "org/team" who can approve the runs of the repository.
+// The reviewers of the repository take precedence over the ones of its owner, none means any user with write permission can approve.
+func approvalReviewers(ctx context.Context, repo *repo_model.Repository) ([]string, error) {
+	if unit, err := repo.GetUnit(ctx, unit_model.TypeActions); err == nil {
+		if reviewers := unit.ActionsConfig().ApprovalReviewers; len(reviewers) > 0 {
+			return reviewers, nil
+		}
+	}
+	value, err := user_model.GetUserSetting(ctx, repo.OwnerID, user_model.SettingsKeyActionsApprovalReviewers)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserSetting: %w", err)
+	}
+	return parseApprovalReviewers(value), nil
+}
+
+// parseApprovalReviewers parses the comma-separated reviewers of the owner setting
+func parseApprovalReviewers(value string) []string {
+	var reviewers []string
+	for _, reviewer := range strings.Split(value, ",") {
+		if reviewer = strings.TrimSpace(reviewer); reviewer != "" {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+	return reviewers
+}
+
+// isApprovalReviewer reports whether the doer is one of the reviewers or a member of one of the teams,
+// the reviewers which don't exist any longer are ignored.
+func isApprovalReviewer(ctx context.Context, reviewers []string, doer *user_model.User) (bool, error) {
+	for _, reviewer := range reviewers {
+		orgName, teamName, isTeam := strings.Cut(reviewer, "/")
+		if !isTeam {
+			if strings.EqualFold(reviewer, doer.Name) {
+				return true, nil
+			}
+			continue
+		}
+
+		org, err := user_model.GetUserByName(ctx, orgName)
+		if user_model.IsErrUserNotExist(err) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("GetUserByName: %w", err)
+		}
+		team, err := organization.GetTeam(ctx, org.ID, teamName)
+		if organization.IsErrTeamNotExist(err) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("GetTeam: %w", err)
+		}
+		if isMember, err := organization.IsTeamMember(ctx, org.ID, team.ID, doer.ID); err != nil {
+			return false, fmt.Errorf("IsTeamMember: %w", err)
+		} else if isMember {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// labelApprover returns the user who has approved the commit of the pull request from a fork by the approval label, or nil if it hasn't been approved.
+// The approval only counts if the label is still on the pull request and its approver is still allowed to approve the runs,
+// and it only approves the head commit of the pull request when the label was added, see actions_model.ActionLabelApproval.
+func labelApprover(ctx context.Context, repo *repo_model.Repository, pr *issues_model.PullRequest, commitSHA string) (*user_model.User, error) {
+	if setting.Actions.ApprovalLabel == "" || pr == nil {
+		return nil, nil
+	}
+	approval, err := actions_model.GetLabelApproval(ctx, pr.IssueID)
+	if err != nil {
+		return nil, fmt.Errorf("GetLabelApproval: %w", err)
+	} else if approval == nil || approval.CommitSHA != commitSHA {
+		return nil, nil
+	}
+	if labeled, err := hasApprovalLabel(ctx, pr); err != nil || !labeled {
+		return nil, err
+	}
+	approver, err := user_model.GetUserByID(ctx, approval.ApproverID)
+	if user_model.IsErrUserNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("GetUserByID: %w", err)
+	}
+	if can, err := CanApproveRuns(ctx, repo, approver); err != nil || !can {
+		return nil, err
+	}
+	return approver, nil
+}
+
+// isLabelApprovable reports whether the run which needs approval could be approved by the approval label,
+// the label only approves the runs of the pull requests from forks, the runs needing approval for other reasons should be approved explicitly.
+func isLabelApprovable(run *actions_model.ActionRun) bool {
+	return run.IsForkPullRequest && !(run.IsProduction && setting.Actions.ProductionApproval) && !(run.IsProtectedTag && setting.Actions.ProtectedTagApproval)
+}
+
+// pullRequestHeadSHA returns the head commit of the pull request
+func pullRequestHeadSHA(ctx context.Context, repo *repo_model.Repository, pr *issues_model.PullRequest) (string, error) {
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return "", fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+	return gitRepo.GetRefCommitID(pr.GetGitRefName())
+}
+
+// hasApprovalLabel reports whether the pull request has the label which approves the runs from forks, see setting.Actions.ApprovalLabel
+func hasApprovalLabel(ctx context.Context, pr *issues_model.PullRequest) (bool, error) {
+	if setting.Actions.ApprovalLabel == "" || pr == nil {
+		return false, nil
+	}
+	if err := pr.LoadIssue(ctx); err != nil {
+		return false, fmt.Errorf("LoadIssue: %w", err)
+	}
+	if err := pr.Issue.LoadLabels(ctx); err != nil {
+		return false, fmt.Errorf("LoadLabels: %w", err)
+	}
+	return containsApprovalLabel(pr.Issue.Labels), nil
+}
+
+func containsApprovalLabel(labels []*issues_model.Label) bool {
+	for _, label := range labels {
+		if label.Name == setting.Actions.ApprovalLabel {
+			return true
+		}
+	}
+	return false
+}
+
+// handleApprovalLabelChanged approves the head commit of the pull request when the approval label is added by a user who can approve the runs,
+// its runs waiting for approval since they are from a fork are approved, and so are its runs created later until the label is removed.
+// Removing the label cancels the runs approved by the label which haven't started yet, the runs approved explicitly are kept.
+func handleApprovalLabelChanged(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, addedLabels, removedLabels []*issues_model.Label) error {
+	if setting.Actions.ApprovalLabel == "" || doer.IsActions() {
+		return nil
+	}
+
+	added, removed := containsApprovalLabel(addedLabels), containsApprovalLabel(removedLabels)
+	if !added && !removed {
+		return nil
+	}
+
+	if err := pr.LoadIssue(ctx); err != nil {
+		return fmt.Errorf("LoadIssue: %w", err)
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		return fmt.Errorf("LoadRepo: %w", err)
+	}
+	repo := pr.Issue.Repo
+
+	runs, err := db.Find[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+		RepoID: repo.ID,
+		Ref:    pr.GetGitRefName(),
+		Status: []actions_model.Status{actions_model.StatusWaiting, actions_model.StatusBlocked},
+	})
+	if err != nil {
+		return fmt.Errorf("FindRuns: %w", err)
+	}
+
+	if added {
+		// the label only approves the runs if the doer is allowed to approve them in the UI
+		if can, err := CanApproveRuns(ctx, repo, doer); err != nil {
+			return fmt.Errorf("CanApproveRuns: %w", err)
+		} else if !can {
+			log.Trace("ignore approval label added by user %d who can't approve runs", doer.ID)
+			return nil
+		}
+
+		headSHA, err := pullRequestHeadSHA(ctx, repo, pr)
+		if err != nil {
+			return fmt.Errorf("pullRequestHeadSHA: %w", err)
+		}
+		if err := actions_model.SetLabelApproval(ctx, &actions_model.ActionLabelApproval{
+			RepoID:     repo.ID,
+			IssueID:    pr.IssueID,
+			CommitSHA:  headSHA,
+			ApproverID: doer.ID,
+		}); err != nil {
+			return fmt.Errorf("SetLabelApproval: %w", err)
+		}
+
+		for _, run := range runs {
+			if !run.NeedApproval || run.CommitSHA != headSHA || !isLabelApprovable(run) {
+				continue
+			}
+			if err := approveRunByID(ctx, run, doer, true); err != nil {
+				return err
+			}
+			log.Trace("run %d of repo %d has been approved by label from user %d", run.ID, repo.ID, doer.ID)
+		}
+		return nil
+	}
+
+	if err := actions_model.DeleteLabelApproval(ctx, pr.IssueID); err != nil {
+		return fmt.Errorf("DeleteLabelApproval: %w", err)
+	}
+	for _, run := range runs {
+		if !run.ApprovedByLabel {
+			continue
+		}
+		jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+		if err != nil {
+			return fmt.Errorf("GetRunJobsByRunID: %w", err)
+		}
+		if err := db.WithTx(ctx, func(ctx context.Context) error {
+			return actions_model.CancelJobs(ctx, jobs)
+		}); err != nil {
+			return fmt.Errorf("CancelJobs: %w", err)
+		}
+		CreateCommitStatus(ctx, jobs...)
+		log.Trace("run %d of repo %d has been cancelled since the approval label was removed", run.ID, repo.ID)
+	}
+	return nil
+}
+
+func approveRunByID(ctx context.Context, run *actions_model.ActionRun, doer *user_model.User, byLabel bool) error {
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	if err := approveRun(ctx, run, jobs, doer, byLabel); err != nil {
+		return fmt.Errorf("ApproveRun: %w", err)
+	}
+	return nil
+}
+
+// isApprovalCommand reports whether any line of the comment is the command which approves the runs, see setting.Actions.ApprovalCommand
+func isApprovalCommand(content string) bool {
+	if setting.Actions.ApprovalCommand == "" {
+		return false
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == setting.Actions.ApprovalCommand {
+			return true
+		}
+	}
+	return false
+}
+
+// handleApprovalCommand approves the runs of the pull request waiting for approval when the approval command is commented
+// by a user who is allowed to approve them in the UI. The runs of other pull requests are not affected.
+func handleApprovalCommand(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, comment *issues_model.Comment) error {
+	if doer.IsActions() || !isApprovalCommand(comment.Content) {
+		return nil
+	}
+
+	if err := pr.LoadIssue(ctx); err != nil {
+		return fmt.Errorf("LoadIssue: %w", err)
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		return fmt.Errorf("LoadRepo: %w", err)
+	}
+	repo := pr.Issue.Repo
+
+	if can, err := CanApproveRuns(ctx, repo, doer); err != nil {
+		return fmt.Errorf("CanApproveRuns: %w", err)
+	} else if !can {
+		log.Trace("ignore approval command commented by user %d who can't approve runs", doer.ID)
+		return nil
+	}
+
+	runs, err := db.Find[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+		RepoID: repo.ID,
+		Ref:    pr.GetGitRefName(),
+		Status: []actions_model.Status{actions_model.StatusWaiting, actions_model.StatusBlocked},
+	})
+	if err != nil {
+		return fmt.Errorf("FindRuns: %w", err)
+	}
+	for _, run := range runs {
+		if !run.NeedApproval {
+			continue
+		}
+		// the approver is recorded on the run like the approvals in the UI
+		if err := approveRunByID(ctx, run, doer, false); err != nil {
+			return err
+		}
+		log.Info("run %d of repo %s has been approved by %s with the approval command in comment %d of pull request #%d", run.Index, repo.FullName(), doer.Name, comment.ID, pr.Index)
+	}
+	return nil
+}
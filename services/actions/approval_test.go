@@ -0,0 +1,214 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isApprovalCommand(t *testing.T) {
+	assert.False(t, isApprovalCommand("/approve-ci"))
+
+	defer test.MockVariableValue(&setting.Actions.ApprovalCommand, "/approve-ci")()
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{content: "/approve-ci", want: true},
+		{content: "  /approve-ci  ", want: true},
+		{content: "LGTM\n/approve-ci\n", want: true},
+		{content: "LGTM\r\n/approve-ci\r\n", want: true},
+		{content: "please /approve-ci", want: false},
+		{content: "/approve-ci-later", want: false},
+		{content: "> /approve-ci", want: false},
+		{content: "", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isApprovalCommand(tt.content), "content: %q", tt.content)
+	}
+}
+
+func Test_parseApprovalReviewers(t *testing.T) {
+	assert.Nil(t, parseApprovalReviewers(""))
+	assert.Equal(t, []string{"alice", "org3/reviewers"}, parseApprovalReviewers(" alice, ,org3/reviewers ,"))
+}
+
+func Test_isApprovalReviewer(t *testing.T) {
+	doer := &user_model.User{ID: 2, Name: "Alice"}
+
+	is, err := isApprovalReviewer(context.Background(), []string{"bob", "alice"}, doer)
+	assert.NoError(t, err)
+	assert.True(t, is)
+
+	is, err = isApprovalReviewer(context.Background(), []string{"bob"}, doer)
+	assert.NoError(t, err)
+	assert.False(t, is)
+}
+
+func Test_handleApprovalLabelChanged(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.ApprovalLabel, "label1")()
+	defer test.MockVariableValue(&setting.Actions.ProductionApproval, true)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 2})
+	label := unittest.AssertExistsAndLoadBean(t, &issues_model.Label{ID: 1})
+	maintainer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	reader := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+	headSHA, err := pullRequestHeadSHA(db.DefaultContext, repo, pr)
+	require.NoError(t, err)
+	const oldSHA = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+	index := int64(1000)
+	newRun := func(commitSHA string, modify func(run *actions_model.ActionRun)) *actions_model.ActionRun {
+		index++
+		run := &actions_model.ActionRun{
+			RepoID:            repo.ID,
+			OwnerID:           repo.OwnerID,
+			Index:             index,
+			WorkflowID:        "test.yml",
+			TriggerUserID:     5,
+			Ref:               pr.GetGitRefName(),
+			CommitSHA:         commitSHA,
+			TriggerEvent:      "pull_request",
+			IsForkPullRequest: true,
+			NeedApproval:      true,
+			Status:            actions_model.StatusWaiting,
+		}
+		if modify != nil {
+			modify(run)
+		}
+		require.NoError(t, db.Insert(db.DefaultContext, run))
+		require.NoError(t, db.Insert(db.DefaultContext, &actions_model.ActionRunJob{
+			RunID:     run.ID,
+			RepoID:    run.RepoID,
+			OwnerID:   run.OwnerID,
+			CommitSHA: run.CommitSHA,
+			Name:      "test",
+			JobID:     "test",
+			Status:    actions_model.StatusBlocked,
+		}))
+		return run
+	}
+	forkRun := newRun(headSHA, nil)
+	oldRun := newRun(oldSHA, nil)
+	productionRun := newRun(headSHA, func(run *actions_model.ActionRun) { run.IsProduction = true })
+	approvedRun := newRun(headSHA, func(run *actions_model.ActionRun) {
+		run.TriggerUserID, run.NeedApproval, run.ApprovedBy = 4, false, maintainer.ID
+	})
+	reload := func(run *actions_model.ActionRun) *actions_model.ActionRun {
+		return unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID})
+	}
+	jobStatus := func(run *actions_model.ActionRun) actions_model.Status {
+		return unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: run.ID}).Status
+	}
+
+	t.Run("added by a user who can't approve", func(t *testing.T) {
+		require.NoError(t, handleApprovalLabelChanged(db.DefaultContext, reader, pr, []*issues_model.Label{label}, nil))
+		assert.True(t, reload(forkRun).NeedApproval)
+		unittest.AssertNotExistsBean(t, &actions_model.ActionLabelApproval{IssueID: pr.IssueID})
+	})
+
+	t.Run("added", func(t *testing.T) {
+		require.NoError(t, handleApprovalLabelChanged(db.DefaultContext, maintainer, pr, []*issues_model.Label{label}, nil))
+		unittest.AssertExistsAndLoadBean(t, &actions_model.ActionLabelApproval{IssueID: pr.IssueID, CommitSHA: headSHA, ApproverID: maintainer.ID})
+
+		run := reload(forkRun)
+		assert.False(t, run.NeedApproval)
+		assert.Equal(t, actions_model.StatusWaiting, jobStatus(run))
+		assert.Equal(t, maintainer.ID, run.ApprovedBy)
+		assert.True(t, run.ApprovedByLabel)
+		// the label only approves the head commit
+		assert.True(t, reload(oldRun).NeedApproval)
+		// the label only approves the runs needing approval since they are from forks
+		assert.True(t, reload(productionRun).NeedApproval)
+	})
+
+	t.Run("later commits", func(t *testing.T) {
+		require.NoError(t, db.Insert(db.DefaultContext, &issues_model.IssueLabel{IssueID: pr.IssueID, LabelID: label.ID}))
+		pr.Issue = nil
+
+		approver, err := labelApprover(db.DefaultContext, repo, pr, headSHA)
+		require.NoError(t, err)
+		require.NotNil(t, approver)
+		assert.Equal(t, maintainer.ID, approver.ID)
+
+		// the commits pushed after the label was added need approval again
+		approver, err = labelApprover(db.DefaultContext, repo, pr, "985f0301dba5e7b34be866819cd15ad3d8f508ee")
+		require.NoError(t, err)
+		assert.Nil(t, approver)
+
+		// the runs approved by the label don't approve the trigger user for the later runs
+		need, err := ifNeedApproval(db.DefaultContext, newRun(headSHA, nil), repo, reader)
+		require.NoError(t, err)
+		assert.True(t, need)
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		require.NoError(t, handleApprovalLabelChanged(db.DefaultContext, maintainer, pr, nil, []*issues_model.Label{label}))
+		unittest.AssertNotExistsBean(t, &actions_model.ActionLabelApproval{IssueID: pr.IssueID})
+		assert.Equal(t, actions_model.StatusCancelled, jobStatus(forkRun))
+		// the runs approved explicitly are kept
+		assert.Equal(t, actions_model.StatusBlocked, jobStatus(approvedRun))
+	})
+}
+
+func Test_handleApprovalCommand(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.ApprovalCommand, "/approve-ci")()
+
+	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 2})
+	maintainer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	reader := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+	run := &actions_model.ActionRun{
+		RepoID:            1,
+		OwnerID:           2,
+		Index:             1001,
+		WorkflowID:        "test.yml",
+		TriggerUserID:     5,
+		Ref:               pr.GetGitRefName(),
+		CommitSHA:         "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		TriggerEvent:      "pull_request",
+		IsForkPullRequest: true,
+		NeedApproval:      true,
+		Status:            actions_model.StatusWaiting,
+	}
+	require.NoError(t, db.Insert(db.DefaultContext, run))
+	comment := &issues_model.Comment{ID: 1000, Content: "LGTM\n/approve-ci"}
+	notices := unittest.GetCount(t, &system_model.Notice{})
+	exporter := newRunEventExporter("audit event", &recordAuditSink{}, 1)
+	defer test.MockVariableValue(&auditExporter, exporter)()
+
+	require.NoError(t, handleApprovalCommand(db.DefaultContext, reader, pr, comment))
+	assert.True(t, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID}).NeedApproval)
+
+	require.NoError(t, handleApprovalCommand(db.DefaultContext, maintainer, pr, comment))
+	run = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID})
+	assert.False(t, run.NeedApproval)
+	assert.Equal(t, maintainer.ID, run.ApprovedBy)
+	assert.False(t, run.ApprovedByLabel)
+	unittest.AssertCount(t, &system_model.Notice{}, notices)
+
+	// the approval is exported as an audit event
+	var event runAuditEvent
+	require.NoError(t, json.Unmarshal(<-exporter.events, &event))
+	assert.Equal(t, runAuditActionApproved, event.Action)
+	assert.Equal(t, run.ID, event.RunID)
+	assert.Equal(t, maintainer.ID, event.ApprovedBy)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// artifactQuota returns the total size in bytes of the artifacts of the repository, zero means unlimited
+func artifactQuota(ctx context.Context, repo *repo_model.Repository) (int64, error) {
+	actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions)
+	if err != nil {
+		if repo_model.IsErrUnitTypeNotExist(err) {
+			return (&repo_model.ActionsConfig{}).GetArtifactQuota(), nil
+		}
+		return 0, fmt.Errorf("GetUnit: %w", err)
+	}
+	return actionsUnit.ActionsConfig().GetArtifactQuota(), nil
+}
+
+// EvictArtifacts evicts the oldest artifacts of the repository, so a new artifact of incoming bytes could be stored
+// without exceeding the artifact quota of the repository, see repo_model.ActionsConfig.GetArtifactQuota.
+// The artifact of excludeID is the one being uploaded. The evicted artifacts are expired like the ones beyond their retention days.
+// It returns actions_model.ErrArtifactQuotaExceeded if the quota would be exceeded anyway.
+func EvictArtifacts(ctx context.Context, repo *repo_model.Repository, incoming, excludeID int64) error {
+	quota, err := artifactQuota(ctx, repo)
+	if err != nil {
+		return err
+	}
+	artifacts, err := actions_model.FindArtifactsToEvict(ctx, repo.ID, quota, incoming, excludeID)
+	if err != nil {
+		return fmt.Errorf("FindArtifactsToEvict: %w", err)
+	}
+	for _, artifact := range artifacts {
+		if err := storage.ActionsArtifacts.Delete(artifact.StoragePath); err != nil {
+			return fmt.Errorf("delete artifact %d: %w", artifact.ID, err)
+		}
+		if err := actions_model.SetArtifactExpired(ctx, artifact.ID); err != nil {
+			return fmt.Errorf("SetArtifactExpired: %w", err)
+		}
+		log.Info("Artifact %d of repo %s has been evicted since the artifact quota is exceeded", artifact.ID, repo.FullName())
+	}
+	return nil
+}
+
+// EvictArtifactsOverQuota evicts the oldest artifacts of the repositories whose artifacts exceed their quotas,
+// like after the quotas are lowered.
+func EvictArtifactsOverQuota(ctx context.Context) error {
+	usages, err := actions_model.ListArtifactsUsages(ctx)
+	if err != nil {
+		return fmt.Errorf("ListArtifactsUsages: %w", err)
+	}
+	for _, usage := range usages {
+		repo, err := repo_model.GetRepositoryByID(ctx, usage.RepoID)
+		if err != nil {
+			log.Error("GetRepositoryByID %d: %v", usage.RepoID, err)
+			continue
+		}
+		quota, err := artifactQuota(ctx, repo)
+		if err != nil {
+			log.Error("artifactQuota of repo %s: %v", repo.FullName(), err)
+			continue
+		}
+		if quota <= 0 || usage.Size <= quota {
+			continue
+		}
+		if err := EvictArtifacts(ctx, repo, 0, 0); err != nil {
+			var errQuota actions_model.ErrArtifactQuotaExceeded
+			if errors.As(err, &errQuota) {
+				// the artifacts of the unfinished runs are evicted in a later cleanup
+				log.Warn("The artifacts of repo %s exceed the quota: %v", repo.FullName(), err)
+			} else {
+				log.Error("EvictArtifacts of repo %s: %v", repo.FullName(), err)
+			}
+		}
+	}
+	return nil
+}
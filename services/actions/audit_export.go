@@ -0,0 +1,378 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	runAuditActionCreated   = "created"   // the run has been created
+	runAuditActionApproved  = "approved"  // the run which needed approval has been approved, see runAuditEvent.ApprovedBy
+	runAuditActionCompleted = "completed" // the run has been done
+)
+
+// auditExportTimeout is the timeout of every attempt to export an event to the sink, like the webhook or the syslog server
+const auditExportTimeout = 10 * time.Second
+
+// runAuditEvent is the metadata of a run which is exported for audit and forensics, see setting.Actions.AuditExporter
+type runAuditEvent struct {
+	Time         time.Time      `json:"time"`
+	Action       string         `json:"action"`
+	RunID        int64          `json:"run_id"`
+	RunNumber    int64          `json:"run_number"`
+	URL          string         `json:"url"`
+	Repository   string         `json:"repository"`
+	Workflow     string         `json:"workflow"`
+	Event        string         `json:"event"`
+	TriggerEvent string         `json:"trigger_event"`
+	Ref          string         `json:"ref"`
+	CommitSHA    string         `json:"commit_sha"`
+	Actor        string         `json:"actor"`
+	Trusted      bool           `json:"trusted"` // false if the run is from a fork pull request or an untrusted user, its token is read-only
+	NeedApproval bool           `json:"need_approval"`
+	ApprovedBy   int64          `json:"approved_by,omitempty"` // the id of the user who approved the run
+	IsProduction bool           `json:"is_production"`
+	Status       string         `json:"status"`
+	Jobs         []*runAuditJob `json:"jobs"`
+	Errors       []string       `json:"errors,omitempty"`
+	Duration     float64        `json:"duration_seconds,omitempty"` // only for the completed runs
+}
+
+// runAuditJob is the metadata of a job of the run, including the permission of its token
+type runAuditJob struct {
+	JobID           string `json:"job_id"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	TokenPermission string `json:"token_permission"`
+}
+
+// auditSink is where the audit events are exported to, it must be safe for concurrent use
+type auditSink interface {
+	Send(ctx context.Context, event []byte) error
+	Close() error
+}
+
+// runEventExporter exports the events of runs to the sink in the background, like the audit events or the run events.
+// The events are buffered and dropped if the buffer is full, and every event is retried with backoff if it fails to be exported,
+// so the runs are never blocked by a slow sink.
+type runEventExporter struct {
+	name    string // what the events are, like "audit event", for the logs
+	sink    auditSink
+	events  chan []byte
+	dropped atomic.Int64
+}
+
+var auditExporter *runEventExporter
+
+func newRunEventExporter(name string, sink auditSink, buffer int) *runEventExporter {
+	return &runEventExporter{
+		name:   name,
+		sink:   sink,
+		events: make(chan []byte, buffer),
+	}
+}
+
+func initAuditExporter() {
+	if setting.Actions.AuditExporter == setting.AuditExporterNone {
+		auditExporter = nil
+		return
+	}
+	sink, err := newAuditSink(setting.Actions.AuditExporter, setting.Actions.AuditExportTarget)
+	if err != nil {
+		log.Fatal("Unable to init actions audit exporter: %v", err)
+	}
+	auditExporter = newRunEventExporter("audit event", sink, setting.Actions.AuditExportBuffer)
+	auditExporter.start("actions_audit_events_dropped_total", "Number of the audit events of runs which are dropped since the export buffer is full")
+}
+
+// start exports the events until Gitea shuts down, the number of the dropped events is exposed as the metric if the metrics are enabled
+func (e *runEventExporter) start(metric, help string) {
+	if setting.Metrics.Enabled {
+		prometheus.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "gitea",
+			Name:      metric,
+			Help:      help,
+		}, func() float64 {
+			return float64(e.Dropped())
+		}))
+	}
+	go graceful.GetManager().RunWithShutdownContext(e.run)
+}
+
+// enqueue adds the event to the buffer without blocking, it returns false if the event is dropped
+func (e *runEventExporter) enqueue(event []byte) bool {
+	select {
+	case e.events <- event:
+		return true
+	default:
+		e.dropped.Add(1)
+		return false
+	}
+}
+
+// export adds the event of the run to the buffer without blocking
+func (e *runEventExporter) export(runID int64, v any) {
+	event, err := json.Marshal(v)
+	if err != nil {
+		log.Error("json.Marshal: %v", err)
+		return
+	}
+	if !e.enqueue(event) {
+		log.Trace("the %s of run %d is dropped, %d events have been dropped", e.name, runID, e.Dropped())
+	}
+}
+
+// Dropped returns the number of the events which are dropped since the buffer is full
+func (e *runEventExporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+func (e *runEventExporter) run(ctx context.Context) {
+	defer e.sink.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-e.events:
+			// the failures of the sink are retried whatever they are, the events are exported at least once
+			if err := retryIf(ctx, "export "+e.name, func(error) bool { return true }, func() error {
+				sendCtx, cancel := context.WithTimeout(ctx, auditExportTimeout)
+				defer cancel()
+				return e.sink.Send(sendCtx, event)
+			}); err != nil {
+				log.Warn("unable to export the %s of run, it's dropped: %v", e.name, err)
+			}
+		}
+	}
+}
+
+func init() {
+	registerRunCompletedHook("audit_export", exportRunCompletedAuditEvent)
+}
+
+// exportRunAuditEvent exports the metadata of the run, if setting.Actions.AuditExporter is set.
+// It doesn't wait for the event to be exported, and the event is dropped if the buffer of the exporter is full.
+func exportRunAuditEvent(ctx context.Context, action string, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) {
+	if auditExporter == nil {
+		return
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		log.Error("LoadAttributes of run %d: %v", run.ID, err)
+		return
+	}
+	auditExporter.export(run.ID, newRunAuditEvent(action, run, jobs))
+}
+
+// exportRunCompletedAuditEvent exports the metadata of the completed run like exportRunAuditEvent,
+// only loading the jobs of the run fails the hook to be retried.
+func exportRunCompletedAuditEvent(ctx context.Context, run *actions_model.ActionRun) error {
+	if auditExporter == nil {
+		return nil
+	}
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	exportRunAuditEvent(ctx, runAuditActionCompleted, run, jobs)
+	return nil
+}
+
+// newRunAuditEvent returns the audit event of the run, the repository and the trigger user of the run should have been loaded
+func newRunAuditEvent(action string, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) *runAuditEvent {
+	event := &runAuditEvent{
+		Time:         time.Now().UTC(),
+		Action:       action,
+		RunID:        run.ID,
+		RunNumber:    run.Index,
+		URL:          run.HTMLURL(),
+		Workflow:     run.WorkflowID,
+		Event:        string(run.Event),
+		TriggerEvent: run.TriggerEvent,
+		Ref:          run.Ref,
+		CommitSHA:    run.CommitSHA,
+		Trusted:      !run.IsForkPullRequest,
+		NeedApproval: run.NeedApproval,
+		ApprovedBy:   run.ApprovedBy,
+		IsProduction: run.IsProduction,
+		Status:       run.Status.String(),
+		Jobs:         make([]*runAuditJob, 0, len(jobs)),
+		Errors:       run.Errors,
+	}
+	if run.Repo != nil {
+		event.Repository = run.Repo.FullName()
+	}
+	if run.TriggerUser != nil {
+		event.Actor = run.TriggerUser.Name
+	}
+	if action == runAuditActionCompleted {
+		event.Duration = run.Duration().Seconds()
+	}
+	for _, job := range jobs {
+		permission := actions_model.ResolveTokenAccessMode(run.IsForkPullRequest, job.TokenPermission)
+		event.Jobs = append(event.Jobs, &runAuditJob{
+			JobID:           job.JobID,
+			Name:            job.Name,
+			Status:          job.Status.String(),
+			TokenPermission: permission.String(),
+		})
+	}
+	return event
+}
+
+// newAuditSink creates the sink of the exporter, see the values of setting.Actions.AuditExporter
+func newAuditSink(exporter, target string) (auditSink, error) {
+	switch exporter {
+	case setting.AuditExporterWebhook:
+		return &webhookAuditSink{
+			url: target,
+			client: &http.Client{
+				Transport: &http.Transport{
+					Proxy: proxy.Proxy(),
+				},
+			},
+		}, nil
+	case setting.AuditExporterFile:
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(setting.AppDataPath, target)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		return &fileAuditSink{file: f}, nil
+	case setting.AuditExporterSyslog:
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid syslog address %q: %w", target, err)
+		}
+		switch u.Scheme {
+		case "udp", "tcp":
+			return &syslogAuditSink{network: u.Scheme, address: u.Host}, nil
+		case "unix", "unixgram":
+			return &syslogAuditSink{network: u.Scheme, address: u.Path}, nil
+		default:
+			return nil, fmt.Errorf("unsupported network of syslog address %q", target)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported audit exporter %q", exporter)
+	}
+}
+
+// webhookAuditSink posts the events to the URL
+type webhookAuditSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookAuditSink) Send(ctx context.Context, event []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(event))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("the audit webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookAuditSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// fileAuditSink appends the events to the file as JSON lines
+type fileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (s *fileAuditSink) Send(_ context.Context, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.file.Write(append(event, '\n'))
+	return err
+}
+
+func (s *fileAuditSink) Close() error {
+	return s.file.Close()
+}
+
+// syslogAuditSink sends the events to the syslog server in the format of RFC 5424,
+// the connection is established again if it fails.
+type syslogAuditSink struct {
+	network string
+	address string
+	mu      sync.Mutex
+	conn    net.Conn
+}
+
+// syslogPriority is the priority of the events, the facility is local0 and the severity is informational
+const syslogPriority = 16*8 + 6
+
+func (s *syslogAuditSink) Send(ctx context.Context, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s gitea - actions-audit - %s\n", syslogPriority, time.Now().UTC().Format(time.RFC3339), hostname, event)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *syslogAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordAuditSink struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (s *recordAuditSink) Send(_ context.Context, event []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, string(event))
+	return nil
+}
+
+func (s *recordAuditSink) Close() error {
+	return nil
+}
+
+func (s *recordAuditSink) Events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.events...)
+}
+
+func Test_runEventExporter(t *testing.T) {
+	sink := &recordAuditSink{}
+	exporter := newRunEventExporter("audit event", sink, 2)
+
+	// the buffer is full while the exporter isn't running, so the extra events are dropped without blocking
+	assert.True(t, exporter.enqueue([]byte(`{"run_id":1}`)))
+	assert.True(t, exporter.enqueue([]byte(`{"run_id":2}`)))
+	assert.False(t, exporter.enqueue([]byte(`{"run_id":3}`)))
+	assert.False(t, exporter.enqueue([]byte(`{"run_id":4}`)))
+	assert.EqualValues(t, 2, exporter.Dropped())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return len(sink.Events()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{`{"run_id":1}`, `{"run_id":2}`}, sink.Events())
+
+	assert.True(t, exporter.enqueue([]byte(`{"run_id":5}`)))
+	assert.Eventually(t, func() bool { return len(sink.Events()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	assert.EqualValues(t, 2, exporter.Dropped())
+}
+
+func Test_exportRunCompletedAuditEvent(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	exporter := newRunEventExporter("audit event", &recordAuditSink{}, 1)
+	defer test.MockVariableValue(&auditExporter, exporter)()
+	run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: 791})
+
+	// the event of the completed run is buffered like the others, and dropped without blocking if the buffer is full
+	require.NoError(t, exportRunCompletedAuditEvent(db.DefaultContext, run))
+	require.NoError(t, exportRunCompletedAuditEvent(db.DefaultContext, run))
+	assert.EqualValues(t, 1, exporter.Dropped())
+	var event runAuditEvent
+	require.NoError(t, json.Unmarshal(<-exporter.events, &event))
+	assert.Equal(t, runAuditActionCompleted, event.Action)
+	assert.EqualValues(t, 791, event.RunID)
+}
+
+func Test_newRunAuditEvent(t *testing.T) {
+	run := &actions_model.ActionRun{
+		ID:                10,
+		Index:             3,
+		Repo:              &repo_model.Repository{OwnerName: "user2", Name: "repo1"},
+		TriggerUser:       &user_model.User{Name: "user5"},
+		WorkflowID:        "test.yml",
+		Event:             "pull_request",
+		TriggerEvent:      "pull_request",
+		Ref:               "refs/pull/2/head",
+		CommitSHA:         "c2d72f548424103f01ee1dc02889c1e2bff816b0",
+		IsForkPullRequest: true,
+		NeedApproval:      true,
+		ApprovedBy:        1,
+		Status:            actions_model.StatusSuccess,
+		Started:           100,
+		Stopped:           130,
+	}
+	jobs := []*actions_model.ActionRunJob{
+		{JobID: "build", Name: "build", Status: actions_model.StatusSuccess, TokenPermission: perm.AccessModeWrite},
+	}
+
+	event := newRunAuditEvent(runAuditActionCompleted, run, jobs)
+	assert.Equal(t, "user2/repo1", event.Repository)
+	assert.Equal(t, "user5", event.Actor)
+	assert.False(t, event.Trusted)
+	assert.True(t, event.NeedApproval)
+	assert.EqualValues(t, 1, event.ApprovedBy)
+	assert.Equal(t, "success", event.Status)
+	assert.EqualValues(t, 30, event.Duration)
+	// the tokens of the runs from forks are read-only
+	assert.Equal(t, []*runAuditJob{{JobID: "build", Name: "build", Status: "success", TokenPermission: "read"}}, event.Jobs)
+
+	run.IsForkPullRequest = false
+	event = newRunAuditEvent(runAuditActionCreated, run, jobs)
+	assert.True(t, event.Trusted)
+	assert.Zero(t, event.Duration)
+	assert.Equal(t, "write", event.Jobs[0].TokenPermission)
+}
+
+func Test_fileAuditSink(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "audit", "runs.log")
+	sink, err := newAuditSink(setting.AuditExporterFile, target)
+	require.NoError(t, err)
+	for _, event := range []string{`{"run_id":1}`, `{"run_id":2}`} {
+		assert.NoError(t, sink.Send(context.Background(), []byte(event)))
+	}
+	assert.NoError(t, sink.Close())
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)))
+	}
+
+	_, err = newAuditSink(setting.AuditExporterSyslog, "http://localhost:514")
+	assert.Error(t, err)
+}
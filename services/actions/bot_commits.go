@@ -0,0 +1,77 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	system_model "code.gitea.io/gitea/models/system"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+)
+
+// botCommitsSkipReason returns why the workflows of the event should be skipped by the bot commits rule of the repository,
+// or empty if they shouldn't, see repo_model.ActionsConfig.BotCommits. It's strict so the commits of humans are never skipped:
+// every pushed commit must be authored and committed by bots, and a push whose commits aren't all in the payload isn't skipped;
+// the pull request must be opened by a bot and its head commit must be authored and committed by bots.
+func botCommitsSkipReason(input *notifyInput, rule *repo_model.BotCommitsRule, commit *git.Commit) string {
+	if rule == nil || !isBotCommit(rule, commit) {
+		return ""
+	}
+	switch input.Event {
+	case webhook_module.HookEventPush:
+		payload, ok := input.Payload.(*api.PushPayload)
+		if !ok || len(payload.Commits) == 0 || payload.TotalCommits > len(payload.Commits) {
+			return ""
+		}
+		for _, c := range payload.Commits {
+			if c.Author == nil || c.Committer == nil || !rule.IsBot(c.Author.Name, c.Author.Email) || !rule.IsBot(c.Committer.Name, c.Committer.Email) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("the %d pushed commits are authored and committed by bots", len(payload.Commits))
+	case webhook_module.HookEventPullRequest, webhook_module.HookEventPullRequestSync:
+		payload, ok := input.Payload.(*api.PullRequestPayload)
+		if !ok || payload.PullRequest == nil || payload.PullRequest.Poster == nil || !rule.IsBot(payload.PullRequest.Poster.UserName, "") {
+			return ""
+		}
+		return fmt.Sprintf("the pull request is opened by bot %s and its head commit is authored by %s", payload.PullRequest.Poster.UserName, commit.Author.Name)
+	}
+	return ""
+}
+
+func isBotCommit(rule *repo_model.BotCommitsRule, commit *git.Commit) bool {
+	return commit.Author != nil && commit.Committer != nil &&
+		rule.IsBot(commit.Author.Name, commit.Author.Email) && rule.IsBot(commit.Committer.Name, commit.Committer.Email)
+}
+
+// skipBotCommitsWorkflows returns the workflows which still run for the commits of bots, and records the skipped ones with the reason
+func skipBotCommitsWorkflows(ctx context.Context, input *notifyInput, rule *repo_model.BotCommitsRule, workflows []*actions_module.DetectedWorkflow, commit *git.Commit, reason string) []*actions_module.DetectedWorkflow {
+	var kept []*actions_module.DetectedWorkflow
+	var skipped []string
+	for _, wf := range workflows {
+		if slices.Contains(rule.Workflows, wf.EntryName) {
+			kept = append(kept, wf)
+		} else if !slices.Contains(skipped, wf.EntryName) {
+			skipped = append(skipped, wf.EntryName)
+		}
+	}
+	if len(skipped) == 0 {
+		return kept
+	}
+
+	log.Info("skip workflows %v of repo %s with commit %s for event %s since %s", skipped, input.Repo.FullName(), commit.ID, input.Event, reason)
+	if err := system_model.CreateNotice(ctx, system_model.NoticeRepository, "Workflows %s of repository %s for event %s with commit %s have been skipped since %s",
+		strings.Join(skipped, ", "), input.Repo.FullName(), input.Event, commit.ID, reason); err != nil {
+		log.Error("CreateNotice: %v", err)
+	}
+	return kept
+}
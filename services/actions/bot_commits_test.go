@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_botCommitsSkipReason(t *testing.T) {
+	rule := &repo_model.BotCommitsRule{Names: []string{"release-bot"}, Emails: []string{"*[bot]@noreply.example.com"}}
+	botSig := &git.Signature{Name: "release-bot", Email: "release[bot]@noreply.example.com"}
+	humanSig := &git.Signature{Name: "alice", Email: "alice@example.com"}
+	botCommit := &git.Commit{Author: botSig, Committer: botSig}
+	botPayloadCommit := &api.PayloadCommit{
+		Author:    &api.PayloadUser{Name: botSig.Name, Email: botSig.Email},
+		Committer: &api.PayloadUser{Name: botSig.Name, Email: botSig.Email},
+	}
+	humanPayloadCommit := &api.PayloadCommit{
+		Author:    &api.PayloadUser{Name: humanSig.Name, Email: humanSig.Email},
+		Committer: &api.PayloadUser{Name: humanSig.Name, Email: humanSig.Email},
+	}
+	push := func(total int, commits ...*api.PayloadCommit) *notifyInput {
+		return &notifyInput{Event: webhook_module.HookEventPush, Payload: &api.PushPayload{Commits: commits, TotalCommits: total}}
+	}
+	pull := func(poster string) *notifyInput {
+		return &notifyInput{Event: webhook_module.HookEventPullRequestSync, Payload: &api.PullRequestPayload{PullRequest: &api.PullRequest{Poster: &api.User{UserName: poster}}}}
+	}
+
+	tests := []struct {
+		name   string
+		input  *notifyInput
+		rule   *repo_model.BotCommitsRule
+		commit *git.Commit
+		skip   bool
+	}{
+		{name: "no rule", input: push(1, botPayloadCommit), commit: botCommit},
+		{name: "bot push", input: push(2, botPayloadCommit, botPayloadCommit), rule: rule, commit: botCommit, skip: true},
+		{name: "human head commit", input: push(1, botPayloadCommit), rule: rule, commit: &git.Commit{Author: humanSig, Committer: humanSig}},
+		{name: "committed by human", input: push(1, botPayloadCommit), rule: rule, commit: &git.Commit{Author: botSig, Committer: humanSig}},
+		{name: "human commit before bot commit", input: push(2, humanPayloadCommit, botPayloadCommit), rule: rule, commit: botCommit},
+		{name: "truncated commits", input: push(30, botPayloadCommit), rule: rule, commit: botCommit},
+		{name: "no commits", input: push(0), rule: rule, commit: botCommit},
+		{name: "bot pull request", input: pull("release-bot"), rule: rule, commit: botCommit, skip: true},
+		{name: "human pull request", input: pull("alice"), rule: rule, commit: botCommit},
+		{name: "other events", input: &notifyInput{Event: webhook_module.HookEventRelease, Payload: &api.ReleasePayload{}}, rule: rule, commit: botCommit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := botCommitsSkipReason(tt.input, tt.rule, tt.commit)
+			assert.Equal(t, tt.skip, reason != "", reason)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// cacheScopesOfRun returns the refs whose caches can be restored by the run, see actions_model.CacheScopes
+func cacheScopesOfRun(ctx context.Context, run *actions_model.ActionRun) ([]string, error) {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, fmt.Errorf("LoadAttributes: %w", err)
+	}
+	baseRef := ""
+	if run.BaseRef != "" {
+		baseRef = git.RefNameFromBranch(run.BaseRef).String()
+	}
+	return actions_model.CacheScopes(run.Ref, baseRef, run.Repo.DefaultBranch), nil
+}
+
+// RestoreCache finds the cache which the run restores for the primary key and the restore keys, it returns nil if there is none.
+// The restored cache is marked as used, so it will be evicted later than the others.
+func RestoreCache(ctx context.Context, run *actions_model.ActionRun, keys []string, version string) (*actions_model.ActionCache, error) {
+	scopes, err := cacheScopesOfRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := actions_model.FindCacheToRestore(ctx, run.RepoID, scopes, keys, version)
+	if err != nil || cache == nil {
+		return nil, err
+	}
+	if err := actions_model.TouchCache(ctx, cache); err != nil {
+		return nil, fmt.Errorf("TouchCache: %w", err)
+	}
+	return cache, nil
+}
+
+// ReserveCache reserves the cache of the key and the version for the ref of the run to upload,
+// it fails if the ref has created the cache, or the cache is larger than setting.Actions.CacheRepoQuota.
+func ReserveCache(ctx context.Context, run *actions_model.ActionRun, key, version string, size int64) (*actions_model.ActionCache, error) {
+	if quota := setting.Actions.CacheRepoQuota * 1024 * 1024; quota > 0 && size > quota {
+		return nil, util.NewInvalidArgumentErrorf("the cache of %d bytes exceeds the quota of the repository", size)
+	}
+	cache := &actions_model.ActionCache{
+		RepoID:  run.RepoID,
+		Ref:     run.Ref,
+		Key:     key,
+		Version: version,
+	}
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if exists, err := actions_model.ExistsCache(ctx, run.RepoID, run.Ref, key, version); err != nil {
+			return err
+		} else if exists {
+			return util.NewAlreadyExistErrorf("the cache %q has been created by %s", key, run.Ref)
+		}
+		return actions_model.CreateCache(ctx, cache)
+	}); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// CommitCache completes the cache whose content of size bytes has been stored in storagePath,
+// the least recently used caches of the repository are evicted first if they would exceed the quota with it.
+func CommitCache(ctx context.Context, cache *actions_model.ActionCache, storagePath string, size int64) error {
+	if quota := setting.Actions.CacheRepoQuota * 1024 * 1024; quota > 0 && size > quota {
+		return util.NewInvalidArgumentErrorf("the cache of %d bytes exceeds the quota of the repository", size)
+	}
+	if err := EvictCaches(ctx, cache.RepoID, size); err != nil {
+		return err
+	}
+	cache.StoragePath, cache.Size = storagePath, size
+	return actions_model.CompleteCache(ctx, cache)
+}
+
+// EvictCaches evicts the least recently used caches of the repository, so a new cache of incoming bytes
+// could be stored without exceeding setting.Actions.CacheRepoQuota.
+// The caches are stored in the artifact storage.
+func EvictCaches(ctx context.Context, repoID, incoming int64) error {
+	caches, err := actions_model.FindCachesToEvict(ctx, repoID, setting.Actions.CacheRepoQuota*1024*1024, incoming)
+	if err != nil {
+		return fmt.Errorf("FindCachesToEvict: %w", err)
+	}
+	if len(caches) == 0 {
+		return nil
+	}
+
+	deleted := make([]*actions_model.ActionCache, 0, len(caches))
+	for _, cache := range caches {
+		if cache.StoragePath != "" {
+			if err := storage.ActionsArtifacts.Delete(cache.StoragePath); err != nil {
+				log.Error("Cannot delete cache %d: %v", cache.ID, err)
+				continue
+			}
+		}
+		deleted = append(deleted, cache)
+	}
+	if err := actions_model.DeleteCaches(ctx, deleted); err != nil {
+		return fmt.Errorf("DeleteCaches: %w", err)
+	}
+	log.Trace("%d caches of repo %d have been evicted", len(deleted), repoID)
+	return nil
+}
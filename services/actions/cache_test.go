@@ -0,0 +1,76 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	fs, err := storage.NewLocalStorage(context.Background(), &setting.Storage{Path: t.TempDir()})
+	require.NoError(t, err)
+	defer test.MockVariableValue(&storage.ActionsArtifacts, fs)()
+	defer test.MockVariableValue(&setting.Actions.CacheRepoQuota, 1)()
+
+	run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: 791})
+	save := func(key string, size int) *actions_model.ActionCache {
+		cache, err := ReserveCache(db.DefaultContext, run, key, "v1", int64(size))
+		require.NoError(t, err)
+		path := "cache/" + key
+		_, err = fs.Save(path, strings.NewReader(strings.Repeat("A", size)), int64(size))
+		require.NoError(t, err)
+		require.NoError(t, CommitCache(db.DefaultContext, cache, path, int64(size)))
+		return cache
+	}
+
+	old := save("deps-old", 512*1024)
+	_, err = ReserveCache(db.DefaultContext, run, "deps-old", "v1", 1)
+	assert.ErrorIs(t, err, util.ErrAlreadyExist, "a cache can't be overwritten by the same ref")
+	_, err = ReserveCache(db.DefaultContext, run, "deps-large", "v1", 2*1024*1024)
+	assert.ErrorIs(t, err, util.ErrInvalidArgument, "a cache larger than the quota can't be reserved")
+
+	// the uploading cache can't be restored
+	uploading, err := ReserveCache(db.DefaultContext, run, "deps-uploading", "v1", 1)
+	require.NoError(t, err)
+	cache, err := RestoreCache(db.DefaultContext, run, []string{"deps-uploading"}, "v1")
+	require.NoError(t, err)
+	assert.Nil(t, cache)
+
+	used := save("deps-used", 256*1024)
+	for id, usedUnix := range map[int64]timeutil.TimeStamp{old.ID: 1, used.ID: 2} {
+		_, err = db.GetEngine(db.DefaultContext).ID(id).Cols("used_unix").Update(&actions_model.ActionCache{UsedUnix: usedUnix})
+		require.NoError(t, err)
+	}
+	// restore the old cache by the restore key, so it's used later than the other one
+	cache, err = RestoreCache(db.DefaultContext, run, []string{"deps-missing", "deps-o"}, "v1")
+	require.NoError(t, err)
+	require.NotNil(t, cache)
+	assert.Equal(t, old.ID, cache.ID)
+	cache, err = RestoreCache(db.DefaultContext, run, []string{"deps-old"}, "v2")
+	require.NoError(t, err)
+	assert.Nil(t, cache, "the version must match")
+
+	// the least recently used cache is evicted to store the new one
+	save("deps-new", 512*1024)
+	unittest.AssertNotExistsBean(t, &actions_model.ActionCache{ID: used.ID})
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionCache{ID: old.ID})
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionCache{ID: uploading.ID})
+	_, err = fs.Stat("cache/deps-used")
+	assert.Error(t, err)
+}
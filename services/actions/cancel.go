@@ -0,0 +1,193 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+)
+
+// CancelRun cancels the jobs of the run which haven't been done in a transaction, the waiting and blocked jobs are cancelled directly,
+// and the tasks of the running jobs are stopped, so their runners are told to stop them when they report the states next time.
+// It's retried if a runner picks a job in the meantime, see cancelJobs. Then the commit statuses of the jobs are updated,
+// and the jobs are emitted so the workflow_run workflows know the run has been done.
+// It does nothing if all jobs have been done, so it's safe to call it again on a run being cancelled.
+func CancelRun(ctx context.Context, run *actions_model.ActionRun) error {
+	cancelled, err := cancelJobs(ctx, "CancelRun", func(ctx context.Context) ([]*actions_model.ActionRunJob, error) {
+		return actions_model.GetRunJobsByRunID(ctx, run.ID)
+	})
+	if err != nil || !cancelled {
+		return err
+	}
+
+	// the jobs whose tasks have been stopped are only updated in the database, so load them again for the commit statuses
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	CreateCommitStatus(ctx, jobs...)
+	if err := EmitJobsIfReady(run.ID); err != nil {
+		log.Error("Emit ready jobs of run %d: %v", run.ID, err)
+	}
+	return nil
+}
+
+// CancelJob cancels the job without cancelling the other jobs of its run, like CancelRun does for all jobs of the run.
+// The blocked jobs which need it are skipped by the job emitter, since one of their needs has been cancelled,
+// while the other jobs go on. Cancelling a leg of a matrix job doesn't cancel the other legs for `fail-fast`,
+// which only reacts to the failed legs. The run is done when all its jobs are done, and it fails since not all jobs succeeded.
+// It does nothing if the job has been done.
+func CancelJob(ctx context.Context, job *actions_model.ActionRunJob) error {
+	cancelled, err := cancelJobs(ctx, "CancelJob", func(ctx context.Context) ([]*actions_model.ActionRunJob, error) {
+		current, err := actions_model.GetRunJobByID(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		return []*actions_model.ActionRunJob{current}, nil
+	})
+	if err != nil || !cancelled {
+		return err
+	}
+
+	current, err := actions_model.GetRunJobByID(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobByID: %w", err)
+	}
+	CreateCommitStatus(ctx, current)
+	if err := EmitJobsIfReady(current.RunID); err != nil {
+		log.Error("Emit ready jobs of run %d: %v", current.RunID, err)
+	}
+	return nil
+}
+
+// cancelJobs cancels the jobs loaded by load in a transaction, and reports whether any of them hadn't been done.
+// It's retried with the jobs loaded again if a job has been changed after it was loaded, like a runner has picked it,
+// see actions_model.ErrJobChanged, so the task of the picked job is stopped in the next attempt.
+func cancelJobs(ctx context.Context, name string, load func(ctx context.Context) ([]*actions_model.ActionRunJob, error)) (bool, error) {
+	cancelled := false
+	if err := retryIf(ctx, name, isErrCancelRetryable, func() error {
+		return db.WithTx(ctx, func(ctx context.Context) error {
+			// load the jobs in every attempt, since the failed attempt was caused by the changed jobs
+			jobs, err := load(ctx)
+			if err != nil {
+				return fmt.Errorf("load jobs: %w", err)
+			}
+			cancelled = slices.ContainsFunc(jobs, func(job *actions_model.ActionRunJob) bool {
+				return !job.Status.IsDone()
+			})
+			return actions_model.CancelJobs(ctx, jobs)
+		})
+	}); err != nil {
+		return false, fmt.Errorf("CancelJobs: %w", err)
+	}
+	return cancelled, nil
+}
+
+func isErrCancelRetryable(err error) bool {
+	return errors.Is(err, actions_model.ErrJobChanged) || db.IsErrTransient(err)
+}
+
+// cancelRunsOfDeletedRef cancels the runs of the deleted branch or tag which haven't been done, since they are pointless now.
+// Only the runs whose ref is exactly the deleted ref are cancelled, so the runs of pull requests or other refs are not affected.
+func cancelRunsOfDeletedRef(ctx context.Context, repo *repo_model.Repository, ref git.RefName) error {
+	if !ref.IsBranch() && !ref.IsTag() {
+		return nil
+	}
+
+	runs, err := db.Find[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+		RepoID: repo.ID,
+		Ref:    ref.String(),
+		Status: []actions_model.Status{actions_model.StatusWaiting, actions_model.StatusRunning, actions_model.StatusBlocked},
+	})
+	if err != nil {
+		return fmt.Errorf("FindRuns: %w", err)
+	}
+
+	for _, run := range runs {
+		if err := CancelRun(ctx, run); err != nil {
+			return err
+		}
+		log.Trace("run %d of repo %d has been cancelled since its ref %s was deleted", run.ID, repo.ID, ref)
+	}
+	return nil
+}
+
+// cancelSupersededQueuedRuns cancels the queued runs of the branch which are superseded by pushing the commit, see isRunSupersededBy,
+// regardless of their workflows. The runs which any runner has picked a job of are not cancelled, see isRunQueued.
+func cancelSupersededQueuedRuns(ctx context.Context, repo *repo_model.Repository, ref string, commit *git.Commit) error {
+	runs, err := db.Find[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+		RepoID:       repo.ID,
+		Ref:          ref,
+		TriggerEvent: webhook_module.HookEventPush,
+		Status:       []actions_model.Status{actions_model.StatusWaiting, actions_model.StatusBlocked},
+	})
+	if err != nil {
+		return fmt.Errorf("FindRuns: %w", err)
+	}
+
+	for _, run := range runs {
+		if !isRunSupersededBy(run, commit) {
+			continue
+		}
+		jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+		if err != nil {
+			return fmt.Errorf("GetRunJobsByRunID: %w", err)
+		}
+		if !isRunQueued(jobs) {
+			continue
+		}
+		if err := db.WithTx(ctx, func(ctx context.Context) error {
+			return actions_model.CancelJobs(ctx, jobs)
+		}); err != nil {
+			// a runner may have picked a job in the meantime, so the run is running now
+			log.Warn("unable to cancel superseded run %d of repo %d: %v", run.ID, repo.ID, err)
+			continue
+		}
+		CreateCommitStatus(ctx, jobs...)
+		if err := EmitJobsIfReady(run.ID); err != nil {
+			log.Error("Emit ready jobs of run %d: %v", run.ID, err)
+		}
+		log.Trace("run %d of repo %d has been cancelled since it's superseded by commit %s of %s", run.ID, repo.ID, commit.ID, ref)
+	}
+	return nil
+}
+
+// isRunSupersededBy reports whether the run is superseded by pushing the commit: its commit is an ancestor of the pushed one,
+// or it was created before the pushed commit was committed, like the run of a commit which has been dropped by a force-push.
+// So the runs of the newer pushes aren't cancelled if the push of an older commit is notified late.
+func isRunSupersededBy(run *actions_model.ActionRun, commit *git.Commit) bool {
+	if run.CommitSHA == commit.ID.String() {
+		return false
+	}
+	if id, err := git.NewIDFromString(run.CommitSHA); err == nil {
+		if isAncestor, err := commit.HasPreviousCommit(id); err != nil {
+			// the commit of the run may have been removed from the repository after a force-push
+			log.Trace("HasPreviousCommit [run: %d, commit: %s]: %v", run.ID, run.CommitSHA, err)
+		} else if isAncestor {
+			return true
+		}
+	}
+	return commit.Committer != nil && run.Created < timeutil.TimeStamp(commit.Committer.When.Unix())
+}
+
+// isRunQueued reports whether no runner has picked any job of the run yet, so cancelling it wastes no work.
+// The jobs kept by rerunning a part of the run have been picked before, so such a run is not treated as queued.
+func isRunQueued(jobs []*actions_model.ActionRunJob) bool {
+	for _, job := range jobs {
+		if job.TaskID != 0 || !job.Started.IsZero() || job.Status.IsRunning() {
+			return false
+		}
+	}
+	return len(jobs) > 0
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isRunQueued(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []*actions_model.ActionRunJob
+		want bool
+	}{
+		{
+			name: "waiting and blocked",
+			jobs: []*actions_model.ActionRunJob{
+				{JobID: "build", Status: actions_model.StatusWaiting},
+				{JobID: "test", Status: actions_model.StatusBlocked},
+			},
+			want: true,
+		},
+		{
+			name: "skipped without runners",
+			jobs: []*actions_model.ActionRunJob{
+				{JobID: "build", Status: actions_model.StatusSkipped},
+				{JobID: "test", Status: actions_model.StatusWaiting},
+			},
+			want: true,
+		},
+		{
+			name: "picked but not updated yet",
+			jobs: []*actions_model.ActionRunJob{
+				{JobID: "build", Status: actions_model.StatusWaiting, TaskID: 1},
+				{JobID: "test", Status: actions_model.StatusBlocked},
+			},
+			want: false,
+		},
+		{
+			name: "running",
+			jobs: []*actions_model.ActionRunJob{
+				{JobID: "build", Status: actions_model.StatusRunning, TaskID: 1, Started: 1},
+				{JobID: "test", Status: actions_model.StatusBlocked},
+			},
+			want: false,
+		},
+		{
+			name: "partially rerun",
+			jobs: []*actions_model.ActionRunJob{
+				{JobID: "build", Status: actions_model.StatusSuccess, TaskID: 1, Started: 1, Stopped: 2},
+				{JobID: "test", Status: actions_model.StatusWaiting},
+			},
+			want: false,
+		},
+		{
+			name: "no jobs",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRunQueued(tt.jobs))
+		})
+	}
+}
+
+func Test_isRunSupersededBy(t *testing.T) {
+	require.NoError(t, git.InitSimple(context.Background()))
+	repoPath := t.TempDir()
+	require.NoError(t, git.InitRepository(context.Background(), repoPath, false, git.Sha1ObjectFormat.Name()))
+	now := time.Now()
+	for i, when := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour)} {
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, "README.md"), []byte(when.String()), 0o644))
+		require.NoError(t, git.AddChanges(repoPath, true))
+		signature := &git.Signature{Name: "gitea", Email: "gitea@example.com", When: when}
+		require.NoError(t, git.CommitChanges(repoPath, git.CommitChangesOptions{Committer: signature, Author: signature, Message: fmt.Sprint(i)}))
+	}
+	gitRepo, err := git.OpenRepository(context.Background(), repoPath)
+	require.NoError(t, err)
+	defer gitRepo.Close()
+	child, err := gitRepo.GetCommit("HEAD")
+	require.NoError(t, err)
+	parent, err := child.Parent(0)
+	require.NoError(t, err)
+
+	created := timeutil.TimeStamp(now.Unix())
+	// the run of an older commit of the branch
+	assert.True(t, isRunSupersededBy(&actions_model.ActionRun{CommitSHA: parent.ID.String(), Created: created}, child))
+	// the run of the same commit
+	assert.False(t, isRunSupersededBy(&actions_model.ActionRun{CommitSHA: child.ID.String(), Created: created}, child))
+	// the run of a newer commit, the push of the older commit is notified late
+	assert.False(t, isRunSupersededBy(&actions_model.ActionRun{CommitSHA: child.ID.String(), Created: created}, parent))
+	// the runs of a commit dropped by a force-push
+	dropped := "0123456789abcdef0123456789abcdef01234567"
+	assert.True(t, isRunSupersededBy(&actions_model.ActionRun{CommitSHA: dropped, Created: timeutil.TimeStamp(now.Add(-90 * time.Minute).Unix())}, child))
+	assert.False(t, isRunSupersededBy(&actions_model.ActionRun{CommitSHA: dropped, Created: created}, child))
+}
+
+func Test_cancelJobsPickedInTheMeantime(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&retryBackoff, 0)()
+
+	run := &actions_model.ActionRun{RepoID: 1, OwnerID: 2, Index: 1001, WorkflowID: "test.yml", TriggerUserID: 2, Status: actions_model.StatusRunning}
+	require.NoError(t, db.Insert(db.DefaultContext, run))
+	job := &actions_model.ActionRunJob{RunID: run.ID, RepoID: 1, OwnerID: 2, Name: "test", JobID: "test", Status: actions_model.StatusRunning}
+	require.NoError(t, db.Insert(db.DefaultContext, job))
+	task := &actions_model.ActionTask{JobID: job.ID, RepoID: 1, OwnerID: 2, Status: actions_model.StatusRunning, Started: timeutil.TimeStampNow()}
+	require.NoError(t, db.Insert(db.DefaultContext, task))
+	job.TaskID = task.ID
+	_, err := actions_model.UpdateRunJob(db.DefaultContext, job, nil, "task_id")
+	require.NoError(t, err)
+
+	attempts := 0
+	cancelled, err := cancelJobs(db.DefaultContext, "test", func(ctx context.Context) ([]*actions_model.ActionRunJob, error) {
+		attempts++
+		jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+		if attempts == 1 {
+			// the jobs were loaded before the runner picked the job
+			jobs[0].TaskID, jobs[0].Status = 0, actions_model.StatusWaiting
+		}
+		return jobs, err
+	})
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, actions_model.StatusCancelled, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: job.ID}).Status)
+	assert.Equal(t, actions_model.StatusCancelled, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionTask{ID: task.ID}).Status)
+}
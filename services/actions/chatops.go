@@ -0,0 +1,127 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ChatOpsEventType is the type of the `repository_dispatch` event which dispatches the workflow of a chatops command,
+// see repo_model.ActionsConfig.ChatOpsCommands
+const ChatOpsEventType = "chatops"
+
+// parseChatOpsCommand returns the first command which starts a line of the comment, with the values following it on the line
+func parseChatOpsCommand(commands []*repo_model.ChatOpsCommand, content string) (*repo_model.ChatOpsCommand, []string) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, cmd := range commands {
+			if cmd.Command != "" && cmd.Workflow != "" && fields[0] == cmd.Command {
+				return cmd, fields[1:]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// parseChatOpsArguments validates the values against the arguments of the command, and returns the values by the names of the arguments.
+// The omitted arguments get their defaults, it returns an invalid argument error if the values don't fit the arguments.
+func parseChatOpsArguments(cmd *repo_model.ChatOpsCommand, values []string) (map[string]string, error) {
+	if len(values) > len(cmd.Arguments) {
+		return nil, util.NewInvalidArgumentErrorf("command %s takes at most %d arguments, but got %d", cmd.Command, len(cmd.Arguments), len(values))
+	}
+	args := make(map[string]string, len(cmd.Arguments))
+	for i, arg := range cmd.Arguments {
+		value := arg.Default
+		if i < len(values) {
+			value = values[i]
+		}
+		if value == "" {
+			return nil, util.NewInvalidArgumentErrorf("argument %s of command %s is required", arg.Name, cmd.Command)
+		}
+		if arg.Pattern != "" {
+			re, err := regexp.Compile("^(?:" + arg.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of argument %s of command %s: %w", arg.Name, cmd.Command, err)
+			}
+			if !re.MatchString(value) {
+				return nil, util.NewInvalidArgumentErrorf("argument %s of command %s doesn't match %q: %q", arg.Name, cmd.Command, arg.Pattern, value)
+			}
+		}
+		args[arg.Name] = value
+	}
+	return args, nil
+}
+
+// canRunChatOpsCommand reports whether the doer can run the command of the repository, the doer needs write permission for actions,
+// and must be one of the users of the command too if they are configured.
+func canRunChatOpsCommand(ctx context.Context, repo *repo_model.Repository, cmd *repo_model.ChatOpsCommand, doer *user_model.User) (bool, error) {
+	perm, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %w", err)
+	} else if !perm.CanWrite(unit_model.TypeActions) {
+		return false, nil
+	}
+	if len(cmd.Users) == 0 {
+		return true, nil
+	}
+	return isApprovalReviewer(ctx, cmd.Users, doer)
+}
+
+// handleChatOpsCommand dispatches the workflow of the chatops command commented on the issue or the pull request, see ChatOpsEventType.
+// The workflow runs on the default branch with the commenter as the trigger user, so the code of the pull requests is never run by it,
+// and the arguments are available as `github.event.client_payload.arguments`.
+// The commands of the users who can't run them and the ones with invalid arguments are ignored.
+func handleChatOpsCommand(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, issue *issues_model.Issue, comment *issues_model.Comment) error {
+	if doer.IsActions() {
+		return nil
+	}
+	unit, err := repo.GetUnit(ctx, unit_model.TypeActions)
+	if repo_model.IsErrUnitTypeNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("GetUnit: %w", err)
+	}
+	cmd, values := parseChatOpsCommand(unit.ActionsConfig().ChatOpsCommands, comment.Content)
+	if cmd == nil {
+		return nil
+	}
+
+	if can, err := canRunChatOpsCommand(ctx, repo, cmd, doer); err != nil {
+		return fmt.Errorf("canRunChatOpsCommand: %w", err)
+	} else if !can {
+		log.Trace("ignore chatops command %s commented by user %d who can't run it", cmd.Command, doer.ID)
+		return nil
+	}
+	args, err := parseChatOpsArguments(cmd, values)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			log.Trace("ignore chatops command %s in comment %d: %v", cmd.Command, comment.ID, err)
+			return nil
+		}
+		return err
+	}
+
+	return dispatchRepositoryEvent(withMethod(ctx, "handleChatOpsCommand"), doer, repo, ChatOpsEventType, map[string]any{
+		"command":    cmd.Command,
+		"arguments":  args,
+		"issue":      issue.Index,
+		"is_pull":    issue.IsPull,
+		"comment_id": comment.ID,
+	}, cmd.Workflow)
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseChatOpsCommand(t *testing.T) {
+	deploy := &repo_model.ChatOpsCommand{Command: "/deploy", Workflow: "deploy.yml"}
+	rollback := &repo_model.ChatOpsCommand{Command: "/rollback", Workflow: "rollback.yml"}
+	commands := []*repo_model.ChatOpsCommand{deploy, rollback, {Command: "/noop"}}
+
+	tests := []struct {
+		content    string
+		wantCmd    *repo_model.ChatOpsCommand
+		wantValues []string
+	}{
+		{content: "LGTM"},
+		{content: "/deploy", wantCmd: deploy, wantValues: []string{}},
+		{content: "Looks good.\n  /deploy  staging v1.2 \n/rollback", wantCmd: deploy, wantValues: []string{"staging", "v1.2"}},
+		{content: "please /deploy staging"},
+		{content: "/deployment staging"},
+		{content: "/noop"}, // no workflow
+	}
+	for _, tt := range tests {
+		cmd, values := parseChatOpsCommand(commands, tt.content)
+		assert.Equal(t, tt.wantCmd, cmd, "content: %q", tt.content)
+		assert.Equal(t, tt.wantValues, values, "content: %q", tt.content)
+	}
+}
+
+func Test_parseChatOpsArguments(t *testing.T) {
+	cmd := &repo_model.ChatOpsCommand{
+		Command: "/deploy",
+		Arguments: []*repo_model.ChatOpsArgument{
+			{Name: "environment", Pattern: "staging|production"},
+			{Name: "version", Pattern: `v\d+(\.\d+)*`, Default: "v1"},
+		},
+	}
+
+	args, err := parseChatOpsArguments(cmd, []string{"staging", "v1.2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "staging", "version": "v1.2"}, args)
+
+	args, err = parseChatOpsArguments(cmd, []string{"production"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "production", "version": "v1"}, args)
+
+	for _, values := range [][]string{
+		nil,                                 // required
+		{"staging-2"},                       // the whole value must match
+		{"staging", "v1; rm -rf /"},         // invalid
+		{"staging", "v1", "extra-argument"}, // too many
+	} {
+		_, err := parseChatOpsArguments(cmd, values)
+		assert.ErrorIs(t, err, util.ErrInvalidArgument, "values: %v", values)
+	}
+
+	_, err = parseChatOpsArguments(&repo_model.ChatOpsCommand{
+		Command:   "/deploy",
+		Arguments: []*repo_model.ChatOpsArgument{{Name: "environment", Pattern: "("}},
+	}, []string{"staging"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, util.ErrInvalidArgument)
+}
@@ -20,7 +20,8 @@ func Cleanup(taskCtx context.Context, olderThan time.Duration) error {
 	return CleanupArtifacts(taskCtx)
 }
 
-// CleanupArtifacts removes expired artifacts and set records expired status
+// CleanupArtifacts removes expired artifacts and set records expired status,
+// then evicts the oldest artifacts of the repositories which exceed their artifact quotas
 func CleanupArtifacts(taskCtx context.Context) error {
 	artifacts, err := actions.ListNeedExpiredArtifacts(taskCtx)
 	if err != nil {
@@ -38,5 +39,5 @@ func CleanupArtifacts(taskCtx context.Context) error {
 		}
 		log.Info("Artifact %d set expired", artifact.ID)
 	}
-	return nil
+	return EvictArtifactsOverQuota(taskCtx)
 }
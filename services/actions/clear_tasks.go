@@ -11,17 +11,19 @@ import (
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 )
 
-// StopZombieTasks stops the task which have running status, but haven't been updated for a long time
+// StopZombieTasks stops the task which have running status, but haven't been updated for a long time,
+// their runners are regarded as dead, so the jobs failed by the infrastructure
 func StopZombieTasks(ctx context.Context) error {
 	return stopTasks(ctx, actions_model.FindTaskOptions{
 		Status:        actions_model.StatusRunning,
 		UpdatedBefore: timeutil.TimeStamp(time.Now().Add(-setting.Actions.ZombieTaskTimeout).Unix()),
-	})
+	}, actions_model.FailureCauseInfra)
 }
 
 // StopEndlessTasks stops the tasks which have running status and continuous updates, but don't end for a long time
@@ -29,10 +31,11 @@ func StopEndlessTasks(ctx context.Context) error {
 	return stopTasks(ctx, actions_model.FindTaskOptions{
 		Status:        actions_model.StatusRunning,
 		StartedBefore: timeutil.TimeStamp(time.Now().Add(-setting.Actions.EndlessTaskTimeout).Unix()),
-	})
+	}, actions_model.FailureCauseLogic)
 }
 
-func stopTasks(ctx context.Context, opts actions_model.FindTaskOptions) error {
+// stopTasks fails the tasks, the failed jobs are recorded to fail by the cause
+func stopTasks(ctx context.Context, opts actions_model.FindTaskOptions, cause string) error {
 	tasks, err := db.Find[actions_model.ActionTask](ctx, opts)
 	if err != nil {
 		return fmt.Errorf("find tasks: %w", err)
@@ -47,6 +50,13 @@ func stopTasks(ctx context.Context, opts actions_model.FindTaskOptions) error {
 			if err := task.LoadJob(ctx); err != nil {
 				return err
 			}
+			// the task may have been finished by the runner just now, then the cause has been reported
+			if task.Job.TaskID == task.ID && task.Job.Status == actions_model.StatusFailure && task.Job.FailureCause == "" {
+				task.Job.FailureCause = cause
+				if _, err := actions_model.UpdateRunJob(ctx, task.Job, nil, "failure_cause"); err != nil {
+					return err
+				}
+			}
 			jobs = append(jobs, task.Job)
 			return nil
 		}); err != nil {
@@ -69,6 +79,16 @@ func stopTasks(ctx context.Context, opts actions_model.FindTaskOptions) error {
 
 	CreateCommitStatus(ctx, jobs...)
 
+	// the jobs which need the failed jobs are resolved, and the completed runs are notified
+	runIDs := make(container.Set[int64])
+	for _, job := range jobs {
+		if runIDs.Add(job.RunID) {
+			if err := EmitJobsIfReady(job.RunID); err != nil {
+				log.Error("EmitJobsIfReady [run: %d]: %v", job.RunID, err)
+			}
+		}
+	}
+
 	return nil
 }
 
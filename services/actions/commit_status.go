@@ -7,10 +7,14 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"slices"
+	"strings"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	git "code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
@@ -20,12 +24,25 @@ import (
 	"github.com/nektos/act/pkg/jobparser"
 )
 
-// CreateCommitStatus creates a commit status for the given job.
+// CreateCommitStatus creates a commit status for the given job,
+// and updates the aggregate commit status of the workflow of its run if the repository creates it, see repo_model.ActionsConfig.CommitStatusMode.
 // It won't return an error failed, but will log it, because it's not critical.
 func CreateCommitStatus(ctx context.Context, jobs ...*actions_model.ActionRunJob) {
+	runs := make([]*actions_model.ActionRun, 0, 1)
 	for _, job := range jobs {
-		if err := createCommitStatus(ctx, job); err != nil {
+		err := createCommitStatus(ctx, job)
+		if job.Run != nil && !slices.ContainsFunc(runs, func(run *actions_model.ActionRun) bool { return run.ID == job.RunID }) {
+			runs = append(runs, job.Run)
+		}
+		if err != nil {
 			log.Error("Failed to create commit status for job %d: %v", job.ID, err)
+			continue
+		}
+		refreshPullChecksIfDone(job)
+	}
+	for _, run := range runs {
+		if err := createWorkflowCommitStatus(ctx, run); err != nil {
+			log.Error("Failed to create commit status for run %d: %v", run.ID, err)
 		}
 	}
 }
@@ -37,57 +54,24 @@ func createCommitStatus(ctx context.Context, job *actions_model.ActionRunJob) er
 
 	run := job.Run
 
-	var (
-		sha   string
-		event string
-	)
-	switch run.Event {
-	case webhook_module.HookEventPush:
-		event = "push"
-		payload, err := run.GetPushEventPayload()
-		if err != nil {
-			return fmt.Errorf("GetPushEventPayload: %w", err)
-		}
-		if payload.HeadCommit == nil {
-			return fmt.Errorf("head commit is missing in event payload")
-		}
-		sha = payload.HeadCommit.ID
-	case webhook_module.HookEventPullRequest, webhook_module.HookEventPullRequestSync:
-		event = "pull_request"
-		payload, err := run.GetPullRequestEventPayload()
-		if err != nil {
-			return fmt.Errorf("GetPullRequestEventPayload: %w", err)
-		}
-		if payload.PullRequest == nil {
-			return fmt.Errorf("pull request is missing in event payload")
-		} else if payload.PullRequest.Head == nil {
-			return fmt.Errorf("head of pull request is missing in event payload")
-		}
-		sha = payload.PullRequest.Head.Sha
-	default:
-		return nil
+	sha, err := commitStatusSHA(run)
+	if err != nil || sha == "" {
+		return err
 	}
-
 	repo := run.Repo
-	// TODO: store workflow name as a field in ActionRun to avoid parsing
-	runName := path.Base(run.WorkflowID)
-	if wfs, err := jobparser.Parse(job.WorkflowPayload); err == nil && len(wfs) > 0 {
-		runName = wfs[0].Name
+	if cfg, err := commitStatusConfig(ctx, repo); err != nil {
+		return err
+	} else if !cfg.CreatesJobCommitStatuses() {
+		return nil
 	}
-	ctxname := fmt.Sprintf("%s / %s (%s)", runName, job.Name, event)
+
+	ctxname := commitStatusContext(run, job)
 	state := toCommitStatus(job.Status)
-	if statuses, _, err := git_model.GetLatestCommitStatus(ctx, repo.ID, sha, db.ListOptions{ListAll: true}); err == nil {
-		for _, v := range statuses {
-			if v.Context == ctxname {
-				if v.State == state {
-					// no need to update
-					return nil
-				}
-				break
-			}
-		}
-	} else {
-		return fmt.Errorf("GetLatestCommitStatus: %w", err)
+	if latest, err := getLatestCommitStatus(ctx, repo.ID, sha, ctxname); err != nil {
+		return err
+	} else if latest != nil && latest.State == state {
+		// no need to update
+		return nil
 	}
 
 	description := ""
@@ -138,6 +122,199 @@ func createCommitStatus(ctx context.Context, job *actions_model.ActionRunJob) er
 	return nil
 }
 
+// createWorkflowCommitStatus creates or updates the aggregate commit status of the workflow of the run, like "CI (pull_request)",
+// it's pending until all jobs are done, then it fails if any job without continue-on-error has failed or been cancelled.
+func createWorkflowCommitStatus(ctx context.Context, run *actions_model.ActionRun) error {
+	sha, err := commitStatusSHA(run)
+	if err != nil || sha == "" {
+		return err
+	}
+	// reload the run since it may have been done by the jobs
+	run, err = actions_model.GetRunByID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunByID: %w", err)
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		return fmt.Errorf("LoadAttributes: %w", err)
+	}
+	repo := run.Repo
+	if cfg, err := commitStatusConfig(ctx, repo); err != nil {
+		return err
+	} else if !cfg.CreatesWorkflowCommitStatuses() {
+		return nil
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ctxname := fmt.Sprintf("%s (%s)", commitStatusWorkflowName(run, jobs[0]), commitStatusEvent(run.Event))
+	state, description := aggregateCommitStatus(run, jobs)
+	if latest, err := getLatestCommitStatus(ctx, repo.ID, sha, ctxname); err != nil {
+		return err
+	} else if latest != nil && latest.State == state && latest.Description == description {
+		// no need to update
+		return nil
+	}
+
+	creator := user_model.NewActionsUser()
+	commitID, err := git.NewIDFromString(sha)
+	if err != nil {
+		return fmt.Errorf("HashTypeInterfaceFromHashString: %w", err)
+	}
+	if err := git_model.NewCommitStatus(ctx, git_model.NewCommitStatusOptions{
+		Repo:    repo,
+		SHA:     commitID,
+		Creator: creator,
+		CommitStatus: &git_model.CommitStatus{
+			SHA:         sha,
+			TargetURL:   run.Link(),
+			Description: description,
+			Context:     ctxname,
+			CreatorID:   creator.ID,
+			State:       state,
+		},
+	}); err != nil {
+		return fmt.Errorf("NewCommitStatus: %w", err)
+	}
+
+	return nil
+}
+
+// aggregateCommitStatus returns the state and the description of the aggregate commit status of the workflow of the run
+func aggregateCommitStatus(run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) (api.CommitStatusState, string) {
+	done, failed := 0, false
+	for _, job := range jobs {
+		if !job.Status.IsDone() {
+			continue
+		}
+		done++
+		if toCommitStatus(job.Status) != api.CommitStatusSuccess && !job.ContinueOnError {
+			failed = true
+		}
+	}
+	switch {
+	case done < len(jobs):
+		return api.CommitStatusPending, fmt.Sprintf("%d of %d jobs done", done, len(jobs))
+	case failed:
+		return api.CommitStatusFailure, fmt.Sprintf("Failing after %s", run.Duration())
+	default:
+		return api.CommitStatusSuccess, fmt.Sprintf("Successful in %s", run.Duration())
+	}
+}
+
+// commitStatusSHA returns the commit which the commit statuses of the run are created for,
+// it's empty if the run doesn't create commit statuses
+func commitStatusSHA(run *actions_model.ActionRun) (string, error) {
+	switch run.Event {
+	case webhook_module.HookEventPush:
+		payload, err := run.GetPushEventPayload()
+		if err != nil {
+			return "", fmt.Errorf("GetPushEventPayload: %w", err)
+		}
+		if payload.HeadCommit == nil {
+			return "", fmt.Errorf("head commit is missing in event payload")
+		}
+		return payload.HeadCommit.ID, nil
+	case webhook_module.HookEventPullRequest, webhook_module.HookEventPullRequestSync:
+		payload, err := run.GetPullRequestEventPayload()
+		if err != nil {
+			return "", fmt.Errorf("GetPullRequestEventPayload: %w", err)
+		}
+		if payload.PullRequest == nil {
+			return "", fmt.Errorf("pull request is missing in event payload")
+		} else if payload.PullRequest.Head == nil {
+			return "", fmt.Errorf("head of pull request is missing in event payload")
+		}
+		return payload.PullRequest.Head.Sha, nil
+	case webhook_module.HookEventMergeGroup:
+		payload, err := run.GetMergeGroupEventPayload()
+		if err != nil {
+			return "", fmt.Errorf("GetMergeGroupEventPayload: %w", err)
+		}
+		if payload.MergeGroup == nil {
+			return "", fmt.Errorf("merge group is missing in event payload")
+		}
+		return payload.MergeGroup.HeadSHA, nil
+	default:
+		return "", nil
+	}
+}
+
+// commitStatusConfig returns the actions config of the repository which decides the commit statuses to create
+func commitStatusConfig(ctx context.Context, repo *repo_model.Repository) (*repo_model.ActionsConfig, error) {
+	actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions)
+	if err != nil {
+		if repo_model.IsErrUnitTypeNotExist(err) {
+			return &repo_model.ActionsConfig{}, nil
+		}
+		return nil, fmt.Errorf("GetUnit: %w", err)
+	}
+	return actionsUnit.ActionsConfig(), nil
+}
+
+// getLatestCommitStatus returns the latest commit status of the context of the commit, or nil if there isn't one
+func getLatestCommitStatus(ctx context.Context, repoID int64, sha, ctxname string) (*git_model.CommitStatus, error) {
+	statuses, _, err := git_model.GetLatestCommitStatus(ctx, repoID, sha, db.ListOptions{ListAll: true})
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestCommitStatus: %w", err)
+	}
+	for _, v := range statuses {
+		if v.Context == ctxname {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// commitStatusEvent returns the event in the contexts of the commit statuses of the runs triggered by event,
+// it's empty if the runs don't create commit statuses
+func commitStatusEvent(event webhook_module.HookEventType) string {
+	switch event {
+	case webhook_module.HookEventPush:
+		return "push"
+	case webhook_module.HookEventPullRequest, webhook_module.HookEventPullRequestSync:
+		return "pull_request"
+	case webhook_module.HookEventMergeGroup:
+		return "merge_group"
+	default:
+		return ""
+	}
+}
+
+// commitStatusContext returns the context of the commit status of the job, like "CI / test (1) (pull_request)",
+// the legs of a matrix job have different contexts since their names contain the matrix values.
+func commitStatusContext(run *actions_model.ActionRun, job *actions_model.ActionRunJob) string {
+	return fmt.Sprintf("%s / %s (%s)", commitStatusWorkflowName(run, job), job.Name, commitStatusEvent(run.Event))
+}
+
+// commitStatusWorkflowName returns the name of the workflow in the contexts of the commit statuses, or the file name if the workflow has no name.
+// The file name is added to the name if other workflows have the same name, like "CI (lint.yml)", so the contexts of them don't collide.
+func commitStatusWorkflowName(run *actions_model.ActionRun, job *actions_model.ActionRunJob) string {
+	// TODO: store workflow name as a field in ActionRun to avoid parsing
+	runName := path.Base(run.WorkflowID)
+	if wfs, err := jobparser.Parse(job.WorkflowPayload); err == nil && len(wfs) > 0 {
+		runName = wfs[0].Name
+		if len(run.SameNamed) > 0 {
+			runName = fmt.Sprintf("%s (%s)", runName, run.WorkflowID)
+		}
+	}
+	return runName
+}
+
+// sameNamedWarning returns the warning of the run whose workflow has the same name as the other workflows, or empty if there are none
+func sameNamedWarning(sameNamed []string) string {
+	if len(sameNamed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("the name of the workflow is also used by %s, so the file name is added to the contexts of the commit statuses to distinguish them, "+
+		"please give the workflows different names", strings.Join(sameNamed, ", "))
+}
+
 func toCommitStatus(status actions_model.Status) api.CommitStatusState {
 	switch status {
 	case actions_model.StatusSuccess, actions_model.StatusSkipped:
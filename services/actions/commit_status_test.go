@@ -0,0 +1,89 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_aggregateCommitStatus(t *testing.T) {
+	run := &actions_model.ActionRun{Status: actions_model.StatusSuccess, Started: timeutil.TimeStamp(100), Stopped: timeutil.TimeStamp(160)}
+	tests := []struct {
+		name        string
+		jobs        []*actions_model.ActionRunJob
+		state       api.CommitStatusState
+		description string
+	}{
+		{
+			name: "in progress",
+			jobs: []*actions_model.ActionRunJob{
+				{Status: actions_model.StatusFailure},
+				{Status: actions_model.StatusRunning},
+			},
+			state:       api.CommitStatusPending,
+			description: "1 of 2 jobs done",
+		},
+		{
+			name: "success",
+			jobs: []*actions_model.ActionRunJob{
+				{Status: actions_model.StatusSuccess},
+				{Status: actions_model.StatusSkipped},
+			},
+			state:       api.CommitStatusSuccess,
+			description: "Successful in 1m0s",
+		},
+		{
+			name: "continue on error",
+			jobs: []*actions_model.ActionRunJob{
+				{Status: actions_model.StatusSuccess},
+				{Status: actions_model.StatusFailure, ContinueOnError: true},
+			},
+			state:       api.CommitStatusSuccess,
+			description: "Successful in 1m0s",
+		},
+		{
+			name: "cancelled",
+			jobs: []*actions_model.ActionRunJob{
+				{Status: actions_model.StatusSuccess},
+				{Status: actions_model.StatusCancelled},
+			},
+			state:       api.CommitStatusFailure,
+			description: "Failing after 1m0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, description := aggregateCommitStatus(run, tt.jobs)
+			assert.Equal(t, tt.state, state)
+			assert.Equal(t, tt.description, description)
+		})
+	}
+}
+
+func Test_commitStatusContext_SameNamed(t *testing.T) {
+	job := &actions_model.ActionRunJob{
+		Name:            "test",
+		WorkflowPayload: []byte("name: CI\non: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make test\n"),
+	}
+	lint := &actions_model.ActionRun{WorkflowID: "lint.yml", Event: "push", SameNamed: []string{".gitea/workflows/test.yml"}}
+	test := &actions_model.ActionRun{WorkflowID: "test.yml", Event: "push", SameNamed: []string{".gitea/workflows/lint.yml"}}
+	unique := &actions_model.ActionRun{WorkflowID: "ci.yml", Event: "push"}
+
+	assert.Equal(t, "CI (lint.yml) / test (push)", commitStatusContext(lint, job))
+	assert.Equal(t, "CI (test.yml) / test (push)", commitStatusContext(test, job))
+	assert.Equal(t, "CI / test (push)", commitStatusContext(unique, job))
+}
+
+func Test_sameNamedWarning(t *testing.T) {
+	assert.Empty(t, sameNamedWarning(nil))
+	assert.Equal(t, "the name of the workflow is also used by .gitea/workflows/b.yml, .gitea/workflows/c.yml, "+
+		"so the file name is added to the contexts of the commit statuses to distinguish them, please give the workflows different names",
+		sameNamedWarning([]string{".gitea/workflows/b.yml", ".gitea/workflows/c.yml"}))
+}
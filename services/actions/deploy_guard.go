@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// checkDeployGuard checks the previous deploy run of the workflow before the production run is created, if setting.Actions.DeployGuard is set,
+// and records the decision on the run. If the run is blocked, the reason is added to run.Errors so it fails when it's created,
+// and if it's queued, the runners won't pick its jobs until the previous run is done.
+func checkDeployGuard(ctx context.Context, run *actions_model.ActionRun) error {
+	if !run.IsProduction || len(setting.Actions.DeployGuard) == 0 {
+		return nil
+	}
+	previous, err := actions_model.GetPreviousDeployRun(ctx, run.RepoID, run.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("GetPreviousDeployRun: %w", err)
+	}
+	if previous == nil {
+		run.DeployGuard = actions_model.DeployGuardPassed
+		return nil
+	}
+
+	decision, reason := decideDeployGuard(previous, setting.Actions.DeployGuard, setting.Actions.DeployGuardTimeout, timeutil.TimeStampNow())
+	run.DeployGuard = decision
+	run.DeployGuardRunID = previous.ID
+	if decision == actions_model.DeployGuardBlocked {
+		run.Errors = append(run.Errors, reason)
+	}
+	return nil
+}
+
+// decideDeployGuard returns the decision of the deploy guard according to the previous deploy run, and the reason if the run is blocked.
+// An unfinished previous run which has been stuck for the timeout is ignored, so a new deploy is never blocked or queued forever by it.
+func decideDeployGuard(previous *actions_model.ActionRun, guard []string, timeout time.Duration, now timeutil.TimeStamp) (string, string) {
+	switch {
+	case actions_model.IsDeployRunStuck(previous, timeout, now):
+		return actions_model.DeployGuardStale, ""
+	case !previous.Status.IsDone():
+		if slices.Contains(guard, setting.DeployGuardBlockOnInProgress) {
+			return actions_model.DeployGuardBlocked, fmt.Sprintf("the previous deploy run #%d of the workflow is still in progress", previous.Index)
+		}
+		if slices.Contains(guard, setting.DeployGuardQueueOnInProgress) {
+			return actions_model.DeployGuardQueued, ""
+		}
+	case previous.Status.IsFailure():
+		if slices.Contains(guard, setting.DeployGuardBlockOnFailure) {
+			return actions_model.DeployGuardBlocked, fmt.Sprintf("the previous deploy run #%d of the workflow failed", previous.Index)
+		}
+	}
+	return actions_model.DeployGuardPassed, ""
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decideDeployGuard(t *testing.T) {
+	const now = timeutil.TimeStamp(100000)
+	previous := func(status actions_model.Status, updated timeutil.TimeStamp) *actions_model.ActionRun {
+		return &actions_model.ActionRun{ID: 10, Index: 3, Status: status, Updated: updated}
+	}
+	block := []string{setting.DeployGuardBlockOnInProgress, setting.DeployGuardBlockOnFailure}
+	queue := []string{setting.DeployGuardQueueOnInProgress}
+
+	cases := []struct {
+		name     string
+		previous *actions_model.ActionRun
+		guard    []string
+		decision string
+		reason   string
+	}{
+		{"in progress blocked", previous(actions_model.StatusRunning, now-60), block, actions_model.DeployGuardBlocked, "the previous deploy run #3 of the workflow is still in progress"},
+		{"waiting queued", previous(actions_model.StatusWaiting, now-60), queue, actions_model.DeployGuardQueued, ""},
+		{"in progress not guarded", previous(actions_model.StatusRunning, now-60), []string{setting.DeployGuardBlockOnFailure}, actions_model.DeployGuardPassed, ""},
+		{"stuck ignored", previous(actions_model.StatusRunning, now-3*3600-1), block, actions_model.DeployGuardStale, ""},
+		{"failure blocked", previous(actions_model.StatusFailure, now-60), block, actions_model.DeployGuardBlocked, "the previous deploy run #3 of the workflow failed"},
+		{"old failure blocked", previous(actions_model.StatusFailure, now-30*86400), block, actions_model.DeployGuardBlocked, "the previous deploy run #3 of the workflow failed"},
+		{"failure not guarded", previous(actions_model.StatusFailure, now-60), queue, actions_model.DeployGuardPassed, ""},
+		{"success", previous(actions_model.StatusSuccess, now-60), block, actions_model.DeployGuardPassed, ""},
+		{"cancelled", previous(actions_model.StatusCancelled, now-60), block, actions_model.DeployGuardPassed, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			decision, reason := decideDeployGuard(c.previous, c.guard, 3*time.Hour, now)
+			assert.Equal(t, c.decision, decision)
+			assert.Equal(t, c.reason, reason)
+		})
+	}
+}
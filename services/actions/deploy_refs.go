@@ -0,0 +1,113 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/gobwas/glob"
+)
+
+// checkDeployRefs returns the errors of the run if it's restricted by any rule of repo_model.ActionsConfig.DeployRefRules
+// and its ref isn't allowed by the rule. The ref is the resolved one which the run checks out, like the base branch
+// for `pull_request_target`, so the runs of all events, including the dispatched ones and the schedules, are checked the same way.
+func checkDeployRefs(ctx context.Context, repo *repo_model.Repository, run *actions_model.ActionRun, content []byte) ([]string, error) {
+	unit, err := repo.GetUnit(ctx, unit_model.TypeActions)
+	if repo_model.IsErrUnitTypeNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("GetUnit: %w", err)
+	}
+	rules := unit.ActionsConfig().DeployRefRules
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	environments, err := actions_module.JobEnvironments(content)
+	if err != nil {
+		return nil, fmt.Errorf("JobEnvironments: %w", err)
+	}
+
+	isProtected := func(branch string) (bool, error) {
+		return git_model.IsBranchProtected(ctx, repo.ID, branch)
+	}
+	var problems []string
+	for _, rule := range rules {
+		problem, err := deployRefViolation(rule, run.WorkflowID, environments, git.RefName(run.Ref), isProtected)
+		if err != nil {
+			return nil, err
+		}
+		if problem != "" && !slices.Contains(problems, problem) {
+			problems = append(problems, problem)
+		}
+	}
+	return problems, nil
+}
+
+// deployRefViolation returns why the rule doesn't allow the workflow with the environments of its jobs to run from the ref,
+// or empty if the rule doesn't restrict the workflow or allows the ref. A pattern which can't be compiled allows nothing.
+func deployRefViolation(rule *repo_model.DeployRefRule, workflowID string, environments []string, ref git.RefName, isProtected func(branch string) (bool, error)) (string, error) {
+	var restricted string
+	if slices.Contains(rule.Workflows, workflowID) {
+		restricted = fmt.Sprintf("workflow %s", workflowID)
+	} else {
+		for _, pattern := range rule.Environments {
+			g, err := glob.Compile(strings.ToLower(pattern))
+			if err != nil {
+				return fmt.Sprintf("invalid environment pattern %q of the deploy ref rules: %v", pattern, err), nil
+			}
+			if i := slices.IndexFunc(environments, g.Match); i >= 0 {
+				restricted = fmt.Sprintf("workflow %s deploying to environment %s", workflowID, environments[i])
+				break
+			}
+		}
+	}
+	if restricted == "" {
+		return "", nil
+	}
+
+	var allowed []string
+	if len(rule.Branches) > 0 {
+		allowed = append(allowed, "branches "+strings.Join(rule.Branches, ", "))
+	}
+	if rule.ProtectedBranches {
+		allowed = append(allowed, "the protected branches")
+	}
+	if len(allowed) == 0 {
+		return fmt.Sprintf("%s can't run from any ref, since its deploy ref rule allows no branches", restricted), nil
+	}
+	problem := fmt.Sprintf("%s can only run from %s, but it runs from %s", restricted, strings.Join(allowed, " or "), ref)
+
+	if !ref.IsBranch() {
+		return problem, nil
+	}
+	branch := ref.BranchName()
+	for _, pattern := range rule.Branches {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return fmt.Sprintf("invalid branch pattern %q of the deploy ref rules: %v", pattern, err), nil
+		}
+		if g.Match(branch) {
+			return "", nil
+		}
+	}
+	if rule.ProtectedBranches {
+		protected, err := isProtected(branch)
+		if err != nil {
+			return "", fmt.Errorf("IsBranchProtected: %w", err)
+		} else if protected {
+			return "", nil
+		}
+	}
+	return problem, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deployRefViolation(t *testing.T) {
+	isProtected := func(branch string) (bool, error) {
+		return branch == "main", nil
+	}
+	byWorkflow := &repo_model.DeployRefRule{Workflows: []string{"deploy.yml"}, ProtectedBranches: true}
+	byEnvironment := &repo_model.DeployRefRule{Environments: []string{"prod-*"}, Branches: []string{"release/*"}}
+	allowsNothing := &repo_model.DeployRefRule{Workflows: []string{"deploy.yml"}}
+
+	tests := []struct {
+		name         string
+		rule         *repo_model.DeployRefRule
+		workflowID   string
+		environments []string
+		ref          git.RefName
+		want         string
+	}{
+		{name: "unrestricted workflow", rule: byWorkflow, workflowID: "ci.yml", ref: "refs/heads/feature"},
+		{name: "protected branch", rule: byWorkflow, workflowID: "deploy.yml", ref: "refs/heads/main"},
+		{
+			name: "unprotected branch", rule: byWorkflow, workflowID: "deploy.yml", ref: "refs/heads/feature",
+			want: "workflow deploy.yml can only run from the protected branches, but it runs from refs/heads/feature",
+		},
+		{
+			name: "tag", rule: byWorkflow, workflowID: "deploy.yml", ref: "refs/tags/main",
+			want: "workflow deploy.yml can only run from the protected branches, but it runs from refs/tags/main",
+		},
+		{
+			name: "pull request", rule: byWorkflow, workflowID: "deploy.yml", ref: "refs/pull/1/head",
+			want: "workflow deploy.yml can only run from the protected branches, but it runs from refs/pull/1/head",
+		},
+		{name: "allowed branch", rule: byEnvironment, workflowID: "ci.yml", environments: []string{"prod-eu"}, ref: "refs/heads/release/v1"},
+		{
+			name: "branch pattern doesn't match nested branches", rule: byEnvironment, workflowID: "ci.yml", environments: []string{"prod-eu"}, ref: "refs/heads/release/v1/hotfix",
+			want: "workflow ci.yml deploying to environment prod-eu can only run from branches release/*, but it runs from refs/heads/release/v1/hotfix",
+		},
+		{name: "other environment", rule: byEnvironment, workflowID: "ci.yml", environments: []string{"staging"}, ref: "refs/heads/feature"},
+		{
+			name: "no branches allowed", rule: allowsNothing, workflowID: "deploy.yml", ref: "refs/heads/main",
+			want: "workflow deploy.yml can't run from any ref, since its deploy ref rule allows no branches",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deployRefViolation(tt.rule, tt.workflowID, tt.environments, tt.ref, isProtected)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
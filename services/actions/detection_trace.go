@@ -0,0 +1,37 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"path"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// the checks which decided whether a run needs approval, see actions_model.RunDetectionTrace.Approval
+const (
+	approvalForkPullRequest = "fork_pull_request" // the pull request is from a fork and its poster isn't trusted
+	approvalLabel           = "approval_label"    // the commit of the pull request from a fork has been approved by the approval label, so the run needn't approval
+	approvalProduction      = "production"        // the run deploys to production, see setting.Actions.ProductionApproval
+	approvalProtectedTag    = "protected_tag"     // the run was triggered by a protected tag, see setting.Actions.ProtectedTagApproval
+)
+
+// newDetectionTrace returns the trace of why the run is created, it's nil if setting.Actions.DetectionTrace is disabled.
+// The run should have been decided whether it needs approval, and policyReason is the reason of the policy webhook which allowed it.
+func newDetectionTrace(run *actions_model.ActionRun, approval []string, policyReason string) *actions_model.RunDetectionTrace {
+	if !setting.Actions.DetectionTrace {
+		return nil
+	}
+	return &actions_model.RunDetectionTrace{
+		Workflow:     path.Join(run.WorkflowDir, run.WorkflowID),
+		Event:        string(run.Event),
+		Match:        run.TriggerMatch,
+		Trusted:      !run.IsForkPullRequest || run.TriggerEvent == actions_module.GithubEventPullRequestTarget,
+		NeedApproval: run.NeedApproval,
+		Approval:     approval,
+		Policy:       policyReason,
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDetectionTrace(t *testing.T) {
+	match := &actions_model.RunTriggerMatch{Event: "pull_request", ActivityType: "opened", Ref: "main"}
+	run := &actions_model.ActionRun{
+		WorkflowDir:       ".gitea/workflows",
+		WorkflowID:        "test.yml",
+		Event:             webhook_module.HookEventPullRequest,
+		EventPayload:      `{"secret": "value"}`,
+		TriggerEvent:      "pull_request",
+		TriggerMatch:      match,
+		IsForkPullRequest: true,
+		NeedApproval:      true,
+	}
+
+	assert.Nil(t, newDetectionTrace(run, []string{approvalForkPullRequest}, ""))
+
+	defer test.MockVariableValue(&setting.Actions.DetectionTrace, true)()
+	assert.Equal(t, &actions_model.RunDetectionTrace{
+		Workflow:     ".gitea/workflows/test.yml",
+		Event:        "pull_request",
+		Match:        match,
+		Trusted:      false,
+		NeedApproval: true,
+		Approval:     []string{approvalForkPullRequest},
+		Policy:       "reviewed",
+	}, newDetectionTrace(run, []string{approvalForkPullRequest}, "reviewed"))
+
+	// the runs of pull_request_target have the access of the base repository
+	run.TriggerEvent = "pull_request_target"
+	run.NeedApproval = false
+	trace := newDetectionTrace(run, nil, "")
+	assert.True(t, trace.Trusted)
+	assert.False(t, trace.NeedApproval)
+	assert.Empty(t, trace.Approval)
+}
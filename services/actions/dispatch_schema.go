@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// maxClientPayloadSchemaErrors is the max number of the errors reported for a client payload which doesn't match its schema
+const maxClientPayloadSchemaErrors = 10
+
+// validateClientPayloadSchema validates the client payload of a `repository_dispatch` event against the JSON schema of the event type
+// in the actions config of the repository, see repo_model.ActionsConfig.DispatchSchemas. It returns an invalid argument error
+// with the locations of the offending fields if the payload doesn't match the schema.
+func validateClientPayloadSchema(ctx context.Context, repo *repo_model.Repository, eventType string, clientPayload map[string]any) error {
+	unit, err := repo.GetUnit(ctx, unit_model.TypeActions)
+	if repo_model.IsErrUnitTypeNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("GetUnit: %w", err)
+	}
+	schema, ok := unit.ActionsConfig().DispatchSchemas[eventType]
+	if !ok {
+		return nil
+	}
+	return validateJSONSchema(schema, clientPayload, "client_payload")
+}
+
+// validateJSONSchema validates the value against the JSON schema, the errors are prefixed by name.
+// The schema can't reference any schemas except itself and the meta-schemas, so nothing is ever fetched.
+func validateJSONSchema(schema, value any, name string) error {
+	schemaContent, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("invalid schema of %s: %w", name, err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("loading %s is not allowed", s)
+	}
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schemaContent)); err != nil {
+		return fmt.Errorf("invalid schema of %s: %w", name, err)
+	}
+	sch, err := compiler.Compile("schema.json")
+	if err != nil {
+		return fmt.Errorf("invalid schema of %s: %w", name, err)
+	}
+
+	// the value is decoded again so it only consists of the raw JSON values, like map[string]any rather than map[string]string
+	content, err := json.Marshal(value)
+	if err != nil {
+		return util.NewInvalidArgumentErrorf("%s is invalid: %v", name, err)
+	}
+	var decoded any
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return util.NewInvalidArgumentErrorf("%s is invalid: %v", name, err)
+	}
+
+	err = sch.Validate(decoded)
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		return util.NewInvalidArgumentErrorf("%s doesn't match its schema: %s", name, formatSchemaErrors(validationErr, name))
+	} else if err != nil {
+		return fmt.Errorf("validate %s: %w", name, err)
+	}
+	return nil
+}
+
+// formatSchemaErrors returns the errors of the offending fields, which are the innermost causes of the validation error
+func formatSchemaErrors(err *jsonschema.ValidationError, name string) string {
+	var details []string
+	var flatten func(*jsonschema.ValidationError)
+	flatten = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			details = append(details, fmt.Sprintf("%s%s: %s", name, e.InstanceLocation, e.Message))
+			return
+		}
+		for _, cause := range e.Causes {
+			flatten(cause)
+		}
+	}
+	flatten(err)
+
+	if len(details) > maxClientPayloadSchemaErrors {
+		details = append(details[:maxClientPayloadSchemaErrors], fmt.Sprintf("and %d more errors", len(details)-maxClientPayloadSchemaErrors))
+	}
+	return strings.Join(details, "; ")
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateJSONSchema(t *testing.T) {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["environment"],
+  "properties": {
+    "environment": {"enum": ["staging", "production"]},
+    "replicas": {"type": "integer", "minimum": 1},
+    "arguments": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 8}}
+  }
+}`), &schema))
+
+	assert.NoError(t, validateJSONSchema(schema, map[string]any{"environment": "staging", "replicas": 2}, "client_payload"))
+	assert.NoError(t, validateJSONSchema(schema, map[string]any{"environment": "production", "arguments": map[string]string{"version": "v1"}}, "client_payload"))
+
+	err := validateJSONSchema(schema, map[string]any{"replicas": 0}, "client_payload")
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	assert.ErrorContains(t, err, "client_payload: missing properties: 'environment'")
+	assert.ErrorContains(t, err, "client_payload/replicas: must be >= 1 but found 0")
+
+	err = validateJSONSchema(schema, map[string]any{"environment": "staging", "arguments": map[string]string{"version": "v1.2.3-rc1"}}, "client_payload")
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	assert.ErrorContains(t, err, "client_payload/arguments/version: length must be <= 8")
+
+	// the schemas are never fetched
+	err = validateJSONSchema(map[string]any{"$ref": "https://example.com/schema.json"}, map[string]any{}, "client_payload")
+	assert.ErrorContains(t, err, "loading https://example.com/schema.json is not allowed")
+	assert.NotErrorIs(t, err, util.ErrInvalidArgument)
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+)
+
+// resolveEnvironmentURLs resolves the `url` of the `environment` of the jobs of the run which has just been created,
+// with the `github`, `inputs`, `vars` and `matrix` contexts. The urls referencing the contexts which are only known
+// when the jobs run, like `steps` and `needs`, are resolved after the jobs are done, see ResolveJobEnvironmentURL.
+func resolveEnvironmentURLs(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) error {
+	var runEnv *exprparser.EvaluationEnvironment
+	for _, job := range jobs {
+		if job.RawEnvironmentURL == "" || actions_module.IsRuntimeEnvironmentURL(job.RawEnvironmentURL) {
+			continue
+		}
+		if runEnv == nil {
+			var err error
+			if runEnv, err = runEvaluationEnvironment(ctx, run); err != nil {
+				return err
+			}
+		}
+		env := *runEnv
+		env.Matrix = jobMatrix(job)
+		if err := updateEnvironmentURL(ctx, job, &env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveJobEnvironmentURL resolves the `url` of the `environment` of the job which has been done, if it references the contexts
+// which are only known when the job runs. The `needs` context has the outputs of the jobs which the job needs,
+// and the `steps` context only has the outputs of the steps which are the outputs of the job, see actions_module.JobStepsContext.
+// It's resolved every time the job is done, so the url of a rerun job is updated too.
+func ResolveJobEnvironmentURL(ctx context.Context, job *actions_model.ActionRunJob) error {
+	if job.RawEnvironmentURL == "" || !actions_module.IsRuntimeEnvironmentURL(job.RawEnvironmentURL) {
+		return nil
+	}
+	if err := job.LoadRun(ctx); err != nil {
+		return fmt.Errorf("LoadRun: %w", err)
+	}
+	env, err := runEvaluationEnvironment(ctx, job.Run)
+	if err != nil {
+		return err
+	}
+	env.Matrix = jobMatrix(job)
+	env.Env = job.Env
+
+	jobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: job.RunID})
+	if err != nil {
+		return fmt.Errorf("FindRunJobs: %w", err)
+	}
+	env.Needs = make(map[string]exprparser.Needs, len(job.Needs))
+	for _, v := range jobs {
+		if !slices.Contains(job.Needs, v.JobID) || v.TaskID == 0 || !v.Status.IsDone() {
+			continue
+		}
+		outputs, err := taskOutputs(ctx, v.TaskID)
+		if err != nil {
+			return err
+		}
+		env.Needs[v.JobID] = exprparser.Needs{Outputs: outputs, Result: v.Status.String()}
+	}
+
+	if job.TaskID != 0 {
+		outputs, err := taskOutputs(ctx, job.TaskID)
+		if err != nil {
+			return err
+		}
+		var declared map[string]string
+		if wfs, err := jobparser.Parse(job.WorkflowPayload); err == nil && len(wfs) > 0 {
+			if _, j := wfs[0].Job(); j != nil {
+				declared = j.Outputs
+			}
+		}
+		env.Steps = actions_module.JobStepsContext(declared, outputs)
+	}
+
+	return updateEnvironmentURL(ctx, job, env)
+}
+
+// runEvaluationEnvironment returns the contexts of the run for evaluating the `url` of the `environment` of its jobs,
+// the `github` context is rebuilt from the run like when it was created.
+func runEvaluationEnvironment(ctx context.Context, run *actions_model.ActionRun) (*exprparser.EvaluationEnvironment, error) {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, fmt.Errorf("LoadAttributes: %w", err)
+	}
+	event := map[string]any{}
+	if run.EventPayload != "" {
+		if err := json.Unmarshal([]byte(run.EventPayload), &event); err != nil {
+			return nil, fmt.Errorf("unmarshal the event payload of run %d: %w", run.ID, err)
+		}
+	}
+	vars, err := actions_model.GetVariablesOfRepo(ctx, run.OwnerID, run.RepoID)
+	if err != nil {
+		return nil, fmt.Errorf("GetVariablesOfRepo: %w", err)
+	}
+	inputs, _ := event["inputs"].(map[string]any)
+	gitCtx := runGitContext(run, &notifyInput{Repo: run.Repo, Doer: run.TriggerUser}, event)
+	gitCtx.RunID = fmt.Sprint(run.ID)
+	gitCtx.RunNumber = fmt.Sprint(run.Index)
+	return &exprparser.EvaluationEnvironment{
+		Github: gitCtx,
+		Inputs: inputs,
+		Vars:   vars,
+	}, nil
+}
+
+// jobMatrix returns the `matrix` context of the job, the matrix of a leg has only one value of each key
+func jobMatrix(job *actions_model.ActionRunJob) map[string]any {
+	matrix := map[string]any{}
+	wfs, err := jobparser.Parse(job.WorkflowPayload)
+	if err != nil || len(wfs) == 0 {
+		return matrix
+	}
+	_, j := wfs[0].Job()
+	if j == nil || j.Strategy.RawMatrix.Kind == 0 {
+		return matrix
+	}
+	var values map[string][]any
+	if err := j.Strategy.RawMatrix.Decode(&values); err != nil {
+		log.Warn("unable to decode the matrix of job %d: %v", job.ID, err)
+		return matrix
+	}
+	for k, v := range values {
+		if len(v) > 0 {
+			matrix[k] = v[0]
+		}
+	}
+	return matrix
+}
+
+// taskOutputs returns the outputs which the task has sent
+func taskOutputs(ctx context.Context, taskID int64) (map[string]string, error) {
+	got, err := actions_model.FindTaskOutputByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
+	}
+	outputs := make(map[string]string, len(got))
+	for _, v := range got {
+		outputs[v.OutputKey] = v.OutputValue
+	}
+	return outputs, nil
+}
+
+// updateEnvironmentURL evaluates the `url` of the `environment` of the job and saves it, the url which can't be evaluated
+// to a valid URL is logged and saved as empty, so the link isn't shown.
+func updateEnvironmentURL(ctx context.Context, job *actions_model.ActionRunJob, env *exprparser.EvaluationEnvironment) error {
+	url, err := actions_module.EvaluateEnvironmentURL(job.RawEnvironmentURL, env)
+	if err != nil {
+		log.Warn("job %d of run %d: %v", job.ID, job.RunID, err)
+	}
+	if url == job.EnvironmentURL {
+		return nil
+	}
+	job.EnvironmentURL = url
+	if _, err := actions_model.UpdateRunJob(ctx, job, nil, "environment_url"); err != nil {
+		return fmt.Errorf("UpdateRunJob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// failFastLegs returns the legs of the matrix jobs which should be cancelled like GitHub, since another leg of the same matrix job
+// has failed and `fail-fast` is true. A failed leg with `continue-on-error` doesn't cancel others,
+// and the legs which have been updated after the failure, like the re-run ones, are kept.
+func failFastLegs(jobs []*actions_model.ActionRunJob) []*actions_model.ActionRunJob {
+	failedAt := map[string]timeutil.TimeStamp{} // the latest time when a leg failed, keyed by job id
+	for _, job := range jobs {
+		if job.FailFast && !job.ContinueOnError && job.Status == actions_model.StatusFailure && job.Stopped > failedAt[job.JobID] {
+			failedAt[job.JobID] = job.Stopped
+		}
+	}
+	if len(failedAt) == 0 {
+		return nil
+	}
+
+	var ret []*actions_model.ActionRunJob
+	for _, job := range jobs {
+		if at, ok := failedAt[job.JobID]; ok && !job.Status.IsDone() && job.Updated <= at {
+			ret = append(ret, job)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_failFastLegs(t *testing.T) {
+	leg := func(id int64, jobID string, failFast bool, status actions_model.Status) *actions_model.ActionRunJob {
+		job := &actions_model.ActionRunJob{ID: id, JobID: jobID, FailFast: failFast, Status: status, Updated: 100}
+		if status.IsDone() {
+			job.Stopped = 200
+		}
+		return job
+	}
+	ids := func(jobs []*actions_model.ActionRunJob) []int64 {
+		var ret []int64
+		for _, job := range jobs {
+			ret = append(ret, job.ID)
+		}
+		return ret
+	}
+
+	t.Run("fail-fast", func(t *testing.T) {
+		jobs := []*actions_model.ActionRunJob{
+			leg(1, "test", true, actions_model.StatusFailure),
+			leg(2, "test", true, actions_model.StatusRunning),
+			leg(3, "test", true, actions_model.StatusWaiting),
+			leg(4, "test", true, actions_model.StatusSuccess),
+			leg(5, "build", false, actions_model.StatusRunning), // another job isn't cancelled
+		}
+		assert.Equal(t, []int64{2, 3}, ids(failFastLegs(jobs)))
+	})
+
+	t.Run("fail-fast false", func(t *testing.T) {
+		jobs := []*actions_model.ActionRunJob{
+			leg(1, "test", false, actions_model.StatusFailure),
+			leg(2, "test", false, actions_model.StatusRunning),
+			leg(3, "test", false, actions_model.StatusWaiting),
+		}
+		assert.Empty(t, failFastLegs(jobs))
+	})
+
+	t.Run("continue-on-error", func(t *testing.T) {
+		experimental := leg(1, "test", true, actions_model.StatusFailure)
+		experimental.ContinueOnError = true
+		jobs := []*actions_model.ActionRunJob{
+			experimental,
+			leg(2, "test", true, actions_model.StatusRunning),
+			leg(3, "test", true, actions_model.StatusWaiting),
+		}
+		assert.Empty(t, failFastLegs(jobs))
+	})
+
+	t.Run("cancelled leg", func(t *testing.T) {
+		jobs := []*actions_model.ActionRunJob{
+			leg(1, "test", true, actions_model.StatusCancelled),
+			leg(2, "test", true, actions_model.StatusRunning),
+			leg(3, "test", true, actions_model.StatusWaiting),
+		}
+		assert.Empty(t, failFastLegs(jobs))
+	})
+
+	t.Run("re-run leg", func(t *testing.T) {
+		rerun := leg(2, "test", true, actions_model.StatusWaiting)
+		rerun.Updated = 300
+		jobs := []*actions_model.ActionRunJob{
+			leg(1, "test", true, actions_model.StatusFailure),
+			rerun,
+		}
+		assert.Empty(t, failFastLegs(jobs))
+	})
+}
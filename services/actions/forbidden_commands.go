@@ -0,0 +1,29 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// checkForbiddenCommands checks the `run` steps of the workflow against the rules of [actions.forbidden_commands],
+// except the rules allowed by the actions config of the repository, and returns the problems which should fail the run
+func checkForbiddenCommands(ctx context.Context, repo *repo_model.Repository, content []byte) ([]string, error) {
+	if len(setting.Actions.ForbiddenCommands) == 0 {
+		return nil, nil
+	}
+	cfg := repo.MustGetUnit(ctx, unit.TypeActions).ActionsConfig()
+	rules := make([]*setting.ForbiddenCommand, 0, len(setting.Actions.ForbiddenCommands))
+	for _, rule := range setting.Actions.ForbiddenCommands {
+		if !cfg.IsForbiddenCommandAllowed(rule.Name) {
+			rules = append(rules, rule)
+		}
+	}
+	return actions_module.CheckForbiddenCommands(content, rules, setting.Actions.ForbiddenCommandsLimit)
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// CanDecideGate reports whether the doer can approve or reject the manual gate of the job.
+// Only the reviewers can decide if the gate has reviewers, otherwise anyone with write permission for actions can.
+func CanDecideGate(ctx context.Context, job *actions_model.ActionRunJob, doer *user_model.User) (bool, error) {
+	if job.Gate == nil || doer == nil {
+		return false, nil
+	}
+	if len(job.Gate.Reviewers) > 0 {
+		for _, reviewer := range job.Gate.Reviewers {
+			if strings.EqualFold(reviewer, doer.Name) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if err := job.LoadAttributes(ctx); err != nil {
+		return false, fmt.Errorf("LoadAttributes: %w", err)
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, job.Run.Repo, doer)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %w", err)
+	}
+	return perm.CanWrite(unit_model.TypeActions), nil
+}
+
+// DecideGate approves or rejects the manual gate of the job which is waiting for a decision,
+// the job succeeds if it's approved, or fails if it's rejected so that the jobs which need it will be skipped.
+// The doer is nil if the gate is rejected since timeout.
+func DecideGate(ctx context.Context, job *actions_model.ActionRunJob, doer *user_model.User, approve bool) error {
+	if !job.IsAwaitingGate() {
+		return util.NewInvalidArgumentErrorf("job %d is not a gate waiting for a decision", job.ID)
+	}
+
+	deadline := job.GateDeadline
+	now := timeutil.TimeStampNow()
+	job.Status = actions_model.StatusFailure
+	if approve {
+		job.Status = actions_model.StatusSuccess
+	}
+	job.Started = deadline.AddDuration(-job.Gate.Timeout)
+	job.Stopped = now
+	job.GateDecidedBy = 0
+	if doer != nil {
+		job.GateDecidedBy = doer.ID
+	}
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": actions_model.StatusBlocked, "gate_deadline": deadline},
+			"status", "started", "stopped", "gate_decided_by")
+		if err != nil {
+			return err
+		} else if n == 0 {
+			return util.NewInvalidArgumentErrorf("the gate of job %d has been decided", job.ID)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	CreateCommitStatus(ctx, job)
+	return EmitJobsIfReady(job.RunID)
+}
+
+// RejectExpiredGates rejects the manual gates which haven't been decided before their deadlines
+func RejectExpiredGates(ctx context.Context) error {
+	jobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{
+		Statuses:           []actions_model.Status{actions_model.StatusBlocked},
+		GateDeadlineBefore: timeutil.TimeStampNow(),
+	})
+	if err != nil {
+		return fmt.Errorf("find expired gates: %w", err)
+	}
+
+	for _, job := range jobs {
+		if err := DecideGate(ctx, job, nil, false); err != nil {
+			log.Warn("reject expired gate of job %d: %v", job.ID, err)
+			// go on
+			continue
+		}
+		log.Trace("the gate of job %d of run %d has been rejected since timeout", job.ID, job.RunID)
+	}
+	return nil
+}
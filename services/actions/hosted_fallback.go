@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// isHostedFallbackPermitted reports whether the jobs of the runs of the repository may fall back to the hosted runners,
+// private repositories never fall back unless their owners are allowed by setting.Actions.HostedFallbackOwners.
+func isHostedFallbackPermitted(repo *repo_model.Repository) bool {
+	if !setting.Actions.HostedFallbackPolicy {
+		return true
+	}
+	if !repo.IsPrivate {
+		return setting.Actions.HostedFallbackPublic
+	}
+	for _, owner := range setting.Actions.HostedFallbackOwners {
+		if strings.EqualFold(owner, repo.OwnerName) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditHostedFallback records a system notice when the task has fallen back to a hosted runner
+func AuditHostedFallback(ctx context.Context, runner *actions_model.ActionRunner, task *actions_model.ActionTask) {
+	if !setting.Actions.HostedFallbackPolicy || !runner.IsHosted() {
+		return
+	}
+	if err := system_model.CreateNotice(ctx, system_model.NoticeTask, "Job %d of run %d in repository %s has fallen back to hosted runner %s (id: %d), task id: %d",
+		task.JobID, task.Job.RunID, task.Job.Run.Repo.FullName(), runner.Name, runner.ID, task.ID); err != nil {
+		log.Error("CreateNotice: %v", err)
+	}
+}
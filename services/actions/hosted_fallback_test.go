@@ -0,0 +1,33 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isHostedFallbackPermitted(t *testing.T) {
+	publicRepo := &repo_model.Repository{OwnerName: "user2"}
+	privateRepo := &repo_model.Repository{OwnerName: "user2", IsPrivate: true}
+
+	// everything may run on the hosted runners if the policy is not enabled
+	assert.True(t, isHostedFallbackPermitted(publicRepo))
+	assert.True(t, isHostedFallbackPermitted(privateRepo))
+
+	defer test.MockVariableValue(&setting.Actions.HostedFallbackPolicy, true)()
+	assert.True(t, isHostedFallbackPermitted(publicRepo))
+	assert.False(t, isHostedFallbackPermitted(privateRepo))
+
+	defer test.MockVariableValue(&setting.Actions.HostedFallbackOwners, []string{"User2"})()
+	assert.True(t, isHostedFallbackPermitted(privateRepo))
+
+	defer test.MockVariableValue(&setting.Actions.HostedFallbackPublic, false)()
+	assert.False(t, isHostedFallbackPermitted(publicRepo))
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+)
+
+func init() {
+	registerRunCompletedHook("infra_retry", retryInfraFailedRun)
+}
+
+// retryInfraFailedRun reruns the failed jobs of the run and the jobs which need them, if all of its failed jobs failed by the infrastructure,
+// up to repo_model.ActionsConfig.InfraFailureRetries times. The runs with any job failed by the workflow are never rerun.
+// The rerun jobs get new attempts like the manual reruns, and the run records how many times it has been rerun automatically.
+func retryInfraFailedRun(ctx context.Context, run *actions_model.ActionRun) error {
+	if run.Status != actions_model.StatusFailure {
+		return nil
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		return fmt.Errorf("LoadAttributes: %w", err)
+	}
+	unit, err := run.Repo.GetUnit(ctx, unit_model.TypeActions)
+	if repo_model.IsErrUnitTypeNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("GetUnit: %w", err)
+	}
+	if run.InfraRetries >= unit.ActionsConfig().InfraFailureRetries {
+		return nil
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	jobIDs := infraFailedJobIDs(jobs)
+	if len(jobIDs) == 0 {
+		return nil
+	}
+
+	// the retry is counted with the rerun in a transaction, so the hook retried since the rerun failed doesn't count it again
+	run.InfraRetries++
+	if err := rerunFromJobs(ctx, run, []string{"infra_retries"}, jobIDs...); err != nil {
+		return err
+	}
+	log.Info("rerun jobs %v of run %d of repo %s automatically for the %d time since they failed by the infrastructure", jobIDs, run.Index, run.Repo.FullName(), run.InfraRetries)
+	return nil
+}
+
+// infraFailedJobIDs returns the ids of the failed jobs in the workflow if all of them failed by the infrastructure, or nil if any failed otherwise
+func infraFailedJobIDs(jobs []*actions_model.ActionRunJob) []string {
+	var jobIDs []string
+	seen := make(container.Set[string])
+	for _, job := range jobs {
+		if job.Status != actions_model.StatusFailure {
+			continue
+		}
+		if job.FailureCause != actions_model.FailureCauseInfra {
+			return nil
+		}
+		if seen.Add(job.JobID) {
+			jobIDs = append(jobIDs, job.JobID)
+		}
+	}
+	return jobIDs
+}
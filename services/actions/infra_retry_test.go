@@ -0,0 +1,62 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_infraFailedJobIDs(t *testing.T) {
+	job := func(jobID string, status actions_model.Status, cause string) *actions_model.ActionRunJob {
+		return &actions_model.ActionRunJob{JobID: jobID, Status: status, FailureCause: cause}
+	}
+
+	assert.Equal(t, []string{"build", "test"}, infraFailedJobIDs([]*actions_model.ActionRunJob{
+		job("lint", actions_model.StatusSuccess, ""),
+		job("build", actions_model.StatusFailure, actions_model.FailureCauseInfra),
+		job("build", actions_model.StatusFailure, actions_model.FailureCauseInfra),
+		job("test", actions_model.StatusFailure, actions_model.FailureCauseInfra),
+		job("deploy", actions_model.StatusSkipped, ""),
+	}))
+	// the logic failures are never retried, even with infra failures in the same run
+	assert.Nil(t, infraFailedJobIDs([]*actions_model.ActionRunJob{
+		job("build", actions_model.StatusFailure, actions_model.FailureCauseInfra),
+		job("test", actions_model.StatusFailure, actions_model.FailureCauseLogic),
+	}))
+	// the failures recorded before the causes were recorded are unknown
+	assert.Nil(t, infraFailedJobIDs([]*actions_model.ActionRunJob{job("build", actions_model.StatusFailure, "")}))
+	assert.Nil(t, infraFailedJobIDs([]*actions_model.ActionRunJob{job("build", actions_model.StatusSuccess, "")}))
+}
+
+func Test_retryInfraFailedRunNotRerun(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	repo.Units = []*repo_model.RepoUnit{{RepoID: 1, Type: unit.TypeActions, Config: &repo_model.ActionsConfig{InfraFailureRetries: 1}}}
+	run := &actions_model.ActionRun{RepoID: 1, OwnerID: 2, Index: 1001, WorkflowID: "test.yml", TriggerUserID: 2, Status: actions_model.StatusFailure, Started: 1, Stopped: 2}
+	require.NoError(t, db.Insert(db.DefaultContext, run))
+	require.NoError(t, db.Insert(db.DefaultContext, []*actions_model.ActionRunJob{
+		{RunID: run.ID, RepoID: 1, OwnerID: 2, Name: "build", JobID: "build", Status: actions_model.StatusSuccess},
+		{RunID: run.ID, RepoID: 1, OwnerID: 2, Name: "test", JobID: "test", Needs: []string{"build"}, Status: actions_model.StatusFailure, FailureCause: actions_model.FailureCauseInfra},
+	}))
+	// the rerun fails since the artifacts uploaded by build have expired
+	require.NoError(t, db.Insert(db.DefaultContext, &actions_model.ActionArtifact{RunID: run.ID, RepoID: 1, OwnerID: 2, ArtifactName: "dist", ArtifactPath: "dist.zip", Status: int64(actions_model.ArtifactStatusExpired)}))
+
+	run.Repo = repo
+	require.Error(t, retryInfraFailedRun(db.DefaultContext, run))
+	// the retry isn't counted, so the hook retried later still reruns the jobs
+	run = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID})
+	assert.EqualValues(t, 0, run.InfraRetries)
+	assert.EqualValues(t, 2, run.Stopped)
+	assert.Equal(t, actions_model.StatusFailure, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: run.ID, JobID: "test"}).Status)
+}
@@ -22,5 +22,25 @@ func Init() {
 	}
 	go graceful.GetManager().RunWithCancel(jobEmitterQueue)
 
+	pullChecksQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "actions_pull_checks", pullChecksQueueHandler)
+	if pullChecksQueue == nil {
+		log.Fatal("Unable to create actions_pull_checks queue")
+	}
+	go graceful.GetManager().RunWithCancel(pullChecksQueue)
+
+	runCompletedQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "actions_run_completed", runCompletedQueueHandler)
+	if runCompletedQueue == nil {
+		log.Fatal("Unable to create actions_run_completed queue")
+	}
+	go graceful.GetManager().RunWithCancel(runCompletedQueue)
+
+	if err := initWorkflowsCache(); err != nil {
+		log.Fatal("Unable to init actions workflows cache: %v", err)
+	}
+	initRunPolicy()
+	initAuditExporter()
+	initRunEventPublisher()
+	initTracing()
+
 	notify_service.RegisterNotifier(NewNotifier())
 }
@@ -11,6 +11,7 @@ import (
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/queue"
 
 	"xorm.io/builder"
@@ -48,6 +49,14 @@ func checkJobsOfRun(ctx context.Context, runID int64) error {
 	if err != nil {
 		return err
 	}
+	if legs := failFastLegs(jobs); len(legs) > 0 {
+		if err := actions_model.CancelJobs(ctx, legs); err != nil {
+			return fmt.Errorf("cancel the legs of matrix jobs for fail-fast: %w", err)
+		}
+		if jobs, err = db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: runID}); err != nil {
+			return err
+		}
+	}
 	if err := db.WithTx(ctx, func(ctx context.Context) error {
 		idToJobs := make(map[string][]*actions_model.ActionRunJob, len(jobs))
 		for _, job := range jobs {
@@ -57,6 +66,13 @@ func checkJobsOfRun(ctx context.Context, runID int64) error {
 		updates := newJobStatusResolver(jobs).Resolve()
 		for _, job := range jobs {
 			if status, ok := updates[job.ID]; ok {
+				if status == actions_model.StatusWaiting && job.Gate != nil {
+					// gates never run on runners, they wait for decisions instead
+					if err := actions_model.ReachGate(ctx, job); err != nil {
+						return err
+					}
+					continue
+				}
 				job.Status = status
 				if n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": actions_model.StatusBlocked}, "status"); err != nil {
 					return err
@@ -70,6 +86,14 @@ func checkJobsOfRun(ctx context.Context, runID int64) error {
 		return err
 	}
 	CreateCommitStatus(ctx, jobs...)
+
+	run, err := actions_model.GetRunByID(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if err := notifyRunCompleted(ctx, run); err != nil {
+		log.Error("notifyRunCompleted [run: %d]: %v", runID, err)
+	}
 	return nil
 }
 
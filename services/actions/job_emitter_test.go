@@ -61,6 +61,21 @@ func Test_jobStatusResolver_Resolve(t *testing.T) {
 				3: actions_model.StatusSkipped,
 			},
 		},
+		{
+			name: "cancelled need",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "1", Status: actions_model.StatusCancelled, Needs: []string{}},
+				{ID: 2, JobID: "2", Status: actions_model.StatusBlocked, Needs: []string{"1"}},
+				{ID: 3, JobID: "3", Status: actions_model.StatusBlocked, Needs: []string{"2"}},
+				{ID: 4, JobID: "4", Status: actions_model.StatusSuccess, Needs: []string{}},
+				{ID: 5, JobID: "5", Status: actions_model.StatusBlocked, Needs: []string{"4"}},
+			},
+			want: map[int64]actions_model.Status{
+				2: actions_model.StatusSkipped,
+				3: actions_model.StatusSkipped,
+				5: actions_model.StatusWaiting,
+			},
+		},
 		{
 			name: "loop need",
 			jobs: actions_model.ActionJobList{
@@ -0,0 +1,95 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func init() {
+	// the runs which fail to be archived are retried by the hook, and the logs which are still missed are archived by ArchiveAndPruneLogs later
+	registerRunCompletedHook("log_archive", ArchiveRunLogs)
+}
+
+// logArchiveBatchSize is the max number of the tasks whose logs are archived or pruned by ArchiveAndPruneLogs at a time
+const logArchiveBatchSize = 100
+
+// ArchiveRunLogs archives the logs of the tasks of the run to the storage of [storage.actions_log_archive], and records where they are.
+// The logs which are still in the database since their tasks haven't finished are archived by ArchiveAndPruneLogs later.
+// The failures are retried by runCompletedQueue, and the local copies are always kept, they are removed by ArchiveAndPruneLogs after the grace period.
+func ArchiveRunLogs(ctx context.Context, run *actions_model.ActionRun) error {
+	if !setting.Actions.LogArchive {
+		return nil
+	}
+	tasks, err := actions_model.FindTasksToArchiveLogs(ctx, run.ID, 0)
+	if err != nil {
+		return fmt.Errorf("FindTasksToArchiveLogs: %w", err)
+	}
+	return archiveTasksLogs(ctx, tasks)
+}
+
+func archiveTasksLogs(ctx context.Context, tasks []*actions_model.ActionTask) error {
+	var errs []error
+	for _, task := range tasks {
+		path, err := actions_module.ArchiveLogs(task.LogFilename)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %w", task.ID, err))
+			continue
+		}
+		task.LogArchivePath = path
+		task.LogArchivedUnix = timeutil.TimeStampNow()
+		if err := actions_model.UpdateTask(ctx, task, "log_archive_path", "log_archived_unix"); err != nil {
+			errs = append(errs, fmt.Errorf("task %d: UpdateTask: %w", task.ID, err))
+			continue
+		}
+		log.Trace("logs of task %d have been archived to %q", task.ID, path)
+	}
+	return errors.Join(errs...)
+}
+
+// ArchiveAndPruneLogs archives the logs of the finished tasks which haven't been archived, like the ones which failed to be archived
+// when their runs completed, and removes the local copies of the logs archived longer than setting.Actions.LogArchivePruneAfter.
+func ArchiveAndPruneLogs(ctx context.Context) error {
+	if !setting.Actions.LogArchive {
+		return nil
+	}
+	tasks, err := actions_model.FindTasksToArchiveLogs(ctx, 0, logArchiveBatchSize)
+	if err != nil {
+		return fmt.Errorf("FindTasksToArchiveLogs: %w", err)
+	}
+	if err := archiveTasksLogs(ctx, tasks); err != nil {
+		log.Warn("archive the logs of %d tasks: %v", len(tasks), err)
+	}
+
+	if setting.Actions.LogArchivePruneAfter <= 0 {
+		return nil
+	}
+	tasks, err = actions_model.FindTasksToPruneLogs(ctx, timeutil.TimeStamp(time.Now().Add(-setting.Actions.LogArchivePruneAfter).Unix()), logArchiveBatchSize)
+	if err != nil {
+		return fmt.Errorf("FindTasksToPruneLogs: %w", err)
+	}
+	for _, task := range tasks {
+		// mark it pruned first, so the log is never read from the removed local copy
+		task.LogPruned = true
+		if err := actions_model.UpdateTask(ctx, task, "log_pruned"); err != nil {
+			log.Warn("UpdateTask [task: %d]: %v", task.ID, err)
+			continue
+		}
+		if err := actions_module.RemoveLogs(ctx, task.LogInStorage, task.LogFilename); err != nil {
+			log.Warn("RemoveLogs [task: %d]: %v", task.ID, err)
+			continue
+		}
+		log.Trace("local logs of task %d have been pruned", task.ID)
+	}
+	return nil
+}
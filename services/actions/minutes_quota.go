@@ -0,0 +1,97 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// checkMinutesQuota fails the new jobs of the run immediately if the owner has used up the runner minutes of the current period
+// and setting.Actions.MinutesQuotaPolicy is "fail". With the "queue" policy, the jobs are left waiting and the run is marked as queued by the quota,
+// and the runners won't pick them until the quota is reset in the next period.
+// The jobs which self-hosted runners can run are not failed unless their minutes count against the quota too.
+func checkMinutesQuota(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) error {
+	if setting.Actions.MinutesQuota <= 0 || len(jobs) == 0 {
+		return nil
+	}
+	exhausted, err := actions_model.IsMinutesQuotaExhausted(ctx, run.OwnerID)
+	if err != nil {
+		return fmt.Errorf("IsMinutesQuotaExhausted: %w", err)
+	} else if !exhausted {
+		return nil
+	}
+	if setting.Actions.MinutesQuotaPolicy != setting.MinutesQuotaPolicyFail {
+		if err := actions_model.MarkRunsQuotaQueued(ctx, true, run.ID); err != nil {
+			return fmt.Errorf("MarkRunsQuotaQueued: %w", err)
+		}
+		run.QuotaQueued = true
+		return nil
+	}
+
+	var selfHosted []*actions_model.ActionRunner
+	if !setting.Actions.MinutesQuotaSelfHosted {
+		runners, err := db.Find[actions_model.ActionRunner](ctx, actions_model.FindRunnerOptions{
+			RepoID:        run.RepoID,
+			WithAvailable: true,
+		})
+		if err != nil {
+			return fmt.Errorf("FindRunners: %w", err)
+		}
+		for _, runner := range runners {
+			if !runner.IsHosted() {
+				selfHosted = append(selfHosted, runner)
+			}
+		}
+	}
+
+	var toFail []*actions_model.ActionRunJob
+	for _, job := range jobs {
+		// the jobs which need others will be skipped when the jobs they need fail
+		if job.Status.IsDone() || len(job.Needs) > 0 || canAnyRunnerMatch(selfHosted, job.EffectiveRunsOn()) {
+			continue
+		}
+		toFail = append(toFail, job)
+	}
+	if len(toFail) == 0 {
+		return nil
+	}
+
+	// reload the run since its version may have been changed by updating its jobs
+	run, err = actions_model.GetRunByID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunByID: %w", err)
+	}
+	run.QuotaExceeded = true
+	if err := actions_model.UpdateRun(ctx, run, "quota_exceeded"); err != nil {
+		return fmt.Errorf("UpdateRun: %w", err)
+	}
+
+	for _, job := range toFail {
+		log.Info("job %q of run %d fails since the owner %d has used up the runner minutes", job.Name, job.RunID, run.OwnerID)
+		status := job.Status
+		job.Status = actions_model.StatusFailure
+		job.Stopped = timeutil.TimeStampNow()
+		if err := db.WithTx(ctx, func(ctx context.Context) error {
+			_, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "status", "stopped")
+			return err
+		}); err != nil {
+			return fmt.Errorf("UpdateRunJob: %w", err)
+		}
+	}
+
+	// the jobs which need the failed jobs should be skipped
+	if err := EmitJobsIfReady(run.ID); err != nil {
+		log.Error("Emit ready jobs of run %d: %v", run.ID, err)
+	}
+	return nil
+}
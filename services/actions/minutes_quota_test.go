@@ -0,0 +1,54 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkMinutesQuotaQueued(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.MinutesQuota, 10)()
+	defer test.MockVariableValue(&setting.Actions.MinutesQuotaPolicy, setting.MinutesQuotaPolicyQueue)()
+
+	newRun := func(index int64) (*actions_model.ActionRun, []*actions_model.ActionRunJob) {
+		run := &actions_model.ActionRun{RepoID: 1, OwnerID: 2, Index: index, WorkflowID: "test.yml", TriggerUserID: 2, Status: actions_model.StatusWaiting}
+		require.NoError(t, db.Insert(db.DefaultContext, run))
+		job := &actions_model.ActionRunJob{RunID: run.ID, RepoID: 1, OwnerID: 2, Name: "test", JobID: "test", Status: actions_model.StatusWaiting}
+		require.NoError(t, db.Insert(db.DefaultContext, job))
+		return run, []*actions_model.ActionRunJob{job}
+	}
+
+	run, jobs := newRun(1001)
+	require.NoError(t, checkMinutesQuota(db.DefaultContext, run, jobs))
+	assert.False(t, run.QuotaQueued)
+
+	// the owner has used up the runner minutes
+	require.NoError(t, db.Insert(db.DefaultContext, &actions_model.ActionMinutesUsage{
+		OwnerID: 2,
+		Period:  actions_model.MinutesQuotaPeriodKey(time.Now(), setting.Actions.MinutesQuotaPeriod),
+		Seconds: 10 * 60,
+	}))
+	run, jobs = newRun(1002)
+	require.NoError(t, checkMinutesQuota(db.DefaultContext, run, jobs))
+	assert.True(t, run.QuotaQueued)
+	// the jobs keep waiting and the view reads the state without checking the usage
+	run = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID})
+	assert.True(t, run.QuotaQueued)
+	assert.Equal(t, actions_model.StatusWaiting, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: jobs[0].ID}).Status)
+
+	// it's cleared once a runner picks the job since the quota has been reset
+	require.NoError(t, actions_model.MarkRunsQuotaQueued(db.DefaultContext, false, run.ID))
+	assert.False(t, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID}).QuotaQueued)
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// handleMissingCommit handles the event whose commit doesn't exist any longer according to setting.Actions.MissingCommitPolicy.
+// It's common for the delayed events, the commit may have been garbage collected or the ref may have been deleted,
+// so the workflows are skipped cleanly by default rather than failing like a bug.
+// The callers should only call it for git.ErrNotExist, the other errors mean the git repository is unreadable.
+func handleMissingCommit(ctx context.Context, input *notifyInput, commitID string, err error) error {
+	if setting.Actions.MissingCommitPolicy == setting.MissingCommitPolicyError {
+		return fmt.Errorf("gitRepo.GetCommit: %w", err)
+	}
+
+	log.Warn("skip triggering workflows of repo %s for event %s since commit %q does not exist, it may have been garbage collected or the ref may have been deleted",
+		input.Repo.FullName(), input.Event, commitID)
+	if setting.Actions.MissingCommitPolicy == setting.MissingCommitPolicyNotice {
+		if err := system_model.CreateNotice(ctx, system_model.NoticeRepository, "Workflows of repository %s for event %s have been skipped since commit %q does not exist",
+			input.Repo.FullName(), input.Event, commitID); err != nil {
+			log.Error("CreateNotice: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handleMissingCommit(t *testing.T) {
+	input := &notifyInput{
+		Repo:  &repo_model.Repository{OwnerName: "user2", Name: "repo1"},
+		Event: webhook_module.HookEventPush,
+	}
+	commitErr := git.ErrNotExist{ID: "refs/heads/deleted"}
+
+	// the event is skipped cleanly by default
+	assert.NoError(t, handleMissingCommit(context.Background(), input, "refs/heads/deleted", commitErr))
+
+	defer test.MockVariableValue(&setting.Actions.MissingCommitPolicy, setting.MissingCommitPolicyError)()
+	err := handleMissingCommit(context.Background(), input, "refs/heads/deleted", commitErr)
+	assert.ErrorIs(t, err, commitErr)
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 
 	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	perm_model "code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -102,7 +103,7 @@ func (n *actionsNotifier) IssueChangeStatus(ctx context.Context, doer *user_mode
 }
 
 func (n *actionsNotifier) IssueChangeLabels(ctx context.Context, doer *user_model.User, issue *issues_model.Issue,
-	_, _ []*issues_model.Label,
+	addedLabels, removedLabels []*issues_model.Label,
 ) {
 	ctx = withMethod(ctx, "IssueChangeLabels")
 
@@ -127,6 +128,9 @@ func (n *actionsNotifier) IssueChangeLabels(ctx context.Context, doer *user_mode
 			log.Error("LoadIssue: %v", err)
 			return
 		}
+		if err = handleApprovalLabelChanged(ctx, doer, issue.PullRequest, addedLabels, removedLabels); err != nil {
+			log.Error("handleApprovalLabelChanged: %v", err)
+		}
 		newNotifyInputFromIssue(issue, webhook_module.HookEventPullRequestLabel).
 			WithDoer(doer).
 			WithPayload(&api.PullRequestPayload{
@@ -152,6 +156,82 @@ func (n *actionsNotifier) IssueChangeLabels(ctx context.Context, doer *user_mode
 		Notify(ctx)
 }
 
+// NewLabel runs the `label` workflows with the `created` activity type
+func (n *actionsNotifier) NewLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+	ctx = withMethod(ctx, "NewLabel")
+	notifyLabel(ctx, doer, label, api.HookLabelCreated)
+}
+
+// UpdateLabel runs the `label` workflows with the `edited` activity type
+func (n *actionsNotifier) UpdateLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+	ctx = withMethod(ctx, "UpdateLabel")
+	notifyLabel(ctx, doer, label, api.HookLabelEdited)
+}
+
+// DeleteLabel runs the `label` workflows with the `deleted` activity type
+func (n *actionsNotifier) DeleteLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+	ctx = withMethod(ctx, "DeleteLabel")
+	notifyLabel(ctx, doer, label, api.HookLabelDeleted)
+}
+
+// notifyLabel notifies the changes of the labels of a repository,
+// the labels of organizations don't belong to any repository, so they can't trigger workflows.
+// Adding labels to issues or removing labels from issues triggers the `issues` or `pull_request` workflows rather than these.
+func notifyLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label, action api.HookLabelAction) {
+	if !label.BelongsToRepo() {
+		return
+	}
+	repo, err := repo_model.GetRepositoryByID(ctx, label.RepoID)
+	if err != nil {
+		log.Error("GetRepositoryByID: %v", err)
+		return
+	}
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventLabel).
+		WithPayload(&api.LabelPayload{
+			Action:     action,
+			Label:      convert.ToLabel(label, repo, nil),
+			Repository: convert.ToRepo(ctx, repo, permission),
+			Sender:     convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+}
+
+// NewMilestone runs the `milestone` workflows with the `created` activity type
+func (n *actionsNotifier) NewMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone) {
+	ctx = withMethod(ctx, "NewMilestone")
+	notifyMilestone(ctx, doer, milestone, api.HookMilestoneCreated)
+}
+
+// MilestoneChangeStatus runs the `milestone` workflows with the `closed` or `opened` activity type
+func (n *actionsNotifier) MilestoneChangeStatus(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone, isClosed bool) {
+	ctx = withMethod(ctx, "MilestoneChangeStatus")
+	action := api.HookMilestoneOpened
+	if isClosed {
+		action = api.HookMilestoneClosed
+	}
+	notifyMilestone(ctx, doer, milestone, action)
+}
+
+func notifyMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone, action api.HookMilestoneAction) {
+	repo, err := repo_model.GetRepositoryByID(ctx, milestone.RepoID)
+	if err != nil {
+		log.Error("GetRepositoryByID: %v", err)
+		return
+	}
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventMilestone).
+		WithPayload(&api.MilestonePayload{
+			Action:     action,
+			Milestone:  convert.ToAPIMilestone(milestone),
+			Repository: convert.ToRepo(ctx, repo, permission),
+			Sender:     convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+}
+
 // CreateIssueComment notifies comment on an issue to notifiers
 func (n *actionsNotifier) CreateIssueComment(ctx context.Context, doer *user_model.User, repo *repo_model.Repository,
 	issue *issues_model.Issue, comment *issues_model.Comment, _ []*user_model.User,
@@ -165,6 +245,12 @@ func (n *actionsNotifier) CreateIssueComment(ctx context.Context, doer *user_mod
 			log.Error("LoadPullRequest: %v", err)
 			return
 		}
+		if err := handleApprovalCommand(ctx, doer, issue.PullRequest, comment); err != nil {
+			log.Error("handleApprovalCommand: %v", err)
+		}
+		if err := handleChatOpsCommand(ctx, doer, repo, issue, comment); err != nil {
+			log.Error("handleChatOpsCommand: %v", err)
+		}
 		newNotifyInputFromIssue(issue, webhook_module.HookEventPullRequestComment).
 			WithDoer(doer).
 			WithPayload(&api.IssueCommentPayload{
@@ -179,6 +265,9 @@ func (n *actionsNotifier) CreateIssueComment(ctx context.Context, doer *user_mod
 			Notify(ctx)
 		return
 	}
+	if err := handleChatOpsCommand(ctx, doer, repo, issue, comment); err != nil {
+		log.Error("handleChatOpsCommand: %v", err)
+	}
 	newNotifyInputFromIssue(issue, webhook_module.HookEventIssueComment).
 		WithDoer(doer).
 		WithPayload(&api.IssueCommentPayload{
@@ -233,6 +322,49 @@ func (n *actionsNotifier) CreateRepository(ctx context.Context, doer, u *user_mo
 	}).Notify(ctx)
 }
 
+// StarRepository runs the `watch` workflows, it's named `watch` in GitHub though it's about starring
+func (n *actionsNotifier) StarRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	ctx = withMethod(ctx, "StarRepository")
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventWatch).WithPayload(&api.WatchPayload{
+		Action:     api.HookWatchStarted,
+		Repository: convert.ToRepo(ctx, repo, permission),
+		Sender:     convert.ToUser(ctx, doer, nil),
+	}).Notify(ctx)
+}
+
+// PageBuild runs the `page_build` workflows on the default branch, like GitHub
+func (n *actionsNotifier) PageBuild(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, buildID int64, build *api.PageBuild) {
+	ctx = withMethod(ctx, "PageBuild")
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventPageBuild).WithPayload(&api.PageBuildPayload{
+		ID:         buildID,
+		Build:      build,
+		Repository: convert.ToRepo(ctx, repo, permission),
+		Sender:     convert.ToUser(ctx, doer, nil),
+	}).Notify(ctx)
+}
+
+// TransferRepository fixes up the schedules of the repository since its owner and path have been changed
+func (n *actionsNotifier) TransferRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldOwnerName string) {
+	ctx = withMethod(ctx, "TransferRepository")
+
+	if err := revalidateSchedules(ctx, repo.ID); err != nil {
+		log.Error("revalidateSchedules [repo: %d]: %v", repo.ID, err)
+	}
+}
+
+// RenameRepository fixes up the schedules of the repository since its path has been changed
+func (n *actionsNotifier) RenameRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldRepoName string) {
+	ctx = withMethod(ctx, "RenameRepository")
+
+	if err := revalidateSchedules(ctx, repo.ID); err != nil {
+		log.Error("revalidateSchedules [repo: %d]: %v", repo.ID, err)
+	}
+}
+
 func (n *actionsNotifier) ForkRepository(ctx context.Context, doer *user_model.User, oldRepo, repo *repo_model.Repository) {
 	ctx = withMethod(ctx, "ForkRepository")
 
@@ -349,6 +481,9 @@ func (*actionsNotifier) MergePullRequest(ctx context.Context, doer *user_model.U
 func (n *actionsNotifier) PushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	ctx = withMethod(ctx, "PushCommits")
 
+	notifyReusableWorkflowCallers(ctx, pusher, repo, opts.RefFullName, opts.OldCommitID, opts.NewCommitID)
+	notifyWorkflowsSourceDependents(ctx, pusher, repo, opts.RefFullName, opts.OldCommitID, opts.NewCommitID)
+
 	apiPusher := convert.ToUser(ctx, pusher, nil)
 	apiCommits, apiHeadCommit, err := commits.ToAPIPayloadCommits(ctx, repo.RepoPath(), repo.HTMLURL())
 	if err != nil {
@@ -375,28 +510,26 @@ func (n *actionsNotifier) PushCommits(ctx context.Context, pusher *user_model.Us
 func (n *actionsNotifier) CreateRef(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) {
 	ctx = withMethod(ctx, "CreateRef")
 
-	apiPusher := convert.ToUser(ctx, pusher, nil)
-	apiRepo := convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm_model.AccessModeNone})
-
 	newNotifyInput(repo, pusher, webhook_module.HookEventCreate).
 		WithRef(refFullName.ShortName()). // FIXME: should we use a full ref name
-		WithPayload(&api.CreatePayload{
-			Ref:     refFullName.ShortName(),
-			Sha:     refID,
-			RefType: refFullName.RefType(),
-			Repo:    apiRepo,
-			Sender:  apiPusher,
-		}).
+		WithPayload(convert.ToCreatePayload(ctx, pusher, repo, refFullName, refID)).
 		Notify(ctx)
 }
 
 func (n *actionsNotifier) DeleteRef(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName) {
 	ctx = withMethod(ctx, "DeleteRef")
+	notifyDeleteRef(ctx, pusher, repo, refFullName, false)
+}
+
+func notifyDeleteRef(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName, mirrorSync bool) {
+	if err := cancelRunsOfDeletedRef(ctx, repo, refFullName); err != nil {
+		log.Error("cancelRunsOfDeletedRef [repo: %d, ref: %s]: %v", repo.ID, refFullName, err)
+	}
 
 	apiPusher := convert.ToUser(ctx, pusher, nil)
 	apiRepo := convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm_model.AccessModeNone})
 
-	newNotifyInput(repo, pusher, webhook_module.HookEventDelete).
+	input := newNotifyInput(repo, pusher, webhook_module.HookEventDelete).
 		WithRef(refFullName.ShortName()). // FIXME: should we use a full ref name
 		WithPayload(&api.DeletePayload{
 			Ref:        refFullName.ShortName(),
@@ -404,13 +537,19 @@ func (n *actionsNotifier) DeleteRef(ctx context.Context, pusher *user_model.User
 			PusherType: api.PusherTypeUser,
 			Repo:       apiRepo,
 			Sender:     apiPusher,
-		}).
-		Notify(ctx)
+		})
+	if mirrorSync {
+		input.WithMirrorSync()
+	}
+	input.Notify(ctx)
 }
 
-func (n *actionsNotifier) SyncPushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+// SyncPushCommits triggers the workflows on the commits synced from the upstream of a pull mirror like a push,
+// the pusher is the actions user rather than the owner of the mirror since nobody pushed them.
+func (n *actionsNotifier) SyncPushCommits(ctx context.Context, _ *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 	ctx = withMethod(ctx, "SyncPushCommits")
 
+	pusher := user_model.NewActionsUser()
 	apiPusher := convert.ToUser(ctx, pusher, nil)
 	apiCommits, apiHeadCommit, err := commits.ToAPIPayloadCommits(ctx, repo.RepoPath(), repo.HTMLURL())
 	if err != nil {
@@ -428,21 +567,29 @@ func (n *actionsNotifier) SyncPushCommits(ctx context.Context, pusher *user_mode
 			Commits:      apiCommits,
 			TotalCommits: commits.Len,
 			HeadCommit:   apiHeadCommit,
+			MirrorSync:   true,
 			Repo:         convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm_model.AccessModeOwner}),
 			Pusher:       apiPusher,
 			Sender:       apiPusher,
 		}).
+		WithMirrorSync().
 		Notify(ctx)
 }
 
-func (n *actionsNotifier) SyncCreateRef(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) {
+func (n *actionsNotifier) SyncCreateRef(ctx context.Context, _ *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) {
 	ctx = withMethod(ctx, "SyncCreateRef")
-	n.CreateRef(ctx, pusher, repo, refFullName, refID)
+
+	pusher := user_model.NewActionsUser()
+	newNotifyInput(repo, pusher, webhook_module.HookEventCreate).
+		WithRef(refFullName.ShortName()). // FIXME: should we use a full ref name
+		WithPayload(convert.ToCreatePayload(ctx, pusher, repo, refFullName, refID)).
+		WithMirrorSync().
+		Notify(ctx)
 }
 
-func (n *actionsNotifier) SyncDeleteRef(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName) {
+func (n *actionsNotifier) SyncDeleteRef(ctx context.Context, _ *user_model.User, repo *repo_model.Repository, refFullName git.RefName) {
 	ctx = withMethod(ctx, "SyncDeleteRef")
-	n.DeleteRef(ctx, pusher, repo, refFullName)
+	notifyDeleteRef(ctx, user_model.NewActionsUser(), repo, refFullName, true)
 }
 
 func (n *actionsNotifier) NewRelease(ctx context.Context, rel *repo_model.Release) {
@@ -470,6 +617,30 @@ func (n *actionsNotifier) PackageDelete(ctx context.Context, doer *user_model.Us
 	notifyPackage(ctx, doer, pd, api.HookPackageDeleted)
 }
 
+// OrgMemberAdded runs the `organization` workflows of the organization events repository with the `member_added` activity type
+func (n *actionsNotifier) OrgMemberAdded(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User) {
+	ctx = withMethod(ctx, "OrgMemberAdded")
+	notifyOrganization(ctx, doer, org, member, api.HookOrganizationMemberAdded)
+}
+
+// OrgMemberRemoved runs the `organization` workflows of the organization events repository with the `member_removed` activity type
+func (n *actionsNotifier) OrgMemberRemoved(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User) {
+	ctx = withMethod(ctx, "OrgMemberRemoved")
+	notifyOrganization(ctx, doer, org, member, api.HookOrganizationMemberRemoved)
+}
+
+// TeamMemberAdded runs the `membership` workflows of the organization events repository with the `added` activity type
+func (n *actionsNotifier) TeamMemberAdded(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User) {
+	ctx = withMethod(ctx, "TeamMemberAdded")
+	notifyMembership(ctx, doer, team, member, api.HookMembershipAdded)
+}
+
+// TeamMemberRemoved runs the `membership` workflows of the organization events repository with the `removed` activity type
+func (n *actionsNotifier) TeamMemberRemoved(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User) {
+	ctx = withMethod(ctx, "TeamMemberRemoved")
+	notifyMembership(ctx, doer, team, member, api.HookMembershipRemoved)
+}
+
 func (n *actionsNotifier) AutoMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
 	ctx = withMethod(ctx, "AutoMergePullRequest")
 	n.MergePullRequest(ctx, doer, pr)
@@ -500,6 +671,39 @@ func (n *actionsNotifier) PullRequestSynchronized(ctx context.Context, doer *use
 		Notify(ctx)
 }
 
+// MergeGroupChecksRequested runs the `merge_group` workflows against the temporary merge group ref,
+// the created runs are bound to that ref and its head commit so their results can gate the queue.
+func (n *actionsNotifier) MergeGroupChecksRequested(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, mergeGroupRef git.RefName, headSHA, baseSHA string) {
+	ctx = withMethod(ctx, "MergeGroupChecksRequested")
+
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		log.Error("pr.Issue.LoadRepo: %v", err)
+		return
+	}
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, pr.Issue.Repo, doer)
+	newNotifyInput(pr.Issue.Repo, doer, webhook_module.HookEventMergeGroup).
+		WithRef(mergeGroupRef.String()).
+		WithPayload(&api.MergeGroupPayload{
+			Action: api.HookMergeGroupChecksRequested,
+			MergeGroup: &api.MergeGroup{
+				HeadSHA:     headSHA,
+				HeadRef:     mergeGroupRef.String(),
+				BaseSHA:     baseSHA,
+				BaseRef:     git.RefNameFromBranch(pr.BaseBranch).String(),
+				PullRequest: convert.ToAPIPullRequest(ctx, pr, nil),
+			},
+			Repository: convert.ToRepo(ctx, pr.Issue.Repo, permission),
+			Sender:     convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+}
+
 func (n *actionsNotifier) PullRequestChangeTargetBranch(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, oldBranch string) {
 	ctx = withMethod(ctx, "PullRequestChangeTargetBranch")
 
@@ -6,9 +6,12 @@ package actions
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"path"
 	"slices"
 	"strings"
+	"time"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
@@ -19,16 +22,21 @@ import (
 	unit_model "code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 	"code.gitea.io/gitea/services/convert"
+	notify_service "code.gitea.io/gitea/services/notify"
 
+	"github.com/google/uuid"
 	"github.com/nektos/act/pkg/jobparser"
 	"github.com/nektos/act/pkg/model"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var methodCtxKey struct{}
@@ -57,6 +65,28 @@ func getMethod(ctx context.Context) string {
 	return "notify"
 }
 
+// deliveryIDCtxKeyType has its own type, the keys of the same empty struct type would be equal to methodCtxKey
+type deliveryIDCtxKeyType struct{}
+
+var deliveryIDCtxKey deliveryIDCtxKeyType
+
+// WithDeliveryID sets the ID of the upstream webhook delivery which the event of the notification originates from,
+// it's recorded on the runs triggered by the event, so an event can be followed from the webhook to its runs.
+func WithDeliveryID(ctx context.Context, deliveryID string) context.Context {
+	if deliveryID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, deliveryIDCtxKey, deliveryID)
+}
+
+// getDeliveryID gets the ID of the upstream webhook delivery, it's empty for the internally-originated events
+func getDeliveryID(ctx context.Context) string {
+	if v, ok := ctx.Value(deliveryIDCtxKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
 type notifyInput struct {
 	// required
 	Repo  *repo_model.Repository
@@ -67,6 +97,12 @@ type notifyInput struct {
 	Ref         string
 	Payload     api.Payloader
 	PullRequest *issues_model.PullRequest
+	IssueID     int64  // the issue or the pull request which the event is about, zero for other events
+	MirrorSync  bool   // the event is synced from the upstream of a pull mirror, its doer is the actions user
+	Workflow    string // only the workflow of the file name runs, empty for all workflows
+
+	// set by Notify
+	NotificationID string // identifies the notification, so the runs inserted for it are recognized when inserting them is retried
 }
 
 func newNotifyInput(repo *repo_model.Repository, doer *user_model.User, event webhook_module.HookEventType) *notifyInput {
@@ -92,8 +128,21 @@ func (input *notifyInput) WithPayload(payload api.Payloader) *notifyInput {
 	return input
 }
 
+// WithMirrorSync marks the event as synced from the upstream of a pull mirror,
+// it only triggers the workflows if the repository has enabled repo_model.ActionsConfig.RunOnMirrorSync.
+func (input *notifyInput) WithMirrorSync() *notifyInput {
+	input.MirrorSync = true
+	return input
+}
+
+func (input *notifyInput) WithWorkflow(workflow string) *notifyInput {
+	input.Workflow = workflow
+	return input
+}
+
 func (input *notifyInput) WithPullRequest(pr *issues_model.PullRequest) *notifyInput {
 	input.PullRequest = pr
+	input.IssueID = pr.IssueID
 	if input.Ref == "" {
 		input.Ref = pr.GetGitRefName()
 	}
@@ -101,15 +150,22 @@ func (input *notifyInput) WithPullRequest(pr *issues_model.PullRequest) *notifyI
 }
 
 func (input *notifyInput) Notify(ctx context.Context) {
+	input.NotificationID = uuid.NewString()
 	log.Trace("execute %v for event %v whose doer is %v", getMethod(ctx), input.Event, input.Doer.Name)
 
-	if err := notify(ctx, input); err != nil {
+	ctx, span := startSpan(ctx, "actions.notify", append(notifyInputAttributes(input),
+		attribute.String("gitea.actions.method", getMethod(ctx)),
+		attribute.String("gitea.actions.delivery_id", getDeliveryID(ctx)),
+	)...)
+	err := notify(ctx, input)
+	endSpan(span, err)
+	if err != nil {
 		log.Error("an error occurred while executing the %s actions method: %v", getMethod(ctx), err)
 	}
 }
 
 func notify(ctx context.Context, input *notifyInput) error {
-	if input.Doer.IsActions() {
+	if input.Doer.IsActions() && !input.MirrorSync {
 		// avoiding triggering cyclically, for example:
 		// a comment of an issue will trigger the runner to add a new comment as reply,
 		// and the new comment will trigger the runner again.
@@ -117,8 +173,10 @@ func notify(ctx context.Context, input *notifyInput) error {
 		return nil
 	}
 	if unit_model.TypeActions.UnitGlobalDisabled() {
-		if err := actions_model.CleanRepoScheduleTasks(ctx, input.Repo); err != nil {
+		if cleaned, err := actions_model.CleanRepoScheduleTasks(ctx, input.Repo); err != nil {
 			log.Error("CleanRepoScheduleTasks: %v", err)
+		} else if len(cleaned) > 0 {
+			notify_service.ActionsSchedulesCleaned(ctx, user_model.NewActionsUser(), input.Repo, cleaned)
 		}
 		return nil
 	}
@@ -127,6 +185,15 @@ func notify(ctx context.Context, input *notifyInput) error {
 	} else if !input.Repo.UnitEnabled(ctx, unit_model.TypeActions) {
 		return nil
 	}
+	actionsConfig := input.Repo.MustGetUnit(ctx, unit_model.TypeActions).ActionsConfig()
+	if input.MirrorSync && !actionsConfig.RunOnMirrorSync {
+		log.Trace("repo %s hasn't enabled the workflows on mirror syncs", input.Repo.RepoPath())
+		return nil
+	}
+	if isEventDisabled(actionsConfig, input.Event) {
+		log.Trace("repo %s has disabled event %s", input.Repo.RepoPath(), input.Event)
+		return nil
+	}
 
 	gitRepo, err := git.OpenRepository(context.Background(), input.Repo.RepoPath())
 	if err != nil {
@@ -140,29 +207,44 @@ func notify(ctx context.Context, input *notifyInput) error {
 		// Set ref to empty string to fall back to the default branch.
 		ref = ""
 	}
+	refName := git.RefName(ref)
 	if ref == "" {
 		ref = input.Repo.DefaultBranch
+		refName = git.RefNameFromBranch(ref)
 	}
 
 	// Get the commit object for the ref
 	commit, err := gitRepo.GetCommit(ref)
 	if err != nil {
+		if git.IsErrNotExist(err) {
+			return handleMissingCommit(ctx, input, ref, err)
+		}
 		return fmt.Errorf("gitRepo.GetCommit: %w", err)
 	}
 
-	if skipWorkflowsForCommit(input, commit) {
+	if skipWorkflowsForCommit(input, actionsConfig, commit) {
 		return nil
 	}
 
+	title := strings.SplitN(commit.CommitMessage, "\n", 2)[0]
+	if tagName := git.RefName(ref).TagName(); tagName != "" {
+		// use the message of the annotated tag so that release workflows can use it, lightweight tags have no message
+		if message := annotatedTagMessage(gitRepo, tagName); message != "" {
+			title = strings.SplitN(message, "\n", 2)[0]
+			if payload, ok := input.Payload.(*api.PushPayload); ok {
+				payload.TagMessage = message
+			}
+		}
+	}
+
 	var detectedWorkflows []*actions_module.DetectedWorkflow
-	actionsConfig := input.Repo.MustGetUnit(ctx, unit_model.TypeActions).ActionsConfig()
-	workflows, schedules, err := actions_module.DetectWorkflows(gitRepo, commit,
-		input.Event,
-		input.Payload,
-		input.Event == webhook_module.HookEventPush && git.RefName(input.Ref).BranchName() == input.Repo.DefaultBranch,
-	)
+	isDefaultBranchPush := input.Event == webhook_module.HookEventPush && git.RefName(input.Ref).BranchName() == input.Repo.DefaultBranch
+	workflows, schedules, err := detectWorkflows(ctx, gitRepo, input, commit, isDefaultBranchPush)
 	if err != nil {
-		return fmt.Errorf("DetectWorkflows: %w", err)
+		return fmt.Errorf("detectWorkflows: %w", err)
+	}
+	if isDefaultBranchPush && setting.Actions.ReusableWorkflowCallers > 0 {
+		recordWorkflowDependencies(ctx, input.Repo, refName, commit, actionsConfig)
 	}
 
 	log.Trace("repo %s with commit %s event %s find %d workflows and %d schedules",
@@ -174,6 +256,9 @@ func notify(ctx context.Context, input *notifyInput) error {
 	)
 
 	for _, wf := range workflows {
+		if input.Workflow != "" && wf.EntryName != input.Workflow {
+			continue
+		}
 		if actionsConfig.IsWorkflowDisabled(wf.EntryName) {
 			log.Trace("repo %s has disable workflows %s", input.Repo.RepoPath(), wf.EntryName)
 			continue
@@ -185,35 +270,146 @@ func notify(ctx context.Context, input *notifyInput) error {
 	}
 
 	if input.PullRequest != nil {
-		// detect pull_request_target workflows
-		baseRef := git.BranchPrefix + input.PullRequest.BaseBranch
-		baseCommit, err := gitRepo.GetCommit(baseRef)
+		baseWorkflows, err := detectPullRequestTargetWorkflows(ctx, gitRepo, input)
 		if err != nil {
-			return fmt.Errorf("gitRepo.GetCommit: %w", err)
+			return err
 		}
-		baseWorkflows, _, err := actions_module.DetectWorkflows(gitRepo, baseCommit, input.Event, input.Payload, false)
-		if err != nil {
-			return fmt.Errorf("DetectWorkflows: %w", err)
-		}
-		if len(baseWorkflows) == 0 {
-			log.Trace("repo %s with commit %s couldn't find pull_request_target workflows", input.Repo.RepoPath(), baseCommit.ID)
-		} else {
-			for _, wf := range baseWorkflows {
-				if wf.TriggerEvent.Name == actions_module.GithubEventPullRequestTarget {
-					detectedWorkflows = append(detectedWorkflows, wf)
-				}
-			}
+		detectedWorkflows = append(detectedWorkflows, baseWorkflows...)
+	}
+
+	if reason := botCommitsSkipReason(input, actionsConfig.BotCommits, commit); reason != "" {
+		detectedWorkflows = skipBotCommitsWorkflows(ctx, input, actionsConfig.BotCommits, detectedWorkflows, commit, reason)
+	}
+
+	if actionsConfig.CancelSupersededRuns && input.Event == webhook_module.HookEventPush && git.RefName(ref).IsBranch() {
+		if err := cancelSupersededQueuedRuns(ctx, input.Repo, ref, commit); err != nil {
+			log.Error("cancelSupersededQueuedRuns [repo: %d, ref: %s]: %v", input.Repo.ID, ref, err)
 		}
 	}
 
-	if err := handleSchedules(ctx, schedules, commit, input, ref); err != nil {
-		return err
+	// the schedules are only detected for a push to the default branch, the other events must keep the registered ones,
+	// or they would be cleaned with the states set by the operators, like disabled or paused
+	if isDefaultBranchPush {
+		if err := handleSchedules(ctx, schedules, commit, input, ref); err != nil {
+			return err
+		}
 	}
 
-	return handleWorkflows(ctx, detectedWorkflows, commit, input, ref)
+	return handleWorkflows(ctx, detectedWorkflows, commit, title, input, ref)
 }
 
-func skipWorkflowsForCommit(input *notifyInput, commit *git.Commit) bool {
+// detectPullRequestTargetWorkflows detects the pull_request_target workflows from the base branch of the pull request
+func detectPullRequestTargetWorkflows(ctx context.Context, gitRepo *git.Repository, input *notifyInput) ([]*actions_module.DetectedWorkflow, error) {
+	baseRef := git.BranchPrefix + input.PullRequest.BaseBranch
+	baseCommit, err := gitRepo.GetCommit(baseRef)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			// the base branch has been deleted, only the pull_request_target workflows are skipped
+			return nil, handleMissingCommit(ctx, input, baseRef, err)
+		}
+		return nil, fmt.Errorf("gitRepo.GetCommit: %w", err)
+	}
+	baseWorkflows, _, err := detectWorkflows(ctx, gitRepo, input, baseCommit, false)
+	if err != nil {
+		return nil, fmt.Errorf("detectWorkflows: %w", err)
+	}
+	if len(baseWorkflows) == 0 {
+		log.Trace("repo %s with commit %s couldn't find pull_request_target workflows", input.Repo.RepoPath(), baseCommit.ID)
+		return nil, nil
+	}
+
+	var workflows []*actions_module.DetectedWorkflow
+	for _, wf := range baseWorkflows {
+		if wf.TriggerEvent.Name == actions_module.GithubEventPullRequestTarget {
+			workflows = append(workflows, wf)
+		}
+	}
+	return workflows, nil
+}
+
+// annotatedTagMessage returns the message of the tag if it's an annotated tag, or empty if it's a lightweight tag
+func annotatedTagMessage(gitRepo *git.Repository, tagName string) string {
+	tag, err := gitRepo.GetTag(tagName)
+	if err != nil {
+		log.Error("GetTag %q of repo %s: %v", tagName, gitRepo.Path, err)
+		return ""
+	}
+	if tag.Type != string(git.ObjectTag) {
+		return ""
+	}
+	return strings.TrimSpace(tag.Message)
+}
+
+// detectWorkflows detects the workflows and the schedules of the commit
+func detectWorkflows(ctx context.Context, gitRepo *git.Repository, input *notifyInput, commit *git.Commit, detectSchedule bool) (workflows, schedules []*actions_module.DetectedWorkflow, err error) {
+	_, span := startSpan(ctx, "actions.detect_workflows", append(notifyInputAttributes(input),
+		attribute.String("gitea.commit.sha", commit.ID.String()),
+	)...)
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("gitea.actions.workflows", len(workflows)),
+			attribute.Int("gitea.actions.schedules", len(schedules)),
+		)
+		endSpan(span, err)
+	}()
+
+	actionsConfig := input.Repo.MustGetUnit(ctx, unit_model.TypeActions).ActionsConfig()
+	parsed, err := readWorkflows(commit, actionsConfig.WorkflowDirs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("readWorkflows: %w", err)
+	}
+	if sourceWorkflows, err := readSourceWorkflows(ctx, input.Repo, actionsConfig); err != nil {
+		// the workflows of the repository itself still work if the workflows source is missing or unreadable
+		log.Warn("readSourceWorkflows [repo: %s, source: %s]: %v", input.Repo.FullName(), actionsConfig.WorkflowsSource, err)
+	} else {
+		parsed = mergeSourceWorkflows(parsed, sourceWorkflows, actionsConfig.WorkflowsSourcePrecedence)
+	}
+	workflows, schedules = actions_module.MatchWorkflows(gitRepo, commit, parsed, input.Event, input.Payload, detectSchedule)
+	return workflows, schedules, nil
+}
+
+// resolveRunCommitSHA returns the commit which the run of a workflow triggered by triggerEvent operates on,
+// and the head and base commits of the pull request or the merge group if the payload is about one of them.
+// commitSHA is the commit of the ref of the notification, it's chosen by the event:
+//   - pull_request_target: the base commit, since the workflow is read from the base branch
+//   - other pull request events: commitSHA, which is the head of the pull request, including the pull requests from forks,
+//     Gitea has no merge ref for pull requests like GitHub's `refs/pull/:prNumber/merge`
+//   - merge_group: commitSHA, which is the head of the temporary merge group ref
+//   - other events: commitSHA
+func resolveRunCommitSHA(triggerEvent, commitSHA string, payload api.Payloader) (sha, headSHA, baseSHA string) {
+	switch p := payload.(type) {
+	case *api.PullRequestPayload:
+		if p.PullRequest != nil && p.PullRequest.Head != nil && p.PullRequest.Base != nil {
+			headSHA, baseSHA = p.PullRequest.Head.Sha, p.PullRequest.Base.Sha
+		}
+	case *api.MergeGroupPayload:
+		if p.MergeGroup != nil {
+			headSHA, baseSHA = p.MergeGroup.HeadSHA, p.MergeGroup.BaseSHA
+		}
+	}
+
+	if triggerEvent == actions_module.GithubEventPullRequestTarget && baseSHA != "" {
+		return baseSHA, headSHA, baseSHA
+	}
+	return commitSHA, headSHA, baseSHA
+}
+
+// resolveRunRefs returns the head and the base branches of the pull request for `github.head_ref` and `github.base_ref`.
+// Like GitHub, they are only available when the trigger event is pull_request or pull_request_target,
+// and they are the names of the branches, even if the head branch is in a fork.
+func resolveRunRefs(triggerEvent string, pr *issues_model.PullRequest) (headRef, baseRef string) {
+	if pr == nil || (triggerEvent != actions_module.GithubEventPullRequest && triggerEvent != actions_module.GithubEventPullRequestTarget) {
+		return "", ""
+	}
+	return pr.HeadBranch, pr.BaseBranch
+}
+
+// isEventDisabled reports whether the event is disabled by the instance or the repository, so it won't trigger any workflows
+func isEventDisabled(cfg *repo_model.ActionsConfig, event webhook_module.HookEventType) bool {
+	return slices.Contains(setting.Actions.DisabledEvents, string(event)) || cfg.IsEventDisabled(string(event))
+}
+
+func skipWorkflowsForCommit(input *notifyInput, cfg *repo_model.ActionsConfig, commit *git.Commit) bool {
 	// skip workflow runs with a configured skip-ci string in commit message if the event is push or pull_request(_sync)
 	// https://docs.github.com/en/actions/managing-workflow-runs/skipping-workflow-runs
 	skipWorkflowEvents := []webhook_module.HookEventType{
@@ -222,8 +418,8 @@ func skipWorkflowsForCommit(input *notifyInput, commit *git.Commit) bool {
 		webhook_module.HookEventPullRequestSync,
 	}
 	if slices.Contains(skipWorkflowEvents, input.Event) {
-		for _, s := range setting.Actions.SkipWorkflowStrings {
-			if strings.Contains(commit.CommitMessage, s) {
+		for _, s := range cfg.GetSkipWorkflowStrings() {
+			if s != "" && strings.Contains(commit.CommitMessage, s) {
 				log.Debug("repo %s with commit %s: skipped run because of %s string", input.Repo.RepoPath(), commit.ID, s)
 				return true
 			}
@@ -236,17 +432,37 @@ func handleWorkflows(
 	ctx context.Context,
 	detectedWorkflows []*actions_module.DetectedWorkflow,
 	commit *git.Commit,
+	title string,
 	input *notifyInput,
 	ref string,
-) error {
+) (err error) {
+	ctx, span := startSpan(ctx, "actions.handle_workflows", append(notifyInputAttributes(input),
+		attribute.String("gitea.commit.sha", commit.ID.String()),
+		attribute.Int("gitea.actions.workflows", len(detectedWorkflows)),
+	)...)
+	defer func() {
+		endSpan(span, err)
+	}()
+
 	if len(detectedWorkflows) == 0 {
 		log.Trace("repo %s with commit %s couldn't find workflows", input.Repo.RepoPath(), commit.ID)
 		return nil
 	}
 
-	p, err := json.Marshal(input.Payload)
+	p, err := marshalPayload(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	vars, err := actions_model.GetVariablesOfRepo(ctx, input.Repo.OwnerID, input.Repo.ID)
 	if err != nil {
-		return fmt.Errorf("json.Marshal: %w", err)
+		return fmt.Errorf("GetVariablesOfRepo: %w", err)
+	}
+
+	// the event context of the expressions in `run-name`
+	event := map[string]any{}
+	if err := json.Unmarshal(p, &event); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
 	}
 
 	isForkPullRequest := false
@@ -264,41 +480,166 @@ func handleWorkflows(
 		}
 	}
 
-	for _, dwf := range detectedWorkflows {
+	protectedTag, err := isProtectedTagEvent(ctx, input.Repo, input.Event, ref)
+	if err != nil {
+		return fmt.Errorf("isProtectedTagEvent: %w", err)
+	}
+
+	handle := func(dwf *actions_module.DetectedWorkflow) {
+		if err := actions_module.CheckTriggerEvent(dwf, input.Event); err != nil {
+			if setting.Actions.StrictEventCheck {
+				log.Error("skip workflow %q of repo %s with commit %s: %v", dwf.EntryName, input.Repo.RepoPath(), commit.ID, err)
+				return
+			}
+			log.Warn("workflow %q of repo %s with commit %s: %v", dwf.EntryName, input.Repo.RepoPath(), commit.ID, err)
+		}
+
+		actorTeams, err := actions_module.ParseActorTeams(dwf.Content)
+		if err != nil {
+			log.Error("skip workflow %q of repo %s with commit %s: ParseActorTeams: %v", dwf.EntryName, input.Repo.RepoPath(), commit.ID, err)
+			return
+		}
+		if ok, reason := checkActorTeams(ctx, input.Repo, input.Doer, actorTeams); !ok {
+			log.Info("skip workflow %q of repo %s with commit %s since it requires the actor to be a team member: %s", dwf.EntryName, input.Repo.RepoPath(), commit.ID, reason)
+			return
+		}
+
+		commitSHA, headSHA, baseSHA := resolveRunCommitSHA(dwf.TriggerEvent.Name, commit.ID.String(), input.Payload)
+		headRef, baseRef := resolveRunRefs(dwf.TriggerEvent.Name, input.PullRequest)
 		run := &actions_model.ActionRun{
-			Title:             strings.SplitN(commit.CommitMessage, "\n", 2)[0],
+			Title:             title,
 			RepoID:            input.Repo.ID,
 			OwnerID:           input.Repo.OwnerID,
 			WorkflowID:        dwf.EntryName,
+			WorkflowDir:       dwf.Dir,
 			TriggerUserID:     input.Doer.ID,
 			Ref:               ref,
-			CommitSHA:         commit.ID.String(),
+			CommitSHA:         commitSHA,
+			WorkflowSHA:       dwf.BlobSHA,
+			HeadSHA:           headSHA,
+			BaseSHA:           baseSHA,
+			HeadRef:           headRef,
+			BaseRef:           baseRef,
 			IsForkPullRequest: isForkPullRequest,
 			Event:             input.Event,
 			EventPayload:      string(p),
 			TriggerEvent:      dwf.TriggerEvent.Name,
+			TriggerMatch:      dwf.TriggerMatch,
 			Status:            actions_model.StatusWaiting,
+			HostedFallback:    isHostedFallbackPermitted(input.Repo),
+			Warnings:          actions_module.LintWorkflow(dwf.Content),
+			IssueID:           input.IssueID,
+			IsProtectedTag:    protectedTag,
+			DeliveryID:        getDeliveryID(ctx),
 		}
+		run.Title = evaluateRunTitle(run, input, dwf.Content, event, vars)
+		if warning := sameNamedWarning(dwf.SameNamed); warning != "" {
+			run.SameNamed = dwf.SameNamed
+			run.Warnings = append(run.Warnings, warning)
+		}
+		var approval []string
 		if need, err := ifNeedApproval(ctx, run, input.Repo, input.Doer); err != nil {
 			log.Error("check if need approval for repo %d with user %d: %v", input.Repo.ID, input.Doer.ID, err)
-			continue
-		} else {
-			run.NeedApproval = need
+			return
+		} else if need {
+			// the commit of the pull request has been approved by the approval label
+			approver, err := labelApprover(ctx, input.Repo, input.PullRequest, run.CommitSHA)
+			if err != nil {
+				log.Error("check if pull request has been approved by label for repo %d: %v", input.Repo.ID, err)
+				return
+			}
+			if approver != nil {
+				run.ApprovedBy, run.ApprovedByLabel = approver.ID, true
+				approval = append(approval, approvalLabel)
+			} else {
+				run.NeedApproval = true
+				approval = append(approval, approvalForkPullRequest)
+			}
+		}
+		production, err := actions_module.IsProductionDeploy(dwf.Content, setting.Actions.ProductionEnvironments)
+		if err != nil {
+			log.Error("IsProductionDeploy of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+		run.IsProduction = production
+		if production && setting.Actions.ProductionApproval {
+			// an existing approval label of the pull request doesn't bypass it, the run should be approved explicitly
+			run.NeedApproval = true
+			approval = append(approval, approvalProduction)
+		}
+		if protectedTag && setting.Actions.ProtectedTagApproval {
+			run.NeedApproval = true
+			approval = append(approval, approvalProtectedTag)
+		}
+		if run.NeedApproval {
+			// the approval label only approves the runs of the pull requests from forks, see isLabelApprovable
+			run.ApprovedBy, run.ApprovedByLabel = 0, false
 		}
 
+		if err := checkRunWorkflow(ctx, input.Repo, run, dwf.Content, runGitContext(run, input, event), vars); err != nil {
+			log.Error("checkRunWorkflow of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+
+		if hasJobs, err := actions_module.HasJobs(dwf.Content); err != nil {
+			log.Error("HasJobs of workflow %q: %v", dwf.EntryName, err)
+			return
+		} else if !hasJobs {
+			log.Info("skip workflow %q of repo %s with commit %s since it has no jobs", dwf.EntryName, input.Repo.RepoPath(), commit.ID)
+			return
+		}
 		jobs, err := jobparser.Parse(dwf.Content)
 		if err != nil {
 			log.Error("jobparser.Parse: %v", err)
-			continue
+			return
+		}
+		stepRetries, err := actions_module.ParseStepRetries(dwf.Content, setting.Actions.MaxStepRetries)
+		if err != nil {
+			log.Error("ParseStepRetries of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+		gates, err := actions_module.ParseGates(dwf.Content, setting.Actions.GateTimeout)
+		if err != nil {
+			log.Error("ParseGates of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+		envs, err := actions_module.ResolveJobEnvs(dwf.Content, vars)
+		if err != nil {
+			log.Error("ResolveJobEnvs of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+		services, err := actions_module.ParseJobServices(dwf.Content, vars)
+		if err != nil {
+			log.Error("ParseJobServices of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+
+		if run.Timeout, err = actions_module.ParseRunTimeout(dwf.Content); err != nil {
+			log.Error("ParseRunTimeout of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+
+		autoCancelExempt, err := actions_module.IsAutoCancelExempt(dwf.Content)
+		if err != nil {
+			log.Error("IsAutoCancelExempt of workflow %q: %v", dwf.EntryName, err)
+			return
+		}
+
+		decision := checkRunPolicy(ctx, run, input)
+		if !decision.Allow {
+			recordRunPolicyDenial(ctx, input, run.WorkflowID, decision.Reason)
+			return
 		}
+		run.DetectionTrace = newDetectionTrace(run, approval, decision.Reason)
 
-		// cancel running jobs if the event is push
-		if run.Event == webhook_module.HookEventPush {
+		// cancel running jobs if the event is push, unless the workflow opts out of it
+		if run.Event == webhook_module.HookEventPush && !autoCancelExempt {
 			// cancel running jobs of the same workflow
-			if err := actions_model.CancelRunningJobs(
+			if err := actions_model.CancelRunningJobsOfWorkflowDir(
 				ctx,
 				run.RepoID,
 				run.Ref,
+				cancelWorkflowDir(run.WorkflowDir),
 				run.WorkflowID,
 				run.Event,
 			); err != nil {
@@ -306,23 +647,106 @@ func handleWorkflows(
 			}
 		}
 
-		if err := actions_model.InsertRun(ctx, run, jobs); err != nil {
-			log.Error("InsertRun: %v", err)
-			continue
+		if input.NotificationID != "" {
+			run.Fingerprint = runFingerprint(input.NotificationID, path.Join(dwf.Dir, dwf.EntryName), dwf.TriggerEvent.Name)
+		}
+		retried, inserted := false, true
+		if err := withRetry(ctx, "InsertRun", func() error {
+			if retried {
+				// inserting the jobs has erased their needs, so parse them again
+				if jobs, err = jobparser.Parse(dwf.Content); err != nil {
+					return err
+				}
+			}
+			retried = true
+			// the failed attempt may have changed the run, like its ID
+			attempt := *run
+			if err := actions_model.InsertRun(ctx, &attempt, dwf.Content, jobs, stepRetries, gates, envs, services); errors.Is(err, actions_model.ErrRunFingerprintExists) {
+				inserted = false
+			} else if err != nil {
+				return err
+			}
+			*run = attempt
+			return nil
+		}); err != nil {
+			log.Error("InsertRun of workflow %q of repo %s with commit %s for event %s: %v", dwf.EntryName, input.Repo.RepoPath(), commit.ID, input.Event, err)
+			return
+		}
+		if !inserted {
+			// the run has been inserted for the notification before, the side effects of inserting it must not be repeated
+			log.Trace("run %d of workflow %q of repo %s has been inserted for the notification", run.ID, dwf.EntryName, input.Repo.RepoPath())
+			return
 		}
 
-		alljobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: run.ID})
-		if err != nil {
-			log.Error("FindRunJobs: %v", err)
-			continue
+		var alljobs []*actions_model.ActionRunJob
+		if err := withRetry(ctx, "FindRunJobs", func() (err error) {
+			alljobs, err = db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+			return err
+		}); err != nil {
+			log.Error("FindRunJobs of run %d of repo %s failed: %v", run.ID, input.Repo.RepoPath(), err)
+			return
+		}
+		if err := storeRunContext(ctx, run, input, event, vars, envs); err != nil {
+			log.Error("storeRunContext: %v", err)
+		}
+		if err := resolveEnvironmentURLs(ctx, run, alljobs); err != nil {
+			log.Error("resolveEnvironmentURLs: %v", err)
+		}
+		if err := failRunWithErrors(ctx, run, alljobs); err != nil {
+			log.Error("failRunWithErrors: %v", err)
+		}
+		if err := checkMinutesQuota(ctx, run, alljobs); err != nil {
+			log.Error("checkMinutesQuota: %v", err)
+		}
+		if err := checkJobsRunsOn(ctx, input.Repo, alljobs); err != nil {
+			log.Error("checkJobsRunsOn: %v", err)
+		}
+		if slices.ContainsFunc(alljobs, func(job *actions_model.ActionRunJob) bool { return job.Status.IsSkipped() }) {
+			// the jobs which need the skipped jobs could be resolved now, or the run has been skipped and should be notified as completed
+			if err := EmitJobsIfReady(run.ID); err != nil {
+				log.Error("EmitJobsIfReady: %v", err)
+			}
 		}
 		CreateCommitStatus(ctx, alljobs...)
+		exportRunAuditEvent(ctx, runAuditActionCreated, run, alljobs)
+		publishRunEvent(ctx, run)
+		if err := notifyRunBlocked(ctx, run, input.Doer); err != nil {
+			log.Error("notifyRunBlocked: %v", err)
+		}
+	}
+	forEachWorkflow(detectedWorkflows, setting.Actions.WorkflowParallelism, handle)
+	return nil
+}
+
+// checkRunWorkflow checks the workflow of the run before the run is created, whatever creates it, like an event or a schedule.
+// The container images, the forbidden commands and the deploy refs which aren't allowed are added to run.Errors, so the run fails when it's created,
+// and so is the reason if the production run is blocked by the deploy guard, see checkDeployGuard. run.IsProduction should have been set.
+func checkRunWorkflow(ctx context.Context, repo *repo_model.Repository, run *actions_model.ActionRun, content []byte, gitCtx *model.GithubContext, vars map[string]string) error {
+	images, err := actions_module.CheckContainerImages(content, gitCtx, vars, setting.Actions.AllowedImages, setting.Actions.RequireImageDigest)
+	if err != nil {
+		return fmt.Errorf("CheckContainerImages: %w", err)
+	}
+	run.Errors = append(run.Errors, images...)
+	forbidden, err := checkForbiddenCommands(ctx, repo, content)
+	if err != nil {
+		return fmt.Errorf("checkForbiddenCommands: %w", err)
 	}
+	run.Errors = append(run.Errors, forbidden...)
+	if err := checkDeployGuard(ctx, run); err != nil {
+		return fmt.Errorf("checkDeployGuard: %w", err)
+	}
+	deployRefs, err := checkDeployRefs(ctx, repo, run, content)
+	if err != nil {
+		return fmt.Errorf("checkDeployRefs: %w", err)
+	}
+	run.Errors = append(run.Errors, deployRefs...)
 	return nil
 }
 
 func newNotifyInputFromIssue(issue *issues_model.Issue, event webhook_module.HookEventType) *notifyInput {
-	return newNotifyInput(issue.Repo, issue.Poster, event)
+	input := newNotifyInput(issue.Repo, issue.Poster, event)
+	input.IssueID = issue.ID
+	return input
 }
 
 func notifyRelease(ctx context.Context, doer *user_model.User, rel *repo_model.Release, action api.HookReleaseAction) {
@@ -345,11 +769,21 @@ func notifyRelease(ctx context.Context, doer *user_model.User, rel *repo_model.R
 }
 
 func notifyPackage(ctx context.Context, sender *user_model.User, pd *packages_model.PackageDescriptor, action api.HookPackageAction) {
-	if pd.Repository == nil {
+	repo := pd.Repository
+	if repo == nil {
 		// When a package is uploaded to an organization, it could trigger an event to notify.
-		// So the repository could be nil, however, actions can't support that yet.
+		// So the repository could be nil, then the event is sent to the organization events repository of the owner if it's an organization.
 		// See https://github.com/go-gitea/gitea/pull/17940
-		return
+		if !pd.Owner.IsOrganization() {
+			return
+		}
+		var err error
+		if repo, err = orgEventsRepo(ctx, pd.Owner.ID); err != nil {
+			log.Error("orgEventsRepo: %v", err)
+			return
+		} else if repo == nil {
+			return
+		}
 	}
 
 	apiPackage, err := convert.ToPackage(ctx, pd, sender)
@@ -358,7 +792,7 @@ func notifyPackage(ctx context.Context, sender *user_model.User, pd *packages_mo
 		return
 	}
 
-	newNotifyInput(pd.Repository, sender, webhook_module.HookEventPackage).
+	newNotifyInput(repo, sender, webhook_module.HookEventPackage).
 		WithPayload(&api.PackagePayload{
 			Action:  action,
 			Package: apiPackage,
@@ -367,6 +801,15 @@ func notifyPackage(ctx context.Context, sender *user_model.User, pd *packages_mo
 		Notify(ctx)
 }
 
+// cancelWorkflowDir returns the workflow directory whose runs are cancelled by a new run of the workflow in dir,
+// it's only needed if the same-named workflows in both directories run, otherwise they are the same workflow.
+func cancelWorkflowDir(dir string) string {
+	if setting.Actions.WorkflowDirsPrecedence != setting.WorkflowDirsPrecedenceBoth {
+		return ""
+	}
+	return dir
+}
+
 func ifNeedApproval(ctx context.Context, run *actions_model.ActionRun, repo *repo_model.Repository, user *user_model.User) (bool, error) {
 	// 1. don't need approval if it's not a fork PR
 	// 2. don't need approval if the event is `pull_request_target` since the workflow will run in the context of base branch
@@ -390,11 +833,7 @@ func ifNeedApproval(ctx context.Context, run *actions_model.ActionRun, repo *rep
 	}
 
 	// don't need approval if the user has been approved before
-	if count, err := db.Count[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
-		RepoID:        repo.ID,
-		TriggerUserID: user.ID,
-		Approved:      true,
-	}); err != nil {
+	if count, err := db.Count[actions_model.ActionRun](ctx, approvedRunsOptions(repo, user)); err != nil {
 		return false, fmt.Errorf("CountRuns: %w", err)
 	} else if count > 0 {
 		log.Trace("do not need approval because user %d has been approved before", user.ID)
@@ -406,13 +845,38 @@ func ifNeedApproval(ctx context.Context, run *actions_model.ActionRun, repo *rep
 	return true, nil
 }
 
+// approvedRunsOptions returns the options to find the approved runs triggered by the user,
+// which are in the repository, the repositories of the same owner, or all repositories according to setting.Actions.ApprovalScope.
+func approvedRunsOptions(repo *repo_model.Repository, user *user_model.User) actions_model.FindRunOptions {
+	opts := actions_model.FindRunOptions{
+		TriggerUserID: user.ID,
+		Approved:      true,
+	}
+	switch setting.Actions.ApprovalScope {
+	case setting.ApprovalScopeInstance:
+	case setting.ApprovalScopeOwner:
+		opts.OwnerID = repo.OwnerID
+	default:
+		opts.RepoID = repo.ID
+	}
+	return opts
+}
+
 func handleSchedules(
 	ctx context.Context,
 	detectedWorkflows []*actions_module.DetectedWorkflow,
 	commit *git.Commit,
 	input *notifyInput,
 	ref string,
-) error {
+) (err error) {
+	ctx, span := startSpan(ctx, "actions.handle_schedules", append(notifyInputAttributes(input),
+		attribute.String("gitea.commit.sha", commit.ID.String()),
+		attribute.Int("gitea.actions.schedules", len(detectedWorkflows)),
+	)...)
+	defer func() {
+		endSpan(span, err)
+	}()
+
 	branch, err := commit.GetBranchName()
 	if err != nil {
 		return err
@@ -421,24 +885,56 @@ func handleSchedules(
 		log.Trace("commit branch is not default branch in repo")
 		return nil
 	}
+	if err := input.Repo.LoadOwner(ctx); err != nil {
+		return err
+	} else if IsOwnerSuspended(input.Repo.Owner) {
+		// the schedules will be registered when the owner is reactivated, see RegisterOwnerSchedules
+		log.Trace("owner %s of repo %s is suspended, its schedules are not registered", input.Repo.Owner.Name, input.Repo.RepoPath())
+		return nil
+	}
 
-	if count, err := db.Count[actions_model.ActionSchedule](ctx, actions_model.FindScheduleOptions{RepoID: input.Repo.ID}); err != nil {
-		log.Error("CountSchedules: %v", err)
+	var existing []*actions_model.ActionSchedule
+	if err := withRetry(ctx, "FindSchedules", func() (err error) {
+		existing, err = db.Find[actions_model.ActionSchedule](ctx, actions_model.FindScheduleOptions{RepoID: input.Repo.ID})
 		return err
-	} else if count > 0 {
-		if err := actions_model.CleanRepoScheduleTasks(ctx, input.Repo); err != nil {
+	}); err != nil {
+		log.Error("FindSchedules of repo %s failed: %v", input.Repo.RepoPath(), err)
+		return err
+	} else if len(existing) > 0 {
+		if _, err := actions_model.CleanRepoScheduleTasks(ctx, input.Repo); err != nil {
 			log.Error("CleanRepoScheduleTasks: %v", err)
 		}
 	}
+	// the schedules disabled by the operators stay disabled if their specs are unchanged,
+	// and the schedules of the paused workflows stay paused until resumed
+	disabled := make(container.Set[string])
+	pausedSince := make(map[string]timeutil.TimeStamp)
+	for _, schedule := range existing {
+		if schedule.Disabled {
+			disabled.Add(schedule.SpecsKey())
+		}
+		if schedule.Paused {
+			pausedSince[schedule.WorkflowID] = schedule.PausedUnix
+		}
+	}
 
 	if len(detectedWorkflows) == 0 {
 		log.Trace("repo %s with commit %s couldn't find schedules", input.Repo.RepoPath(), commit.ID)
+		if len(existing) > 0 {
+			notify_service.ActionsSchedulesCleaned(ctx, input.Doer, input.Repo, existing)
+		}
 		return nil
 	}
 
-	p, err := json.Marshal(input.Payload)
+	p, err := marshalPayload(ctx, input)
 	if err != nil {
-		return fmt.Errorf("json.Marshal: %w", err)
+		return err
+	}
+	// the schedules of the repositories which don't meet the requirements of the site are registered but deferred,
+	// they are resumed by the scheduler once the repositories meet them, see startTasks
+	deferReason, err := scheduleDeferReason(ctx, input.Repo, time.Now())
+	if err != nil {
+		return err
 	}
 
 	crons := make([]*actions_model.ActionSchedule, 0, len(detectedWorkflows))
@@ -460,16 +956,63 @@ func handleSchedules(
 			RepoID:        input.Repo.ID,
 			OwnerID:       input.Repo.OwnerID,
 			WorkflowID:    dwf.EntryName,
+			WorkflowDir:   dwf.Dir,
 			TriggerUserID: input.Doer.ID,
 			Ref:           ref,
 			CommitSHA:     commit.ID.String(),
+			WorkflowSHA:   dwf.BlobSHA,
 			Event:         input.Event,
 			EventPayload:  string(p),
 			Specs:         schedules,
 			Content:       dwf.Content,
+			SameNamed:     dwf.SameNamed,
+		}
+		run.Disabled = disabled.Contains(run.SpecsKey())
+		if since, ok := pausedSince[run.WorkflowID]; ok {
+			run.Disabled, run.Paused, run.PausedUnix = true, true, since
 		}
+		run.Deferred = deferReason != ""
 		crons = append(crons, run)
 	}
 
-	return actions_model.CreateScheduleTask(ctx, crons)
+	if err := withRetry(ctx, "CreateScheduleTask", func() error {
+		return db.WithTx(ctx, func(ctx context.Context) error {
+			// replace the schedules created by the previous attempt whose result was lost, so no duplicate schedules will be created
+			if err := actions_model.DeleteScheduleTaskByRepo(ctx, input.Repo.ID); err != nil {
+				return err
+			}
+			for _, row := range crons {
+				row.ID = 0
+			}
+			return actions_model.CreateScheduleTask(ctx, crons)
+		})
+	}); err != nil {
+		log.Error("CreateScheduleTask of repo %s with commit %s failed: %v", input.Repo.RepoPath(), commit.ID, err)
+		return err
+	}
+	if deferReason != "" && len(crons) > 0 {
+		log.Info("deferred %d schedules of repo %s with commit %s since %s, see [actions] SCHEDULE_REQUIREMENTS", len(crons), input.Repo.FullName(), commit.ID, deferReason)
+	}
+	// a single event carries all the schedules which replace the existing ones, only if the push has changed them
+	if len(crons) > 0 {
+		if isScheduleSetChanged(existing, crons) {
+			notify_service.ActionsSchedulesRegistered(ctx, input.Doer, input.Repo, crons)
+		}
+	} else if len(existing) > 0 {
+		notify_service.ActionsSchedulesCleaned(ctx, input.Doer, input.Repo, existing)
+	}
+	return nil
+}
+
+// isScheduleSetChanged reports whether the registered schedules have other workflows or specs than the existing ones
+func isScheduleSetChanged(existing, registered []*actions_model.ActionSchedule) bool {
+	specsKeys := func(schedules []*actions_model.ActionSchedule) []string {
+		keys := make([]string, 0, len(schedules))
+		for _, schedule := range schedules {
+			keys = append(keys, schedule.SpecsKey())
+		}
+		slices.Sort(keys)
+		return keys
+	}
+	return !slices.Equal(specsKeys(existing), specsKeys(registered))
 }
@@ -0,0 +1,445 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	notify_service "code.gitea.io/gitea/services/notify"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveRunCommitSHA(t *testing.T) {
+	const (
+		commitSHA = "c2d72f548424103f01ee1dc02889c1e2bff816b0"
+		headSHA   = "985f0301dba5e7b34be866819cd15ad3d8f508ee"
+		baseSHA   = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	)
+	pullRequestPayload := &api.PullRequestPayload{
+		PullRequest: &api.PullRequest{
+			Head: &api.PRBranchInfo{Sha: headSHA},
+			Base: &api.PRBranchInfo{Sha: baseSHA},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		triggerEvent string
+		payload      api.Payloader
+		wantSHA      string
+		wantHeadSHA  string
+		wantBaseSHA  string
+	}{
+		{
+			name:         "push",
+			triggerEvent: actions_module.GithubEventPush,
+			payload:      &api.PushPayload{},
+			wantSHA:      commitSHA,
+		},
+		{
+			name:         "pull_request",
+			triggerEvent: actions_module.GithubEventPullRequest,
+			payload:      pullRequestPayload,
+			wantSHA:      commitSHA,
+			wantHeadSHA:  headSHA,
+			wantBaseSHA:  baseSHA,
+		},
+		{
+			name:         "pull_request_review",
+			triggerEvent: actions_module.GithubEventPullRequestReview,
+			payload:      pullRequestPayload,
+			wantSHA:      commitSHA,
+			wantHeadSHA:  headSHA,
+			wantBaseSHA:  baseSHA,
+		},
+		{
+			name:         "pull_request_target",
+			triggerEvent: actions_module.GithubEventPullRequestTarget,
+			payload:      pullRequestPayload,
+			wantSHA:      baseSHA,
+			wantHeadSHA:  headSHA,
+			wantBaseSHA:  baseSHA,
+		},
+		{
+			name:         "pull_request_target without base",
+			triggerEvent: actions_module.GithubEventPullRequestTarget,
+			payload:      &api.PullRequestPayload{},
+			wantSHA:      commitSHA,
+		},
+		{
+			name:         "merge_group",
+			triggerEvent: actions_module.GithubEventMergeGroup,
+			payload:      &api.MergeGroupPayload{MergeGroup: &api.MergeGroup{HeadSHA: headSHA, BaseSHA: baseSHA}},
+			wantSHA:      commitSHA,
+			wantHeadSHA:  headSHA,
+			wantBaseSHA:  baseSHA,
+		},
+		{
+			name:         "issue_comment",
+			triggerEvent: actions_module.GithubEventIssueComment,
+			payload:      &api.IssueCommentPayload{},
+			wantSHA:      commitSHA,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sha, head, base := resolveRunCommitSHA(tt.triggerEvent, commitSHA, tt.payload)
+			assert.Equal(t, tt.wantSHA, sha)
+			assert.Equal(t, tt.wantHeadSHA, head)
+			assert.Equal(t, tt.wantBaseSHA, base)
+		})
+	}
+}
+
+func Test_resolveRunRefs(t *testing.T) {
+	samePR := &issues_model.PullRequest{HeadRepoID: 1, BaseRepoID: 1, HeadBranch: "feature", BaseBranch: "main"}
+	forkPR := &issues_model.PullRequest{HeadRepoID: 2, BaseRepoID: 1, HeadBranch: "fix", BaseBranch: "release/v1"}
+
+	tests := []struct {
+		name         string
+		triggerEvent string
+		pr           *issues_model.PullRequest
+		wantHeadRef  string
+		wantBaseRef  string
+	}{
+		{
+			name:         "same repo pull_request",
+			triggerEvent: actions_module.GithubEventPullRequest,
+			pr:           samePR,
+			wantHeadRef:  "feature",
+			wantBaseRef:  "main",
+		},
+		{
+			name:         "fork pull_request",
+			triggerEvent: actions_module.GithubEventPullRequest,
+			pr:           forkPR,
+			wantHeadRef:  "fix",
+			wantBaseRef:  "release/v1",
+		},
+		{
+			name:         "fork pull_request_target",
+			triggerEvent: actions_module.GithubEventPullRequestTarget,
+			pr:           forkPR,
+			wantHeadRef:  "fix",
+			wantBaseRef:  "release/v1",
+		},
+		{
+			name:         "comment of pull request",
+			triggerEvent: actions_module.GithubEventIssueComment,
+			pr:           samePR,
+		},
+		{
+			name:         "push",
+			triggerEvent: actions_module.GithubEventPush,
+		},
+		{
+			name:         "release of tag",
+			triggerEvent: actions_module.GithubEventRelease,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headRef, baseRef := resolveRunRefs(tt.triggerEvent, tt.pr)
+			assert.Equal(t, tt.wantHeadRef, headRef)
+			assert.Equal(t, tt.wantBaseRef, baseRef)
+		})
+	}
+}
+
+func Test_approvedRunsOptions(t *testing.T) {
+	repo := &repo_model.Repository{ID: 4, OwnerID: 5}
+	user := &user_model.User{ID: 2}
+
+	tests := []struct {
+		scope string
+		want  actions_model.FindRunOptions
+	}{
+		{
+			scope: setting.ApprovalScopeRepo,
+			want:  actions_model.FindRunOptions{RepoID: 4, TriggerUserID: 2, Approved: true},
+		},
+		{
+			scope: setting.ApprovalScopeOwner,
+			want:  actions_model.FindRunOptions{OwnerID: 5, TriggerUserID: 2, Approved: true},
+		},
+		{
+			scope: setting.ApprovalScopeInstance,
+			want:  actions_model.FindRunOptions{TriggerUserID: 2, Approved: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			defer test.MockVariableValue(&setting.Actions.ApprovalScope, tt.scope)()
+			assert.Equal(t, tt.want, approvedRunsOptions(repo, user))
+		})
+	}
+}
+
+func Test_skipWorkflowsForCommit(t *testing.T) {
+	defer test.MockVariableValue(&setting.Actions.SkipWorkflowStrings, []string{"[skip ci]"})()
+
+	tests := []struct {
+		name    string
+		cfg     *repo_model.ActionsConfig
+		message string
+		want    bool
+	}{
+		{
+			name:    "global strings by default",
+			cfg:     &repo_model.ActionsConfig{},
+			message: "fix typo [skip ci]",
+			want:    true,
+		},
+		{
+			name:    "extend the global strings",
+			cfg:     &repo_model.ActionsConfig{SkipWorkflowStrings: []string{"[wip]"}, SkipWorkflowStringsMode: repo_model.SkipWorkflowStringsModeExtend},
+			message: "fix typo [skip ci]",
+			want:    true,
+		},
+		{
+			name:    "extended strings",
+			cfg:     &repo_model.ActionsConfig{SkipWorkflowStrings: []string{"[wip]"}, SkipWorkflowStringsMode: repo_model.SkipWorkflowStringsModeExtend},
+			message: "[wip] refactor",
+			want:    true,
+		},
+		{
+			name:    "replace the global strings",
+			cfg:     &repo_model.ActionsConfig{SkipWorkflowStrings: []string{"[wip]"}, SkipWorkflowStringsMode: repo_model.SkipWorkflowStringsModeReplace},
+			message: "fix typo [skip ci]",
+			want:    false,
+		},
+		{
+			name:    "disable skipping",
+			cfg:     &repo_model.ActionsConfig{SkipWorkflowStrings: []string{"[wip]"}, SkipWorkflowStringsMode: repo_model.SkipWorkflowStringsModeDisable},
+			message: "[wip] fix typo [skip ci]",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &notifyInput{Repo: &repo_model.Repository{}, Event: webhook_module.HookEventPush}
+			assert.Equal(t, tt.want, skipWorkflowsForCommit(input, tt.cfg, &git.Commit{CommitMessage: tt.message}))
+		})
+	}
+
+	// only some events can be skipped
+	input := &notifyInput{Repo: &repo_model.Repository{}, Event: webhook_module.HookEventIssues}
+	assert.False(t, skipWorkflowsForCommit(input, &repo_model.ActionsConfig{}, &git.Commit{CommitMessage: "[skip ci]"}))
+}
+
+func Test_evaluateRunTitle(t *testing.T) {
+	input := &notifyInput{
+		Repo: &repo_model.Repository{OwnerName: "user2", Name: "repo1"},
+		Doer: &user_model.User{Name: "user2"},
+	}
+	run := &actions_model.ActionRun{
+		Title:        "fix the bug",
+		WorkflowID:   "deploy.yml",
+		Ref:          "refs/heads/main",
+		TriggerEvent: "push",
+	}
+	event := map[string]any{"inputs": map[string]any{"environment": "staging"}}
+
+	content := []byte("on: push\nrun-name: Deploy ${{ github.ref_name }} to ${{ inputs.environment }} by @${{ github.actor }}\n")
+	assert.Equal(t, "Deploy main to staging by @user2", evaluateRunTitle(run, input, content, event, nil))
+
+	// falls back to the commit subject
+	assert.Equal(t, "fix the bug", evaluateRunTitle(run, input, []byte("on: push\n"), event, nil))
+	assert.Equal(t, "fix the bug", evaluateRunTitle(run, input, []byte("on: push\nrun-name: ${{ github.actor == }}\n"), event, nil))
+}
+
+func Test_getDeliveryID(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, getDeliveryID(ctx))
+	assert.Empty(t, getDeliveryID(WithDeliveryID(ctx, "")))
+	assert.Equal(t, "d3e1a5b0", getDeliveryID(WithDeliveryID(ctx, "d3e1a5b0")))
+
+	// the delivery id and the method don't overwrite each other
+	ctx = withMethod(WithDeliveryID(ctx, "d3e1a5b0"), "PushCommits")
+	assert.Equal(t, "d3e1a5b0", getDeliveryID(ctx))
+	assert.Equal(t, "PushCommits", getMethod(ctx))
+	assert.Empty(t, getDeliveryID(withMethod(context.Background(), "PushCommits")))
+}
+
+func Test_notifyKeepsSchedules(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	// the head of master is also the head of other branches, name-rev names it by the branch "DefaultBranch"
+	repo.DefaultBranch = "DefaultBranch"
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	require.NoError(t, actions_model.CreateScheduleTask(db.DefaultContext, []*actions_model.ActionSchedule{{
+		RepoID:        repo.ID,
+		OwnerID:       repo.OwnerID,
+		WorkflowID:    "cron.yml",
+		TriggerUserID: doer.ID,
+		Ref:           "refs/heads/DefaultBranch",
+		Event:         webhook_module.HookEventPush,
+		Specs:         []string{"0 12 * * *"},
+		Disabled:      true,
+	}}))
+
+	// an event other than a push to the default branch doesn't detect the schedules, so it keeps the registered ones
+	newNotifyInput(repo, doer, webhook_module.HookEventIssueComment).
+		WithPayload(&api.IssueCommentPayload{Action: api.HookIssueCommentCreated}).
+		Notify(withMethod(db.DefaultContext, "CreateIssueComment"))
+
+	schedule := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionSchedule{RepoID: repo.ID, WorkflowID: "cron.yml"})
+	assert.True(t, schedule.Disabled)
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionScheduleSpec{ScheduleID: schedule.ID})
+}
+
+func Test_notifyKeepsPausedSchedules(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	repo.DefaultBranch = "DefaultBranch"
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	require.NoError(t, actions_model.CreateScheduleTask(db.DefaultContext, []*actions_model.ActionSchedule{{
+		RepoID:        repo.ID,
+		OwnerID:       repo.OwnerID,
+		WorkflowID:    "cron.yml",
+		TriggerUserID: doer.ID,
+		Ref:           "refs/heads/DefaultBranch",
+		Event:         webhook_module.HookEventPush,
+		Specs:         []string{"0 12 * * *"},
+	}}))
+	require.NoError(t, PauseWorkflowSchedules(db.DefaultContext, repo, "cron.yml"))
+	paused := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionSchedule{RepoID: repo.ID, WorkflowID: "cron.yml"})
+	require.True(t, paused.Paused)
+
+	newNotifyInput(repo, doer, webhook_module.HookEventIssueComment).
+		WithPayload(&api.IssueCommentPayload{Action: api.HookIssueCommentCreated}).
+		Notify(withMethod(db.DefaultContext, "CreateIssueComment"))
+	schedule := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionSchedule{RepoID: repo.ID, WorkflowID: "cron.yml"})
+	assert.True(t, schedule.Paused)
+	assert.Equal(t, paused.PausedUnix, schedule.PausedUnix)
+
+	// the next push to the default branch re-registers the schedules of the workflow, even with other specs, and they stay paused
+	gitRepo, err := git.OpenRepository(db.DefaultContext, repo.RepoPath())
+	require.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	require.NoError(t, err)
+	input := newNotifyInput(repo, doer, webhook_module.HookEventPush).WithRef("refs/heads/DefaultBranch").WithPayload(&api.PushPayload{Ref: "refs/heads/DefaultBranch"})
+	require.NoError(t, handleSchedules(db.DefaultContext, []*actions_module.DetectedWorkflow{{
+		EntryName:    "cron.yml",
+		TriggerEvent: &jobparser.Event{Name: "schedule"},
+		Content:      []byte("on:\n  schedule:\n    - cron: '0 18 * * *'\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n"),
+	}}, commit, input, "refs/heads/DefaultBranch"))
+	schedule = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionSchedule{RepoID: repo.ID, WorkflowID: "cron.yml"})
+	assert.Equal(t, []string{"0 18 * * *"}, schedule.Specs)
+	assert.True(t, schedule.Paused)
+	assert.True(t, schedule.Disabled)
+	assert.Equal(t, paused.PausedUnix, schedule.PausedUnix)
+}
+
+type scheduleNotifier struct {
+	notify_service.NullNotifier
+	events []string
+}
+
+func (n *scheduleNotifier) ActionsSchedulesRegistered(_ context.Context, _ *user_model.User, _ *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+	n.events = append(n.events, fmt.Sprintf("registered %d", len(schedules)))
+}
+
+func (n *scheduleNotifier) ActionsSchedulesCleaned(_ context.Context, _ *user_model.User, _ *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+	n.events = append(n.events, fmt.Sprintf("cleaned %d", len(schedules)))
+}
+
+func Test_handleSchedulesNotifications(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	notifier := &scheduleNotifier{}
+	notify_service.RegisterNotifier(notifier)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	repo.DefaultBranch = "DefaultBranch"
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	gitRepo, err := git.OpenRepository(db.DefaultContext, repo.RepoPath())
+	require.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	require.NoError(t, err)
+
+	push := func(specs ...string) {
+		var detected []*actions_module.DetectedWorkflow
+		for _, spec := range specs {
+			detected = append(detected, &actions_module.DetectedWorkflow{
+				EntryName:    "cron.yml",
+				TriggerEvent: &jobparser.Event{Name: "schedule"},
+				Content:      []byte(fmt.Sprintf("on:\n  schedule:\n    - cron: '%s'\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n", spec)),
+			})
+		}
+		input := newNotifyInput(repo, doer, webhook_module.HookEventPush).WithRef("refs/heads/DefaultBranch").WithPayload(&api.PushPayload{Ref: "refs/heads/DefaultBranch"})
+		require.NoError(t, handleSchedules(db.DefaultContext, detected, commit, input, "refs/heads/DefaultBranch"))
+	}
+	assertEvents := func(t *testing.T, expected ...string) {
+		t.Helper()
+		assert.Equal(t, expected, notifier.events)
+		notifier.events = nil
+	}
+
+	push("0 12 * * *")
+	assertEvents(t, "registered 1")
+	// a push which doesn't change the schedules doesn't emit any event
+	push("0 12 * * *")
+	assertEvents(t)
+	newNotifyInput(repo, doer, webhook_module.HookEventIssueComment).
+		WithPayload(&api.IssueCommentPayload{Action: api.HookIssueCommentCreated}).
+		Notify(withMethod(db.DefaultContext, "CreateIssueComment"))
+	assertEvents(t)
+	push("0 18 * * *")
+	assertEvents(t, "registered 1")
+	push()
+	assertEvents(t, "cleaned 1")
+}
+
+func Test_handleWorkflowsFingerprint(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	gitRepo, err := git.OpenRepository(db.DefaultContext, repo.RepoPath())
+	require.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	require.NoError(t, err)
+
+	handle := func(notificationID string) {
+		input := newNotifyInput(repo, doer, webhook_module.HookEventPush).WithRef("refs/heads/master").WithPayload(&api.PushPayload{Ref: "refs/heads/master"})
+		input.NotificationID = notificationID
+		require.NoError(t, handleWorkflows(db.DefaultContext, []*actions_module.DetectedWorkflow{{
+			EntryName:    "fingerprint.yml",
+			Dir:          ".gitea/workflows",
+			TriggerEvent: &jobparser.Event{Name: "push"},
+			Content:      []byte("on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hello\n"),
+		}}, commit, "title", input, "refs/heads/master"))
+	}
+
+	handle("notification-1")
+	unittest.AssertCount(t, &actions_model.ActionRun{RepoID: repo.ID, WorkflowID: "fingerprint.yml"}, 1)
+	// retrying the same notification doesn't create a duplicate run
+	handle("notification-1")
+	unittest.AssertCount(t, &actions_model.ActionRun{RepoID: repo.ID, WorkflowID: "fingerprint.yml"}, 1)
+	// an identical event notified again creates its own run
+	handle("notification-2")
+	unittest.AssertCount(t, &actions_model.ActionRun{RepoID: repo.ID, WorkflowID: "fingerprint.yml"}, 2)
+}
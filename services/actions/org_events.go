@@ -0,0 +1,95 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// orgEventsRepo returns the repository of the organization which hosts the workflows of the organization events, see setting.Actions.OrgEventsRepo.
+// It returns nil if it's not configured or the organization has no such repository.
+// The public repositories are ignored, since the payloads reveal the memberships of the organization, which may be private.
+// The runs belong to the repository and use the workflows of its default branch like the schedules,
+// so their tokens are scoped to the repository and only the ones who can write the repository decide what they do.
+func orgEventsRepo(ctx context.Context, orgID int64) (*repo_model.Repository, error) {
+	if setting.Actions.OrgEventsRepo == "" {
+		return nil, nil
+	}
+	repo, err := repo_model.GetRepositoryByName(ctx, orgID, setting.Actions.OrgEventsRepo)
+	if repo_model.IsErrRepoNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("GetRepositoryByName: %w", err)
+	}
+	if !repo.IsPrivate {
+		log.Warn("ignore the organization events of repo %s since it's public", repo.FullName())
+		return nil, nil
+	}
+	return repo, nil
+}
+
+func notifyOrganization(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User, action api.HookOrganizationAction) {
+	repo, err := orgEventsRepo(ctx, org.ID)
+	if err != nil {
+		log.Error("orgEventsRepo: %v", err)
+		return
+	} else if repo == nil {
+		return
+	}
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventOrganization).
+		WithPayload(&api.OrganizationPayload{
+			Action:       action,
+			Membership:   &api.OrganizationMembership{User: convert.ToUser(ctx, member, nil)},
+			Organization: convert.ToOrganization(ctx, org),
+			Repository:   convert.ToRepo(ctx, repo, permission),
+			Sender:       convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+}
+
+func notifyMembership(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User, action api.HookMembershipAction) {
+	repo, err := orgEventsRepo(ctx, team.OrgID)
+	if err != nil {
+		log.Error("orgEventsRepo: %v", err)
+		return
+	} else if repo == nil {
+		return
+	}
+	org, err := organization.GetOrgByID(ctx, team.OrgID)
+	if err != nil {
+		log.Error("GetOrgByID: %v", err)
+		return
+	}
+	apiTeam, err := convert.ToTeam(ctx, team)
+	if err != nil {
+		log.Error("ToTeam: %v", err)
+		return
+	}
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventMembership).
+		WithPayload(&api.MembershipPayload{
+			Action:       action,
+			Scope:        "team",
+			Member:       convert.ToUser(ctx, member, nil),
+			Team:         apiTeam,
+			Organization: convert.ToOrganization(ctx, org),
+			Repository:   convert.ToRepo(ctx, repo, permission),
+			Sender:       convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	notify_service "code.gitea.io/gitea/services/notify"
+
+	"xorm.io/builder"
+)
+
+// IsOwnerSuspended reports whether the schedules of the owner must not fire, which is true if the account is prohibited from login or inactive
+func IsOwnerSuspended(owner *user_model.User) bool {
+	return owner.ProhibitLogin || !owner.IsActive
+}
+
+// UpdateOwnerSchedules cleans the schedules of the owner when it has been suspended, and registers them again when it has been reactivated,
+// wasSuspended is whether the owner was suspended before it was updated. It does nothing if the suspension hasn't changed.
+func UpdateOwnerSchedules(ctx context.Context, owner *user_model.User, wasSuspended bool) error {
+	switch suspended := IsOwnerSuspended(owner); {
+	case suspended && !wasSuspended:
+		return CleanOwnerSchedules(ctx, owner)
+	case !suspended && wasSuspended:
+		return RegisterOwnerSchedules(ctx, owner)
+	default:
+		return nil
+	}
+}
+
+// CleanOwnerSchedules deletes the schedules of all repositories of the owner, and cancels the unfinished runs created by them,
+// so a suspended account doesn't consume runner time. The schedules and the runs of other owners are never touched.
+func CleanOwnerSchedules(ctx context.Context, owner *user_model.User) error {
+	schedules, err := db.Find[actions_model.ActionSchedule](ctx, actions_model.FindScheduleOptions{OwnerID: owner.ID})
+	if err != nil {
+		return fmt.Errorf("FindSchedules: %w", err)
+	}
+	deleted, err := actions_model.DeleteScheduleTaskByOwner(ctx, owner.ID)
+	if err != nil {
+		return fmt.Errorf("DeleteScheduleTaskByOwner: %w", err)
+	}
+	notifyOwnerSchedulesCleaned(ctx, schedules)
+
+	runs, err := db.Find[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+		OwnerID:      owner.ID,
+		TriggerEvent: webhook_module.HookEventSchedule,
+		Status:       []actions_model.Status{actions_model.StatusWaiting, actions_model.StatusBlocked, actions_model.StatusRunning},
+	})
+	if err != nil {
+		return fmt.Errorf("FindRuns: %w", err)
+	}
+	for _, run := range runs {
+		if err := CancelRun(ctx, run); err != nil {
+			return fmt.Errorf("CancelRun %d: %w", run.ID, err)
+		}
+	}
+
+	log.Info("cleaned %d schedules and cancelled %d scheduled runs of suspended owner %s", deleted, len(runs), owner.Name)
+	return nil
+}
+
+// notifyOwnerSchedulesCleaned notifies the cleaned schedules with a single event for every repository rather than one for every schedule
+func notifyOwnerSchedulesCleaned(ctx context.Context, schedules actions_model.ScheduleList) {
+	if len(schedules) == 0 {
+		return
+	}
+	if err := schedules.LoadRepos(ctx); err != nil {
+		log.Error("LoadRepos: %v", err)
+		return
+	}
+	byRepo := make(map[int64][]*actions_model.ActionSchedule)
+	for _, schedule := range schedules {
+		byRepo[schedule.RepoID] = append(byRepo[schedule.RepoID], schedule)
+	}
+	for _, repoSchedules := range byRepo {
+		if repo := repoSchedules[0].Repo; repo != nil {
+			notify_service.ActionsSchedulesCleaned(ctx, user_model.NewActionsUser(), repo, repoSchedules)
+		}
+	}
+}
+
+// RegisterOwnerSchedules registers the schedules of all repositories of the owner again from the workflows of their current default branches,
+// the schedules of a repository are replaced if it has any. A repository which fails to be registered doesn't stop the others.
+func RegisterOwnerSchedules(ctx context.Context, owner *user_model.User) error {
+	if unit_model.TypeActions.UnitGlobalDisabled() {
+		return nil
+	}
+
+	registered := 0
+	if err := db.Iterate(ctx, builder.Eq{"owner_id": owner.ID}, func(ctx context.Context, repo *repo_model.Repository) error {
+		ok, err := registerRepoSchedules(ctx, repo)
+		if err != nil {
+			log.Error("registerRepoSchedules of repo %s: %v", repo.FullName(), err)
+		} else if ok {
+			registered++
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	log.Info("registered the schedules of %d repositories of reactivated owner %s", registered, owner.Name)
+	return nil
+}
+
+// registerRepoSchedules registers the schedules of the repository from the workflows of its default branch,
+// it returns false if the repository can't have schedules, like an empty or archived repository or one whose actions are disabled.
+func registerRepoSchedules(ctx context.Context, repo *repo_model.Repository) (bool, error) {
+	if repo.IsEmpty || repo.IsArchived {
+		return false, nil
+	}
+	if err := repo.LoadUnits(ctx); err != nil {
+		return false, fmt.Errorf("repo.LoadUnits: %w", err)
+	} else if !repo.UnitEnabled(ctx, unit_model.TypeActions) {
+		return false, nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return false, fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+
+	ref := git.RefNameFromBranch(repo.DefaultBranch)
+	commit, err := gitRepo.GetCommit(ref.String())
+	if err != nil {
+		return false, fmt.Errorf("GetCommit: %w", err)
+	}
+
+	// the schedules are registered by Gitea rather than by a push, the runs they create are triggered by the actions user
+	input := newNotifyInput(repo, user_model.NewActionsUser(), webhook_module.HookEventSchedule)
+	_, schedules, err := detectWorkflows(ctx, gitRepo, input, commit, true)
+	if err != nil {
+		return false, err
+	}
+	return true, handleSchedules(ctx, schedules, commit, input, ref.String())
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+)
+
+// maxTransformedPayloadSize is the max size in bytes of a payload returned by a transform, it's the same as the limit of GitHub's webhook payloads
+const maxTransformedPayloadSize = 25 * 1024 * 1024
+
+// PayloadTransform rewrites the encoded payload of an event before it's stored in the runs or the schedules and handed to runners,
+// like stripping internal fields or adding correlation IDs. It must return a JSON object.
+type PayloadTransform func(ctx context.Context, repo *repo_model.Repository, event webhook_module.HookEventType, payload []byte) ([]byte, error)
+
+var payloadTransforms []PayloadTransform
+
+// RegisterPayloadTransform registers a transform of the event payloads, the transforms are called in the order they are registered.
+// It should be called when initializing, before any events are handled.
+func RegisterPayloadTransform(transform PayloadTransform) {
+	payloadTransforms = append(payloadTransforms, transform)
+}
+
+// marshalPayload encodes the payload of the input and applies the registered transforms to it.
+// If a transform fails, or returns invalid JSON or a too large payload, the error is logged and its result is discarded,
+// so a misbehaving transform can't break the runs.
+func marshalPayload(ctx context.Context, input *notifyInput) ([]byte, error) {
+	p, err := json.Marshal(input.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	for i, transform := range payloadTransforms {
+		transformed, err := transform(ctx, input.Repo, input.Event, p)
+		if err == nil {
+			err = checkTransformedPayload(transformed)
+		}
+		if err != nil {
+			log.Error("payload transform %d of event %q of repo %s: %v, the payload is kept as it was", i, input.Event, input.Repo.FullName(), err)
+			continue
+		}
+		p = transformed
+	}
+	return p, nil
+}
+
+// checkTransformedPayload checks that the payload returned by a transform is a JSON object and not too large
+func checkTransformedPayload(payload []byte) error {
+	if len(payload) > maxTransformedPayloadSize {
+		return fmt.Errorf("the payload is larger than %d bytes", maxTransformedPayloadSize)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(payload), []byte("{")) || !json.Valid(payload) {
+		return fmt.Errorf("the payload is not a valid JSON object")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_marshalPayload(t *testing.T) {
+	input := &notifyInput{
+		Repo:    &repo_model.Repository{OwnerName: "user2", Name: "repo1"},
+		Event:   webhook_module.HookEventPush,
+		Payload: &api.PushPayload{Ref: "refs/heads/main"},
+	}
+	original, err := marshalPayload(context.Background(), input)
+	assert.NoError(t, err)
+	assert.Contains(t, string(original), `"ref":"refs/heads/main"`)
+
+	addCorrelationID := func(_ context.Context, _ *repo_model.Repository, _ webhook_module.HookEventType, payload []byte) ([]byte, error) {
+		return bytes.Replace(payload, []byte("{"), []byte(`{"correlation_id":"abc",`), 1), nil
+	}
+	tests := []struct {
+		name       string
+		transforms []PayloadTransform
+		want       string
+	}{
+		{
+			name:       "enrich",
+			transforms: []PayloadTransform{addCorrelationID},
+			want:       `{"correlation_id":"abc",` + string(original[1:]),
+		},
+		{
+			name: "error",
+			transforms: []PayloadTransform{func(context.Context, *repo_model.Repository, webhook_module.HookEventType, []byte) ([]byte, error) {
+				return nil, errors.New("unavailable")
+			}},
+			want: string(original),
+		},
+		{
+			name: "invalid json falls back",
+			transforms: []PayloadTransform{addCorrelationID, func(_ context.Context, _ *repo_model.Repository, _ webhook_module.HookEventType, payload []byte) ([]byte, error) {
+				return payload[:len(payload)-1], nil
+			}},
+			want: `{"correlation_id":"abc",` + string(original[1:]),
+		},
+		{
+			name: "not an object",
+			transforms: []PayloadTransform{func(context.Context, *repo_model.Repository, webhook_module.HookEventType, []byte) ([]byte, error) {
+				return []byte(`["redacted"]`), nil
+			}},
+			want: string(original),
+		},
+		{
+			name: "too large",
+			transforms: []PayloadTransform{func(context.Context, *repo_model.Repository, webhook_module.HookEventType, []byte) ([]byte, error) {
+				return []byte(`{"padding":"` + string(bytes.Repeat([]byte("a"), maxTransformedPayloadSize)) + `"}`), nil
+			}},
+			want: string(original),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer test.MockVariableValue(&payloadTransforms, tt.transforms)()
+			p, err := marshalPayload(context.Background(), input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(p))
+		})
+	}
+}
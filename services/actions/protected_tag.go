@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+)
+
+// isProtectedTagEvent reports whether the event creates or pushes a tag matching the protected tags of the repository,
+// the runs triggered by it need approval if setting.Actions.ProtectedTagApproval is enabled.
+func isProtectedTagEvent(ctx context.Context, repo *repo_model.Repository, event webhook_module.HookEventType, ref string) (bool, error) {
+	if event != webhook_module.HookEventCreate && event != webhook_module.HookEventPush {
+		return false, nil
+	}
+	refName := git.RefName(ref)
+	if !refName.IsTag() {
+		return false, nil
+	}
+
+	tags, err := git_model.GetProtectedTags(ctx, repo.ID)
+	if err != nil {
+		return false, fmt.Errorf("GetProtectedTags: %w", err)
+	}
+	return git_model.IsTagProtected(tags, refName.TagName())
+}
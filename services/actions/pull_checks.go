@@ -0,0 +1,106 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/automerge"
+)
+
+// pullChecksQueue refreshes the checks of the pull requests whose runs have concluded.
+// It's a unique queue so the bursts of job completions within a run are handled once.
+var pullChecksQueue *queue.WorkerPoolQueue[*pullChecksUpdate]
+
+type pullChecksUpdate struct {
+	RunID int64
+}
+
+// isPullRequestRunEvent reports whether the commit statuses of runs triggered by event are reported to a pull request
+func isPullRequestRunEvent(event webhook_module.HookEventType) bool {
+	return event == webhook_module.HookEventPullRequest || event == webhook_module.HookEventPullRequestSync
+}
+
+// refreshPullChecksIfDone queues the refreshment of the pull request checks if the job has concluded.
+// The run is checked again by the queue handler, so only the last job of the run does the work.
+func refreshPullChecksIfDone(job *actions_model.ActionRunJob) {
+	if pullChecksQueue == nil || job.Run == nil || !job.Status.IsDone() || !isPullRequestRunEvent(job.Run.Event) {
+		return
+	}
+	err := pullChecksQueue.Push(&pullChecksUpdate{RunID: job.RunID})
+	if err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+		log.Error("Unable to queue the pull checks of run %d: %v", job.RunID, err)
+	}
+}
+
+func pullChecksQueueHandler(items ...*pullChecksUpdate) []*pullChecksUpdate {
+	ctx := graceful.GetManager().ShutdownContext()
+	var ret []*pullChecksUpdate
+	for _, update := range items {
+		if err := refreshPullChecks(ctx, update.RunID); err != nil {
+			log.Error("refreshPullChecks [run: %d]: %v", update.RunID, err)
+			ret = append(ret, update)
+		}
+	}
+	return ret
+}
+
+// refreshPullChecks re-evaluates the required status checks of the pull request of a concluded run,
+// so the pull requests scheduled to merge don't need to wait for another status to be merged.
+func refreshPullChecks(ctx context.Context, runID int64) error {
+	run, err := actions_model.GetRunByID(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("GetRunByID: %w", err)
+	}
+	if !run.Status.IsDone() {
+		// the other jobs of the run will queue it again when they conclude
+		return nil
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		return fmt.Errorf("LoadAttributes: %w", err)
+	}
+
+	payload, err := run.GetPullRequestEventPayload()
+	if err != nil {
+		return fmt.Errorf("GetPullRequestEventPayload: %w", err)
+	}
+	if payload.PullRequest == nil || payload.PullRequest.Head == nil {
+		return nil
+	}
+
+	pr, err := issues_model.GetPullRequestByIndex(ctx, run.RepoID, payload.PullRequest.Index)
+	if err != nil {
+		if issues_model.IsErrPullRequestNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetPullRequestByIndex: %w", err)
+	}
+	if pr.HasMerged {
+		return nil
+	}
+
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetFirstMatchProtectedBranchRule: %w", err)
+	}
+	if pb == nil || !pb.EnableStatusCheck {
+		// nothing depends on the statuses without required status checks
+		return nil
+	}
+
+	log.Trace("refresh the checks of pull request %d since run %d has concluded", pr.ID, run.ID)
+	if err := automerge.MergeScheduledPullRequest(ctx, payload.PullRequest.Head.Sha, run.Repo); err != nil {
+		return fmt.Errorf("MergeScheduledPullRequest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/convert"
+)
+
+const (
+	// maxRepositoryDispatchEventTypeLength is the max length of the event type of a dispatch, the same as GitHub
+	maxRepositoryDispatchEventTypeLength = 100
+	// maxRepositoryDispatchClientPayloadProperties is the max number of the top-level properties of the client payload, the same as GitHub
+	maxRepositoryDispatchClientPayloadProperties = 10
+	// maxRepositoryDispatchClientPayloadSize is the max size in bytes of the encoded client payload
+	maxRepositoryDispatchClientPayloadSize = 64 * 1024
+	// maxDeliveryIDLength is the max length of the ID of the upstream webhook delivery recorded on the runs
+	maxDeliveryIDLength = 255
+)
+
+// validateRepositoryDispatch checks the event type and the client payload injected by an external system
+func validateRepositoryDispatch(eventType string, clientPayload map[string]any) error {
+	if eventType == "" {
+		return util.NewInvalidArgumentErrorf("event type is required")
+	}
+	if len(eventType) > maxRepositoryDispatchEventTypeLength {
+		return util.NewInvalidArgumentErrorf("event type is longer than %d characters", maxRepositoryDispatchEventTypeLength)
+	}
+	if len(clientPayload) > maxRepositoryDispatchClientPayloadProperties {
+		return util.NewInvalidArgumentErrorf("client payload has more than %d top-level properties", maxRepositoryDispatchClientPayloadProperties)
+	}
+	content, err := json.Marshal(clientPayload)
+	if err != nil {
+		return util.NewInvalidArgumentErrorf("client payload is invalid: %v", err)
+	}
+	if len(content) > maxRepositoryDispatchClientPayloadSize {
+		return util.NewInvalidArgumentErrorf("client payload is larger than %d bytes", maxRepositoryDispatchClientPayloadSize)
+	}
+	return nil
+}
+
+// DispatchRepositoryEvent runs the `repository_dispatch` workflows on the default branch with the custom event type,
+// the client payload is available as `github.event.client_payload` in the workflows.
+// The ID of the upstream webhook delivery set by WithDeliveryID is recorded on the runs.
+func DispatchRepositoryEvent(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, eventType string, clientPayload map[string]any) error {
+	return dispatchRepositoryEvent(withMethod(ctx, "DispatchRepositoryEvent"), doer, repo, eventType, clientPayload, "")
+}
+
+// dispatchRepositoryEvent runs the `repository_dispatch` workflows like DispatchRepositoryEvent,
+// only the workflow of the file name runs if workflow is not empty.
+func dispatchRepositoryEvent(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, eventType string, clientPayload map[string]any, workflow string) error {
+	if err := validateRepositoryDispatch(eventType, clientPayload); err != nil {
+		return err
+	}
+	if err := validateClientPayloadSchema(ctx, repo, eventType, clientPayload); err != nil {
+		return err
+	}
+	if len(getDeliveryID(ctx)) > maxDeliveryIDLength {
+		return util.NewInvalidArgumentErrorf("delivery id is longer than %d characters", maxDeliveryIDLength)
+	}
+	if clientPayload == nil {
+		clientPayload = map[string]any{}
+	}
+
+	permission, _ := access_model.GetUserRepoPermission(ctx, repo, doer)
+	newNotifyInput(repo, doer, webhook_module.HookEventRepositoryDispatch).
+		WithWorkflow(workflow).
+		WithPayload(&api.RepositoryDispatchPayload{
+			Action:        eventType,
+			Branch:        repo.DefaultBranch,
+			ClientPayload: clientPayload,
+			Repository:    convert.ToRepo(ctx, repo, permission),
+			Sender:        convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateRepositoryDispatch(t *testing.T) {
+	assert.NoError(t, validateRepositoryDispatch("deploy", nil))
+	assert.NoError(t, validateRepositoryDispatch("deploy", map[string]any{"env": "prod", "unit": false}))
+
+	tooMany := map[string]any{}
+	for i := 0; i <= maxRepositoryDispatchClientPayloadProperties; i++ {
+		tooMany[strings.Repeat("k", i+1)] = i
+	}
+	for name, fn := range map[string]func() error{
+		"missing event type":  func() error { return validateRepositoryDispatch("", nil) },
+		"too long event type": func() error { return validateRepositoryDispatch(strings.Repeat("a", 101), nil) },
+		"too many properties": func() error { return validateRepositoryDispatch("deploy", tooMany) },
+		"too large payload": func() error {
+			return validateRepositoryDispatch("deploy", map[string]any{"blob": strings.Repeat("a", maxRepositoryDispatchClientPayloadSize)})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, fn(), util.ErrInvalidArgument)
+		})
+	}
+}
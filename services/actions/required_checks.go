@@ -0,0 +1,70 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/gobwas/glob"
+)
+
+// RequiredChecks is how the jobs of a run match the required status checks of a protected branch,
+// every required context is in one of the lists, in the order of the rule.
+type RequiredChecks struct {
+	Satisfied []string // the contexts matched only by the jobs which have succeeded or been skipped
+	Pending   []string // the contexts matched by the jobs which haven't been done, and by no failing job
+	Failing   []string // the contexts matched by any job which has failed or been cancelled
+	Missing   []string // the contexts matched by no job of the run
+}
+
+// MatchRequiredChecks matches the required status check contexts of the protected branch with the jobs of the run.
+// The contexts of the jobs are the same as the ones of the commit statuses created by CreateCommitStatus,
+// and a required context could be a glob pattern like "CI / test * (pull_request)" as branch protection accepts.
+// Nothing is required if the status check isn't enabled, and all contexts are missing if the run doesn't create commit statuses.
+func MatchRequiredChecks(run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob, pb *git_model.ProtectedBranch) *RequiredChecks {
+	ret := &RequiredChecks{}
+	if pb == nil || !pb.EnableStatusCheck {
+		return ret
+	}
+
+	states := make(map[string]api.CommitStatusState, len(jobs))
+	if commitStatusEvent(run.Event) != "" {
+		for _, job := range jobs {
+			ctxname := commitStatusContext(run, job)
+			state := toCommitStatus(job.Status)
+			if old, ok := states[ctxname]; !ok || state.NoBetterThan(old) {
+				states[ctxname] = state
+			}
+		}
+	}
+
+	for _, required := range pb.StatusCheckContexts {
+		gp, err := glob.Compile(required)
+		if err != nil {
+			log.Error("glob.Compile %s failed. Error: %v", required, err)
+			ret.Missing = append(ret.Missing, required)
+			continue
+		}
+		var state api.CommitStatusState
+		for ctxname, s := range states {
+			if gp.Match(ctxname) && (state == "" || s.NoBetterThan(state)) {
+				state = s
+			}
+		}
+		switch {
+		case state == "":
+			ret.Missing = append(ret.Missing, required)
+		case state.IsSuccess():
+			ret.Satisfied = append(ret.Satisfied, required)
+		case state.IsPending():
+			ret.Pending = append(ret.Pending, required)
+		default:
+			ret.Failing = append(ret.Failing, required)
+		}
+	}
+	return ret
+}
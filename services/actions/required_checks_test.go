@@ -0,0 +1,82 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	git_model "code.gitea.io/gitea/models/git"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRequiredChecks(t *testing.T) {
+	workflows, err := jobparser.Parse([]byte(`name: CI
+on: pull_request
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        version: [1, 2]
+    steps:
+      - run: make test
+  lint:
+    name: Lint code
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+`))
+	require.NoError(t, err)
+	statuses := map[string]actions_model.Status{
+		"test (1)":  actions_model.StatusSuccess,
+		"test (2)":  actions_model.StatusFailure,
+		"Lint code": actions_model.StatusRunning,
+	}
+	jobs := make([]*actions_model.ActionRunJob, 0, len(workflows))
+	for _, wf := range workflows {
+		id, job := wf.Job()
+		payload, err := wf.Marshal()
+		require.NoError(t, err)
+		jobs = append(jobs, &actions_model.ActionRunJob{JobID: id, Name: job.Name, WorkflowPayload: payload, Status: statuses[job.Name]})
+	}
+	run := &actions_model.ActionRun{WorkflowID: "ci.yaml", Event: webhook_module.HookEventPullRequest}
+
+	// the contexts are the same as the ones of the commit statuses
+	for _, job := range jobs {
+		assert.Contains(t, []string{"CI / test (1) (pull_request)", "CI / test (2) (pull_request)", "CI / Lint code (pull_request)"}, commitStatusContext(run, job))
+	}
+
+	pb := &git_model.ProtectedBranch{
+		EnableStatusCheck: true,
+		StatusCheckContexts: []string{
+			// the legs of a matrix job are required individually
+			"CI / test (1) (pull_request)",
+			"CI / test (2) (pull_request)",
+			// the renamed job is required by its name rather than its id
+			"CI / Lint code (pull_request)",
+			"CI / lint (pull_request)",
+			// a pattern matching all legs is as bad as the worst leg
+			"CI / test * (pull_request)",
+		},
+	}
+	assert.Equal(t, &RequiredChecks{
+		Satisfied: []string{"CI / test (1) (pull_request)"},
+		Pending:   []string{"CI / Lint code (pull_request)"},
+		Failing:   []string{"CI / test (2) (pull_request)", "CI / test * (pull_request)"},
+		Missing:   []string{"CI / lint (pull_request)"},
+	}, MatchRequiredChecks(run, jobs, pb))
+
+	// the runs of other events don't create commit statuses
+	run.Event = webhook_module.HookEventIssues
+	assert.Equal(t, &RequiredChecks{Missing: pb.StatusCheckContexts}, MatchRequiredChecks(run, jobs, pb))
+
+	// nothing is required without status checks
+	pb.EnableStatusCheck = false
+	assert.Equal(t, &RequiredChecks{}, MatchRequiredChecks(run, jobs, pb))
+}
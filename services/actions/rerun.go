@@ -0,0 +1,141 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// RerunFromJob reruns the job and all jobs which need it directly or indirectly, the other jobs and their outputs are kept.
+// jobID is the id of the job in the workflow, so all legs of a matrix job are rerun together.
+// The jobs which the rerun jobs need must have succeeded, and the artifacts of the run must not have expired,
+// since the rerun jobs may still depend on them.
+func RerunFromJob(ctx context.Context, run *actions_model.ActionRun, jobID string) error {
+	return rerunFromJobs(ctx, run, nil, jobID)
+}
+
+// rerunFromJobs reruns the jobs and all jobs which need them like RerunFromJob.
+// The jobs keep their payloads, so they run the workflow content which the run was created from rather than the current file.
+// runCols are the other columns of the run which are updated in the same transaction, like the count of the automatic reruns,
+// so they are never saved if the jobs fail to be rerun.
+func rerunFromJobs(ctx context.Context, run *actions_model.ActionRun, runCols []string, jobIDs ...string) error {
+	if !run.Status.IsDone() {
+		return util.NewInvalidArgumentErrorf("run %d is not done", run.Index)
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	rerunJobs, upstreamJobs, err := resolveRerunFromJob(jobs, jobIDs...)
+	if err != nil {
+		return err
+	}
+	if len(upstreamJobs) > 0 {
+		names, err := actions_model.ListExpiredArtifactNames(ctx, run.ID)
+		if err != nil {
+			return fmt.Errorf("ListExpiredArtifactNames: %w", err)
+		}
+		if len(names) > 0 {
+			return util.NewInvalidArgumentErrorf("the artifacts %s of run %d have expired, please rerun all jobs instead", strings.Join(names, ", "), run.Index)
+		}
+	}
+
+	run.PreviousDuration = run.Duration()
+	run.Started = 0
+	run.Stopped = 0
+	run.CompletedNotified = false
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := actions_model.UpdateRun(ctx, run, append([]string{"started", "stopped", "previous_duration", "completed_notified"}, runCols...)...); err != nil {
+			return fmt.Errorf("UpdateRun: %w", err)
+		}
+		for _, job := range rerunJobs {
+			status := job.Status
+			// the job emitter will make the jobs waiting or reach their gates once their needs are done
+			job.TaskID = 0
+			job.Status = actions_model.StatusBlocked
+			job.Started = 0
+			job.Stopped = 0
+			job.GateDeadline = 0
+			job.GateDecidedBy = 0
+			if _, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "task_id", "status", "started", "stopped", "gate_deadline", "gate_decided_by"); err != nil {
+				return fmt.Errorf("UpdateRunJob: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	CreateCommitStatus(ctx, rerunJobs...)
+
+	// the artifacts uploaded before will be downloaded by the rerun jobs rather than being regenerated
+	if _, err := actions_model.ExtendArtifactsForRerun(ctx, run.ID); err != nil {
+		log.Error("ExtendArtifactsForRerun [run: %d]: %v", run.ID, err)
+	}
+
+	return EmitJobsIfReady(run.ID)
+}
+
+// resolveRerunFromJob returns the jobs to rerun from the jobs with jobIDs, and the jobs which they need but won't be rerun
+func resolveRerunFromJob(jobs []*actions_model.ActionRunJob, jobIDs ...string) (rerunJobs, upstreamJobs []*actions_model.ActionRunJob, err error) {
+	idToJobs := make(map[string][]*actions_model.ActionRunJob, len(jobs))
+	for _, job := range jobs {
+		idToJobs[job.JobID] = append(idToJobs[job.JobID], job)
+	}
+	for _, jobID := range jobIDs {
+		if len(idToJobs[jobID]) == 0 {
+			return nil, nil, util.NewNotExistErrorf("job %q does not exist", jobID)
+		}
+	}
+
+	// the jobs which need any rerun job directly or indirectly are rerun too
+	rerunIDs := container.SetOf(jobIDs...)
+	for changed := true; changed; {
+		changed = false
+		for _, job := range jobs {
+			if rerunIDs.Contains(job.JobID) {
+				continue
+			}
+			for _, need := range job.Needs {
+				if rerunIDs.Contains(need) {
+					rerunIDs.Add(job.JobID)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	upstreamIDs := make(container.Set[string])
+	for _, job := range jobs {
+		if !rerunIDs.Contains(job.JobID) {
+			continue
+		}
+		if !job.Status.IsDone() {
+			return nil, nil, util.NewInvalidArgumentErrorf("job %q is not done", job.Name)
+		}
+		rerunJobs = append(rerunJobs, job)
+		for _, need := range job.Needs {
+			if !rerunIDs.Contains(need) && upstreamIDs.Add(need) {
+				for _, upstream := range idToJobs[need] {
+					if upstream.Status != actions_model.StatusSuccess {
+						return nil, nil, util.NewInvalidArgumentErrorf("job %q needed by job %q is %s rather than success", upstream.Name, job.Name, upstream.Status)
+					}
+					upstreamJobs = append(upstreamJobs, upstream)
+				}
+			}
+		}
+	}
+	return rerunJobs, upstreamJobs, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_resolveRerunFromJob(t *testing.T) {
+	newJobs := func() []*actions_model.ActionRunJob {
+		return []*actions_model.ActionRunJob{
+			{ID: 1, JobID: "build", Name: "build (linux)", Status: actions_model.StatusSuccess},
+			{ID: 2, JobID: "build", Name: "build (windows)", Status: actions_model.StatusSuccess},
+			{ID: 3, JobID: "lint", Name: "lint", Status: actions_model.StatusFailure},
+			{ID: 4, JobID: "test", Name: "test", Needs: []string{"build"}, Status: actions_model.StatusFailure},
+			{ID: 5, JobID: "package", Name: "package", Needs: []string{"test", "build"}, Status: actions_model.StatusSkipped},
+			{ID: 6, JobID: "deploy", Name: "deploy", Needs: []string{"package"}, Status: actions_model.StatusSkipped},
+			{ID: 7, JobID: "report", Name: "report", Needs: []string{"lint"}, Status: actions_model.StatusSkipped},
+		}
+	}
+	ids := func(jobs []*actions_model.ActionRunJob) []int64 {
+		ret := make([]int64, 0, len(jobs))
+		for _, job := range jobs {
+			ret = append(ret, job.ID)
+		}
+		return ret
+	}
+
+	t.Run("reruns the job and its dependents", func(t *testing.T) {
+		rerunJobs, upstreamJobs, err := resolveRerunFromJob(newJobs(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{4, 5, 6}, ids(rerunJobs))
+		assert.Equal(t, []int64{1, 2}, ids(upstreamJobs))
+	})
+
+	t.Run("reruns all legs of a matrix job", func(t *testing.T) {
+		rerunJobs, upstreamJobs, err := resolveRerunFromJob(newJobs(), "build")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 4, 5, 6}, ids(rerunJobs))
+		assert.Empty(t, upstreamJobs)
+	})
+
+	t.Run("job does not exist", func(t *testing.T) {
+		_, _, err := resolveRerunFromJob(newJobs(), "release")
+		assert.ErrorIs(t, err, util.ErrNotExist)
+	})
+
+	t.Run("upstream job failed", func(t *testing.T) {
+		_, _, err := resolveRerunFromJob(newJobs(), "report")
+		assert.ErrorIs(t, err, util.ErrInvalidArgument)
+		assert.ErrorContains(t, err, `job "lint" needed by job "report" is failure`)
+	})
+
+	t.Run("job is not done", func(t *testing.T) {
+		jobs := newJobs()
+		jobs[5].Status = actions_model.StatusRunning
+		_, _, err := resolveRerunFromJob(jobs, "package")
+		assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// retryAttempts is how many times the critical database operations of creating runs and schedules are attempted,
+// so the triggers won't be lost if the database is unavailable for a moment
+const retryAttempts = 3
+
+// retryBackoff is how long to wait before the first retry, it's doubled for every retry
+var retryBackoff = 500 * time.Millisecond
+
+// withRetry calls fn until it succeeds, fails with an error which isn't a transient error of the database, or has failed retryAttempts times.
+// fn must be idempotent, since the previous attempt may have succeeded even if it returned an error.
+func withRetry(ctx context.Context, name string, fn func() error) error {
+	return retryIf(ctx, name, db.IsErrTransient, fn)
+}
+
+// retryIf calls fn until it succeeds, fails with an error which isn't retryable, or has failed retryAttempts times.
+func retryIf(ctx context.Context, name string, retryable func(error) bool, fn func() error) error {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retryable(err) || attempt >= retryAttempts || ctx.Err() != nil {
+			return err
+		}
+		log.Warn("%s failed in attempt %d, retry after %v: %v", name, attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runFingerprint identifies the run created for the trigger event of the workflow by a notification,
+// so retrying to insert the run won't create duplicate runs.
+func runFingerprint(notificationID, entryName, triggerEvent string) string {
+	h := sha256.Sum256([]byte(notificationID + "\n" + entryName + "\n" + triggerEvent))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_withRetry(t *testing.T) {
+	defer test.MockVariableValue(&retryBackoff, 0)()
+	errDB := fmt.Errorf("database is unavailable: %w", driver.ErrBadConn)
+
+	calls := 0
+	err := withRetry(context.Background(), "test", func() error {
+		calls++
+		if calls < retryAttempts {
+			return errDB
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, retryAttempts, calls)
+
+	calls = 0
+	err = withRetry(context.Background(), "test", func() error {
+		calls++
+		return errDB
+	})
+	assert.ErrorIs(t, err, errDB)
+	assert.Equal(t, retryAttempts, calls)
+
+	// the errors which aren't transient errors of the database aren't retried
+	errInvalid := errors.New("invalid workflow")
+	calls = 0
+	err = withRetry(context.Background(), "test", func() error {
+		calls++
+		return errInvalid
+	})
+	assert.ErrorIs(t, err, errInvalid)
+	assert.Equal(t, 1, calls)
+
+	// stop retrying when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = withRetry(ctx, "test", func() error {
+		calls++
+		return errDB
+	})
+	assert.ErrorIs(t, err, errDB)
+	assert.Equal(t, 1, calls)
+}
+
+func Test_runFingerprint(t *testing.T) {
+	fingerprint := runFingerprint("notification", "test.yaml", "push")
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, runFingerprint("notification", "test.yaml", "push"))
+	assert.NotEqual(t, fingerprint, runFingerprint("notification", "test.yaml", "pull_request"))
+	assert.NotEqual(t, fingerprint, runFingerprint("notification", "build.yaml", "push"))
+	assert.NotEqual(t, fingerprint, runFingerprint("another", "test.yaml", "push"))
+}
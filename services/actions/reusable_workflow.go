@@ -0,0 +1,166 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ReusableWorkflowChangedEventType is the type of the `repository_dispatch` event sent to the callers of a changed reusable workflow
+const ReusableWorkflowChangedEventType = "reusable-workflow-changed"
+
+// recordWorkflowDependencies records the reusable workflows of other repositories called by the workflows on the default branch,
+// the disabled workflows are ignored.
+func recordWorkflowDependencies(ctx context.Context, repo *repo_model.Repository, ref git.RefName, commit *git.Commit, cfg *repo_model.ActionsConfig) {
+	workflows, err := readWorkflows(commit, cfg.WorkflowDirs)
+	if err != nil {
+		log.Error("readWorkflows [repo: %d]: %v", repo.ID, err)
+		return
+	}
+
+	var dependencies []*actions_model.ActionWorkflowDependency
+	for _, wf := range workflows {
+		if cfg.IsWorkflowDisabled(wf.EntryName) {
+			continue
+		}
+		calls, err := actions_module.ParseReusableWorkflowCalls(wf.Content)
+		if err != nil {
+			log.Warn("ParseReusableWorkflowCalls [repo: %d, workflow: %s]: %v", repo.ID, wf.EntryName, err)
+			continue
+		}
+		for _, call := range calls {
+			if call.Repo == strings.ToLower(repo.FullName()) {
+				// the push to the repository itself triggers the workflow
+				continue
+			}
+			dependencies = append(dependencies, &actions_model.ActionWorkflowDependency{
+				WorkflowID: wf.EntryName,
+				CalleeRepo: call.Repo,
+				CalleeRef:  call.Ref,
+				CalleePath: call.Path,
+			})
+		}
+	}
+	if err := actions_model.ReplaceWorkflowDependencies(ctx, repo.ID, dependencies); err != nil {
+		log.Error("ReplaceWorkflowDependencies [repo: %d]: %v", repo.ID, err)
+	}
+}
+
+// notifyReusableWorkflowCallers sends a `repository_dispatch` event to the repositories calling the reusable workflows of the repository
+// by ref, if the push from oldCommitID to newCommitID changes any of them. At most setting.Actions.ReusableWorkflowCallers repositories
+// are notified for a push to avoid trigger storms, and the callers of a private repository are only notified if they have the same owner.
+func notifyReusableWorkflowCallers(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, ref git.RefName, oldCommitID, newCommitID string) {
+	if setting.Actions.ReusableWorkflowCallers == 0 || git.IsEmptyCommitID(newCommitID) || !(ref.IsBranch() || ref.IsTag()) {
+		return
+	}
+	dependencies, err := actions_model.FindWorkflowDependents(ctx, strings.ToLower(repo.FullName()), ref.ShortName())
+	if err != nil {
+		log.Error("FindWorkflowDependents [repo: %d, ref: %s]: %v", repo.ID, ref, err)
+		return
+	} else if len(dependencies) == 0 {
+		return
+	}
+
+	changed, err := changedReusableWorkflows(ctx, repo, dependencies, oldCommitID, newCommitID)
+	if err != nil {
+		log.Error("changedReusableWorkflows [repo: %d, ref: %s]: %v", repo.ID, ref, err)
+		return
+	}
+	callers, paths := selectReusableWorkflowCallers(dependencies, changed, repo.ID, setting.Actions.ReusableWorkflowCallers)
+	for _, callerID := range callers {
+		caller, err := repo_model.GetRepositoryByID(ctx, callerID)
+		if err != nil {
+			log.Error("GetRepositoryByID [repo: %d]: %v", callerID, err)
+			continue
+		}
+		if repo.IsPrivate && caller.OwnerID != repo.OwnerID {
+			log.Trace("skip notifying repo %d calling the reusable workflows of private repo %d", callerID, repo.ID)
+			continue
+		}
+		if err := DispatchRepositoryEvent(ctx, pusher, caller, ReusableWorkflowChangedEventType, map[string]any{
+			"repository": repo.FullName(),
+			"ref":        ref.ShortName(),
+			"sha":        newCommitID,
+			"workflows":  paths[callerID],
+		}); err != nil {
+			log.Error("DispatchRepositoryEvent [repo: %d]: %v", callerID, err)
+		}
+	}
+}
+
+// changedReusableWorkflows returns the paths of the reusable workflows in the dependencies which have been changed by the push,
+// all of them are changed if the ref has been created.
+func changedReusableWorkflows(ctx context.Context, repo *repo_model.Repository, dependencies []*actions_model.ActionWorkflowDependency, oldCommitID, newCommitID string) (map[string]bool, error) {
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	newCommit, err := gitRepo.GetCommit(newCommitID)
+	if err != nil {
+		return nil, err
+	}
+	var oldCommit *git.Commit
+	if !git.IsEmptyCommitID(oldCommitID) {
+		if oldCommit, err = gitRepo.GetCommit(oldCommitID); err != nil {
+			return nil, err
+		}
+	}
+
+	changed := make(map[string]bool, len(dependencies))
+	for _, dependency := range dependencies {
+		if _, ok := changed[dependency.CalleePath]; ok {
+			continue
+		}
+		changed[dependency.CalleePath] = oldCommit == nil || blobIDOfPath(oldCommit, dependency.CalleePath) != blobIDOfPath(newCommit, dependency.CalleePath)
+	}
+	return changed, nil
+}
+
+// blobIDOfPath returns the ID of the file at the path of the commit, or empty if it doesn't exist
+func blobIDOfPath(commit *git.Commit, path string) string {
+	entry, err := commit.GetTreeEntryByPath(path)
+	if err != nil {
+		return ""
+	}
+	return entry.ID.String()
+}
+
+// selectReusableWorkflowCallers returns the distinct repositories calling the changed reusable workflows in the order of the dependencies,
+// and the changed paths called by each of them. The repository of the reusable workflows is excluded, and at most limit repositories are returned.
+func selectReusableWorkflowCallers(dependencies []*actions_model.ActionWorkflowDependency, changed map[string]bool, calleeRepoID int64, limit int) ([]int64, map[int64][]string) {
+	var callers []int64
+	var truncated bool
+	paths := make(map[int64][]string)
+	for _, dependency := range dependencies {
+		if !changed[dependency.CalleePath] || dependency.RepoID == calleeRepoID {
+			continue
+		}
+		if _, ok := paths[dependency.RepoID]; !ok {
+			if len(callers) == limit {
+				truncated = true
+				continue
+			}
+			callers = append(callers, dependency.RepoID)
+		}
+		if !slices.Contains(paths[dependency.RepoID], dependency.CalleePath) {
+			paths[dependency.RepoID] = append(paths[dependency.RepoID], dependency.CalleePath)
+		}
+	}
+	if truncated {
+		log.Warn("more than %d repositories call the changed reusable workflows of repo %d, the others are not notified", limit, calleeRepoID)
+	}
+	return callers, paths
+}
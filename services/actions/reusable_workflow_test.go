@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_selectReusableWorkflowCallers(t *testing.T) {
+	const build, release, lint = ".gitea/workflows/build.yml", ".gitea/workflows/release.yml", ".gitea/workflows/lint.yml"
+	dependencies := []*actions_model.ActionWorkflowDependency{
+		{RepoID: 1, WorkflowID: "ci.yml", CalleePath: build},
+		{RepoID: 2, WorkflowID: "ci.yml", CalleePath: build},
+		{RepoID: 2, WorkflowID: "release.yml", CalleePath: release},
+		{RepoID: 2, WorkflowID: "nightly.yml", CalleePath: build},
+		{RepoID: 3, WorkflowID: "lint.yml", CalleePath: lint},
+		{RepoID: 4, WorkflowID: "ci.yml", CalleePath: release},
+		{RepoID: 5, WorkflowID: "ci.yml", CalleePath: build},
+	}
+	changed := map[string]bool{build: true, release: true, lint: false}
+
+	callers, paths := selectReusableWorkflowCallers(dependencies, changed, 1, 10)
+	assert.Equal(t, []int64{2, 4, 5}, callers)
+	assert.Equal(t, map[int64][]string{2: {build, release}, 4: {release}, 5: {build}}, paths)
+
+	// the fan-out is capped, but all changed paths of the selected callers are kept
+	callers, paths = selectReusableWorkflowCallers(dependencies, changed, 1, 1)
+	assert.Equal(t, []int64{2}, callers)
+	assert.Equal(t, map[int64][]string{2: {build, release}}, paths)
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// runCompletedHook is called once a run is completed. The hooks failing with an error are retried by runCompletedQueue later,
+// every hook independently of the others, so a hook should be safe to be called again after it fails.
+type runCompletedHook func(ctx context.Context, run *actions_model.ActionRun) error
+
+var (
+	runCompletedHooks = map[string]runCompletedHook{}
+	runCompletedQueue *queue.WorkerPoolQueue[*runCompletedRequest]
+)
+
+type runCompletedRequest struct {
+	RunID int64
+	Hook  string
+}
+
+// registerRunCompletedHook registers the hook called once a run is completed by the unique name, it should be called by init functions
+func registerRunCompletedHook(name string, hook runCompletedHook) {
+	if _, ok := runCompletedHooks[name]; ok {
+		panic(fmt.Sprintf("run completed hook %q has been registered", name))
+	}
+	runCompletedHooks[name] = hook
+}
+
+// notifyRunCompleted queues calling the registered hooks if the run is completed, only once for every run.
+func notifyRunCompleted(ctx context.Context, run *actions_model.ActionRun) error {
+	if !run.Status.IsDone() || run.CompletedNotified {
+		return nil
+	}
+	if ok, err := actions_model.MarkRunCompletedNotified(ctx, run.ID); err != nil {
+		return fmt.Errorf("MarkRunCompletedNotified: %w", err)
+	} else if !ok {
+		// it has been notified by others
+		return nil
+	}
+
+	names := make([]string, 0, len(runCompletedHooks))
+	for name := range runCompletedHooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := runCompletedQueue.Push(&runCompletedRequest{RunID: run.ID, Hook: name})
+		if err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+			log.Error("Unable to queue the run completed hook %s of run %d: %v", name, run.ID, err)
+		}
+	}
+	return nil
+}
+
+func runCompletedQueueHandler(items ...*runCompletedRequest) []*runCompletedRequest {
+	ctx := graceful.GetManager().ShutdownContext()
+	var ret []*runCompletedRequest
+	for _, req := range items {
+		hook, ok := runCompletedHooks[req.Hook]
+		if !ok {
+			log.Warn("ignore the unknown run completed hook %s of run %d", req.Hook, req.RunID)
+			continue
+		}
+		run, err := actions_model.GetRunByID(ctx, req.RunID)
+		if errors.Is(err, util.ErrNotExist) {
+			continue
+		} else if err != nil {
+			log.Error("GetRunByID [run: %d]: %v", req.RunID, err)
+			ret = append(ret, req)
+			continue
+		}
+		if !run.Status.IsDone() {
+			// it has been rerun, the hooks will be called again once it's completed
+			log.Trace("ignore the run completed hook %s of run %d since it has been rerun", req.Hook, req.RunID)
+			continue
+		}
+		if err := hook(ctx, run); err != nil {
+			log.Error("run completed hook %s [run: %d]: %v", req.Hook, req.RunID, err)
+			ret = append(ret, req)
+		}
+	}
+	return ret
+}
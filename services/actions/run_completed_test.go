@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runCompletedQueueHandler(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	calls := map[string]int{}
+	defer test.MockVariableValue(&runCompletedHooks, map[string]runCompletedHook{
+		"flaky": func(ctx context.Context, run *actions_model.ActionRun) error {
+			if calls["flaky"]++; calls["flaky"] == 1 {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+		"stable": func(ctx context.Context, run *actions_model.ActionRun) error {
+			calls["stable"]++
+			return nil
+		},
+	})()
+
+	flaky := &runCompletedRequest{RunID: 791, Hook: "flaky"}
+	// only the failed hook is retried, the unknown hooks and the deleted runs are dropped
+	assert.Equal(t, []*runCompletedRequest{flaky}, runCompletedQueueHandler(
+		flaky,
+		&runCompletedRequest{RunID: 791, Hook: "stable"},
+		&runCompletedRequest{RunID: 791, Hook: "unknown"},
+		&runCompletedRequest{RunID: 100000, Hook: "stable"},
+	))
+	assert.Empty(t, runCompletedQueueHandler(flaky))
+	assert.Equal(t, map[string]int{"flaky": 2, "stable": 1}, calls)
+
+	// the hooks of the runs which have been rerun are called once they are completed again
+	_, err := db.GetEngine(db.DefaultContext).ID(791).Cols("status").Update(&actions_model.ActionRun{Status: actions_model.StatusWaiting})
+	require.NoError(t, err)
+	assert.Empty(t, runCompletedQueueHandler(&runCompletedRequest{RunID: 791, Hook: "stable"}))
+	assert.Equal(t, 1, calls["stable"])
+}
+
+func Test_runCompletedHooks(t *testing.T) {
+	names := make([]string, 0, len(runCompletedHooks))
+	for name := range runCompletedHooks {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"workflow_run", "audit_export", "run_event", "log_archive", "infra_retry"}, names)
+}
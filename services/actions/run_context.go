@@ -0,0 +1,121 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	secret_model "code.gitea.io/gitea/models/secret"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// redactedSecret is the value of the secrets in the stored contexts of runs
+const redactedSecret = "***"
+
+// runContextSnapshot is the snapshot of the contexts of a run when it's created, see actions_model.ActionRunContext
+type runContextSnapshot struct {
+	Github *model.GithubContext `json:"github"`
+	Inputs map[string]any       `json:"inputs,omitempty"`
+	Vars   map[string]string    `json:"vars"`
+	// the env of the workflow and the jobs keyed by job id, the expressions which can't be resolved when the run is created are kept as they are
+	Env map[string]map[string]string `json:"env,omitempty"`
+	// the names of the secrets which the jobs will get with redacted values, it's omitted if setting.Actions.RunContextSecrets is none
+	Secrets map[string]string `json:"secrets,omitempty"`
+}
+
+// storeRunContext stores the snapshot of the contexts which the workflow of the run sees, so the support can inspect what it saw.
+// It does nothing if setting.Actions.RunContextMaxSize is zero.
+func storeRunContext(ctx context.Context, run *actions_model.ActionRun, input *notifyInput, event map[string]any, vars map[string]string, envs map[string]map[string]string) error {
+	if setting.Actions.RunContextMaxSize == 0 {
+		return nil
+	}
+
+	gitCtx := runGitContext(run, input, event)
+	gitCtx.RunID = fmt.Sprint(run.ID)
+	gitCtx.RunNumber = fmt.Sprint(run.Index)
+	inputs, _ := event["inputs"].(map[string]any)
+	snapshot := &runContextSnapshot{
+		Github: gitCtx,
+		Inputs: inputs,
+		Vars:   vars,
+		Env:    envs,
+	}
+	if setting.Actions.RunContextSecrets == setting.RunContextSecretsRedacted {
+		secrets, err := runSecretNames(ctx, run)
+		if err != nil {
+			return err
+		}
+		snapshot.Secrets = secrets
+	}
+
+	content, truncated, err := marshalRunContext(snapshot, setting.Actions.RunContextMaxSize)
+	if err != nil {
+		return err
+	}
+	return actions_model.InsertRunContext(ctx, &actions_model.ActionRunContext{
+		RunID:     run.ID,
+		RepoID:    run.RepoID,
+		Content:   string(content),
+		Truncated: truncated,
+	})
+}
+
+// runSecretNames returns the names of the secrets which the jobs of the run will get with redacted values, like getSecretsOfTask
+func runSecretNames(ctx context.Context, run *actions_model.ActionRun) (map[string]string, error) {
+	secrets := map[string]string{
+		"GITHUB_TOKEN": redactedSecret,
+		"GITEA_TOKEN":  redactedSecret,
+	}
+	if run.IsForkPullRequest && run.TriggerEvent != actions_module.GithubEventPullRequestTarget {
+		return secrets, nil
+	}
+
+	ownerSecrets, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{OwnerID: run.OwnerID})
+	if err != nil {
+		return nil, fmt.Errorf("find secrets of owner %d: %w", run.OwnerID, err)
+	}
+	repoSecrets, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{RepoID: run.RepoID})
+	if err != nil {
+		return nil, fmt.Errorf("find secrets of repo %d: %w", run.RepoID, err)
+	}
+	for _, secret := range append(ownerSecrets, repoSecrets...) {
+		secrets[secret.Name] = redactedSecret
+	}
+	return secrets, nil
+}
+
+// marshalRunContext encodes the snapshot within maxSize bytes. If it's too large, the largest parts are dropped in turn,
+// the event payload first, and it returns true if anything has been dropped.
+func marshalRunContext(snapshot *runContextSnapshot, maxSize int64) ([]byte, bool, error) {
+	drops := []func(){
+		func() { snapshot.Github.Event = nil },
+		func() { snapshot.Env = nil },
+		func() { snapshot.Inputs = nil },
+		func() { snapshot.Vars = nil },
+		func() { snapshot.Secrets = nil },
+	}
+	truncated := false
+	for i := 0; ; i++ {
+		content, err := json.Marshal(snapshot)
+		if err != nil {
+			return nil, false, fmt.Errorf("json.Marshal: %w", err)
+		}
+		if int64(len(content)) <= maxSize {
+			return content, truncated, nil
+		}
+		if i == len(drops) {
+			// even the github context is too large
+			return []byte("{}"), true, nil
+		}
+		drops[i]()
+		truncated = true
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_marshalRunContext(t *testing.T) {
+	newSnapshot := func() *runContextSnapshot {
+		return &runContextSnapshot{
+			Github: &model.GithubContext{
+				Event:     map[string]any{"ref": "refs/heads/main", "commits": strings.Repeat("a", 1000)},
+				EventName: "push",
+				Ref:       "refs/heads/main",
+			},
+			Vars:    map[string]string{"REGION": "eu"},
+			Env:     map[string]map[string]string{"build": {"TOKEN": "${{ secrets.TOKEN }}"}},
+			Secrets: map[string]string{"GITHUB_TOKEN": redactedSecret, "TOKEN": redactedSecret},
+		}
+	}
+
+	content, truncated, err := marshalRunContext(newSnapshot(), 1<<20)
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Contains(t, string(content), `"commits":"aaa`)
+	assert.Contains(t, string(content), `"TOKEN":"***"`)
+
+	content, truncated, err = marshalRunContext(newSnapshot(), 900)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.LessOrEqual(t, len(content), 900)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(content, &got))
+	assert.Nil(t, got["github"].(map[string]any)["event"])
+	assert.Equal(t, map[string]any{"REGION": "eu"}, got["vars"])
+
+	content, truncated, err = marshalRunContext(newSnapshot(), 10)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, "{}", string(content))
+}
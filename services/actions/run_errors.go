@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// failRunWithErrors fails the jobs of the run immediately if the problems found when creating the run are recorded in run.Errors,
+// like the container images which are not allowed, so no runners will pick them.
+func failRunWithErrors(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) error {
+	if len(run.Errors) == 0 {
+		return nil
+	}
+	log.Info("run %d of repo %d fails since %v", run.ID, run.RepoID, run.Errors)
+	now := timeutil.TimeStampNow()
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		for _, job := range jobs {
+			if job.Status.IsDone() {
+				continue
+			}
+			status := job.Status
+			job.Status = actions_model.StatusFailure
+			job.Stopped = now
+			if _, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "status", "stopped"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("UpdateRunJob: %w", err)
+	}
+	return nil
+}
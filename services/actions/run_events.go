@@ -0,0 +1,87 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// runEvent is the compact event of a run which is published when the run is created and when it's done,
+// see setting.Actions.RunEventPublisher
+type runEvent struct {
+	Time       time.Time `json:"time"`
+	RunID      int64     `json:"run_id"`
+	Repository string    `json:"repository"`
+	Event      string    `json:"event"`
+	Status     string    `json:"status"`
+}
+
+var eventPublisher *runEventExporter
+
+func initRunEventPublisher() {
+	if setting.Actions.RunEventPublisher == setting.RunEventPublisherNone {
+		eventPublisher = nil
+		return
+	}
+	sink, err := newRunEventSink(setting.Actions.RunEventPublisher, setting.Actions.RunEventTarget)
+	if err != nil {
+		log.Fatal("Unable to init actions run event publisher: %v", err)
+	}
+	eventPublisher = newRunEventExporter("run event", sink, setting.Actions.RunEventBuffer)
+	eventPublisher.start("actions_run_events_dropped_total", "Number of the run events which are dropped since the publish buffer is full")
+}
+
+// newRunEventSink creates the sink of the publisher, see the values of setting.Actions.RunEventPublisher.
+// The message queues like NATS or Kafka can be supported by adding their sinks here.
+func newRunEventSink(publisher, target string) (auditSink, error) {
+	switch publisher {
+	case setting.RunEventPublisherWebhook:
+		return newAuditSink(setting.AuditExporterWebhook, target)
+	default:
+		return nil, fmt.Errorf("unsupported run event publisher %q", publisher)
+	}
+}
+
+func init() {
+	registerRunCompletedHook("run_event", publishRunCompletedEvent)
+}
+
+// publishRunEvent publishes the event of the run when it's created or done, if setting.Actions.RunEventPublisher is set.
+// It doesn't wait for the event to be published, and the event is dropped if the buffer of the publisher is full.
+func publishRunEvent(ctx context.Context, run *actions_model.ActionRun) {
+	if eventPublisher == nil {
+		return
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		log.Error("LoadAttributes of run %d: %v", run.ID, err)
+		return
+	}
+	eventPublisher.export(run.ID, newRunEvent(run))
+}
+
+// publishRunCompletedEvent publishes the event of the run when it's done like publishRunEvent
+func publishRunCompletedEvent(ctx context.Context, run *actions_model.ActionRun) error {
+	publishRunEvent(ctx, run)
+	return nil
+}
+
+// newRunEvent returns the event of the run, the repository of the run should have been loaded
+func newRunEvent(run *actions_model.ActionRun) *runEvent {
+	event := &runEvent{
+		Time:   time.Now().UTC(),
+		RunID:  run.ID,
+		Event:  string(run.Event),
+		Status: run.Status.String(),
+	}
+	if run.Repo != nil {
+		event.Repository = run.Repo.FullName()
+	}
+	return event
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyAuditSink fails the first attempts of every event
+type flakyAuditSink struct {
+	recordAuditSink
+	failures atomic.Int64
+}
+
+func (s *flakyAuditSink) Send(ctx context.Context, event []byte) error {
+	if s.failures.Add(-1) >= 0 {
+		return errors.New("unavailable")
+	}
+	return s.recordAuditSink.Send(ctx, event)
+}
+
+func Test_runEventExporterRetry(t *testing.T) {
+	defer test.MockVariableValue(&retryBackoff, time.Millisecond)()
+
+	sink := &flakyAuditSink{}
+	sink.failures.Store(2)
+	exporter := newRunEventExporter("run event", sink, 1)
+
+	assert.True(t, exporter.enqueue([]byte(`{"run_id":1}`)))
+	assert.False(t, exporter.enqueue([]byte(`{"run_id":2}`)))
+	assert.EqualValues(t, 1, exporter.Dropped())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.run(ctx)
+		close(done)
+	}()
+	// the event is exported in the third attempt
+	assert.Eventually(t, func() bool { return len(sink.Events()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{`{"run_id":1}`}, sink.Events())
+	cancel()
+	<-done
+}
+
+func Test_newRunEvent(t *testing.T) {
+	event := newRunEvent(&actions_model.ActionRun{
+		ID:     10,
+		Repo:   &repo_model.Repository{OwnerName: "user2", Name: "repo1"},
+		Event:  "push",
+		Status: actions_model.StatusRunning,
+	})
+	assert.EqualValues(t, 10, event.RunID)
+	assert.Equal(t, "user2/repo1", event.Repository)
+	assert.Equal(t, "push", event.Event)
+	assert.Equal(t, "running", event.Status)
+
+	_, err := newRunEventSink("kafka", "localhost:9092")
+	assert.Error(t, err)
+	sink, err := newRunEventSink(setting.RunEventPublisherWebhook, "http://localhost/events")
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Close())
+}
+
+func Test_publishRunCompletedEvent(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	publisher := newRunEventExporter("run event", &recordAuditSink{}, 1)
+	defer test.MockVariableValue(&eventPublisher, publisher)()
+	run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: 791})
+
+	// the event of the done run is buffered like the others, and dropped without blocking if the buffer is full
+	require.NoError(t, publishRunCompletedEvent(db.DefaultContext, run))
+	require.NoError(t, publishRunCompletedEvent(db.DefaultContext, run))
+	assert.EqualValues(t, 1, publisher.Dropped())
+	assert.Contains(t, string(<-publisher.events), `"run_id":791`)
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// evaluateRunTitle evaluates the `run-name` of the workflow against the event of the run to be the title of the run,
+// the title is kept as the commit subject if the workflow has no `run-name` or it fails to be evaluated.
+// event is the decoded payload of the event, its `inputs` are the `inputs` context if there are.
+func evaluateRunTitle(run *actions_model.ActionRun, input *notifyInput, content []byte, event map[string]any, vars map[string]string) string {
+	inputs, _ := event["inputs"].(map[string]any)
+	title, err := actions_module.EvaluateRunName(content, runGitContext(run, input, event), inputs, vars)
+	if err != nil {
+		log.Warn("workflow %q of repo %s: %v, the commit subject is used as the title", run.WorkflowID, input.Repo.FullName(), err)
+		return run.Title
+	} else if title == "" {
+		return run.Title
+	}
+	return title
+}
+
+// runGitContext returns the `github` context of the run which is being created, for the expressions evaluated by Gitea.
+// The fields which are only known when the jobs run, like the run id and the token, are empty.
+func runGitContext(run *actions_model.ActionRun, input *notifyInput, event map[string]any) *model.GithubContext {
+	refName := git.RefName(run.Ref)
+	return &model.GithubContext{
+		Event:           event,
+		EventName:       run.TriggerEvent,
+		Actor:           input.Doer.Name,
+		Repository:      input.Repo.OwnerName + "/" + input.Repo.Name,
+		RepositoryOwner: input.Repo.OwnerName,
+		Sha:             run.CommitSHA,
+		Ref:             run.Ref,
+		RefName:         refName.ShortName(),
+		RefType:         refName.RefType(),
+		HeadRef:         run.HeadRef,
+		BaseRef:         run.BaseRef,
+		Workflow:        run.WorkflowID,
+		ServerURL:       setting.AppURL,
+		APIURL:          setting.AppURL + "api/v1",
+	}
+}
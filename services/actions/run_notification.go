@@ -0,0 +1,49 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	activities_model "code.gitea.io/gitea/models/activities"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// isRunBlocked reports whether the new run can't start until it's approved or the runner minutes of the owner are reset
+func isRunBlocked(ctx context.Context, run *actions_model.ActionRun) (bool, error) {
+	if run.NeedApproval {
+		return true, nil
+	}
+	if setting.Actions.MinutesQuotaPolicy != setting.MinutesQuotaPolicyQueue {
+		// the jobs have failed at once with the "fail" policy
+		return false, nil
+	}
+	return actions_model.IsMinutesQuotaExhausted(ctx, run.OwnerID)
+}
+
+// notifyRunBlocked creates an in-app notification for the doer who triggered the run if the run is blocked,
+// so the stuck runs can be discovered. The users opt in by their notification preference.
+func notifyRunBlocked(ctx context.Context, run *actions_model.ActionRun, doer *user_model.User) error {
+	if doer.IsActions() || doer.IsGhost() {
+		return nil
+	}
+	if blocked, err := isRunBlocked(ctx, run); err != nil {
+		return fmt.Errorf("isRunBlocked: %w", err)
+	} else if !blocked {
+		return nil
+	}
+
+	preference, err := user_model.GetUserSetting(ctx, doer.ID, user_model.SettingsKeyActionsRunNotification)
+	if err != nil {
+		return fmt.Errorf("GetUserSetting: %w", err)
+	}
+	if enabled, _ := strconv.ParseBool(preference); !enabled {
+		return nil
+	}
+	return activities_model.CreateActionRunNotification(ctx, doer.ID, run.RepoID, run.Index)
+}
@@ -0,0 +1,33 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isRunBlocked(t *testing.T) {
+	blocked, err := isRunBlocked(context.Background(), &actions_model.ActionRun{NeedApproval: true})
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// the quota is unlimited
+	blocked, err = isRunBlocked(context.Background(), &actions_model.ActionRun{OwnerID: 2})
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	// the jobs fail rather than wait with the "fail" policy
+	defer test.MockVariableValue(&setting.Actions.MinutesQuota, 10)()
+	defer test.MockVariableValue(&setting.Actions.MinutesQuotaPolicy, setting.MinutesQuotaPolicyFail)()
+	blocked, err = isRunBlocked(context.Background(), &actions_model.ActionRun{OwnerID: 2})
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+}
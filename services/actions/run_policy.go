@@ -0,0 +1,147 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// runPolicyCacheSize is the max number of cached decisions of the policy webhook
+const runPolicyCacheSize = 1000
+
+var (
+	runPolicyClient *http.Client
+	runPolicyCache  *expirable.LRU[string, *runPolicyDecision]
+)
+
+// runPolicyRequest is the context of the run which is posted to the policy webhook, see setting.Actions.PolicyWebhookURL
+type runPolicyRequest struct {
+	Repository        string `json:"repository"`
+	Workflow          string `json:"workflow"`
+	Event             string `json:"event"`
+	TriggerEvent      string `json:"trigger_event"`
+	Ref               string `json:"ref"`
+	CommitSHA         string `json:"commit_sha"`
+	TriggerUser       string `json:"trigger_user"`
+	IsForkPullRequest bool   `json:"is_fork_pull_request"`
+	NeedApproval      bool   `json:"need_approval"`
+}
+
+// runPolicyDecision is the response of the policy webhook.
+// The webhook can only allow or deny the run, it can't modify the run, so a response with "modify" is rejected like a failure.
+type runPolicyDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason"`
+	Modify any    `json:"modify,omitempty"`
+}
+
+func initRunPolicy() {
+	if setting.Actions.PolicyWebhookURL == "" {
+		runPolicyClient, runPolicyCache = nil, nil
+		return
+	}
+	runPolicyClient = &http.Client{
+		Timeout: setting.Actions.PolicyWebhookTimeout,
+		Transport: &http.Transport{
+			Proxy: proxy.Proxy(),
+		},
+	}
+	runPolicyCache = expirable.NewLRU[string, *runPolicyDecision](runPolicyCacheSize, nil, setting.Actions.PolicyWebhookCacheTTL)
+}
+
+// checkRunPolicy consults the policy webhook whether the run is allowed to be created.
+// The decisions are cached for identical requests, and if the webhook fails,
+// the run is allowed or denied according to setting.Actions.PolicyWebhookFailOpen.
+func checkRunPolicy(ctx context.Context, run *actions_model.ActionRun, input *notifyInput) *runPolicyDecision {
+	if runPolicyClient == nil {
+		return &runPolicyDecision{Allow: true}
+	}
+
+	body, err := json.Marshal(&runPolicyRequest{
+		Repository:        input.Repo.FullName(),
+		Workflow:          run.WorkflowID,
+		Event:             string(run.Event),
+		TriggerEvent:      run.TriggerEvent,
+		Ref:               run.Ref,
+		CommitSHA:         run.CommitSHA,
+		TriggerUser:       input.Doer.Name,
+		IsForkPullRequest: run.IsForkPullRequest,
+		NeedApproval:      run.NeedApproval,
+	})
+	if err != nil {
+		return runPolicyFailure(fmt.Errorf("json.Marshal: %w", err))
+	}
+
+	sum := sha256.Sum256(body)
+	key := hex.EncodeToString(sum[:])
+	if decision, ok := runPolicyCache.Get(key); ok {
+		return decision
+	}
+
+	decision, err := requestRunPolicy(ctx, body)
+	if err != nil {
+		return runPolicyFailure(err)
+	}
+	runPolicyCache.Add(key, decision)
+	return decision
+}
+
+func requestRunPolicy(ctx context.Context, body []byte) (*runPolicyDecision, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, setting.Actions.PolicyWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := runPolicyClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var decision runPolicyDecision
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decision); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if decision.Modify != nil {
+		return nil, fmt.Errorf("modifying the run is not supported")
+	}
+	return &decision, nil
+}
+
+// recordRunPolicyDenial records the run denied by the policy webhook as a notice of the repository,
+// since the run isn't created, the admins can't find out why the workflow didn't run otherwise.
+func recordRunPolicyDenial(ctx context.Context, input *notifyInput, workflowID, reason string) {
+	log.Info("the policy webhook denied the run of workflow %q of repo %s for event %s: %s", workflowID, input.Repo.FullName(), input.Event, reason)
+	if err := system_model.CreateNotice(ctx, system_model.NoticeRepository, "Run of workflow %q of repository %s for event %s has been denied by the policy webhook: %s",
+		workflowID, input.Repo.FullName(), input.Event, reason); err != nil {
+		log.Error("CreateNotice: %v", err)
+	}
+}
+
+func runPolicyFailure(err error) *runPolicyDecision {
+	log.Error("Failed to call the actions policy webhook: %v", err)
+	if setting.Actions.PolicyWebhookFailOpen {
+		return &runPolicyDecision{Allow: true, Reason: "the policy webhook failed"}
+	}
+	return &runPolicyDecision{Allow: false, Reason: "the policy webhook failed"}
+}
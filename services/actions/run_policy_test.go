@@ -0,0 +1,86 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRunPolicy(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req runPolicyRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Workflow == "modify.yaml" {
+			_, _ = w.Write([]byte(`{"allow": true, "modify": {"runs-on": "ubuntu-latest"}}`))
+			return
+		}
+		if req.IsForkPullRequest {
+			_, _ = w.Write([]byte(`{"allow": false, "reason": "forks are not allowed"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"allow": true}`))
+	}))
+	defer server.Close()
+
+	defer test.MockVariableValue(&setting.Actions.PolicyWebhookURL, server.URL)()
+	defer test.MockVariableValue(&setting.Actions.PolicyWebhookTimeout, time.Second)()
+	defer test.MockVariableValue(&setting.Actions.PolicyWebhookCacheTTL, time.Minute)()
+	initRunPolicy()
+	defer func() {
+		runPolicyClient, runPolicyCache = nil, nil
+	}()
+
+	input := newNotifyInput(&repo_model.Repository{OwnerName: "user2", Name: "repo1"}, &user_model.User{Name: "user2"}, "push")
+
+	run := &actions_model.ActionRun{WorkflowID: "test.yaml", Ref: "refs/heads/main", CommitSHA: "c2d72f548424103f01ee1dc02889c1e2bff816b0"}
+	assert.True(t, checkRunPolicy(context.Background(), run, input).Allow)
+	assert.True(t, checkRunPolicy(context.Background(), run, input).Allow)
+	assert.Equal(t, 1, requests, "identical requests should be cached")
+
+	forkRun := &actions_model.ActionRun{WorkflowID: "test.yaml", Ref: "refs/pull/1/head", CommitSHA: "c2d72f548424103f01ee1dc02889c1e2bff816b0", IsForkPullRequest: true}
+	decision := checkRunPolicy(context.Background(), forkRun, input)
+	assert.False(t, decision.Allow)
+	assert.Equal(t, "forks are not allowed", decision.Reason)
+	assert.Equal(t, 2, requests)
+
+	modifyRun := &actions_model.ActionRun{WorkflowID: "modify.yaml", Ref: "refs/heads/main"}
+	assert.False(t, checkRunPolicy(context.Background(), modifyRun, input).Allow, "modifying the run should be rejected")
+
+	server.Close()
+	otherRun := &actions_model.ActionRun{WorkflowID: "other.yaml", Ref: "refs/heads/main"}
+	assert.False(t, checkRunPolicy(context.Background(), otherRun, input).Allow, "should fail closed by default")
+	defer test.MockVariableValue(&setting.Actions.PolicyWebhookFailOpen, true)()
+	assert.True(t, checkRunPolicy(context.Background(), otherRun, input).Allow)
+}
+
+func TestRecordRunPolicyDenial(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	input := newNotifyInput(repo, &user_model.User{Name: "user2"}, "push")
+	recordRunPolicyDenial(db.DefaultContext, input, "test.yaml", "forks are not allowed")
+
+	unittest.AssertExistsAndLoadBean(t, &system_model.Notice{
+		Type:        system_model.NoticeRepository,
+		Description: `Run of workflow "test.yaml" of repository user2/repo1 for event push has been denied by the policy webhook: forks are not allowed`,
+	})
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CancelTimedOutRuns cancels the unfinished jobs of the runs which have been running longer than the `run-timeout-minutes`
+// of their workflows, the timeout counts from the time the first job of the run started.
+func CancelTimedOutRuns(ctx context.Context) error {
+	runs, err := actions_model.FindTimedOutRuns(ctx, timeutil.TimeStampNow())
+	if err != nil {
+		return fmt.Errorf("find timed out runs: %w", err)
+	}
+
+	for _, run := range runs {
+		if err := CancelRun(ctx, run); err != nil {
+			log.Warn("cancel timed out run %d: %v", run.ID, err)
+			// go on
+			continue
+		}
+		log.Trace("run %d of repo %d has been cancelled since it has been running longer than %v", run.ID, run.RepoID, run.Timeout)
+	}
+	return nil
+}
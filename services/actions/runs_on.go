@@ -0,0 +1,102 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// checkJobsRunsOn checks whether the registered runners which are available to the repository can run the jobs.
+// Every leg of a matrix job is a job with its own `runs-on`, so they are checked separately.
+// The jobs which omit `runs-on` or which no runners match are routed to the default label if it's configured, see defaultRunsOn.
+// The jobs which no runners match are logged by default,
+// or fail immediately if setting.Actions.StrictRunsOnCheck is true, so they won't wait for runners which don't exist.
+func checkJobsRunsOn(ctx context.Context, repo *repo_model.Repository, jobs []*actions_model.ActionRunJob) error {
+	runners, err := db.Find[actions_model.ActionRunner](ctx, actions_model.FindRunnerOptions{
+		RepoID:        repo.ID,
+		WithAvailable: true,
+	})
+	if err != nil {
+		return fmt.Errorf("FindRunners: %w", err)
+	}
+	fallback, err := defaultRunsOn(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	var failed bool
+	for _, job := range jobs {
+		if job.Status.IsDone() || job.Gate != nil {
+			continue
+		}
+		if fallback != "" && (len(job.RunsOn) == 0 || !canAnyRunnerMatch(runners, job.RunsOn)) {
+			log.Info("job %q of run %d falls back to the default label %q since no runners of repo %s match the labels %v", job.Name, job.RunID, fallback, repo.FullName(), job.RunsOn)
+			job.FallbackRunsOn = fallback
+			if _, err := actions_model.UpdateRunJob(ctx, job, nil, "fallback_runs_on"); err != nil {
+				return fmt.Errorf("UpdateRunJob: %w", err)
+			}
+		}
+		if canAnyRunnerMatch(runners, job.EffectiveRunsOn()) {
+			continue
+		}
+		if !setting.Actions.StrictRunsOnCheck {
+			log.Warn("no runners of repo %s match the labels %v of job %q of run %d", repo.FullName(), job.EffectiveRunsOn(), job.Name, job.RunID)
+			continue
+		}
+
+		log.Info("job %q of run %d fails since no runners of repo %s match the labels %v", job.Name, job.RunID, repo.FullName(), job.EffectiveRunsOn())
+		status := job.Status
+		job.Status = actions_model.StatusFailure
+		job.Stopped = timeutil.TimeStampNow()
+		if err := db.WithTx(ctx, func(ctx context.Context) error {
+			_, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "status", "stopped")
+			return err
+		}); err != nil {
+			return fmt.Errorf("UpdateRunJob: %w", err)
+		}
+		failed = true
+	}
+
+	if failed {
+		// the jobs which need the failed jobs should be skipped
+		if err := EmitJobsIfReady(jobs[0].RunID); err != nil {
+			log.Error("Emit ready jobs of run %d: %v", jobs[0].RunID, err)
+		}
+	}
+	return nil
+}
+
+func canAnyRunnerMatch(runners []*actions_model.ActionRunner, runsOn []string) bool {
+	for _, runner := range runners {
+		if runner.CanMatchLabels(runsOn) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultRunsOn returns the label applied to the jobs of the repository which no runners match,
+// the setting of the owner takes precedence over setting.Actions.DefaultRunsOn, empty means no fallback.
+func defaultRunsOn(ctx context.Context, repo *repo_model.Repository) (string, error) {
+	value, err := user_model.GetUserSetting(ctx, repo.OwnerID, user_model.SettingsKeyActionsDefaultRunsOn)
+	if err != nil {
+		return "", fmt.Errorf("GetUserSetting: %w", err)
+	}
+	if value = strings.TrimSpace(value); value != "" {
+		return value, nil
+	}
+	return strings.TrimSpace(setting.Actions.DefaultRunsOn), nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+)
+
+// checkScheduleConditions evaluates the `schedule-if` of the workflow of the schedule when a spec fires, see actions_module.ParseScheduleConditions.
+// It returns false with the condition which is false if the run should be skipped. It fails open: if the conditions are invalid
+// or any of them can't be evaluated, it's regarded as true, so the schedule runs as if it were unconditional. The repo of cron must be loaded.
+func checkScheduleConditions(ctx context.Context, cron *actions_model.ActionSchedule) (bool, string) {
+	conditions, err := actions_module.ParseScheduleConditions(cron.Content)
+	if err != nil {
+		log.Warn("ignore schedule-if of workflow %q in repo %d: %v", cron.WorkflowID, cron.RepoID, err)
+		return true, ""
+	}
+	if len(conditions) == 0 {
+		return true, ""
+	}
+
+	eval := &scheduleConditionEvaluator{cron: cron}
+	defer eval.close()
+	for _, cond := range conditions {
+		ok, err := eval.evaluate(ctx, cond)
+		if err != nil {
+			log.Warn("regard condition %q of schedule %d in repo %d as true: %v", cond, cron.ID, cron.RepoID, err)
+			continue
+		}
+		if !ok {
+			return false, cond.String()
+		}
+	}
+	return true, ""
+}
+
+// scheduleConditionEvaluator evaluates the conditions of a schedule, the git repository and the variables are loaded once when needed
+type scheduleConditionEvaluator struct {
+	cron    *actions_model.ActionSchedule
+	gitRepo *git.Repository
+	commit  *git.Commit
+	vars    map[string]string
+}
+
+func (e *scheduleConditionEvaluator) evaluate(ctx context.Context, cond *actions_module.ScheduleCondition) (bool, error) {
+	switch {
+	case cond.CommitsSince != "":
+		return e.hasCommitsSince(ctx, cond.CommitsSince == actions_module.ScheduleCommitsSinceLastSuccess)
+	case cond.FileExists != "":
+		return e.fileExists(ctx, cond.FileExists)
+	default:
+		return e.isFlagSet(ctx, cond.EnvFlag)
+	}
+}
+
+// hasCommitsSince returns whether the commit of the schedule, which is the head of the ref when the schedule was registered,
+// differs from the commit of the last scheduled run of the workflow. It's true if the workflow has never run by schedule.
+func (e *scheduleConditionEvaluator) hasCommitsSince(ctx context.Context, successOnly bool) (bool, error) {
+	opts := actions_model.FindRunOptions{
+		ListOptions:  db.ListOptions{Page: 1, PageSize: 1},
+		RepoID:       e.cron.RepoID,
+		WorkflowID:   e.cron.WorkflowID,
+		Ref:          e.cron.Ref,
+		TriggerEvent: webhook_module.HookEventSchedule,
+	}
+	if successOnly {
+		opts.Status = []actions_model.Status{actions_model.StatusSuccess}
+	}
+	runs, err := db.Find[actions_model.ActionRun](ctx, opts)
+	if err != nil {
+		return false, fmt.Errorf("FindRuns: %w", err)
+	}
+	if len(runs) == 0 {
+		return true, nil
+	}
+	return runs[0].CommitSHA != e.cron.CommitSHA, nil
+}
+
+// fileExists returns whether the path exists in the commit of the schedule
+func (e *scheduleConditionEvaluator) fileExists(ctx context.Context, path string) (bool, error) {
+	if e.gitRepo == nil {
+		gitRepo, err := git.OpenRepository(ctx, e.cron.Repo.RepoPath())
+		if err != nil {
+			return false, fmt.Errorf("git.OpenRepository: %w", err)
+		}
+		e.gitRepo = gitRepo
+	}
+	if e.commit == nil {
+		commit, err := e.gitRepo.GetCommit(e.cron.CommitSHA)
+		if err != nil {
+			return false, fmt.Errorf("gitRepo.GetCommit: %w", err)
+		}
+		e.commit = commit
+	}
+	if _, err := e.commit.GetTreeEntryByPath(path); err != nil {
+		if git.IsErrNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("GetTreeEntryByPath: %w", err)
+	}
+	return true, nil
+}
+
+// isFlagSet returns whether the variable of the repository, its owner or the instance is true, it's false if the variable doesn't exist
+func (e *scheduleConditionEvaluator) isFlagSet(ctx context.Context, name string) (bool, error) {
+	if e.vars == nil {
+		vars, err := actions_model.GetVariablesOfRepo(ctx, e.cron.Repo.OwnerID, e.cron.RepoID)
+		if err != nil {
+			return false, fmt.Errorf("GetVariablesOfRepo: %w", err)
+		}
+		e.vars = vars
+	}
+	value, ok := e.vars[strings.ToUpper(name)]
+	if !ok {
+		return false, nil
+	}
+	flag, _ := strconv.ParseBool(strings.TrimSpace(value))
+	return flag, nil
+}
+
+func (e *scheduleConditionEvaluator) close() {
+	if e.gitRepo != nil {
+		e.gitRepo.Close()
+	}
+}
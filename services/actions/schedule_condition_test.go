@@ -0,0 +1,45 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckScheduleConditions_FailOpen(t *testing.T) {
+	const head = "on:\n  schedule:\n    - cron: '0 2 * * *'\n"
+	const jobs = "jobs:\n  build:\n    runs-on: ubuntu-latest\n"
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "unconditional",
+			content: head + jobs,
+		},
+		{
+			name:    "invalid conditions",
+			content: head + "schedule-if:\n  - branch-exists: main\n" + jobs,
+		},
+		{
+			name:    "unreadable repository",
+			content: head + "schedule-if:\n  - file-exists: nightly/config.yml\n" + jobs,
+		},
+	}
+	repo := &repo_model.Repository{ID: 1, OwnerName: "user2", Name: "repo1"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron := &actions_model.ActionSchedule{ID: 1, RepoID: 1, WorkflowID: "nightly.yml", CommitSHA: "abc", Content: []byte(tt.content), Repo: repo}
+			ok, reason := checkScheduleConditions(context.Background(), cron)
+			assert.True(t, ok)
+			assert.Empty(t, reason)
+		})
+	}
+}
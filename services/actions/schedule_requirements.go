@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// scheduleDeferReason returns why the schedules of the repository are deferred by [actions] SCHEDULE_REQUIREMENTS,
+// or empty if the repository meets any of the requirements. There are no requirements unless the operators set them,
+// then the schedules are never deferred.
+func scheduleDeferReason(ctx context.Context, repo *repo_model.Repository, now time.Time) (string, error) {
+	var unmet []string
+	for _, requirement := range setting.Actions.ScheduleRequirements {
+		switch requirement {
+		case setting.ScheduleRequirementProtectedBranch:
+			protected, err := git_model.IsBranchProtected(ctx, repo.ID, repo.DefaultBranch)
+			if err != nil {
+				return "", fmt.Errorf("IsBranchProtected: %w", err)
+			} else if protected {
+				return "", nil
+			}
+			unmet = append(unmet, fmt.Sprintf("its default branch %s isn't protected", repo.DefaultBranch))
+		case setting.ScheduleRequirementRecentActivity:
+			if now.Sub(repo.UpdatedUnix.AsTime()) <= setting.Actions.ScheduleActivityWindow {
+				return "", nil
+			}
+			unmet = append(unmet, fmt.Sprintf("it hasn't been updated since %s", repo.UpdatedUnix.AsTime().Format(time.DateOnly)))
+		}
+	}
+	return strings.Join(unmet, " and "), nil
+}
+
+// updateRepoSchedulesDeferred defers the schedules of the repository if reason isn't empty, or resumes them otherwise,
+// the changes are logged with the reason
+func updateRepoSchedulesDeferred(ctx context.Context, repo *repo_model.Repository, reason string) error {
+	n, err := actions_model.SetRepoSchedulesDeferred(ctx, repo.ID, reason != "")
+	if err != nil {
+		return fmt.Errorf("SetRepoSchedulesDeferred: %w", err)
+	}
+	if n == 0 {
+		return nil
+	}
+	if reason != "" {
+		log.Info("deferred %d schedules of repo %s since %s, see [actions] SCHEDULE_REQUIREMENTS", n, repo.FullName(), reason)
+	} else {
+		log.Info("resumed %d deferred schedules of repo %s", n, repo.FullName())
+	}
+	return nil
+}
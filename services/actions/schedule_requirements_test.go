@@ -0,0 +1,39 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scheduleDeferReason(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &repo_model.Repository{ID: 1, DefaultBranch: "main", UpdatedUnix: timeutil.TimeStamp(now.Add(-40 * 24 * time.Hour).Unix())}
+
+	// there are no requirements by default
+	reason, err := scheduleDeferReason(context.Background(), repo, now)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	defer test.MockVariableValue(&setting.Actions.ScheduleRequirements, []string{setting.ScheduleRequirementRecentActivity})()
+	defer test.MockVariableValue(&setting.Actions.ScheduleActivityWindow, 90*24*time.Hour)()
+	reason, err = scheduleDeferReason(context.Background(), repo, now)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	setting.Actions.ScheduleActivityWindow = 30 * 24 * time.Hour
+	reason, err = scheduleDeferReason(context.Background(), repo, now)
+	require.NoError(t, err)
+	assert.Equal(t, "it hasn't been updated since "+repo.UpdatedUnix.AsTime().Format(time.DateOnly), reason)
+}
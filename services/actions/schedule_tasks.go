@@ -10,9 +10,16 @@ import (
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 
 	"github.com/nektos/act/pkg/jobparser"
@@ -32,6 +39,8 @@ func startTasks(ctx context.Context) error {
 
 	// Retrieve specs in pages until all specs have been retrieved
 	now := time.Now()
+	// the reasons why the schedules of the repositories are deferred, every repository is checked once
+	deferReasons := make(map[int64]string)
 	for page := 1; ; page++ {
 		// Retrieve the specs for the current page
 		specs, _, err := actions_model.FindSpecs(ctx, actions_model.FindSpecOptions{
@@ -54,10 +63,11 @@ func startTasks(ctx context.Context) error {
 			// cancel running jobs if the event is push
 			if row.Schedule.Event == webhook_module.HookEventPush {
 				// cancel running jobs of the same workflow
-				if err := actions_model.CancelRunningJobs(
+				if err := actions_model.CancelRunningJobsOfWorkflowDir(
 					ctx,
 					row.RepoID,
 					row.Schedule.Ref,
+					cancelWorkflowDir(row.Schedule.WorkflowDir),
 					row.Schedule.WorkflowID,
 					webhook_module.HookEventSchedule,
 				); err != nil {
@@ -70,7 +80,28 @@ func startTasks(ctx context.Context) error {
 				continue
 			}
 
-			if err := CreateScheduleTask(ctx, row.Schedule); err != nil {
+			reason, checked := deferReasons[row.RepoID]
+			if !checked {
+				if reason, err = scheduleDeferReason(ctx, row.Repo, now); err != nil {
+					log.Error("scheduleDeferReason: %v", err)
+					return err
+				}
+				deferReasons[row.RepoID] = reason
+				if row.Schedule.Deferred != (reason != "") {
+					if err := updateRepoSchedulesDeferred(ctx, row.Repo, reason); err != nil {
+						log.Error("updateRepoSchedulesDeferred: %v", err)
+						return err
+					}
+				}
+			}
+			row.Schedule.Deferred = reason != ""
+
+			// the next time of a disabled or deferred schedule is still updated, so it won't fire at once when it's enabled or resumed again
+			if row.Schedule.Disabled {
+				log.Trace("skip disabled schedule %d of workflow %q in repo %d", row.ScheduleID, row.Schedule.WorkflowID, row.RepoID)
+			} else if row.Schedule.Deferred {
+				log.Trace("skip deferred schedule %d of workflow %q in repo %d since %s", row.ScheduleID, row.Schedule.WorkflowID, row.RepoID, reason)
+			} else if err := CreateScheduleTask(ctx, row.Schedule, row.Spec); err != nil {
 				log.Error("CreateScheduleTask: %v", err)
 				return err
 			}
@@ -84,7 +115,8 @@ func startTasks(ctx context.Context) error {
 
 			// Update the spec's next run time and previous run time
 			row.Prev = row.Next
-			row.Next = timeutil.TimeStamp(schedule.Next(now.Add(1 * time.Minute)).Unix())
+			jitter := actions_model.ScheduleJitter(row.RepoID, row.Schedule.WorkflowID)
+			row.Next = timeutil.TimeStamp(actions_model.NextScheduleTime(schedule, now.Add(1*time.Minute), jitter).Unix())
 			if err := actions_model.UpdateScheduleSpec(ctx, row, "prev", "next"); err != nil {
 				log.Error("UpdateScheduleSpec: %v", err)
 				return err
@@ -100,36 +132,296 @@ func startTasks(ctx context.Context) error {
 	return nil
 }
 
-// CreateScheduleTask creates a scheduled task from a cron action schedule.
+// EnableOrDisableSchedule enables or disables a schedule of the repository without changing the workflow,
+// the disabled schedule is skipped by the scheduler until it's enabled again.
+func EnableOrDisableSchedule(ctx context.Context, repo *repo_model.Repository, scheduleID int64, isEnable bool) error {
+	if err := actions_model.SetScheduleDisabled(ctx, repo.ID, scheduleID, !isEnable); err != nil {
+		return fmt.Errorf("SetScheduleDisabled: %w", err)
+	}
+	log.Trace("schedule %d of repo %s has been enabled: %t", scheduleID, repo.FullName(), isEnable)
+	return nil
+}
+
+// PauseWorkflowSchedules pauses all schedules of the workflow of the repository without changing the workflow, like during a maintenance window.
+// The paused schedules are disabled, and they stay disabled even if they are re-registered with other specs until they are resumed.
+func PauseWorkflowSchedules(ctx context.Context, repo *repo_model.Repository, workflowID string) error {
+	if _, err := actions_model.SetWorkflowSchedulesPaused(ctx, repo.ID, workflowID, true); err != nil {
+		return fmt.Errorf("SetWorkflowSchedulesPaused: %w", err)
+	}
+	log.Trace("schedules of workflow %q of repo %s have been paused", workflowID, repo.FullName())
+	return nil
+}
+
+// ResumeWorkflowSchedules resumes all schedules of the workflow of the repository, they fire at their next times as if they had never been paused.
+// The occurrences missed while paused are skipped, unless catchUp is true, then a schedule which has missed any occurrence runs once at once.
+func ResumeWorkflowSchedules(ctx context.Context, repo *repo_model.Repository, workflowID string, catchUp bool) error {
+	schedules, err := actions_model.SetWorkflowSchedulesPaused(ctx, repo.ID, workflowID, false)
+	if err != nil {
+		return fmt.Errorf("SetWorkflowSchedulesPaused: %w", err)
+	}
+	log.Trace("schedules of workflow %q of repo %s have been resumed", workflowID, repo.FullName())
+	if !catchUp {
+		return nil
+	}
+
+	actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions)
+	if err != nil {
+		if repo_model.IsErrUnitTypeNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("GetUnit: %w", err)
+	}
+	if actionsUnit.ActionsConfig().IsWorkflowDisabled(workflowID) {
+		return nil
+	}
+
+	now := time.Now()
+	for _, cron := range schedules {
+		// the deferred schedules don't catch up, they don't fire until the repository meets the requirements of the site
+		if !cron.Paused || cron.Deferred {
+			continue
+		}
+		spec := missedScheduleSpec(cron.Specs, cron.PausedUnix.AsLocalTime(), now)
+		if spec == "" {
+			continue
+		}
+		cron.Repo = repo
+		if _, err := createScheduleRun(ctx, cron, spec, nil); err != nil {
+			return fmt.Errorf("createScheduleRun: %w", err)
+		}
+		log.Trace("schedule %d of workflow %q of repo %s has caught up with spec %q", cron.ID, workflowID, repo.FullName(), spec)
+	}
+	return nil
+}
+
+// missedScheduleSpec returns the first spec which would have fired between since and now, or empty if none would.
+func missedScheduleSpec(specs []string, since, now time.Time) string {
+	for _, spec := range specs {
+		schedule, err := actions_model.ParseScheduleSpec(spec)
+		if err != nil {
+			continue
+		}
+		if next := schedule.Next(since); !next.IsZero() && !next.After(now) {
+			return spec
+		}
+	}
+	return ""
+}
+
+// revalidateSchedules checks the schedules of the repository after it has been renamed or transferred.
+// The runs in progress are not touched, but the schedules follow the new owner so that the future runs will be created for it,
+// and the schedules whose commits can't be found in the repository at the new path are logged as orphaned.
+// Nothing else needs updating, since the schedules and the cached workflows refer to the repository by id rather than by path.
+func revalidateSchedules(ctx context.Context, repoID int64) error {
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %w", err)
+	}
+
+	if affected, err := actions_model.UpdateScheduleOwnerByRepo(ctx, repo.ID, repo.OwnerID); err != nil {
+		return fmt.Errorf("UpdateScheduleOwnerByRepo: %w", err)
+	} else if affected > 0 {
+		log.Trace("update owner of %d schedules of repo %d to %d", affected, repo.ID, repo.OwnerID)
+	}
+
+	schedules, err := db.Find[actions_model.ActionSchedule](ctx, actions_model.FindScheduleOptions{RepoID: repo.ID})
+	if err != nil {
+		return fmt.Errorf("FindSchedules: %w", err)
+	}
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+
+	for _, schedule := range schedules {
+		if _, err := gitRepo.GetCommit(schedule.CommitSHA); err != nil {
+			log.Warn("schedule %d of workflow %q in repo %s is orphaned since its commit %s can't be found: %v",
+				schedule.ID, schedule.WorkflowID, repo.FullName(), schedule.CommitSHA, err)
+		}
+	}
+	return nil
+}
+
+// RunScheduleNow creates a run of the schedule at once as if it had fired, so the authors can test a new schedule without waiting for the cron.
+// The rules of the scheduler are respected: the actions of the repository and the workflow must be enabled, the schedule must be enabled,
+// and it must be of the current default branch. The run is recorded as triggered manually by the doer.
+func RunScheduleNow(ctx context.Context, doer *user_model.User, cron *actions_model.ActionSchedule) (*actions_model.ActionRun, error) {
+	if cron.Repo == nil {
+		repo, err := repo_model.GetRepositoryByID(ctx, cron.RepoID)
+		if err != nil {
+			return nil, fmt.Errorf("GetRepositoryByID: %w", err)
+		}
+		cron.Repo = repo
+	}
+
+	actionsUnit, err := cron.Repo.GetUnit(ctx, unit.TypeActions)
+	if err != nil {
+		if repo_model.IsErrUnitTypeNotExist(err) {
+			return nil, util.NewInvalidArgumentErrorf("actions of repo %s are disabled", cron.Repo.FullName())
+		}
+		return nil, fmt.Errorf("GetUnit: %w", err)
+	}
+	if actionsUnit.ActionsConfig().IsWorkflowDisabled(cron.WorkflowID) {
+		return nil, util.NewInvalidArgumentErrorf("workflow %q is disabled", cron.WorkflowID)
+	}
+	if cron.Disabled {
+		return nil, util.NewInvalidArgumentErrorf("schedule %d of workflow %q is disabled", cron.ID, cron.WorkflowID)
+	}
+	// the schedules are registered only for the default branch, a schedule of another branch is stale after the default branch changed
+	if cron.Ref != git.BranchPrefix+cron.Repo.DefaultBranch {
+		return nil, util.NewInvalidArgumentErrorf("schedule %d of workflow %q is not of the default branch %q", cron.ID, cron.WorkflowID, cron.Repo.DefaultBranch)
+	}
+
+	// cancel running jobs like the scheduler does
+	if cron.Event == webhook_module.HookEventPush {
+		if err := actions_model.CancelRunningJobsOfWorkflowDir(ctx, cron.RepoID, cron.Ref, cancelWorkflowDir(cron.WorkflowDir), cron.WorkflowID, webhook_module.HookEventSchedule); err != nil {
+			log.Error("CancelRunningJobs: %v", err)
+		}
+	}
+
+	run, err := createScheduleRun(ctx, cron, "", doer)
+	if err != nil {
+		return nil, err
+	}
+	log.Trace("schedule %d of workflow %q in repo %s has been run manually by %s", cron.ID, cron.WorkflowID, cron.Repo.FullName(), doer.Name)
+	return run, nil
+}
+
+// CreateScheduleTask creates a scheduled task from a cron action schedule when its spec fires.
 // It creates an action run based on the schedule, inserts it into the database, and creates commit statuses for each job.
-func CreateScheduleTask(ctx context.Context, cron *actions_model.ActionSchedule) error {
+// If the `schedule-if` of the workflow is false, no run is created and the schedule is marked as skipped instead.
+func CreateScheduleTask(ctx context.Context, cron *actions_model.ActionSchedule, spec string) error {
+	if cron.Repo == nil {
+		repo, err := repo_model.GetRepositoryByID(ctx, cron.RepoID)
+		if err != nil {
+			return fmt.Errorf("GetRepositoryByID: %w", err)
+		}
+		cron.Repo = repo
+	}
+	if ok, reason := checkScheduleConditions(ctx, cron); !ok {
+		log.Trace("skip schedule %d of workflow %q in repo %d since %q is false", cron.ID, cron.WorkflowID, cron.RepoID, reason)
+		if err := actions_model.SetScheduleSkipped(ctx, cron.ID, reason); err != nil {
+			log.Error("SetScheduleSkipped: %v", err)
+		}
+		return nil
+	}
+	_, err := createScheduleRun(ctx, cron, spec, nil)
+	return err
+}
+
+// createScheduleRun creates the run of the schedule, doer is the user who triggered it manually, or nil if the spec fired
+func createScheduleRun(ctx context.Context, cron *actions_model.ActionSchedule, spec string, doer *user_model.User) (*actions_model.ActionRun, error) {
 	// Create a new action run based on the schedule
 	run := &actions_model.ActionRun{
-		Title:         cron.Title,
-		RepoID:        cron.RepoID,
-		OwnerID:       cron.OwnerID,
-		WorkflowID:    cron.WorkflowID,
-		TriggerUserID: cron.TriggerUserID,
-		Ref:           cron.Ref,
-		CommitSHA:     cron.CommitSHA,
-		Event:         cron.Event,
-		EventPayload:  cron.EventPayload,
-		TriggerEvent:  string(webhook_module.HookEventSchedule),
-		ScheduleID:    cron.ID,
-		Status:        actions_model.StatusWaiting,
+		Title:          cron.Title,
+		RepoID:         cron.RepoID,
+		OwnerID:        cron.Repo.OwnerID, // the repository could have been transferred since the schedule was created
+		WorkflowID:     cron.WorkflowID,
+		WorkflowDir:    cron.WorkflowDir,
+		TriggerUserID:  cron.TriggerUserID,
+		Ref:            cron.Ref,
+		CommitSHA:      cron.CommitSHA,
+		WorkflowSHA:    cron.WorkflowSHA,
+		Event:          cron.Event,
+		EventPayload:   cron.EventPayload,
+		TriggerEvent:   string(webhook_module.HookEventSchedule),
+		TriggerMatch:   &actions_model.RunTriggerMatch{Event: string(webhook_module.HookEventSchedule), Schedule: spec},
+		ScheduleID:     cron.ID,
+		Status:         actions_model.StatusWaiting,
+		HostedFallback: isHostedFallbackPermitted(cron.Repo),
+		Warnings:       actions_module.LintWorkflow(cron.Content),
+	}
+	if doer != nil {
+		run.TriggerUserID = doer.ID
+		run.ManualSchedule = true
+	}
+	if warning := sameNamedWarning(cron.SameNamed); warning != "" {
+		run.SameNamed = cron.SameNamed
+		run.Warnings = append(run.Warnings, warning)
 	}
 
 	// Parse the workflow specification from the cron schedule
 	workflows, err := jobparser.Parse(cron.Content)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	stepRetries, err := actions_module.ParseStepRetries(cron.Content, setting.Actions.MaxStepRetries)
+	if err != nil {
+		return nil, err
+	}
+	gates, err := actions_module.ParseGates(cron.Content, setting.Actions.GateTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if run.Timeout, err = actions_module.ParseRunTimeout(cron.Content); err != nil {
+		return nil, err
+	}
+
+	vars, err := actions_model.GetVariablesOfRepo(ctx, run.OwnerID, run.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	actor := doer
+	if actor == nil {
+		if actor, err = user_model.GetPossibleUserByID(ctx, run.TriggerUserID); user_model.IsErrUserNotExist(err) {
+			actor = user_model.NewGhostUser()
+		} else if err != nil {
+			return nil, fmt.Errorf("GetPossibleUserByID: %w", err)
+		}
+	}
+	event := map[string]any{}
+	if cron.EventPayload != "" {
+		if err := json.Unmarshal([]byte(cron.EventPayload), &event); err != nil {
+			return nil, fmt.Errorf("unmarshal the event payload of schedule %d: %w", cron.ID, err)
+		}
+	}
+	// the production deploys of the schedules aren't approved, but they are guarded like the others
+	if run.IsProduction, err = actions_module.IsProductionDeploy(cron.Content, setting.Actions.ProductionEnvironments); err != nil {
+		return nil, err
+	}
+	if err := checkRunWorkflow(ctx, cron.Repo, run, cron.Content, runGitContext(run, &notifyInput{Repo: cron.Repo, Doer: actor}, event), vars); err != nil {
+		return nil, err
+	}
+	envs, err := actions_module.ResolveJobEnvs(cron.Content, vars)
+	if err != nil {
+		return nil, err
+	}
+	services, err := actions_module.ParseJobServices(cron.Content, vars)
+	if err != nil {
+		return nil, err
 	}
 
+	// the schedules run the workflows of the default branch, they are trusted and need no approval
+	run.DetectionTrace = newDetectionTrace(run, nil, "")
+
 	// Insert the action run and its associated jobs into the database
-	if err := actions_model.InsertRun(ctx, run, workflows); err != nil {
-		return err
+	if err := actions_model.InsertRun(ctx, run, cron.Content, workflows, stepRetries, gates, envs, services); err != nil {
+		return nil, err
 	}
 
-	// Return nil if no errors occurred
-	return nil
+	alljobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveEnvironmentURLs(ctx, run, alljobs); err != nil {
+		log.Error("resolveEnvironmentURLs: %v", err)
+	}
+	if err := failRunWithErrors(ctx, run, alljobs); err != nil {
+		log.Error("failRunWithErrors: %v", err)
+	}
+	if err := checkMinutesQuota(ctx, run, alljobs); err != nil {
+		log.Error("checkMinutesQuota: %v", err)
+	}
+	if err := checkJobsRunsOn(ctx, cron.Repo, alljobs); err != nil {
+		log.Error("checkJobsRunsOn: %v", err)
+	}
+	exportRunAuditEvent(ctx, runAuditActionCreated, run, alljobs)
+	publishRunEvent(ctx, run)
+
+	return run, nil
 }
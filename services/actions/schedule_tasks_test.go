@@ -0,0 +1,151 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScheduleNow_Rejected(t *testing.T) {
+	newRepo := func(cfg *repo_model.ActionsConfig) *repo_model.Repository {
+		repo := &repo_model.Repository{ID: 1, OwnerName: "user2", Name: "repo1", DefaultBranch: "main", Units: []*repo_model.RepoUnit{}}
+		if cfg != nil {
+			repo.Units = append(repo.Units, &repo_model.RepoUnit{Type: unit.TypeActions, Config: cfg})
+		}
+		return repo
+	}
+	tests := []struct {
+		name     string
+		schedule *actions_model.ActionSchedule
+	}{
+		{
+			name:     "actions disabled",
+			schedule: &actions_model.ActionSchedule{ID: 1, RepoID: 1, WorkflowID: "cron.yml", Ref: "refs/heads/main", Repo: newRepo(nil)},
+		},
+		{
+			name: "workflow disabled",
+			schedule: &actions_model.ActionSchedule{ID: 1, RepoID: 1, WorkflowID: "cron.yml", Ref: "refs/heads/main", Repo: newRepo(&repo_model.ActionsConfig{
+				DisabledWorkflows: []string{"cron.yml"},
+			})},
+		},
+		{
+			name:     "schedule disabled",
+			schedule: &actions_model.ActionSchedule{ID: 1, RepoID: 1, WorkflowID: "cron.yml", Ref: "refs/heads/main", Disabled: true, Repo: newRepo(&repo_model.ActionsConfig{})},
+		},
+		{
+			name:     "not default branch",
+			schedule: &actions_model.ActionSchedule{ID: 1, RepoID: 1, WorkflowID: "cron.yml", Ref: "refs/heads/old-main", Repo: newRepo(&repo_model.ActionsConfig{})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := RunScheduleNow(context.Background(), &user_model.User{ID: 2, Name: "user2"}, tt.schedule)
+			assert.ErrorIs(t, err, util.ErrInvalidArgument)
+			assert.Nil(t, run)
+		})
+	}
+}
+
+func TestMissedScheduleSpec(t *testing.T) {
+	since := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	specs := []string{"0 12 * * *", "0 9 * * 1"}
+
+	// nothing fires between 10:30 and 11:59
+	assert.Empty(t, missedScheduleSpec(specs, since, since.Add(89*time.Minute)))
+	// the daily spec fires at 12:00
+	assert.Equal(t, "0 12 * * *", missedScheduleSpec(specs, since, since.Add(90*time.Minute)))
+	// invalid specs are ignored
+	assert.Empty(t, missedScheduleSpec([]string{"invalid"}, since, since.Add(48*time.Hour)))
+}
+
+func TestCreateScheduleRun_ContainerImages(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.AllowedImages, []string{"docker.io/library/*"})()
+	exporter := newRunEventExporter("audit event", &recordAuditSink{}, 1)
+	defer test.MockVariableValue(&auditExporter, exporter)()
+	publisher := newRunEventExporter("run event", &recordAuditSink{}, 1)
+	defer test.MockVariableValue(&eventPublisher, publisher)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	cron := &actions_model.ActionSchedule{
+		ID:            1,
+		Title:         "nightly",
+		RepoID:        repo.ID,
+		OwnerID:       repo.OwnerID,
+		Repo:          repo,
+		WorkflowID:    "nightly.yml",
+		TriggerUserID: 2,
+		Ref:           "refs/heads/master",
+		CommitSHA:     "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Event:         webhook_module.HookEventPush,
+		EventPayload:  "{}",
+		Content:       []byte("on:\n  schedule:\n    - cron: '0 12 * * *'\njobs:\n  build:\n    runs-on: ubuntu-latest\n    container: registry.example.com/build:latest\n    steps:\n      - run: make\n"),
+	}
+	run, err := createScheduleRun(db.DefaultContext, cron, "0 12 * * *", nil)
+	require.NoError(t, err)
+	// the scheduled run is checked like the runs of the events, so it fails at once
+	run = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID})
+	require.Len(t, run.Errors, 1)
+	assert.Contains(t, run.Errors[0], "registry.example.com/build:latest")
+	assert.Equal(t, actions_model.StatusFailure, run.Status)
+
+	// the scheduled run is exported and published when it's created like the others
+	var event runAuditEvent
+	require.NoError(t, json.Unmarshal(<-exporter.events, &event))
+	assert.Equal(t, runAuditActionCreated, event.Action)
+	assert.Equal(t, run.ID, event.RunID)
+	assert.Contains(t, string(<-publisher.events), fmt.Sprintf(`"run_id":%d`, run.ID))
+}
+
+func TestCreateScheduleRun_DeployGuard(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.ProductionEnvironments, []string{"production"})()
+	defer test.MockVariableValue(&setting.Actions.DeployGuard, []string{setting.DeployGuardBlockOnInProgress})()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	cron := &actions_model.ActionSchedule{
+		ID:            1,
+		Title:         "nightly deploy",
+		RepoID:        repo.ID,
+		OwnerID:       repo.OwnerID,
+		Repo:          repo,
+		WorkflowID:    "deploy.yml",
+		TriggerUserID: 2,
+		Ref:           "refs/heads/master",
+		CommitSHA:     "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Event:         webhook_module.HookEventPush,
+		EventPayload:  "{}",
+		Content:       []byte("on:\n  schedule:\n    - cron: '0 2 * * *'\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    environment: production\n    steps:\n      - run: make deploy\n"),
+	}
+	first, err := createScheduleRun(db.DefaultContext, cron, "0 2 * * *", nil)
+	require.NoError(t, err)
+	assert.True(t, first.IsProduction)
+	assert.Equal(t, actions_model.DeployGuardPassed, first.DeployGuard)
+
+	// the next nightly deploy is blocked while the previous one is still in progress
+	second, err := createScheduleRun(db.DefaultContext, cron, "0 2 * * *", nil)
+	require.NoError(t, err)
+	second = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: second.ID})
+	assert.Equal(t, actions_model.DeployGuardBlocked, second.DeployGuard)
+	assert.Equal(t, first.ID, second.DeployGuardRunID)
+	assert.Equal(t, actions_model.StatusFailure, second.Status)
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"net/url"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "code.gitea.io/gitea/services/actions"
+
+// tracerProvider provides the tracer of the spans of triggering the workflows, it's a no-op unless setting.Actions.TracingEndpoint is set
+var tracerProvider trace.TracerProvider = noop.NewTracerProvider()
+
+// initTracing exports the spans to the OTLP/HTTP endpoint of setting.Actions.TracingEndpoint in batches, they are flushed at termination
+func initTracing() {
+	if setting.Actions.TracingEndpoint == "" {
+		tracerProvider = noop.NewTracerProvider()
+		return
+	}
+	u, err := url.Parse(setting.Actions.TracingEndpoint)
+	if err != nil {
+		log.Fatal("Unable to parse actions tracing endpoint: %v", err)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	exporter, err := otlptracehttp.New(graceful.GetManager().ShutdownContext(), opts...)
+	if err != nil {
+		log.Fatal("Unable to init actions tracing exporter: %v", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "gitea"))),
+	)
+	tracerProvider = provider
+	graceful.GetManager().RunAtTerminate(func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			log.Warn("Unable to flush the actions spans: %v", err)
+		}
+	})
+}
+
+// startSpan starts a span, as a child of the span in ctx if there is one, like the span of "actions.notify".
+// The trace context of the incoming requests isn't extracted, since most events are notified by the queues
+// after the requests are done, so every notification starts a new trace.
+// Never put event payloads or other user contents into the attributes.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracerProvider.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span and records the error if there is one.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func notifyInputAttributes(input *notifyInput) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("gitea.repo.id", input.Repo.ID),
+		attribute.String("gitea.repo.full_name", input.Repo.FullName()),
+		attribute.String("gitea.actions.event", string(input.Event)),
+		attribute.String("gitea.actions.ref", input.Ref),
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNotifySpans(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	exporter := tracetest.NewInMemoryExporter()
+	defer test.MockVariableValue[trace.TracerProvider](&tracerProvider, sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	newNotifyInput(repo, doer, webhook_module.HookEventPush).
+		WithRef("refs/heads/master").
+		WithPayload(&api.PushPayload{Ref: "refs/heads/master"}).
+		Notify(withMethod(db.DefaultContext, "PushCommits"))
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	require.Len(t, spans, 4)
+	notify := spans["actions.notify"]
+	assert.False(t, notify.Parent.IsValid())
+	for _, name := range []string{"actions.detect_workflows", "actions.handle_workflows", "actions.handle_schedules"} {
+		if assert.Contains(t, spans, name) {
+			assert.Equal(t, notify.SpanContext.SpanID(), spans[name].Parent.SpanID(), name)
+		}
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, attr := range append(notify.Attributes, spans["actions.detect_workflows"].Attributes...) {
+		attrs[attr.Key] = attr.Value
+	}
+	assert.Equal(t, "user2/repo1", attrs["gitea.repo.full_name"].AsString())
+	assert.Equal(t, "push", attrs["gitea.actions.event"].AsString())
+	assert.Equal(t, "refs/heads/master", attrs["gitea.actions.ref"].AsString())
+	assert.Equal(t, "PushCommits", attrs["gitea.actions.method"].AsString())
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", attrs["gitea.commit.sha"].AsString())
+	for key := range attrs {
+		assert.NotContains(t, string(key), "payload")
+	}
+}
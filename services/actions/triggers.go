@@ -0,0 +1,45 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// GetTriggerMap returns what can trigger the workflows of the default branch of the repository, and the commit they are read from.
+// The workflows are read like they are detected, see repo_model.ActionsConfig.WorkflowDirs, and the disabled workflows are excluded.
+// An empty repository has an empty map.
+func GetTriggerMap(ctx context.Context, repo *repo_model.Repository) (*actions_module.TriggerMap, string, error) {
+	if repo.IsEmpty {
+		return &actions_module.TriggerMap{}, "", nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return nil, "", fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return nil, "", fmt.Errorf("GetBranchCommit: %w", err)
+	}
+
+	cfg := repo.MustGetUnit(ctx, unit_model.TypeActions).ActionsConfig()
+	jitter := func(workflow string) time.Duration {
+		return actions_model.ScheduleJitter(repo.ID, workflow)
+	}
+	triggerMap, err := actions_module.ReadTriggerMap(commit, cfg.WorkflowDirs, cfg.IsWorkflowDisabled, jitter, time.Now())
+	if err != nil {
+		return nil, "", fmt.Errorf("ReadTriggerMap: %w", err)
+	}
+	return triggerMap, commit.ID.String(), nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"sync"
+
+	actions_module "code.gitea.io/gitea/modules/actions"
+)
+
+// forEachWorkflow calls handle for the detected workflows in at most parallelism goroutines, see setting.Actions.WorkflowParallelism.
+// The detected workflows of the same file are handled in order by the same goroutine,
+// so the runs of a workflow cancel the earlier ones like they are handled one by one.
+// The failures should be handled by handle, so a failing workflow never stops the others.
+func forEachWorkflow(detectedWorkflows []*actions_module.DetectedWorkflow, parallelism int, handle func(dwf *actions_module.DetectedWorkflow)) {
+	if parallelism <= 1 {
+		for _, dwf := range detectedWorkflows {
+			handle(dwf)
+		}
+		return
+	}
+
+	// a workflow may be detected for more than one event, like both push and schedule
+	var groups [][]*actions_module.DetectedWorkflow
+	indexes := make(map[string]int, len(detectedWorkflows))
+	for _, dwf := range detectedWorkflows {
+		i, ok := indexes[dwf.EntryName]
+		if !ok {
+			i = len(groups)
+			indexes[dwf.EntryName] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], dwf)
+	}
+
+	ch := make(chan []*actions_module.DetectedWorkflow)
+	wg := sync.WaitGroup{}
+	for i := 0; i < min(parallelism, len(groups)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range ch {
+				for _, dwf := range group {
+					handle(dwf)
+				}
+			}
+		}()
+	}
+	for _, group := range groups {
+		ch <- group
+	}
+	close(ch)
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_forEachWorkflow(t *testing.T) {
+	detectedWorkflows := []*actions_module.DetectedWorkflow{
+		{EntryName: "build.yml", TriggerEvent: &jobparser.Event{Name: "push"}},
+		{EntryName: "test.yml", TriggerEvent: &jobparser.Event{Name: "push"}},
+		{EntryName: "fail.yml", TriggerEvent: &jobparser.Event{Name: "push"}},
+		{EntryName: "build.yml", TriggerEvent: &jobparser.Event{Name: "schedule"}},
+		{EntryName: "lint.yml", TriggerEvent: &jobparser.Event{Name: "push"}},
+	}
+
+	for _, parallelism := range []int{0, 1, 3, 10} {
+		t.Run(fmt.Sprint(parallelism), func(t *testing.T) {
+			var mu sync.Mutex
+			var handled []string
+			forEachWorkflow(detectedWorkflows, parallelism, func(dwf *actions_module.DetectedWorkflow) {
+				if dwf.EntryName == "fail.yml" {
+					// a failing workflow doesn't stop the others
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				handled = append(handled, dwf.EntryName+":"+dwf.TriggerEvent.Name)
+			})
+			assert.ElementsMatch(t, []string{"build.yml:push", "test.yml:push", "build.yml:schedule", "lint.yml:push"}, handled)
+			// the same workflow is handled in order
+			assert.Less(t, slices.Index(handled, "build.yml:push"), slices.Index(handled, "build.yml:schedule"))
+		})
+	}
+}
+
+// BenchmarkHandleWorkflows handles the push event of a repository with many workflows, every workflow creates a run with its jobs
+func BenchmarkHandleWorkflows(b *testing.B) {
+	require.NoError(b, unittest.PrepareTestDatabase())
+	require.NoError(b, git.InitSimple(context.Background()))
+
+	const workflowsNum = 50
+	repoPath := b.TempDir()
+	require.NoError(b, git.InitRepository(context.Background(), repoPath, false, git.Sha1ObjectFormat.Name()))
+	require.NoError(b, os.MkdirAll(filepath.Join(repoPath, ".gitea/workflows"), os.ModePerm))
+	for i := 0; i < workflowsNum; i++ {
+		content := fmt.Sprintf("name: workflow-%d\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo %d\n  test:\n    needs: build\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo %d\n", i, i, i)
+		require.NoError(b, os.WriteFile(filepath.Join(repoPath, fmt.Sprintf(".gitea/workflows/workflow-%d.yml", i)), []byte(content), 0o644))
+	}
+	require.NoError(b, git.AddChanges(repoPath, true))
+	signature := &git.Signature{Name: "gitea", Email: "gitea@example.com", When: time.Now()}
+	require.NoError(b, git.CommitChanges(repoPath, git.CommitChangesOptions{Committer: signature, Message: "add workflows"}))
+	gitRepo, err := git.OpenRepository(context.Background(), repoPath)
+	require.NoError(b, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetCommit("HEAD")
+	require.NoError(b, err)
+
+	repo := unittest.AssertExistsAndLoadBean(b, &repo_model.Repository{ID: 1})
+	doer := unittest.AssertExistsAndLoadBean(b, &user_model.User{ID: 2})
+	payload := &api.PushPayload{Ref: "refs/heads/master", After: commit.ID.String()}
+	input := newNotifyInput(repo, doer, webhook_module.HookEventPush).WithRef("refs/heads/master").WithPayload(payload)
+	detectedWorkflows, _, err := actions_module.DetectWorkflows(gitRepo, commit, webhook_module.HookEventPush, payload, false)
+	require.NoError(b, err)
+	require.Len(b, detectedWorkflows, workflowsNum)
+
+	for _, parallelism := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("parallelism-%d", parallelism), func(b *testing.B) {
+			defer test.MockVariableValue(&setting.Actions.WorkflowParallelism, parallelism)()
+			for i := 0; i < b.N; i++ {
+				require.NoError(b, handleWorkflows(db.DefaultContext, detectedWorkflows, commit, "add workflows", input, "refs/heads/master"))
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	perm_model "code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/convert"
+
+	"github.com/nektos/act/pkg/jobparser"
+)
+
+func init() {
+	registerRunCompletedHook("workflow_run", notifyWorkflowRunCompleted)
+}
+
+// notifyWorkflowRunCompleted triggers the workflow_run workflows when the run is completed, see newWorkflowRunNotifyInput.
+func notifyWorkflowRunCompleted(ctx context.Context, run *actions_model.ActionRun) error {
+	input, err := newWorkflowRunNotifyInput(ctx, run)
+	if err != nil {
+		return err
+	} else if input == nil {
+		return nil
+	}
+	input.Notify(withMethod(ctx, "WorkflowRunCompleted"))
+	return nil
+}
+
+// newWorkflowRunNotifyInput returns the input which triggers the workflow_run workflows by the completed run, or nil if it triggers nothing.
+// Like GitHub, the workflow_run workflows are read from the default branch and run on its head, rather than the ref and the commit
+// of the upstream run, so the upstream runs of the pull requests from forks never get their own code run with the secrets of the repository.
+// The upstream runs which were never approved, like the ones cancelled while waiting for approval, trigger nothing.
+func newWorkflowRunNotifyInput(ctx context.Context, run *actions_model.ActionRun) (*notifyInput, error) {
+	if run.NeedApproval {
+		log.Trace("run %d has never been approved, ignore its completion", run.ID)
+		return nil, nil
+	}
+	if depth := workflowRunDepth(ctx, run); depth+1 >= actions_model.MaxWorkflowRunDepth {
+		log.Trace("run %d is the %d level of chained workflow runs, ignore its completion", run.ID, depth+1)
+		return nil, nil
+	}
+
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, fmt.Errorf("LoadAttributes: %w", err)
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	name := run.WorkflowID
+	if len(jobs) > 0 {
+		if wfs, err := jobparser.Parse(jobs[0].WorkflowPayload); err == nil && len(wfs) > 0 && wfs[0].Name != "" {
+			name = wfs[0].Name
+		}
+	}
+
+	headBranch := git.RefName(run.Ref).ShortName()
+	if payload, err := run.GetPullRequestEventPayload(); err == nil && payload.PullRequest != nil && payload.PullRequest.Head != nil {
+		headBranch = payload.PullRequest.Head.Ref
+	}
+
+	return newNotifyInput(run.Repo, run.TriggerUser, webhook_module.HookEventWorkflowRun).
+		WithRef(git.RefNameFromBranch(run.Repo.DefaultBranch).String()).
+		WithPayload(&api.WorkflowRunPayload{
+			Action: api.HookWorkflowRunCompleted,
+			WorkflowRun: &api.ActionWorkflowRun{
+				ID:         run.ID,
+				RunNumber:  run.Index,
+				Name:       name,
+				Path:       run.WorkflowID,
+				Event:      string(run.Event),
+				Status:     "completed",
+				Conclusion: run.Status.String(),
+				HeadBranch: headBranch,
+				HeadSha:    run.CommitSHA,
+				HTMLURL:    run.HTMLURL(),
+				Production: run.IsProduction,
+			},
+			Repository: convert.ToRepo(ctx, run.Repo, access_model.Permission{AccessMode: perm_model.AccessModeOwner}),
+			Sender:     convert.ToUser(ctx, run.TriggerUser, nil),
+		}), nil
+}
+
+// workflowRunDepth returns how many upstream runs have triggered the run by workflow_run
+func workflowRunDepth(ctx context.Context, run *actions_model.ActionRun) int {
+	upstreams, err := run.GetUpstreamRuns(ctx)
+	if err != nil {
+		log.Warn("GetUpstreamRuns: %v", err)
+	}
+	return len(upstreams)
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newWorkflowRunNotifyInput(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+	// the run of a pull request from a fork, whose ref and commit are controlled by the author of the fork
+	upstream := &actions_model.ActionRun{
+		ID:                1000,
+		Index:             10,
+		RepoID:            repo.ID,
+		OwnerID:           repo.OwnerID,
+		WorkflowID:        "test.yml",
+		TriggerUserID:     2,
+		Ref:               "refs/pull/3/head",
+		CommitSHA:         "985f0301dba5e7b34be866819cd15ad3d8f508ee",
+		Event:             webhook_module.HookEventPullRequest,
+		TriggerEvent:      "pull_request",
+		IsForkPullRequest: true,
+		Status:            actions_model.StatusSuccess,
+	}
+
+	t.Run("fork pull request", func(t *testing.T) {
+		input, err := newWorkflowRunNotifyInput(db.DefaultContext, upstream)
+		require.NoError(t, err)
+		require.NotNil(t, input)
+		assert.Equal(t, webhook_module.HookEventWorkflowRun, input.Event)
+		// the workflows are read from the default branch rather than the code of the fork
+		assert.Equal(t, "refs/heads/"+repo.DefaultBranch, input.Ref)
+		assert.Nil(t, input.PullRequest)
+		payload, ok := input.Payload.(*api.WorkflowRunPayload)
+		require.True(t, ok)
+		assert.Equal(t, upstream.ID, payload.WorkflowRun.ID)
+		assert.Equal(t, upstream.CommitSHA, payload.WorkflowRun.HeadSha)
+		assert.Equal(t, "success", payload.WorkflowRun.Conclusion)
+	})
+
+	t.Run("never approved", func(t *testing.T) {
+		// the run was cancelled while waiting for approval
+		upstream.NeedApproval = true
+		upstream.Status = actions_model.StatusCancelled
+		input, err := newWorkflowRunNotifyInput(db.DefaultContext, upstream)
+		require.NoError(t, err)
+		assert.Nil(t, input)
+	})
+}
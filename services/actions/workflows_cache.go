@@ -0,0 +1,56 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// workflowsCache caches the parsed workflows of commits, keyed by the commit ID and the workflow dirs.
+// A commit never changes, so the entries never get stale and are only evicted by the LRU.
+var workflowsCache *lru.Cache[string, []*actions_module.ParsedWorkflow]
+
+func initWorkflowsCache() error {
+	if setting.Actions.WorkflowsCacheSize <= 0 {
+		workflowsCache = nil
+		return nil
+	}
+	var err error
+	workflowsCache, err = lru.New[string, []*actions_module.ParsedWorkflow](setting.Actions.WorkflowsCacheSize)
+	if err != nil {
+		return fmt.Errorf("unable to allocate workflows cache: %w", err)
+	}
+	return nil
+}
+
+// workflowsCacheKey returns the key of the parsed workflows read from the dirs of the commit,
+// the dirs are a part of it since the workflow dirs of the repository could be changed, see repo_model.ActionsConfig.WorkflowDirs
+func workflowsCacheKey(commit *git.Commit, dirs []string) string {
+	return commit.ID.String() + ":" + strings.Join(dirs, ",")
+}
+
+// readWorkflows reads the parsed workflows of the commit in the dirs, from the cache if possible
+func readWorkflows(commit *git.Commit, dirs []string) ([]*actions_module.ParsedWorkflow, error) {
+	if workflowsCache == nil {
+		return actions_module.ReadWorkflows(commit, dirs...)
+	}
+
+	key := workflowsCacheKey(commit, dirs)
+	if workflows, ok := workflowsCache.Get(key); ok {
+		return workflows, nil
+	}
+	workflows, err := actions_module.ReadWorkflows(commit, dirs...)
+	if err != nil {
+		return nil, err
+	}
+	workflowsCache.Add(key, workflows)
+	return workflows, nil
+}
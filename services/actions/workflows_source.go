@@ -0,0 +1,321 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// WorkflowsSourceChangedEventType is the type of the `repository_dispatch` event sent to the repositories
+// whose workflows source has been changed, see repo_model.ActionsConfig.WorkflowsSourceNotify
+const WorkflowsSourceChangedEventType = "workflows-source-changed"
+
+// SetWorkflowsSource configures the workflows source of the repository, see repo_model.ActionsConfig.WorkflowsSource.
+// The doer must be able to read the source, and the workflows of it are only read while the doer can still read it.
+func SetWorkflowsSource(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, workflowsSource, precedence string, notify bool) error {
+	actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions)
+	if repo_model.IsErrUnitTypeNotExist(err) {
+		return util.NewInvalidArgumentErrorf("actions of %s are disabled", repo.FullName())
+	} else if err != nil {
+		return fmt.Errorf("GetUnit: %w", err)
+	}
+	cfg := actionsUnit.ActionsConfig()
+
+	ownerName, repoName, _ := (&repo_model.ActionsConfig{WorkflowsSource: workflowsSource}).GetWorkflowsSource()
+	if repoName == "" {
+		return util.NewInvalidArgumentErrorf("invalid workflows source %q", workflowsSource)
+	}
+	if precedence != repo_model.WorkflowsSourcePrecedenceLocal && precedence != repo_model.WorkflowsSourcePrecedenceSource {
+		return util.NewInvalidArgumentErrorf("invalid precedence %q", precedence)
+	}
+	source, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+	if repo_model.IsErrRepoNotExist(err) {
+		return util.NewInvalidArgumentErrorf("the workflows source %s doesn't exist or can't be read", workflowsSource)
+	} else if err != nil {
+		return fmt.Errorf("GetRepositoryByOwnerAndName: %w", err)
+	}
+	if can, err := canReadWorkflowsSource(ctx, doer.ID, source); err != nil {
+		return err
+	} else if !can {
+		return util.NewInvalidArgumentErrorf("the workflows source %s doesn't exist or can't be read", workflowsSource)
+	}
+	if source.ID == repo.ID {
+		return util.NewInvalidArgumentErrorf("the workflows source can't be the repository itself")
+	}
+	if source.IsPrivate && source.OwnerID != repo.OwnerID {
+		return util.NewInvalidArgumentErrorf("the private workflows source %s has another owner", source.FullName())
+	}
+
+	cfg.WorkflowsSource = strings.TrimSpace(workflowsSource)
+	cfg.WorkflowsSourcePrecedence = precedence
+	cfg.WorkflowsSourceNotify = notify
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := repo_model.UpdateRepoUnit(ctx, actionsUnit); err != nil {
+			return fmt.Errorf("UpdateRepoUnit: %w", err)
+		}
+		return actions_model.SetWorkflowsSource(ctx, &actions_model.ActionWorkflowsSource{
+			RepoID:       repo.ID,
+			SourceRepoID: source.ID,
+			ConfiguredBy: doer.ID,
+		})
+	})
+}
+
+// RemoveWorkflowsSource removes the workflows source of the repository
+func RemoveWorkflowsSource(ctx context.Context, repo *repo_model.Repository) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions); err == nil {
+			cfg := actionsUnit.ActionsConfig()
+			cfg.WorkflowsSource = ""
+			cfg.WorkflowsSourcePrecedence = ""
+			cfg.WorkflowsSourceNotify = false
+			if err := repo_model.UpdateRepoUnit(ctx, actionsUnit); err != nil {
+				return fmt.Errorf("UpdateRepoUnit: %w", err)
+			}
+		} else if !repo_model.IsErrUnitTypeNotExist(err) {
+			return fmt.Errorf("GetUnit: %w", err)
+		}
+		return actions_model.DeleteWorkflowsSource(ctx, repo.ID)
+	})
+}
+
+// canReadWorkflowsSource returns whether the user can read the code of the workflows source,
+// the users who have been deleted or can't sign in anymore can't read it.
+func canReadWorkflowsSource(ctx context.Context, userID int64, source *repo_model.Repository) (bool, error) {
+	user, err := user_model.GetUserByID(ctx, userID)
+	if user_model.IsErrUserNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("GetUserByID: %w", err)
+	}
+	if !user.IsActive || user.ProhibitLogin {
+		return false, nil
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, source, user)
+	if err != nil {
+		return false, fmt.Errorf("GetUserRepoPermission: %w", err)
+	}
+	return perm.CanRead(unit.TypeCode), nil
+}
+
+// checkWorkflowsSourceConfigurer returns an error if the workflows source of the repository hasn't been configured
+// by a user who can still read it, see SetWorkflowsSource.
+func checkWorkflowsSourceConfigurer(ctx context.Context, repo, source *repo_model.Repository) error {
+	configured, err := actions_model.GetWorkflowsSource(ctx, repo.ID)
+	if err != nil {
+		return fmt.Errorf("GetWorkflowsSource: %w", err)
+	}
+	if configured == nil || configured.SourceRepoID != source.ID {
+		return fmt.Errorf("the workflows source %s hasn't been configured by a user", source.FullName())
+	}
+	if can, err := canReadWorkflowsSource(ctx, configured.ConfiguredBy, source); err != nil {
+		return err
+	} else if !can {
+		return fmt.Errorf("user %d who has configured the workflows source can't read %s", configured.ConfiguredBy, source.FullName())
+	}
+	return nil
+}
+
+// readSourceWorkflows reads the workflows of the workflows source of the repository, see repo_model.ActionsConfig.WorkflowsSource.
+// It returns nil if the repository has no workflows source, and an error if the workflows source can't be read by the repository.
+func readSourceWorkflows(ctx context.Context, repo *repo_model.Repository, cfg *repo_model.ActionsConfig) ([]*actions_module.ParsedWorkflow, error) {
+	if cfg.WorkflowsSource == "" {
+		return nil, nil
+	}
+	ownerName, repoName, ref := cfg.GetWorkflowsSource()
+	if repoName == "" {
+		return nil, fmt.Errorf("invalid workflows source %q", cfg.WorkflowsSource)
+	}
+	source, err := repo_model.GetRepositoryByOwnerAndName(ctx, ownerName, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("GetRepositoryByOwnerAndName: %w", err)
+	}
+	if source.ID == repo.ID {
+		return nil, nil
+	}
+	if source.IsPrivate && source.OwnerID != repo.OwnerID {
+		return nil, fmt.Errorf("the private workflows source %s has another owner", source.FullName())
+	}
+	if err := checkWorkflowsSourceConfigurer(ctx, repo, source); err != nil {
+		return nil, err
+	}
+	if source.IsEmpty {
+		return nil, nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, source.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+
+	var refName git.RefName
+	switch {
+	case ref == "":
+		refName = git.RefNameFromBranch(source.DefaultBranch)
+	case gitRepo.IsBranchExist(ref):
+		refName = git.RefNameFromBranch(ref)
+	case gitRepo.IsTagExist(ref):
+		refName = git.RefNameFromTag(ref)
+	default:
+		refName = git.RefName(ref)
+	}
+	commit, err := gitRepo.GetCommit(refName.String())
+	if err != nil {
+		return nil, fmt.Errorf("gitRepo.GetCommit: %w", err)
+	}
+
+	// the workflows are read from the workflow dirs of the source like its own, so they share the cache
+	var dirs []string
+	if sourceUnit, err := source.GetUnit(ctx, unit.TypeActions); err == nil {
+		dirs = sourceUnit.ActionsConfig().WorkflowDirs
+	}
+	return readWorkflows(commit, dirs)
+}
+
+// mergeSourceWorkflows merges the workflows of the repository and the ones of its workflows source,
+// only the one preferred by precedence is kept for the same-named workflows.
+func mergeSourceWorkflows(local, source []*actions_module.ParsedWorkflow, precedence string) []*actions_module.ParsedWorkflow {
+	if len(source) == 0 {
+		return local
+	}
+	preferred, other := local, source
+	if precedence == repo_model.WorkflowsSourcePrecedenceSource {
+		preferred, other = source, local
+	}
+
+	merged := make([]*actions_module.ParsedWorkflow, 0, len(local)+len(source))
+	names := make(map[string]bool, len(preferred))
+	for _, wf := range preferred {
+		names[wf.EntryName] = true
+		merged = append(merged, wf)
+	}
+	for _, wf := range other {
+		if names[wf.EntryName] {
+			log.Trace("ignore workflow %q since it's overridden by the same-named one", wf.EntryName)
+			continue
+		}
+		merged = append(merged, wf)
+	}
+	return merged
+}
+
+// notifyWorkflowsSourceDependents sends a `repository_dispatch` event to the repositories which read the workflows of the repository
+// by ref and have enabled repo_model.ActionsConfig.WorkflowsSourceNotify, if the push from oldCommitID to newCommitID changes the workflows.
+// The dependents of a private repository are only notified if they have the same owner, and only the ones whose workflows source
+// has been configured by a user who can still read the repository are notified.
+func notifyWorkflowsSourceDependents(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, ref git.RefName, oldCommitID, newCommitID string) {
+	if git.IsEmptyCommitID(newCommitID) || !(ref.IsBranch() || ref.IsTag()) {
+		return
+	}
+	sources, err := actions_model.FindWorkflowsSourceDependents(ctx, repo.ID)
+	if err != nil {
+		log.Error("FindWorkflowsSourceDependents: %v", err)
+		return
+	}
+
+	var dependents []*repo_model.Repository
+	for _, s := range sources {
+		if s.RepoID == repo.ID {
+			continue
+		}
+		dependent, err := repo_model.GetRepositoryByID(ctx, s.RepoID)
+		if err != nil {
+			log.Error("GetRepositoryByID [repo: %d]: %v", s.RepoID, err)
+			continue
+		}
+		actionsUnit, err := dependent.GetUnit(ctx, unit.TypeActions)
+		if err != nil {
+			continue
+		}
+		if cfg := actionsUnit.ActionsConfig(); !cfg.WorkflowsSourceNotify || !isWorkflowsSourceRef(cfg, repo, ref) {
+			continue
+		}
+		if repo.IsPrivate && dependent.OwnerID != repo.OwnerID {
+			log.Trace("skip notifying repo %d reading the workflows of private repo %d", dependent.ID, repo.ID)
+			continue
+		}
+		if can, err := canReadWorkflowsSource(ctx, s.ConfiguredBy, repo); err != nil {
+			log.Error("canReadWorkflowsSource [repo: %d]: %v", dependent.ID, err)
+			continue
+		} else if !can {
+			log.Trace("skip notifying repo %d since user %d who has configured its workflows source can't read repo %d", dependent.ID, s.ConfiguredBy, repo.ID)
+			continue
+		}
+		dependents = append(dependents, dependent)
+	}
+	if len(dependents) == 0 {
+		return
+	}
+
+	if changed, err := isWorkflowDirsChanged(ctx, repo, oldCommitID, newCommitID); err != nil {
+		log.Error("isWorkflowDirsChanged [repo: %d, ref: %s]: %v", repo.ID, ref, err)
+		return
+	} else if !changed {
+		return
+	}
+	for _, dependent := range dependents {
+		if err := DispatchRepositoryEvent(ctx, pusher, dependent, WorkflowsSourceChangedEventType, map[string]any{
+			"repository": repo.FullName(),
+			"ref":        ref.ShortName(),
+			"sha":        newCommitID,
+		}); err != nil {
+			log.Error("DispatchRepositoryEvent [repo: %d]: %v", dependent.ID, err)
+		}
+	}
+}
+
+// isWorkflowsSourceRef returns whether the workflows source of cfg is the ref of the repository
+func isWorkflowsSourceRef(cfg *repo_model.ActionsConfig, repo *repo_model.Repository, ref git.RefName) bool {
+	ownerName, repoName, sourceRef := cfg.GetWorkflowsSource()
+	if !strings.EqualFold(ownerName, repo.OwnerName) || !strings.EqualFold(repoName, repo.Name) {
+		return false
+	}
+	if sourceRef == "" {
+		return ref.BranchName() == repo.DefaultBranch
+	}
+	return ref.ShortName() == sourceRef
+}
+
+// isWorkflowDirsChanged returns whether the push from oldCommitID to newCommitID changes the workflow files in the workflow dirs
+// of the repository, see repo_model.ActionsConfig.WorkflowDirs. An empty or zero commit id means the ref has been created or deleted.
+func isWorkflowDirsChanged(ctx context.Context, repo *repo_model.Repository, oldCommitID, newCommitID string) (bool, error) {
+	if git.IsEmptyCommitID(oldCommitID) || git.IsEmptyCommitID(newCommitID) {
+		return true, nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return false, fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+
+	oldCommit, err := gitRepo.GetCommit(oldCommitID)
+	if err != nil {
+		return false, fmt.Errorf("gitRepo.GetCommit: %w", err)
+	}
+	newCommit, err := gitRepo.GetCommit(newCommitID)
+	if err != nil {
+		return false, fmt.Errorf("gitRepo.GetCommit: %w", err)
+	}
+
+	var dirs []string
+	if actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions); err == nil {
+		dirs = actionsUnit.ActionsConfig().WorkflowDirs
+	}
+	return actions_module.IsWorkflowDirsChanged(oldCommit, newCommit, dirs...)
+}
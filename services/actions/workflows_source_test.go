@@ -0,0 +1,93 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mergeSourceWorkflows(t *testing.T) {
+	local := []*actions_module.ParsedWorkflow{
+		{EntryName: "build.yml", Dir: ".gitea/workflows"},
+		{EntryName: "local.yml", Dir: ".gitea/workflows"},
+	}
+	source := []*actions_module.ParsedWorkflow{
+		{EntryName: "build.yml", Dir: ".github/workflows"},
+		{EntryName: "lint.yml", Dir: ".github/workflows"},
+	}
+	paths := func(workflows []*actions_module.ParsedWorkflow) []string {
+		var ret []string
+		for _, wf := range workflows {
+			ret = append(ret, wf.Dir+"/"+wf.EntryName)
+		}
+		return ret
+	}
+
+	assert.Equal(t, local, mergeSourceWorkflows(local, nil, repo_model.WorkflowsSourcePrecedenceLocal))
+	assert.Equal(t, []string{".gitea/workflows/build.yml", ".gitea/workflows/local.yml", ".github/workflows/lint.yml"},
+		paths(mergeSourceWorkflows(local, source, repo_model.WorkflowsSourcePrecedenceLocal)))
+	assert.Equal(t, []string{".github/workflows/build.yml", ".github/workflows/lint.yml", ".gitea/workflows/local.yml"},
+		paths(mergeSourceWorkflows(local, source, repo_model.WorkflowsSourcePrecedenceSource)))
+}
+
+func Test_isWorkflowsSourceRef(t *testing.T) {
+	repo := &repo_model.Repository{OwnerName: "org", Name: "ci-config", DefaultBranch: "main"}
+
+	assert.True(t, isWorkflowsSourceRef(&repo_model.ActionsConfig{WorkflowsSource: "Org/CI-Config"}, repo, git.RefNameFromBranch("main")))
+	assert.False(t, isWorkflowsSourceRef(&repo_model.ActionsConfig{WorkflowsSource: "org/ci-config"}, repo, git.RefNameFromBranch("dev")))
+	assert.True(t, isWorkflowsSourceRef(&repo_model.ActionsConfig{WorkflowsSource: "org/ci-config@v1"}, repo, git.RefNameFromTag("v1")))
+	assert.False(t, isWorkflowsSourceRef(&repo_model.ActionsConfig{WorkflowsSource: "org/other@v1"}, repo, git.RefNameFromTag("v1")))
+}
+
+func TestSetWorkflowsSource(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	source := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2}) // private, of the same owner
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	other := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}) // can't read the source
+
+	assert.ErrorIs(t, SetWorkflowsSource(db.DefaultContext, repo, owner, "user2", "", false), util.ErrInvalidArgument)
+	assert.ErrorIs(t, SetWorkflowsSource(db.DefaultContext, repo, owner, "user2/repo2", "other", false), util.ErrInvalidArgument)
+	assert.ErrorIs(t, SetWorkflowsSource(db.DefaultContext, repo, owner, "user2/repo1", "", false), util.ErrInvalidArgument)
+	assert.ErrorIs(t, SetWorkflowsSource(db.DefaultContext, repo, other, "user2/repo2", "", false), util.ErrInvalidArgument)
+	unittest.AssertNotExistsBean(t, &actions_model.ActionWorkflowsSource{RepoID: repo.ID})
+
+	require.NoError(t, SetWorkflowsSource(db.DefaultContext, repo, owner, "user2/repo2", repo_model.WorkflowsSourcePrecedenceSource, true))
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionWorkflowsSource{RepoID: repo.ID, SourceRepoID: source.ID, ConfiguredBy: owner.ID})
+	repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	cfg := repo.MustGetUnit(db.DefaultContext, unit.TypeActions).ActionsConfig()
+	assert.Equal(t, "user2/repo2", cfg.WorkflowsSource)
+	assert.Equal(t, repo_model.WorkflowsSourcePrecedenceSource, cfg.WorkflowsSourcePrecedence)
+	assert.True(t, cfg.WorkflowsSourceNotify)
+	assert.NoError(t, checkWorkflowsSourceConfigurer(db.DefaultContext, repo, source))
+
+	// the configurer has lost the access to the source
+	require.NoError(t, actions_model.SetWorkflowsSource(db.DefaultContext, &actions_model.ActionWorkflowsSource{
+		RepoID: repo.ID, SourceRepoID: source.ID, ConfiguredBy: other.ID,
+	}))
+	assert.Error(t, checkWorkflowsSourceConfigurer(db.DefaultContext, repo, source))
+	_, err := readSourceWorkflows(db.DefaultContext, repo, cfg)
+	assert.Error(t, err)
+
+	require.NoError(t, RemoveWorkflowsSource(db.DefaultContext, repo))
+	unittest.AssertNotExistsBean(t, &actions_model.ActionWorkflowsSource{RepoID: repo.ID})
+	repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	assert.Empty(t, repo.MustGetUnit(db.DefaultContext, unit.TypeActions).ActionsConfig().WorkflowsSource)
+	// the source set in the config without the API isn't read
+	assert.Error(t, checkWorkflowsSourceConfigurer(db.DefaultContext, repo, source))
+}
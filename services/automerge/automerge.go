@@ -110,6 +110,28 @@ func MergeScheduledPullRequest(ctx context.Context, sha string, repo *repo_model
 	return nil
 }
 
+// MergeScheduledPullRequestsByBaseBranch re-evaluates the scheduled merges of the unmerged pull requests targeting the branch,
+// it's called when the protection rules of the branch have been changed, since the required status checks may be satisfied now.
+// Nothing is re-run, the latest commit statuses of the head commits are checked against the current rules.
+func MergeScheduledPullRequestsByBaseBranch(ctx context.Context, repo *repo_model.Repository, baseBranch string) error {
+	pulls, err := issues_model.GetUnmergedPullRequestsByBaseInfo(ctx, repo.ID, baseBranch)
+	if err != nil {
+		return err
+	}
+
+	for _, pr := range pulls {
+		exists, _, err := pull_model.GetScheduledMergeByPullID(ctx, pr.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			addToQueue(pr, pr.HeadCommitID)
+		}
+	}
+
+	return nil
+}
+
 func getPullRequestsByHeadSHA(ctx context.Context, sha string, repo *repo_model.Repository, filter func(*issues_model.PullRequest) bool) (map[int64]*issues_model.PullRequest, error) {
 	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
 	if err != nil {
@@ -0,0 +1,199 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ToWorkflowLintResult converts WorkflowDiagnostics to API format
+func ToWorkflowLintResult(diagnostics *actions_module.WorkflowDiagnostics) *api.WorkflowLintResult {
+	result := &api.WorkflowLintResult{
+		Valid:    len(diagnostics.Errors) == 0,
+		Errors:   make([]string, 0, len(diagnostics.Errors)),
+		Warnings: make([]string, 0, len(diagnostics.Warnings)),
+		Triggers: make([]*api.WorkflowLintTrigger, 0, len(diagnostics.Triggers)),
+		Jobs:     make([]*api.WorkflowLintJob, 0, len(diagnostics.Jobs)),
+	}
+	result.Errors = append(result.Errors, diagnostics.Errors...)
+	result.Warnings = append(result.Warnings, diagnostics.Warnings...)
+	for _, trigger := range diagnostics.Triggers {
+		result.Triggers = append(result.Triggers, &api.WorkflowLintTrigger{
+			Event:     trigger.Event,
+			Filters:   trigger.Acts,
+			Schedules: trigger.Schedules,
+		})
+	}
+	for _, job := range diagnostics.Jobs {
+		result.Jobs = append(result.Jobs, &api.WorkflowLintJob{
+			ID:     job.ID,
+			Name:   job.Name,
+			Needs:  job.Needs,
+			RunsOn: job.RunsOn,
+		})
+	}
+	return result
+}
+
+// ToActionRun converts ActionRun to API format, the repository of the run should be loaded
+func ToActionRun(run *actions_model.ActionRun) *api.ActionRun {
+	ret := &api.ActionRun{
+		ID:                run.ID,
+		RunNumber:         run.Index,
+		Title:             run.Title,
+		WorkflowID:        run.WorkflowID,
+		WorkflowDir:       run.WorkflowDir,
+		WorkflowBlobSHA:   run.WorkflowSHA,
+		Event:             string(run.Event),
+		TriggerEvent:      run.TriggerEvent,
+		Status:            run.Status.String(),
+		Ref:               run.Ref,
+		CommitSHA:         run.CommitSHA,
+		HeadSHA:           run.HeadSHA,
+		BaseSHA:           run.BaseSHA,
+		HTMLURL:           run.HTMLURL(),
+		Production:        run.IsProduction,
+		ProtectedTag:      run.IsProtectedTag,
+		DeliveryID:        run.DeliveryID,
+		EstimatedDuration: int64(run.EstimatedDuration.Seconds()),
+		InfraRetries:      run.InfraRetries,
+		Started:           run.Started.AsLocalTime(),
+		Stopped:           run.Stopped.AsLocalTime(),
+		Created:           run.Created.AsLocalTime(),
+		Updated:           run.Updated.AsLocalTime(),
+	}
+	ret.TriggerMatch = toActionRunTriggerMatch(run.TriggerMatch)
+	return ret
+}
+
+func toActionRunTriggerMatch(match *actions_model.RunTriggerMatch) *api.ActionRunTriggerMatch {
+	if match == nil {
+		return nil
+	}
+	return &api.ActionRunTriggerMatch{
+		Event:        match.Event,
+		ActivityType: match.ActivityType,
+		Ref:          match.Ref,
+		Path:         match.Path,
+		Schedule:     match.Schedule,
+	}
+}
+
+// ToActionRunDetectionTrace converts RunDetectionTrace to API format
+func ToActionRunDetectionTrace(trace *actions_model.RunDetectionTrace) *api.ActionRunDetectionTrace {
+	return &api.ActionRunDetectionTrace{
+		Workflow:     trace.Workflow,
+		Event:        trace.Event,
+		Match:        toActionRunTriggerMatch(trace.Match),
+		Trusted:      trace.Trusted,
+		NeedApproval: trace.NeedApproval,
+		Approval:     trace.Approval,
+		PolicyReason: trace.Policy,
+	}
+}
+
+// ToActionRunJob converts ActionRunJob to API format
+func ToActionRunJob(job *actions_model.ActionRunJob) *api.ActionRunJob {
+	return &api.ActionRunJob{
+		ID:             job.ID,
+		JobID:          job.JobID,
+		Name:           job.Name,
+		Status:         job.Status.String(),
+		RunsOn:         job.EffectiveRunsOn(),
+		Queued:         job.Queued.AsLocalTime(),
+		Started:        job.Started.AsLocalTime(),
+		Stopped:        job.Stopped.AsLocalTime(),
+		QueueDuration:  int64(job.QueueDuration().Seconds()),
+		Duration:       int64(job.Duration().Seconds()),
+		FailureCause:   job.FailureCause,
+		EnvironmentURL: job.EnvironmentURL,
+	}
+}
+
+// ToActionQueueStats converts QueueWaitStats to API format
+func ToActionQueueStats(stats *actions_model.QueueWaitStats) *api.ActionQueueStats {
+	return &api.ActionQueueStats{
+		RunsOn:      stats.RunsOn,
+		Jobs:        stats.Jobs,
+		AverageWait: int64(stats.Average.Seconds()),
+		MaxWait:     int64(stats.Max.Seconds()),
+	}
+}
+
+// ToActionSchedule converts ActionSchedule to API format, next is the earliest next time of its specs, or zero if it's unknown
+func ToActionSchedule(schedule *actions_model.ActionSchedule, next timeutil.TimeStamp) *api.ActionSchedule {
+	ret := &api.ActionSchedule{
+		ID:         schedule.ID,
+		WorkflowID: schedule.WorkflowID,
+		Specs:      schedule.Specs,
+		Ref:        schedule.Ref,
+		CommitSHA:  schedule.CommitSHA,
+		Enabled:    !schedule.Disabled,
+		Deferred:   schedule.Deferred,
+	}
+	if !next.IsZero() {
+		t := next.AsLocalTime()
+		ret.NextRun = &t
+	}
+	if schedule.Paused {
+		ret.Paused = true
+		t := schedule.PausedUnix.AsLocalTime()
+		ret.PausedAt = &t
+	}
+	if !schedule.SkippedUnix.IsZero() {
+		t := schedule.SkippedUnix.AsLocalTime()
+		ret.SkippedAt = &t
+		ret.SkipReason = schedule.SkipReason
+	}
+	return ret
+}
+
+// ToWorkflowTriggerMap converts TriggerMap to API format, commitSHA is the commit which the workflows are read from
+func ToWorkflowTriggerMap(triggerMap *actions_module.TriggerMap, commitSHA string) *api.WorkflowTriggerMap {
+	result := &api.WorkflowTriggerMap{
+		CommitSHA:         commitSHA,
+		Events:            make([]*api.WorkflowEventTriggers, 0, len(triggerMap.Events)),
+		Schedules:         make([]*api.WorkflowScheduleTrigger, 0, len(triggerMap.Schedules)),
+		DisabledWorkflows: make([]string, 0, len(triggerMap.DisabledWorkflows)),
+		Errors:            make([]*api.WorkflowTriggerError, 0, len(triggerMap.Errors)),
+	}
+	for _, event := range triggerMap.Events {
+		triggers := &api.WorkflowEventTriggers{
+			Event:     event.Event,
+			Supported: event.Supported,
+			Workflows: make([]*api.WorkflowEventWorkflow, 0, len(event.Workflows)),
+		}
+		for _, workflow := range event.Workflows {
+			triggers.Workflows = append(triggers.Workflows, &api.WorkflowEventWorkflow{
+				WorkflowID:  workflow.Workflow,
+				WorkflowDir: workflow.Dir,
+				Filters:     workflow.Filters,
+			})
+		}
+		result.Events = append(result.Events, triggers)
+	}
+	for _, schedule := range triggerMap.Schedules {
+		trigger := &api.WorkflowScheduleTrigger{
+			WorkflowID:  schedule.Workflow,
+			WorkflowDir: schedule.Dir,
+			Spec:        schedule.Spec,
+		}
+		if !schedule.Next.IsZero() {
+			trigger.NextRun = &schedule.Next
+		}
+		result.Schedules = append(result.Schedules, trigger)
+	}
+	result.DisabledWorkflows = append(result.DisabledWorkflows, triggerMap.DisabledWorkflows...)
+	for _, e := range triggerMap.Errors {
+		result.Errors = append(result.Errors, &api.WorkflowTriggerError{
+			WorkflowID:  e.Workflow,
+			WorkflowDir: e.Dir,
+			Error:       e.Error,
+		})
+	}
+	return result
+}
@@ -8,6 +8,8 @@ import (
 	"net/url"
 	"time"
 
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	ctx "code.gitea.io/gitea/modules/context"
@@ -73,6 +75,50 @@ func ToPayloadCommit(ctx context.Context, repo *repo_model.Repository, c *git.Co
 	}
 }
 
+// ToCreatePayload returns the payload of the create event of the ref.
+// The message and the tagger are included for annotated tags, and omitted for branches and lightweight tags.
+func ToCreatePayload(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) *api.CreatePayload {
+	payload := &api.CreatePayload{
+		Ref:     refFullName.ShortName(), // FIXME: should it be a full ref name?
+		Sha:     refID,
+		RefType: refFullName.RefType(),
+		Repo:    ToRepo(ctx, repo, access_model.Permission{AccessMode: perm.AccessModeNone}),
+		Sender:  ToUser(ctx, pusher, nil),
+	}
+	if !refFullName.IsTag() {
+		return payload
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", repo.RepoPath(), err)
+		return payload
+	}
+	defer closer.Close()
+	tag, err := gitRepo.GetTag(refFullName.TagName())
+	if err != nil {
+		log.Error("GetTag[%s]: %v", refFullName.TagName(), err)
+		return payload
+	}
+	if git.ObjectType(tag.Type) != git.ObjectTag || tag.Tagger == nil {
+		return payload
+	}
+
+	taggerUsername := ""
+	if tagger, err := user_model.GetUserByEmail(ctx, tag.Tagger.Email); err == nil {
+		taggerUsername = tagger.Name
+	} else if !user_model.IsErrUserNotExist(err) {
+		log.Error("GetUserByEmail: %v", err)
+	}
+	payload.Message = tag.Message
+	payload.Tagger = &api.PayloadUser{
+		Name:     tag.Tagger.Name,
+		Email:    tag.Tagger.Email,
+		UserName: taggerUsername,
+	}
+	return payload
+}
+
 type ToCommitOptions struct {
 	Stat         bool
 	Verification bool
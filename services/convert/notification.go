@@ -5,6 +5,7 @@ package convert
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 
 	activities_model "code.gitea.io/gitea/models/activities"
@@ -82,6 +83,14 @@ func ToNotificationThread(ctx context.Context, n *activities_model.Notification)
 			URL:     n.Repository.Link(),
 			HTMLURL: n.Repository.HTMLURL(),
 		}
+	case activities_model.NotificationSourceActionRun:
+		url := n.HTMLURL(ctx)
+		result.Subject = &api.NotificationSubject{
+			Type:    api.NotifySubjectActionRun,
+			Title:   fmt.Sprintf("#%d", n.RunIndex),
+			URL:     url,
+			HTMLURL: url,
+		}
 	}
 
 	return result
@@ -19,6 +19,9 @@ func initActionsTasks() {
 	registerStopEndlessTasks()
 	registerCancelAbandonedJobs()
 	registerScheduleTasks()
+	registerRejectExpiredGates()
+	registerCancelTimedOutRuns()
+	registerArchiveActionsLogs()
 }
 
 func registerStopZombieTasks() {
@@ -63,3 +66,36 @@ func registerScheduleTasks() {
 		return actions_service.StartScheduleTasks(ctx)
 	})
 }
+
+func registerRejectExpiredGates() {
+	RegisterTaskFatal("reject_expired_gates", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 5m",
+	}, func(ctx context.Context, _ *user_model.User, cfg Config) error {
+		return actions_service.RejectExpiredGates(ctx)
+	})
+}
+
+func registerCancelTimedOutRuns() {
+	RegisterTaskFatal("cancel_timed_out_runs", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 5m",
+	}, func(ctx context.Context, _ *user_model.User, cfg Config) error {
+		return actions_service.CancelTimedOutRuns(ctx)
+	})
+}
+
+func registerArchiveActionsLogs() {
+	if !setting.Actions.LogArchive {
+		return
+	}
+	RegisterTaskFatal("archive_actions_logs", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *user_model.User, cfg Config) error {
+		return actions_service.ArchiveAndPruneLogs(ctx)
+	})
+}
@@ -13,6 +13,47 @@ import (
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
+// NewLabel creates a new label of a repository or an organization
+func NewLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) error {
+	if err := issues_model.NewLabel(ctx, label); err != nil {
+		return err
+	}
+
+	notify_service.NewLabel(ctx, doer, label)
+	return nil
+}
+
+// UpdateLabel updates a label of a repository or an organization
+func UpdateLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) error {
+	if err := issues_model.UpdateLabel(ctx, label); err != nil {
+		return err
+	}
+
+	notify_service.UpdateLabel(ctx, doer, label)
+	return nil
+}
+
+// DeleteLabel deletes a label of the repository or the organization with id, it does nothing if the label doesn't belong to it
+func DeleteLabel(ctx context.Context, doer *user_model.User, id, labelID int64) error {
+	label, err := issues_model.GetLabelByID(ctx, labelID)
+	if err != nil {
+		if issues_model.IsErrLabelNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if (label.BelongsToOrg() && label.OrgID != id) || (label.BelongsToRepo() && label.RepoID != id) {
+		return nil
+	}
+
+	if err := issues_model.DeleteLabel(ctx, id, labelID); err != nil {
+		return err
+	}
+
+	notify_service.DeleteLabel(ctx, doer, label)
+	return nil
+}
+
 // ClearLabels clears all of an issue's labels
 func ClearLabels(ctx context.Context, issue *issues_model.Issue, doer *user_model.User) error {
 	if err := issues_model.ClearIssueLabels(ctx, issue, doer); err != nil {
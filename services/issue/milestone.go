@@ -13,6 +13,49 @@ import (
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
+// NewMilestone creates a new milestone of a repository
+func NewMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone) error {
+	if err := issues_model.NewMilestone(ctx, milestone); err != nil {
+		return err
+	}
+
+	notify_service.NewMilestone(ctx, doer, milestone)
+	return nil
+}
+
+// UpdateMilestone updates a milestone of a repository, oldIsClosed is whether the milestone was closed before updating
+func UpdateMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone, oldIsClosed bool) error {
+	if err := issues_model.UpdateMilestone(ctx, milestone, oldIsClosed); err != nil {
+		return err
+	}
+
+	if milestone.IsClosed != oldIsClosed {
+		notify_service.MilestoneChangeStatus(ctx, doer, milestone, milestone.IsClosed)
+	}
+	return nil
+}
+
+// ChangeMilestoneStatus closes or reopens a milestone of a repository
+func ChangeMilestoneStatus(ctx context.Context, doer *user_model.User, repoID, milestoneID int64, isClosed bool) error {
+	milestone, err := issues_model.GetMilestoneByRepoID(ctx, repoID, milestoneID)
+	if err != nil {
+		return err
+	}
+	oldIsClosed := milestone.IsClosed
+
+	if err := issues_model.ChangeMilestoneStatusByRepoIDAndID(ctx, repoID, milestoneID, isClosed); err != nil {
+		return err
+	}
+
+	if oldIsClosed != isClosed {
+		if milestone, err = issues_model.GetMilestoneByRepoID(ctx, repoID, milestoneID); err != nil {
+			return fmt.Errorf("GetMilestoneByRepoID: %w", err)
+		}
+		notify_service.MilestoneChangeStatus(ctx, doer, milestone, isClosed)
+	}
+	return nil
+}
+
 func changeMilestoneAssign(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, oldMilestoneID int64) error {
 	// Only check if milestone exists if we don't remove it.
 	if issue.MilestoneID > 0 {
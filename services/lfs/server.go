@@ -520,10 +520,7 @@ func authenticate(ctx *context.Context, repository *repo_model.Repository, autho
 			return false
 		}
 
-		if task.IsForkPullRequest {
-			return accessMode <= perm.AccessModeRead
-		}
-		return accessMode <= perm.AccessModeWrite
+		return accessMode <= task.TokenAccessMode()
 	}
 
 	// ctx.IsSigned is unnecessary here, this will be checked in perm.CanAccess
@@ -6,12 +6,15 @@ package notify
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/repository"
+	api "code.gitea.io/gitea/modules/structs"
 )
 
 // Notifier defines an interface to notify receiver
@@ -26,6 +29,8 @@ type Notifier interface {
 	RenameRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldRepoName string)
 	TransferRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldOwnerName string)
 	RepoPendingTransfer(ctx context.Context, doer, newOwner *user_model.User, repo *repo_model.Repository)
+	StarRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository)
+	PageBuild(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, buildID int64, build *api.PageBuild)
 
 	NewIssue(ctx context.Context, issue *issues_model.Issue, mentions []*user_model.User)
 	IssueChangeStatus(ctx context.Context, doer *user_model.User, commitID string, issue *issues_model.Issue, actionComment *issues_model.Comment, closeOrReopen bool)
@@ -40,6 +45,12 @@ type Notifier interface {
 	IssueChangeLabels(ctx context.Context, doer *user_model.User, issue *issues_model.Issue,
 		addedLabels, removedLabels []*issues_model.Label)
 
+	NewLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label)
+	UpdateLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label)
+	DeleteLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label)
+	NewMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone)
+	MilestoneChangeStatus(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone, isClosed bool)
+
 	NewPullRequest(ctx context.Context, pr *issues_model.PullRequest, mentions []*user_model.User)
 	MergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest)
 	AutoMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest)
@@ -49,6 +60,7 @@ type Notifier interface {
 	PullRequestChangeTargetBranch(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, oldBranch string)
 	PullRequestPushCommits(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, comment *issues_model.Comment)
 	PullReviewDismiss(ctx context.Context, doer *user_model.User, review *issues_model.Review, comment *issues_model.Comment)
+	MergeGroupChecksRequested(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, mergeGroupRef git.RefName, headSHA, baseSHA string)
 
 	CreateIssueComment(ctx context.Context, doer *user_model.User, repo *repo_model.Repository,
 		issue *issues_model.Issue, comment *issues_model.Comment, mentions []*user_model.User)
@@ -73,5 +85,13 @@ type Notifier interface {
 	PackageCreate(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor)
 	PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor)
 
+	OrgMemberAdded(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User)
+	OrgMemberRemoved(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User)
+	TeamMemberAdded(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User)
+	TeamMemberRemoved(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User)
+
+	ActionsSchedulesRegistered(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule)
+	ActionsSchedulesCleaned(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule)
+
 	ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository)
 }
@@ -6,13 +6,16 @@ package notify
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repository"
+	api "code.gitea.io/gitea/modules/structs"
 )
 
 var notifiers []Notifier
@@ -152,6 +155,13 @@ func PullReviewDismiss(ctx context.Context, doer *user_model.User, review *issue
 	}
 }
 
+// MergeGroupChecksRequested notifies that a merge group has been created for a queued pull request and needs to be checked
+func MergeGroupChecksRequested(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, mergeGroupRef git.RefName, headSHA, baseSHA string) {
+	for _, notifier := range notifiers {
+		notifier.MergeGroupChecksRequested(ctx, doer, pr, mergeGroupRef, headSHA, baseSHA)
+	}
+}
+
 // UpdateComment notifies update comment to notifiers
 func UpdateComment(ctx context.Context, doer *user_model.User, c *issues_model.Comment, oldContent string) {
 	for _, notifier := range notifiers {
@@ -249,6 +259,41 @@ func IssueChangeLabels(ctx context.Context, doer *user_model.User, issue *issues
 	}
 }
 
+// NewLabel notifies a label of a repository or an organization is created
+func NewLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+	for _, notifier := range notifiers {
+		notifier.NewLabel(ctx, doer, label)
+	}
+}
+
+// UpdateLabel notifies a label of a repository or an organization is edited
+func UpdateLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+	for _, notifier := range notifiers {
+		notifier.UpdateLabel(ctx, doer, label)
+	}
+}
+
+// DeleteLabel notifies a label of a repository or an organization is deleted
+func DeleteLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+	for _, notifier := range notifiers {
+		notifier.DeleteLabel(ctx, doer, label)
+	}
+}
+
+// NewMilestone notifies a milestone is created
+func NewMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone) {
+	for _, notifier := range notifiers {
+		notifier.NewMilestone(ctx, doer, milestone)
+	}
+}
+
+// MilestoneChangeStatus notifies a milestone is closed or reopened
+func MilestoneChangeStatus(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone, isClosed bool) {
+	for _, notifier := range notifiers {
+		notifier.MilestoneChangeStatus(ctx, doer, milestone, isClosed)
+	}
+}
+
 // CreateRepository notifies create repository to notifiers
 func CreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
 	for _, notifier := range notifiers {
@@ -291,6 +336,20 @@ func ForkRepository(ctx context.Context, doer *user_model.User, oldRepo, repo *r
 	}
 }
 
+// StarRepository notifies a repository is starred by the doer
+func StarRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	for _, notifier := range notifiers {
+		notifier.StarRepository(ctx, doer, repo)
+	}
+}
+
+// PageBuild notifies the pages of a repository have been built, or failed to build
+func PageBuild(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, buildID int64, build *api.PageBuild) {
+	for _, notifier := range notifiers {
+		notifier.PageBuild(ctx, doer, repo, buildID, build)
+	}
+}
+
 // RenameRepository notifies repository renamed
 func RenameRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldName string) {
 	for _, notifier := range notifiers {
@@ -361,6 +420,49 @@ func PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_mode
 	}
 }
 
+// OrgMemberAdded notifies a user has become a member of an organization
+func OrgMemberAdded(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User) {
+	for _, notifier := range notifiers {
+		notifier.OrgMemberAdded(ctx, doer, org, member)
+	}
+}
+
+// OrgMemberRemoved notifies a user is no longer a member of an organization
+func OrgMemberRemoved(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User) {
+	for _, notifier := range notifiers {
+		notifier.OrgMemberRemoved(ctx, doer, org, member)
+	}
+}
+
+// TeamMemberAdded notifies a user is added to a team of an organization
+func TeamMemberAdded(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User) {
+	for _, notifier := range notifiers {
+		notifier.TeamMemberAdded(ctx, doer, team, member)
+	}
+}
+
+// TeamMemberRemoved notifies a user is removed from a team of an organization
+func TeamMemberRemoved(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User) {
+	for _, notifier := range notifiers {
+		notifier.TeamMemberRemoved(ctx, doer, team, member)
+	}
+}
+
+// ActionsSchedulesRegistered notifies the registration of the schedules of a repository to notifiers,
+// the schedules are all schedules of the repository which replace the previous ones
+func ActionsSchedulesRegistered(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+	for _, notifier := range notifiers {
+		notifier.ActionsSchedulesRegistered(ctx, doer, repo, schedules)
+	}
+}
+
+// ActionsSchedulesCleaned notifies the cleanup of the schedules of a repository to notifiers
+func ActionsSchedulesCleaned(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+	for _, notifier := range notifiers {
+		notifier.ActionsSchedulesCleaned(ctx, doer, repo, schedules)
+	}
+}
+
 // ChangeDefaultBranch notifies change default branch to notifiers
 func ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
 	for _, notifier := range notifiers {
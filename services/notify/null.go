@@ -6,12 +6,15 @@ package notify
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/repository"
+	api "code.gitea.io/gitea/modules/structs"
 )
 
 // NullNotifier implements a blank notifier
@@ -76,6 +79,10 @@ func (*NullNotifier) PullRequestPushCommits(ctx context.Context, doer *user_mode
 func (*NullNotifier) PullReviewDismiss(ctx context.Context, doer *user_model.User, review *issues_model.Review, comment *issues_model.Comment) {
 }
 
+// MergeGroupChecksRequested places a place holder function
+func (*NullNotifier) MergeGroupChecksRequested(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, mergeGroupRef git.RefName, headSHA, baseSHA string) {
+}
+
 // UpdateComment places a place holder function
 func (*NullNotifier) UpdateComment(ctx context.Context, doer *user_model.User, c *issues_model.Comment, oldContent string) {
 }
@@ -141,6 +148,26 @@ func (*NullNotifier) IssueChangeLabels(ctx context.Context, doer *user_model.Use
 	addedLabels, removedLabels []*issues_model.Label) {
 }
 
+// NewLabel places a place holder function
+func (*NullNotifier) NewLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+}
+
+// UpdateLabel places a place holder function
+func (*NullNotifier) UpdateLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+}
+
+// DeleteLabel places a place holder function
+func (*NullNotifier) DeleteLabel(ctx context.Context, doer *user_model.User, label *issues_model.Label) {
+}
+
+// NewMilestone places a place holder function
+func (*NullNotifier) NewMilestone(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone) {
+}
+
+// MilestoneChangeStatus places a place holder function
+func (*NullNotifier) MilestoneChangeStatus(ctx context.Context, doer *user_model.User, milestone *issues_model.Milestone, isClosed bool) {
+}
+
 // CreateRepository places a place holder function
 func (*NullNotifier) CreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
 }
@@ -173,6 +200,14 @@ func (*NullNotifier) CreateRef(ctx context.Context, doer *user_model.User, repo
 func (*NullNotifier) DeleteRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refFullName git.RefName) {
 }
 
+// StarRepository places a place holder function
+func (*NullNotifier) StarRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+}
+
+// PageBuild places a place holder function
+func (*NullNotifier) PageBuild(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, buildID int64, build *api.PageBuild) {
+}
+
 // RenameRepository places a place holder function
 func (*NullNotifier) RenameRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldRepoName string) {
 }
@@ -205,6 +240,30 @@ func (*NullNotifier) PackageCreate(ctx context.Context, doer *user_model.User, p
 func (*NullNotifier) PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor) {
 }
 
+// OrgMemberAdded places a place holder function
+func (*NullNotifier) OrgMemberAdded(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User) {
+}
+
+// OrgMemberRemoved places a place holder function
+func (*NullNotifier) OrgMemberRemoved(ctx context.Context, doer *user_model.User, org *organization.Organization, member *user_model.User) {
+}
+
+// TeamMemberAdded places a place holder function
+func (*NullNotifier) TeamMemberAdded(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User) {
+}
+
+// TeamMemberRemoved places a place holder function
+func (*NullNotifier) TeamMemberRemoved(ctx context.Context, doer *user_model.User, team *organization.Team, member *user_model.User) {
+}
+
+// ActionsSchedulesRegistered places a place holder function
+func (*NullNotifier) ActionsSchedulesRegistered(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+}
+
+// ActionsSchedulesCleaned places a place holder function
+func (*NullNotifier) ActionsSchedulesCleaned(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+}
+
 // ChangeDefaultBranch places a place holder function
 func (*NullNotifier) ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
 }
@@ -0,0 +1,87 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	org_model "code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	notify_service "code.gitea.io/gitea/services/notify"
+)
+
+// AddTeamMember adds the user to the team, and to the organization of the team if the user isn't a member of it yet
+func AddTeamMember(ctx context.Context, doer *user_model.User, team *org_model.Team, user *user_model.User) error {
+	isOrgMember, err := org_model.IsOrganizationMember(ctx, team.OrgID, user.ID)
+	if err != nil {
+		return err
+	}
+	isTeamMember, err := org_model.IsTeamMember(ctx, team.OrgID, team.ID, user.ID)
+	if err != nil || isTeamMember {
+		return err
+	}
+
+	if err := models.AddTeamMember(ctx, team, user.ID); err != nil {
+		return err
+	}
+
+	if !isOrgMember {
+		org, err := org_model.GetOrgByID(ctx, team.OrgID)
+		if err != nil {
+			return err
+		}
+		notify_service.OrgMemberAdded(ctx, doer, org, user)
+	}
+	notify_service.TeamMemberAdded(ctx, doer, team, user)
+	return nil
+}
+
+// RemoveTeamMember removes the user from the team, and from the organization of the team if it's the last team of the user
+func RemoveTeamMember(ctx context.Context, doer *user_model.User, team *org_model.Team, user *user_model.User) error {
+	isTeamMember, err := org_model.IsTeamMember(ctx, team.OrgID, team.ID, user.ID)
+	if err != nil || !isTeamMember {
+		return err
+	}
+
+	if err := models.RemoveTeamMember(ctx, team, user.ID); err != nil {
+		return err
+	}
+
+	notify_service.TeamMemberRemoved(ctx, doer, team, user)
+	isOrgMember, err := org_model.IsOrganizationMember(ctx, team.OrgID, user.ID)
+	if err != nil {
+		return err
+	}
+	if !isOrgMember {
+		org, err := org_model.GetOrgByID(ctx, team.OrgID)
+		if err != nil {
+			return err
+		}
+		notify_service.OrgMemberRemoved(ctx, doer, org, user)
+	}
+	return nil
+}
+
+// RemoveOrgUser removes the user from the organization and all its teams
+func RemoveOrgUser(ctx context.Context, doer *user_model.User, org *org_model.Organization, user *user_model.User) error {
+	isOrgMember, err := org_model.IsOrganizationMember(ctx, org.ID, user.ID)
+	if err != nil || !isOrgMember {
+		return err
+	}
+	teams, err := org_model.GetUserOrgTeams(ctx, org.ID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := models.RemoveOrgUser(ctx, org.ID, user.ID); err != nil {
+		return err
+	}
+
+	for _, team := range teams {
+		notify_service.TeamMemberRemoved(ctx, doer, team, user)
+	}
+	notify_service.OrgMemberRemoved(ctx, doer, org, user)
+	return nil
+}
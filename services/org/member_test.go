@@ -0,0 +1,79 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	notify_service "code.gitea.io/gitea/services/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type membershipNotifier struct {
+	notify_service.NullNotifier
+	events []string
+}
+
+func (n *membershipNotifier) OrgMemberAdded(_ context.Context, _ *user_model.User, org *organization.Organization, member *user_model.User) {
+	n.events = append(n.events, fmt.Sprintf("%s joined %s", member.Name, org.Name))
+}
+
+func (n *membershipNotifier) OrgMemberRemoved(_ context.Context, _ *user_model.User, org *organization.Organization, member *user_model.User) {
+	n.events = append(n.events, fmt.Sprintf("%s left %s", member.Name, org.Name))
+}
+
+func (n *membershipNotifier) TeamMemberAdded(_ context.Context, _ *user_model.User, team *organization.Team, member *user_model.User) {
+	n.events = append(n.events, fmt.Sprintf("%s joined team %s", member.Name, team.Name))
+}
+
+func (n *membershipNotifier) TeamMemberRemoved(_ context.Context, _ *user_model.User, team *organization.Team, member *user_model.User) {
+	n.events = append(n.events, fmt.Sprintf("%s left team %s", member.Name, team.Name))
+}
+
+func TestMembershipNotifications(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	notifier := &membershipNotifier{}
+	notify_service.RegisterNotifier(notifier)
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+	org := unittest.AssertExistsAndLoadBean(t, &organization.Organization{ID: 3})
+	team1 := unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: 2})
+	team2 := unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: 7})
+
+	assertEvents := func(t *testing.T, expected ...string) {
+		t.Helper()
+		assert.Equal(t, expected, notifier.events)
+		notifier.events = nil
+	}
+
+	require.NoError(t, AddTeamMember(db.DefaultContext, doer, team1, user))
+	assertEvents(t, "user5 joined org3", "user5 joined team team1")
+	require.NoError(t, AddTeamMember(db.DefaultContext, doer, team1, user))
+	assertEvents(t)
+	require.NoError(t, AddTeamMember(db.DefaultContext, doer, team2, user))
+	assertEvents(t, "user5 joined team test_team")
+
+	require.NoError(t, RemoveTeamMember(db.DefaultContext, doer, team1, user))
+	assertEvents(t, "user5 left team team1")
+	require.NoError(t, RemoveTeamMember(db.DefaultContext, doer, team2, user))
+	assertEvents(t, "user5 left team test_team", "user5 left org3")
+	unittest.AssertNotExistsBean(t, &organization.OrgUser{OrgID: org.ID, UID: user.ID})
+
+	require.NoError(t, AddTeamMember(db.DefaultContext, doer, team1, user))
+	require.NoError(t, AddTeamMember(db.DefaultContext, doer, team2, user))
+	notifier.events = nil
+	require.NoError(t, RemoveOrgUser(db.DefaultContext, doer, org, user))
+	assertEvents(t, "user5 left team team1", "user5 left team test_team", "user5 left org3")
+	require.NoError(t, RemoveOrgUser(db.DefaultContext, doer, org, user))
+	assertEvents(t)
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// MergeRequiredContextsCommitStatus returns a commit status state for given required contexts
+// MergeRequiredContextsCommitStatus returns a commit status state for given required contexts.
+// A required context without any matched commit status, like a newly required check which hasn't been run for the commit yet,
+// makes the state pending at best.
 func MergeRequiredContextsCommitStatus(commitStatuses []*git_model.CommitStatus, requiredContexts []string) structs.CommitStatusState {
 	// matchedCount is the number of `CommitStatus.Context` that match any context of `requiredContexts`
 	matchedCount := 0
@@ -34,13 +36,18 @@ func MergeRequiredContextsCommitStatus(commitStatuses []*git_model.CommitStatus,
 			}
 		}
 
+		matchedContexts := make(map[string]bool, len(requiredContextsGlob))
 		for _, commitStatus := range commitStatuses {
 			var targetStatus structs.CommitStatusState
-			for _, gp := range requiredContextsGlob {
-				if gp.Match(commitStatus.Context) {
+			for ctx, gp := range requiredContextsGlob {
+				if !gp.Match(commitStatus.Context) {
+					continue
+				}
+				// a commit status may satisfy more than one required context
+				matchedContexts[ctx] = true
+				if targetStatus == "" {
 					targetStatus = commitStatus.State
 					matchedCount++
-					break
 				}
 			}
 
@@ -48,6 +55,10 @@ func MergeRequiredContextsCommitStatus(commitStatuses []*git_model.CommitStatus,
 				returnedStatus = targetStatus
 			}
 		}
+
+		if len(matchedContexts) < len(requiredContextsGlob) && returnedStatus.IsSuccess() {
+			return structs.CommitStatusPending
+		}
 	}
 
 	if matchedCount == 0 {
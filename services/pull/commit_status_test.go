@@ -0,0 +1,67 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pull
+
+import (
+	"testing"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRequiredContextsCommitStatus(t *testing.T) {
+	commitStatuses := []*git_model.CommitStatus{
+		{Context: "Build / build (push)", State: structs.CommitStatusSuccess},
+		{Context: "Test / test (push)", State: structs.CommitStatusSuccess},
+		{Context: "Lint / lint (push)", State: structs.CommitStatusFailure},
+	}
+
+	cases := []struct {
+		name             string
+		requiredContexts []string
+		expected         structs.CommitStatusState
+	}{
+		{
+			name:     "no required contexts",
+			expected: structs.CommitStatusFailure,
+		},
+		{
+			name:             "required contexts succeed",
+			requiredContexts: []string{"Build / build (push)", "Test / *"},
+			expected:         structs.CommitStatusSuccess,
+		},
+		{
+			name:             "required context fails",
+			requiredContexts: []string{"Build / build (push)", "Lint / *"},
+			expected:         structs.CommitStatusFailure,
+		},
+		{
+			name:             "overlapping required contexts",
+			requiredContexts: []string{"Build / *", "Build / build (push)"},
+			expected:         structs.CommitStatusSuccess,
+		},
+		{
+			name:             "newly required context has no status yet",
+			requiredContexts: []string{"Build / build (push)", "Deploy / *"},
+			expected:         structs.CommitStatusPending,
+		},
+		{
+			name:             "only newly required contexts",
+			requiredContexts: []string{"Deploy / *"},
+			expected:         structs.CommitStatusPending,
+		},
+		{
+			name:             "failure is worse than a missing context",
+			requiredContexts: []string{"Lint / *", "Deploy / *"},
+			expected:         structs.CommitStatusFailure,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, MergeRequiredContextsCommitStatus(commitStatuses, c.requiredContexts))
+		})
+	}
+}
@@ -158,9 +158,14 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 		&actions_model.ActionTask{RepoID: repoID},
 		&actions_model.ActionRunJob{RepoID: repoID},
 		&actions_model.ActionRun{RepoID: repoID},
+		&actions_model.ActionRunContext{RepoID: repoID},
+		&actions_model.ActionLabelApproval{RepoID: repoID},
+		&actions_model.ActionWorkflowsSource{RepoID: repoID},
+		&actions_model.ActionWorkflowsSource{SourceRepoID: repoID},
 		&actions_model.ActionRunner{RepoID: repoID},
 		&actions_model.ActionScheduleSpec{RepoID: repoID},
 		&actions_model.ActionSchedule{RepoID: repoID},
+		&actions_model.ActionWorkflowDependency{RepoID: repoID},
 		&actions_model.ActionArtifact{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %w", err)
@@ -327,10 +332,18 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 
 	// Finally, delete action logs after the actions have already been deleted to avoid new log files
 	for _, task := range tasks {
-		err := actions_module.RemoveLogs(ctx, task.LogInStorage, task.LogFilename)
-		if err != nil {
-			log.Error("remove log file %q: %v", task.LogFilename, err)
-			// go on
+		if !task.LogPruned {
+			err := actions_module.RemoveLogs(ctx, task.LogInStorage, task.LogFilename)
+			if err != nil {
+				log.Error("remove log file %q: %v", task.LogFilename, err)
+				// go on
+			}
+		}
+		if task.LogArchivePath != "" {
+			if err := actions_module.RemoveArchivedLogs(task.LogArchivePath); err != nil {
+				log.Error("remove archived log file %q: %v", task.LogArchivePath, err)
+				// go on
+			}
 		}
 	}
 
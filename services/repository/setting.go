@@ -11,7 +11,9 @@ import (
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/log"
+	notify_service "code.gitea.io/gitea/services/notify"
 )
 
 // UpdateRepositoryUnits updates a repository's units
@@ -27,8 +29,9 @@ func UpdateRepositoryUnits(ctx context.Context, repo *repo_model.Repository, uni
 		deleteUnitTypes = append(deleteUnitTypes, u.Type)
 	}
 
+	var cleaned []*actions_model.ActionSchedule
 	if slices.Contains(deleteUnitTypes, unit.TypeActions) {
-		if err := actions_model.CleanRepoScheduleTasks(ctx, repo); err != nil {
+		if cleaned, err = actions_model.CleanRepoScheduleTasks(ctx, repo); err != nil {
 			log.Error("CleanRepoScheduleTasks: %v", err)
 		}
 	}
@@ -43,5 +46,11 @@ func UpdateRepositoryUnits(ctx context.Context, repo *repo_model.Repository, uni
 		}
 	}
 
-	return committer.Commit()
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+	if len(cleaned) > 0 {
+		notify_service.ActionsSchedulesCleaned(ctx, user_model.NewActionsUser(), repo, cleaned)
+	}
+	return nil
 }
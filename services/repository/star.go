@@ -0,0 +1,24 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"context"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	notify_service "code.gitea.io/gitea/services/notify"
+)
+
+// StarRepo stars or unstars the repository for the doer, it notifies only when the repository is newly starred
+func StarRepo(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, star bool) error {
+	staring := repo_model.IsStaring(ctx, doer.ID, repo.ID)
+	if err := repo_model.StarRepo(ctx, doer.ID, repo.ID, star); err != nil {
+		return err
+	}
+	if star && !staring {
+		notify_service.StarRepository(ctx, doer, repo)
+	}
+	return nil
+}
@@ -11,21 +11,35 @@ import (
 )
 
 func CreateOrUpdateSecret(ctx context.Context, ownerID, repoID int64, name, data string) (*secret_model.Secret, bool, error) {
+	return createOrUpdateSecret(ctx, ownerID, repoID, "", name, data)
+}
+
+// CreateOrUpdateEnvironmentSecret creates or updates the secret of the repository scoped to the environment,
+// it's only available to the jobs whose `environment` is the environment.
+func CreateOrUpdateEnvironmentSecret(ctx context.Context, ownerID, repoID int64, environment, name, data string) (*secret_model.Secret, bool, error) {
+	if err := ValidateEnvironment(environment); err != nil {
+		return nil, false, err
+	}
+	return createOrUpdateSecret(ctx, ownerID, repoID, environment, name, data)
+}
+
+func createOrUpdateSecret(ctx context.Context, ownerID, repoID int64, environment, name, data string) (*secret_model.Secret, bool, error) {
 	if err := ValidateName(name); err != nil {
 		return nil, false, err
 	}
 
 	s, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{
-		OwnerID: ownerID,
-		RepoID:  repoID,
-		Name:    name,
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Name:        name,
+		Environment: environment,
 	})
 	if err != nil {
 		return nil, false, err
 	}
 
 	if len(s) == 0 {
-		s, err := secret_model.InsertEncryptedSecret(ctx, ownerID, repoID, name, data)
+		s, err := secret_model.InsertEncryptedSecret(ctx, ownerID, repoID, environment, name, data)
 		if err != nil {
 			return nil, false, err
 		}
@@ -56,14 +70,27 @@ func DeleteSecretByID(ctx context.Context, ownerID, repoID, secretID int64) erro
 }
 
 func DeleteSecretByName(ctx context.Context, ownerID, repoID int64, name string) error {
+	return deleteSecretByName(ctx, ownerID, repoID, "", name)
+}
+
+// DeleteEnvironmentSecretByName deletes the secret of the repository scoped to the environment
+func DeleteEnvironmentSecretByName(ctx context.Context, ownerID, repoID int64, environment, name string) error {
+	if err := ValidateEnvironment(environment); err != nil {
+		return err
+	}
+	return deleteSecretByName(ctx, ownerID, repoID, environment, name)
+}
+
+func deleteSecretByName(ctx context.Context, ownerID, repoID int64, environment, name string) error {
 	if err := ValidateName(name); err != nil {
 		return err
 	}
 
 	s, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{
-		OwnerID: ownerID,
-		RepoID:  repoID,
-		Name:    name,
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Name:        name,
+		Environment: environment,
 	})
 	if err != nil {
 		return err
@@ -5,6 +5,7 @@ package secrets
 
 import (
 	"regexp"
+	"strings"
 
 	"code.gitea.io/gitea/modules/util"
 )
@@ -14,7 +15,8 @@ var (
 	namePattern            = regexp.MustCompile("(?i)^[A-Z_][A-Z0-9_]*$")
 	forbiddenPrefixPattern = regexp.MustCompile("(?i)^GIT(EA|HUB)_")
 
-	ErrInvalidName = util.NewInvalidArgumentErrorf("invalid secret name")
+	ErrInvalidName        = util.NewInvalidArgumentErrorf("invalid secret name")
+	ErrInvalidEnvironment = util.NewInvalidArgumentErrorf("invalid environment name")
 )
 
 func ValidateName(name string) error {
@@ -23,3 +25,13 @@ func ValidateName(name string) error {
 	}
 	return nil
 }
+
+// ValidateEnvironment checks the name of the environment which the secrets are scoped to,
+// it can't be an expression since the environments of jobs are resolved before any context is available.
+func ValidateEnvironment(environment string) error {
+	environment = strings.TrimSpace(environment)
+	if environment == "" || len(environment) > 255 || strings.Contains(environment, "${{") {
+		return ErrInvalidEnvironment
+	}
+	return nil
+}
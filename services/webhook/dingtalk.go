@@ -179,6 +179,13 @@ func (d *DingtalkPayload) Package(p *api.PackagePayload) (api.Payloader, error)
 	return createDingtalkPayload(text, text, "view package", p.Package.HTMLURL), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (d *DingtalkPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, _ := getActionsSchedulePayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view repository", p.Repository.HTMLURL), nil
+}
+
 func createDingtalkPayload(title, text, singleTitle, singleURL string) *DingtalkPayload {
 	return &DingtalkPayload{
 		MsgType: "actionCard",
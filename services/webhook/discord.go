@@ -262,6 +262,13 @@ func (d *DiscordPayload) Package(p *api.PackagePayload) (api.Payloader, error) {
 	return d.createPayload(p.Sender, text, "", p.Package.HTMLURL, color), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (d *DiscordPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, color := getActionsSchedulePayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", p.Repository.HTMLURL, color), nil
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event webhook_module.HookEventType, meta string) (api.Payloader, error) {
 	s := new(DiscordPayload)
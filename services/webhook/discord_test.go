@@ -229,6 +229,21 @@ func TestDiscordPayload(t *testing.T) {
 		assert.Equal(t, p.Sender.AvatarURL, pl.(*DiscordPayload).Embeds[0].Author.IconURL)
 	})
 
+	t.Run("ActionsSchedule", func(t *testing.T) {
+		p := actionsScheduleTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.ActionsSchedule(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[test/repo] Actions schedules registered: nightly.yaml (0 2 * * *); weekly.yaml (0 3 * * 1, 0 3 * * 4)", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "http://localhost:3000/test/repo", pl.(*DiscordPayload).Embeds[0].URL)
+		assert.Equal(t, greenColor, pl.(*DiscordPayload).Embeds[0].Color)
+	})
+
 	t.Run("Wiki", func(t *testing.T) {
 		p := wikiTestPayload()
 
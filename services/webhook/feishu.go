@@ -181,6 +181,13 @@ func (f *FeishuPayload) Package(p *api.PackagePayload) (api.Payloader, error) {
 	return newFeishuTextPayload(text), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (f *FeishuPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, _ := getActionsSchedulePayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
 // GetFeishuPayload converts a ding talk webhook into a FeishuPayload
 func GetFeishuPayload(p api.Payloader, event webhook_module.HookEventType, _ string) (api.Payloader, error) {
 	return convertPayloader(new(FeishuPayload), p, event)
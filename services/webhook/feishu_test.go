@@ -156,6 +156,19 @@ func TestFeishuPayload(t *testing.T) {
 		assert.Equal(t, "Package created: GiteaContainer:latest by user1", pl.(*FeishuPayload).Content.Text)
 	})
 
+	t.Run("ActionsSchedule", func(t *testing.T) {
+		p := actionsScheduleTestPayload()
+		p.Action = api.HookActionsScheduleCleaned
+
+		d := new(FeishuPayload)
+		pl, err := d.ActionsSchedule(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &FeishuPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] Actions schedules cleaned: nightly.yaml (0 2 * * *); weekly.yaml (0 3 * * 1, 0 3 * * 4) by user1", pl.(*FeishuPayload).Content.Text)
+	})
+
 	t.Run("Wiki", func(t *testing.T) {
 		p := wikiTestPayload()
 
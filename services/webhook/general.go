@@ -311,6 +311,28 @@ func getPackagePayloadInfo(p *api.PackagePayload, linkFormatter linkFormatter, w
 	return text, color
 }
 
+func getActionsSchedulePayloadInfo(p *api.ActionsSchedulePayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	workflows := make([]string, 0, len(p.Schedules))
+	for _, schedule := range p.Schedules {
+		workflows = append(workflows, fmt.Sprintf("%s (%s)", schedule.Workflow, strings.Join(schedule.Specs, ", ")))
+	}
+
+	switch p.Action {
+	case api.HookActionsScheduleRegistered:
+		text = fmt.Sprintf("[%s] Actions schedules registered: %s", repoLink, strings.Join(workflows, "; "))
+		color = greenColor
+	case api.HookActionsScheduleCleaned:
+		text = fmt.Sprintf("[%s] Actions schedules cleaned: %s", repoLink, strings.Join(workflows, "; "))
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+url.PathEscape(p.Sender.UserName), p.Sender.UserName))
+	}
+
+	return text, color
+}
+
 // ToHook convert models.Webhook to api.Hook
 // This function is not part of the convert package to prevent an import cycle
 func ToHook(repoLink string, w *webhook_model.Webhook) (*api.Hook, error) {
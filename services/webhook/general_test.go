@@ -333,6 +333,25 @@ func packageTestPayload() *api.PackagePayload {
 	}
 }
 
+func actionsScheduleTestPayload() *api.ActionsSchedulePayload {
+	return &api.ActionsSchedulePayload{
+		Action: api.HookActionsScheduleRegistered,
+		Schedules: []*api.ActionsSchedule{
+			{Workflow: "nightly.yaml", Dir: ".gitea/workflows", Specs: []string{"0 2 * * *"}},
+			{Workflow: "weekly.yaml", Dir: ".gitea/workflows", Specs: []string{"0 3 * * 1", "0 3 * * 4"}},
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+	}
+}
+
 func TestGetIssuesPayloadInfo(t *testing.T) {
 	p := issueTestPayload()
 
@@ -225,6 +225,13 @@ func (m *MatrixPayload) Package(p *api.PackagePayload) (api.Payloader, error) {
 	return getMatrixPayload(text, nil, m.MsgType), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (m *MatrixPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, _ := getActionsSchedulePayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayload(text, nil, m.MsgType), nil
+}
+
 // GetMatrixPayload converts a Matrix webhook into a MatrixPayload
 func GetMatrixPayload(p api.Payloader, event webhook_module.HookEventType, meta string) (api.Payloader, error) {
 	s := new(MatrixPayload)
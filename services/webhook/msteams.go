@@ -310,6 +310,21 @@ func (m *MSTeamsPayload) Package(p *api.PackagePayload) (api.Payloader, error) {
 	), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (m *MSTeamsPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	title, color := getActionsSchedulePayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		"",
+		p.Repository.HTMLURL,
+		color,
+		&MSTeamsFact{"Schedules:", fmt.Sprint(len(p.Schedules))},
+	), nil
+}
+
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
 func GetMSTeamsPayload(p api.Payloader, event webhook_module.HookEventType, _ string) (api.Payloader, error) {
 	return convertPayloader(new(MSTeamsPayload), p, event)
@@ -6,6 +6,7 @@ package webhook
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	issues_model "code.gitea.io/gitea/models/issues"
 	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/models/perm"
@@ -746,17 +747,7 @@ func (m *webhookNotifier) PullRequestReviewRequest(ctx context.Context, doer *us
 }
 
 func (m *webhookNotifier) CreateRef(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) {
-	apiPusher := convert.ToUser(ctx, pusher, nil)
-	apiRepo := convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm.AccessModeNone})
-	refName := refFullName.ShortName()
-
-	if err := PrepareWebhooks(ctx, EventSource{Repository: repo}, webhook_module.HookEventCreate, &api.CreatePayload{
-		Ref:     refName, // FIXME: should it be a full ref name?
-		Sha:     refID,
-		RefType: refFullName.RefType(),
-		Repo:    apiRepo,
-		Sender:  apiPusher,
-	}); err != nil {
+	if err := PrepareWebhooks(ctx, EventSource{Repository: repo}, webhook_module.HookEventCreate, convert.ToCreatePayload(ctx, pusher, repo, refFullName, refID)); err != nil {
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
@@ -887,3 +878,33 @@ func notifyPackage(ctx context.Context, sender *user_model.User, pd *packages_mo
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
+
+func (m *webhookNotifier) ActionsSchedulesRegistered(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+	sendActionsScheduleHook(ctx, doer, repo, schedules, api.HookActionsScheduleRegistered)
+}
+
+func (m *webhookNotifier) ActionsSchedulesCleaned(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule) {
+	sendActionsScheduleHook(ctx, doer, repo, schedules, api.HookActionsScheduleCleaned)
+}
+
+// sendActionsScheduleHook sends a single event for all the schedules of the repository, so a bulk registration or cleanup won't flood the webhooks
+func sendActionsScheduleHook(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, schedules []*actions_model.ActionSchedule, action api.HookActionsScheduleAction) {
+	apiSchedules := make([]*api.ActionsSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		apiSchedules = append(apiSchedules, &api.ActionsSchedule{
+			Workflow: schedule.WorkflowID,
+			Dir:      schedule.WorkflowDir,
+			Specs:    schedule.Specs,
+			Disabled: schedule.Disabled,
+		})
+	}
+
+	if err := PrepareWebhooks(ctx, EventSource{Repository: repo}, webhook_module.HookEventActionsSchedule, &api.ActionsSchedulePayload{
+		Action:     action,
+		Schedules:  apiSchedules,
+		Repository: convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm.AccessModeOwner}),
+		Sender:     convert.ToUser(ctx, doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
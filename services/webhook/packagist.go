@@ -108,6 +108,11 @@ func (f *PackagistPayload) Package(_ *api.PackagePayload) (api.Payloader, error)
 	return nil, nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (f *PackagistPayload) ActionsSchedule(_ *api.ActionsSchedulePayload) (api.Payloader, error) {
+	return nil, nil
+}
+
 // GetPackagistPayload converts a packagist webhook into a PackagistPayload
 func GetPackagistPayload(p api.Payloader, event webhook_module.HookEventType, meta string) (api.Payloader, error) {
 	s := new(PackagistPayload)
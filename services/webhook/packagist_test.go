@@ -124,6 +124,15 @@ func TestPackagistPayload(t *testing.T) {
 		require.Nil(t, pl)
 	})
 
+	t.Run("ActionsSchedule", func(t *testing.T) {
+		p := actionsScheduleTestPayload()
+
+		d := new(PackagistPayload)
+		pl, err := d.ActionsSchedule(p)
+		require.NoError(t, err)
+		require.Nil(t, pl)
+	})
+
 	t.Run("Wiki", func(t *testing.T) {
 		p := wikiTestPayload()
 
@@ -23,6 +23,7 @@ type PayloadConvertor interface {
 	Release(*api.ReleasePayload) (api.Payloader, error)
 	Wiki(*api.WikiPayload) (api.Payloader, error)
 	Package(*api.PackagePayload) (api.Payloader, error)
+	ActionsSchedule(*api.ActionsSchedulePayload) (api.Payloader, error)
 }
 
 func convertPayloader(s PayloadConvertor, p api.Payloader, event webhook_module.HookEventType) (api.Payloader, error) {
@@ -56,6 +57,8 @@ func convertPayloader(s PayloadConvertor, p api.Payloader, event webhook_module.
 		return s.Wiki(p.(*api.WikiPayload))
 	case webhook_module.HookEventPackage:
 		return s.Package(p.(*api.PackagePayload))
+	case webhook_module.HookEventActionsSchedule:
+		return s.ActionsSchedule(p.(*api.ActionsSchedulePayload))
 	}
 	return s, nil
 }
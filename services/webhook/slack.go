@@ -178,6 +178,13 @@ func (s *SlackPayload) Package(p *api.PackagePayload) (api.Payloader, error) {
 	return s.createPayload(text, nil), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (s *SlackPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, _ := getActionsSchedulePayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
 // Push implements PayloadConvertor Push method
 func (s *SlackPayload) Push(p *api.PushPayload) (api.Payloader, error) {
 	// n new commits
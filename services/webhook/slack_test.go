@@ -156,6 +156,18 @@ func TestSlackPayload(t *testing.T) {
 		assert.Equal(t, "Package created: <http://localhost:3000/user1/-/packages/container/GiteaContainer/latest|GiteaContainer:latest> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
 	})
 
+	t.Run("ActionsSchedule", func(t *testing.T) {
+		p := actionsScheduleTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.ActionsSchedule(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Actions schedules registered: nightly.yaml (0 2 * * *); weekly.yaml (0 3 * * 1, 0 3 * * 4) by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
+
 	t.Run("Wiki", func(t *testing.T) {
 		p := wikiTestPayload()
 
@@ -192,6 +192,13 @@ func (t *TelegramPayload) Package(p *api.PackagePayload) (api.Payloader, error)
 	return createTelegramPayload(text), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (t *TelegramPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, _ := getActionsSchedulePayloadInfo(p, htmlLinkFormatter, true)
+
+	return createTelegramPayload(text), nil
+}
+
 // GetTelegramPayload converts a telegram webhook into a TelegramPayload
 func GetTelegramPayload(p api.Payloader, event webhook_module.HookEventType, _ string) (api.Payloader, error) {
 	return convertPayloader(new(TelegramPayload), p, event)
@@ -156,6 +156,18 @@ func TestTelegramPayload(t *testing.T) {
 		assert.Equal(t, `Package created: <a href="http://localhost:3000/user1/-/packages/container/GiteaContainer/latest">GiteaContainer:latest</a> by <a href="https://try.gitea.io/user1">user1</a>`, pl.(*TelegramPayload).Message)
 	})
 
+	t.Run("ActionsSchedule", func(t *testing.T) {
+		p := actionsScheduleTestPayload()
+
+		d := new(TelegramPayload)
+		pl, err := d.ActionsSchedule(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &TelegramPayload{}, pl)
+
+		assert.Equal(t, `[<a href="http://localhost:3000/test/repo">test/repo</a>] Actions schedules registered: nightly.yaml (0 2 * * *); weekly.yaml (0 3 * * 1, 0 3 * * 4) by <a href="https://try.gitea.io/user1">user1</a>`, pl.(*TelegramPayload).Message)
+	})
+
 	t.Run("Wiki", func(t *testing.T) {
 		p := wikiTestPayload()
 
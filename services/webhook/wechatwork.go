@@ -185,6 +185,13 @@ func (f *WechatworkPayload) Package(p *api.PackagePayload) (api.Payloader, error
 	return newWechatworkMarkdownPayload(text), nil
 }
 
+// ActionsSchedule implements PayloadConvertor ActionsSchedule method
+func (f *WechatworkPayload) ActionsSchedule(p *api.ActionsSchedulePayload) (api.Payloader, error) {
+	text, _ := getActionsSchedulePayloadInfo(p, noneLinkFormatter, true)
+
+	return newWechatworkMarkdownPayload(text), nil
+}
+
 // GetWechatworkPayload GetWechatworkPayload converts a ding talk webhook into a WechatworkPayload
 func GetWechatworkPayload(p api.Payloader, event webhook_module.HookEventType, _ string) (api.Payloader, error) {
 	return convertPayloader(new(WechatworkPayload), p, event)
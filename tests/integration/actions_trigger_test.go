@@ -285,3 +285,79 @@ func TestSkipCI(t *testing.T) {
 		assert.Equal(t, 1, unittest.GetCount(t, &actions_model.ActionRun{RepoID: repo.ID}))
 	})
 }
+
+func TestPushAutoCancelExempt(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:          "auto-cancel-exempt",
+			Description:   "test the exemption from auto-cancel on push",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+			IsPrivate:     false,
+		})
+		assert.NoError(t, err)
+
+		err = repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   unit_model.TypeActions,
+		}}, nil)
+		assert.NoError(t, err)
+
+		push := func(files []*files_service.ChangeRepoFile) {
+			resp, err := files_service.ChangeRepoFiles(git.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+				Files:     files,
+				Message:   "update",
+				OldBranch: "main",
+				NewBranch: "main",
+				Author: &files_service.IdentityOptions{
+					Name:  user2.Name,
+					Email: user2.Email,
+				},
+				Committer: &files_service.IdentityOptions{
+					Name:  user2.Name,
+					Email: user2.Email,
+				},
+				Dates: &files_service.CommitDateOptions{
+					Author:    time.Now(),
+					Committer: time.Now(),
+				},
+			})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, resp)
+		}
+
+		// there is no runner, so the runs keep waiting
+		push([]*files_service.ChangeRepoFile{
+			{
+				Operation:     "create",
+				TreePath:      ".gitea/workflows/test.yml",
+				ContentReader: strings.NewReader("name: test\non:\n  push:\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo test\n"),
+			},
+			{
+				Operation:     "create",
+				TreePath:      ".gitea/workflows/deploy.yml",
+				ContentReader: strings.NewReader("name: deploy\non:\n  push:\nconcurrency:\n  group: deploy\n  cancel-in-progress: false\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo deploy\n"),
+			},
+		})
+		testRun := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: repo.ID, WorkflowID: "test.yml"})
+		deployRun := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: repo.ID, WorkflowID: "deploy.yml"})
+
+		push([]*files_service.ChangeRepoFile{
+			{
+				Operation:     "create",
+				TreePath:      "bar.txt",
+				ContentReader: strings.NewReader("bar"),
+			},
+		})
+		assert.Equal(t, 4, unittest.GetCount(t, &actions_model.ActionRun{RepoID: repo.ID}))
+
+		// the previous run of the normal workflow is cancelled, and the one of the exempt workflow survives
+		testJob := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: testRun.ID})
+		assert.Equal(t, actions_model.StatusCancelled, testJob.Status)
+		deployJob := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: deployRun.ID})
+		assert.Equal(t, actions_model.StatusWaiting, deployJob.Status)
+	})
+}
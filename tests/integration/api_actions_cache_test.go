@@ -0,0 +1,95 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reserveCacheRequest struct {
+	Key       string `json:"key"`
+	Version   string `json:"version"`
+	CacheSize int64  `json:"cacheSize"`
+}
+
+type reserveCacheResponse struct {
+	CacheID int64 `json:"cacheId"`
+}
+
+type restoreCacheResponse struct {
+	Result          string `json:"result"`
+	CacheKey        string `json:"cacheKey"`
+	Scope           string `json:"scope"`
+	ArchiveLocation string `json:"archiveLocation"`
+}
+
+func TestActionsCacheSaveAndRestore(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	const token = "8061e833a55f6fc0157c98b883e91fcfeeb1a71a"
+
+	// no cache to restore
+	req := NewRequest(t, "GET", "/api/actions_cache/_apis/artifactcache/cache?keys=deps-linux-abc,deps-linux-&version=v1").
+		AddTokenAuth(token)
+	MakeRequest(t, req, http.StatusNoContent)
+
+	// reserve the cache
+	req = NewRequestWithJSON(t, "POST", "/api/actions_cache/_apis/artifactcache/caches", reserveCacheRequest{
+		Key:       "deps-linux-abc",
+		Version:   "v1",
+		CacheSize: 2048,
+	}).AddTokenAuth(token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var reserveResp reserveCacheResponse
+	DecodeJSON(t, resp, &reserveResp)
+	assert.NotZero(t, reserveResp.CacheID)
+	cacheURL := fmt.Sprintf("/api/actions_cache/_apis/artifactcache/caches/%d", reserveResp.CacheID)
+
+	// the ref can't reserve the same cache again
+	req = NewRequestWithJSON(t, "POST", "/api/actions_cache/_apis/artifactcache/caches", reserveCacheRequest{
+		Key:     "deps-linux-abc",
+		Version: "v1",
+	}).AddTokenAuth(token)
+	MakeRequest(t, req, http.StatusConflict)
+
+	// upload the chunks in any order
+	req = NewRequestWithBody(t, "PATCH", cacheURL, strings.NewReader(strings.Repeat("B", 1024))).
+		AddTokenAuth(token).
+		SetHeader("Content-Range", "bytes 1024-2047/*")
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestWithBody(t, "PATCH", cacheURL, strings.NewReader(strings.Repeat("A", 1024))).
+		AddTokenAuth(token).
+		SetHeader("Content-Range", "bytes 0-1023/*")
+	MakeRequest(t, req, http.StatusNoContent)
+
+	// commit the cache
+	req = NewRequestWithJSON(t, "POST", cacheURL, map[string]int64{"size": 2048}).AddTokenAuth(token)
+	MakeRequest(t, req, http.StatusNoContent)
+
+	// restore the cache by the restore key
+	req = NewRequest(t, "GET", "/api/actions_cache/_apis/artifactcache/cache?keys=deps-linux-xyz,deps-linux-&version=v1").
+		AddTokenAuth(token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var restoreResp restoreCacheResponse
+	DecodeJSON(t, resp, &restoreResp)
+	assert.Equal(t, "hit", restoreResp.Result)
+	assert.Equal(t, "deps-linux-abc", restoreResp.CacheKey)
+	assert.Equal(t, "refs/heads/master", restoreResp.Scope)
+
+	// download the archive without the token
+	idx := strings.Index(restoreResp.ArchiveLocation, "/api/actions_cache/")
+	req = NewRequest(t, "GET", restoreResp.ArchiveLocation[idx:])
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, strings.Repeat("A", 1024)+strings.Repeat("B", 1024), resp.Body.String())
+
+	// the signature must match
+	req = NewRequest(t, "GET", restoreResp.ArchiveLocation[idx:]+"0")
+	MakeRequest(t, req, http.StatusUnauthorized)
+}